@@ -0,0 +1,62 @@
+// Command mpsd is a reference server driving the CMP protocols for a single party.
+//
+// It exposes StartKeygen, StartSign, PushMessage and GetResult as the gRPC service mpsd.v1.Party,
+// and streams outgoing messages with the server-side stream StreamMessages, so that they can be relayed
+// to the other parties by any transport. Unlike the lock-step loop in example/, every session runs
+// in its own goroutine, and a transport can reconnect to the message stream at any offset.
+//
+// The configs produced by keygen are saved in a config.Store, and the pushed messages are recorded
+// by a router.Router, so that messages retransmitted after a reconnection are delivered exactly once.
+// With -dir, the keys are persisted in that directory. Sessions which are still running do not survive
+// a restart, so their messages are only kept in memory.
+//
+// The messages of the service are encoded as JSON with the content subtype "json", so that no generated
+// protobuf code is needed; see Client for the calling convention.
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	id := flag.String("id", "", "party ID of this server")
+	addr := flag.String("addr", "localhost:8080", "address to listen on")
+	workers := flag.Int("workers", 0, "number of workers in the pool, 0 uses all CPUs")
+	dir := flag.String("dir", "", "directory in which keys are persisted, in memory if empty")
+	retain := flag.Duration("retain", DefaultRetain, "how long finished sessions are kept")
+	flag.Parse()
+
+	if *id == "" {
+		log.Fatal("mpsd: -id is required")
+	}
+
+	opts := Options{Retain: *retain}
+	if *dir != "" {
+		kv, err := config.NewDirKV(*dir)
+		if err != nil {
+			log.Fatalf("mpsd: %v", err)
+		}
+		opts.Keys = config.NewStore(kv, 1)
+	}
+
+	pl := pool.NewPool(*workers)
+	defer pl.TearDown()
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatalf("mpsd: %v", err)
+	}
+	g := grpc.NewServer()
+	Register(g, NewServer(party.ID(*id), pl, opts))
+	log.Printf("mpsd: party %s listening on %s", *id, *addr)
+	if err = g.Serve(lis); err != nil {
+		log.Printf("mpsd: %v", err)
+	}
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/pkg/router"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
+)
+
+// DefaultRetain is how long a finished session is kept when Options.Retain is zero.
+const DefaultRetain = 10 * time.Minute
+
+// errUnknownSession is returned for a session which was never started, or which was evicted.
+var errUnknownSession = errors.New("unknown session")
+
+// Options configures the storage of a Server.
+// The messages pushed to the server are always recorded in memory by a router.Router,
+// so that a message retransmitted by a transport after a reconnection is delivered exactly once.
+type Options struct {
+	// Keys stores the configs produced by keygen sessions, and is read by sign sessions.
+	// It defaults to a store in memory.
+	Keys *config.Store
+	// Retain is how long a finished session stays available to GetResult and StreamMessages before
+	// it is evicted, together with its messages. It defaults to DefaultRetain.
+	Retain time.Duration
+}
+
+// Server drives protocol executions for a single party.
+//
+// Each execution is identified by a session name chosen by the caller, which must be the same for all parties.
+// Outgoing messages are kept in an outbox, so that a transport can stream them from any offset,
+// and resume after a disconnection without losing messages.
+type Server struct {
+	self   party.ID
+	group  curve.Curve
+	pl     *pool.Pool
+	keys   *config.Store
+	router *router.Router
+	retain time.Duration
+
+	mtx      sync.Mutex
+	sessions map[string]*session
+}
+
+// NewServer returns a Server for the party self.
+func NewServer(self party.ID, pl *pool.Pool, opts Options) *Server {
+	if opts.Keys == nil {
+		opts.Keys = config.NewStore(config.NewMemoryKV(), 0)
+	}
+	if opts.Retain <= 0 {
+		opts.Retain = DefaultRetain
+	}
+	return &Server{
+		self:     self,
+		group:    curve.Secp256k1{},
+		pl:       pl,
+		keys:     opts.Keys,
+		router:   router.New(router.NewMemoryStore()),
+		retain:   opts.Retain,
+		sessions: make(map[string]*session),
+	}
+}
+
+type session struct {
+	handler *protocol.MultiHandler
+	ssid    []byte
+
+	mtx    sync.Mutex
+	outbox []*protocol.Message
+	done   bool
+	// key is the fingerprint under which the config produced by a keygen session was saved.
+	key string
+	// err is set if the result of the session could not be saved.
+	err error
+	// updated is closed and replaced whenever the outbox grows or the session finishes.
+	updated chan struct{}
+}
+
+// run collects the messages produced by the handler until it finishes, and calls onDone with the result.
+func (s *session) run(onDone func(interface{}) (string, error)) {
+	for msg := range s.handler.Listen() {
+		s.mtx.Lock()
+		s.outbox = append(s.outbox, msg)
+		close(s.updated)
+		s.updated = make(chan struct{})
+		s.mtx.Unlock()
+	}
+	var (
+		key string
+		err error
+	)
+	if result, resultErr := s.handler.Result(); resultErr == nil && onDone != nil {
+		key, err = onDone(result)
+	}
+	s.mtx.Lock()
+	s.key, s.err = key, err
+	s.done = true
+	close(s.updated)
+	s.mtx.Unlock()
+}
+
+// next returns the messages from offset onwards, whether the session is done,
+// and a channel which is closed when more messages are available.
+func (s *session) next(offset int) ([]*protocol.Message, bool, <-chan struct{}) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var msgs []*protocol.Message
+	if offset < len(s.outbox) {
+		msgs = s.outbox[offset:]
+	}
+	return msgs, s.done, s.updated
+}
+
+// KeygenRequest is the request of StartKeygen.
+type KeygenRequest struct {
+	Session      string     `json:"session"`
+	Participants []party.ID `json:"participants"`
+	Threshold    int        `json:"threshold"`
+}
+
+// SignRequest is the request of StartSign.
+type SignRequest struct {
+	Session string `json:"session"`
+	// Key is the fingerprint of the key to sign with, as returned by GetResult for the keygen session.
+	Key     string     `json:"key"`
+	Signers []party.ID `json:"signers"`
+	// MessageHash is hex encoded.
+	MessageHash string `json:"messageHash"`
+}
+
+// OutgoingMessage is a single message of the stream returned by StreamMessages.
+type OutgoingMessage struct {
+	Index     int      `json:"index"`
+	To        party.ID `json:"to,omitempty"`
+	Broadcast bool     `json:"broadcast,omitempty"`
+	// Data is the binary encoding of the protocol.Message.
+	Data []byte `json:"data"`
+}
+
+// Result is the response of GetResult.
+type Result struct {
+	Done     bool   `json:"done"`
+	Error    string `json:"error,omitempty"`
+	Protocol string `json:"protocol,omitempty"`
+	Kind     string `json:"kind,omitempty"`
+	// Key is the fingerprint of the config produced by a keygen session, to be given in SignRequest.Key.
+	Key       string `json:"key,omitempty"`
+	PublicKey string `json:"publicKey,omitempty"`
+	R         string `json:"r,omitempty"`
+	S         string `json:"s,omitempty"`
+}
+
+// StartKeygen starts a new CMP keygen. The resulting config is saved in Options.Keys.
+func (s *Server) StartKeygen(req KeygenRequest) error {
+	start := cmp.Keygen(s.group, s.self, req.Participants, req.Threshold, s.pl)
+	return s.start(req.Session, start, sessionID("keygen", req.Session), func(result interface{}) (string, error) {
+		c, ok := result.(*cmp.Config)
+		if !ok {
+			return "", fmt.Errorf("keygen returned %T", result)
+		}
+		if _, err := s.keys.Save(c); err != nil {
+			return "", err
+		}
+		return c.KeyFingerprint(), nil
+	})
+}
+
+// StartSign starts a new CMP signature, using the latest config saved in Options.Keys for the key req.Key.
+func (s *Server) StartSign(req SignRequest) error {
+	c, _, err := s.keys.Latest(req.Key, s.self)
+	if errors.Is(err, config.ErrNotFound) {
+		return fmt.Errorf("unknown key %q", req.Key)
+	}
+	if err != nil {
+		return err
+	}
+	messageHash, err := hex.DecodeString(req.MessageHash)
+	if err != nil {
+		return fmt.Errorf("invalid message hash: %w", err)
+	}
+	opts := cmp.SignOptions{
+		Config:      c,
+		Signers:     req.Signers,
+		MessageHash: messageHash,
+		SessionID:   sessionID("sign", req.Session),
+	}
+	if err = opts.Validate(); err != nil {
+		return err
+	}
+	return s.start(req.Session, opts.Start(s.pl), opts.SessionID, nil)
+}
+
+func (s *Server) start(name string, create protocol.StartFunc, sessionID []byte, onDone func(interface{}) (string, error)) error {
+	if name == "" {
+		return errors.New("session name is required")
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if _, ok := s.sessions[name]; ok {
+		return fmt.Errorf("session %q already exists", name)
+	}
+	h, err := protocol.NewMultiHandler(create, sessionID)
+	if err != nil {
+		return err
+	}
+	sess := &session{
+		handler: h,
+		ssid:    h.CurrentRound().SSID(),
+		updated: make(chan struct{}),
+	}
+	s.sessions[name] = sess
+	go func() {
+		sess.run(onDone)
+		time.AfterFunc(s.retain, func() { s.evict(name, sess) })
+	}()
+	return nil
+}
+
+// evict removes a finished session and the messages routed to it.
+func (s *Server) evict(name string, sess *session) {
+	s.mtx.Lock()
+	if s.sessions[name] == sess {
+		delete(s.sessions, name)
+	}
+	s.mtx.Unlock()
+	// the messages are only kept to detect retransmissions, so an error only delays their removal
+	_ = s.router.Forget(sess.ssid)
+}
+
+// sessionID derives the protocol session ID from the session name, which all parties share.
+func sessionID(kind, name string) []byte {
+	return protocol.DeriveSessionID("mpsd/"+kind, 0, []byte(name))
+}
+
+func (s *Server) session(name string) (*session, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	sess, ok := s.sessions[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", errUnknownSession, name)
+	}
+	return sess, nil
+}
+
+// PushMessage delivers a message received from another party to the session.
+// Pushing the same message again, for instance after a transport reconnected, has no effect.
+func (s *Server) PushMessage(name string, msg *protocol.Message) error {
+	sess, err := s.session(name)
+	if err != nil {
+		return err
+	}
+	if !msg.IsFor(s.self) {
+		return fmt.Errorf("message is not for %s", s.self)
+	}
+	if !bytes.Equal(msg.SSID, sess.ssid) {
+		return fmt.Errorf("message is not for session %q", name)
+	}
+	// a retransmission is dropped before it is checked, since the handler may have moved on to the next round
+	routed, err := s.router.Route(msg)
+	if err != nil || !routed {
+		return err
+	}
+	if err = sess.handler.CanAcceptErr(msg); err != nil {
+		return err
+	}
+	_, err = s.router.Deliver(sess.handler)
+	return err
+}
+
+// GetResult returns the state of the session.
+func (s *Server) GetResult(name string) (*Result, error) {
+	sess, err := s.session(name)
+	if err != nil {
+		return nil, err
+	}
+	_, done, _ := sess.next(0)
+	if !done {
+		return &Result{}, nil
+	}
+	result, err := sess.handler.Result()
+	if err != nil {
+		return &Result{Done: true, Error: err.Error()}, nil
+	}
+	metadata, err := sess.handler.ResultMetadata()
+	if err != nil {
+		return nil, err
+	}
+	out := &Result{Done: true, Protocol: metadata.Protocol, Kind: string(metadata.Kind)}
+	sess.mtx.Lock()
+	out.Key = sess.key
+	if sess.err != nil {
+		out.Error = sess.err.Error()
+	}
+	sess.mtx.Unlock()
+	switch r := result.(type) {
+	case *cmp.Config:
+		out.PublicKey = r.PublicKeyHex()
+	case *ecdsa.Signature:
+		out.R, out.S = curve.ToHexCompressed(r.R), curve.ScalarToHex(r.S)
+	}
+	return out, nil
+}
+
+// StreamMessages passes all outgoing messages of the session from offset onwards to send,
+// until the session is done or ctx is cancelled.
+func (s *Server) StreamMessages(ctx context.Context, name string, offset int, send func(*OutgoingMessage) error) error {
+	sess, err := s.session(name)
+	if err != nil {
+		return err
+	}
+	if offset < 0 {
+		return errors.New("invalid offset")
+	}
+	for {
+		msgs, done, updated := sess.next(offset)
+		for _, msg := range msgs {
+			data, err := msg.MarshalBinary()
+			if err != nil {
+				return err
+			}
+			if err = send(&OutgoingMessage{
+				Index:     offset,
+				To:        msg.To,
+				Broadcast: msg.Broadcast,
+				Data:      data,
+			}); err != nil {
+				return err
+			}
+			offset++
+		}
+		if done && len(msgs) == 0 {
+			return nil
+		}
+		if len(msgs) > 0 {
+			continue
+		}
+		select {
+		case <-updated:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
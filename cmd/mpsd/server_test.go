@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newClient serves srv over an in-memory connection, and returns a Client for it.
+func newClient(t *testing.T, srv *Server) *Client {
+	lis := bufconn.Listen(1 << 20)
+	g := grpc.NewServer()
+	Register(g, srv)
+	go func() { _ = g.Serve(lis) }()
+	t.Cleanup(g.Stop)
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return NewClient(conn)
+}
+
+// relay streams the outgoing messages of session on from, and pushes them to the other clients.
+// Every message is pushed twice, as a transport resuming a stream would, which must have no effect.
+// It runs in its own goroutine, so it must not use require.
+func relay(t *testing.T, from *Client, session string, to map[party.ID]*Client) {
+	ctx := context.Background()
+	err := from.StreamMessages(ctx, &StreamRequest{Session: session}, func(out *OutgoingMessage) error {
+		msg := new(protocol.Message)
+		if err := msg.UnmarshalBinary(out.Data); err != nil {
+			return err
+		}
+		for id, c := range to {
+			if !msg.IsFor(id) {
+				continue
+			}
+			for i := 0; i < 2; i++ {
+				// the recipient may have already finished and evicted the session
+				err := c.PushMessage(ctx, &PushRequest{Session: session, Data: out.Data})
+				if err != nil && status.Code(err) != codes.NotFound {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func waitResult(t *testing.T, c *Client, session string) *Result {
+	for start := time.Now(); time.Since(start) < time.Minute; time.Sleep(10 * time.Millisecond) {
+		result, err := c.GetResult(context.Background(), &ResultRequest{Session: session})
+		require.NoError(t, err)
+		if result.Done {
+			return result
+		}
+	}
+	t.Fatal("timed out waiting for result")
+	return nil
+}
+
+func run(t *testing.T, clients map[party.ID]*Client, session string, start func(*Client) error) map[party.ID]*Result {
+	for _, c := range clients {
+		require.NoError(t, start(c))
+	}
+	for id, c := range clients {
+		others := make(map[party.ID]*Client)
+		for j, other := range clients {
+			if j != id {
+				others[j] = other
+			}
+		}
+		go relay(t, c, session, others)
+	}
+	results := make(map[party.ID]*Result, len(clients))
+	for id, c := range clients {
+		results[id] = waitResult(t, c, session)
+		require.Empty(t, results[id].Error)
+	}
+	return results
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+	partyIDs := test.PartyIDs(2)
+	clients := make(map[party.ID]*Client, len(partyIDs))
+	for _, id := range partyIDs {
+		pl := pool.NewPool(0)
+		defer pl.TearDown()
+		clients[id] = newClient(t, NewServer(id, pl, Options{Retain: time.Second}))
+	}
+
+	keygen := &KeygenRequest{
+		Session:      "key",
+		Participants: partyIDs,
+		Threshold:    1,
+	}
+	results := run(t, clients, "key", func(c *Client) error { return c.StartKeygen(ctx, keygen) })
+	assert.Equal(t, string(protocol.ResultConfig), results[partyIDs[0]].Kind)
+	publicKey, key := results[partyIDs[0]].PublicKey, results[partyIDs[0]].Key
+	assert.NotEmpty(t, publicKey)
+	assert.NotEmpty(t, key)
+	assert.Equal(t, publicKey, results[partyIDs[1]].PublicKey)
+	assert.Equal(t, key, results[partyIDs[1]].Key)
+
+	// the same session cannot be started twice
+	err := clients[partyIDs[0]].StartKeygen(ctx, keygen)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	messageHash := hex.EncodeToString(bytes.Repeat([]byte{0x42}, 32))
+	results = run(t, clients, "sign", func(c *Client) error {
+		return c.StartSign(ctx, &SignRequest{
+			Session:     "sign",
+			Key:         key,
+			Signers:     partyIDs,
+			MessageHash: messageHash,
+		})
+	})
+	assert.Equal(t, string(protocol.ResultSignature), results[partyIDs[0]].Kind)
+	assert.NotEmpty(t, results[partyIDs[0]].R)
+	assert.Equal(t, results[partyIDs[0]].R, results[partyIDs[1]].R)
+	assert.Equal(t, results[partyIDs[0]].S, results[partyIDs[1]].S)
+
+	err = clients[partyIDs[0]].StartSign(ctx, &SignRequest{
+		Session: "unknown key",
+		Key:     "missing",
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// finished sessions are evicted, but the key remains available
+	require.Eventually(t, func() bool {
+		_, err := clients[partyIDs[0]].GetResult(ctx, &ResultRequest{Session: "key"})
+		return status.Code(err) == codes.NotFound
+	}, 10*time.Second, 50*time.Millisecond)
+	results = run(t, clients, "sign again", func(c *Client) error {
+		return c.StartSign(ctx, &SignRequest{
+			Session:     "sign again",
+			Key:         key,
+			Signers:     partyIDs,
+			MessageHash: messageHash,
+		})
+	})
+	assert.Equal(t, string(protocol.ResultSignature), results[partyIDs[0]].Kind)
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/status"
+)
+
+// serviceName is the fully qualified name of the gRPC service.
+const serviceName = "mpsd.v1.Party"
+
+// codecName is the content subtype of the messages of the service, which are encoded as JSON
+// so that the service does not depend on generated protobuf code.
+// Clients must call it with grpc.CallContentSubtype(codecName), as Client does.
+const codecName = "json"
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                               { return codecName }
+
+// Empty is the response of the methods which only report errors.
+type Empty struct{}
+
+// PushRequest is the request of PushMessage.
+type PushRequest struct {
+	Session string `json:"session"`
+	// Data is the binary encoding of the protocol.Message.
+	Data []byte `json:"data"`
+}
+
+// ResultRequest is the request of GetResult.
+type ResultRequest struct {
+	Session string `json:"session"`
+}
+
+// StreamRequest is the request of StreamMessages.
+type StreamRequest struct {
+	Session string `json:"session"`
+	// Offset is the index of the first message to send, so that a stream can be resumed.
+	Offset int `json:"offset,omitempty"`
+}
+
+// partyServer is implemented by Server, and is the handler type of serviceDesc.
+type partyServer interface {
+	StartKeygen(KeygenRequest) error
+	StartSign(SignRequest) error
+	PushMessage(string, *protocol.Message) error
+	GetResult(string) (*Result, error)
+	StreamMessages(context.Context, string, int, func(*OutgoingMessage) error) error
+}
+
+// Register registers s as the mpsd.v1.Party service of g, which has the methods
+//
+//	rpc StartKeygen(KeygenRequest) returns (Empty)
+//	rpc StartSign(SignRequest) returns (Empty)
+//	rpc PushMessage(PushRequest) returns (Empty)
+//	rpc GetResult(ResultRequest) returns (Result)
+//	rpc StreamMessages(StreamRequest) returns (stream OutgoingMessage)
+func Register(g *grpc.Server, s *Server) {
+	g.RegisterService(&serviceDesc, s)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*partyServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "StartKeygen", Handler: unaryHandler("StartKeygen", func(s partyServer, req *KeygenRequest) (interface{}, error) {
+			return &Empty{}, s.StartKeygen(*req)
+		})},
+		{MethodName: "StartSign", Handler: unaryHandler("StartSign", func(s partyServer, req *SignRequest) (interface{}, error) {
+			return &Empty{}, s.StartSign(*req)
+		})},
+		{MethodName: "PushMessage", Handler: unaryHandler("PushMessage", func(s partyServer, req *PushRequest) (interface{}, error) {
+			msg := new(protocol.Message)
+			if err := msg.UnmarshalBinary(req.Data); err != nil {
+				return nil, err
+			}
+			return &Empty{}, s.PushMessage(req.Session, msg)
+		})},
+		{MethodName: "GetResult", Handler: unaryHandler("GetResult", func(s partyServer, req *ResultRequest) (interface{}, error) {
+			return s.GetResult(req.Session)
+		})},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "StreamMessages", Handler: streamMessages, ServerStreams: true},
+	},
+}
+
+// unaryHandler adapts a method of partyServer taking a request of type T to a grpc.MethodDesc handler.
+func unaryHandler[T any](method string, call func(partyServer, *T) (interface{}, error)) func(interface{}, context.Context, func(interface{}) error, grpc.UnaryServerInterceptor) (interface{}, error) {
+	return func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+		req := new(T)
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		handler := func(_ context.Context, req interface{}) (interface{}, error) {
+			resp, err := call(srv.(partyServer), req.(*T))
+			if err != nil {
+				return nil, toStatus(err)
+			}
+			return resp, nil
+		}
+		if interceptor == nil {
+			return handler(ctx, req)
+		}
+		return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + serviceName + "/" + method}, handler)
+	}
+}
+
+func streamMessages(srv interface{}, stream grpc.ServerStream) error {
+	var req StreamRequest
+	if err := stream.RecvMsg(&req); err != nil {
+		return err
+	}
+	err := srv.(partyServer).StreamMessages(stream.Context(), req.Session, req.Offset, func(msg *OutgoingMessage) error {
+		return stream.SendMsg(msg)
+	})
+	return toStatus(err)
+}
+
+// toStatus converts the errors of Server to gRPC status errors.
+func toStatus(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, errUnknownSession):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// Client calls the mpsd.v1.Party service of a Server.
+type Client struct {
+	conn *grpc.ClientConn
+}
+
+// NewClient returns a Client using conn.
+func NewClient(conn *grpc.ClientConn) *Client {
+	return &Client{conn: conn}
+}
+
+func (c *Client) invoke(ctx context.Context, method string, req, resp interface{}) error {
+	return c.conn.Invoke(ctx, "/"+serviceName+"/"+method, req, resp, grpc.CallContentSubtype(codecName))
+}
+
+// StartKeygen calls StartKeygen.
+func (c *Client) StartKeygen(ctx context.Context, req *KeygenRequest) error {
+	return c.invoke(ctx, "StartKeygen", req, &Empty{})
+}
+
+// StartSign calls StartSign.
+func (c *Client) StartSign(ctx context.Context, req *SignRequest) error {
+	return c.invoke(ctx, "StartSign", req, &Empty{})
+}
+
+// PushMessage calls PushMessage.
+func (c *Client) PushMessage(ctx context.Context, req *PushRequest) error {
+	return c.invoke(ctx, "PushMessage", req, &Empty{})
+}
+
+// GetResult calls GetResult.
+func (c *Client) GetResult(ctx context.Context, req *ResultRequest) (*Result, error) {
+	resp := new(Result)
+	if err := c.invoke(ctx, "GetResult", req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// StreamMessages calls StreamMessages, and passes every received message to recv until the stream ends.
+func (c *Client) StreamMessages(ctx context.Context, req *StreamRequest, recv func(*OutgoingMessage) error) error {
+	stream, err := c.conn.NewStream(ctx, &serviceDesc.Streams[0], "/"+serviceName+"/StreamMessages", grpc.CallContentSubtype(codecName))
+	if err != nil {
+		return err
+	}
+	if err = stream.SendMsg(req); err != nil {
+		return err
+	}
+	if err = stream.CloseSend(); err != nil {
+		return err
+	}
+	for {
+		msg := new(OutgoingMessage)
+		if err = stream.RecvMsg(msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err = recv(msg); err != nil {
+			return err
+		}
+	}
+}
@@ -60,7 +60,7 @@ func CMPRefresh(c *cmp.Config, n *test.Network, pl *pool.Pool) (*cmp.Config, err
 }
 
 func CMPSign(c *cmp.Config, m []byte, signers party.IDSlice, n *test.Network, pl *pool.Pool) error {
-	h, err := protocol.NewMultiHandler(cmp.Sign(c, signers, m, pl), nil)
+	h, err := protocol.NewMultiHandler(cmp.SignMessage(c, signers, m, ecdsa.ProfileEthereum, pl), nil)
 	if err != nil {
 		return err
 	}
@@ -71,7 +71,7 @@ func CMPSign(c *cmp.Config, m []byte, signers party.IDSlice, n *test.Network, pl
 		return err
 	}
 	signature := signResult.(*ecdsa.Signature)
-	if !signature.Verify(c.PublicPoint(), m) {
+	if !signature.Verify(c.PublicPoint(), ecdsa.ProfileEthereum.HashMessage(m)) {
 		return errors.New("failed to verify cmp signature")
 	}
 	return nil
@@ -98,7 +98,7 @@ func CMPPreSign(c *cmp.Config, signers party.IDSlice, n *test.Network, pl *pool.
 }
 
 func CMPPreSignOnline(c *cmp.Config, preSignature *ecdsa.PreSignature, m []byte, n *test.Network, pl *pool.Pool) error {
-	h, err := protocol.NewMultiHandler(cmp.PresignOnline(c, preSignature, m, pl), nil)
+	h, err := protocol.NewMultiHandler(cmp.PresignOnlineMessage(c, preSignature, m, ecdsa.ProfileEthereum, pl), nil)
 	if err != nil {
 		return err
 	}
@@ -109,7 +109,7 @@ func CMPPreSignOnline(c *cmp.Config, preSignature *ecdsa.PreSignature, m []byte,
 		return err
 	}
 	signature := signResult.(*ecdsa.Signature)
-	if !signature.Verify(c.PublicPoint(), m) {
+	if !signature.Verify(c.PublicPoint(), ecdsa.ProfileEthereum.HashMessage(m)) {
 		return errors.New("failed to verify cmp signature")
 	}
 	return nil
@@ -0,0 +1,81 @@
+package base58
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var radix = big.NewInt(58)
+
+// CheckEncode encodes version ‖ payload ‖ checksum in Base58, where the checksum is
+// the first 4 bytes of SHA-256(SHA-256(version ‖ payload)), as used by Bitcoin.
+func CheckEncode(version, payload []byte) string {
+	data := append(append(make([]byte, 0, len(version)+len(payload)+4), version...), payload...)
+	data = append(data, checksum(data)...)
+	return encode(data)
+}
+
+// CheckDecode parses a Base58Check string with a version prefix of versionLen bytes,
+// verifying its checksum.
+func CheckDecode(s string, versionLen int) (version, payload []byte, err error) {
+	data, err := decode(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(data) < versionLen+4 {
+		return nil, nil, errors.New("base58: string too short")
+	}
+	body, sum := data[:len(data)-4], data[len(data)-4:]
+	if !bytes.Equal(checksum(body), sum) {
+		return nil, nil, errors.New("base58: invalid checksum")
+	}
+	return body[:versionLen], body[versionLen:], nil
+}
+
+func checksum(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
+
+func encode(data []byte) string {
+	x := new(big.Int).SetBytes(data)
+	mod := new(big.Int)
+	out := make([]byte, 0, len(data)*138/100+1)
+	for x.Sign() > 0 {
+		x.DivMod(x, radix, mod)
+		out = append(out, alphabet[mod.Int64()])
+	}
+	// leading zero bytes are encoded as '1'
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		out = append(out, alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func decode(s string) ([]byte, error) {
+	x := new(big.Int)
+	for i := 0; i < len(s); i++ {
+		v := bytes.IndexByte([]byte(alphabet), s[i])
+		if v < 0 {
+			return nil, errors.New("base58: invalid character")
+		}
+		x.Mul(x, radix)
+		x.Add(x, big.NewInt(int64(v)))
+	}
+	zeros := 0
+	for zeros < len(s) && s[zeros] == alphabet[0] {
+		zeros++
+	}
+	return append(make([]byte, zeros), x.Bytes()...), nil
+}
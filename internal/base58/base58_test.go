@@ -0,0 +1,28 @@
+package base58
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckEncode(t *testing.T) {
+	// P2PKH address of the all-zero hash160
+	assert.Equal(t, "1111111111111111111114oLvT2", CheckEncode([]byte{0}, make([]byte, 20)))
+
+	version, payload, err := CheckDecode("1111111111111111111114oLvT2", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0}, version)
+	assert.Equal(t, make([]byte, 20), payload)
+}
+
+func TestCheckDecodeInvalid(t *testing.T) {
+	s := CheckEncode([]byte{0x05}, []byte("multi-party-sig"))
+	_, _, err := CheckDecode(s[:len(s)-1]+"2", 1)
+	assert.Error(t, err, "checksum")
+	_, _, err = CheckDecode("0OIl", 1)
+	assert.Error(t, err, "alphabet")
+	_, _, err = CheckDecode("11", 1)
+	assert.Error(t, err, "too short")
+}
@@ -0,0 +1,176 @@
+package bech32
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// maxLength is the maximum length of an encoded string, as defined by BIP-173.
+const maxLength = 90
+
+var generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+// Checksum constants of Bech32 and Bech32m, as defined by BIP-173 and BIP-350.
+const (
+	constBech32  uint32 = 1
+	constBech32m uint32 = 0x2bc830a3
+)
+
+// Encode encodes data as a Bech32 string with the given human readable part.
+//
+// See: https://github.com/bitcoin/bips/blob/master/bip-0173.mediawiki
+func Encode(hrp string, data []byte) (string, error) {
+	return encodeValues(hrp, convertBits(data, 8, 5, true), constBech32)
+}
+
+// EncodeSegwit encodes a segregated witness output as a Bitcoin address with the given human readable part
+// (for example "bc"). Version 0 programs are encoded with Bech32, and later versions with Bech32m.
+//
+// See: https://github.com/bitcoin/bips/blob/master/bip-0350.mediawiki
+func EncodeSegwit(hrp string, version byte, program []byte) (string, error) {
+	if version > 16 {
+		return "", fmt.Errorf("bech32: invalid witness version %d", version)
+	}
+	if len(program) < 2 || len(program) > 40 || (version == 0 && len(program) != 20 && len(program) != 32) {
+		return "", fmt.Errorf("bech32: invalid witness program length %d for version %d", len(program), version)
+	}
+	constant := constBech32
+	if version > 0 {
+		constant = constBech32m
+	}
+	return encodeValues(hrp, append([]byte{version}, convertBits(program, 8, 5, true)...), constant)
+}
+
+// encodeValues encodes 5-bit values with the given human readable part and checksum constant.
+func encodeValues(hrp string, values []byte, constant uint32) (string, error) {
+	if len(hrp) == 0 || strings.ToLower(hrp) != hrp {
+		return "", errors.New("bech32: human readable part must be non-empty and lowercase")
+	}
+	for _, c := range hrp {
+		if c < 33 || c > 126 {
+			return "", fmt.Errorf("bech32: invalid character %q in human readable part", c)
+		}
+	}
+	if len(hrp)+1+len(values)+6 > maxLength {
+		return "", errors.New("bech32: data too long")
+	}
+
+	var b strings.Builder
+	b.WriteString(hrp)
+	b.WriteByte('1')
+	for _, v := range append(values, checksum(hrp, values, constant)...) {
+		b.WriteByte(charset[v])
+	}
+	return b.String(), nil
+}
+
+// Decode parses a Bech32 string, verifying its checksum, and returns the human readable part and the data.
+func Decode(s string) (string, []byte, error) {
+	hrp, values, err := decodeValues(s)
+	if err != nil {
+		return "", nil, err
+	}
+	data := convertBits(values, 5, 8, false)
+	if data == nil {
+		return "", nil, errors.New("bech32: invalid padding")
+	}
+	return hrp, data, nil
+}
+
+// decodeValues parses and verifies a Bech32 string, returning the 5-bit values without the checksum.
+func decodeValues(s string) (string, []byte, error) {
+	if len(s) > maxLength {
+		return "", nil, errors.New("bech32: string too long")
+	}
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, errors.New("bech32: mixed case")
+	}
+	s = strings.ToLower(s)
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+7 > len(s) {
+		return "", nil, errors.New("bech32: invalid separator position")
+	}
+	hrp := s[:sep]
+	for _, c := range hrp {
+		if c < 33 || c > 126 {
+			return "", nil, fmt.Errorf("bech32: invalid character %q in human readable part", c)
+		}
+	}
+	values := make([]byte, 0, len(s)-sep-1)
+	for _, c := range s[sep+1:] {
+		v := strings.IndexRune(charset, c)
+		if v < 0 {
+			return "", nil, fmt.Errorf("bech32: invalid character %q", c)
+		}
+		values = append(values, byte(v))
+	}
+	if polymod(append(expandHRP(hrp), values...)) != 1 {
+		return "", nil, errors.New("bech32: invalid checksum")
+	}
+	return hrp, values[:len(values)-6], nil
+}
+
+func polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func expandHRP(hrp string) []byte {
+	out := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+func checksum(hrp string, values []byte, constant uint32) []byte {
+	mod := polymod(append(append(expandHRP(hrp), values...), 0, 0, 0, 0, 0, 0)) ^ constant
+	out := make([]byte, 6)
+	for i := range out {
+		out[i] = byte(mod>>uint(5*(5-i))) & 31
+	}
+	return out
+}
+
+// convertBits regroups data from groups of fromBits into groups of toBits.
+// It returns nil if pad is false and the input has non-zero or excess padding.
+func convertBits(data []byte, fromBits, toBits uint, pad bool) []byte {
+	var (
+		acc  uint32
+		bits uint
+		out  = make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+		max  = uint32(1)<<toBits - 1
+	)
+	for _, v := range data {
+		acc = acc<<fromBits | uint32(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits&max))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(toBits-bits)&max))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&max != 0 {
+		return nil
+	}
+	return out
+}
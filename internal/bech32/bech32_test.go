@@ -0,0 +1,78 @@
+package bech32
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVectors(t *testing.T) {
+	// valid checksums from BIP-173
+	for _, s := range []string{
+		"A12UEL5L",
+		"a12uel5l",
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+		"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w",
+	} {
+		_, _, err := decodeValues(s)
+		assert.NoError(t, err, s)
+	}
+	// invalid strings from BIP-173
+	for _, s := range []string{
+		"pzry9x0s0muk",
+		"1pzry9x0s0muk",
+		"x1b4n0q5v",
+		"li1dgmt3",
+		"A1G7SGD8",
+		"10a06t8",
+		"1qzzfhee",
+	} {
+		_, _, err := Decode(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	data := []byte{0x02, 0xde, 0xad, 0xbe, 0xef, 0x00, 0xff}
+	s, err := Encode("mpc", data)
+	require.NoError(t, err)
+	hrp, decoded, err := Decode(s)
+	require.NoError(t, err)
+	assert.Equal(t, "mpc", hrp)
+	assert.Equal(t, data, decoded)
+
+	// flipping a character must invalidate the checksum
+	b := []byte(s)
+	if b[len(b)-1] == 'q' {
+		b[len(b)-1] = 'p'
+	} else {
+		b[len(b)-1] = 'q'
+	}
+	_, _, err = Decode(string(b))
+	assert.Error(t, err)
+
+	_, err = Encode("MPC", data)
+	assert.Error(t, err)
+	_, err = Encode("", data)
+	assert.Error(t, err)
+}
+
+func TestEncodeSegwit(t *testing.T) {
+	// valid addresses from BIP-173 and BIP-350
+	program, _ := hex.DecodeString("751e76e8199196d454941c45d1b3a323f1433bd6")
+	s, err := EncodeSegwit("bc", 0, program)
+	require.NoError(t, err)
+	assert.Equal(t, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", s)
+
+	program, _ = hex.DecodeString("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	s, err = EncodeSegwit("bc", 1, program)
+	require.NoError(t, err)
+	assert.Equal(t, "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", s)
+
+	_, err = EncodeSegwit("bc", 0, program[:16])
+	assert.Error(t, err)
+	_, err = EncodeSegwit("bc", 17, program)
+	assert.Error(t, err)
+}
@@ -0,0 +1,222 @@
+// Package cbormap decodes CBOR maps with text keys, as produced by the cbor encoding of a struct,
+// without reflection. It is used by the hand-written decoders of message contents which are
+// received often enough for the cost of reflection to matter.
+//
+// Only definite lengths are supported, which is what every encoder of this module produces.
+// Otherwise, every map accepted by Decode is also accepted by cbor.Unmarshal with its default options,
+// which matches keys to struct fields as Key does, and the limits below are the same.
+// Duplicate keys are rejected, since cbor.Unmarshal silently keeps the first one.
+package cbormap
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	majorUnsigned = 0
+	majorNegative = 1
+	majorBytes    = 2
+	majorText     = 3
+	majorArray    = 4
+	majorMap      = 5
+	majorTag      = 6
+	majorSimple   = 7
+
+	// maxDepth bounds the nesting of maps, arrays and tags, as in cbor.Unmarshal.
+	// Decode counts the map it decodes as nested in another, since a map decoded by Decode
+	// may itself be the value of an entry, for instance a proof in a message content.
+	maxDepth = 32
+	// depthDecode is the nesting depth of the map decoded by Decode.
+	depthDecode = 2
+	// maxItems bounds the number of elements of an array, and of entries of a map.
+	maxItems = 131072
+)
+
+var errTruncated = errors.New("cbormap: unexpected end of data")
+
+// Decode calls field with the key and the raw encoding of the value of every entry of the map encoded in data,
+// in the order in which they are encoded. Keys must be distinct text strings, even when ignoring case,
+// and data must contain nothing after the map. The value passed to field aliases data.
+func Decode(data []byte, field func(key string, value []byte) error) error {
+	major, n, offset, err := head(data)
+	if err != nil {
+		return err
+	}
+	if major != majorMap {
+		return fmt.Errorf("cbormap: expected map, got major type %d", major)
+	}
+	if n > maxItems {
+		return errors.New("cbormap: too many entries")
+	}
+	// every entry takes at least two bytes
+	if n > uint64(len(data)) {
+		return errTruncated
+	}
+	keys := make(map[string]struct{}, n)
+	for i := uint64(0); i < n; i++ {
+		major, length, keyOffset, err := head(data[offset:])
+		if err != nil {
+			return err
+		}
+		if major != majorText {
+			return fmt.Errorf("cbormap: expected text key, got major type %d", major)
+		}
+		keyStart := offset + keyOffset
+		if uint64(len(data)-keyStart) < length {
+			return errTruncated
+		}
+		keyEnd := keyStart + int(length)
+		key := string(data[keyStart:keyEnd])
+		if !utf8.ValidString(key) {
+			return errors.New("cbormap: invalid UTF-8 key")
+		}
+		if _, ok := keys[fold(key)]; ok {
+			return fmt.Errorf("cbormap: duplicate key %q", key)
+		}
+		keys[fold(key)] = struct{}{}
+		valueEnd, err := skip(data, keyEnd, depthDecode)
+		if err != nil {
+			return err
+		}
+		if err = field(key, data[keyEnd:valueEnd]); err != nil {
+			return err
+		}
+		offset = valueEnd
+	}
+	if offset != len(data) {
+		return errors.New("cbormap: unexpected data after map")
+	}
+	return nil
+}
+
+// Key returns the name among names which key designates, as cbor.Unmarshal matches a key to the name of
+// a struct field: exactly, or else ignoring case. It returns "" if there is none.
+func Key(key string, names ...string) string {
+	for _, name := range names {
+		if key == name {
+			return name
+		}
+	}
+	for _, name := range names {
+		if strings.EqualFold(key, name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// fold maps every rune of s to the smallest rune it is equal to when ignoring case,
+// so that fold(a) == fold(b) if and only if strings.EqualFold(a, b), for valid UTF-8.
+func fold(s string) string {
+	return strings.Map(func(r rune) rune {
+		min := r
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if f < min {
+				min = f
+			}
+		}
+		return min
+	}, s)
+}
+
+// Bytes returns the content of the byte string encoded in value, which aliases value.
+func Bytes(value []byte) ([]byte, error) {
+	major, length, offset, err := head(value)
+	if err != nil {
+		return nil, err
+	}
+	if major != majorBytes {
+		return nil, fmt.Errorf("cbormap: expected byte string, got major type %d", major)
+	}
+	if uint64(len(value)-offset) != length {
+		return nil, errTruncated
+	}
+	return value[offset:], nil
+}
+
+// Binary unmarshals the byte string encoded in value into v, as the cbor package does
+// for a type implementing encoding.BinaryUnmarshaler.
+func Binary(value []byte, v encoding.BinaryUnmarshaler) error {
+	data, err := Bytes(value)
+	if err != nil {
+		return err
+	}
+	return v.UnmarshalBinary(data)
+}
+
+// head decodes the initial byte and argument of the item at the start of data, and returns its major type,
+// its argument, and the offset of its content.
+func head(data []byte) (major byte, argument uint64, offset int, err error) {
+	if len(data) == 0 {
+		return 0, 0, 0, errTruncated
+	}
+	major, info := data[0]>>5, data[0]&0x1f
+	switch {
+	case info < 24:
+		return major, uint64(info), 1, nil
+	case info <= 27:
+		size := 1 << (info - 24)
+		if len(data) < 1+size {
+			return 0, 0, 0, errTruncated
+		}
+		for _, b := range data[1 : 1+size] {
+			argument = argument<<8 | uint64(b)
+		}
+		if major == majorSimple && info == 24 && argument < 32 {
+			return 0, 0, 0, fmt.Errorf("cbormap: invalid simple value %d", argument)
+		}
+		return major, argument, 1 + size, nil
+	case info == 31:
+		return 0, 0, 0, errors.New("cbormap: indefinite lengths are not supported")
+	default:
+		return 0, 0, 0, fmt.Errorf("cbormap: invalid additional information %d", info)
+	}
+}
+
+// skip returns the offset in data of the end of the item starting at offset,
+// which is nested in depth maps, arrays or tags.
+func skip(data []byte, offset, depth int) (int, error) {
+	major, argument, headLength, err := head(data[offset:])
+	if err != nil {
+		return 0, err
+	}
+	offset += headLength
+	switch major {
+	case majorUnsigned, majorNegative, majorSimple:
+		return offset, nil
+	case majorBytes, majorText:
+		if uint64(len(data)-offset) < argument {
+			return 0, errTruncated
+		}
+		return offset + int(argument), nil
+	case majorArray, majorMap, majorTag:
+		if depth++; depth > maxDepth {
+			return 0, errors.New("cbormap: nesting too deep")
+		}
+		items := argument
+		if major != majorTag && items > maxItems {
+			return 0, errors.New("cbormap: too many items")
+		}
+		if major == majorMap {
+			items *= 2
+		} else if major == majorTag {
+			items = 1
+		}
+		// every item takes at least one byte
+		if items > uint64(len(data)-offset) {
+			return 0, errTruncated
+		}
+		for i := uint64(0); i < items; i++ {
+			if offset, err = skip(data, offset, depth); err != nil {
+				return 0, err
+			}
+		}
+		return offset, nil
+	}
+	return 0, fmt.Errorf("cbormap: invalid major type %d", major)
+}
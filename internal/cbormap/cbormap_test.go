@@ -0,0 +1,182 @@
+package cbormap
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type content struct {
+	A []byte
+	B []byte
+	C []byte
+}
+
+// decode returns the entries of the map encoded in data.
+func decode(data []byte) (map[string][]byte, []string, error) {
+	entries := make(map[string][]byte)
+	var keys []string
+	err := Decode(data, func(key string, value []byte) error {
+		entries[key] = value
+		keys = append(keys, key)
+		return nil
+	})
+	return entries, keys, err
+}
+
+// entry returns the encoding of a map with a single entry, whose value is encoded in value.
+func entry(key string, value []byte) []byte {
+	data := []byte{0xa1, 0x60 | byte(len(key))}
+	data = append(data, key...)
+	return append(data, value...)
+}
+
+// nested returns the encoding of depth nested arrays, the innermost one being empty.
+func nested(depth int) []byte {
+	data := bytes.Repeat([]byte{0x81}, depth-1)
+	return append(data, 0x80)
+}
+
+func TestDecode(t *testing.T) {
+	data, err := cbor.Marshal(content{A: []byte{1}, B: []byte{2, 3}})
+	require.NoError(t, err)
+	entries, keys, err := decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A", "B", "C"}, keys)
+	a, err := Bytes(entries["A"])
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1}, a)
+	b, err := Bytes(entries["B"])
+	require.NoError(t, err)
+	assert.Equal(t, []byte{2, 3}, b)
+
+	// a nil slice is encoded as null, which is not a byte string
+	assert.Equal(t, []byte{0xf6}, entries["C"])
+	_, err = Bytes(entries["C"])
+	assert.Error(t, err)
+
+	// values of every major type are skipped
+	value, err := cbor.Marshal([]interface{}{uint64(1), -1, "text", []byte{4}, map[string]int{"x": 1}, cbor.Tag{Number: 64, Content: []byte{5}}, true, nil, 1.5})
+	require.NoError(t, err)
+	entries, _, err = decode(entry("D", value))
+	require.NoError(t, err)
+	assert.Equal(t, value, entries["D"])
+
+	_, _, err = decode([]byte{0x80})
+	assert.Error(t, err, "not a map")
+	_, _, err = decode([]byte{0xa1, 0x41, 'A', 0x41, 1})
+	assert.Error(t, err, "byte string key")
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	data, err := cbor.Marshal(content{A: []byte{1}, B: bytes.Repeat([]byte{2}, 300)})
+	require.NoError(t, err)
+	for i := 0; i < len(data); i++ {
+		_, _, err = decode(data[:i])
+		assert.Error(t, err, "length %d", i)
+	}
+
+	// lengths larger than the data
+	_, _, err = decode([]byte{0xbb, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	assert.Error(t, err)
+	_, _, err = decode(entry("A", []byte{0x5b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}))
+	assert.Error(t, err)
+	_, _, err = decode(entry("A", []byte{0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}))
+	assert.Error(t, err)
+	_, err = Bytes([]byte{0x42, 1})
+	assert.Error(t, err)
+}
+
+func TestDecodeIndefinite(t *testing.T) {
+	// cbor.Unmarshal accepts these, but no encoder of this module produces them
+	for _, data := range [][]byte{
+		{0xbf, 0x61, 'A', 0x41, 1, 0xff},
+		entry("A", []byte{0x5f, 0x41, 1, 0xff}),
+		entry("A", []byte{0x7f, 0x61, 'a', 0xff}),
+		entry("A", []byte{0x9f, 0xff}),
+		entry("A", []byte{0xbf, 0xff}),
+	} {
+		var v map[string]interface{}
+		require.NoError(t, cbor.Unmarshal(data, &v))
+		_, _, err := decode(data)
+		assert.Error(t, err)
+	}
+	_, _, err := decode(entry("A", []byte{0xff}))
+	assert.Error(t, err, "break outside of an indefinite length item")
+}
+
+func TestDecodeDepth(t *testing.T) {
+	var v map[string]interface{}
+	for depth := 1; depth <= maxDepth-depthDecode; depth++ {
+		data := entry("A", nested(depth))
+		_, _, err := decode(data)
+		require.NoError(t, err, "depth %d", depth)
+		require.NoError(t, cbor.Unmarshal(data, &v), "depth %d", depth)
+	}
+	for _, depth := range []int{maxDepth - depthDecode + 1, maxDepth, 10 * maxDepth} {
+		_, _, err := decode(entry("A", nested(depth)))
+		assert.Error(t, err, "depth %d", depth)
+	}
+	assert.Error(t, cbor.Unmarshal(entry("A", nested(maxDepth)), &v))
+
+	// tags count as a level of nesting
+	tags := bytes.Repeat([]byte{0xd8, 0x40}, maxDepth)
+	_, _, err := decode(entry("A", append(tags, 0x40)))
+	assert.Error(t, err)
+}
+
+func TestDecodeDuplicateKeys(t *testing.T) {
+	for _, data := range [][]byte{
+		{0xa2, 0x61, 'A', 0x41, 1, 0x61, 'A', 0x41, 2},
+		{0xa2, 0x61, 'A', 0x41, 1, 0x61, 'a', 0x41, 2},
+		// KELVIN SIGN is equal to K when ignoring case
+		{0xa2, 0x61, 'k', 0x41, 1, 0x63, 0xe2, 0x84, 0xaa, 0x41, 2},
+	} {
+		_, _, err := decode(data)
+		assert.Error(t, err)
+	}
+	_, keys, err := decode([]byte{0xa2, 0x61, 'A', 0x41, 1, 0x61, 'B', 0x41, 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A", "B"}, keys)
+}
+
+func TestDecodeTrailingData(t *testing.T) {
+	data, err := cbor.Marshal(content{A: []byte{1}})
+	require.NoError(t, err)
+	_, _, err = decode(append(data, 0))
+	assert.Error(t, err)
+	_, _, err = decode(append(data, data...))
+	assert.Error(t, err)
+	_, err = Bytes([]byte{0x41, 1, 0})
+	assert.Error(t, err)
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"invalid UTF-8 key":      {0xa1, 0x61, 0xff, 0x41, 1},
+		"invalid simple value":   entry("A", []byte{0xf8, 0x10}),
+		"reserved information":   entry("A", []byte{0x1c}),
+		"indefinite integer":     entry("A", []byte{0x1f}),
+		"too many map entries":   {0xba, 0x00, 0x02, 0x00, 0x01},
+		"too many array entries": entry("A", []byte{0x9a, 0x00, 0x02, 0x00, 0x01}),
+	} {
+		_, _, err := decode(data)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "A", Key("A", "A", "B"))
+	assert.Equal(t, "A", Key("a", "A", "B"))
+	assert.Equal(t, "", Key("C", "A", "B"))
+	// an exact match takes precedence
+	assert.Equal(t, "a", Key("a", "A", "a"))
+
+	// as in cbor.Unmarshal
+	var v content
+	require.NoError(t, cbor.Unmarshal([]byte{0xa1, 0x61, 'b', 0x41, 1}, &v))
+	assert.Equal(t, []byte{1}, v.B)
+}
@@ -1,13 +1,14 @@
 package ot
 
 import (
-	"crypto/rand"
 	"errors"
+	"io"
 
 	"github.com/cronokirby/saferith"
 	"github.com/taurusgroup/multi-party-sig/internal/params"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 	"github.com/taurusgroup/multi-party-sig/pkg/pool"
 	"github.com/zeebo/blake3"
 )
@@ -58,7 +59,7 @@ func (r *CorreOTSetupSender) Round1(msg *CorreOTSetupReceiveRound1Message) (*Cor
 		return nil, err
 	}
 
-	_, _ = rand.Read(r._Delta[:])
+	_, _ = io.ReadFull(platform.Reader, r._Delta[:])
 
 	randomOTNonces := r.hash.Fork(&hash.BytesWithDomain{
 		TheDomain: "CorreOT Random OT Nonces",
@@ -1,14 +1,15 @@
 package ot
 
 import (
-	"crypto/rand"
 	"errors"
+	"io"
 
 	"github.com/cronokirby/saferith"
 	"github.com/taurusgroup/multi-party-sig/internal/params"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 )
 
 // scalarBytes returns the number of bytes needed to store a scalar in this group.
@@ -27,7 +28,7 @@ func encode(beta curve.Scalar, noise []curve.Scalar) ([]byte, error) {
 	group := beta.Curve()
 
 	gamma := make([]byte, len(noise)/8)
-	_, _ = rand.Read(gamma)
+	_, _ = io.ReadFull(platform.Reader, gamma)
 
 	acc := group.NewScalar().Set(beta)
 	mulNat := new(saferith.Nat)
@@ -92,7 +93,7 @@ func NewMultiplySender(ctxHash *hash.Hash, setup *CorreOTSendSetup, alpha curve.
 	gadget := makeGadget(ctxHash, group)
 	var doubleAlpha [2]curve.Scalar
 	doubleAlpha[0] = alpha
-	doubleAlpha[1] = sample.Scalar(rand.Reader, group)
+	doubleAlpha[1] = sample.Scalar(platform.Reader, group)
 	return &MultiplySender{
 		ctxHash:     ctxHash,
 		group:       group,
@@ -6,6 +6,10 @@ const (
 	OTParam   = 128
 	OTBytes   = OTParam / 8
 	StatParam = 80
+	// MinStatParam is the smallest statistical security parameter accepted by sessions and by the verifiers
+	// of repeated proofs such as zkmod and zkprm, since fewer iterations let a cheating prover succeed
+	// with probability 2^-iterations. Only dry runs, whose configs are insecure anyway, may use less.
+	MinStatParam = StatParam
 
 	// ZKModIterations is the number of iterations that are performed to prove the validity of
 	// a Paillier-Blum modulus N.
@@ -0,0 +1,52 @@
+package params
+
+import "fmt"
+
+// SecurityProfile sets the length of the random values and digests exchanged during a protocol execution.
+// All parties must use the same profile, since it changes the SSID.
+// The zero value is equivalent to DefaultSecurityProfile.
+type SecurityProfile struct {
+	// CommitmentBytes is the length of the randomness of hash commitments.
+	CommitmentBytes int
+	// RIDBytes is the length of the random identifiers agreed upon during keygen.
+	// Chain keys are not affected, since BIP32 requires them to be SecBytes long.
+	RIDBytes int
+	// DigestBytes is the length of hash outputs, such as commitments and the SSID.
+	DigestBytes int
+}
+
+// DefaultSecurityProfile is the profile used when none is given.
+var DefaultSecurityProfile = SecurityProfile{
+	CommitmentBytes: SecBytes,
+	RIDBytes:        SecBytes,
+	DigestBytes:     2 * SecBytes,
+}
+
+// OrDefault returns DefaultSecurityProfile if p is the zero value, and p otherwise.
+func (p SecurityProfile) OrDefault() SecurityProfile {
+	if p == (SecurityProfile{}) {
+		return DefaultSecurityProfile
+	}
+	return p
+}
+
+// IsDefault returns true if p is equivalent to DefaultSecurityProfile.
+func (p SecurityProfile) IsDefault() bool {
+	return p.OrDefault() == DefaultSecurityProfile
+}
+
+// Validate returns an error if any length of p gives less than OTParam bits of security.
+func (p SecurityProfile) Validate() error {
+	p = p.OrDefault()
+	if p.CommitmentBytes < OTBytes {
+		return fmt.Errorf("security profile: commitment randomness must be at least %d bytes, got %d", OTBytes, p.CommitmentBytes)
+	}
+	if p.RIDBytes < OTBytes {
+		return fmt.Errorf("security profile: RID must be at least %d bytes, got %d", OTBytes, p.RIDBytes)
+	}
+	// collision resistance requires twice as many bytes
+	if p.DigestBytes < 2*OTBytes {
+		return fmt.Errorf("security profile: digests must be at least %d bytes, got %d", 2*OTBytes, p.DigestBytes)
+	}
+	return nil
+}
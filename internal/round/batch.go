@@ -0,0 +1,302 @@
+package round
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// Batch runs several independent sessions of the same protocol in lockstep,
+// multiplexing all their messages for a given round and recipient into a single message.
+// This allows a batch of k executions to complete with the latency of a single one.
+//
+// Each session keeps its own state and hash, so that the executions remain independent.
+// The Batch itself provides the Helper used by the handler, in particular the SSID of the batch.
+type Batch struct {
+	*Helper
+	sessions []Session
+	// combine transforms the outputs of all sessions into the final result.
+	combine func([]interface{}) interface{}
+}
+
+// BatchBroadcast is a Batch whose sessions are all in a BroadcastRound.
+type BatchBroadcast struct {
+	*Batch
+}
+
+// batchContent holds the CBOR encoded contents of each session, in the order of the sessions.
+type batchContent struct {
+	Contents [][]byte
+	number   Number
+	// decoded is set by VerifyMessage so that StoreMessage does not decode the contents again.
+	decoded []Content
+}
+
+// batchBroadcastContent is the broadcast equivalent of batchContent.
+type batchBroadcastContent struct {
+	batchContent
+	reliable bool
+}
+
+// RoundNumber implements Content.
+func (c *batchContent) RoundNumber() Number { return c.number }
+
+// Reliable implements BroadcastContent.
+func (c *batchBroadcastContent) Reliable() bool { return c.reliable }
+
+// BatchSessionID derives the session ID of the i-th session in a batch from the batch's SSID.
+func BatchSessionID(ssid []byte, i int) []byte {
+	index := make([]byte, 4)
+	binary.BigEndian.PutUint32(index, uint32(i))
+	h := hash.New(
+		&hash.BytesWithDomain{TheDomain: "Batch SSID", Bytes: ssid},
+		&hash.BytesWithDomain{TheDomain: "Batch Index", Bytes: index},
+	)
+	return h.Sum()
+}
+
+// NewBatch returns a Session which runs all the given sessions in lockstep.
+// The sessions must all be in the same round, with the same participants, and should have been created
+// with session IDs derived from helper.SSID() using BatchSessionID.
+// Once all sessions have completed, combine is called with their results in order.
+// If combine is nil, the result is the []interface{} of outputs.
+func NewBatch(helper *Helper, sessions []Session, combine func([]interface{}) interface{}) (Session, error) {
+	if len(sessions) == 0 {
+		return nil, errors.New("batch: no sessions")
+	}
+	first := sessions[0]
+	for i, s := range sessions {
+		if s == nil {
+			return nil, fmt.Errorf("batch: session %d is nil", i)
+		}
+		if s.Number() != first.Number() || s.FinalRoundNumber() != first.FinalRoundNumber() {
+			return nil, fmt.Errorf("batch: session %d is not in the same round", i)
+		}
+		if s.SelfID() != helper.SelfID() || !partyIDsEqual(s.PartyIDs(), helper.PartyIDs()) {
+			return nil, fmt.Errorf("batch: session %d has different participants", i)
+		}
+	}
+	if combine == nil {
+		combine = func(results []interface{}) interface{} { return results }
+	}
+	return wrapBatch(&Batch{
+		Helper:   helper,
+		sessions: sessions,
+		combine:  combine,
+	}), nil
+}
+
+func wrapBatch(b *Batch) Session {
+	if _, ok := b.sessions[0].(BroadcastRound); ok {
+		return &BatchBroadcast{Batch: b}
+	}
+	return b
+}
+
+// Sessions returns the sessions of the batch, in order.
+func (b *Batch) Sessions() []Session { return b.sessions }
+
+// Number implements Round.
+func (b *Batch) Number() Number { return b.sessions[0].Number() }
+
+// FinalRoundNumber implements Session.
+func (b *Batch) FinalRoundNumber() Number { return b.sessions[0].FinalRoundNumber() }
+
+// MessageContent implements Round.
+func (b *Batch) MessageContent() Content {
+	if b.sessions[0].MessageContent() == nil {
+		return nil
+	}
+	return &batchContent{number: b.Number()}
+}
+
+// BroadcastContent implements BroadcastRound.
+func (b *BatchBroadcast) BroadcastContent() BroadcastContent {
+	content := b.sessions[0].(BroadcastRound).BroadcastContent()
+	if content == nil {
+		return nil
+	}
+	return &batchBroadcastContent{
+		batchContent: batchContent{number: b.Number()},
+		reliable:     content.Reliable(),
+	}
+}
+
+// StoreBroadcastMessage implements BroadcastRound.
+func (b *BatchBroadcast) StoreBroadcastMessage(msg Message) error {
+	content, ok := msg.Content.(*batchBroadcastContent)
+	if !ok || content == nil {
+		return ErrInvalidContent
+	}
+	if len(content.Contents) != len(b.sessions) {
+		return fmt.Errorf("batch: expected %d contents, got %d", len(b.sessions), len(content.Contents))
+	}
+	for i, s := range b.sessions {
+		r, ok := s.(BroadcastRound)
+		if !ok {
+			return fmt.Errorf("batch: session %d is not a broadcast round", i)
+		}
+		sub := r.BroadcastContent()
+		if err := UnmarshalContent(content.Contents[i], sub); err != nil {
+			return fmt.Errorf("batch: session %d: %w", i, err)
+		}
+		subMsg := msg
+		subMsg.Content = sub
+		if err := r.StoreBroadcastMessage(subMsg); err != nil {
+			return fmt.Errorf("batch: session %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// VerifyMessage implements Round.
+func (b *Batch) VerifyMessage(msg Message) error {
+	content, ok := msg.Content.(*batchContent)
+	if !ok || content == nil {
+		return ErrInvalidContent
+	}
+	if len(content.Contents) != len(b.sessions) {
+		return fmt.Errorf("batch: expected %d contents, got %d", len(b.sessions), len(content.Contents))
+	}
+	decoded := make([]Content, len(b.sessions))
+	for i, s := range b.sessions {
+		sub := s.MessageContent()
+		if err := UnmarshalContent(content.Contents[i], sub); err != nil {
+			return fmt.Errorf("batch: session %d: %w", i, err)
+		}
+		subMsg := msg
+		subMsg.Content = sub
+		if err := s.VerifyMessage(subMsg); err != nil {
+			return fmt.Errorf("batch: session %d: %w", i, err)
+		}
+		decoded[i] = sub
+	}
+	content.decoded = decoded
+	return nil
+}
+
+// StoreMessage implements Round.
+func (b *Batch) StoreMessage(msg Message) error {
+	content, ok := msg.Content.(*batchContent)
+	if !ok || content == nil || len(content.decoded) != len(b.sessions) {
+		return ErrInvalidContent
+	}
+	for i, s := range b.sessions {
+		subMsg := msg
+		subMsg.Content = content.decoded[i]
+		if err := s.StoreMessage(subMsg); err != nil {
+			return fmt.Errorf("batch: session %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+type batchKey struct {
+	to        party.ID
+	broadcast bool
+}
+
+// Finalize implements Round.
+//
+// Each session is finalized in order, and the messages they produce are grouped by recipient.
+// If any session aborts, the whole batch aborts.
+func (b *Batch) Finalize(out chan<- *Message) (Session, error) {
+	n := len(b.sessions)
+	next := make([]Session, n)
+	grouped := make(map[batchKey][]Content)
+	var order []batchKey
+	for i, s := range b.sessions {
+		subOut := make(chan *Message, b.N()+1)
+		r, err := s.Finalize(subOut)
+		close(subOut)
+		if err != nil {
+			return b, fmt.Errorf("batch: session %d: %w", i, err)
+		}
+		if abort, ok := r.(*Abort); ok {
+			return b.AbortRound(fmt.Errorf("batch: session %d: %w", i, abort.Err), abort.Culprits...), nil
+		}
+		next[i] = r
+		for msg := range subOut {
+			key := batchKey{to: msg.To, broadcast: msg.Broadcast}
+			contents, ok := grouped[key]
+			if !ok {
+				contents = make([]Content, n)
+				grouped[key] = contents
+				order = append(order, key)
+			}
+			if contents[i] != nil {
+				return b, fmt.Errorf("batch: session %d sent multiple messages to %q", i, msg.To)
+			}
+			contents[i] = msg.Content
+		}
+	}
+
+	// all sessions must have finished at the same time
+	if _, ok := next[0].(*Output); ok {
+		results := make([]interface{}, n)
+		for i, r := range next {
+			output, ok := r.(*Output)
+			if !ok {
+				return b, fmt.Errorf("batch: session %d did not finish", i)
+			}
+			results[i] = output.Result
+		}
+		return b.ResultRound(b.combine(results)), nil
+	}
+	for i, r := range next {
+		if r.Number() != next[0].Number() {
+			return b, fmt.Errorf("batch: session %d is not in the same round", i)
+		}
+	}
+
+	for _, key := range order {
+		contents := grouped[key]
+		encoded := make([][]byte, n)
+		for i, c := range contents {
+			if c == nil {
+				return b, fmt.Errorf("batch: session %d did not send a message to %q", i, key.to)
+			}
+			data, err := cbor.Marshal(c)
+			if err != nil {
+				return b, fmt.Errorf("batch: session %d: %w", i, err)
+			}
+			encoded[i] = data
+		}
+		content := batchContent{Contents: encoded, number: contents[0].RoundNumber()}
+		var err error
+		if key.broadcast {
+			reliable := false
+			if bc, ok := contents[0].(BroadcastContent); ok {
+				reliable = bc.Reliable()
+			}
+			err = b.BroadcastMessage(out, &batchBroadcastContent{batchContent: content, reliable: reliable})
+		} else {
+			err = b.SendMessage(out, &content, key.to)
+		}
+		if err != nil {
+			return b, err
+		}
+	}
+
+	return wrapBatch(&Batch{
+		Helper:   b.Helper,
+		sessions: next,
+		combine:  b.combine,
+	}), nil
+}
+
+func partyIDsEqual(a, b party.IDSlice) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,27 @@
+package round
+
+import (
+	"github.com/taurusgroup/multi-party-sig/pkg/elgamal"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
+)
+
+// SealFor encrypts payload to the party to, whose ElGamal public key is public,
+// so that it can be included in a message which is broadcast, or relayed by other parties.
+// The envelope is bound to this session, and to the sender and recipient, see OpenFrom.
+func (h *Helper) SealFor(to party.ID, public elgamal.PublicKey, payload []byte) (*elgamal.Envelope, error) {
+	return elgamal.Seal(platform.Reader, public, payload, h.envelopeData(h.SelfID(), to))
+}
+
+// OpenFrom decrypts an envelope sealed for us by the party from with SealFor, using our ElGamal secret key.
+func (h *Helper) OpenFrom(from party.ID, secret curve.Scalar, env *elgamal.Envelope) ([]byte, error) {
+	return elgamal.Open(secret, env, h.envelopeData(from, h.SelfID()))
+}
+
+// envelopeData returns the data authenticated along with an envelope sent from one party to another.
+// It only depends on the SSID, and not on the current hash state, which may differ between rounds.
+func (h *Helper) envelopeData(from, to party.ID) []byte {
+	return hash.New(hash.BytesWithDomain{TheDomain: "SSID", Bytes: h.SSID()}, from, to).Sum()
+}
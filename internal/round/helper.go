@@ -1,11 +1,13 @@
 package round
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"math"
 	"sync"
 
+	"github.com/taurusgroup/multi-party-sig/internal/params"
 	"github.com/taurusgroup/multi-party-sig/internal/types"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
@@ -31,9 +33,29 @@ type Helper struct {
 
 	hash *hash.Hash
 
+	// broadcasts[r][j] is the digest of the content broadcast by party j in round r, see BroadcastDigests.
+	broadcasts map[Number]map[party.ID][]byte
+
 	mtx sync.Mutex
 }
 
+// NewDescriptionHelper returns a Helper with placeholder session information, for rounds which are
+// only instantiated to describe their messages, see protocol.DescribeRounds.
+func NewDescriptionHelper(protocolID string, finalRound Number) *Helper {
+	helper, err := NewSession(Info{
+		ProtocolID:       protocolID,
+		FinalRoundNumber: finalRound,
+		SelfID:           "a",
+		PartyIDs:         []party.ID{"a", "b"},
+		Threshold:        1,
+		Group:            curve.Secp256k1{},
+	}, nil, nil)
+	if err != nil {
+		panic(err)
+	}
+	return helper
+}
+
 // NewSession creates a new *Helper which can be embedded in the first Round,
 // so that the full struct implements Session.
 // `sessionID` is an optional byte slice that can be provided by the user.
@@ -51,16 +73,62 @@ func NewSession(info Info, sessionID []byte, pl *pool.Pool, auxInfo ...hash.Writ
 		return nil, errors.New("session: selfID not included in partyIDs")
 	}
 
+	if info.FinalRoundNumber == 0 || !info.FinalRoundNumber.Valid() {
+		return nil, fmt.Errorf("session: final round number %d is out of range [1, %d]", info.FinalRoundNumber, MaxRounds)
+	}
+
 	// make sure the threshold is correct
 	if info.Threshold < 0 || info.Threshold > math.MaxUint32 {
 		return nil, fmt.Errorf("session: threshold %d is invalid", info.Threshold)
 	}
 
+	if err := info.Weights.Validate(); err != nil {
+		return nil, fmt.Errorf("session: %w", err)
+	}
+
 	// the number of users satisfies the threshold
-	if n := len(partyIDs); n <= 0 || info.Threshold > n-1 {
+	if n := info.Weights.Total(partyIDs); n <= 0 || info.Threshold > n-1 {
 		return nil, fmt.Errorf("session: threshold %d is invalid for number of parties %d", info.Threshold, n)
 	}
 
+	pending := party.NewIDSlice(info.Pending)
+	if !pending.Valid() {
+		return nil, errors.New("session: pending parties contain duplicates")
+	}
+	for _, id := range pending {
+		if partyIDs.Contains(id) {
+			return nil, fmt.Errorf("session: pending party %s is also a participant", id)
+		}
+	}
+	if len(pending) == 0 {
+		pending = nil
+	}
+	info.Pending = pending
+
+	// the IDs are used as evaluation points, and must therefore be distinct and non-zero scalars,
+	// including those of the pending parties, which will be used once they join
+	if info.Group != nil {
+		points := append(info.Weights.EvaluationPoints(partyIDs), pending...)
+		if err := party.NewIDSlice(points).ValidateScalars(info.Group); err != nil {
+			return nil, fmt.Errorf("session: %w", err)
+		}
+	}
+
+	if info.StatParam < 0 {
+		return nil, fmt.Errorf("session: statistical security parameter %d is invalid", info.StatParam)
+	}
+	if info.StatParam != 0 && info.StatParam < params.MinStatParam && !info.InsecureStatParam {
+		return nil, fmt.Errorf("session: statistical security parameter %d is below the minimum of %d", info.StatParam, params.MinStatParam)
+	}
+
+	if info.ChallengeVersion > hash.ChallengeV3 {
+		return nil, fmt.Errorf("session: unknown challenge version %d", info.ChallengeVersion)
+	}
+
+	if err := info.SecurityProfile.Validate(); err != nil {
+		return nil, fmt.Errorf("session: %w", err)
+	}
+
 	var err error
 	h := hash.New()
 
@@ -97,6 +165,60 @@ func NewSession(info Info, sessionID []byte, pl *pool.Pool, auxInfo ...hash.Writ
 		return nil, fmt.Errorf("session: %w", err)
 	}
 
+	// the default parameter is not written, so that existing SSIDs remain unchanged.
+	if info.StatParam != 0 && info.StatParam != params.StatParam {
+		statParam := make([]byte, 4)
+		binary.BigEndian.PutUint32(statParam, uint32(info.StatParam))
+		if err = h.WriteAny(&hash.BytesWithDomain{
+			TheDomain: "Statistical Security Parameter",
+			Bytes:     statParam,
+		}); err != nil {
+			return nil, fmt.Errorf("session: %w", err)
+		}
+	}
+
+	// sessions using a key which was never refreshed keep their SSID
+	if info.Epoch != 0 {
+		epoch := make([]byte, 8)
+		binary.BigEndian.PutUint64(epoch, info.Epoch)
+		if err = h.WriteAny(&hash.BytesWithDomain{
+			TheDomain: "Epoch",
+			Bytes:     epoch,
+		}); err != nil {
+			return nil, fmt.Errorf("session: %w", err)
+		}
+	}
+
+	// unweighted sessions keep their SSID
+	for _, id := range partyIDs {
+		weight := info.Weights.Of(id)
+		if weight == 1 {
+			continue
+		}
+		data := make([]byte, 4, 4+len(id))
+		binary.BigEndian.PutUint32(data, uint32(weight))
+		if err = h.WriteAny(&hash.BytesWithDomain{
+			TheDomain: "Weight",
+			Bytes:     append(data, id...),
+		}); err != nil {
+			return nil, fmt.Errorf("session: %w", err)
+		}
+	}
+
+	// sessions without pending parties keep their SSID
+	for _, id := range pending {
+		if err = h.WriteAny(&hash.BytesWithDomain{
+			TheDomain: "Pending",
+			Bytes:     []byte(id),
+		}); err != nil {
+			return nil, fmt.Errorf("session: %w", err)
+		}
+	}
+
+	// as for the statistical parameter, the default version does not change the SSID
+	h.SetChallengeVersion(info.ChallengeVersion)
+	h.SetSecurityProfile(info.SecurityProfile)
+
 	for _, a := range auxInfo {
 		if a == nil {
 			continue
@@ -137,8 +259,12 @@ func (h *Helper) UpdateHashState(value hash.WriterToWithDomain) {
 }
 
 // BroadcastMessage constructs a Message from the broadcast Content, and sets the header correctly.
+// The content is recorded as with StoreBroadcast.
 // An error is returned if the message cannot be sent to the out channel.
 func (h *Helper) BroadcastMessage(out chan<- *Message, broadcastContent Content) error {
+	if err := h.storeBroadcast(h.info.SelfID, broadcastContent); err != nil {
+		return err
+	}
 	msg := &Message{
 		From:      h.info.SelfID,
 		Broadcast: true,
@@ -170,6 +296,48 @@ func (h *Helper) SendMessage(out chan<- *Message, content Content, to party.ID)
 	}
 }
 
+// StoreBroadcast records the content of a broadcast message accepted by a round, so that it is included
+// in BroadcastDigests. It should be called by BroadcastRound.StoreBroadcastMessage once msg is verified.
+func (h *Helper) StoreBroadcast(msg Message) error {
+	return h.storeBroadcast(msg.From, msg.Content)
+}
+
+func (h *Helper) storeBroadcast(from party.ID, content Content) error {
+	data, err := canonicalEncMode.Marshal(content)
+	if err != nil {
+		return fmt.Errorf("session: broadcast of %s: %w", from, err)
+	}
+	digest := hash.New(&hash.BytesWithDomain{TheDomain: "Broadcast Content", Bytes: data}).Sum()
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if h.broadcasts == nil {
+		h.broadcasts = make(map[Number]map[party.ID][]byte)
+	}
+	number := content.RoundNumber()
+	if h.broadcasts[number] == nil {
+		h.broadcasts[number] = make(map[party.ID][]byte, len(h.partyIDs))
+	}
+	h.broadcasts[number][from] = digest
+	return nil
+}
+
+// BroadcastDigests returns, for every round in which broadcasts were recorded, the hash of the contents
+// broadcast by all parties in that round, bound to the SSID.
+// Parties which stored the same broadcasts obtain the same digests.
+func (h *Helper) BroadcastDigests() map[Number][]byte {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	digests := make(map[Number][]byte, len(h.broadcasts))
+	for number, contents := range h.broadcasts {
+		state := hash.New(&hash.BytesWithDomain{TheDomain: "SSID", Bytes: h.ssid}, number)
+		for _, id := range h.partyIDs {
+			_ = state.WriteAny(&hash.BytesWithDomain{TheDomain: "Message", Bytes: contents[id]})
+		}
+		digests[number] = state.Sum()
+	}
+	return digests
+}
+
 // Hash returns copy of the hash function of this protocol execution.
 func (h *Helper) Hash() *hash.Hash {
 	h.mtx.Lock()
@@ -217,8 +385,36 @@ func (h *Helper) OtherPartyIDs() party.IDSlice { return h.otherPartyIDs }
 // Threshold is the maximum number of parties that are assumed to be corrupted during the execution of this protocol.
 func (h *Helper) Threshold() int { return h.info.Threshold }
 
+// Weight returns the number of shares held by the party id, which is 1 unless the session uses a weighted sharing.
+func (h *Helper) Weight(id party.ID) int { return h.info.Weights.Of(id) }
+
+// Weights returns the number of shares held by each party, or nil if the session does not use a weighted sharing.
+func (h *Helper) Weights() party.Weights { return h.info.Weights }
+
+// Pending returns the sorted IDs of the parties which are expected to join the output of this protocol later.
+func (h *Helper) Pending() party.IDSlice { return h.info.Pending }
+
 // N returns the number of participants.
 func (h *Helper) N() int { return len(h.info.PartyIDs) }
 
 // Group returns the curve used for this protocol.
 func (h *Helper) Group() curve.Curve { return h.info.Group }
+
+// Epoch returns the number of refreshes of the key used by this protocol.
+func (h *Helper) Epoch() uint64 { return h.info.Epoch }
+
+// SecurityProfile returns the security profile agreed upon for this protocol execution.
+func (h *Helper) SecurityProfile() params.SecurityProfile {
+	return h.info.SecurityProfile.OrDefault()
+}
+
+// StatParam returns the statistical security parameter agreed upon for this protocol execution.
+func (h *Helper) StatParam() int {
+	if h.info.StatParam == 0 {
+		return params.StatParam
+	}
+	return h.info.StatParam
+}
+
+// InsecureStatParam returns true if StatParam may be below params.MinStatParam, see Info.InsecureStatParam.
+func (h *Helper) InsecureStatParam() bool { return h.info.InsecureStatParam }
@@ -1,11 +1,18 @@
 package round_test
 
 import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
 	"testing"
 
+	"github.com/taurusgroup/multi-party-sig/internal/params"
 	"github.com/taurusgroup/multi-party-sig/internal/round"
 	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/party"
 )
 
@@ -87,6 +94,42 @@ func TestNewSession(t *testing.T) {
 			curve.Secp256k1{},
 			true,
 		},
+		{
+			"zero scalar ID",
+			RNumber,
+			selfID,
+			append(partyIDs, "\x00"),
+			T,
+			curve.Secp256k1{},
+			true,
+		},
+		{
+			"colliding scalar IDs",
+			RNumber,
+			selfID,
+			append(partyIDs, "\x01", "\x00\x01"),
+			T,
+			curve.Secp256k1{},
+			true,
+		},
+		{
+			"zero final round",
+			0,
+			selfID,
+			partyIDs,
+			T,
+			curve.Secp256k1{},
+			true,
+		},
+		{
+			"final round out of range",
+			round.MaxRounds + 1,
+			selfID,
+			partyIDs,
+			T,
+			curve.Secp256k1{},
+			true,
+		},
 		{
 			"no group",
 			RNumber,
@@ -114,3 +157,265 @@ func TestNewSession(t *testing.T) {
 		})
 	}
 }
+
+func TestNewSessionStatParam(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	info := round.Info{
+		ProtocolID:       "TEST",
+		FinalRoundNumber: 5,
+		SelfID:           partyIDs[0],
+		PartyIDs:         partyIDs,
+		Threshold:        1,
+		Group:            curve.Secp256k1{},
+	}
+	defaultHelper, err := round.NewSession(info, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info.StatParam = params.StatParam
+	explicitHelper, err := round.NewSession(info, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(defaultHelper.SSID(), explicitHelper.SSID()) {
+		t.Error("default statistical parameter should not change the SSID")
+	}
+
+	info.StatParam = 2 * params.StatParam
+	customHelper, err := round.NewSession(info, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if customHelper.StatParam() != 2*params.StatParam {
+		t.Errorf("expected StatParam %d, got %d", 2*params.StatParam, customHelper.StatParam())
+	}
+	if bytes.Equal(defaultHelper.SSID(), customHelper.SSID()) {
+		t.Error("custom statistical parameter should change the SSID")
+	}
+
+	info.StatParam = params.MinStatParam - 1
+	if _, err = round.NewSession(info, nil, nil); err == nil {
+		t.Error("statistical parameter below the minimum should be rejected")
+	}
+	info.InsecureStatParam = true
+	if _, err = round.NewSession(info, nil, nil); err != nil {
+		t.Errorf("insecure statistical parameter should be accepted: %v", err)
+	}
+	info.InsecureStatParam = false
+
+	info.StatParam = -1
+	if _, err = round.NewSession(info, nil, nil); err == nil {
+		t.Error("negative statistical parameter should be rejected")
+	}
+
+	info.StatParam = 0
+	info.ChallengeVersion = hash.ChallengeV2
+	wideHelper, err := round.NewSession(info, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(defaultHelper.SSID(), wideHelper.SSID()) {
+		t.Error("challenge version should change the SSID")
+	}
+	if !wideHelper.HashForID(partyIDs[1]).WideChallenges() {
+		t.Error("session hash should use the challenge version")
+	}
+
+	info.ChallengeVersion = hash.ChallengeV3 + 1
+	if _, err = round.NewSession(info, nil, nil); err == nil {
+		t.Error("unknown challenge version should be rejected")
+	}
+}
+
+func TestNewSessionSecurityProfile(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	info := round.Info{
+		ProtocolID:       "TEST",
+		FinalRoundNumber: 5,
+		SelfID:           partyIDs[0],
+		PartyIDs:         partyIDs,
+		Threshold:        1,
+		Group:            curve.Secp256k1{},
+	}
+	defaultHelper, err := round.NewSession(info, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info.SecurityProfile = params.DefaultSecurityProfile
+	explicitHelper, err := round.NewSession(info, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(defaultHelper.SSID(), explicitHelper.SSID()) {
+		t.Error("default security profile should not change the SSID")
+	}
+
+	info.SecurityProfile = params.SecurityProfile{CommitmentBytes: 48, RIDBytes: 48, DigestBytes: 96}
+	customHelper, err := round.NewSession(info, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := len(customHelper.SSID()); l != 96 {
+		t.Errorf("expected SSID of 96 bytes, got %d", l)
+	}
+	h := customHelper.HashForID(partyIDs[0])
+	c, d, err := h.Commit([]byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c) != 96 || len(d) != 48 {
+		t.Errorf("expected commitment of 96 bytes and decommitment of 48 bytes, got %d and %d", len(c), len(d))
+	}
+	if !customHelper.HashForID(partyIDs[0]).Decommit(c, d, []byte("data")) {
+		t.Error("failed to decommit with the same profile")
+	}
+	if defaultHelper.HashForID(partyIDs[0]).Decommit(c, d, []byte("data")) {
+		t.Error("decommitted with a different profile")
+	}
+
+	info.SecurityProfile = params.SecurityProfile{CommitmentBytes: 8, RIDBytes: 32, DigestBytes: 64}
+	if _, err = round.NewSession(info, nil, nil); err == nil {
+		t.Error("short commitment randomness should be rejected")
+	}
+}
+
+func TestValidateScalars(t *testing.T) {
+	group := curve.Secp256k1{}
+	if err := test.PartyIDs(10).ValidateScalars(group); err != nil {
+		t.Fatal(err)
+	}
+
+	partyIDs := party.NewIDSlice([]party.ID{"\x00", "\x01", "\x00\x01", "\x00\x00\x01", "b"})
+	err := partyIDs.ValidateScalars(group)
+	var collision *party.ScalarCollisionError
+	if !errors.As(err, &collision) {
+		t.Fatalf("expected ScalarCollisionError, got %v", err)
+	}
+	if len(collision.Zero) != 1 || collision.Zero[0] != "\x00" {
+		t.Errorf("unexpected zero IDs %v", collision.Zero)
+	}
+	if len(collision.Collisions) != 1 || len(collision.Collisions[0]) != 3 {
+		t.Errorf("unexpected collisions %v", collision.Collisions)
+	}
+}
+
+func TestNumber(t *testing.T) {
+	if _, err := round.NewNumber(-1); err == nil {
+		t.Error("negative number should be rejected")
+	}
+	if _, err := round.NewNumber(int(round.MaxRounds) + 1); err == nil {
+		t.Error("number larger than MaxRounds should be rejected")
+	}
+	if n, err := round.NewNumber(3); err != nil || n != 3 {
+		t.Error("valid number should be accepted", err)
+	}
+
+	if _, ok := round.Number(0).Prev(); ok {
+		t.Error("the output round has no previous round")
+	}
+	if prev, ok := round.Number(3).Prev(); !ok || prev != 2 {
+		t.Error("wrong previous round")
+	}
+	if _, ok := round.MaxRounds.Next(); ok {
+		t.Error("MaxRounds has no next round")
+	}
+	if next, ok := round.Number(3).Next(); !ok || next != 4 {
+		t.Error("wrong next round")
+	}
+
+	var n round.Number
+	if err := json.Unmarshal([]byte("7"), &n); err != nil || n != 7 {
+		t.Error("valid number should be unmarshalled", err)
+	}
+	for _, data := range []string{"-1", "256", "65537", "\"1\""} {
+		if err := json.Unmarshal([]byte(data), &n); err == nil {
+			t.Errorf("%s should be rejected", data)
+		}
+	}
+}
+
+func TestSealFor(t *testing.T) {
+	group := curve.Secp256k1{}
+	partyIDs := test.PartyIDs(2)
+	helpers := make([]*round.Helper, len(partyIDs))
+	for i, id := range partyIDs {
+		h, err := round.NewSession(round.Info{
+			ProtocolID:       "TEST",
+			FinalRoundNumber: 2,
+			SelfID:           id,
+			PartyIDs:         partyIDs,
+			Threshold:        1,
+			Group:            group,
+		}, []byte("session"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		helpers[i] = h
+	}
+	secret := sample.Scalar(rand.Reader, group)
+	payload := []byte("payload")
+
+	env, err := helpers[0].SealFor(partyIDs[1], secret.ActOnBase(), payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened, err := helpers[1].OpenFrom(partyIDs[0], secret, env)
+	if err != nil || !bytes.Equal(opened, payload) {
+		t.Error("recipient should open the envelope", err)
+	}
+	// the envelope is bound to the sender and recipient
+	if _, err = helpers[0].OpenFrom(partyIDs[1], secret, env); err == nil {
+		t.Error("envelope should not open with swapped parties")
+	}
+}
+
+type testBroadcast struct {
+	round.NormalBroadcastContent
+	Value []byte
+}
+
+func (testBroadcast) RoundNumber() round.Number { return 2 }
+
+func TestBroadcastDigests(t *testing.T) {
+	partyIDs := test.PartyIDs(2)
+	helpers := make([]*round.Helper, len(partyIDs))
+	for i, id := range partyIDs {
+		h, err := round.NewSession(round.Info{
+			ProtocolID:       "TEST",
+			FinalRoundNumber: 2,
+			SelfID:           id,
+			PartyIDs:         partyIDs,
+			Threshold:        1,
+			Group:            curve.Secp256k1{},
+		}, []byte("session"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		helpers[i] = h
+	}
+	out := make(chan *round.Message, 2)
+	for i, h := range helpers {
+		if err := h.BroadcastMessage(out, &testBroadcast{Value: []byte{byte(i)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sent := []*round.Message{<-out, <-out}
+	for i, h := range helpers {
+		if err := h.StoreBroadcast(*sent[1-i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, b := helpers[0].BroadcastDigests(), helpers[1].BroadcastDigests()
+	if len(a) != 1 || !bytes.Equal(a[2], b[2]) {
+		t.Error("parties which stored the same broadcasts should have the same digests")
+	}
+
+	if err := helpers[1].StoreBroadcast(round.Message{From: partyIDs[0], Content: &testBroadcast{Value: []byte{7}}}); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(a[2], helpers[1].BroadcastDigests()[2]) {
+		t.Error("a different broadcast should change the digest")
+	}
+}
@@ -1,6 +1,7 @@
 package round
 
 import (
+	"github.com/fxamacker/cbor/v2"
 	"github.com/taurusgroup/multi-party-sig/pkg/party"
 )
 
@@ -17,6 +18,26 @@ type BroadcastContent interface {
 	Reliable() bool
 }
 
+// ContentUnmarshaler is implemented by contents which decode their CBOR encoding without reflection,
+// because they are received often enough for its cost to matter.
+// UnmarshalContent must accept the encoding produced by cbor.Marshal, and leave the content in the same
+// state as cbor.Unmarshal would.
+type ContentUnmarshaler interface {
+	UnmarshalContent(data []byte) error
+}
+
+// UnmarshalContent decodes the CBOR encoding of a content into content, which is returned by
+// Session.MessageContent or BroadcastRound.BroadcastContent.
+//
+// Contents are allocated for every message rather than taken from a pool,
+// since rounds keep references to the contents they store.
+func UnmarshalContent(data []byte, content Content) error {
+	if u, ok := content.(ContentUnmarshaler); ok {
+		return u.UnmarshalContent(data)
+	}
+	return cbor.Unmarshal(data, content)
+}
+
 // These structs can be embedded in a broadcast message as a way of
 // 1. implementing BroadcastContent
 // 2. indicate to the handler whether the content should be reliably broadcast
@@ -34,3 +55,13 @@ type Message struct {
 	Broadcast bool
 	Content   Content
 }
+
+// canonicalEncMode encodes contents with sorted map keys, so that the same content always has the same digest,
+// see Helper.BroadcastDigests.
+var canonicalEncMode = func() cbor.EncMode {
+	em, err := cbor.CanonicalEncOptions().EncMode()
+	if err != nil {
+		panic(err)
+	}
+	return em
+}()
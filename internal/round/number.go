@@ -2,6 +2,8 @@ package round
 
 import (
 	"encoding/binary"
+	"encoding/json"
+	"fmt"
 	"io"
 )
 
@@ -9,6 +11,53 @@ import (
 // 0 indicates the output round, 1 is the first round.
 type Number uint16
 
+// MaxRounds is the largest number of rounds of a protocol, and therefore the largest valid Number.
+// It leaves enough room so that Next never wraps around.
+const MaxRounds Number = 255
+
+// NewNumber returns n as a Number, or an error if it is out of the range [0, MaxRounds].
+func NewNumber(n int) (Number, error) {
+	if n < 0 || n > int(MaxRounds) {
+		return 0, fmt.Errorf("round: number %d is out of range [0, %d]", n, MaxRounds)
+	}
+	return Number(n), nil
+}
+
+// Valid returns true if i is at most MaxRounds.
+func (i Number) Valid() bool {
+	return i <= MaxRounds
+}
+
+// Prev returns the number of the round preceding i, and false if i is the output round 0.
+func (i Number) Prev() (Number, bool) {
+	if i == 0 || !i.Valid() {
+		return 0, false
+	}
+	return i - 1, true
+}
+
+// Next returns the number of the round following i, and false if i is MaxRounds or larger.
+func (i Number) Next() (Number, bool) {
+	if i >= MaxRounds {
+		return 0, false
+	}
+	return i + 1, true
+}
+
+// UnmarshalJSON implements json.Unmarshaler, and rejects numbers larger than MaxRounds.
+func (i *Number) UnmarshalJSON(data []byte) error {
+	var n int
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("round: number: %w", err)
+	}
+	number, err := NewNumber(n)
+	if err != nil {
+		return err
+	}
+	*i = number
+	return nil
+}
+
 // WriteTo implements io.WriterTo interface.
 func (i Number) WriteTo(w io.Writer) (int64, error) {
 	err := binary.Write(w, binary.BigEndian, uint64(i))
@@ -1,5 +1,7 @@
 package round
 
+import "github.com/taurusgroup/multi-party-sig/pkg/party"
+
 type Round interface {
 	// VerifyMessage handles an incoming Message and validates its content with regard to the protocol specification.
 	// The content argument can be cast to the appropriate type for this round without error check.
@@ -52,3 +54,22 @@ type BroadcastRound interface {
 	// Round must be implemented by an inherited round which would otherwise function the same way.
 	Round
 }
+
+// DependentRound extends Round in that it declares which parties' normal messages Finalize depends on.
+// The handler finalizes the round, and emits the messages of the next round, as soon as the messages
+// of these parties have been stored, instead of waiting for all other parties.
+// This is useful over high-latency links for rounds whose output only depends on some of the inputs.
+//
+// The dependencies only apply to normal messages; when combined with BroadcastRound, all broadcast messages
+// are still required so that the echo broadcast hash is consistent. Messages arriving after the round was
+// finalized are dropped, and Finalize must therefore only rely on the messages which were actually stored.
+//
+// The method is inherited by any round which embeds this one,
+// so subsequent rounds which require all messages should define DependsOn to return nil.
+type DependentRound interface {
+	// DependsOn returns the other parties whose message is required before the round can be finalized.
+	// A nil slice indicates that all messages are required, and an empty one that none are.
+	DependsOn() []party.ID
+	// Round must be implemented by an inherited round which would otherwise function the same way.
+	Round
+}
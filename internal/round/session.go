@@ -1,6 +1,7 @@
 package round
 
 import (
+	"github.com/taurusgroup/multi-party-sig/internal/params"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/party"
@@ -19,6 +20,32 @@ type Info struct {
 	Threshold int
 	// Group returns the group used for this protocol execution.
 	Group curve.Curve
+	// StatParam is the statistical security parameter, i.e. the number of Fiat-Shamir iterations
+	// used by repeated proofs such as zkmod and zkprm.
+	// All parties must agree on this value, since it is included in the SSID.
+	// If zero, params.StatParam is used. Values below params.MinStatParam are rejected, unless InsecureStatParam is set.
+	StatParam int
+	// InsecureStatParam allows StatParam to be below params.MinStatParam.
+	// It is only meant for dry runs and tests, whose proofs are not sound.
+	InsecureStatParam bool
+	// ChallengeVersion selects how zero-knowledge proofs derive their challenges from the session's hash.
+	// The zero value is hash.ChallengeV1.
+	ChallengeVersion hash.ChallengeVersion
+	// SecurityProfile sets the length of commitment randomness, RIDs and digests.
+	// The zero value is params.DefaultSecurityProfile.
+	SecurityProfile params.SecurityProfile
+	// Epoch is the number of refreshes of the key used by this protocol, see config.Config.Epoch.
+	// All parties must agree on this value, since it is included in the SSID when it is not zero.
+	Epoch uint64
+	// Weights is the number of shares held by each party, for protocols using a weighted threshold sharing.
+	// The threshold then applies to the total weight of the parties rather than their number.
+	// All parties must agree on this value, since the weights different from 1 are included in the SSID.
+	// If nil, every party holds a single share.
+	Weights party.Weights
+	// Pending contains parties which do not take part in this protocol, but are expected to join its output later,
+	// such as the invitees of a keygen which was restarted without them. Their IDs must be distinct from PartyIDs.
+	// All parties must agree on this value, since it is included in the SSID when it is not empty.
+	Pending []party.ID
 }
 
 // Session represents the current execution of a round-based protocol.
@@ -46,4 +73,6 @@ type Session interface {
 	Threshold() int
 	// N returns the total number of parties participating in the protocol.
 	N() int
+	// Epoch is the number of refreshes of the key used by this protocol.
+	Epoch() uint64
 }
@@ -16,6 +16,12 @@ import (
 
 // GenerateConfig creates some random configuration for N parties with set threshold T over the group.
 func GenerateConfig(group curve.Curve, N, T int, source io.Reader, pl *pool.Pool) (map[party.ID]*config.Config, party.IDSlice) {
+	return GenerateWeightedConfig(group, N, T, nil, source, pl)
+}
+
+// GenerateWeightedConfig is the same as GenerateConfig, but gives each party the number of shares set in weights,
+// and T applies to their total weight. The weights are indexed by the IDs returned by PartyIDs(N).
+func GenerateWeightedConfig(group curve.Curve, N, T int, weights party.Weights, source io.Reader, pl *pool.Pool) (map[party.ID]*config.Config, party.IDSlice) {
 	partyIDs := PartyIDs(N)
 	configs := make(map[party.ID]*config.Config, N)
 	public := make(map[party.ID]*config.Public, N)
@@ -38,11 +44,19 @@ func GenerateConfig(group curve.Curve, N, T int, source io.Reader, pl *pool.Pool
 		elGamalSecret := sample.Scalar(source, group)
 
 		ecdsaSecret := f.Evaluate(pid.Scalar(group))
+		var extraSecrets []curve.Scalar
+		var extraPublic []curve.Point
+		for _, extraID := range pid.WeightedIDs(weights.Of(pid))[1:] {
+			extra := f.Evaluate(extraID.Scalar(group))
+			extraSecrets = append(extraSecrets, extra)
+			extraPublic = append(extraPublic, extra.ActOnBase())
+		}
 		configs[pid] = &config.Config{
 			Group:     group,
 			ID:        pid,
 			Threshold: T,
 			ECDSA:     ecdsaSecret,
+			Shares:    extraSecrets,
 			ElGamal:   elGamalSecret,
 			Paillier:  paillierSecret,
 			RID:       rid.Copy(),
@@ -55,6 +69,7 @@ func GenerateConfig(group curve.Curve, N, T int, source io.Reader, pl *pool.Pool
 			ElGamal:  elGamalSecret.ActOnBase(),
 			Paillier: paillierSecret.PublicKey,
 			Pedersen: pedersenPublic,
+			Shares:   extraPublic,
 		}
 	}
 	return configs, partyIDs
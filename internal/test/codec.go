@@ -0,0 +1,88 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+)
+
+// Codec serializes protocol messages, as done by a transport between parties.
+type Codec struct {
+	Name      string
+	Marshal   func(msg *protocol.Message) ([]byte, error)
+	Unmarshal func(data []byte) (*protocol.Message, error)
+}
+
+// Codecs contains the serializations of protocol.Message used by integrators:
+// the CBOR encoding of MarshalBinary, and the default JSON encoding of the struct.
+var Codecs = []Codec{
+	{
+		Name:    "cbor",
+		Marshal: func(msg *protocol.Message) ([]byte, error) { return msg.MarshalBinary() },
+		Unmarshal: func(data []byte) (*protocol.Message, error) {
+			msg := new(protocol.Message)
+			return msg, msg.UnmarshalBinary(data)
+		},
+	},
+	{
+		Name:    "json",
+		Marshal: func(msg *protocol.Message) ([]byte, error) { return json.Marshal(msg) },
+		Unmarshal: func(data []byte) (*protocol.Message, error) {
+			msg := new(protocol.Message)
+			return msg, json.Unmarshal(data, msg)
+		},
+	},
+}
+
+// RoundTrip serializes msg with every codec in Codecs, restores it, and checks that all restored messages
+// are equivalent to msg: they must have the same hash, and be encoded identically when serialized again.
+// It returns the message restored by the last codec, which can then be delivered in place of msg.
+func RoundTrip(msg *protocol.Message) (*protocol.Message, error) {
+	expectedHash := msg.Hash()
+	var restored *protocol.Message
+	for _, codec := range Codecs {
+		data, err := codec.Marshal(msg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: marshal %v: %w", codec.Name, msg, err)
+		}
+		if restored, err = codec.Unmarshal(data); err != nil {
+			return nil, fmt.Errorf("%s: unmarshal %v: %w", codec.Name, msg, err)
+		}
+		if !bytes.Equal(expectedHash, restored.Hash()) {
+			return nil, fmt.Errorf("%s: %v changed after round trip", codec.Name, msg)
+		}
+		again, err := codec.Marshal(restored)
+		if err != nil {
+			return nil, fmt.Errorf("%s: marshal restored %v: %w", codec.Name, msg, err)
+		}
+		if !bytes.Equal(data, again) {
+			return nil, fmt.Errorf("%s: %v is encoded differently after round trip", codec.Name, msg)
+		}
+	}
+	return restored, nil
+}
+
+var canonicalEncoding, _ = cbor.CanonicalEncOptions().EncMode()
+
+// ContentRoundTrip checks that restored, obtained by unmarshalling the CBOR encoding of original,
+// is equivalent to it. Both contents are compared through their canonical CBOR encoding,
+// so that asymmetries between the Marshal and Unmarshal methods of a content's fields are detected.
+func ContentRoundTrip(original, restored round.Content) error {
+	expected, err := canonicalEncoding.Marshal(original)
+	if err != nil {
+		return fmt.Errorf("round %d: marshal %s: %w", original.RoundNumber(), reflect.TypeOf(original), err)
+	}
+	actual, err := canonicalEncoding.Marshal(restored)
+	if err != nil {
+		return fmt.Errorf("round %d: marshal restored %s: %w", original.RoundNumber(), reflect.TypeOf(original), err)
+	}
+	if !bytes.Equal(expected, actual) {
+		return fmt.Errorf("round %d: %s changed after round trip", original.RoundNumber(), reflect.TypeOf(original))
+	}
+	return nil
+}
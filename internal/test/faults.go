@@ -0,0 +1,115 @@
+package test
+
+import (
+	"github.com/cronokirby/saferith"
+	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
+	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
+)
+
+// Fault describes how a FaultyEncrypter or FaultyCommitter deviates from the honest implementation.
+type Fault uint8
+
+const (
+	// FaultNone behaves honestly.
+	FaultNone Fault = iota
+	// FaultBitFlip flips the lowest bit of the ciphertext or commitment.
+	FaultBitFlip
+	// FaultWrongNonce uses a different nonce than the one returned or given to EncWithNonce,
+	// or commits with a different second value.
+	FaultWrongNonce
+	// FaultOutOfRange adds Offset to the plaintext or committed value,
+	// so that it is outside of the range expected by the protocol.
+	FaultOutOfRange
+)
+
+// defaultOffset is used by FaultOutOfRange when no Offset is set. It is larger than the order of all supported groups.
+var defaultOffset = new(saferith.Int).SetNat(new(saferith.Nat).Lsh(new(saferith.Nat).SetUint64(1), 256, -1))
+
+// FaultyEncrypter is a paillier.Encrypter which produces invalid ciphertexts under Key,
+// to simulate a malicious party without crafting the corrupted messages by hand.
+type FaultyEncrypter struct {
+	Key   *paillier.PublicKey
+	Fault Fault
+	// Offset is added to plaintexts by FaultOutOfRange, or 2²⁵⁶ if nil.
+	Offset *saferith.Int
+}
+
+// Enc implements paillier.Encrypter.
+func (e *FaultyEncrypter) Enc(m *saferith.Int) (*paillier.Ciphertext, *saferith.Nat) {
+	ct, nonce := e.Key.Enc(e.plaintext(m))
+	switch e.Fault {
+	case FaultBitFlip:
+		ct = flipCiphertext(ct)
+	case FaultWrongNonce:
+		_, nonce = e.Key.Enc(m)
+	}
+	return ct, nonce
+}
+
+// EncWithNonce implements paillier.Encrypter.
+func (e *FaultyEncrypter) EncWithNonce(m *saferith.Int, nonce *saferith.Nat) *paillier.Ciphertext {
+	switch e.Fault {
+	case FaultBitFlip:
+		return flipCiphertext(e.Key.EncWithNonce(m, nonce))
+	case FaultWrongNonce:
+		ct, _ := e.Key.Enc(m)
+		return ct
+	}
+	return e.Key.EncWithNonce(e.plaintext(m), nonce)
+}
+
+func (e *FaultyEncrypter) plaintext(m *saferith.Int) *saferith.Int {
+	if e.Fault != FaultOutOfRange {
+		return m
+	}
+	return faultOffset(m, e.Offset)
+}
+
+// FaultyCommitter is a pedersen.Committer which produces invalid commitments under Parameters.
+type FaultyCommitter struct {
+	Parameters *pedersen.Parameters
+	Fault      Fault
+	// Offset is added to the committed value by FaultOutOfRange, or 2²⁵⁶ if nil.
+	Offset *saferith.Int
+}
+
+// Commit implements pedersen.Committer.
+func (c *FaultyCommitter) Commit(x, y *saferith.Int) *saferith.Nat {
+	switch c.Fault {
+	case FaultBitFlip:
+		commitment := c.Parameters.Commit(x, y)
+		return flipBit(commitment)
+	case FaultWrongNonce:
+		return c.Parameters.Commit(x, new(saferith.Int).Add(y, new(saferith.Int).SetUint64(1), -1))
+	case FaultOutOfRange:
+		return c.Parameters.Commit(faultOffset(x, c.Offset), y)
+	}
+	return c.Parameters.Commit(x, y)
+}
+
+func faultOffset(m, offset *saferith.Int) *saferith.Int {
+	if offset == nil {
+		offset = defaultOffset
+	}
+	return new(saferith.Int).Add(m, offset, -1)
+}
+
+func flipBit(n *saferith.Nat) *saferith.Nat {
+	mask := new(saferith.Nat).SetUint64(1)
+	if n.Byte(0)&1 == 1 {
+		return new(saferith.Nat).Sub(n, mask, -1)
+	}
+	return new(saferith.Nat).Add(n, mask, -1)
+}
+
+func flipCiphertext(ct *paillier.Ciphertext) *paillier.Ciphertext {
+	data, err := flipBit(ct.Nat()).MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	out := new(paillier.Ciphertext)
+	if err = out.UnmarshalBinary(data); err != nil {
+		panic(err)
+	}
+	return out
+}
@@ -15,6 +15,8 @@ type Network struct {
 	listenChannels   map[party.ID]chan *protocol.Message
 	done             chan struct{}
 	closedListenChan chan *protocol.Message
+	roundTrip        bool
+	errs             []error
 	mtx              sync.Mutex
 }
 
@@ -29,6 +31,21 @@ func NewNetwork(parties party.IDSlice) *Network {
 	return c
 }
 
+// NewRoundTripNetwork is the same as NewNetwork, but every message is serialized and restored with all Codecs
+// before being delivered, see RoundTrip. Failures are returned by Errors.
+func NewRoundTripNetwork(parties party.IDSlice) *Network {
+	n := NewNetwork(parties)
+	n.roundTrip = true
+	return n
+}
+
+// Errors returns the errors encountered by a network created with NewRoundTripNetwork.
+func (n *Network) Errors() []error {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return append([]error(nil), n.errs...)
+}
+
 func (n *Network) init() {
 	N := len(n.parties)
 	for _, id := range n.parties {
@@ -53,6 +70,14 @@ func (n *Network) Next(id party.ID) <-chan *protocol.Message {
 func (n *Network) Send(msg *protocol.Message) {
 	n.mtx.Lock()
 	defer n.mtx.Unlock()
+	if n.roundTrip {
+		restored, err := RoundTrip(msg)
+		if err != nil {
+			n.errs = append(n.errs, err)
+		} else {
+			msg = restored
+		}
+	}
 	for id, c := range n.listenChannels {
 		if msg.IsFor(id) && c != nil {
 			n.listenChannels[id] <- msg
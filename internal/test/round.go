@@ -99,7 +99,10 @@ func Rounds(rounds []round.Session, rule Rule) (error, bool) {
 						return errors.New("broadcast message but not broadcast round")
 					}
 					m.Content = b.BroadcastContent()
-					if err = cbor.Unmarshal(msgBytes, m.Content); err != nil {
+					if err = round.UnmarshalContent(msgBytes, m.Content); err != nil {
+						return err
+					}
+					if err = ContentRoundTrip(msg.Content, m.Content); err != nil {
 						return err
 					}
 
@@ -108,7 +111,10 @@ func Rounds(rounds []round.Session, rule Rule) (error, bool) {
 					}
 				} else {
 					m.Content = r.MessageContent()
-					if err = cbor.Unmarshal(msgBytes, m.Content); err != nil {
+					if err = round.UnmarshalContent(msgBytes, m.Content); err != nil {
+						return err
+					}
+					if err = ContentRoundTrip(msg.Content, m.Content); err != nil {
 						return err
 					}
 
@@ -0,0 +1,259 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+)
+
+// Transcript is the record of a protocol execution: for every party, the randomness it read, the messages it received
+// in the order they were delivered, the messages it emitted and its result.
+// Replaying the transcript of a party feeds it the same randomness and messages, and checks that it emits
+// the same messages and result byte for byte, so that a transcript recorded before a refactor freezes the
+// behaviour of the protocol.
+//
+// Randomness is injected by replacing platform.Reader, so Record and Replay must not run concurrently
+// with other code reading it, such as parallel tests. The handlers are created with DeterministicOutput,
+// and the protocol must not use a pool.Pool, since work done in parallel reads randomness in an arbitrary order.
+type Transcript struct {
+	SessionID []byte
+	Parties   map[party.ID]*PartyTranscript
+}
+
+// PartyTranscript is the part of a Transcript recorded for a single party.
+type PartyTranscript struct {
+	// Randomness is the concatenation of all bytes read from platform.Reader by the party.
+	Randomness []byte
+	// Received and Sent are the messages received and emitted by the party, encoded with Message.MarshalBinary.
+	Received [][]byte
+	Sent     [][]byte
+	// Result is the canonical CBOR encoding of the result.
+	Result []byte
+}
+
+var (
+	// randMtx serializes the executions which replace platform.Reader.
+	randMtx       sync.Mutex
+	canonicalMode cbor.EncMode
+)
+
+func init() {
+	var err error
+	if canonicalMode, err = cbor.CanonicalEncOptions().EncMode(); err != nil {
+		panic(err)
+	}
+}
+
+// switchReader reads from the randomness of the party currently handling a message.
+type switchReader struct {
+	r io.Reader
+}
+
+func (s *switchReader) Read(p []byte) (int, error) { return s.r.Read(p) }
+
+// recordingReader reads from r and appends all bytes read to out.
+type recordingReader struct {
+	r   io.Reader
+	out *[]byte
+}
+
+func (r *recordingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	*r.out = append(*r.out, p[:n]...)
+	return n, err
+}
+
+// replayReader returns the recorded randomness. Once it is exhausted, it returns zeros and counts the missing bytes,
+// so that a diverging replay is reported by Replay rather than by a panic in the protocol.
+type replayReader struct {
+	data    []byte
+	overrun int
+}
+
+func (r *replayReader) Read(p []byte) (int, error) {
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	for i := n; i < len(p); i++ {
+		p[i] = 0
+	}
+	r.overrun += len(p) - n
+	return len(p), nil
+}
+
+// withRandomness replaces platform.Reader by src while f runs, and passes the original reader to f.
+func withRandomness(src *switchReader, f func(original io.Reader) error) error {
+	randMtx.Lock()
+	defer randMtx.Unlock()
+	original := platform.Reader
+	platform.Reader = src
+	defer func() { platform.Reader = original }()
+	return f(original)
+}
+
+// Record runs the protocol created by start between all parties, delivering messages one at a time,
+// and returns its transcript along with the results of all parties.
+// It returns an error if a party does not complete the protocol.
+func Record(partyIDs party.IDSlice, sessionID []byte, start func(id party.ID) protocol.StartFunc) (*Transcript, map[party.ID]interface{}, error) {
+	t := &Transcript{
+		SessionID: bytes.Clone(sessionID),
+		Parties:   make(map[party.ID]*PartyTranscript, len(partyIDs)),
+	}
+	results := make(map[party.ID]interface{}, len(partyIDs))
+	src := &switchReader{}
+	err := withRandomness(src, func(original io.Reader) error {
+		handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+		var queue []*protocol.Message
+		collect := func(id party.ID) error {
+			for _, msg := range drainMessages(handlers[id]) {
+				data, err := msg.MarshalBinary()
+				if err != nil {
+					return err
+				}
+				t.Parties[id].Sent = append(t.Parties[id].Sent, data)
+				queue = append(queue, msg)
+			}
+			return nil
+		}
+		readers := make(map[party.ID]io.Reader, len(partyIDs))
+		for _, id := range partyIDs {
+			p := &PartyTranscript{}
+			t.Parties[id] = p
+			readers[id] = &recordingReader{r: original, out: &p.Randomness}
+			src.r = readers[id]
+			h, err := protocol.NewMultiHandlerWithOptions(start(id), sessionID, protocol.HandlerOptions{DeterministicOutput: true})
+			if err != nil {
+				return fmt.Errorf("transcript: party %s: %w", id, err)
+			}
+			handlers[id] = h
+			if err = collect(id); err != nil {
+				return err
+			}
+		}
+		for len(queue) > 0 {
+			msg := queue[0]
+			queue = queue[1:]
+			for _, id := range partyIDs {
+				if !msg.IsFor(id) {
+					continue
+				}
+				data, err := msg.MarshalBinary()
+				if err != nil {
+					return err
+				}
+				t.Parties[id].Received = append(t.Parties[id].Received, data)
+				src.r = readers[id]
+				handlers[id].Accept(msg)
+				if err = collect(id); err != nil {
+					return err
+				}
+			}
+		}
+		for _, id := range partyIDs {
+			result, err := handlers[id].Result()
+			if err != nil {
+				return fmt.Errorf("transcript: party %s: %w", id, err)
+			}
+			if t.Parties[id].Result, err = canonicalMode.Marshal(result); err != nil {
+				return fmt.Errorf("transcript: party %s: failed to encode result: %w", id, err)
+			}
+			results[id] = result
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return t, results, nil
+}
+
+// Replay runs the protocol created by start for party id with the randomness and messages recorded in t,
+// and returns an error unless the party emits the same messages and obtains the same result.
+func (t *Transcript) Replay(id party.ID, start protocol.StartFunc) (interface{}, error) {
+	p, ok := t.Parties[id]
+	if !ok {
+		return nil, fmt.Errorf("transcript: no record for party %s", id)
+	}
+	replay := &replayReader{data: p.Randomness}
+	var result interface{}
+	err := withRandomness(&switchReader{r: replay}, func(io.Reader) error {
+		h, err := protocol.NewMultiHandlerWithOptions(start, t.SessionID, protocol.HandlerOptions{DeterministicOutput: true})
+		if err != nil {
+			return fmt.Errorf("transcript: party %s: %w", id, err)
+		}
+		sent := drainMessages(h)
+		for i, data := range p.Received {
+			msg := &protocol.Message{}
+			if err = msg.UnmarshalBinary(data); err != nil {
+				return fmt.Errorf("transcript: party %s: received message %d: %w", id, i, err)
+			}
+			h.Accept(msg)
+			sent = append(sent, drainMessages(h)...)
+		}
+		if len(sent) != len(p.Sent) {
+			return fmt.Errorf("transcript: party %s sent %d messages, recorded %d", id, len(sent), len(p.Sent))
+		}
+		for i, msg := range sent {
+			data, err := msg.MarshalBinary()
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(data, p.Sent[i]) {
+				return fmt.Errorf("transcript: party %s: message %d (%s) differs from the recording", id, i, msg)
+			}
+		}
+		if result, err = h.Result(); err != nil {
+			return fmt.Errorf("transcript: party %s: %w", id, err)
+		}
+		data, err := canonicalMode.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("transcript: party %s: failed to encode result: %w", id, err)
+		}
+		if !bytes.Equal(data, p.Result) {
+			return fmt.Errorf("transcript: party %s: result differs from the recording", id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if replay.overrun > 0 || len(replay.data) > 0 {
+		return nil, fmt.Errorf("transcript: party %s read %d bytes of randomness, recorded %d",
+			id, len(p.Randomness)-len(replay.data)+replay.overrun, len(p.Randomness))
+	}
+	return result, nil
+}
+
+// MarshalBinary returns the canonical CBOR encoding of t, so that it can be stored as a test fixture.
+func (t *Transcript) MarshalBinary() ([]byte, error) {
+	return canonicalMode.Marshal((*transcriptMarshal)(t))
+}
+
+// UnmarshalBinary decodes a transcript encoded by MarshalBinary.
+func (t *Transcript) UnmarshalBinary(data []byte) error {
+	return cbor.Unmarshal(data, (*transcriptMarshal)(t))
+}
+
+// transcriptMarshal has the fields of Transcript, without its MarshalBinary method.
+type transcriptMarshal Transcript
+
+// drainMessages returns the messages emitted by h which have not been read yet.
+func drainMessages(h *protocol.MultiHandler) []*protocol.Message {
+	var msgs []*protocol.Message
+	for {
+		select {
+		case msg, ok := <-h.Listen():
+			if !ok {
+				return msgs
+			}
+			msgs = append(msgs, msg)
+		default:
+			return msgs
+		}
+	}
+}
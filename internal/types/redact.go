@@ -0,0 +1,19 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// redactedDigestBytes is the length of the SHA-256 prefix kept by RedactedDigest.
+const redactedDigestBytes = 8
+
+// RedactedDigest returns a short SHA-256 digest of a secret value, prefixed with "sha256:".
+// It allows comparing secrets across debugging dumps, without revealing them.
+func RedactedDigest(data []byte) string {
+	if data == nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:redactedDigestBytes])
+}
@@ -17,15 +17,25 @@ func EmptyRID() RID {
 	return make(RID, params.SecBytes)
 }
 
+// EmptyRIDForProfile returns a zeroed-out RID with the length given by profile.
+func EmptyRIDForProfile(profile params.SecurityProfile) RID {
+	return make(RID, profile.OrDefault().RIDBytes)
+}
+
 func NewRID(r io.Reader) (RID, error) {
-	rid := EmptyRID()
+	return NewRIDForProfile(r, params.DefaultSecurityProfile)
+}
+
+// NewRIDForProfile samples a RID with the length given by profile.
+func NewRIDForProfile(r io.Reader, profile params.SecurityProfile) (RID, error) {
+	rid := EmptyRIDForProfile(profile)
 	_, err := io.ReadFull(r, rid)
 	return rid, err
 }
 
 // XOR modifies the receiver by taking the XOR with the argument.
 func (rid RID) XOR(otherRID RID) {
-	for b := 0; b < params.SecBytes; b++ {
+	for b := 0; b < len(rid) && b < len(otherRID); b++ {
 		rid[b] ^= otherRID[b]
 	}
 }
@@ -44,8 +54,14 @@ func (RID) Domain() string { return "RID" }
 
 // Validate ensure that the RID is the correct length and is not identically 0.
 func (rid RID) Validate() error {
-	if l := len(rid); l != params.SecBytes {
-		return fmt.Errorf("rid: incorrect length (got %d, expected %d)", l, params.SecBytes)
+	return rid.ValidateProfile(params.DefaultSecurityProfile)
+}
+
+// ValidateProfile is the same as Validate, but expects the RID length given by profile.
+func (rid RID) ValidateProfile(profile params.SecurityProfile) error {
+	expected := profile.OrDefault().RIDBytes
+	if l := len(rid); l != expected {
+		return fmt.Errorf("rid: incorrect length (got %d, expected %d)", l, expected)
 	}
 	for _, b := range rid {
 		if b != 0 {
@@ -56,7 +72,7 @@ func (rid RID) Validate() error {
 }
 
 func (rid RID) Copy() RID {
-	other := EmptyRID()
+	other := make(RID, len(rid))
 	copy(other, rid)
 	return other
 }
@@ -0,0 +1,210 @@
+// Package conformance replays known-answer vectors against the hash function, signature verification
+// and zero-knowledge proof verifiers of this module, so that a build can be checked before it is deployed,
+// for example after cross-compiling it for a new platform.
+//
+// The vectors returned by DefaultVectors were produced by this implementation, and therefore detect
+// regressions and miscompilations rather than divergences from other implementations.
+// Vectors from other sources can be replayed with RunConformance once converted to the Vector format.
+package conformance
+
+import (
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cronokirby/saferith"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/arith"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	zkmod "github.com/taurusgroup/multi-party-sig/pkg/zk/mod"
+	zkprm "github.com/taurusgroup/multi-party-sig/pkg/zk/prm"
+	zksch "github.com/taurusgroup/multi-party-sig/pkg/zk/sch"
+)
+
+// Kind identifies what a Vector checks.
+type Kind string
+
+const (
+	// KindHash checks the digest of Input, hashed with Domain.
+	KindHash Kind = "hash"
+	// KindECDSA checks the signature Proof of the message hash Input under the public key Public.
+	KindECDSA Kind = "ecdsa"
+	// KindSchnorr checks the zksch proof Proof of the discrete logarithm of Public.
+	KindSchnorr Kind = "zksch"
+	// KindMod checks the zkmod proof Proof that the modulus Public is a Blum integer.
+	KindMod Kind = "zkmod"
+	// KindPrm checks the zkprm proof Proof that S and T are valid Pedersen parameters for the modulus Public.
+	KindPrm Kind = "zkprm"
+)
+
+// Vector is a single known-answer test. All byte strings are hex encoded.
+//
+// Proofs are verified against a transcript obtained by hashing Input with Domain,
+// and are encoded with CBOR, as in the messages of the protocols.
+type Vector struct {
+	Name string `json:"name"`
+	Kind Kind   `json:"kind"`
+	// Group is the name of the curve, for KindECDSA and KindSchnorr.
+	Group  string `json:"group,omitempty"`
+	Domain string `json:"domain,omitempty"`
+	Input  string `json:"input,omitempty"`
+	// Public is a compressed point, or a modulus for KindMod and KindPrm.
+	Public string `json:"public,omitempty"`
+	S      string `json:"s,omitempty"`
+	T      string `json:"t,omitempty"`
+	// Iterations of KindMod and KindPrm proofs may be below params.MinStatParam, to keep the vectors small,
+	// since they check the encoding and the verification equations rather than soundness.
+	Iterations int    `json:"iterations,omitempty"`
+	Proof      string `json:"proof,omitempty"`
+	// Expected is the digest for KindHash.
+	Expected string `json:"expected,omitempty"`
+	// Valid indicates whether the proof or signature must be accepted.
+	Valid bool `json:"valid"`
+}
+
+//go:embed vectors.json
+var defaultVectors []byte
+
+// DefaultVectors returns the vectors embedded in this package.
+func DefaultVectors() ([]Vector, error) {
+	var vectors []Vector
+	if err := json.Unmarshal(defaultVectors, &vectors); err != nil {
+		return nil, fmt.Errorf("conformance: %w", err)
+	}
+	return vectors, nil
+}
+
+// RunConformance checks all vectors, and returns an error listing every vector which failed.
+// A nil pool can be given, in which case the proofs are verified sequentially.
+func RunConformance(vectors []Vector, pl *pool.Pool) error {
+	var errs []error
+	for _, v := range vectors {
+		if err := Run(v, pl); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run checks a single vector.
+func Run(v Vector, pl *pool.Pool) error {
+	ok, err := run(v, pl)
+	if err != nil {
+		return fmt.Errorf("conformance: %s: %w", v.Name, err)
+	}
+	if ok != v.Valid {
+		return fmt.Errorf("conformance: %s: got %t, expected %t", v.Name, ok, v.Valid)
+	}
+	return nil
+}
+
+func run(v Vector, pl *pool.Pool) (bool, error) {
+	input, err := hex.DecodeString(v.Input)
+	if err != nil {
+		return false, fmt.Errorf("input: %w", err)
+	}
+	proof, err := hex.DecodeString(v.Proof)
+	if err != nil {
+		return false, fmt.Errorf("proof: %w", err)
+	}
+
+	switch v.Kind {
+	case KindHash:
+		return hex.EncodeToString(transcript(v.Domain, input).Sum()) == v.Expected, nil
+
+	case KindECDSA:
+		group, public, err := parsePoint(v.Group, v.Public)
+		if err != nil {
+			return false, err
+		}
+		sig := ecdsa.EmptySignature(group)
+		if err = cbor.Unmarshal(proof, &sig); err != nil {
+			return false, nil
+		}
+		return sig.Verify(public, input), nil
+
+	case KindSchnorr:
+		group, public, err := parsePoint(v.Group, v.Public)
+		if err != nil {
+			return false, err
+		}
+		p := zksch.EmptyProof(group)
+		if err = cbor.Unmarshal(proof, p); err != nil {
+			return false, nil
+		}
+		return p.Verify(transcript(v.Domain, input), public, nil), nil
+
+	case KindMod:
+		n, err := parseNat(v.Public)
+		if err != nil {
+			return false, err
+		}
+		p := &zkmod.Proof{}
+		if err = cbor.Unmarshal(proof, p); err != nil {
+			return false, nil
+		}
+		public := zkmod.Public{N: saferith.ModulusFromNat(n), Iterations: v.Iterations, Insecure: true}
+		return p.Verify(public, transcript(v.Domain, input), pl), nil
+
+	case KindPrm:
+		n, err := parseNat(v.Public)
+		if err != nil {
+			return false, err
+		}
+		s, err := parseNat(v.S)
+		if err != nil {
+			return false, err
+		}
+		t, err := parseNat(v.T)
+		if err != nil {
+			return false, err
+		}
+		p := &zkprm.Proof{}
+		if err = cbor.Unmarshal(proof, p); err != nil {
+			return false, nil
+		}
+		modulus := saferith.ModulusFromNat(n)
+		if err = pedersen.ValidateParameters(modulus, s, t); err != nil {
+			return false, err
+		}
+		public := zkprm.Public{Aux: pedersen.New(arith.ModulusFromN(modulus), s, t), Iterations: v.Iterations, Insecure: true}
+		return p.Verify(public, transcript(v.Domain, input), pl), nil
+
+	default:
+		return false, fmt.Errorf("unknown kind %q", v.Kind)
+	}
+}
+
+// transcript returns the hash state against which the proofs of a vector are verified.
+func transcript(domain string, input []byte) *hash.Hash {
+	return hash.New(hash.BytesWithDomain{TheDomain: domain, Bytes: input})
+}
+
+func parsePoint(groupName, s string) (curve.Curve, curve.Point, error) {
+	group, err := curve.ByName(groupName)
+	if err != nil {
+		return nil, nil, err
+	}
+	p, err := curve.PointFromHex(group, s)
+	if err != nil {
+		return nil, nil, fmt.Errorf("public: %w", err)
+	}
+	return group, p, nil
+}
+
+func parseNat(s string) (*saferith.Nat, error) {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty integer")
+	}
+	return new(saferith.Nat).SetBytes(data), nil
+}
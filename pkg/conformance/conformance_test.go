@@ -0,0 +1,138 @@
+package conformance_test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/pkg/conformance"
+	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/pkg/zk"
+	zkmod "github.com/taurusgroup/multi-party-sig/pkg/zk/mod"
+	zkprm "github.com/taurusgroup/multi-party-sig/pkg/zk/prm"
+	zksch "github.com/taurusgroup/multi-party-sig/pkg/zk/sch"
+)
+
+var update = flag.Bool("update", false, "regenerate vectors.json")
+
+func TestRunConformance(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	vectors, err := conformance.DefaultVectors()
+	require.NoError(t, err)
+	require.NotEmpty(t, vectors)
+	assert.NoError(t, conformance.RunConformance(vectors, pl))
+
+	// a vector with the opposite expectation must fail
+	for _, v := range vectors {
+		v.Valid = !v.Valid
+		assert.Error(t, conformance.Run(v, pl), v.Name)
+	}
+
+	assert.Error(t, conformance.Run(conformance.Vector{Name: "unknown", Kind: "unknown"}, nil))
+}
+
+func TestGenerateVectors(t *testing.T) {
+	if !*update {
+		t.Skip("run with -update to regenerate vectors.json")
+	}
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	const domain = "Conformance"
+	transcript := func(input []byte) *hash.Hash {
+		return hash.New(hash.BytesWithDomain{TheDomain: domain, Bytes: input})
+	}
+	encode := func(v interface{}) string {
+		data, err := cbor.Marshal(v)
+		require.NoError(t, err)
+		return hex.EncodeToString(data)
+	}
+	input := []byte("multi-party-sig conformance")
+	var vectors []conformance.Vector
+
+	vectors = append(vectors, conformance.Vector{
+		Name: "hash", Kind: conformance.KindHash, Domain: domain,
+		Input: hex.EncodeToString(input), Expected: hex.EncodeToString(transcript(input).Sum()), Valid: true,
+	})
+
+	group := curve.Secp256k1{}
+	secret, public := sample.ScalarPointPair(rand.Reader, group)
+	messageHash := hash.New(hash.BytesWithDomain{TheDomain: domain, Bytes: input}).Sum()[:32]
+	k := sample.Scalar(rand.Reader, group)
+	R := k.ActOnBase()
+	m := curve.FromHash(group, messageHash)
+	s := group.NewScalar().Set(R.XScalar()).Mul(secret).Add(m).Mul(k.Invert())
+	sig := ecdsa.Signature{R: R, S: s}
+	require.True(t, sig.Verify(public, messageHash))
+	vectors = append(vectors,
+		conformance.Vector{
+			Name: "ecdsa", Kind: conformance.KindECDSA, Group: group.Name(), Input: hex.EncodeToString(messageHash),
+			Public: curve.ToHexCompressed(public), Proof: encode(sig), Valid: true,
+		},
+		conformance.Vector{
+			Name: "ecdsa wrong key", Kind: conformance.KindECDSA, Group: group.Name(), Input: hex.EncodeToString(messageHash),
+			Public: curve.ToHexCompressed(sig.R), Proof: encode(sig), Valid: false,
+		},
+	)
+
+	sch := zksch.NewProof(transcript(input), public, secret, nil)
+	vectors = append(vectors,
+		conformance.Vector{
+			Name: "zksch", Kind: conformance.KindSchnorr, Group: group.Name(), Domain: domain, Input: hex.EncodeToString(input),
+			Public: curve.ToHexCompressed(public), Proof: encode(sch), Valid: true,
+		},
+		conformance.Vector{
+			Name: "zksch wrong transcript", Kind: conformance.KindSchnorr, Group: group.Name(), Domain: domain,
+			Public: curve.ToHexCompressed(public), Proof: encode(sch), Valid: false,
+		},
+	)
+
+	sk := zk.ProverPaillierSecret
+	n := hex.EncodeToString(sk.PublicKey.N().Bytes())
+	const iterations = 8
+	mod := zkmod.NewProof(transcript(input), zkmod.Private{P: sk.P(), Q: sk.Q(), Phi: sk.Phi()},
+		zkmod.Public{N: sk.PublicKey.N(), Iterations: iterations}, pl)
+	vectors = append(vectors,
+		conformance.Vector{
+			Name: "zkmod", Kind: conformance.KindMod, Domain: domain, Input: hex.EncodeToString(input),
+			Public: n, Iterations: iterations, Proof: encode(mod), Valid: true,
+		},
+		conformance.Vector{
+			Name: "zkmod wrong modulus", Kind: conformance.KindMod, Domain: domain, Input: hex.EncodeToString(input),
+			Public: hex.EncodeToString(zk.VerifierPaillierPublic.N().Bytes()), Iterations: iterations, Proof: encode(mod), Valid: false,
+		},
+	)
+
+	ped, lambda := sk.GeneratePedersen()
+	prm := zkprm.NewProof(zkprm.Private{Lambda: lambda, Phi: sk.Phi(), P: sk.P(), Q: sk.Q()}, transcript(input),
+		zkprm.Public{Aux: ped, Iterations: iterations}, pl)
+	vectors = append(vectors,
+		conformance.Vector{
+			Name: "zkprm", Kind: conformance.KindPrm, Domain: domain, Input: hex.EncodeToString(input),
+			Public: n, S: hex.EncodeToString(ped.S().Bytes()), T: hex.EncodeToString(ped.T().Bytes()),
+			Iterations: iterations, Proof: encode(prm), Valid: true,
+		},
+		conformance.Vector{
+			Name: "zkprm swapped parameters", Kind: conformance.KindPrm, Domain: domain, Input: hex.EncodeToString(input),
+			Public: n, S: hex.EncodeToString(ped.T().Bytes()), T: hex.EncodeToString(ped.S().Bytes()),
+			Iterations: iterations, Proof: encode(prm), Valid: false,
+		},
+	)
+
+	require.NoError(t, conformance.RunConformance(vectors, pl))
+	data, err := json.MarshalIndent(vectors, "", "  ")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile("vectors.json", append(data, '\n'), 0o644))
+}
@@ -0,0 +1,39 @@
+package ecdsa
+
+import (
+	"fmt"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+)
+
+// DigestLengthError is returned when a message to be signed is not a digest of the length expected for the group.
+type DigestLengthError struct {
+	// Group is the name of the curve.
+	Group string
+	// Length is the length of the message.
+	Length int
+	// Expected is the length of a digest, see DigestLength.
+	Expected int
+}
+
+// Error implements error.
+func (e *DigestLengthError) Error() string {
+	return fmt.Sprintf("ecdsa: message has %d bytes, expected a digest of %d bytes for %s", e.Length, e.Expected, e.Group)
+}
+
+// DigestLength returns the length of the digests signed over group, which is the length of its order in bytes,
+// for example 32 for secp256k1.
+func DigestLength(group curve.Curve) int {
+	return (group.Order().BitLen() + 7) / 8
+}
+
+// ValidateDigest returns a *DigestLengthError if digest does not have the length given by DigestLength.
+//
+// The signing protocols only accept digests, since curve.FromHash silently truncates longer inputs,
+// so that signing unhashed data of the wrong length would go unnoticed. Use Profile.HashMessage to obtain a digest.
+func ValidateDigest(group curve.Curve, digest []byte) error {
+	if expected := DigestLength(group); len(digest) != expected {
+		return &DigestLengthError{Group: group.Name(), Length: len(digest), Expected: expected}
+	}
+	return nil
+}
@@ -1,11 +1,14 @@
 package ecdsa
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 
 	"github.com/taurusgroup/multi-party-sig/internal/types"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/party"
 )
 
@@ -22,6 +25,12 @@ type PreSignature struct {
 	KShare curve.Scalar
 	// ChiShare = χᵢ
 	ChiShare curve.Scalar
+	// ConfigFingerprint is the fingerprint of the config this presignature was produced with,
+	// see CheckFingerprint.
+	ConfigFingerprint []byte
+	// MessageHash is set by AdaptToChild to the only message the presignature may sign, see CheckMessage.
+	// It is empty for presignatures which may sign any message.
+	MessageHash []byte
 }
 
 // Group returns the elliptic curve group associated with this PreSignature.
@@ -68,23 +77,69 @@ func (sig *PreSignature) Signature(shares map[party.ID]SignatureShare) *Signatur
 // VerifySignatureShares should be called if the signature returned by PreSignature.Signature is not valid.
 // It returns the list of parties whose shares are invalid.
 func (sig *PreSignature) VerifySignatureShares(shares map[party.ID]SignatureShare, hash []byte) (culprits []party.ID) {
-	r := sig.R.XScalar()
-	m := curve.FromHash(sig.Group(), hash)
 	for j, share := range shares {
-		Rj, Sj := sig.RBar.Points[j], sig.S.Points[j]
-		if Rj == nil || Sj == nil {
-			culprits = append(culprits, j)
-			continue
-		}
-		lhs := share.Act(sig.R)
-		rhs := m.Act(Rj).Add(r.Act(Sj))
-		if !lhs.Equal(rhs) {
+		if !sig.VerifySignatureShare(j, share, hash) {
 			culprits = append(culprits, j)
 		}
 	}
 	return
 }
 
+// VerifySignatureShare returns true if share is the valid share σⱼ of party j for the given hash,
+// that is σⱼ⋅R = m⋅R̄ⱼ + r⋅Sⱼ.
+// Since it only uses public data, it can be called on each share as it is received,
+// for example by a coordinator which aggregates the shares.
+func (sig *PreSignature) VerifySignatureShare(j party.ID, share SignatureShare, hash []byte) bool {
+	Rj, Sj := sig.RBar.Points[j], sig.S.Points[j]
+	if Rj == nil || Sj == nil || share == nil {
+		return false
+	}
+	r := sig.R.XScalar()
+	m := curve.FromHash(sig.Group(), hash)
+	lhs := share.Act(sig.R)
+	rhs := m.Act(Rj).Add(r.Act(Sj))
+	return lhs.Equal(rhs)
+}
+
+// AdaptToChild returns a presignature for the child key X + adjust⋅G, such as a BIP32 child derived with
+// config.Config.Derive, whose config has the given fingerprint, to sign messageHash.
+//
+// Since χ = k⋅x, the child's χ' = k⋅(x + adjust) is shared as χᵢ' = χᵢ + adjust⋅kᵢ, and its public shares are
+// Sⱼ' = χⱼ'⋅R = Sⱼ + adjust⋅R̄ⱼ, so that no interaction is required.
+//
+// Using R for a key chosen after R is known allows related-key forgeries (Groth and Shoup, "On the security of ECDSA
+// with additive key derivation and presignatures"), so R is re-randomized as R' = ρ⋅R, where ρ is derived from
+// R, adjust and messageHash. The shares become kᵢ' = ρ⁻¹⋅kᵢ and χᵢ' = ρ⁻¹⋅(χᵢ + adjust⋅kᵢ), while R̄ⱼ and Sⱼ' are
+// unchanged. The adapted presignature is therefore bound to messageHash, see CheckMessage,
+// and sig and the adapted presignature must not both be used, since they share the same k.
+func (sig *PreSignature) AdaptToChild(adjust curve.Scalar, messageHash, fingerprint []byte) *PreSignature {
+	group := sig.Group()
+	S := make(map[party.ID]curve.Point, len(sig.S.Points))
+	for j, Sj := range sig.S.Points {
+		S[j] = Sj.Add(adjust.Act(sig.RBar.Points[j]))
+	}
+	rho := rerandomizer(sig.R, adjust, messageHash)
+	rhoInv := group.NewScalar().Set(rho).Invert()
+	chi := group.NewScalar().Set(adjust).Mul(sig.KShare).Add(sig.ChiShare)
+	return &PreSignature{
+		ID:                sig.ID.Copy(),
+		R:                 rho.Act(sig.R),
+		RBar:              sig.RBar,
+		S:                 party.NewPointMap(S),
+		KShare:            group.NewScalar().Set(rhoInv).Mul(sig.KShare),
+		ChiShare:          chi.Mul(rhoInv),
+		ConfigFingerprint: bytes.Clone(fingerprint),
+		MessageHash:       bytes.Clone(messageHash),
+	}
+}
+
+// rerandomizer returns the non-zero scalar ρ by which AdaptToChild multiplies R.
+func rerandomizer(R curve.Point, adjust curve.Scalar, messageHash []byte) curve.Scalar {
+	h := hash.New(&hash.BytesWithDomain{TheDomain: "PreSignature Adapt Message", Bytes: messageHash})
+	_ = h.WriteAny(R, adjust)
+	return sample.ScalarUnit(h.Digest(), R.Curve())
+}
+
 func (sig *PreSignature) Validate() error {
 	if len(sig.RBar.Points) != len(sig.S.Points) {
 		return errors.New("presignature: different number of R,S shares")
@@ -117,3 +172,6 @@ func (sig *PreSignature) SignerIDs() party.IDSlice {
 	}
 	return party.NewIDSlice(ids)
 }
+
+// ResultKind returns "presignature", see protocol.KindOf.
+func (PreSignature) ResultKind() string { return "presignature" }
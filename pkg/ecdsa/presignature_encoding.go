@@ -0,0 +1,231 @@
+package ecdsa
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// CheckFingerprint returns an error unless sig was produced with the config whose fingerprint is given,
+// see config.Config.Fingerprint.
+func (sig *PreSignature) CheckFingerprint(fingerprint []byte) error {
+	if len(sig.ConfigFingerprint) == 0 {
+		return errors.New("presignature: missing config fingerprint")
+	}
+	if !bytes.Equal(sig.ConfigFingerprint, fingerprint) {
+		return errors.New("presignature: produced with a different config")
+	}
+	return nil
+}
+
+// CheckMessage returns an error if sig is bound to a message other than messageHash, see AdaptToChild.
+func (sig *PreSignature) CheckMessage(messageHash []byte) error {
+	if len(sig.MessageHash) != 0 && !bytes.Equal(sig.MessageHash, messageHash) {
+		return errors.New("presignature: bound to a different message")
+	}
+	return nil
+}
+
+// preSignatureMarshal is the encoding of a PreSignature, tagged with the name of its group.
+// Points and scalars are encoded with their MarshalBinary methods.
+type preSignatureMarshal struct {
+	Group             string
+	ID                []byte
+	R                 []byte
+	RBar, S           map[party.ID][]byte
+	KShare, ChiShare  []byte
+	ConfigFingerprint []byte
+	MessageHash       []byte
+}
+
+// preSignatureJSON is the JSON encoding of a PreSignature, where points are compressed and all values are hex encoded.
+type preSignatureJSON struct {
+	Group             string              `json:"group"`
+	ID                string              `json:"id"`
+	R                 string              `json:"r"`
+	RBar              map[party.ID]string `json:"rbar"`
+	S                 map[party.ID]string `json:"s"`
+	KShare            string              `json:"k"`
+	ChiShare          string              `json:"chi"`
+	ConfigFingerprint string              `json:"config"`
+	MessageHash       string              `json:"message,omitempty"`
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (sig *PreSignature) MarshalBinary() ([]byte, error) {
+	pm := preSignatureMarshal{
+		Group:             sig.Group().Name(),
+		ID:                sig.ID,
+		RBar:              make(map[party.ID][]byte, len(sig.RBar.Points)),
+		S:                 make(map[party.ID][]byte, len(sig.S.Points)),
+		ConfigFingerprint: sig.ConfigFingerprint,
+		MessageHash:       sig.MessageHash,
+	}
+	var err error
+	if pm.R, err = sig.R.MarshalBinary(); err != nil {
+		return nil, fmt.Errorf("presignature: %w", err)
+	}
+	for id, p := range sig.RBar.Points {
+		if pm.RBar[id], err = p.MarshalBinary(); err != nil {
+			return nil, fmt.Errorf("presignature: party %s: %w", id, err)
+		}
+	}
+	for id, p := range sig.S.Points {
+		if pm.S[id], err = p.MarshalBinary(); err != nil {
+			return nil, fmt.Errorf("presignature: party %s: %w", id, err)
+		}
+	}
+	if pm.KShare, err = sig.KShare.MarshalBinary(); err != nil {
+		return nil, fmt.Errorf("presignature: %w", err)
+	}
+	if pm.ChiShare, err = sig.ChiShare.MarshalBinary(); err != nil {
+		return nil, fmt.Errorf("presignature: %w", err)
+	}
+	return cbor.Marshal(&pm)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, and validates the result.
+// If sig was created with EmptyPreSignature, the encoded group must match; otherwise it is obtained with curve.ByName.
+func (sig *PreSignature) UnmarshalBinary(data []byte) error {
+	var pm preSignatureMarshal
+	if err := cbor.Unmarshal(data, &pm); err != nil {
+		return fmt.Errorf("presignature: %w", err)
+	}
+	group, err := sig.groupFor(pm.Group)
+	if err != nil {
+		return err
+	}
+	out := EmptyPreSignature(group)
+	out.ID = pm.ID
+	out.ConfigFingerprint = pm.ConfigFingerprint
+	out.MessageHash = pm.MessageHash
+	if err = out.R.UnmarshalBinary(pm.R); err != nil {
+		return fmt.Errorf("presignature: r: %w", err)
+	}
+	if out.RBar.Points, err = unmarshalPoints(group, pm.RBar); err != nil {
+		return err
+	}
+	if out.S.Points, err = unmarshalPoints(group, pm.S); err != nil {
+		return err
+	}
+	if err = out.KShare.UnmarshalBinary(pm.KShare); err != nil {
+		return fmt.Errorf("presignature: k: %w", err)
+	}
+	if err = out.ChiShare.UnmarshalBinary(pm.ChiShare); err != nil {
+		return fmt.Errorf("presignature: chi: %w", err)
+	}
+	if err = out.Validate(); err != nil {
+		return err
+	}
+	*sig = *out
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (sig *PreSignature) MarshalJSON() ([]byte, error) {
+	pj := preSignatureJSON{
+		Group:             sig.Group().Name(),
+		ID:                hex.EncodeToString(sig.ID),
+		R:                 curve.ToHexCompressed(sig.R),
+		RBar:              make(map[party.ID]string, len(sig.RBar.Points)),
+		S:                 make(map[party.ID]string, len(sig.S.Points)),
+		KShare:            curve.ScalarToHex(sig.KShare),
+		ChiShare:          curve.ScalarToHex(sig.ChiShare),
+		ConfigFingerprint: hex.EncodeToString(sig.ConfigFingerprint),
+		MessageHash:       hex.EncodeToString(sig.MessageHash),
+	}
+	for id, p := range sig.RBar.Points {
+		pj.RBar[id] = curve.ToHexCompressed(p)
+	}
+	for id, p := range sig.S.Points {
+		pj.S[id] = curve.ToHexCompressed(p)
+	}
+	return json.Marshal(&pj)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, and validates the result in the same way as UnmarshalBinary.
+func (sig *PreSignature) UnmarshalJSON(data []byte) error {
+	var pj preSignatureJSON
+	if err := json.Unmarshal(data, &pj); err != nil {
+		return fmt.Errorf("presignature: %w", err)
+	}
+	group, err := sig.groupFor(pj.Group)
+	if err != nil {
+		return err
+	}
+	out := EmptyPreSignature(group)
+	if out.ID, err = hex.DecodeString(pj.ID); err != nil {
+		return fmt.Errorf("presignature: id: %w", err)
+	}
+	if out.ConfigFingerprint, err = hex.DecodeString(pj.ConfigFingerprint); err != nil {
+		return fmt.Errorf("presignature: config: %w", err)
+	}
+	if out.MessageHash, err = hex.DecodeString(pj.MessageHash); err != nil {
+		return fmt.Errorf("presignature: message: %w", err)
+	}
+	if out.R, err = curve.PointFromHex(group, pj.R); err != nil {
+		return fmt.Errorf("presignature: r: %w", err)
+	}
+	if out.RBar.Points, err = pointsFromHex(group, pj.RBar); err != nil {
+		return err
+	}
+	if out.S.Points, err = pointsFromHex(group, pj.S); err != nil {
+		return err
+	}
+	if out.KShare, err = curve.ScalarFromHex(group, pj.KShare); err != nil {
+		return fmt.Errorf("presignature: k: %w", err)
+	}
+	if out.ChiShare, err = curve.ScalarFromHex(group, pj.ChiShare); err != nil {
+		return fmt.Errorf("presignature: chi: %w", err)
+	}
+	if err = out.Validate(); err != nil {
+		return err
+	}
+	*sig = *out
+	return nil
+}
+
+// groupFor returns the group of sig if it was set by EmptyPreSignature, after checking that it matches name.
+func (sig *PreSignature) groupFor(name string) (curve.Curve, error) {
+	if sig.R == nil {
+		group, err := curve.ByName(name)
+		if err != nil {
+			return nil, fmt.Errorf("presignature: %w", err)
+		}
+		return group, nil
+	}
+	if group := sig.Group(); group.Name() != name {
+		return nil, fmt.Errorf("presignature: group %s does not match %s", name, group.Name())
+	}
+	return sig.Group(), nil
+}
+
+func unmarshalPoints(group curve.Curve, data map[party.ID][]byte) (map[party.ID]curve.Point, error) {
+	points := make(map[party.ID]curve.Point, len(data))
+	for id, b := range data {
+		p := group.NewPoint()
+		if err := p.UnmarshalBinary(b); err != nil {
+			return nil, fmt.Errorf("presignature: party %s: %w", id, err)
+		}
+		points[id] = p
+	}
+	return points, nil
+}
+
+func pointsFromHex(group curve.Curve, data map[party.ID]string) (map[party.ID]curve.Point, error) {
+	points := make(map[party.ID]curve.Point, len(data))
+	for id, s := range data {
+		p, err := curve.PointFromHex(group, s)
+		if err != nil {
+			return nil, fmt.Errorf("presignature: party %s: %w", id, err)
+		}
+		points[id] = p
+	}
+	return points, nil
+}
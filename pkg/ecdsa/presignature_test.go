@@ -2,10 +2,12 @@ package ecdsa
 
 import (
 	"encoding/binary"
+	"encoding/json"
 	mrand "math/rand"
 	"testing"
 
-	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/party"
@@ -33,7 +35,11 @@ func generateShares(secret curve.Scalar, ids []party.ID) map[party.ID]curve.Scal
 func NewPreSignatures(group curve.Curve, N int) (x curve.Scalar, X curve.Point, preSignatures map[party.ID]*PreSignature) {
 	rand := mrand.New(mrand.NewSource(0))
 
-	partyIDs := test.PartyIDs(N)
+	// internal/test imports the cmp config, which imports this package
+	partyIDs := make(party.IDSlice, N)
+	for i := range partyIDs {
+		partyIDs[i] = party.ID(rune('a' + i))
+	}
 
 	x = sample.Scalar(rand, group)
 	X = x.ActOnBase()
@@ -108,3 +114,86 @@ func TestPreSignature_Fail(t *testing.T) {
 		}
 	}
 }
+
+func TestPreSignature_AdaptToChild(t *testing.T) {
+	N := 5
+	group := curve.Secp256k1{}
+	message := []byte("HELLO WORLD")
+	_, X, preSignatures := NewPreSignatures(group, N)
+	adjust := sample.Scalar(mrand.New(mrand.NewSource(1)), group)
+	child := X.Add(adjust.ActOnBase())
+	fingerprint := []byte("child fingerprint")
+
+	adapted := make(map[party.ID]*PreSignature, N)
+	sigmaShares := make(map[party.ID]SignatureShare, N)
+	for id, preSignature := range preSignatures {
+		adapted[id] = preSignature.AdaptToChild(adjust, message, fingerprint)
+		assert.NoError(t, adapted[id].CheckFingerprint(fingerprint))
+		assert.NoError(t, adapted[id].CheckMessage(message))
+		assert.Error(t, adapted[id].CheckMessage([]byte("OTHER")))
+		assert.NoError(t, preSignature.CheckMessage([]byte("OTHER")), "only adapted presignatures are bound to a message")
+		// the nonce is re-randomized, and depends on the message
+		assert.False(t, adapted[id].R.Equal(preSignature.R))
+		assert.False(t, adapted[id].R.Equal(preSignature.AdaptToChild(adjust, []byte("OTHER"), fingerprint).R))
+		sigmaShares[id] = adapted[id].SignatureShare(message)
+	}
+	for id, preSignature := range adapted {
+		assert.Empty(t, preSignature.VerifySignatureShares(sigmaShares, message))
+		signature := preSignature.Signature(sigmaShares)
+		assert.True(t, signature.Verify(child, message))
+		assert.False(t, signature.Verify(X, message))
+		// the original presignature is unchanged
+		assert.False(t, preSignatures[id].ChiShare.Equal(preSignature.ChiShare))
+	}
+}
+
+func TestPreSignature_Marshal(t *testing.T) {
+	group := curve.Secp256k1{}
+	_, _, preSignatures := NewPreSignatures(group, 3)
+	fingerprint := []byte("config fingerprint")
+	for _, preSignature := range preSignatures {
+		preSignature.ID = []byte("01234567890123456789012345678901")
+		preSignature.ConfigFingerprint = fingerprint
+
+		data, err := preSignature.MarshalBinary()
+		require.NoError(t, err)
+		restored := EmptyPreSignature(group)
+		require.NoError(t, restored.UnmarshalBinary(data))
+		assert.Equal(t, preSignature.SignatureShare([]byte("hello")), restored.SignatureShare([]byte("hello")))
+		assert.NoError(t, restored.CheckFingerprint(fingerprint))
+		assert.Error(t, restored.CheckFingerprint([]byte("other")))
+
+		// the group is taken from the encoding
+		restored = &PreSignature{}
+		require.NoError(t, restored.UnmarshalBinary(data))
+		assert.True(t, preSignature.R.Equal(restored.R))
+
+		data, err = json.Marshal(preSignature)
+		require.NoError(t, err)
+		restored = &PreSignature{}
+		require.NoError(t, json.Unmarshal(data, restored))
+		assert.Equal(t, preSignature.SignatureShare([]byte("hello")), restored.SignatureShare([]byte("hello")))
+		assert.Equal(t, fingerprint, restored.ConfigFingerprint)
+		assert.Len(t, restored.SignerIDs(), 3)
+
+		// the message of an adapted presignature is preserved
+		preSignature.MessageHash = []byte("message")
+		data, err = preSignature.MarshalBinary()
+		require.NoError(t, err)
+		restored = EmptyPreSignature(group)
+		require.NoError(t, restored.UnmarshalBinary(data))
+		assert.Error(t, restored.CheckMessage([]byte("other")))
+		data, err = json.Marshal(preSignature)
+		require.NoError(t, err)
+		restored = &PreSignature{}
+		require.NoError(t, json.Unmarshal(data, restored))
+		assert.Equal(t, preSignature.MessageHash, restored.MessageHash)
+
+		// invalid presignatures are rejected on load
+		preSignature.KShare = group.NewScalar()
+		data, err = preSignature.MarshalBinary()
+		require.NoError(t, err)
+		assert.Error(t, EmptyPreSignature(group).UnmarshalBinary(data))
+	}
+	assert.Error(t, (&PreSignature{}).CheckFingerprint(fingerprint))
+}
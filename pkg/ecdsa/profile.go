@@ -0,0 +1,318 @@
+package ecdsa
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/taurusgroup/multi-party-sig/internal/base58"
+	"github.com/taurusgroup/multi-party-sig/internal/bech32"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/taproot"
+	"golang.org/x/crypto/ripemd160" //nolint:staticcheck // required by Bitcoin and Cosmos addresses
+	"golang.org/x/crypto/sha3"
+)
+
+// SignatureEncoding is the serialization of a signature expected by a chain.
+type SignatureEncoding uint8
+
+const (
+	// EncodingDER is the ASN.1 DER encoding of (r, s).
+	EncodingDER SignatureEncoding = iota
+	// EncodingCompact is r ‖ s, 64 bytes.
+	EncodingCompact
+	// EncodingRecoverable is r ‖ s ‖ v, 65 bytes, where v ∈ {0, 1} is the recovery ID, as returned by SigEthereum.
+	EncodingRecoverable
+	// EncodingCBOR is the CBOR encoding of the Signature object, with the point R, as in a protocol.ResultEnvelope.
+	EncodingCBOR
+)
+
+// HashAlgorithm is the function applied to a message to obtain the hash being signed.
+type HashAlgorithm uint8
+
+const (
+	HashSHA256 HashAlgorithm = iota
+	// HashDoubleSHA256 is SHA-256(SHA-256(m)), as used by Bitcoin.
+	HashDoubleSHA256
+	HashKeccak256
+)
+
+// AddressFormat is the derivation of an address from a public key.
+type AddressFormat uint8
+
+const (
+	// AddressP2PKH is the Base58Check encoding of HASH160 of the compressed public key, with Profile.AddressVersion.
+	AddressP2PKH AddressFormat = iota
+	// AddressEthereum is the last 20 bytes of Keccak-256 of the uncompressed public key, with an EIP-55 checksum.
+	AddressEthereum
+	// AddressBech32 is the Bech32 encoding of HASH160 of the compressed public key, with Profile.AddressHRP.
+	AddressBech32
+	// AddressP2WPKH is the segwit version 0 address of HASH160 of the compressed public key, with Profile.AddressHRP.
+	AddressP2WPKH
+	// AddressP2TR is the segwit version 1 address of the x-only public key tweaked without a script tree,
+	// as defined by BIP-86, with Profile.AddressHRP.
+	AddressP2TR
+)
+
+// Profile gathers the conventions of a chain for secp256k1 signatures, so that a signature produced by
+// the signing protocols can be serialized without further processing.
+type Profile struct {
+	Name     string
+	Encoding SignatureEncoding
+	// LowS requires s ≤ n/2, and signatures are normalized accordingly.
+	LowS           bool
+	Hash           HashAlgorithm
+	AddressFormat  AddressFormat
+	AddressVersion byte
+	AddressHRP     string
+}
+
+var (
+	ProfileBitcoin = &Profile{
+		Name:          "bitcoin",
+		Encoding:      EncodingDER,
+		LowS:          true,
+		Hash:          HashDoubleSHA256,
+		AddressFormat: AddressP2PKH,
+	}
+	ProfileBitcoinTestnet = &Profile{
+		Name:           "bitcoin-testnet",
+		Encoding:       EncodingDER,
+		LowS:           true,
+		Hash:           HashDoubleSHA256,
+		AddressFormat:  AddressP2PKH,
+		AddressVersion: 0x6f,
+	}
+	ProfileBitcoinSegwit = &Profile{
+		Name:          "bitcoin-segwit",
+		Encoding:      EncodingDER,
+		LowS:          true,
+		Hash:          HashDoubleSHA256,
+		AddressFormat: AddressP2WPKH,
+		AddressHRP:    "bc",
+	}
+	ProfileBitcoinSegwitTestnet = &Profile{
+		Name:          "bitcoin-segwit-testnet",
+		Encoding:      EncodingDER,
+		LowS:          true,
+		Hash:          HashDoubleSHA256,
+		AddressFormat: AddressP2WPKH,
+		AddressHRP:    "tb",
+	}
+	// ProfileBitcoinTaproot only derives addresses: taproot outputs are spent with BIP-340 signatures
+	// rather than ECDSA, so it is not returned by Profiles.
+	ProfileBitcoinTaproot = &Profile{
+		Name:          "bitcoin-taproot",
+		Hash:          HashSHA256,
+		AddressFormat: AddressP2TR,
+		AddressHRP:    "bc",
+	}
+	ProfileEthereum = &Profile{
+		Name:          "ethereum",
+		Encoding:      EncodingRecoverable,
+		LowS:          true,
+		Hash:          HashKeccak256,
+		AddressFormat: AddressEthereum,
+	}
+	ProfileCosmos = &Profile{
+		Name:          "cosmos",
+		Encoding:      EncodingCompact,
+		LowS:          true,
+		Hash:          HashSHA256,
+		AddressFormat: AddressBech32,
+		AddressHRP:    "cosmos",
+	}
+)
+
+// Profiles returns the predefined profiles.
+func Profiles() []*Profile {
+	return []*Profile{
+		ProfileBitcoin, ProfileBitcoinTestnet, ProfileBitcoinSegwit, ProfileBitcoinSegwitTestnet,
+		ProfileEthereum, ProfileCosmos,
+	}
+}
+
+// ProfileByName returns the predefined profile with the given name.
+func ProfileByName(name string) (*Profile, error) {
+	for _, p := range Profiles() {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("ecdsa: unknown profile %q", name)
+}
+
+// Supports returns an error if the profile cannot be used with keys of the given group.
+func (p *Profile) Supports(group curve.Curve) error {
+	if group == nil || group.Name() != (curve.Secp256k1{}).Name() {
+		return fmt.Errorf("ecdsa: profile %s requires secp256k1", p.Name)
+	}
+	return nil
+}
+
+// HashMessage returns the hash of message to be signed, with the hash algorithm of the profile.
+func (p *Profile) HashMessage(message []byte) []byte {
+	switch p.Hash {
+	case HashDoubleSHA256:
+		first := sha256.Sum256(message)
+		second := sha256.Sum256(first[:])
+		return second[:]
+	case HashKeccak256:
+		h := sha3.NewLegacyKeccak256()
+		_, _ = h.Write(message)
+		return h.Sum(nil)
+	default:
+		sum := sha256.Sum256(message)
+		return sum[:]
+	}
+}
+
+// Normalize returns a copy of sig, with s replaced by -s if the profile requires a low s.
+// The returned signature is equally valid, since R is negated alongside s.
+func (p *Profile) Normalize(sig Signature) Signature {
+	group := sig.S.Curve()
+	out := Signature{R: sig.R, S: group.NewScalar().Set(sig.S)}
+	if p.LowS && out.S.IsOverHalfOrder() {
+		out.S.Negate()
+		out.R = out.R.Negate()
+	}
+	return out
+}
+
+// Encode normalizes sig and serializes it with the encoding of the profile.
+func (p *Profile) Encode(sig Signature) ([]byte, error) {
+	if err := p.Supports(sig.S.Curve()); err != nil {
+		return nil, err
+	}
+	sig = p.Normalize(sig)
+	R, err := sig.R.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	s, err := sig.S.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	r := R[1:]
+	switch p.Encoding {
+	case EncodingDER:
+		return encodeDER(r, s), nil
+	case EncodingCompact:
+		return append(append(make([]byte, 0, 64), r...), s...), nil
+	case EncodingRecoverable:
+		// the parity of the y-coordinate of R is the recovery ID
+		return append(append(append(make([]byte, 0, 65), r...), s...), R[0]-2), nil
+	case EncodingCBOR:
+		return cbor.Marshal(sig)
+	default:
+		return nil, fmt.Errorf("ecdsa: unknown signature encoding %d", p.Encoding)
+	}
+}
+
+// encodeDER returns SEQUENCE { INTEGER r, INTEGER s } for big endian r and s.
+func encodeDER(r, s []byte) []byte {
+	r, s = derInteger(r), derInteger(s)
+	out := make([]byte, 0, 6+len(r)+len(s))
+	out = append(out, 0x30, byte(4+len(r)+len(s)))
+	out = append(out, 0x02, byte(len(r)))
+	out = append(out, r...)
+	out = append(out, 0x02, byte(len(s)))
+	return append(out, s...)
+}
+
+// derInteger strips leading zeros from a positive big endian integer,
+// and prepends a zero byte if the high bit is set.
+func derInteger(x []byte) []byte {
+	for len(x) > 1 && x[0] == 0 {
+		x = x[1:]
+	}
+	if x[0]&0x80 != 0 {
+		return append([]byte{0}, x...)
+	}
+	return x
+}
+
+// Address returns the address of the public key X on the chain of the profile.
+func (p *Profile) Address(X curve.Point) (string, error) {
+	if err := p.Supports(X.Curve()); err != nil {
+		return "", err
+	}
+	if X.IsIdentity() {
+		return "", errors.New("ecdsa: public key is the identity")
+	}
+	compressed, err := X.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	switch p.AddressFormat {
+	case AddressP2PKH:
+		return base58.CheckEncode([]byte{p.AddressVersion}, hash160(compressed)), nil
+	case AddressEthereum:
+		key, err := secp256k1.ParsePubKey(compressed)
+		if err != nil {
+			return "", fmt.Errorf("ecdsa: %w", err)
+		}
+		h := sha3.NewLegacyKeccak256()
+		_, _ = h.Write(key.SerializeUncompressed()[1:])
+		return checksumAddress(h.Sum(nil)[12:]), nil
+	case AddressBech32:
+		return bech32.Encode(p.AddressHRP, hash160(compressed))
+	case AddressP2WPKH:
+		return bech32.EncodeSegwit(p.AddressHRP, 0, hash160(compressed))
+	case AddressP2TR:
+		Q, err := taprootOutputKey(X.(*curve.Secp256k1Point))
+		if err != nil {
+			return "", err
+		}
+		return bech32.EncodeSegwit(p.AddressHRP, 1, Q)
+	default:
+		return "", fmt.Errorf("ecdsa: unknown address format %d", p.AddressFormat)
+	}
+}
+
+// taprootOutputKey returns the x-only output key Q = P + int(hash_TapTweak(x(P)))⋅G, where P is X with an even y-coordinate.
+//
+// See: https://github.com/bitcoin/bips/blob/master/bip-0341.mediawiki#constructing-and-spending-taproot-outputs
+func taprootOutputKey(X *curve.Secp256k1Point) ([]byte, error) {
+	P := curve.Point(X)
+	if !X.HasEvenY() {
+		P = X.Negate()
+	}
+	t := new(curve.Secp256k1Scalar)
+	if err := t.UnmarshalBinary(taproot.TaggedHash("TapTweak", X.XBytes())); err != nil {
+		return nil, fmt.Errorf("ecdsa: invalid taproot tweak: %w", err)
+	}
+	Q := P.Add(t.ActOnBase()).(*curve.Secp256k1Point)
+	if Q.IsIdentity() {
+		return nil, errors.New("ecdsa: taproot output key is the identity")
+	}
+	return Q.XBytes(), nil
+}
+
+func hash160(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	h := ripemd160.New()
+	_, _ = h.Write(sum[:])
+	return h.Sum(nil)
+}
+
+// checksumAddress returns the EIP-55 encoding of an Ethereum address.
+func checksumAddress(address []byte) string {
+	lower := hex.EncodeToString(address)
+	h := sha3.NewLegacyKeccak256()
+	_, _ = h.Write([]byte(lower))
+	digest := h.Sum(nil)
+	var sb strings.Builder
+	sb.WriteString("0x")
+	for i, c := range lower {
+		if c >= 'a' && digest[i/2]>>(4*(1-i%2))&0xf >= 8 {
+			c -= 'a' - 'A'
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String()
+}
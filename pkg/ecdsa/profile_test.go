@@ -0,0 +1,121 @@
+package ecdsa
+
+import (
+	"crypto/rand"
+	"encoding/asn1"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/cronokirby/saferith"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/bech32"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+)
+
+func TestProfileAddress(t *testing.T) {
+	group := curve.Secp256k1{}
+	one := group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1))
+	X := one.ActOnBase()
+
+	address, err := ProfileBitcoin.Address(X)
+	require.NoError(t, err)
+	assert.Equal(t, "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", address)
+
+	address, err = ProfileEthereum.Address(X)
+	require.NoError(t, err)
+	assert.Equal(t, "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf", address)
+
+	address, err = ProfileCosmos.Address(X)
+	require.NoError(t, err)
+	hrp, data, err := bech32.Decode(address)
+	require.NoError(t, err)
+	assert.Equal(t, "cosmos", hrp)
+	compressed, _ := X.MarshalBinary()
+	assert.Equal(t, hash160(compressed), data)
+
+	// BIP-173
+	address, err = ProfileBitcoinSegwit.Address(X)
+	require.NoError(t, err)
+	assert.Equal(t, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", address)
+
+	address, err = ProfileBitcoinSegwitTestnet.Address(X)
+	require.NoError(t, err)
+	assert.Equal(t, "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx", address)
+
+	// first receiving address of BIP-86, whose internal key has an even y-coordinate
+	internal, _ := hex.DecodeString("02cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115")
+	P := group.NewPoint()
+	require.NoError(t, P.UnmarshalBinary(internal))
+	address, err = ProfileBitcoinTaproot.Address(P)
+	require.NoError(t, err)
+	assert.Equal(t, "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr", address)
+
+	// the output key only depends on the x-coordinate of the internal key
+	odd, err := ProfileBitcoinTaproot.Address(P.Negate())
+	require.NoError(t, err)
+	assert.Equal(t, address, odd)
+}
+
+func TestProfileEncode(t *testing.T) {
+	group := curve.Secp256k1{}
+	x := sample.Scalar(rand.Reader, group)
+	X := x.ActOnBase()
+
+	for _, p := range Profiles() {
+		hash := p.HashMessage([]byte("hello"))
+		for i := 0; i < 8; i++ {
+			sig := NewSignature(x, hash, nil)
+			normalized := p.Normalize(*sig)
+			assert.False(t, normalized.S.IsOverHalfOrder(), p.Name)
+			assert.True(t, normalized.Verify(X, hash), p.Name)
+
+			data, err := p.Encode(*sig)
+			require.NoError(t, err, p.Name)
+			switch p.Encoding {
+			case EncodingDER:
+				var parsed struct{ R, S *big.Int }
+				rest, err := asn1.Unmarshal(data, &parsed)
+				require.NoError(t, err, p.Name)
+				assert.Empty(t, rest)
+				s, _ := normalized.S.MarshalBinary()
+				assert.Equal(t, new(big.Int).SetBytes(s), parsed.S)
+			case EncodingCompact:
+				assert.Len(t, data, 64)
+			case EncodingRecoverable:
+				recovered, err := RecoverPublicKeyEthereum(group, hash, data)
+				require.NoError(t, err, p.Name)
+				assert.True(t, X.Equal(recovered), p.Name)
+			}
+		}
+	}
+}
+
+func TestVerifyEncoded(t *testing.T) {
+	group := curve.Secp256k1{}
+	x := sample.Scalar(rand.Reader, group)
+	X := x.ActOnBase()
+	other := sample.Scalar(rand.Reader, group).ActOnBase()
+	hash := ProfileBitcoin.HashMessage([]byte("hello"))
+
+	for _, encoding := range []SignatureEncoding{EncodingDER, EncodingCompact, EncodingRecoverable, EncodingCBOR} {
+		for i := 0; i < 8; i++ {
+			sig := NewSignature(x, hash, nil)
+			data, err := (&Profile{Name: "test", Encoding: encoding, LowS: i%2 == 0}).Encode(*sig)
+			require.NoError(t, err)
+			assert.NoError(t, VerifyEncoded(X, hash, data, encoding), encoding)
+			assert.Error(t, VerifyEncoded(other, hash, data, encoding), encoding)
+			assert.Error(t, VerifyEncoded(X, []byte("other"), data, encoding), encoding)
+			assert.Error(t, VerifyEncoded(X, hash, data[:len(data)-1], encoding), encoding)
+		}
+	}
+
+	sig := NewSignature(x, hash, nil)
+	data, err := ProfileEthereum.Encode(*sig)
+	require.NoError(t, err)
+	data[64] ^= 1
+	assert.Error(t, VerifyEncoded(X, hash, data, EncodingRecoverable), "wrong recovery ID should be rejected")
+	assert.Error(t, VerifyEncoded(X, hash, data, EncodingCBOR+1))
+}
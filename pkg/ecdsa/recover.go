@@ -0,0 +1,83 @@
+package ecdsa
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+)
+
+// RecoverPublicKey returns the public key X for which sig is a valid signature of hash,
+// given the recovery ID of the point R, as found in the last byte of SigEthereum.
+//
+// Only the x-coordinate of sig.R is used, as is the case for signatures received from the network.
+// Recovery IDs 2 and 3, which indicate that the x-coordinate of R is larger than the group order,
+// occur with negligible probability and are not supported.
+func RecoverPublicKey(hash []byte, sig Signature, recoveryID byte) (curve.Point, error) {
+	if recoveryID > 1 {
+		return nil, fmt.Errorf("ecdsa: unsupported recovery ID %d", recoveryID)
+	}
+	if sig.R == nil || sig.S == nil || sig.S.IsZero() {
+		return nil, errors.New("ecdsa: invalid signature")
+	}
+	group := sig.S.Curve()
+
+	// R is determined by its x-coordinate and the parity of its y-coordinate
+	data, err := sig.R.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	data[0] = 2 + recoveryID
+	R := group.NewPoint()
+	if err = R.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("ecdsa: %w", err)
+	}
+
+	r := R.XScalar()
+	if r == nil || r.IsZero() {
+		return nil, errors.New("ecdsa: invalid signature")
+	}
+
+	// X = r⁻¹•(s•R - m•G)
+	m := curve.FromHash(group, hash)
+	rInv := group.NewScalar().Set(r).Invert()
+	X := rInv.Act(sig.S.Act(R).Sub(m.ActOnBase()))
+	if X.IsIdentity() {
+		return nil, errors.New("ecdsa: recovered public key is the identity")
+	}
+	return X, nil
+}
+
+// RecoverPublicKeyEthereum returns the public key recovered from a 65 byte signature
+// in the format returned by SigEthereum, with the recovery ID as last byte.
+// Values of 27 and 28 for the recovery ID, as used by some Ethereum APIs, are also accepted.
+func RecoverPublicKeyEthereum(group curve.Curve, hash, sigEthereum []byte) (curve.Point, error) {
+	if len(sigEthereum) != 65 {
+		return nil, fmt.Errorf("ecdsa: invalid length for Ethereum signature: %d", len(sigEthereum))
+	}
+	recoveryID := sigEthereum[64]
+	if recoveryID >= 27 {
+		recoveryID -= 27
+	}
+	R := group.NewPoint()
+	if err := R.UnmarshalBinary(append([]byte{2}, sigEthereum[:32]...)); err != nil {
+		return nil, fmt.Errorf("ecdsa: %w", err)
+	}
+	S := group.NewScalar()
+	if err := S.UnmarshalBinary(sigEthereum[32:64]); err != nil {
+		return nil, fmt.Errorf("ecdsa: %w", err)
+	}
+	return RecoverPublicKey(hash, Signature{R: R, S: S}, recoveryID)
+}
+
+// RecoveryCandidates returns the public keys which could have produced sig on hash,
+// indexed by recovery ID. Entries are nil when no public key can be recovered for that ID.
+func (sig Signature) RecoveryCandidates(hash []byte) []curve.Point {
+	candidates := make([]curve.Point, 2)
+	for id := range candidates {
+		if X, err := RecoverPublicKey(hash, sig, byte(id)); err == nil {
+			candidates[id] = X
+		}
+	}
+	return candidates
+}
@@ -70,3 +70,6 @@ func (sig Signature) SigEthereum() ([]byte, error) {
 
 	return rs, nil
 }
+
+// ResultKind returns "signature", see protocol.KindOf.
+func (Signature) ResultKind() string { return "signature" }
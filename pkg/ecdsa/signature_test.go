@@ -59,3 +59,85 @@ func TestSignature_Verify_Zero(t *testing.T) {
 		t.Error("zero R/S signature should not verify")
 	}
 }
+
+func TestRecoverPublicKey(t *testing.T) {
+	group := curve.Secp256k1{}
+
+	for i := 0; i < 10; i++ {
+		m := make([]byte, 32)
+		_, _ = rand.Read(m)
+		x := sample.Scalar(rand.Reader, group)
+		X := x.ActOnBase()
+		sig := NewSignature(x, m, nil)
+
+		candidates := sig.RecoveryCandidates(m)
+		found := 0
+		for _, candidate := range candidates {
+			if candidate != nil && candidate.Equal(X) {
+				found++
+			}
+		}
+		if found != 1 {
+			t.Fatalf("expected exactly one candidate to match, got %d", found)
+		}
+
+		sigEth, err := sig.SigEthereum()
+		if err != nil {
+			t.Fatal(err)
+		}
+		recovered, err := RecoverPublicKeyEthereum(group, m, sigEth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !recovered.Equal(X) {
+			t.Error("recovered wrong public key from Ethereum signature")
+		}
+		sigEth[64] += 27
+		if recovered, err = RecoverPublicKeyEthereum(group, m, sigEth); err != nil || !recovered.Equal(X) {
+			t.Error("failed to recover with offset recovery ID")
+		}
+
+		m[0] ^= 1
+		if recovered, err = RecoverPublicKeyEthereum(group, m, sigEth); err == nil && recovered.Equal(X) {
+			t.Error("recovered public key for a different message")
+		}
+	}
+
+	if _, err := RecoverPublicKey([]byte("hash"), EmptySignature(group), 2); err == nil {
+		t.Error("recovery ID 2 should be rejected")
+	}
+}
+
+func TestVerifyEthereumAddress(t *testing.T) {
+	group := curve.Secp256k1{}
+	x := sample.Scalar(rand.Reader, group)
+	address, err := ProfileEthereum.Address(x.ActOnBase())
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ProfileEthereum.Address(sample.Scalar(rand.Reader, group).ActOnBase())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		m := make([]byte, 32)
+		_, _ = rand.Read(m)
+		sig := NewSignature(x, m, nil)
+		S := group.NewScalar().Set(sig.S)
+		if err = VerifyEthereumAddress(address, m, *sig); err != nil {
+			t.Fatal(err)
+		}
+		if !sig.S.Equal(S) {
+			t.Fatal("signature was modified")
+		}
+		if err = VerifyEthereumAddress(other, m, *sig); err == nil {
+			t.Error("signature should not recover to another address")
+		}
+		// a signature whose R does not match s, as produced by a faulty normalization
+		broken := Signature{R: sig.R.Negate(), S: sig.S}
+		if err = VerifyEthereumAddress(address, m, broken); err == nil {
+			t.Error("broken signature should not recover to the address")
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package ecdsa
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+)
+
+// ErrInvalidSignature is returned by VerifyEncoded when a well formed signature does not verify.
+var ErrInvalidSignature = errors.New("ecdsa: invalid signature")
+
+// VerifyEncoded returns nil if data is a valid signature of hash by the public key X, serialized with encoding.
+//
+// This allows verifying the output of the signing protocols without any protocol state,
+// in any of the encodings produced by Profile.Encode.
+// Both low and high values of s are accepted, as well as recovery IDs of 27 and 28 for EncodingRecoverable.
+func VerifyEncoded(X curve.Point, hash, data []byte, encoding SignatureEncoding) error {
+	if X == nil || X.IsIdentity() {
+		return errors.New("ecdsa: invalid public key")
+	}
+	group := X.Curve()
+	switch encoding {
+	case EncodingCBOR:
+		sig := EmptySignature(group)
+		if err := cbor.Unmarshal(data, &sig); err != nil {
+			return fmt.Errorf("ecdsa: %w", err)
+		}
+		if !sig.Verify(X, hash) {
+			return ErrInvalidSignature
+		}
+		return nil
+	case EncodingDER:
+		var parsed struct{ R, S *big.Int }
+		rest, err := asn1.Unmarshal(data, &parsed)
+		if err != nil {
+			return fmt.Errorf("ecdsa: %w", err)
+		}
+		if len(rest) > 0 {
+			return errors.New("ecdsa: trailing data after DER signature")
+		}
+		if parsed.R.Sign() <= 0 || parsed.S.Sign() <= 0 || parsed.R.BitLen() > 256 || parsed.S.BitLen() > 256 {
+			return errors.New("ecdsa: DER signature values out of range")
+		}
+		return verifyRS(X, hash, parsed.R.FillBytes(make([]byte, 32)), parsed.S.FillBytes(make([]byte, 32)))
+	case EncodingCompact:
+		if len(data) != 64 {
+			return fmt.Errorf("ecdsa: invalid length for compact signature: %d", len(data))
+		}
+		return verifyRS(X, hash, data[:32], data[32:])
+	case EncodingRecoverable:
+		if len(data) != 65 {
+			return fmt.Errorf("ecdsa: invalid length for recoverable signature: %d", len(data))
+		}
+		if err := verifyRS(X, hash, data[:32], data[32:64]); err != nil {
+			return err
+		}
+		recovered, err := RecoverPublicKeyEthereum(group, hash, data)
+		if err != nil {
+			return err
+		}
+		if !recovered.Equal(X) {
+			return errors.New("ecdsa: recovery ID does not match the public key")
+		}
+		return nil
+	default:
+		return fmt.Errorf("ecdsa: unknown signature encoding %d", encoding)
+	}
+}
+
+// verifyRS verifies the signature given by the big endian r and s, where only the x-coordinate of R is known.
+func verifyRS(X curve.Point, hash, rBytes, sBytes []byte) error {
+	group := X.Curve()
+	r, s := group.NewScalar(), group.NewScalar()
+	if err := r.UnmarshalBinary(rBytes); err != nil {
+		return fmt.Errorf("ecdsa: %w", err)
+	}
+	if err := s.UnmarshalBinary(sBytes); err != nil {
+		return fmt.Errorf("ecdsa: %w", err)
+	}
+	if r.IsZero() || s.IsZero() {
+		return ErrInvalidSignature
+	}
+
+	// R = s⁻¹•(m•G + r•X)
+	m := curve.FromHash(group, hash)
+	sInv := group.NewScalar().Set(s).Invert()
+	R := sInv.Act(m.ActOnBase().Add(r.Act(X)))
+	if R.IsIdentity() || !R.XScalar().Equal(r) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyEthereumAddress serializes a copy of sig with SigEthereum, recovers the public key from the result,
+// and returns an error unless its Ethereum address is equal to address, ignoring the case of the EIP-55 checksum.
+//
+// This is an independent check of the serialization of a signature which verifies as a Signature,
+// and catches regressions in the normalization of s or in the recovery ID before the signature is published.
+func VerifyEthereumAddress(address string, hash []byte, sig Signature) error {
+	if sig.R == nil || sig.S == nil {
+		return errors.New("ecdsa: signature is nil")
+	}
+	group := sig.S.Curve()
+	R, err := sig.R.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("ecdsa: %w", err)
+	}
+	// SigEthereum modifies the signature it is called on
+	clone := Signature{R: group.NewPoint(), S: group.NewScalar().Set(sig.S)}
+	if err = clone.R.UnmarshalBinary(R); err != nil {
+		return fmt.Errorf("ecdsa: %w", err)
+	}
+	data, err := clone.SigEthereum()
+	if err != nil {
+		return fmt.Errorf("ecdsa: %w", err)
+	}
+	X, err := RecoverPublicKeyEthereum(group, hash, data)
+	if err != nil {
+		return err
+	}
+	recovered, err := ProfileEthereum.Address(X)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(recovered, address) {
+		return fmt.Errorf("ecdsa: signature recovers to address %s, expected %s", recovered, address)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// Package elgamal exposes the ElGamal keys of a cmp.Config, so that parties can encrypt data to each other.
+//
+// Scalars are encrypted in the exponent with Encrypt, which allows proving statements about them with Prove,
+// but only recovers message⋅G when decrypting.
+// Arbitrary payloads are encrypted with Seal, which derives a symmetric key from an ephemeral Diffie-Hellman
+// exchange with the ElGamal key of the recipient.
+package elgamal
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"io"
+
+	"github.com/taurusgroup/multi-party-sig/internal/elgamal"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+	zkelog "github.com/taurusgroup/multi-party-sig/pkg/zk/elog"
+)
+
+type (
+	// PublicKey is the ElGamal public key X = x⋅G of a party.
+	PublicKey = elgamal.PublicKey
+	// Nonce is the randomness λ of a Ciphertext.
+	Nonce = elgamal.Nonce
+	// Ciphertext is the encryption (L=λ⋅G, M=m⋅G+λ⋅X) of a scalar m.
+	Ciphertext = elgamal.Ciphertext
+	// Proof shows that a Ciphertext encrypts the discrete logarithm of a public point.
+	Proof = zkelog.Proof
+)
+
+// Encrypt returns the encryption of message to public, as well as the nonce, which is needed by Prove.
+func Encrypt(public PublicKey, message curve.Scalar) (*Ciphertext, Nonce) {
+	return elgamal.Encrypt(public, message)
+}
+
+// Decrypt returns message⋅G, where message is the scalar encrypted in c to the public key secret⋅G.
+func Decrypt(secret curve.Scalar, c *Ciphertext) curve.Point {
+	return c.M.Sub(secret.Act(c.L))
+}
+
+// EmptyCiphertext returns a Ciphertext ready to be unmarshalled.
+func EmptyCiphertext(group curve.Curve) *Ciphertext {
+	return elgamal.Empty(group)
+}
+
+// Prove returns a proof that c, encrypted to public with nonce, encrypts the discrete logarithm of message⋅G.
+// The proof is bound to the state of h, which the verifier must reproduce.
+func Prove(h *hash.Hash, public PublicKey, c *Ciphertext, message curve.Scalar, nonce Nonce) *Proof {
+	group := public.Curve()
+	return zkelog.NewProof(group, h, zkelog.Public{
+		E:             c,
+		ElGamalPublic: public,
+		Base:          group.NewBasePoint(),
+		Y:             message.ActOnBase(),
+	}, zkelog.Private{
+		Y:      message,
+		Lambda: nonce,
+	})
+}
+
+// Verify checks a proof returned by Prove that c, encrypted to public, encrypts the discrete logarithm of Y.
+func Verify(h *hash.Hash, public PublicKey, c *Ciphertext, Y curve.Point, proof *Proof) bool {
+	if !c.Valid() {
+		return false
+	}
+	return proof.Verify(h, zkelog.Public{
+		E:             c,
+		ElGamalPublic: public,
+		Base:          public.Curve().NewBasePoint(),
+		Y:             Y,
+	})
+}
+
+// EmptyProof returns a Proof ready to be unmarshalled.
+func EmptyProof(group curve.Curve) *Proof {
+	return zkelog.Empty(group)
+}
+
+// Envelope is a payload encrypted with Seal.
+type Envelope struct {
+	// Ephemeral = r⋅G is the ephemeral public key of the sender.
+	Ephemeral curve.Point
+	// Data is the payload encrypted with AES-256-GCM under a key derived from r⋅X, followed by its tag.
+	Data []byte
+}
+
+// EmptyEnvelope returns an Envelope ready to be unmarshalled.
+func EmptyEnvelope(group curve.Curve) *Envelope {
+	return &Envelope{Ephemeral: group.NewPoint()}
+}
+
+// Seal encrypts payload to public, and authenticates it along with associatedData,
+// which is not encrypted but must be given to Open.
+func Seal(rand io.Reader, public PublicKey, payload, associatedData []byte) (*Envelope, error) {
+	if public == nil || public.IsIdentity() {
+		return nil, errors.New("elgamal: invalid public key")
+	}
+	r, ephemeral := sample.ScalarPointPair(rand, public.Curve())
+	aead, err := envelopeCipher(public, ephemeral, r.Act(public))
+	if err != nil {
+		return nil, err
+	}
+	// the key is never reused, since it depends on the ephemeral key, so the nonce can be fixed.
+	nonce := make([]byte, aead.NonceSize())
+	return &Envelope{
+		Ephemeral: ephemeral,
+		Data:      aead.Seal(nil, nonce, payload, associatedData),
+	}, nil
+}
+
+// Open decrypts an envelope sealed to secret⋅G with the same associatedData.
+func Open(secret curve.Scalar, env *Envelope, associatedData []byte) ([]byte, error) {
+	if env == nil || env.Ephemeral == nil || env.Ephemeral.IsIdentity() {
+		return nil, errors.New("elgamal: invalid envelope")
+	}
+	aead, err := envelopeCipher(secret.ActOnBase(), env.Ephemeral, secret.Act(env.Ephemeral))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	payload, err := aead.Open(nil, nonce, env.Data, associatedData)
+	if err != nil {
+		return nil, errors.New("elgamal: failed to open envelope")
+	}
+	return payload, nil
+}
+
+// envelopeCipher derives the AES-256-GCM cipher of an envelope from the shared point r⋅X = x⋅(r⋅G).
+func envelopeCipher(public, ephemeral, shared curve.Point) (cipher.AEAD, error) {
+	h := hash.New(hash.BytesWithDomain{TheDomain: "ElGamal Envelope", Bytes: []byte{}})
+	if err := h.WriteAny(public, ephemeral, shared); err != nil {
+		return nil, err
+	}
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(h.Digest(), key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,70 @@
+package elgamal_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/pkg/elgamal"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+)
+
+func TestEncrypt(t *testing.T) {
+	group := curve.Secp256k1{}
+	secret, public := sample.ScalarPointPair(rand.Reader, group)
+	message := sample.Scalar(rand.Reader, group)
+
+	c, nonce := elgamal.Encrypt(public, message)
+	assert.True(t, elgamal.Decrypt(secret, c).Equal(message.ActOnBase()))
+
+	proof := elgamal.Prove(hash.New(), public, c, message, nonce)
+	assert.True(t, elgamal.Verify(hash.New(), public, c, message.ActOnBase(), proof))
+
+	other := sample.Scalar(rand.Reader, group)
+	assert.False(t, elgamal.Verify(hash.New(), public, c, other.ActOnBase(), proof))
+
+	data, err := cbor.Marshal(proof)
+	require.NoError(t, err)
+	decoded := elgamal.EmptyProof(group)
+	require.NoError(t, cbor.Unmarshal(data, decoded))
+	assert.True(t, elgamal.Verify(hash.New(), public, c, message.ActOnBase(), decoded))
+}
+
+func TestSeal(t *testing.T) {
+	group := curve.Secp256k1{}
+	secret, public := sample.ScalarPointPair(rand.Reader, group)
+	payload := []byte("confidential\x00payload")
+	ad := []byte("session")
+
+	env, err := elgamal.Seal(rand.Reader, public, payload, ad)
+	require.NoError(t, err)
+	opened, err := elgamal.Open(secret, env, ad)
+	require.NoError(t, err)
+	assert.Equal(t, payload, opened)
+
+	data, err := cbor.Marshal(env)
+	require.NoError(t, err)
+	decoded := elgamal.EmptyEnvelope(group)
+	require.NoError(t, cbor.Unmarshal(data, decoded))
+	opened, err = elgamal.Open(secret, decoded, ad)
+	require.NoError(t, err)
+	assert.Equal(t, payload, opened)
+
+	_, err = elgamal.Open(secret, env, []byte("other session"))
+	assert.Error(t, err)
+
+	wrongSecret := sample.Scalar(rand.Reader, group)
+	_, err = elgamal.Open(wrongSecret, env, ad)
+	assert.Error(t, err)
+
+	env.Data[0] ^= 1
+	_, err = elgamal.Open(secret, env, ad)
+	assert.Error(t, err)
+
+	_, err = elgamal.Seal(rand.Reader, group.NewPoint(), payload, ad)
+	assert.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package hash
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// ChallengeVersion identifies the way Fiat-Shamir challenges are reduced to scalars,
+// and must be agreed upon by all parties before starting the protocol.
+type ChallengeVersion uint8
+
+const (
+	// ChallengeV1 reduces curve.Curve.SafeScalarBytes bytes of the digest modulo the group order.
+	// It is the default, for compatibility with existing deployments on secp256k1,
+	// whose order is close enough to 2²⁵⁶ for the bias to be negligible.
+	ChallengeV1 ChallengeVersion = iota
+	// ChallengeV2 reduces twice as many bytes as the size of the group order, see curve.FromBytesWide,
+	// which gives uniform challenges for any curve. Challenges which are integers rather than scalars
+	// are not reduced, and are the same as with ChallengeV1, see sample.Challenge.
+	ChallengeV2
+	// ChallengeV3 is the same as ChallengeV2, and additionally binds the challenges of zkfac, zkmod and zkprm
+	// to an explicit ProofContext, rather than relying on the caller having seeded the hash state,
+	// so that these proofs can not be replayed in another session or by another prover.
+	ChallengeV3
+)
+
+// SetChallengeVersion sets the version used to derive challenges from this hash and its clones.
+// Any version other than the default is also written to the hash state,
+// so that proofs created with different versions can never be confused.
+func (hash *Hash) SetChallengeVersion(version ChallengeVersion) {
+	if version != ChallengeV1 {
+		_ = hash.WriteAny(&BytesWithDomain{
+			TheDomain: "Challenge Version",
+			Bytes:     []byte{byte(version)},
+		})
+	}
+	hash.challengeVersion = version
+}
+
+// ChallengeVersion returns the version used to derive challenges from this hash.
+func (hash *Hash) ChallengeVersion() ChallengeVersion {
+	return hash.challengeVersion
+}
+
+// ProofContext identifies the session and the prover of a zero-knowledge proof,
+// and is written to its challenge with ChallengeV3, see WriteProofContext.
+type ProofContext struct {
+	// SSID is the identifier of the session in which the proof is created.
+	SSID []byte
+	// Prover is the ID of the party creating the proof.
+	Prover string
+	// Epoch distinguishes successive proofs by the same prover in the same session,
+	// such as proofs for successive refreshes of a key, and may be left at zero.
+	Epoch uint64
+}
+
+// WriteTo implements io.WriterTo.
+func (c *ProofContext) WriteTo(w io.Writer) (int64, error) {
+	if c == nil || len(c.SSID) == 0 || c.Prover == "" {
+		return 0, io.ErrUnexpectedEOF
+	}
+	out := make([]byte, 0, 24+len(c.SSID)+len(c.Prover))
+	out = binary.BigEndian.AppendUint64(out, uint64(len(c.SSID)))
+	out = append(out, c.SSID...)
+	out = binary.BigEndian.AppendUint64(out, uint64(len(c.Prover)))
+	out = append(out, c.Prover...)
+	out = binary.BigEndian.AppendUint64(out, c.Epoch)
+	n, err := w.Write(out)
+	return int64(n), err
+}
+
+// Domain implements WriterToWithDomain.
+func (*ProofContext) Domain() string {
+	return "Proof Context"
+}
+
+// WriteProofContext writes ctx to the hash state if its version is at least ChallengeV3, and returns an error
+// if ctx is then missing or incomplete. With earlier versions, ctx is ignored so that challenges are unchanged.
+func (hash *Hash) WriteProofContext(ctx *ProofContext) error {
+	if hash.challengeVersion < ChallengeV3 {
+		return nil
+	}
+	if ctx == nil {
+		return errors.New("hash: missing proof context")
+	}
+	return hash.WriteAny(ctx)
+}
+
+// WideChallenges returns true if challenges derived from this hash should use a wide reduction,
+// see sample.Challenge.
+func (hash *Hash) WideChallenges() bool {
+	return hash.challengeVersion >= ChallengeV2
+}
@@ -2,12 +2,13 @@ package hash
 
 import (
 	"bytes"
-	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 
 	"github.com/taurusgroup/multi-party-sig/internal/params"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 )
 
 type (
@@ -29,9 +30,16 @@ func (Commitment) Domain() string {
 	return "Commitment"
 }
 
+// Validate checks that the commitment has the default length and is not 0.
 func (c Commitment) Validate() error {
-	if l := len(c); l != DigestLengthBytes {
-		return fmt.Errorf("commitment: incorrect length (got %d, expected %d)", l, DigestLengthBytes)
+	return c.ValidateProfile(params.DefaultSecurityProfile)
+}
+
+// ValidateProfile checks that the commitment has the digest length of the given profile and is not 0.
+func (c Commitment) ValidateProfile(profile params.SecurityProfile) error {
+	expected := profile.OrDefault().DigestBytes
+	if l := len(c); l != expected {
+		return fmt.Errorf("commitment: incorrect length (got %d, expected %d)", l, expected)
 	}
 	for _, b := range c {
 		if b != 0 {
@@ -55,9 +63,16 @@ func (Decommitment) Domain() string {
 	return "Decommitment"
 }
 
+// Validate checks that the decommitment has the default length and is not 0.
 func (d Decommitment) Validate() error {
-	if l := len(d); l != params.SecBytes {
-		return fmt.Errorf("decommitment: incorrect length (got %d, expected %d)", l, params.SecBytes)
+	return d.ValidateProfile(params.DefaultSecurityProfile)
+}
+
+// ValidateProfile checks that the decommitment has the commitment randomness length of the given profile and is not 0.
+func (d Decommitment) ValidateProfile(profile params.SecurityProfile) error {
+	expected := profile.OrDefault().CommitmentBytes
+	if l := len(d); l != expected {
+		return fmt.Errorf("decommitment: incorrect length (got %d, expected %d)", l, expected)
 	}
 	for _, b := range d {
 		if b != 0 {
@@ -71,9 +86,9 @@ func (d Decommitment) Validate() error {
 // commitment = h(data, decommitment).
 func (hash *Hash) Commit(data ...interface{}) (Commitment, Decommitment, error) {
 	var err error
-	decommitment := Decommitment(make([]byte, params.SecBytes))
+	decommitment := Decommitment(make([]byte, hash.SecurityProfile().CommitmentBytes))
 
-	if _, err = rand.Read(decommitment); err != nil {
+	if _, err = io.ReadFull(platform.Reader, decommitment); err != nil {
 		return nil, nil, fmt.Errorf("hash.Commit: failed to generate decommitment: %w", err)
 	}
 
@@ -96,10 +111,10 @@ func (hash *Hash) Commit(data ...interface{}) (Commitment, Decommitment, error)
 // commitment = h(data, decommitment).
 func (hash *Hash) Decommit(c Commitment, d Decommitment, data ...interface{}) bool {
 	var err error
-	if err = c.Validate(); err != nil {
+	if err = c.ValidateProfile(hash.profile); err != nil {
 		return false
 	}
-	if err = d.Validate(); err != nil {
+	if err = d.ValidateProfile(hash.profile); err != nil {
 		return false
 	}
 
@@ -117,3 +132,86 @@ func (hash *Hash) Decommit(c Commitment, d Decommitment, data ...interface{}) bo
 
 	return bytes.Equal(computedCommitment, c)
 }
+
+// CommitField is a named value included in a commitment, see DecommitFields.
+type CommitField struct {
+	Name  string
+	Value interface{}
+}
+
+// DecommitError describes why a decommitment failed, see DecommitFields.
+type DecommitError struct {
+	// Field is the name of the field responsible for the failure, or is empty if it could not be determined.
+	Field string
+	// Reason describes the failure.
+	Reason string
+	// FieldDigests contains a short digest of each field, in hex, which can be compared with the values
+	// committed to by another implementation.
+	FieldDigests map[string]string
+}
+
+// Error implements error.
+func (e *DecommitError) Error() string {
+	if e.Field == "" {
+		return "decommitment: " + e.Reason
+	}
+	return fmt.Sprintf("decommitment: %s: %s", e.Field, e.Reason)
+}
+
+// DecommitFields is the same as Decommit, but returns a *DecommitError describing the failure.
+//
+// Since the commitment is a hash, the field which does not match can not be determined in general.
+// Instead, the commitment is recomputed with the encoding mistakes which are common between implementations:
+// a field which was not committed, two fields committed in a different order, or trailing fields which were not
+// committed. If none match, the digest of each field is returned so that it can be compared out of band.
+// This is only intended for diagnosing a failure, after Decommit returned false.
+func (hash *Hash) DecommitFields(c Commitment, d Decommitment, fields ...CommitField) error {
+	if err := c.ValidateProfile(hash.profile); err != nil {
+		return &DecommitError{Reason: err.Error()}
+	}
+	if err := d.ValidateProfile(hash.profile); err != nil {
+		return &DecommitError{Reason: err.Error()}
+	}
+	digests := make(map[string]string, len(fields))
+	for _, f := range fields {
+		fieldHash := New()
+		if err := fieldHash.WriteAny(f.Value); err != nil {
+			return &DecommitError{Field: f.Name, Reason: err.Error()}
+		}
+		digests[f.Name] = hex.EncodeToString(fieldHash.Sum()[:8])
+	}
+	matches := func(fields ...CommitField) bool {
+		data := make([]interface{}, len(fields))
+		for i, f := range fields {
+			data[i] = f.Value
+		}
+		return hash.Decommit(c, d, data...)
+	}
+	if matches(fields...) {
+		return nil
+	}
+
+	for i := range fields {
+		without := append(append([]CommitField{}, fields[:i]...), fields[i+1:]...)
+		if matches(without...) {
+			return &DecommitError{Field: fields[i].Name, Reason: "field was not committed", FieldDigests: digests}
+		}
+	}
+	for i := 0; i+1 < len(fields); i++ {
+		swapped := append([]CommitField{}, fields...)
+		swapped[i], swapped[i+1] = swapped[i+1], swapped[i]
+		if matches(swapped...) {
+			return &DecommitError{
+				Field:        fields[i].Name,
+				Reason:       fmt.Sprintf("field was committed after %s", fields[i+1].Name),
+				FieldDigests: digests,
+			}
+		}
+	}
+	for i := len(fields) - 2; i >= 0; i-- {
+		if matches(fields[:i]...) {
+			return &DecommitError{Field: fields[i].Name, Reason: "field and the following ones were not committed", FieldDigests: digests}
+		}
+	}
+	return &DecommitError{Reason: "commitment does not match the fields", FieldDigests: digests}
+}
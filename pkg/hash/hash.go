@@ -22,6 +22,11 @@ const DigestLengthBytes = params.SecBytes * 2 // 64
 // an easily extendable output would work as well.
 type Hash struct {
 	h *blake3.Hasher
+	// challengeVersion is preserved by Clone, so that all hashes derived from a session's hash
+	// compute challenges the same way.
+	challengeVersion ChallengeVersion
+	// profile is preserved by Clone in the same way, see SetSecurityProfile.
+	profile params.SecurityProfile
 }
 
 // New creates a Hash struct where the internal hash function is initialized with "CMP-BLAKE".
@@ -42,10 +47,11 @@ func (hash *Hash) Digest() io.Reader {
 	return hash.h.Digest()
 }
 
-// Sum returns a slice of length DigestLengthBytes resulting from the current hash state.
+// Sum returns a slice of length DigestLengthBytes resulting from the current hash state,
+// or of the length given by the SecurityProfile of the hash.
 // If a different length is required, use io.ReadFull(hash.Digest(), out) instead.
 func (hash *Hash) Sum() []byte {
-	out := make([]byte, DigestLengthBytes)
+	out := make([]byte, hash.SecurityProfile().DigestBytes)
 	if _, err := io.ReadFull(hash.Digest(), out); err != nil {
 		panic(fmt.Sprintf("hash.ReadBytes: internal hash failure: %v", err))
 	}
@@ -127,7 +133,7 @@ func (hash *Hash) WriteAny(data ...interface{}) error {
 
 // Clone returns a copy of the Hash in its current state.
 func (hash *Hash) Clone() *Hash {
-	return &Hash{h: hash.h.Clone()}
+	return &Hash{h: hash.h.Clone(), challengeVersion: hash.challengeVersion, profile: hash.profile}
 }
 
 // Fork clones this hash, and then writes some data.
@@ -136,3 +142,11 @@ func (hash *Hash) Fork(data ...interface{}) *Hash {
 	_ = newHash.WriteAny(data...)
 	return newHash
 }
+
+// ForkLabel clones this hash, and then writes label in its own domain.
+//
+// Proofs computed from the same transcript should each use a distinct label, such as "zkprm" or "zkfac:"+id,
+// so that they never share a challenge, and the transcript separation can be audited by searching for the label.
+func (hash *Hash) ForkLabel(label string) *Hash {
+	return hash.Fork(BytesWithDomain{TheDomain: "Fork Label", Bytes: []byte(label)})
+}
@@ -65,3 +65,49 @@ func TestHash_WriteAny_Collision(t *testing.T) {
 
 	assert.NotEqual(t, h1, h2)
 }
+
+func TestHash_ForkLabel(t *testing.T) {
+	h := New(BytesWithDomain{"test", []byte("transcript")})
+	before := h.Clone().Sum()
+
+	prm := h.ForkLabel("zkprm")
+	assert.Equal(t, before, h.Sum(), "forking should not modify the parent")
+	assert.Equal(t, prm.Sum(), h.ForkLabel("zkprm").Sum())
+	assert.NotEqual(t, prm.Sum(), h.ForkLabel("zkmod").Sum())
+	assert.NotEqual(t, h.ForkLabel("zkfac:a").Sum(), h.ForkLabel("zkfac:b").Sum())
+	assert.NotEqual(t, prm.Sum(), h.Fork([]byte("zkprm")).Sum())
+}
+
+func TestHash_DecommitFields(t *testing.T) {
+	h := New(BytesWithDomain{"test", []byte("commitment")})
+	a, b, c := []byte("a"), []byte("b"), []byte("c")
+	fields := []CommitField{{"a", a}, {"b", b}, {"c", c}}
+	reason := func(err error) string {
+		var decommitErr *DecommitError
+		if !assert.ErrorAs(t, err, &decommitErr) {
+			return ""
+		}
+		return decommitErr.Field
+	}
+
+	com, dec, err := h.Commit(a, b, c)
+	assert.NoError(t, err)
+	assert.NoError(t, h.DecommitFields(com, dec, fields...))
+
+	com, dec, _ = h.Commit(a, c)
+	assert.Equal(t, "b", reason(h.DecommitFields(com, dec, fields...)))
+	com, dec, _ = h.Commit(a, c, b)
+	assert.Equal(t, "b", reason(h.DecommitFields(com, dec, fields...)))
+	com, dec, _ = h.Commit(a)
+	assert.Equal(t, "b", reason(h.DecommitFields(com, dec, fields...)))
+
+	com, dec, _ = h.Commit([]byte("d"), b, c)
+	err = h.DecommitFields(com, dec, fields...)
+	assert.Equal(t, "", reason(err))
+	var decommitErr *DecommitError
+	if assert.ErrorAs(t, err, &decommitErr) {
+		assert.Len(t, decommitErr.FieldDigests, 3)
+	}
+
+	assert.Error(t, h.DecommitFields(com[:10], dec, fields...))
+}
@@ -0,0 +1,28 @@
+package hash
+
+import (
+	"encoding/binary"
+
+	"github.com/taurusgroup/multi-party-sig/internal/params"
+)
+
+// SetSecurityProfile sets the lengths used by Sum and Commit for this hash and its clones.
+// As for SetChallengeVersion, any profile other than the default is also written to the hash state.
+func (hash *Hash) SetSecurityProfile(profile params.SecurityProfile) {
+	if !profile.IsDefault() {
+		lengths := make([]byte, 12)
+		binary.BigEndian.PutUint32(lengths[0:], uint32(profile.CommitmentBytes))
+		binary.BigEndian.PutUint32(lengths[4:], uint32(profile.RIDBytes))
+		binary.BigEndian.PutUint32(lengths[8:], uint32(profile.DigestBytes))
+		_ = hash.WriteAny(&BytesWithDomain{
+			TheDomain: "Security Profile",
+			Bytes:     lengths,
+		})
+	}
+	hash.profile = profile
+}
+
+// SecurityProfile returns the profile of this hash, which is params.DefaultSecurityProfile unless set otherwise.
+func (hash *Hash) SecurityProfile() params.SecurityProfile {
+	return hash.profile.OrDefault()
+}
@@ -0,0 +1,95 @@
+package curve
+
+import (
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// UnmarshalPoints decodes a list of points of the given group, each encoded with MarshalBinary,
+// such as the coefficients of a VSS polynomial.
+//
+// For secp256k1, the prefix and range of every encoding are checked before any point is decompressed,
+// so that a list containing a malformed encoding is rejected without computing the square roots of the others.
+// The decoded points are affine, so encoding or hashing them later does not require a field inversion.
+// The error for an invalid encoding contains its index, and matches ErrInvalidPoint.
+func UnmarshalPoints(group Curve, data [][]byte) ([]Point, error) {
+	points := make([]Point, len(data))
+	if _, ok := group.(Secp256k1); !ok {
+		for i := range data {
+			points[i] = group.NewPoint()
+			if err := points[i].UnmarshalBinary(data[i]); err != nil {
+				return nil, fmt.Errorf("curve: point %d: %w", i, err)
+			}
+		}
+		return points, nil
+	}
+
+	values := make([]Secp256k1Point, len(data))
+	odd := make([]bool, len(data))
+	for i := range data {
+		var err error
+		if odd[i], err = secp256k1ParseX(data[i], &values[i].value.X); err != nil {
+			return nil, fmt.Errorf("curve: point %d: %w", i, err)
+		}
+	}
+	for i := range values {
+		if err := secp256k1Decompress(&values[i].value, odd[i]); err != nil {
+			return nil, fmt.Errorf("curve: point %d: %w", i, err)
+		}
+		points[i] = &values[i]
+	}
+	return points, nil
+}
+
+// NormalizePoints converts the given points to affine coordinates in place, so that encoding, hashing or comparing
+// them later does not require a field inversion for each one.
+// This is useful for points which are computed once and then hashed many times, such as the public shares
+// of all parties at the end of a keygen.
+//
+// For secp256k1, the inversions of all points are replaced by a single one using Montgomery's trick,
+// at the cost of three multiplications per point. Points of other groups, and identity points, are left unchanged.
+// The points must not be used concurrently while they are being normalized.
+func NormalizePoints(points ...Point) {
+	values := make([]*secp256k1.JacobianPoint, 0, len(points))
+	for _, p := range points {
+		q, ok := p.(*Secp256k1Point)
+		if !ok || q == nil {
+			continue
+		}
+		q.value.Z.Normalize()
+		if q.value.Z.IsZero() || q.value.Z.IsOne() {
+			continue
+		}
+		values = append(values, &q.value)
+	}
+	if len(values) == 0 {
+		return
+	}
+
+	// products[i] = Z₀⋯Zᵢ
+	products := make([]secp256k1.FieldVal, len(values))
+	products[0].Set(&values[0].Z)
+	for i := 1; i < len(values); i++ {
+		products[i].Mul2(&products[i-1], &values[i].Z).Normalize()
+	}
+
+	// inv = (Z₀⋯Zᵢ)⁻¹, starting from the last point
+	var inv, zInv, zInv2 secp256k1.FieldVal
+	inv.Set(&products[len(values)-1]).Inverse()
+	for i := len(values) - 1; i >= 0; i-- {
+		v := values[i]
+		// Zᵢ⁻¹ = (Z₀⋯Zᵢ)⁻¹•(Z₀⋯Zᵢ₋₁)
+		if i > 0 {
+			zInv.Mul2(&inv, &products[i-1])
+			inv.Mul(&v.Z)
+		} else {
+			zInv.Set(&inv)
+		}
+		zInv.Normalize()
+		zInv2.SquareVal(&zInv)
+		v.X.Mul(&zInv2).Normalize()
+		v.Y.Mul(zInv2.Mul(&zInv)).Normalize()
+		v.Z.SetInt(1)
+	}
+}
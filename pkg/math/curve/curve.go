@@ -173,3 +173,12 @@ func FromHash(group Curve, h []byte) Scalar {
 	}
 	return group.NewScalar().SetNat(s)
 }
+
+// FromBytesWide interprets data as a big-endian integer and reduces it modulo the order of the group.
+//
+// When data is at least twice as long as the order, as the 64 bytes of a hash digest are for a 256 bit curve,
+// the result is statistically close to uniform. This is not the case for FromHash, which truncates its input.
+func FromBytesWide(group Curve, data []byte) Scalar {
+	s := new(saferith.Nat).SetBytes(data)
+	return group.NewScalar().SetNat(s.Mod(s, group.Order()))
+}
@@ -0,0 +1,90 @@
+package curve
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrInvalidPoint is matched by errors.Is for every InvalidPointError.
+var ErrInvalidPoint = errors.New("curve: invalid point")
+
+// InvalidPointError is returned when decoding a point which is not canonically encoded, or not on the curve.
+// Points received from other parties must always be decoded, so that they are checked before being used
+// in a scalar multiplication.
+type InvalidPointError struct {
+	// Group is the name of the curve.
+	Group string
+	// Reason describes the problem with the encoding.
+	Reason string
+}
+
+// Error implements error.
+func (e *InvalidPointError) Error() string {
+	return fmt.Sprintf("curve: invalid %s point: %s", e.Group, e.Reason)
+}
+
+// Is returns true for ErrInvalidPoint.
+func (e *InvalidPointError) Is(target error) bool {
+	return target == ErrInvalidPoint
+}
+
+var lenientPoints atomic.Bool
+
+// SetStrictPointEncoding controls whether points with a non-canonical prefix are rejected, which is the default.
+// Disabling it normalizes such legacy encodings instead, and should only be used to read data
+// which was stored by an older implementation. Points must still be on the curve.
+func SetStrictPointEncoding(strict bool) {
+	lenientPoints.Store(!strict)
+}
+
+// StrictPointEncoding returns true if points with a non-canonical prefix are rejected, see SetStrictPointEncoding.
+func StrictPointEncoding() bool {
+	return !lenientPoints.Load()
+}
+
+// ToHexCompressed returns the hex encoding of the compressed form of p,
+// as produced by its MarshalBinary method.
+func ToHexCompressed(p Point) string {
+	data, err := p.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(data)
+}
+
+// PointFromHex parses a point of the given group encoded by ToHexCompressed.
+func PointFromHex(group Curve, s string) (Point, error) {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("curve: invalid hex point: %w", err)
+	}
+	p := group.NewPoint()
+	if err = p.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("curve: invalid point: %w", err)
+	}
+	return p, nil
+}
+
+// ScalarToHex returns the hex encoding of the big endian bytes of s.
+func ScalarToHex(s Scalar) string {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(data)
+}
+
+// ScalarFromHex parses a scalar of the given group encoded by ScalarToHex.
+func ScalarFromHex(group Curve, s string) (Scalar, error) {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("curve: invalid hex scalar: %w", err)
+	}
+	x := group.NewScalar()
+	if err = x.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("curve: invalid scalar: %w", err)
+	}
+	return x, nil
+}
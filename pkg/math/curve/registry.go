@@ -0,0 +1,57 @@
+package curve
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	registryMtx sync.RWMutex
+	registry    = map[string]func() Curve{}
+)
+
+func init() {
+	Register(Secp256k1{}.Name(), func() Curve { return Secp256k1{} })
+}
+
+// Register makes a curve available by name to ByName, so that serialized data which only records
+// the name of its curve can be decoded with implementations from other packages.
+// The name should be the one returned by the curve's Name method.
+//
+// Register panics if name is empty, or if a curve was already registered under that name.
+// It is safe for concurrent use, but is typically called from an init function.
+func Register(name string, constructor func() Curve) {
+	if name == "" || constructor == nil {
+		panic("curve: Register with empty name or nil constructor")
+	}
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("curve: Register called twice for %s", name))
+	}
+	registry[name] = constructor
+}
+
+// ByName returns the curve registered under name.
+func ByName(name string) (Curve, error) {
+	registryMtx.RLock()
+	constructor, ok := registry[name]
+	registryMtx.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("curve: unknown curve %q", name)
+	}
+	return constructor(), nil
+}
+
+// Registered returns the sorted names of all registered curves.
+func Registered() []string {
+	registryMtx.RLock()
+	defer registryMtx.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,36 @@
+package curve_test
+
+import (
+	"testing"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+)
+
+type otherCurve struct{ curve.Secp256k1 }
+
+func (otherCurve) Name() string { return "test-curve" }
+
+func TestRegistry(t *testing.T) {
+	group, err := curve.ByName("secp256k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := group.(curve.Secp256k1); !ok {
+		t.Errorf("expected secp256k1, got %T", group)
+	}
+
+	if _, err = curve.ByName("test-curve"); err == nil {
+		t.Error("unregistered curve should not be found")
+	}
+	curve.Register("test-curve", func() curve.Curve { return otherCurve{} })
+	if group, err = curve.ByName("test-curve"); err != nil || group.Name() != "test-curve" {
+		t.Errorf("registered curve not found: %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a curve twice should panic")
+		}
+	}()
+	curve.Register("secp256k1", func() curve.Curve { return curve.Secp256k1{} })
+}
@@ -2,6 +2,7 @@ package curve
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -192,7 +193,7 @@ func (*Secp256k1Point) Curve() Curve {
 }
 
 func (p *Secp256k1Point) XBytes() []byte {
-	p.value.ToAffine()
+	secp256k1ToAffine(&p.value)
 	return p.value.X.Bytes()[:]
 }
 
@@ -200,7 +201,7 @@ func (p *Secp256k1Point) MarshalBinary() ([]byte, error) {
 	out := make([]byte, 33)
 	// we clone v to not case a race during a hash.Write
 	v := p.value
-	v.ToAffine()
+	secp256k1ToAffine(&v)
 	// Doing it this way is compatible with Bitcoin
 	out[0] = byte(v.Y.IsOddBit()) + 2
 	data := v.X.Bytes()
@@ -209,19 +210,84 @@ func (p *Secp256k1Point) MarshalBinary() ([]byte, error) {
 }
 
 func (p *Secp256k1Point) UnmarshalBinary(data []byte) error {
+	var v secp256k1.JacobianPoint
+	odd, err := secp256k1ParseX(data, &v.X)
+	if err != nil {
+		return err
+	}
+	if err = secp256k1Decompress(&v, odd); err != nil {
+		return err
+	}
+	p.value = v
+	return nil
+}
+
+// secp256k1ParseX checks the prefix and the range of the x coordinate of a compressed point, which is set in x,
+// and returns whether the y coordinate is odd.
+func secp256k1ParseX(data []byte, x *secp256k1.FieldVal) (bool, error) {
 	if len(data) != 33 {
-		return fmt.Errorf("invalid length for secp256k1Point: %d", len(data))
+		return false, &InvalidPointError{Group: "secp256k1", Reason: fmt.Sprintf("invalid length %d", len(data))}
 	}
-	p.value.Z.SetInt(1)
-	if p.value.X.SetByteSlice(data[1:]) {
-		return fmt.Errorf("secp256k1Point.UnmarshalBinary: x coordinate out of range")
+	var odd bool
+	switch data[0] {
+	case 2, 3:
+		odd = data[0] == 3
+	default:
+		if StrictPointEncoding() {
+			return false, &InvalidPointError{Group: "secp256k1", Reason: fmt.Sprintf("invalid prefix %#x", data[0])}
+		}
+		// legacy encodings are normalized, any prefix other than 3 indicates an even y coordinate
+		odd = data[0] == 3
 	}
-	if !secp256k1.DecompressY(&p.value.X, data[0] == 3, &p.value.Y) {
-		return fmt.Errorf("secp256k1Point.UnmarshalBinary: x coordinate not on curve")
+	if x.SetByteSlice(data[1:]) {
+		return false, &InvalidPointError{Group: "secp256k1", Reason: "x coordinate out of range"}
 	}
+	return odd, nil
+}
+
+// secp256k1Decompress sets the y and z coordinates of v, whose x coordinate was set by secp256k1ParseX.
+func secp256k1Decompress(v *secp256k1.JacobianPoint, odd bool) error {
+	v.Z.SetInt(1)
+	if !secp256k1.DecompressY(&v.X, odd, &v.Y) {
+		return &InvalidPointError{Group: "secp256k1", Reason: "x coordinate not on curve"}
+	}
+	v.Y.Normalize()
 	return nil
 }
 
+// secp256k1ToAffine is the same as JacobianPoint.ToAffine, but skips the field inversion
+// for points which are already affine, such as decoded points or points normalized with NormalizePoints.
+func secp256k1ToAffine(v *secp256k1.JacobianPoint) {
+	if !v.Z.IsOne() {
+		v.ToAffine()
+		return
+	}
+	v.X.Normalize()
+	v.Y.Normalize()
+}
+
+// MarshalJSON implements json.Marshaler, and encodes p as the hex string of its compressed form.
+func (p *Secp256k1Point) MarshalJSON() ([]byte, error) {
+	data, err := p.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(hex.EncodeToString(data))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, and applies the same validation as UnmarshalBinary.
+func (p *Secp256k1Point) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return &InvalidPointError{Group: "secp256k1", Reason: err.Error()}
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return &InvalidPointError{Group: "secp256k1", Reason: err.Error()}
+	}
+	return p.UnmarshalBinary(b)
+}
+
 func (p *Secp256k1Point) Add(that Point) Point {
 	other := secp256k1CastPoint(that)
 
@@ -252,8 +318,8 @@ func (p *Secp256k1Point) Negate() Point {
 func (p *Secp256k1Point) Equal(that Point) bool {
 	other := secp256k1CastPoint(that)
 
-	p.value.ToAffine()
-	other.value.ToAffine()
+	secp256k1ToAffine(&p.value)
+	secp256k1ToAffine(&other.value)
 	return p.value.X.Equals(&other.value.X) && p.value.Y.Equals(&other.value.Y) && p.value.Z.Equals(&other.value.Z)
 }
 
@@ -262,13 +328,13 @@ func (p *Secp256k1Point) IsIdentity() bool {
 }
 
 func (p *Secp256k1Point) HasEvenY() bool {
-	p.value.ToAffine()
+	secp256k1ToAffine(&p.value)
 	return !p.value.Y.IsOdd()
 }
 
 func (p *Secp256k1Point) XScalar() Scalar {
 	out := new(Secp256k1Scalar)
-	p.value.ToAffine()
+	secp256k1ToAffine(&p.value)
 	out.value.SetBytes(p.value.X.Bytes())
 	return out
 }
@@ -0,0 +1,157 @@
+package curve_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+)
+
+func TestSecp256k1PointEncoding(t *testing.T) {
+	group := curve.Secp256k1{}
+	p := sample.Scalar(rand.Reader, group).ActOnBase()
+	data, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := group.NewPoint()
+	if err = q.UnmarshalBinary(data); err != nil || !q.Equal(p) {
+		t.Error("failed to decode a valid point", err)
+	}
+
+	legacy := append([]byte{}, data...)
+	legacy[0] = 4
+	if err = q.UnmarshalBinary(legacy); !errors.Is(err, curve.ErrInvalidPoint) {
+		t.Errorf("expected ErrInvalidPoint for an invalid prefix, got %v", err)
+	}
+	curve.SetStrictPointEncoding(false)
+	err = q.UnmarshalBinary(legacy)
+	curve.SetStrictPointEncoding(true)
+	if err != nil {
+		t.Error("legacy prefix should be normalized when not strict", err)
+	}
+
+	// x = 5 is not the x coordinate of a point on secp256k1
+	offCurve := make([]byte, 33)
+	offCurve[0], offCurve[32] = 2, 5
+	if err = q.UnmarshalBinary(offCurve); !errors.Is(err, curve.ErrInvalidPoint) {
+		t.Errorf("expected ErrInvalidPoint for a point not on the curve, got %v", err)
+	}
+	if _, err = q.MarshalBinary(); err != nil {
+		t.Error(err)
+	}
+
+	encoded, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := group.NewPoint()
+	if err = json.Unmarshal(encoded, r); err != nil || !r.Equal(p) {
+		t.Error("failed to decode a point from JSON", err)
+	}
+	if err = json.Unmarshal([]byte(`"zz"`), r); !errors.Is(err, curve.ErrInvalidPoint) {
+		t.Errorf("expected ErrInvalidPoint for invalid hex, got %v", err)
+	}
+}
+
+func TestUnmarshalPoints(t *testing.T) {
+	group := curve.Secp256k1{}
+	points := make([]curve.Point, 5)
+	data := make([][]byte, len(points))
+	for i := range points {
+		points[i] = sample.Scalar(rand.Reader, group).ActOnBase()
+		var err error
+		if data[i], err = points[i].MarshalBinary(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	decoded, err := curve.UnmarshalPoints(group, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range points {
+		if !decoded[i].Equal(points[i]) {
+			t.Errorf("point %d was not decoded correctly", i)
+		}
+	}
+
+	data[3] = append([]byte{}, data[3]...)
+	data[3][0] = 4
+	if _, err = curve.UnmarshalPoints(group, data); !errors.Is(err, curve.ErrInvalidPoint) {
+		t.Errorf("expected ErrInvalidPoint for an invalid prefix, got %v", err)
+	}
+}
+
+func TestNormalizePoints(t *testing.T) {
+	group := curve.Secp256k1{}
+	points := make([]curve.Point, 6)
+	expected := make([][]byte, len(points))
+	for i := range points {
+		points[i] = sample.Scalar(rand.Reader, group).ActOnBase().Add(group.NewBasePoint())
+		var err error
+		if expected[i], err = points[i].MarshalBinary(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// identity and affine points are left as they are
+	points = append(points, group.NewPoint(), group.NewBasePoint())
+	curve.NormalizePoints(points...)
+	for i := range expected {
+		data, err := points[i].MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, expected[i]) {
+			t.Errorf("point %d changed after normalization", i)
+		}
+	}
+	if !points[6].IsIdentity() || !points[7].Equal(group.NewBasePoint()) {
+		t.Error("identity or base point changed after normalization")
+	}
+}
+
+func BenchmarkNormalizePoints(b *testing.B) {
+	group := curve.Secp256k1{}
+	points := make([]curve.Point, 100)
+	for i := range points {
+		points[i] = sample.Scalar(rand.Reader, group).ActOnBase()
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		batch := make([]curve.Point, len(points))
+		for j := range points {
+			batch[j] = points[j].Add(group.NewBasePoint())
+		}
+		b.StartTimer()
+		curve.NormalizePoints(batch...)
+		for _, p := range batch {
+			_, _ = p.MarshalBinary()
+		}
+	}
+}
+
+func BenchmarkMarshalPoints(b *testing.B) {
+	group := curve.Secp256k1{}
+	points := make([]curve.Point, 100)
+	for i := range points {
+		points[i] = sample.Scalar(rand.Reader, group).ActOnBase()
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		batch := make([]curve.Point, len(points))
+		for j := range points {
+			batch[j] = points[j].Add(group.NewBasePoint())
+		}
+		b.StartTimer()
+		for _, p := range batch {
+			_, _ = p.MarshalBinary()
+		}
+	}
+}
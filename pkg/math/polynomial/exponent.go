@@ -15,6 +15,12 @@ type rawExponentData struct {
 	Coefficients []curve.Point
 }
 
+// rawExponentEncoding is the encoding of rawExponentData, in which the coefficients are not decoded yet.
+type rawExponentEncoding struct {
+	IsConstant   bool
+	Coefficients [][]byte
+}
+
 // Exponent represent a polynomial F(X) whose coefficients belong to a group 𝔾.
 type Exponent struct {
 	group curve.Curve
@@ -95,16 +101,19 @@ func (p *Exponent) Degree() int {
 }
 
 func (p *Exponent) add(q *Exponent) error {
-	if len(p.coefficients) != len(q.coefficients) {
-		return errors.New("q is not the same length as p")
+	if p.Degree() != q.Degree() {
+		return errors.New("q is not the same degree as p")
 	}
 
-	if p.IsConstant != q.IsConstant {
-		return errors.New("p and q differ in 'IsConstant'")
+	// if only one of them has an identity constant, the sum has the constant of the other one
+	if p.IsConstant && !q.IsConstant {
+		p.coefficients = append([]curve.Point{p.group.NewPoint()}, p.coefficients...)
+		p.IsConstant = false
 	}
+	offset := len(p.coefficients) - len(q.coefficients)
 
-	for i := 0; i < len(p.coefficients); i++ {
-		p.coefficients[i] = p.coefficients[i].Add(q.coefficients[i])
+	for i := 0; i < len(q.coefficients); i++ {
+		p.coefficients[i+offset] = p.coefficients[i+offset].Add(q.coefficients[i])
 	}
 
 	return nil
@@ -189,17 +198,24 @@ func (e *Exponent) UnmarshalBinary(data []byte) error {
 		return errors.New("can't unmarshal Exponent with no group")
 	}
 	group := e.group
-	size := binary.BigEndian.Uint32(data)
-	e.coefficients = make([]curve.Point, int(size))
-	for i := 0; i < len(e.coefficients); i++ {
-		e.coefficients[i] = group.NewPoint()
+	if len(data) < 4 {
+		return errors.New("exponent: data too short")
 	}
-	rawExponent := rawExponentData{Coefficients: e.coefficients}
+	size := binary.BigEndian.Uint32(data)
+	// the coefficients are decoded together, so that they are all checked before being decompressed
+	var rawExponent rawExponentEncoding
 	if err := cbor.Unmarshal(data[4:], &rawExponent); err != nil {
 		return err
 	}
+	if len(rawExponent.Coefficients) != int(size) {
+		return errors.New("exponent: wrong number of coefficients")
+	}
+	coefficients, err := curve.UnmarshalPoints(group, rawExponent.Coefficients)
+	if err != nil {
+		return err
+	}
 	e.group = group
-	e.coefficients = rawExponent.Coefficients
+	e.coefficients = coefficients
 	e.IsConstant = rawExponent.IsConstant
 	return nil
 }
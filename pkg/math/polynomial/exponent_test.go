@@ -54,7 +54,11 @@ func TestSum(t *testing.T) {
 	polys := make([]*Polynomial, N)
 	polysExp := make([]*Exponent, N)
 	for i := range polys {
-		sec := sample.Scalar(rand.Reader, group)
+		// polynomials with and without a constant can be summed
+		sec := group.NewScalar()
+		if i%3 != 0 {
+			sec = sample.Scalar(rand.Reader, group)
+		}
 		polys[i] = NewPolynomial(group, Deg, sec)
 		polysExp[i] = NewPolynomialExponent(polys[i])
 
@@ -1,10 +1,9 @@
 package polynomial
 
 import (
-	"crypto/rand"
-
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 )
 
 // Polynomial represents f(X) = a₀ + a₁⋅X + … + aₜ⋅Xᵗ.
@@ -28,7 +27,7 @@ func NewPolynomial(group curve.Curve, degree int, constant curve.Scalar) *Polyno
 	polynomial.coefficients[0] = constant
 
 	for i := 1; i <= degree; i++ {
-		polynomial.coefficients[i] = sample.Scalar(rand.Reader, group)
+		polynomial.coefficients[i] = sample.Scalar(platform.Reader, group)
 	}
 
 	return polynomial
@@ -95,6 +95,31 @@ func Scalar(rand io.Reader, group curve.Curve) curve.Scalar {
 	return group.NewScalar().SetNat(n)
 }
 
+// ScalarWide returns a new *curve.Scalar by reading twice as many bytes as the size of the group order from rand,
+// and reducing them with curve.FromBytesWide.
+func ScalarWide(rand io.Reader, group curve.Curve) curve.Scalar {
+	buffer := make([]byte, 2*((group.Order().BitLen()+7)/8))
+	mustReadBits(rand, buffer)
+	return curve.FromBytesWide(group, buffer)
+}
+
+// Challenge returns a Fiat-Shamir challenge scalar by reading from digest.
+// If wide is set, the reduction of ScalarWide is used, otherwise that of Scalar.
+//
+// Only the challenges used as scalars modulo the group order are reduced, and derived with Challenge:
+// those of zksch, zklog, zkelog and of config certificates. The other proofs use their challenge as an integer,
+// in exponents over a Paillier or Pedersen modulus, where reducing it modulo the group order would be incorrect.
+// zkaffg, zkaffp, zkdec, zkenc, zkencelg, zklogstar, zkmul and zkmulstar sample it from ±q with IntervalScalar,
+// zkfac and zknth from ±2ˡ with IntervalL, zkmod samples elements of ℤₙ with ModN, and zkprm derives single bits.
+// These samplers read exactly the bits of their range, or reject values outside of it, so they are uniform
+// and have none of the bias which the wide reduction avoids.
+func Challenge(digest io.Reader, group curve.Curve, wide bool) curve.Scalar {
+	if wide {
+		return ScalarWide(digest, group)
+	}
+	return Scalar(digest, group)
+}
+
 // ScalarUnit returns a new *curve.Scalar by reading bytes from rand.
 func ScalarUnit(rand io.Reader, group curve.Curve) curve.Scalar {
 	for i := 0; i < maxIterations; i++ {
@@ -1,12 +1,14 @@
 package sample
 
 import (
+	"bytes"
 	"crypto/rand"
 	"math/big"
 	"testing"
 
 	"github.com/cronokirby/saferith"
 	"github.com/taurusgroup/multi-party-sig/internal/params"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/pool"
 )
 
@@ -19,6 +21,20 @@ func TestModN(t *testing.T) {
 	}
 }
 
+func TestScalarWide(t *testing.T) {
+	group := curve.Secp256k1{}
+	// 2⁵¹² - 1 reduced modulo the order must equal FromBytesWide
+	input := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 512), big.NewInt(1))
+	expected := new(big.Int).Mod(input, group.Order().Big())
+	s := ScalarWide(bytes.NewReader(input.Bytes()), group)
+	if curve.MakeInt(s).Big().Cmp(expected) != 0 {
+		t.Errorf("ScalarWide(2⁵¹² - 1) = %v, expected %v", curve.MakeInt(s).Big(), expected)
+	}
+	if Challenge(bytes.NewReader(input.Bytes()), group, false).Equal(s) {
+		t.Error("narrow challenge should differ from wide challenge")
+	}
+}
+
 const blumPrimeProbabilityIterations = 20
 
 func TestPaillier(t *testing.T) {
@@ -1,14 +1,18 @@
+// Package mta implements the multiplicative-to-additive share conversion used by CMP.
+//
+// Given a receiver's encryption Encⱼ(bⱼ) and a sender's secret aᵢ, the sender produces
+// D = Encⱼ(aᵢ•bⱼ - β) and keeps β, so that after decryption the receiver obtains α such that
+// α + β = aᵢ•bⱼ. The sender also proves with zkaffg or zkaffp that D was computed correctly.
 package mta
 
 import (
-	"crypto/rand"
-
 	"github.com/cronokirby/saferith"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
 	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 	zkaffg "github.com/taurusgroup/multi-party-sig/pkg/zk/affg"
 	zkaffp "github.com/taurusgroup/multi-party-sig/pkg/zk/affp"
 )
@@ -83,7 +87,7 @@ func ProveAffP(group curve.Curve, h *hash.Hash,
 
 func newMta(senderSecretShare *saferith.Int, receiverEncryptedShare *paillier.Ciphertext,
 	sender *paillier.SecretKey, receiver *paillier.PublicKey) (D, F *paillier.Ciphertext, S, R *saferith.Nat, BetaNeg *saferith.Int) {
-	BetaNeg = sample.IntervalLPrime(rand.Reader)
+	BetaNeg = sample.IntervalLPrime(platform.Reader)
 
 	F, R = sender.Enc(BetaNeg) // F = encᵢ(-β, r)
 
@@ -93,3 +97,81 @@ func newMta(senderSecretShare *saferith.Int, receiverEncryptedShare *paillier.Ci
 
 	return
 }
+
+// AffGOutput contains the values sent by the sender of ProveAffG to the receiver.
+// It can be serialized using CBOR, after being initialized with EmptyAffGOutput.
+type AffGOutput struct {
+	// D = (aᵢ ⊙ Bⱼ) ⊕ encⱼ(-β, s)
+	D *paillier.Ciphertext
+	// F = encᵢ(-β, r)
+	F *paillier.Ciphertext
+	// Proof is the zkaffg proof that D and F were computed correctly.
+	Proof *zkaffg.Proof
+}
+
+// EmptyAffGOutput returns an AffGOutput with the group set, ready for unmarshalling.
+func EmptyAffGOutput(group curve.Curve) *AffGOutput {
+	return &AffGOutput{Proof: zkaffg.Empty(group)}
+}
+
+// AffPOutput contains the values sent by the sender of ProveAffP to the receiver.
+// It can be serialized using CBOR.
+type AffPOutput struct {
+	// D = (aᵢ ⊙ Bⱼ) ⊕ encⱼ(-β, s)
+	D *paillier.Ciphertext
+	// F = encᵢ(-β, r)
+	F *paillier.Ciphertext
+	// Proof is the zkaffp proof that D and F were computed correctly.
+	Proof *zkaffp.Proof
+}
+
+// VerifyAffG verifies the output of ProveAffG, as received by the receiver.
+// h must be initialized in the same way as the sender's hash function.
+// - senderSecretSharePoint = Aᵢ = aᵢ⋅G
+// - receiverEncryptedShare = Encⱼ(bⱼ)
+// - sender is the sender's Paillier public key
+// - receiver is the receiver's Paillier public key
+// - verifier are the receiver's Pedersen parameters.
+func VerifyAffG(h *hash.Hash, output *AffGOutput, senderSecretSharePoint curve.Point, receiverEncryptedShare *paillier.Ciphertext,
+	sender, receiver *paillier.PublicKey, verifier *pedersen.Parameters) bool {
+	if output == nil || output.Proof == nil {
+		return false
+	}
+	return output.Proof.Verify(h, zkaffg.Public{
+		Kv:       receiverEncryptedShare,
+		Dv:       output.D,
+		Fp:       output.F,
+		Xp:       senderSecretSharePoint,
+		Prover:   sender,
+		Verifier: receiver,
+		Aux:      verifier,
+	})
+}
+
+// VerifyAffP verifies the output of ProveAffP, as received by the receiver.
+// h must be initialized in the same way as the sender's hash function.
+// - senderEncryptedShare = Encᵢ(aᵢ)
+// - receiverEncryptedShare = Encⱼ(bⱼ)
+// - sender is the sender's Paillier public key
+// - receiver is the receiver's Paillier public key
+// - verifier are the receiver's Pedersen parameters.
+func VerifyAffP(group curve.Curve, h *hash.Hash, output *AffPOutput, senderEncryptedShare, receiverEncryptedShare *paillier.Ciphertext,
+	sender, receiver *paillier.PublicKey, verifier *pedersen.Parameters) bool {
+	if output == nil || output.Proof == nil {
+		return false
+	}
+	return output.Proof.Verify(group, h, zkaffp.Public{
+		Kv:       receiverEncryptedShare,
+		Dv:       output.D,
+		Fp:       output.F,
+		Xp:       senderEncryptedShare,
+		Prover:   sender,
+		Verifier: receiver,
+		Aux:      verifier,
+	})
+}
+
+// Alpha returns the receiver's additive share α = Decⱼ(D), such that α + β = aᵢ•bⱼ.
+func Alpha(receiver *paillier.SecretKey, D *paillier.Ciphertext) (*saferith.Int, error) {
+	return receiver.Dec(D)
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/cronokirby/saferith"
+	"github.com/fxamacker/cbor/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
@@ -107,3 +108,47 @@ func Test_newMtA(t *testing.T) {
 	}
 
 }
+
+func TestOutputs(t *testing.T) {
+	group := curve.Secp256k1{}
+	source := mrand.New(mrand.NewSource(2))
+
+	sender, receiver := zk.ProverPaillierSecret, zk.VerifierPaillierSecret
+	aScalar, bScalar := sample.Scalar(source, group), sample.Scalar(source, group)
+	a, A := curve.MakeInt(aScalar), aScalar.ActOnBase()
+	B, _ := receiver.Enc(curve.MakeInt(bScalar))
+	expected := group.NewScalar().Set(aScalar).Mul(bScalar)
+
+	checkShares := func(D *paillier.Ciphertext, beta *saferith.Int) {
+		alpha, err := Alpha(receiver, D)
+		require.NoError(t, err)
+		sum := alpha.Add(alpha, beta, -1)
+		assert.Equal(t, expected, group.NewScalar().SetNat(sum.Mod(group.Order())), "a•b should be equal to α + β")
+	}
+
+	{
+		beta, D, F, proof := ProveAffG(group, hash.New(), a, A, B, sender, receiver.PublicKey, zk.Pedersen)
+		data, err := cbor.Marshal(&AffGOutput{D: D, F: F, Proof: proof})
+		require.NoError(t, err)
+		output := EmptyAffGOutput(group)
+		require.NoError(t, cbor.Unmarshal(data, output))
+
+		assert.True(t, VerifyAffG(hash.New(), output, A, B, sender.PublicKey, receiver.PublicKey, zk.Pedersen))
+		assert.False(t, VerifyAffG(hash.New(), output, A.Add(A), B, sender.PublicKey, receiver.PublicKey, zk.Pedersen))
+		assert.False(t, VerifyAffG(hash.New(), nil, A, B, sender.PublicKey, receiver.PublicKey, zk.Pedersen))
+		checkShares(output.D, beta)
+	}
+
+	{
+		encA, nonce := sender.Enc(a)
+		beta, D, F, proof := ProveAffP(group, hash.New(), a, encA, nonce, B, sender, receiver.PublicKey, zk.Pedersen)
+		data, err := cbor.Marshal(&AffPOutput{D: D, F: F, Proof: proof})
+		require.NoError(t, err)
+		output := &AffPOutput{}
+		require.NoError(t, cbor.Unmarshal(data, output))
+
+		assert.True(t, VerifyAffP(group, hash.New(), output, encA, B, sender.PublicKey, receiver.PublicKey, zk.Pedersen))
+		assert.False(t, VerifyAffP(group, hash.New(), output, B, B, sender.PublicKey, receiver.PublicKey, zk.Pedersen))
+		checkShares(output.D, beta)
+	}
+}
@@ -1,12 +1,12 @@
 package paillier
 
 import (
-	"crypto/rand"
 	"io"
 
 	"github.com/cronokirby/saferith"
 	"github.com/taurusgroup/multi-party-sig/internal/params"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 )
 
 // Ciphertext represents an integer of the for (1+N)ᵐρᴺ (mod N²), representing the encryption of m ∈ ℤₙˣ.
@@ -38,6 +38,31 @@ func (ct *Ciphertext) Mul(pk *PublicKey, k *saferith.Int) *Ciphertext {
 	return ct
 }
 
+// AddPlaintext sets ct to the homomorphic sum of ct and the plaintext m, without fresh randomness.
+// ct ← ct•(1+N)ᵐ (mod N²).
+//
+// The nonce of ct is unchanged, so that the result can be linked to ct by anyone who knows the nonce.
+// Call Randomize afterwards if the result is sent to the owner of the secret key.
+func (ct *Ciphertext) AddPlaintext(pk *PublicKey, m *saferith.Int) *Ciphertext {
+	if m == nil {
+		return ct
+	}
+
+	tmp := pk.nSquared.ExpI(pk.nPlusOne, m)
+	ct.c.ModMul(ct.c, tmp, pk.nSquared.Modulus)
+
+	return ct
+}
+
+// ScalarMulPlaintext sets ct to the affine combination k ⊙ ct ⊕ m, as computed by the receiver of
+// a multiplicative-to-additive share conversion.
+// ct ← ctᵏ•(1+N)ᵐ (mod N²).
+//
+// As with AddPlaintext, no fresh randomness is added, and the nonce of ct is raised to the power k.
+func (ct *Ciphertext) ScalarMulPlaintext(pk *PublicKey, k, m *saferith.Int) *Ciphertext {
+	return ct.Mul(pk, k).AddPlaintext(pk, m)
+}
+
 // Equal check whether ct ≡ ctₐ (mod N²).
 func (ct *Ciphertext) Equal(ctA *Ciphertext) bool {
 	return ct.c.Eq(ctA.c) == 1
@@ -54,9 +79,12 @@ func (ct Ciphertext) Clone() *Ciphertext {
 // ct ← ct ⋅ nonceᴺ (mod N²).
 // If nonce is nil, a random one is generated.
 // The receiver is updated, and the nonce update is returned.
+//
+// The result decrypts to the same plaintext, but cannot be linked to the original ciphertext
+// without knowledge of the nonce. If ct was encrypted with nonce ρ, the new nonce is ρ⋅nonce (mod N).
 func (ct *Ciphertext) Randomize(pk *PublicKey, nonce *saferith.Nat) *saferith.Nat {
 	if nonce == nil {
-		nonce = sample.UnitModN(rand.Reader, pk.n.Modulus)
+		nonce = sample.UnitModN(platform.Reader, pk.n.Modulus)
 	}
 	// c = c*r^N
 	tmp := pk.nSquared.Exp(nonce, pk.nNat)
@@ -164,6 +164,61 @@ func TestDecWithRandomness(t *testing.T) {
 	}
 }
 
+func testAffineHomomorphic(k, x, y uint64, kNeg, yNeg bool) bool {
+	mX := new(saferith.Int).SetUint64(x)
+	mY := new(saferith.Int).SetUint64(y)
+	if yNeg {
+		mY.Neg(1)
+	}
+	kInt := new(saferith.Int).SetUint64(k)
+	if kNeg {
+		kInt.Neg(1)
+	}
+	c, nonce := paillierPublic.Enc(mX)
+	c.ScalarMulPlaintext(paillierPublic, kInt, mY)
+	expected := new(saferith.Int).Mul(mX, kInt, -1)
+	expected.Add(expected, mY, -1)
+	actual, actualNonce, err := paillierSecret.DecWithRandomness(c)
+	if err != nil || actual.Eq(expected) != 1 {
+		return false
+	}
+	// the nonce is only raised to the power k
+	return actualNonce.Eq(new(saferith.Nat).ExpI(nonce, kInt, paillierPublic.N())) == 1
+}
+
+func TestAffineHomomorphic(t *testing.T) {
+	if !testing.Short() {
+		reinit()
+	}
+	err := quick.Check(testAffineHomomorphic, &quick.Config{})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRandomize(t *testing.T) {
+	m := new(saferith.Int).SetUint64(42)
+	c, nonce := paillierPublic.Enc(m)
+	original := c.Clone()
+
+	update := c.Randomize(paillierPublic, nil)
+	assert.False(t, c.Equal(original), "randomized ciphertext should differ")
+
+	actual, actualNonce, err := paillierSecret.DecWithRandomness(c)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, int(actual.Eq(m)))
+	expectedNonce := new(saferith.Nat).ModMul(nonce, update, paillierPublic.N())
+	assert.Equal(t, 1, int(actualNonce.Eq(expectedNonce)))
+
+	c = original.Clone().AddPlaintext(paillierPublic, new(saferith.Int).SetUint64(8))
+	actual, actualNonce, err = paillierSecret.DecWithRandomness(c)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, int(actual.Eq(new(saferith.Int).SetUint64(50))))
+	// the sampled nonce is only a unit, and may not be reduced modulo N
+	expectedNonce = new(saferith.Nat).Mod(nonce, paillierPublic.N())
+	assert.Equal(t, 1, int(actualNonce.Eq(expectedNonce)), "AddPlaintext should not change the nonce")
+}
+
 // Used to avoid benchmark optimization.
 var resultCiphertext *Ciphertext
 
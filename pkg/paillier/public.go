@@ -1,7 +1,6 @@
 package paillier
 
 import (
-	"crypto/rand"
 	"errors"
 	"fmt"
 	"io"
@@ -10,6 +9,7 @@ import (
 	"github.com/taurusgroup/multi-party-sig/internal/params"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/arith"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 )
 
 var (
@@ -71,6 +71,18 @@ func ValidateN(n *saferith.Modulus) error {
 	return nil
 }
 
+// Encrypter encrypts plaintexts under a Paillier public key, as done by PublicKey.
+// Rounds which encrypt values for other parties can be given another implementation in tests,
+// to simulate a party which sends invalid ciphertexts.
+type Encrypter interface {
+	// Enc is the same as PublicKey.Enc.
+	Enc(m *saferith.Int) (*Ciphertext, *saferith.Nat)
+	// EncWithNonce is the same as PublicKey.EncWithNonce.
+	EncWithNonce(m *saferith.Int, nonce *saferith.Nat) *Ciphertext
+}
+
+var _ Encrypter = (*PublicKey)(nil)
+
 // Enc returns the encryption of m under the public key pk.
 // The nonce used to encrypt is returned.
 //
@@ -78,7 +90,7 @@ func ValidateN(n *saferith.Modulus) error {
 //
 // ct = (1+N)ᵐρᴺ (mod N²).
 func (pk PublicKey) Enc(m *saferith.Int) (*Ciphertext, *saferith.Nat) {
-	nonce := sample.UnitModN(rand.Reader, pk.n.Modulus)
+	nonce := sample.UnitModN(platform.Reader, pk.n.Modulus)
 	return pk.EncWithNonce(m, nonce), nonce
 }
 
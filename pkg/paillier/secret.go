@@ -1,7 +1,6 @@
 package paillier
 
 import (
-	"crypto/rand"
 	"errors"
 	"fmt"
 
@@ -10,6 +9,7 @@ import (
 	"github.com/taurusgroup/multi-party-sig/pkg/math/arith"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 	"github.com/taurusgroup/multi-party-sig/pkg/pool"
 )
 
@@ -65,7 +65,7 @@ func KeyGen(pl *pool.Pool) (pk *PublicKey, sk *SecretKey) {
 // NewSecretKey generates primes p and q suitable for the scheme, and returns the initialized SecretKey.
 func NewSecretKey(pl *pool.Pool) *SecretKey {
 	// TODO maybe we could take the reader as argument?
-	return NewSecretKeyFromPrimes(sample.Paillier(rand.Reader, pl))
+	return NewSecretKeyFromPrimes(sample.Paillier(platform.Reader, pl))
 }
 
 // NewSecretKeyFromPrimes generates a new SecretKey. Assumes that P and Q are prime.
@@ -149,7 +149,7 @@ func (sk *SecretKey) DecWithRandomness(ct *Ciphertext) (*saferith.Int, *saferith
 }
 
 func (sk SecretKey) GeneratePedersen() (*pedersen.Parameters, *saferith.Nat) {
-	s, t, lambda := sample.Pedersen(rand.Reader, sk.phi, sk.n.Modulus)
+	s, t, lambda := sample.Pedersen(platform.Reader, sk.phi, sk.n.Modulus)
 	ped := pedersen.New(sk.n, s, t)
 	return ped, lambda
 }
@@ -69,6 +69,9 @@ func EmptyPointMap(group curve.Curve) *PointMap {
 	return &PointMap{group: group}
 }
 
+// sortedEncMode encodes maps with sorted keys, so that a PointMap always has the same encoding.
+var sortedEncMode, _ = cbor.EncOptions{Sort: cbor.SortCanonical}.EncMode()
+
 func (m *PointMap) MarshalBinary() ([]byte, error) {
 	pointBytes := make(map[ID]cbor.RawMessage, len(m.Points))
 	var err error
@@ -78,7 +81,7 @@ func (m *PointMap) MarshalBinary() ([]byte, error) {
 			return nil, err
 		}
 	}
-	return cbor.Marshal(pointBytes)
+	return sortedEncMode.Marshal(pointBytes)
 }
 
 func (m *PointMap) UnmarshalBinary(data []byte) error {
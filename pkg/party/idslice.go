@@ -2,9 +2,12 @@ package party
 
 import (
 	"encoding/binary"
+	"fmt"
 	"io"
 	"sort"
 	"strings"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 )
 
 type IDSlice []ID
@@ -113,3 +116,58 @@ func (partyIDs IDSlice) String() string {
 	}
 	return strings.Join(ss, ", ")
 }
+
+// ScalarCollisionError is returned by IDSlice.ValidateScalars when some IDs cannot be used as
+// evaluation points of a polynomial sharing.
+type ScalarCollisionError struct {
+	// Zero contains the IDs which map to the zero scalar.
+	Zero IDSlice
+	// Collisions contains the groups of distinct IDs which map to the same scalar.
+	Collisions []IDSlice
+}
+
+// Error implements error.
+func (e *ScalarCollisionError) Error() string {
+	var parts []string
+	if len(e.Zero) > 0 {
+		parts = append(parts, fmt.Sprintf("zero scalar for IDs [%s]", e.Zero))
+	}
+	for _, c := range e.Collisions {
+		parts = append(parts, fmt.Sprintf("colliding scalar for IDs [%s]", c))
+	}
+	return "party: " + strings.Join(parts, "; ")
+}
+
+// ValidateScalars checks that every ID maps to a distinct, non-zero scalar through ID.Scalar.
+// Otherwise, two parties would receive the same share, or a party would receive the secret itself,
+// and the sharing would be corrupted. The returned error is a *ScalarCollisionError.
+func (partyIDs IDSlice) ValidateScalars(group curve.Curve) error {
+	var result ScalarCollisionError
+	seen := make(map[string]IDSlice, len(partyIDs))
+	var order []string
+	for _, id := range partyIDs {
+		s := id.Scalar(group)
+		if s.IsZero() {
+			result.Zero = append(result.Zero, id)
+			continue
+		}
+		data, err := s.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		key := string(data)
+		if _, ok := seen[key]; !ok {
+			order = append(order, key)
+		}
+		seen[key] = append(seen[key], id)
+	}
+	for _, key := range order {
+		if ids := seen[key]; len(ids) > 1 {
+			result.Collisions = append(result.Collisions, ids)
+		}
+	}
+	if len(result.Zero) == 0 && len(result.Collisions) == 0 {
+		return nil
+	}
+	return &result
+}
@@ -0,0 +1,72 @@
+package party
+
+import "fmt"
+
+// Weights maps parties to the number of shares they hold in a weighted threshold sharing,
+// so that an institution can hold several shares under a single ID, and run a single handler for all of them.
+// Parties which are not in the map hold a single share, so that a nil Weights describes an unweighted sharing.
+//
+// The shares of a party with weight w are the evaluations of the sharing polynomial at the scalars
+// of the IDs returned by ID.WeightedIDs, and the threshold applies to the total weight of the parties.
+type Weights map[ID]int
+
+// Of returns the weight of id, which is 1 if it is not in w.
+func (w Weights) Of(id ID) int {
+	if weight, ok := w[id]; ok {
+		return weight
+	}
+	return 1
+}
+
+// Total returns the sum of the weights of the given parties.
+func (w Weights) Total(ids []ID) int {
+	total := 0
+	for _, id := range ids {
+		total += w.Of(id)
+	}
+	return total
+}
+
+// IsTrivial returns true if all parties have weight 1.
+func (w Weights) IsTrivial() bool {
+	for _, weight := range w {
+		if weight != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// EvaluationPoints returns the IDs of all the shares of the given parties, as returned by ID.WeightedIDs.
+func (w Weights) EvaluationPoints(ids []ID) IDSlice {
+	points := make([]ID, 0, w.Total(ids))
+	for _, id := range ids {
+		points = append(points, id.WeightedIDs(w.Of(id))...)
+	}
+	return NewIDSlice(points)
+}
+
+// Validate returns an error if a weight is not positive.
+func (w Weights) Validate() error {
+	for id, weight := range w {
+		if weight < 1 {
+			return fmt.Errorf("party: weight %d of %s is not positive", weight, id)
+		}
+	}
+	return nil
+}
+
+// WeightedIDs returns the IDs of the shares of a party with the given weight: id itself,
+// followed by the virtual IDs "id/1", …, "id/(weight-1)" of its additional shares.
+// Virtual IDs are only used as evaluation points, and never as the ID of a party in a session.
+func (id ID) WeightedIDs(weight int) []ID {
+	if weight < 1 {
+		weight = 1
+	}
+	ids := make([]ID, 0, weight)
+	ids = append(ids, id)
+	for k := 1; k < weight; k++ {
+		ids = append(ids, ID(fmt.Sprintf("%s/%d", id, k)))
+	}
+	return ids
+}
@@ -68,6 +68,15 @@ func (p Parameters) S() *saferith.Nat { return p.s }
 // T = Sˡ mod N.
 func (p Parameters) T() *saferith.Nat { return p.t }
 
+// Committer computes Pedersen commitments, as done by Parameters.
+// Tests can use another implementation to simulate a party which sends invalid commitments.
+type Committer interface {
+	// Commit is the same as Parameters.Commit.
+	Commit(x, y *saferith.Int) *saferith.Nat
+}
+
+var _ Committer = (*Parameters)(nil)
+
 // Commit computes sˣ tʸ (mod N)
 //
 // x and y are taken as saferith.Int, because we want to keep these values in secret,
@@ -0,0 +1,54 @@
+package platform
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a source of time for deadlines, timeouts and expiry.
+// Hosts which virtualize time, such as some wasip1 runtimes or deterministic simulators,
+// can provide their own implementation with SetClock, or to a single handler.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After returns a channel which receives the current time once d has elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the clock of the platform, see Now.
+var SystemClock Clock = systemClock{}
+
+// DefaultClock is the clock used when none is configured.
+// It delegates to the clock set with SetClock, so that a later call to SetClock also applies to it.
+var DefaultClock Clock = globalClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+var (
+	clockMtx sync.RWMutex
+	clock    = SystemClock
+)
+
+// SetClock replaces the clock used by Now and DefaultClock. A nil clock restores SystemClock.
+func SetClock(c Clock) {
+	if c == nil {
+		c = SystemClock
+	}
+	clockMtx.Lock()
+	clock = c
+	clockMtx.Unlock()
+}
+
+func currentClock() Clock {
+	clockMtx.RLock()
+	defer clockMtx.RUnlock()
+	return clock
+}
+
+type globalClock struct{}
+
+func (globalClock) Now() time.Time                         { return currentClock().Now() }
+func (globalClock) After(d time.Duration) <-chan time.Time { return currentClock().After(d) }
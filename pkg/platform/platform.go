@@ -0,0 +1,90 @@
+// Package platform abstracts the sources of randomness and time used by the library,
+// so that embedders can check that they work correctly on their target.
+//
+// When compiled for wasip1, randomness and time are obtained directly from the WASI host
+// through the random_get and clock_time_get imports. On all other platforms,
+// crypto/rand and the time package are used.
+//
+// Hosts embedding a WebAssembly build should call SelfTest at startup,
+// since a host providing broken entropy would silently compromise all generated keys.
+package platform
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"time"
+)
+
+// Reader is a cryptographically secure source of randomness for the current platform.
+// All the randomness sampled by the library is read from it.
+var Reader io.Reader = reader
+
+// Now returns the current time, as provided by the clock set with SetClock,
+// which defaults to the time of the platform.
+func Now() time.Time {
+	return currentClock().Now()
+}
+
+const (
+	// selfTestBytes is the amount of randomness sampled by SelfTestReader.
+	selfTestBytes = 1024
+	// selfTestBits is the number of bits in selfTestBytes.
+	selfTestBits = 8 * selfTestBytes
+	// selfTestMaxDeviation is the maximum allowed difference between the number of ones and selfTestBits/2.
+	// For uniform bits, the standard deviation is √(8192)/2 ≈ 45, so this corresponds to roughly 6.5σ.
+	selfTestMaxDeviation = 300
+)
+
+// SelfTest checks that the platform's sources of randomness and time are functional.
+// It returns an error describing the first failure found.
+func SelfTest() error {
+	if err := SelfTestReader(Reader); err != nil {
+		return err
+	}
+	t1 := Now()
+	if t1.IsZero() || t1.Unix() <= 0 {
+		return errors.New("platform: clock returned an invalid time")
+	}
+	if t2 := Now(); t2.Before(t1) {
+		return errors.New("platform: clock went backwards")
+	}
+	return nil
+}
+
+// SelfTestReader performs basic health checks on a source of randomness.
+// These checks cannot prove that r is secure, but detect obviously broken sources,
+// such as ones returning errors, constant output, or repeated blocks.
+func SelfTestReader(r io.Reader) error {
+	buf1 := make([]byte, selfTestBytes)
+	buf2 := make([]byte, selfTestBytes)
+	if _, err := io.ReadFull(r, buf1); err != nil {
+		return fmt.Errorf("platform: failed to read randomness: %w", err)
+	}
+	if _, err := io.ReadFull(r, buf2); err != nil {
+		return fmt.Errorf("platform: failed to read randomness: %w", err)
+	}
+	if bytes.Equal(buf1, buf2) {
+		return errors.New("platform: randomness source repeats its output")
+	}
+	// repeated 32 byte blocks within a single read
+	blocks := make(map[string]struct{}, selfTestBytes/32)
+	for i := 0; i+32 <= len(buf1); i += 32 {
+		block := string(buf1[i : i+32])
+		if _, ok := blocks[block]; ok {
+			return errors.New("platform: randomness source contains repeated blocks")
+		}
+		blocks[block] = struct{}{}
+	}
+	// monobit test
+	ones := 0
+	for _, b := range buf1 {
+		ones += bits.OnesCount8(b)
+	}
+	if deviation := ones - selfTestBits/2; deviation > selfTestMaxDeviation || deviation < -selfTestMaxDeviation {
+		return fmt.Errorf("platform: randomness source is biased (%d ones out of %d bits)", ones, selfTestBits)
+	}
+	return nil
+}
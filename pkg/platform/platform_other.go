@@ -0,0 +1,14 @@
+//go:build !wasip1
+
+package platform
+
+import (
+	"crypto/rand"
+	"time"
+)
+
+var reader = rand.Reader
+
+func now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,66 @@
+package platform
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	mrand "math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("no entropy") }
+
+type constantReader byte
+
+func (c constantReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(c)
+	}
+	return len(p), nil
+}
+
+func TestSelfTest(t *testing.T) {
+	assert.NoError(t, SelfTest())
+	assert.NoError(t, SelfTestReader(rand.Reader))
+	assert.Error(t, SelfTestReader(failingReader{}))
+	assert.Error(t, SelfTestReader(constantReader(0)))
+	assert.Error(t, SelfTestReader(constantReader(0x5a)), "constant output must be detected even when balanced")
+
+	// a seeded generator reset between reads returns the same output
+	block := make([]byte, 2*selfTestBytes)
+	_, _ = mrand.New(mrand.NewSource(1)).Read(block[:selfTestBytes])
+	copy(block[selfTestBytes:], block[:selfTestBytes])
+	assert.Error(t, SelfTestReader(bytes.NewReader(block)))
+}
+
+// fixedClock always returns the same time, and fires After immediately.
+type fixedClock struct {
+	t time.Time
+}
+
+func (c fixedClock) Now() time.Time { return c.t }
+func (c fixedClock) After(time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- c.t
+	return ch
+}
+
+func TestSetClock(t *testing.T) {
+	fixed := fixedClock{t: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	SetClock(fixed)
+	defer SetClock(nil)
+
+	assert.Equal(t, fixed.t, Now())
+	assert.Equal(t, fixed.t, DefaultClock.Now())
+	assert.Equal(t, fixed.t, <-DefaultClock.After(time.Hour))
+	assert.NotEqual(t, fixed.t, SystemClock.Now())
+
+	SetClock(nil)
+	assert.NotEqual(t, fixed.t, Now())
+	assert.NotEqual(t, fixed.t, DefaultClock.Now())
+}
@@ -0,0 +1,46 @@
+//go:build wasip1
+
+package platform
+
+import (
+	"errors"
+	"time"
+	"unsafe"
+)
+
+// errnoSuccess is the WASI errno indicating success.
+const errnoSuccess = 0
+
+// clockRealtime is the WASI identifier of the wall clock.
+const clockRealtime = 0
+
+//go:wasmimport wasi_snapshot_preview1 random_get
+//go:noescape
+func randomGet(buf unsafe.Pointer, bufLen uint32) uint32
+
+//go:wasmimport wasi_snapshot_preview1 clock_time_get
+//go:noescape
+func clockTimeGet(id uint32, precision uint64, timestamp unsafe.Pointer) uint32
+
+// wasiReader reads randomness from the host with random_get.
+type wasiReader struct{}
+
+func (wasiReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if errno := randomGet(unsafe.Pointer(&p[0]), uint32(len(p))); errno != errnoSuccess {
+		return 0, errors.New("platform: random_get failed")
+	}
+	return len(p), nil
+}
+
+var reader = wasiReader{}
+
+func now() time.Time {
+	var timestamp uint64
+	if errno := clockTimeGet(clockRealtime, 1, unsafe.Pointer(&timestamp)); errno != errnoSuccess {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(timestamp))
+}
@@ -1,6 +1,9 @@
 package pool
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"runtime"
 	"sync"
@@ -170,7 +173,7 @@ func (p *Pool) Parallelize(count int, f func(int) interface{}) []interface{} {
 
 	ctr := int64(count)
 	ctrChanged := make(chan struct{})
-	cmdI := 0
+	cmdI, done := 0, 0
 	for cmdI < count {
 		cmd := command{
 			search:     false,
@@ -187,15 +190,65 @@ func (p *Pool) Parallelize(count int, f func(int) interface{}) []interface{} {
 		case p.commands <- cmd:
 			cmdI++
 		case <-ctrChanged:
+			done++
 		}
 	}
-	for atomic.LoadInt64(&ctr) > 0 {
+	// every worker signals once per command, so we must receive exactly count signals,
+	// otherwise a worker which decremented ctr before we read it would block forever.
+	for done < count {
 		<-ctrChanged
+		done++
 	}
 
 	return results
 }
 
+// ParallelizeErr calls f count times, passing in indices from 0..count-1, and returns the errors
+// of all calls which failed, joined in the order of their indices.
+//
+// When a call fails, or when ctx is done, calls which have not started yet are skipped.
+// A panic in f is recovered and returned as an error, rather than crashing the worker.
+// If calls were skipped only because ctx was done, ctx.Err() is returned.
+func (p *Pool) ParallelizeErr(ctx context.Context, count int, f func(i int) error) error {
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var skipped int64
+	results := p.Parallelize(count, func(i int) interface{} {
+		if ctx.Err() != nil {
+			atomic.AddInt64(&skipped, 1)
+			return nil
+		}
+		if err := callRecover(f, i); err != nil {
+			cancel()
+			return err
+		}
+		return nil
+	})
+
+	var errs []error
+	for _, result := range results {
+		if result != nil {
+			errs = append(errs, result.(error))
+		}
+	}
+	if len(errs) == 0 && atomic.LoadInt64(&skipped) > 0 {
+		return parent.Err()
+	}
+	return errors.Join(errs...)
+}
+
+// callRecover returns the result of f(i), or an error if f panics.
+func callRecover(f func(i int) error, i int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("pool: task %d panicked: %v", i, r)
+		}
+	}()
+	return f(i)
+}
+
 // LockedReader wraps an io.Reader to be safe for concurrent reads.
 //
 // This type implements io.Reader, returning the same output.
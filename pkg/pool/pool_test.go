@@ -0,0 +1,49 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallelizeErr(t *testing.T) {
+	pl := NewPool(2)
+	defer pl.TearDown()
+
+	for _, p := range []*Pool{nil, pl} {
+		var calls int64
+		assert.NoError(t, p.ParallelizeErr(context.Background(), 10, func(int) error {
+			atomic.AddInt64(&calls, 1)
+			return nil
+		}))
+		assert.EqualValues(t, 10, calls)
+
+		errFailed := errors.New("failed")
+		calls = 0
+		err := p.ParallelizeErr(context.Background(), 100, func(i int) error {
+			atomic.AddInt64(&calls, 1)
+			if i == 1 {
+				return errFailed
+			}
+			return nil
+		})
+		assert.ErrorIs(t, err, errFailed)
+		assert.Less(t, calls, int64(100), "calls after the first error should be skipped")
+
+		err = p.ParallelizeErr(context.Background(), 3, func(i int) error {
+			if i == 0 {
+				panic("boom")
+			}
+			return nil
+		})
+		assert.ErrorContains(t, err, "boom")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err = p.ParallelizeErr(ctx, 3, func(int) error { return nil })
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+}
@@ -0,0 +1,106 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+)
+
+// BatchProof shows that a message is included in a batch of messages emitted together,
+// such as all the messages returned after a round is finalized.
+//
+// A single signature over the root of the batch can then authenticate every message in it,
+// instead of signing each message individually.
+type BatchProof struct {
+	// Index is the position of the message in the batch.
+	Index int
+	// Size is the number of messages in the batch.
+	Size int
+	// Path contains the sibling hashes from the leaf up to the root.
+	Path [][]byte
+}
+
+// BatchRoot returns the Merkle root of the hashes of msgs, in the given order.
+func BatchRoot(msgs []*Message) ([]byte, error) {
+	root, _, err := NewBatch(msgs)
+	return root, err
+}
+
+// NewBatch returns the Merkle root of the hashes of msgs, along with a proof of inclusion for each message.
+func NewBatch(msgs []*Message) ([]byte, []*BatchProof, error) {
+	if len(msgs) == 0 {
+		return nil, nil, errors.New("batch: no messages")
+	}
+	level := make([][]byte, len(msgs))
+	proofs := make([]*BatchProof, len(msgs))
+	// positions[i] is the index in level of the node containing message i
+	positions := make([]int, len(msgs))
+	for i, msg := range msgs {
+		if msg == nil {
+			return nil, nil, errors.New("batch: nil message")
+		}
+		level[i] = batchLeaf(msg)
+		proofs[i] = &BatchProof{Index: i, Size: len(msgs)}
+		positions[i] = i
+	}
+	for len(level) > 1 {
+		for i, pos := range positions {
+			// an unpaired last node is promoted to the next level as is
+			if sibling := pos ^ 1; sibling < len(level) {
+				proofs[i].Path = append(proofs[i].Path, level[sibling])
+			}
+			positions[i] = pos / 2
+		}
+		level = batchLevel(level)
+	}
+	return level[0], proofs, nil
+}
+
+// Verify returns true if msg is included in the batch with the given root.
+func (p *BatchProof) Verify(msg *Message, root []byte) bool {
+	if p == nil || msg == nil || p.Size <= 0 || p.Index < 0 || p.Index >= p.Size {
+		return false
+	}
+	node := batchLeaf(msg)
+	pos, size, path := p.Index, p.Size, p.Path
+	for size > 1 {
+		if sibling := pos ^ 1; sibling < size {
+			if len(path) == 0 {
+				return false
+			}
+			if pos%2 == 0 {
+				node = batchNode(node, path[0])
+			} else {
+				node = batchNode(path[0], node)
+			}
+			path = path[1:]
+		}
+		pos /= 2
+		size = (size + 1) / 2
+	}
+	return len(path) == 0 && bytes.Equal(node, root)
+}
+
+func batchLevel(level [][]byte) [][]byte {
+	next := make([][]byte, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		if i+1 == len(level) {
+			next = append(next, level[i])
+		} else {
+			next = append(next, batchNode(level[i], level[i+1]))
+		}
+	}
+	return next
+}
+
+func batchLeaf(msg *Message) []byte {
+	return hash.New(hash.BytesWithDomain{TheDomain: "Batch Leaf", Bytes: msg.Hash()}).Sum()
+}
+
+func batchNode(left, right []byte) []byte {
+	return hash.New(
+		hash.BytesWithDomain{TheDomain: "Batch Node Left", Bytes: left},
+		hash.BytesWithDomain{TheDomain: "Batch Node Right", Bytes: right},
+	).Sum()
+}
@@ -0,0 +1,46 @@
+package protocol_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+)
+
+func TestBatch(t *testing.T) {
+	_, err := protocol.BatchRoot(nil)
+	assert.Error(t, err)
+
+	for size := 1; size <= 9; size++ {
+		msgs := make([]*protocol.Message, size)
+		for i := range msgs {
+			msgs[i] = &protocol.Message{
+				SSID:        []byte("ssid"),
+				From:        "a",
+				To:          party.ID(fmt.Sprint(i)),
+				Protocol:    "test",
+				RoundNumber: 2,
+				Data:        []byte{byte(i)},
+			}
+		}
+		root, proofs, err := protocol.NewBatch(msgs)
+		require.NoError(t, err)
+		require.Len(t, proofs, size)
+		otherRoot, err := protocol.BatchRoot(msgs)
+		require.NoError(t, err)
+		assert.Equal(t, root, otherRoot)
+
+		for i, proof := range proofs {
+			assert.True(t, proof.Verify(msgs[i], root), "size %d, index %d", size, i)
+			if size > 1 {
+				assert.False(t, proof.Verify(msgs[(i+1)%size], root), "size %d, index %d", size, i)
+			}
+			tampered := *msgs[i]
+			tampered.Data = []byte("tampered")
+			assert.False(t, proof.Verify(&tampered, root))
+		}
+	}
+}
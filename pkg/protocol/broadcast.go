@@ -0,0 +1,187 @@
+package protocol
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// BroadcastHashVersion identifies the way the echo broadcast hash is computed,
+// and must be agreed upon by all parties before starting the protocol.
+type BroadcastHashVersion uint8
+
+const (
+	// BroadcastHashV1 hashes the broadcast messages of a round using the session's hash state.
+	// It is the default, for compatibility with existing deployments.
+	BroadcastHashV1 BroadcastHashVersion = iota
+	// BroadcastHashV2 additionally binds the hash to the protocol ID, SSID and round number,
+	// so that the hash of one round can never be confused with another's.
+	BroadcastHashV2
+	// BroadcastHashV3 additionally hashes each message over its canonical Header, content and broadcast verification,
+	// rather than over the fields of Message, so that the hash can be recomputed from the canonical encoding alone.
+	BroadcastHashV3
+)
+
+// storeBroadcastDigest records the hash of a broadcast message when it is stored,
+// so that each message is hashed once as it arrives, rather than all at once when the round completes.
+// Only messages from the parties of the round count towards the broadcast hash.
+func (h *MultiHandler) storeBroadcastDigest(msg *Message) {
+	digests := h.broadcastDigests[msg.RoundNumber]
+	if digests == nil {
+		digests = make(map[party.ID][]byte, h.currentRound.N())
+		h.broadcastDigests[msg.RoundNumber] = digests
+	}
+	// stored messages passed canAccept, or were emitted by this handler, so their header is valid
+	digests[msg.From], _ = h.broadcastDigest(msg)
+}
+
+// broadcastDigest returns the hash of a single broadcast message, as included in the broadcast hash.
+func (h *MultiHandler) broadcastDigest(msg *Message) ([]byte, error) {
+	if h.hashVersion >= BroadcastHashV3 {
+		return msg.canonicalDigest()
+	}
+	return msg.Hash(), nil
+}
+
+// computeBroadcastHash returns the hash of all broadcast messages received for the round r.
+// It must only be called once all broadcast messages have been received.
+// The digests of the round's messages are released afterwards.
+func (h *MultiHandler) computeBroadcastHash(r round.Session) []byte {
+	digests := h.broadcastDigests[r.Number()]
+	delete(h.broadcastDigests, r.Number())
+	return h.hashDigests(r, digests)
+}
+
+// hashDigests returns the broadcast hash of the round r, given the digest of the broadcast message of every party.
+func (h *MultiHandler) hashDigests(r round.Session, digests map[party.ID][]byte) []byte {
+	hashState := r.Hash()
+	if h.hashVersion >= BroadcastHashV2 {
+		_ = hashState.WriteAny(
+			&hash.BytesWithDomain{TheDomain: "Broadcast Hash Version", Bytes: []byte{byte(h.hashVersion)}},
+			&hash.BytesWithDomain{TheDomain: "Protocol ID", Bytes: []byte(r.ProtocolID())},
+			&hash.BytesWithDomain{TheDomain: "SSID", Bytes: r.SSID()},
+			r.Number(),
+		)
+	}
+	for _, id := range r.PartyIDs() {
+		_ = hashState.WriteAny(&hash.BytesWithDomain{
+			TheDomain: "Message",
+			Bytes:     digests[id],
+		})
+	}
+	return hashState.Sum()
+}
+
+// copyBroadcastHashes returns a copy of the broadcast hashes computed so far, and must be called with h.mtx held.
+func (h *MultiHandler) copyBroadcastHashes() map[round.Number][]byte {
+	hashes := make(map[round.Number][]byte, len(h.broadcastHashes))
+	for number, digest := range h.broadcastHashes {
+		hashes[number] = bytes.Clone(digest)
+	}
+	return hashes
+}
+
+// BroadcastHash returns the hash of all broadcast messages of the given round, as computed by this handler.
+// It returns nil if the round did not expect broadcast messages, or if they have not all been received yet.
+func (h *MultiHandler) BroadcastHash(number round.Number) []byte {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return bytes.Clone(h.broadcastHashes[number])
+}
+
+// VerifyBroadcastHash returns true if broadcastHash is equal to the hash computed by this handler for the given round.
+// This can be used to compare the BroadcastVerification field of a message with the handler's view of the broadcast.
+func (h *MultiHandler) VerifyBroadcastHash(number round.Number, broadcastHash []byte) bool {
+	expected := h.BroadcastHash(number)
+	return expected != nil && bytes.Equal(expected, broadcastHash)
+}
+
+// ImportBroadcastHash recovers the broadcast hash of the given round from a peer, for example when this handler
+// was restored from a snapshot taken before it received all broadcast messages of the round,
+// so that it can verify the messages of the following round without restarting the session.
+//
+// broadcasts must contain the broadcast message of every party of the round, as stored by the peer,
+// which can be obtained from the Mailbox it exports if it keeps the messages of past rounds.
+// They are cross-checked against the broadcast messages stored by this handler, and the hash recomputed from them
+// must be equal to broadcastHash. For the current round, the missing messages are then delivered as with Accept.
+//
+// If the handler has already computed the hash of the round, it is only compared with broadcastHash.
+// An error is returned if the hashes or messages differ, which indicates that the peer did not receive the same
+// broadcast messages, or if the round is no longer available, which is the case for rounds older than the previous
+// one unless the handler was created with HandlerOptions.KeepAllRounds.
+func (h *MultiHandler) ImportBroadcastHash(number round.Number, broadcastHash []byte, broadcasts []*Message) error {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	if local, ok := h.broadcastHashes[number]; ok {
+		if !bytes.Equal(local, broadcastHash) {
+			return fmt.Errorf("protocol: imported broadcast hash for round %d differs from the local one", number)
+		}
+		return nil
+	}
+	if h.err != nil {
+		return h.err
+	}
+	r, ok := h.rounds[number]
+	if !ok || number > h.currentRound.Number() {
+		return fmt.Errorf("protocol: round %d is not available", number)
+	}
+	if _, ok = r.(round.BroadcastRound); !ok {
+		return fmt.Errorf("protocol: round %d has no broadcast messages", number)
+	}
+
+	stored := h.broadcast[number]
+	imported := make(map[party.ID]*Message, r.N())
+	digests := make(map[party.ID][]byte, r.N())
+	for _, msg := range broadcasts {
+		if msg == nil || !msg.Broadcast || msg.RoundNumber != number || !r.PartyIDs().Contains(msg.From) ||
+			msg.Protocol != r.ProtocolID() || !bytes.Equal(msg.SSID, r.SSID()) {
+			return fmt.Errorf("protocol: imported message is not a broadcast message of round %d", number)
+		}
+		if _, ok = imported[msg.From]; ok {
+			return fmt.Errorf("protocol: imported broadcast messages of round %d contain duplicates from %s", number, msg.From)
+		}
+		// broadcast messages are hashed without their recipient, see store
+		canonical := *msg
+		canonical.To = ""
+		digest, err := h.broadcastDigest(&canonical)
+		if err != nil {
+			return err
+		}
+		if local := stored[msg.From]; local != nil {
+			localDigest, _ := h.broadcastDigest(local)
+			if !bytes.Equal(localDigest, digest) {
+				return fmt.Errorf("protocol: imported broadcast message of round %d from %s differs from the local one", number, msg.From)
+			}
+		}
+		imported[msg.From] = &canonical
+		digests[msg.From] = digest
+	}
+	for _, id := range r.PartyIDs() {
+		if digests[id] == nil {
+			return fmt.Errorf("protocol: imported broadcast messages of round %d miss the message from %s", number, id)
+		}
+	}
+	if !bytes.Equal(h.hashDigests(r, digests), broadcastHash) {
+		return fmt.Errorf("protocol: imported broadcast hash for round %d does not match the imported messages", number)
+	}
+
+	if number != h.currentRound.Number() {
+		// the messages of a past round were already processed, only the hash is missing
+		h.broadcastHashes[number] = bytes.Clone(broadcastHash)
+		h.traceBroadcastHash(number, broadcastHash)
+		return nil
+	}
+	for _, id := range r.OtherPartyIDs() {
+		if stored[id] == nil {
+			h.accept(imported[id])
+		}
+	}
+	if h.err != nil {
+		return h.err
+	}
+	return nil
+}
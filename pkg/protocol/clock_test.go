@@ -0,0 +1,71 @@
+package protocol_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/frost"
+)
+
+// stepClock advances by one second every time it is read, and records the delays it waited for.
+type stepClock struct {
+	mtx    sync.Mutex
+	now    time.Time
+	delays []time.Duration
+}
+
+func (c *stepClock) Now() time.Time {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.now = c.now.Add(time.Second)
+	return c.now
+}
+
+func (c *stepClock) After(d time.Duration) <-chan time.Time {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.delays = append(c.delays, d)
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func TestHandlerClock(t *testing.T) {
+	partyIDs := test.PartyIDs(2)
+	clock := &stepClock{now: time.Unix(0, 0)}
+	opts := protocol.HandlerOptions{
+		Clock:        clock,
+		CollectStats: true,
+		Restart:      &protocol.RestartPolicy{MaxAttempts: 1, Backoff: time.Hour},
+	}
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandlerWithOptions(frost.Keygen(curve.Secp256k1{}, id, partyIDs, 1), nil, opts)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+
+	// the backoff is waited for on the clock of the handler, and returns immediately
+	start := time.Now()
+	h, err := protocol.Restart(handlers[partyIDs[0]])
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), time.Hour)
+	assert.Equal(t, []time.Duration{time.Hour}, clock.delays)
+
+	stats, ok := h.Stats()
+	require.True(t, ok)
+	require.NotEmpty(t, stats.Rounds)
+	// every duration is measured in whole steps of the clock
+	for _, r := range stats.Rounds {
+		assert.Zero(t, r.FinalizeDuration%time.Second)
+		assert.Positive(t, r.FinalizeDuration)
+	}
+}
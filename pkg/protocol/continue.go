@@ -0,0 +1,59 @@
+package protocol
+
+import "fmt"
+
+// Result is the outcome of driving a Handler with Continue.
+type Result[T any] struct {
+	// Done is true when the protocol has finished successfully, in which case Value contains its result.
+	Done bool
+	// Value is the result of the protocol, and is only set when Done is true.
+	Value T
+	// Messages contains the messages emitted by the handler, which must be delivered to the other parties.
+	Messages []*Message
+}
+
+// Continue delivers msgs to h, and returns all messages emitted by the handler in response.
+// Messages which cannot be accepted by the handler are ignored.
+//
+// Once the protocol has finished, the result is cast to T. This allows any protocol to be driven
+// through the same code path, for example with T = *cmp.Config for keygen and refresh,
+// or T = *ecdsa.Signature for signing.
+//
+// If the protocol aborted, the error is returned along with the emitted messages,
+// since they contain the abort message which should be forwarded to the other parties.
+func Continue[T any](h Handler, msgs []*Message) (Result[T], error) {
+	var result Result[T]
+	for _, msg := range msgs {
+		if h.CanAccept(msg) {
+			h.Accept(msg)
+		}
+	}
+
+	out := h.Listen()
+	for {
+		select {
+		case msg, ok := <-out:
+			if !ok {
+				return finish(h, result)
+			}
+			result.Messages = append(result.Messages, msg)
+		default:
+			return result, nil
+		}
+	}
+}
+
+// finish is called once the handler's channel is closed, and casts the result to T.
+func finish[T any](h Handler, result Result[T]) (Result[T], error) {
+	r, err := h.Result()
+	if err != nil {
+		return result, err
+	}
+	value, ok := r.(T)
+	if !ok {
+		return result, fmt.Errorf("protocol: result has type %T, expected %T", r, result.Value)
+	}
+	result.Done = true
+	result.Value = value
+	return result, nil
+}
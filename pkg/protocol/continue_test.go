@@ -0,0 +1,67 @@
+package protocol_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/frost"
+)
+
+func TestContinue(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	handlers := make(map[party.ID]protocol.Handler, len(partyIDs))
+	inboxes := make(map[party.ID][]*protocol.Message, len(partyIDs))
+	results := make(map[party.ID]*frost.Config, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(frost.Keygen(curve.Secp256k1{}, id, partyIDs, 1), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+
+	for len(results) < len(partyIDs) {
+		progress := false
+		for _, id := range partyIDs {
+			if results[id] != nil {
+				continue
+			}
+			msgs := inboxes[id]
+			inboxes[id] = nil
+			res, err := protocol.Continue[*frost.Config](handlers[id], msgs)
+			require.NoError(t, err)
+			for _, msg := range res.Messages {
+				for _, other := range partyIDs {
+					if msg.IsFor(other) {
+						inboxes[other] = append(inboxes[other], msg)
+						progress = true
+					}
+				}
+			}
+			if res.Done {
+				results[id] = res.Value
+				progress = true
+			}
+		}
+		require.True(t, progress, "protocol is stuck")
+	}
+
+	publicKey := results[partyIDs[0]].PublicKey
+	for _, c := range results {
+		assert.True(t, publicKey.Equal(c.PublicKey))
+	}
+}
+
+func TestContinueWrongType(t *testing.T) {
+	partyIDs := test.PartyIDs(2)
+	h, err := protocol.NewMultiHandler(startQuorum(partyIDs[0], partyIDs, 1), nil)
+	require.NoError(t, err)
+	other, err := protocol.NewMultiHandler(startQuorum(partyIDs[1], partyIDs, 1), nil)
+	require.NoError(t, err)
+
+	_, err = protocol.Continue[string](h, drain(other))
+	assert.Error(t, err, "result should not be cast to the wrong type")
+}
@@ -0,0 +1,192 @@
+package protocol
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/cronokirby/saferith"
+	"github.com/taurusgroup/multi-party-sig/internal/params"
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/internal/types"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
+	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
+)
+
+// Cost is an estimate of the communication of each party during a protocol, see EstimateCost.
+type Cost struct {
+	Protocol string
+	// N is the number of parties of the session, and Threshold the threshold of the key.
+	N, Threshold int
+	// Rounds contains the estimate of each round, starting with round 1.
+	Rounds []RoundCost
+}
+
+// RoundCost estimates the messages received by each party in a single round.
+// Since all parties send the same messages, it is also the estimate of the messages sent by each party.
+type RoundCost struct {
+	Number round.Number
+	// Broadcasts and Messages are the numbers of broadcast and p2p messages received by each party.
+	Broadcasts, Messages int
+	// BroadcastBytes and MessageBytes are the estimated sizes of the data of a single broadcast and p2p message.
+	BroadcastBytes, MessageBytes int
+}
+
+// Bytes returns the estimated size of the data received by each party in this round.
+func (r RoundCost) Bytes() int {
+	return r.Broadcasts*r.BroadcastBytes + r.Messages*r.MessageBytes
+}
+
+// Messages returns the number of messages received by each party during the protocol.
+func (c *Cost) Messages() int {
+	count := 0
+	for _, r := range c.Rounds {
+		count += r.Broadcasts + r.Messages
+	}
+	return count
+}
+
+// Bytes returns the estimated size of the data received by each party during the protocol.
+func (c *Cost) Bytes() int {
+	size := 0
+	for _, r := range c.Rounds {
+		size += r.Bytes()
+	}
+	return size
+}
+
+// EstimateCost returns an estimate of the messages exchanged by each party during the protocol with the given ID,
+// in a session with n parties and threshold t, which must have been registered with RegisterDescription.
+//
+// Sizes are derived from the fields of the message contents, assuming secp256k1 and the default Paillier modulus,
+// and count the CBOR encoding of Message.Data without the envelope of the message, see Message.EncodedSize.
+// Integers of variable length, such as Paillier ciphertexts and the responses of proofs, are counted at their
+// maximum length, and slices of unknown length as t+1 elements, the size of a polynomial of degree t.
+// The actual traffic of a session can be measured with HandlerOptions.CollectStats.
+func EstimateCost(protocolID string, n, t int) (*Cost, error) {
+	if n <= 0 || t < 0 || t >= n {
+		return nil, fmt.Errorf("protocol: invalid session size %d with threshold %d", n, t)
+	}
+	d, err := Describe(protocolID)
+	if err != nil {
+		return nil, err
+	}
+	e := sizeEstimator{n: n, t: t}
+	c := &Cost{Protocol: protocolID, N: n, Threshold: t}
+	for _, rd := range d.Rounds {
+		rc := RoundCost{Number: rd.Number}
+		if rd.broadcastType != nil {
+			rc.Broadcasts = n - 1
+			rc.BroadcastBytes = e.size(rd.broadcastType, 0)
+		}
+		if rd.messageType != nil {
+			rc.Messages = n - 1
+			rc.MessageBytes = e.size(rd.messageType, 0)
+		}
+		c.Rounds = append(c.Rounds, rc)
+	}
+	return c, nil
+}
+
+var (
+	scalarType     = reflect.TypeOf((*curve.Scalar)(nil)).Elem()
+	pointType      = reflect.TypeOf((*curve.Point)(nil)).Elem()
+	ciphertextType = reflect.TypeOf(paillier.Ciphertext{})
+	paillierType   = reflect.TypeOf(paillier.PublicKey{})
+	pedersenType   = reflect.TypeOf(pedersen.Parameters{})
+	natType        = reflect.TypeOf(saferith.Nat{})
+	intType        = reflect.TypeOf(saferith.Int{})
+	modulusType    = reflect.TypeOf(saferith.Modulus{})
+	ridType        = reflect.TypeOf(types.RID{})
+)
+
+// maxEstimateDepth bounds the recursion of sizeEstimator over recursive types.
+const maxEstimateDepth = 16
+
+// sizeEstimator estimates the size of the CBOR encoding of a value of a given type.
+type sizeEstimator struct {
+	n, t int
+}
+
+func (e sizeEstimator) size(t reflect.Type, depth int) int {
+	if depth > maxEstimateDepth {
+		return 0
+	}
+	switch t {
+	case scalarType:
+		return cborStringSize(32)
+	case pointType:
+		return cborStringSize(33)
+	case ridType:
+		return cborStringSize(params.SecBytes)
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case ciphertextType:
+		return cborStringSize(2 * params.BytesPaillier)
+	case paillierType, natType, modulusType:
+		return cborStringSize(params.BytesPaillier)
+	case intType:
+		// the sign is encoded in an additional byte
+		return cborStringSize(params.BytesPaillier + 1)
+	case pedersenType:
+		return cborHeadSize(3) + 3*cborStringSize(params.BytesPaillier)
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		fields, size := 0, 0
+		e.structFields(t, depth, &fields, &size)
+		return cborHeadSize(uint64(fields)) + size
+	case reflect.Map:
+		// maps in message contents are indexed by party
+		keys := e.n
+		return cborHeadSize(uint64(keys)) + keys*(e.size(t.Key(), depth+1)+e.size(t.Elem(), depth+1))
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// byte slices are hashes and commitments
+			return cborStringSize(params.DefaultSecurityProfile.DigestBytes)
+		}
+		return cborHeadSize(uint64(e.t+1)) + (e.t+1)*e.size(t.Elem(), depth+1)
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return cborStringSize(t.Len())
+		}
+		return cborHeadSize(uint64(t.Len())) + t.Len()*e.size(t.Elem(), depth+1)
+	case reflect.String:
+		// party IDs and labels
+		return cborStringSize(8)
+	case reflect.Bool:
+		return 1
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cborHeadSize(uint64(e.n))
+	default:
+		return 0
+	}
+}
+
+// structFields adds the number and size of the encoded fields of the struct t to fields and size.
+// Embedded structs are flattened, as they are by the CBOR encoder.
+func (e sizeEstimator) structFields(t reflect.Type, depth int, fields, size *int) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		ft := f.Type
+		if f.Anonymous {
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				e.structFields(ft, depth+1, fields, size)
+				continue
+			}
+		}
+		if !f.IsExported() || f.Tag.Get("cbor") == "-" {
+			continue
+		}
+		*fields++
+		*size += cborStringSize(len(f.Name)) + e.size(ft, depth+1)
+	}
+}
@@ -0,0 +1,147 @@
+package protocol
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+)
+
+// Description is a machine-readable summary of the rounds of a protocol, so that orchestrators can
+// display the progress of a session and prepare their transport without knowledge of each protocol.
+//
+// The size of messages depends on the group and on the security parameters, and is not described.
+// It can be estimated with EstimateCost, and measured with HandlerOptions.CollectStats.
+type Description struct {
+	// Protocol is the protocol ID, as found in Message.Protocol.
+	Protocol string
+	// FinalRound is the number of the last round.
+	FinalRound round.Number
+	// Rounds describes the messages received in each round, starting with round 1.
+	Rounds []RoundDescription
+}
+
+// RoundDescription describes the messages received by a party in a single round.
+// The first round of a protocol receives no message.
+type RoundDescription struct {
+	Number round.Number
+	// Broadcast is the name of the type of the broadcast message content, or empty if the round has no broadcast.
+	Broadcast string
+	// Message is the name of the type of the p2p message content, or empty if the round has no p2p message.
+	Message string
+
+	// broadcastType and messageType are the types of the contents, used by EstimateCost.
+	broadcastType, messageType reflect.Type
+}
+
+// Messages returns the number of messages received by each party in this round, in a session with n parties.
+func (r RoundDescription) Messages(n int) int {
+	count := 0
+	if r.Broadcast != "" {
+		count += n - 1
+	}
+	if r.Message != "" {
+		count += n - 1
+	}
+	return count
+}
+
+// Messages returns the number of messages received by each party during the protocol, in a session with n parties.
+func (d *Description) Messages(n int) int {
+	count := 0
+	for _, r := range d.Rounds {
+		count += r.Messages(n)
+	}
+	return count
+}
+
+// DescribeRounds returns the Description of a protocol whose rounds are given in order, starting with round 1.
+// It is meant to be called by protocol implementations with uninitialized rounds, which only need a round.Helper
+// to return their message contents.
+func DescribeRounds(protocolID string, rounds ...round.Session) *Description {
+	d := &Description{Protocol: protocolID}
+	for _, r := range rounds {
+		rd := RoundDescription{
+			Number:      r.Number(),
+			Message:     contentName(r.MessageContent()),
+			messageType: contentType(r.MessageContent()),
+		}
+		if b, ok := r.(round.BroadcastRound); ok {
+			rd.Broadcast = contentName(b.BroadcastContent())
+			rd.broadcastType = contentType(b.BroadcastContent())
+		}
+		d.Rounds = append(d.Rounds, rd)
+		d.FinalRound = r.Number()
+	}
+	return d
+}
+
+// contentName returns the name of the type of content, for example "keygen.broadcast2", or an empty string if content is nil.
+func contentName(content round.Content) string {
+	if t := contentType(content); t != nil {
+		return t.String()
+	}
+	return ""
+}
+
+// contentType returns the type of content, dereferenced if it is a pointer, or nil if content is nil.
+func contentType(content round.Content) reflect.Type {
+	if content == nil {
+		return nil
+	}
+	t := reflect.TypeOf(content)
+	if t.Kind() == reflect.Ptr {
+		if reflect.ValueOf(content).IsNil() {
+			return nil
+		}
+		t = t.Elem()
+	}
+	return t
+}
+
+var (
+	describersMtx sync.RWMutex
+	describers    = map[string]func() *Description{}
+)
+
+// RegisterDescription makes the description of a protocol available to Describe.
+// Protocol implementations register themselves in an init function, so that a protocol is described
+// as soon as its package is imported.
+//
+// RegisterDescription panics if protocolID is empty, or if a description was already registered for it.
+func RegisterDescription(protocolID string, describe func() *Description) {
+	if protocolID == "" || describe == nil {
+		panic("protocol: RegisterDescription with empty protocol ID or nil function")
+	}
+	describersMtx.Lock()
+	defer describersMtx.Unlock()
+	if _, ok := describers[protocolID]; ok {
+		panic(fmt.Sprintf("protocol: RegisterDescription called twice for %s", protocolID))
+	}
+	describers[protocolID] = describe
+}
+
+// Describe returns the description of the protocol with the given ID, for example "cmp/keygen-threshold".
+func Describe(protocolID string) (*Description, error) {
+	describersMtx.RLock()
+	describe, ok := describers[protocolID]
+	describersMtx.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("protocol: no description for protocol %q", protocolID)
+	}
+	return describe(), nil
+}
+
+// Described returns the sorted IDs of all described protocols.
+func Described() []string {
+	describersMtx.RLock()
+	defer describersMtx.RUnlock()
+	ids := make([]string, 0, len(describers))
+	for id := range describers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
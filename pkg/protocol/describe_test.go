@@ -0,0 +1,46 @@
+package protocol_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+)
+
+func TestDescribeRounds(t *testing.T) {
+	protocolID := "test/quorum-describe"
+	protocol.RegisterDescription(protocolID, func() *protocol.Description {
+		helper := round.NewDescriptionHelper(protocolID, 2)
+		r1 := &quorumRound1{Helper: helper}
+		return protocol.DescribeRounds(protocolID, r1, &quorumRound2{quorumRound1: r1})
+	})
+	assert.Panics(t, func() { protocol.RegisterDescription(protocolID, func() *protocol.Description { return nil }) })
+	assert.Contains(t, protocol.Described(), protocolID)
+
+	d, err := protocol.Describe(protocolID)
+	require.NoError(t, err)
+	assert.Equal(t, round.Number(2), d.FinalRound)
+	require.Len(t, d.Rounds, 2)
+	assert.Equal(t, 0, d.Rounds[0].Messages(4), "the first round receives no message")
+	assert.Equal(t, 3, d.Messages(4))
+
+	_, err = protocol.Describe("test/unknown")
+	assert.Error(t, err)
+
+	cost, err := protocol.EstimateCost(protocolID, 4, 1)
+	require.NoError(t, err)
+	require.Len(t, cost.Rounds, 2)
+	assert.Zero(t, cost.Rounds[0].Bytes())
+	assert.Equal(t, 3, cost.Rounds[1].Messages)
+	// {"Value": 1} is encoded in 8 bytes
+	assert.Equal(t, 8, cost.Rounds[1].MessageBytes)
+	assert.Equal(t, 3, cost.Messages())
+	assert.Equal(t, 24, cost.Bytes())
+
+	_, err = protocol.EstimateCost(protocolID, 4, 4)
+	assert.Error(t, err)
+	_, err = protocol.EstimateCost("test/unknown", 4, 1)
+	assert.Error(t, err)
+}
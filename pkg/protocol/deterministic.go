@@ -0,0 +1,49 @@
+package protocol
+
+import (
+	"sort"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+)
+
+// canonicalEncMode encodes round contents with sorted map keys, so that the same content
+// always produces the same bytes.
+var canonicalEncMode = func() cbor.EncMode {
+	em, err := cbor.CanonicalEncOptions().EncMode()
+	if err != nil {
+		panic(err)
+	}
+	return em
+}()
+
+// MessageID returns a 32 byte identifier of m, computed from its SSID, sender, round number, recipient
+// and a digest of its content.
+//
+// Two messages with the same ID carry the same content for the same recipient, which makes it suitable
+// to deduplicate retried messages and to correlate logs across parties.
+// When the handler does not set HandlerOptions.DeterministicOutput, the same logical content may be
+// encoded differently, and therefore get a different ID.
+func (m *Message) MessageID() []byte {
+	content := hash.New(hash.BytesWithDomain{TheDomain: "Content", Bytes: m.Data}).Sum()
+	h := hash.New(
+		hash.BytesWithDomain{TheDomain: "SSID", Bytes: m.SSID},
+		m.From,
+		m.RoundNumber,
+		m.To,
+		hash.BytesWithDomain{TheDomain: "ContentDigest", Bytes: content},
+	)
+	id := make([]byte, 32)
+	_, _ = h.Digest().Read(id)
+	return id
+}
+
+// sortMessages orders the messages emitted for a round: broadcast messages first, then by recipient.
+func sortMessages(msgs []*Message) {
+	sort.SliceStable(msgs, func(i, j int) bool {
+		if msgs[i].Broadcast != msgs[j].Broadcast {
+			return msgs[i].Broadcast
+		}
+		return msgs[i].To < msgs[j].To
+	})
+}
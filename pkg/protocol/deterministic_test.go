@@ -0,0 +1,93 @@
+package protocol_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/frost"
+)
+
+func TestMessageID(t *testing.T) {
+	msg := &protocol.Message{
+		SSID:        []byte("ssid"),
+		From:        "a",
+		To:          "b",
+		Protocol:    "test",
+		RoundNumber: 2,
+		Data:        []byte{1, 2, 3},
+	}
+	id := msg.MessageID()
+	assert.Len(t, id, 32)
+
+	// the ID does not depend on the broadcast verification
+	copied := *msg
+	copied.BroadcastVerification = []byte{4}
+	assert.Equal(t, id, copied.MessageID())
+
+	for _, modify := range []func(m *protocol.Message){
+		func(m *protocol.Message) { m.SSID = []byte("other") },
+		func(m *protocol.Message) { m.From = "c" },
+		func(m *protocol.Message) { m.To = "c" },
+		func(m *protocol.Message) { m.RoundNumber = 3 },
+		func(m *protocol.Message) { m.Data = []byte{1, 2} },
+	} {
+		modified := *msg
+		modify(&modified)
+		assert.NotEqual(t, id, modified.MessageID())
+	}
+}
+
+func TestHandlerDeterministicOutput(t *testing.T) {
+	partyIDs := test.PartyIDs(4)
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandlerWithOptions(frost.Keygen(curve.Secp256k1{}, id, partyIDs, 2), nil, protocol.HandlerOptions{
+			DeterministicOutput: true,
+			UnicastBroadcast:    true,
+		})
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+
+	seen := map[string]bool{}
+	for {
+		var msgs []*protocol.Message
+		for _, id := range partyIDs {
+			out := drain(handlers[id])
+			for i := 1; i < len(out); i++ {
+				prev, cur := out[i-1], out[i]
+				if prev.RoundNumber != cur.RoundNumber {
+					continue
+				}
+				assert.False(t, !prev.Broadcast && cur.Broadcast, "broadcast messages must come first")
+				if prev.Broadcast == cur.Broadcast {
+					assert.Less(t, prev.To, cur.To)
+				}
+			}
+			msgs = append(msgs, out...)
+		}
+		if len(msgs) == 0 {
+			break
+		}
+		for _, msg := range msgs {
+			id := string(msg.MessageID())
+			assert.False(t, seen[id], "message IDs must be unique")
+			seen[id] = true
+			for id, h := range handlers {
+				if msg.IsFor(id) {
+					h.Accept(msg)
+				}
+			}
+		}
+	}
+
+	for _, h := range handlers {
+		_, err := h.Result()
+		require.NoError(t, err)
+	}
+}
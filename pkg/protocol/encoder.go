@@ -0,0 +1,192 @@
+package protocol
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+)
+
+// encoderBufferSize is the size of the buffer through which a MailboxEncoder writes.
+const encoderBufferSize = 32 * 1024
+
+// Encoding a Mailbox requires a base64 buffer as large as the largest message,
+// which we reuse across encoders since mailboxes of the same protocol have messages of similar sizes.
+var scratchPool = sync.Pool{
+	New: func() interface{} {
+		scratch := make([]byte, 0, encoderBufferSize)
+		return &scratch
+	},
+}
+
+// MailboxEncoder writes the JSON encoding of a Mailbox to an io.Writer.
+//
+// json.Marshal builds the entire encoding in memory, along with the base64 encoding of every message,
+// which causes large allocations for protocols with big messages such as cmp/keygen.
+// This is a problem in environments where the garbage collector is disabled, for example WebAssembly builds.
+// A MailboxEncoder instead writes each field as it is encoded, through a fixed size buffer and a scratch space
+// which is reused across calls to Encode.
+// The output is identical to that of json.Marshal, so it can be decoded with json.Unmarshal.
+type MailboxEncoder struct {
+	w       *bufio.Writer
+	scratch *[]byte
+}
+
+// NewMailboxEncoder returns a MailboxEncoder which writes to w.
+func NewMailboxEncoder(w io.Writer) *MailboxEncoder {
+	return &MailboxEncoder{w: bufio.NewWriterSize(w, encoderBufferSize)}
+}
+
+// Encode writes the JSON encoding of m to the underlying writer.
+func (e *MailboxEncoder) Encode(m *Mailbox) error {
+	if e.scratch == nil {
+		e.scratch = scratchPool.Get().(*[]byte)
+	}
+	if m == nil {
+		e.w.WriteString("null")
+		return e.w.Flush()
+	}
+	e.w.WriteString(`{"SSID":`)
+	e.writeBytes(m.SSID)
+	e.w.WriteString(`,"Messages":`)
+	e.writeMessages(m.Messages)
+	e.w.WriteString(`,"Broadcast":`)
+	e.writeMessages(m.Broadcast)
+	e.w.WriteString(`,"BroadcastHashes":`)
+	e.writeHashes(m.BroadcastHashes)
+	e.w.WriteString(`,"Out":`)
+	e.writeMessages(m.Out)
+	e.w.WriteByte('}')
+	// bufio.Writer keeps the first error, so that it is enough to check the result of Flush.
+	return e.w.Flush()
+}
+
+// Release returns the scratch space of the encoder to a pool, so that it can be used by other encoders.
+// The encoder may still be used afterwards.
+func (e *MailboxEncoder) Release() {
+	if e.scratch != nil {
+		*e.scratch = (*e.scratch)[:0]
+		scratchPool.Put(e.scratch)
+		e.scratch = nil
+	}
+}
+
+func (e *MailboxEncoder) writeMessages(msgs []*Message) {
+	if msgs == nil {
+		e.w.WriteString("null")
+		return
+	}
+	e.w.WriteByte('[')
+	for i, msg := range msgs {
+		if i > 0 {
+			e.w.WriteByte(',')
+		}
+		e.writeMessage(msg)
+	}
+	e.w.WriteByte(']')
+}
+
+func (e *MailboxEncoder) writeMessage(msg *Message) {
+	if msg == nil {
+		e.w.WriteString("null")
+		return
+	}
+	e.w.WriteString(`{"SSID":`)
+	e.writeBytes(msg.SSID)
+	e.w.WriteString(`,"From":`)
+	e.writeString(string(msg.From))
+	e.w.WriteString(`,"To":`)
+	e.writeString(string(msg.To))
+	e.w.WriteString(`,"Protocol":`)
+	e.writeString(msg.Protocol)
+	e.w.WriteString(`,"RoundNumber":`)
+	e.w.WriteString(strconv.FormatUint(uint64(msg.RoundNumber), 10))
+	e.w.WriteString(`,"Data":`)
+	e.writeBytes(msg.Data)
+	e.w.WriteString(`,"Broadcast":`)
+	e.w.WriteString(strconv.FormatBool(msg.Broadcast))
+	e.w.WriteString(`,"BroadcastVerification":`)
+	e.writeBytes(msg.BroadcastVerification)
+	if msg.Epoch != 0 {
+		e.w.WriteString(`,"Epoch":`)
+		e.w.WriteString(strconv.FormatUint(msg.Epoch, 10))
+	}
+	e.w.WriteByte('}')
+}
+
+// writeHashes writes hashes with its keys sorted as strings, as done by json.Marshal.
+func (e *MailboxEncoder) writeHashes(hashes map[round.Number][]byte) {
+	if hashes == nil {
+		e.w.WriteString("null")
+		return
+	}
+	keys := make([]string, 0, len(hashes))
+	numbers := make(map[string]round.Number, len(hashes))
+	for number := range hashes {
+		key := strconv.FormatUint(uint64(number), 10)
+		keys = append(keys, key)
+		numbers[key] = number
+	}
+	sort.Strings(keys)
+	e.w.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			e.w.WriteByte(',')
+		}
+		e.w.WriteByte('"')
+		e.w.WriteString(key)
+		e.w.WriteString(`":`)
+		e.writeBytes(hashes[numbers[key]])
+	}
+	e.w.WriteByte('}')
+}
+
+// writeBytes writes b as a base64 string, using the scratch space of the encoder.
+func (e *MailboxEncoder) writeBytes(b []byte) {
+	if b == nil {
+		e.w.WriteString("null")
+		return
+	}
+	n := base64.StdEncoding.EncodedLen(len(b))
+	if cap(*e.scratch) < n {
+		*e.scratch = make([]byte, n)
+	}
+	dst := (*e.scratch)[:n]
+	base64.StdEncoding.Encode(dst, b)
+	e.w.WriteByte('"')
+	e.w.Write(dst)
+	e.w.WriteByte('"')
+}
+
+// writeString writes s as a JSON string.
+// Party IDs and protocol names rarely need escaping, in which case s is written as is.
+func (e *MailboxEncoder) writeString(s string) {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c >= 0x80 || strings.IndexByte(`"\<>&`, c) >= 0 {
+			encoded, _ := json.Marshal(s)
+			e.w.Write(encoded)
+			return
+		}
+	}
+	e.w.WriteByte('"')
+	e.w.WriteString(s)
+	e.w.WriteByte('"')
+}
+
+// MarshalJSON implements json.Marshaler using a MailboxEncoder.
+func (m *Mailbox) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e := NewMailboxEncoder(&buf)
+	defer e.Release()
+	if err := e.Encode(m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,45 @@
+package protocol_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+)
+
+func TestMailboxEncoder(t *testing.T) {
+	// plainMailbox has no MarshalJSON method, so that json.Marshal uses the default encoding
+	type plainMailbox protocol.Mailbox
+	mailbox := &protocol.Mailbox{
+		SSID: []byte("ssid"),
+		Messages: []*protocol.Message{
+			{SSID: []byte("ssid"), From: "a", To: "b", Protocol: "test", RoundNumber: 2, Data: bytes.Repeat([]byte{1}, 100)},
+			{SSID: []byte("ssid"), From: "<c&\"d\">", Protocol: "test", RoundNumber: 3, Data: []byte{}, BroadcastVerification: []byte{2}},
+		},
+		Broadcast:       []*protocol.Message{{From: "é", Broadcast: true}},
+		BroadcastHashes: map[round.Number][]byte{2: {3}, 10: {4}, 1: nil},
+	}
+
+	for _, m := range []*protocol.Mailbox{mailbox, {}} {
+		expected, err := json.Marshal((*plainMailbox)(m))
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		e := protocol.NewMailboxEncoder(&buf)
+		require.NoError(t, e.Encode(m))
+		e.Release()
+		assert.Equal(t, string(expected), buf.String())
+
+		encoded, err := json.Marshal(m)
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(encoded))
+
+		var decoded protocol.Mailbox
+		require.NoError(t, json.Unmarshal(encoded, &decoded))
+		assert.Equal(t, (*plainMailbox)(m), (*plainMailbox)(&decoded))
+	}
+}
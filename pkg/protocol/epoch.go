@@ -0,0 +1,44 @@
+package protocol
+
+import (
+	"fmt"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// EpochMismatchError is reported for a message of the protocol addressed to this party, whose epoch differs from the
+// epoch of the session. The sender either already uses the config produced by a refresh, or still uses the config
+// preceding it, see HandlerOptions.OnEpochMismatch.
+type EpochMismatchError struct {
+	// From is the sender of the message.
+	From party.ID
+	// Protocol is the protocol ID of the message.
+	Protocol string
+	// Epoch is the epoch of the message, and Expected is the epoch of the session.
+	Epoch, Expected uint64
+}
+
+// Error implements error.
+func (e *EpochMismatchError) Error() string {
+	return fmt.Sprintf("protocol: %s sent a %s message of epoch %d to a session of epoch %d", e.From, e.Protocol, e.Epoch, e.Expected)
+}
+
+// fromOtherEpoch returns true if msg is a message of the protocol for this party, whose epoch differs from the session's.
+func (h *MultiHandler) fromOtherEpoch(msg *Message) bool {
+	r := h.currentRound
+	return msg != nil && msg.Protocol == r.ProtocolID() && msg.IsFor(r.SelfID()) && msg.Epoch != r.Epoch()
+}
+
+// rejectOtherEpoch reports msg to HandlerOptions.OnEpochMismatch, and must be called with h.mtx held.
+// The session is not aborted, since the sender may be running a session of its own epoch concurrently.
+func (h *MultiHandler) rejectOtherEpoch(msg *Message) {
+	if h.onEpochMismatch == nil {
+		return
+	}
+	h.onEpochMismatch(&EpochMismatchError{
+		From:     msg.From,
+		Protocol: msg.Protocol,
+		Epoch:    msg.Epoch,
+		Expected: h.currentRound.Epoch(),
+	})
+}
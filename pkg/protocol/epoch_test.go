@@ -0,0 +1,49 @@
+package protocol_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+)
+
+func TestHandlerEpoch(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	start := func(i int, epoch uint64, opts protocol.HandlerOptions) *protocol.MultiHandler {
+		h, err := protocol.NewMultiHandlerWithOptions(startQuorumEpoch(partyIDs[i], partyIDs, 2, epoch), nil, opts)
+		require.NoError(t, err)
+		return h
+	}
+
+	previous := drain(start(1, 1, protocol.HandlerOptions{}))
+	require.NotEmpty(t, previous)
+	for _, msg := range previous {
+		assert.Equal(t, uint64(1), msg.Epoch)
+	}
+	h := start(0, 2, protocol.HandlerOptions{})
+	current := drain(h)
+	assert.NotEqual(t, previous[0].SSID, current[0].SSID, "the epoch is part of the SSID")
+	assert.False(t, h.CanAccept(previous[0]))
+
+	var reported []*protocol.EpochMismatchError
+	h = start(0, 2, protocol.HandlerOptions{
+		OnEpochMismatch: func(err *protocol.EpochMismatchError) { reported = append(reported, err) },
+	})
+	drain(h)
+	require.True(t, h.CanAccept(previous[0]))
+	h.Accept(previous[0])
+	// a message of the session whose epoch was changed is rejected as well
+	relabeled := start(1, 2, protocol.HandlerOptions{})
+	msg := drain(relabeled)[0]
+	require.True(t, h.CanAccept(msg))
+	msg.Epoch = 1
+	h.Accept(msg)
+	require.Len(t, reported, 2)
+	assert.Equal(t, partyIDs[1], reported[0].From)
+	assert.Equal(t, uint64(1), reported[0].Epoch)
+	assert.Equal(t, uint64(2), reported[0].Expected)
+	_, err := h.Result()
+	assert.EqualError(t, err, "protocol: not finished", "messages of another epoch should not abort the protocol")
+}
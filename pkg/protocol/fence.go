@@ -0,0 +1,57 @@
+package protocol
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+)
+
+// ErrFenced is the error of a handler which stopped because another instance of the same party
+// claimed the session with a higher fencing token.
+var ErrFenced = errors.New("protocol: fenced by another instance")
+
+// Fence arbitrates between redundant instances of the same party, such as an active and a standby server,
+// so that only one of them emits messages for a given session.
+//
+// Each instance is given a fencing token, which must increase every time a new instance becomes active.
+// Before finalizing a round, the handler claims the session with its token, and stops if the claim fails.
+//
+// The state of a round contains secrets sampled by the instance executing it, and cannot be moved
+// to another instance. When the standby takes over, the sessions of the previous instance end with ErrFenced,
+// without alerting the other parties, and must be restarted by the new instance under a new session ID.
+type Fence interface {
+	// Claim returns true if the instance holding token may finalize the given round of the session ssid.
+	// It must return false if a higher token has been used to claim ssid.
+	Claim(ssid []byte, number round.Number, token uint64) bool
+}
+
+// MemoryFence is a Fence for instances running in the same process.
+// Instances on different machines need a Fence backed by a shared store supporting compare-and-swap.
+type MemoryFence struct {
+	mtx    sync.Mutex
+	tokens map[string]uint64
+}
+
+// NewMemoryFence returns an empty MemoryFence.
+func NewMemoryFence() *MemoryFence {
+	return &MemoryFence{tokens: map[string]uint64{}}
+}
+
+// Claim implements Fence.
+func (f *MemoryFence) Claim(ssid []byte, _ round.Number, token uint64) bool {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if token < f.tokens[string(ssid)] {
+		return false
+	}
+	f.tokens[string(ssid)] = token
+	return true
+}
+
+// fenced stops the handler after a failed claim. Unlike abort, no message is sent to the other parties,
+// since the instance holding the fence may still complete the protocol.
+func (h *MultiHandler) fenced() {
+	h.err = &Error{Err: ErrFenced}
+	close(h.out)
+}
@@ -0,0 +1,76 @@
+package protocol
+
+import (
+	"sort"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// Footprint describes the state retained by a MultiHandler.
+type Footprint struct {
+	// Rounds is the sorted list of round numbers for which the handler still holds the round.
+	Rounds []round.Number
+	// Messages is the number of messages stored in the handler's queues.
+	Messages int
+	// MessageBytes is the total size of the content of the stored messages.
+	MessageBytes int
+}
+
+// MemoryFootprint returns diagnostics about the rounds and messages currently retained by the handler.
+func (h *MultiHandler) MemoryFootprint() Footprint {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	var f Footprint
+	for number := range h.rounds {
+		f.Rounds = append(f.Rounds, number)
+	}
+	sort.Slice(f.Rounds, func(i, j int) bool { return f.Rounds[i] < f.Rounds[j] })
+
+	for _, queues := range []map[round.Number]map[party.ID]*Message{h.messages, h.broadcast} {
+		for _, q := range queues {
+			for _, msg := range q {
+				if msg == nil {
+					continue
+				}
+				f.Messages++
+				f.MessageBytes += len(msg.Data)
+			}
+		}
+	}
+	return f
+}
+
+// prune removes all rounds older than the previous one, as well as the messages received for them.
+//
+// Note that rounds usually embed the previous ones, so that the state of a pruned round may still be
+// referenced by the current round. Pruning only releases the handler's own references.
+func (h *MultiHandler) prune() {
+	if h.keepAllRounds {
+		return
+	}
+	current := h.currentRound.Number()
+	// the output and abort rounds have number 0, in which case only the final round is kept.
+	finished := current == 0
+	for number := range h.rounds {
+		if next, _ := number.Next(); number != current && (finished || next < current) {
+			delete(h.rounds, number)
+		}
+	}
+	for number := range h.messages {
+		if finished || number < current {
+			delete(h.messages, number)
+		}
+	}
+	for number := range h.broadcast {
+		if finished || number < current {
+			delete(h.broadcast, number)
+		}
+	}
+	for number := range h.broadcastDigests {
+		if finished || number < current {
+			delete(h.broadcastDigests, number)
+		}
+	}
+}
@@ -4,12 +4,13 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"sync"
 
 	"github.com/fxamacker/cbor/v2"
 	"github.com/taurusgroup/multi-party-sig/internal/round"
-	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 )
 
 // StartFunc is function that creates the first round of a protocol.
@@ -43,23 +44,107 @@ type MultiHandler struct {
 	messages        map[round.Number]map[party.ID]*Message
 	broadcast       map[round.Number]map[party.ID]*Message
 	broadcastHashes map[round.Number][]byte
-	out             chan *Message
-	mtx             sync.Mutex
+	// broadcastDigests holds the hash of each broadcast message stored for a round whose broadcast hash
+	// has not been computed yet, see storeBroadcastDigest.
+	broadcastDigests map[round.Number]map[party.ID][]byte
+	out              chan *Message
+	mtx              sync.Mutex
+	trace            io.Writer
+	keepAllRounds    bool
+	hashVersion      BroadcastHashVersion
+	ordering         MessageOrdering
+	stats            *statsCollector
+	unicast          bool
+	fence            Fence
+	fencingToken     uint64
+	deterministic    bool
+	clock            platform.Clock
+	answer           func(*Message) *Message
+	membership       MembershipPolicy
+	onNonParticipant func(*NonParticipantError)
+	onEpochMismatch  func(*EpochMismatchError)
+	// nonParticipants counts the messages received from parties which do not take part in the session.
+	nonParticipants map[party.ID]int
+	// otherSessions counts the messages of the protocol received for other sessions.
+	otherSessions map[party.ID]int
+	selfEcho      bool
+	// create, sessionID and opts are kept so that Restart can recreate the handler.
+	create    StartFunc
+	sessionID []byte
+	opts      HandlerOptions
+	restart   *RestartPolicy
+	attempt   uint64
 }
 
 // NewMultiHandler expects a StartFunc for the desired protocol. It returns a handler that the user can interact with.
+//
+// The sessionID is optional, but when given it must be at least MinSessionIDLength bytes long.
+// See NewSessionID and DeriveSessionID.
 func NewMultiHandler(create StartFunc, sessionID []byte) (*MultiHandler, error) {
+	return NewMultiHandlerWithOptions(create, sessionID, HandlerOptions{})
+}
+
+// NewMultiHandlerWithOptions is the same as NewMultiHandler, but allows configuring the handler with HandlerOptions.
+func NewMultiHandlerWithOptions(create StartFunc, sessionID []byte, opts HandlerOptions) (*MultiHandler, error) {
+	if err := ValidateSessionID(sessionID); err != nil {
+		return nil, err
+	}
+	if opts.BroadcastHashVersion > BroadcastHashV3 {
+		return nil, fmt.Errorf("protocol: unknown broadcast hash version %d", opts.BroadcastHashVersion)
+	}
+	if opts.MessageOrdering > OrderBroadcastFirst {
+		return nil, fmt.Errorf("protocol: unknown message ordering %d", opts.MessageOrdering)
+	}
+	if opts.Fence != nil && opts.FencingToken == 0 {
+		return nil, errors.New("protocol: fencing token must be positive")
+	}
+	if opts.Restart != nil && opts.Restart.MaxAttempts < 0 {
+		return nil, errors.New("protocol: restart attempts must not be negative")
+	}
+	clock := opts.Clock
+	if clock == nil {
+		clock = platform.DefaultClock
+	}
+	var stats *statsCollector
+	if opts.CollectStats {
+		stats = newStatsCollector(clock)
+	}
 	r, err := create(sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("protocol: failed to create round: %w", err)
 	}
+	// each round emits at most one broadcast and N-1 p2p messages, unless broadcasts are expanded into N-1 copies
+	capacity := 2 * r.N()
+	if opts.UnicastBroadcast {
+		capacity *= 2
+	}
 	h := &MultiHandler{
-		currentRound:    r,
-		rounds:          map[round.Number]round.Session{r.Number(): r},
-		messages:        newQueue(r.OtherPartyIDs(), r.FinalRoundNumber()),
-		broadcast:       newQueue(r.OtherPartyIDs(), r.FinalRoundNumber()),
-		broadcastHashes: map[round.Number][]byte{},
-		out:             make(chan *Message, 2*r.N()),
+		currentRound:     r,
+		rounds:           map[round.Number]round.Session{r.Number(): r},
+		messages:         newQueue(r.OtherPartyIDs(), r.FinalRoundNumber()),
+		broadcast:        newQueue(r.OtherPartyIDs(), r.FinalRoundNumber()),
+		broadcastHashes:  map[round.Number][]byte{},
+		broadcastDigests: map[round.Number]map[party.ID][]byte{},
+		out:              make(chan *Message, capacity),
+		trace:            opts.TraceWriter,
+		keepAllRounds:    opts.KeepAllRounds,
+		hashVersion:      opts.BroadcastHashVersion,
+		ordering:         opts.MessageOrdering,
+		stats:            stats,
+		unicast:          opts.UnicastBroadcast,
+		fence:            opts.Fence,
+		answer:           opts.Answer,
+		membership:       opts.Membership,
+		onNonParticipant: opts.OnNonParticipant,
+		onEpochMismatch:  opts.OnEpochMismatch,
+		selfEcho:         opts.TolerateSelfEcho,
+		fencingToken:     opts.FencingToken,
+		deterministic:    opts.DeterministicOutput,
+		clock:            clock,
+		create:           create,
+		sessionID:        bytes.Clone(sessionID),
+		opts:             opts,
+		restart:          opts.Restart,
 	}
 	h.finalize()
 	return h, nil
@@ -78,6 +163,14 @@ func (h *MultiHandler) Result() (interface{}, error) {
 	return nil, errors.New("protocol: not finished")
 }
 
+// CurrentRound returns the round the handler is at, or the last round it reached if it aborted.
+// It is meant for recovering the state of a protocol which did not complete, see keygen.RecoverConfig.
+func (h *MultiHandler) CurrentRound() round.Session {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.currentRound
+}
+
 // Listen returns a channel with outgoing messages that must be sent to other parties.
 // The message received should be _reliably_ broadcast if msg.Broadcast is true.
 // The channel is closed when either an error occurs or the protocol detects an error.
@@ -88,43 +181,30 @@ func (h *MultiHandler) Listen() <-chan *Message {
 }
 
 // CanAccept returns true if the message is designated for this protocol protocol execution.
+// If HandlerOptions.Answer is set, it also returns true for messages of other protocols addressed to this party,
+// and unless HandlerOptions.Membership is MembershipIgnore, for messages of the session from parties which do not take part in it,
+// so that they are delivered to Accept and reported. It always returns false for messages of other sessions.
+// If HandlerOptions.TolerateSelfEcho is set, it returns true for echoes of the messages emitted by this handler.
 func (h *MultiHandler) CanAccept(msg *Message) bool {
-	r := h.currentRound
-	if msg == nil {
-		return false
-	}
-	// are we the intended recipient
-	if !msg.IsFor(r.SelfID()) {
-		return false
-	}
-	// is the protocol ID correct
-	if msg.Protocol != r.ProtocolID() {
-		return false
-	}
-	// check for same SSID
-	if !bytes.Equal(msg.SSID, r.SSID()) {
-		return false
-	}
-	// do we know the sender
-	if !r.PartyIDs().Contains(msg.From) {
-		return false
-	}
-
-	// data is cannot be nil
-	if msg.Data == nil {
-		return false
-	}
+	return h.canAccept(msg) || h.canAnswer(msg) || (h.membership != MembershipIgnore && h.fromNonParticipant(msg)) ||
+		(h.selfEcho && h.isSelfEcho(msg)) || (h.onEpochMismatch != nil && h.fromOtherEpoch(msg))
+}
 
-	// check if message for unexpected round
-	if msg.RoundNumber > r.FinalRoundNumber() {
-		return false
-	}
+// isSelfEcho returns true if msg claims to be a message of this party in the current session.
+func (h *MultiHandler) isSelfEcho(msg *Message) bool {
+	r := h.currentRound
+	return msg != nil && msg.From == r.SelfID() && msg.Protocol == r.ProtocolID() && bytes.Equal(msg.SSID, r.SSID())
+}
 
-	if msg.RoundNumber < r.Number() && msg.RoundNumber > 0 {
-		return false
-	}
+// canAnswer returns true if msg should be passed to HandlerOptions.Answer.
+func (h *MultiHandler) canAnswer(msg *Message) bool {
+	r := h.currentRound
+	return h.answer != nil && msg != nil && msg.Protocol != r.ProtocolID() && msg.IsFor(r.SelfID())
+}
 
-	return true
+// canAccept returns true if the message belongs to this protocol execution.
+func (h *MultiHandler) canAccept(msg *Message) bool {
+	return h.rejectReason(msg) == 0
 }
 
 // Accept tries to process the given message. If an abort occurs, the channel returned by Listen() is closed,
@@ -134,12 +214,73 @@ func (h *MultiHandler) CanAccept(msg *Message) bool {
 func (h *MultiHandler) Accept(msg *Message) {
 	h.mtx.Lock()
 	defer h.mtx.Unlock()
+	if h.canAnswer(msg) {
+		h.answerMessage(msg)
+		return
+	}
+	if h.selfEcho && h.isSelfEcho(msg) {
+		h.acceptSelfEcho(msg)
+		return
+	}
+	h.accept(msg)
+}
+
+// acceptSelfEcho ignores an echo of a message emitted by this handler, and must be called with h.mtx held.
+//
+// A party never expects a P2P message from itself, so echoed P2P messages are dropped.
+// Its own broadcast message is stored when it is emitted, and counted in the broadcast hash of the round,
+// so an echoed broadcast is compared with the stored one, ignoring the recipient of UnicastBroadcast copies.
+// The handler aborts if they differ, since the transport may have delivered a different message on behalf of this party.
+func (h *MultiHandler) acceptSelfEcho(msg *Message) {
+	if !msg.Broadcast || msg.RoundNumber == 0 || h.err != nil || h.result != nil {
+		return
+	}
+	// the message was not emitted yet, or its round was pruned
+	sent := h.broadcast[msg.RoundNumber][msg.From]
+	if sent == nil {
+		return
+	}
+	if !bytes.Equal(sent.Data, msg.Data) || !bytes.Equal(sent.BroadcastVerification, msg.BroadcastVerification) {
+		h.abort(fmt.Errorf("echo of own broadcast message of round %d differs from the message sent", msg.RoundNumber))
+	}
+}
+
+// answerMessage emits the reply of HandlerOptions.Answer to msg, and must be called with h.mtx held.
+// Replies are dropped once the protocol has finished, or if the channel is full.
+func (h *MultiHandler) answerMessage(msg *Message) {
+	if h.err != nil || h.result != nil {
+		return
+	}
+	if reply := h.answer(msg); reply != nil {
+		select {
+		case h.out <- reply:
+		default:
+		}
+	}
+}
 
+// accept implements Accept, and must be called with h.mtx held.
+func (h *MultiHandler) accept(msg *Message) {
+	if h.fromOtherEpoch(msg) {
+		h.rejectOtherEpoch(msg)
+		return
+	}
+	if h.fromNonParticipant(msg) {
+		h.rejectNonParticipant(msg)
+		return
+	}
+	if h.fromOtherSession(msg) {
+		h.countOtherSession(msg)
+		return
+	}
 	// exit early if the message is bad, or if we are already done
-	if !h.CanAccept(msg) || h.err != nil || h.result != nil || h.duplicate(msg) {
+	if !h.canAccept(msg) || h.err != nil || h.result != nil || h.duplicate(msg) {
 		return
 	}
 
+	h.traceMessage(TraceIn, msg)
+	h.stats.received(msg)
+
 	// a msg with roundNumber 0 is considered an abort from another party
 	if msg.RoundNumber == 0 {
 		h.abort(fmt.Errorf("aborted by other party with error: \"%s\"", msg.Data), msg.From)
@@ -153,7 +294,11 @@ func (h *MultiHandler) Accept(msg *Message) {
 
 	if msg.Broadcast {
 		if err := h.verifyBroadcastMessage(msg); err != nil {
-			h.abort(err, msg.From)
+			h.abortWithCulprit(err, msg.From)
+			return
+		}
+		if err := h.verifyQueuedMessages(msg.RoundNumber); err != nil {
+			h.abortWithCulprit(err, msg.From)
 			return
 		}
 	} else {
@@ -179,7 +324,10 @@ func (h *MultiHandler) verifyBroadcastMessage(msg *Message) error {
 	}
 
 	// store the broadcast message for this round
-	if err = r.(round.BroadcastRound).StoreBroadcastMessage(roundMsg); err != nil {
+	start := h.clock.Now()
+	err = r.(round.BroadcastRound).StoreBroadcastMessage(roundMsg)
+	h.stats.verified(r.Number(), start)
+	if err != nil {
 		return fmt.Errorf("round %d: %w", r.Number(), err)
 	}
 
@@ -188,6 +336,11 @@ func (h *MultiHandler) verifyBroadcastMessage(msg *Message) error {
 		return nil
 	}
 
+	// the p2p messages are handled by verifyQueuedMessages once all broadcasts are stored
+	if h.ordering == OrderBroadcastFirst {
+		return nil
+	}
+
 	// otherwise, we can try to handle the p2p message that may be stored.
 	msg = h.messages[msg.RoundNumber][msg.From]
 	if msg == nil {
@@ -211,6 +364,9 @@ func (h *MultiHandler) verifyMessage(msg *Message) error {
 		if q == nil || q[msg.From] == nil {
 			return nil
 		}
+		if h.ordering == OrderBroadcastFirst && !h.receivedAllBroadcasts(r) {
+			return nil
+		}
 	}
 
 	roundMsg, err := getRoundMessage(msg, r)
@@ -218,6 +374,9 @@ func (h *MultiHandler) verifyMessage(msg *Message) error {
 		return err
 	}
 
+	start := h.clock.Now()
+	defer h.stats.verified(r.Number(), start)
+
 	// verify message for round
 	if err = r.VerifyMessage(roundMsg); err != nil {
 		return fmt.Errorf("round %d: %w", r.Number(), err)
@@ -239,10 +398,16 @@ func (h *MultiHandler) finalize() {
 		h.abort(errors.New("broadcast verification failed"))
 		return
 	}
+	if h.fence != nil && !h.fence.Claim(h.currentRound.SSID(), h.currentRound.Number(), h.fencingToken) {
+		h.fenced()
+		return
+	}
 
 	out := make(chan *round.Message, h.currentRound.N()+1)
 	// since we pass a large enough channel, we should never get an error
+	start := h.clock.Now()
 	r, err := h.currentRound.Finalize(out)
+	h.stats.finalized(h.currentRound.Number(), start)
 	close(out)
 	// either we got an error due to some problem on our end (sampling etc)
 	// or the new round is nil (should not happen)
@@ -252,8 +417,14 @@ func (h *MultiHandler) finalize() {
 	}
 
 	// forward messages with the correct header.
+	msgs := make([]*Message, 0, cap(out))
 	for roundMsg := range out {
-		data, err := cbor.Marshal(roundMsg.Content)
+		var data []byte
+		if h.deterministic {
+			data, err = canonicalEncMode.Marshal(roundMsg.Content)
+		} else {
+			data, err = cbor.Marshal(roundMsg.Content)
+		}
 		if err != nil {
 			panic(fmt.Errorf("failed to marshal round message: %w", err))
 		}
@@ -265,11 +436,29 @@ func (h *MultiHandler) finalize() {
 			RoundNumber:           roundMsg.Content.RoundNumber(),
 			Data:                  data,
 			Broadcast:             roundMsg.Broadcast,
-			BroadcastVerification: h.broadcastHashes[r.Number()-1],
+			BroadcastVerification: h.previousBroadcastHash(r.Number()),
+			Epoch:                 r.Epoch(),
 		}
+		// our own broadcast message is part of the broadcast hash, but we never store a P2P message to ourselves
 		if msg.Broadcast {
 			h.store(msg)
 		}
+		if msg.Broadcast && h.unicast {
+			for _, id := range r.OtherPartyIDs() {
+				msgCopy := *msg
+				msgCopy.To = id
+				msgs = append(msgs, &msgCopy)
+			}
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	if h.deterministic {
+		sortMessages(msgs)
+	}
+	for _, msg := range msgs {
+		h.traceMessage(TraceOut, msg)
+		h.stats.sent(msg, h.currentRound.Number(), r.OtherPartyIDs())
 		h.out <- msg
 	}
 
@@ -280,6 +469,7 @@ func (h *MultiHandler) finalize() {
 	}
 	h.rounds[roundNumber] = r
 	h.currentRound = r
+	h.prune()
 
 	// either we get the current round, the next one, or one of the two final ones
 	switch R := r.(type) {
@@ -295,21 +485,28 @@ func (h *MultiHandler) finalize() {
 	default:
 	}
 
+	// queued messages are handled in the order of the party IDs, so that the outcome does not depend on map iteration
 	if _, ok := r.(round.BroadcastRound); ok {
 		// handle queued broadcast messages, which will then check the subsequent normal message
-		for id, m := range h.broadcast[roundNumber] {
-			if m == nil || id == r.SelfID() {
+		for _, id := range r.OtherPartyIDs() {
+			m := h.broadcast[roundNumber][id]
+			if m == nil {
 				continue
 			}
 			// if false, we aborted and so we return
 			if err = h.verifyBroadcastMessage(m); err != nil {
-				h.abort(err, m.From)
+				h.abortWithCulprit(err, m.From)
 				return
 			}
 		}
+		if err = h.verifyQueuedMessages(roundNumber); err != nil {
+			h.abortWithCulprit(err, r.SelfID())
+			return
+		}
 	} else {
 		// handle simple queued messages
-		for _, m := range h.messages[roundNumber] {
+		for _, id := range r.OtherPartyIDs() {
+			m := h.messages[roundNumber][id]
 			if m == nil {
 				continue
 			}
@@ -331,13 +528,16 @@ func (h *MultiHandler) abort(err error, culprits ...party.ID) {
 			Culprits: culprits,
 			Err:      err,
 		}
-		select {
-		case h.out <- &Message{
+		msg := &Message{
 			SSID:     h.currentRound.SSID(),
 			From:     h.currentRound.SelfID(),
 			Protocol: h.currentRound.ProtocolID(),
 			Data:     []byte(h.err.Error()),
-		}:
+			Epoch:    h.currentRound.Epoch(),
+		}
+		h.traceMessage(TraceOut, msg)
+		select {
+		case h.out <- msg:
 		default:
 		}
 
@@ -352,6 +552,63 @@ func (h *MultiHandler) Stop() {
 	}
 }
 
+// verifyQueuedMessages verifies all queued p2p messages of the given round, in the order of the party IDs,
+// once the broadcast messages of all parties have been stored.
+// It only applies to OrderBroadcastFirst, since otherwise p2p messages are verified along with the broadcast of their sender.
+// The returned error identifies the party whose message failed verification.
+func (h *MultiHandler) verifyQueuedMessages(number round.Number) error {
+	if h.ordering != OrderBroadcastFirst {
+		return nil
+	}
+	r, ok := h.rounds[number]
+	if !ok || !expectsNormalMessage(r) || !h.receivedAllBroadcasts(r) {
+		return nil
+	}
+	for _, id := range r.OtherPartyIDs() {
+		m := h.messages[number][id]
+		if m == nil {
+			continue
+		}
+		if err := h.verifyMessage(m); err != nil {
+			return &culpritError{culprit: id, err: err}
+		}
+	}
+	return nil
+}
+
+// receivedAllBroadcasts returns true if r is a broadcast round for which the broadcast message of every party has been received.
+func (h *MultiHandler) receivedAllBroadcasts(r round.Session) bool {
+	q := h.broadcast[r.Number()]
+	if q == nil {
+		return false
+	}
+	for _, id := range r.PartyIDs() {
+		if q[id] == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// culpritError attributes an error to a party other than the sender of the message being handled.
+type culpritError struct {
+	culprit party.ID
+	err     error
+}
+
+func (e *culpritError) Error() string { return e.err.Error() }
+func (e *culpritError) Unwrap() error { return e.err }
+
+// abortWithCulprit aborts with the culprit given by a culpritError, or with culprit otherwise.
+func (h *MultiHandler) abortWithCulprit(err error, culprit party.ID) {
+	var ce *culpritError
+	if errors.As(err, &ce) {
+		h.abort(ce.err, ce.culprit)
+		return
+	}
+	h.abort(err, culprit)
+}
+
 func expectsNormalMessage(r round.Session) bool {
 	return r.MessageContent() != nil
 }
@@ -364,24 +621,13 @@ func (h *MultiHandler) receivedAll() bool {
 		if h.broadcast[number] == nil {
 			return true
 		}
-		for _, id := range r.PartyIDs() {
-			msg := h.broadcast[number][id]
-			if msg == nil {
-				return false
-			}
-		}
-
 		// create hash of all message for this round
 		if h.broadcastHashes[number] == nil {
-			hashState := r.Hash()
-			for _, id := range r.PartyIDs() {
-				msg := h.broadcast[number][id]
-				_ = hashState.WriteAny(&hash.BytesWithDomain{
-					TheDomain: "Message",
-					Bytes:     msg.Hash(),
-				})
+			if len(h.broadcastDigests[number]) < r.N() {
+				return false
 			}
-			h.broadcastHashes[number] = hashState.Sum()
+			h.broadcastHashes[number] = h.computeBroadcastHash(r)
+			h.traceBroadcastHash(number, h.broadcastHashes[number])
 		}
 	}
 
@@ -390,15 +636,53 @@ func (h *MultiHandler) receivedAll() bool {
 		if h.messages[number] == nil {
 			return true
 		}
-		for _, id := range r.OtherPartyIDs() {
+		for _, id := range dependencies(r) {
 			if h.messages[number][id] == nil {
 				return false
 			}
 		}
+		received := 0
+		for _, id := range r.OtherPartyIDs() {
+			if h.messages[number][id] != nil {
+				received++
+			}
+		}
+		// we count ourselves as part of the quorum
+		return received+1 >= quorum(r)
 	}
 	return true
 }
 
+// quorum returns the number of parties from whom a message is required to finalize the round r,
+// including ourselves.
+func quorum(r round.Session) int {
+	// only the dependencies are required, which are checked separately
+	if dependencies(r) != nil {
+		return 1
+	}
+	return r.N()
+}
+
+// dependencies returns the other parties from whom a message is required to finalize the round r,
+// or nil if r does not restrict them.
+func dependencies(r round.Session) []party.ID {
+	d, ok := r.(round.DependentRound)
+	if !ok {
+		return nil
+	}
+	ids := d.DependsOn()
+	if ids == nil {
+		return nil
+	}
+	deps := make([]party.ID, 0, len(ids))
+	for _, id := range ids {
+		if id != r.SelfID() && r.PartyIDs().Contains(id) {
+			deps = append(deps, id)
+		}
+	}
+	return deps
+}
+
 func (h *MultiHandler) duplicate(msg *Message) bool {
 	if msg.RoundNumber == 0 {
 		return false
@@ -426,7 +710,17 @@ func (h *MultiHandler) store(msg *Message) {
 	if q == nil || q[msg.From] != nil {
 		return
 	}
+	// a broadcast message may have been addressed to us by a sender using UnicastBroadcast,
+	// but the broadcast hash must be computed over the same message by all parties.
+	if msg.Broadcast && msg.To != "" {
+		canonical := *msg
+		canonical.To = ""
+		msg = &canonical
+	}
 	q[msg.From] = msg
+	if msg.Broadcast {
+		h.storeBroadcastDigest(msg)
+	}
 }
 
 // getRoundMessage attempts to unmarshal a raw Message for round `r` in a round.Message.
@@ -446,7 +740,7 @@ func getRoundMessage(msg *Message, r round.Session) (round.Message, error) {
 	}
 
 	// unmarshal message
-	if err := cbor.Unmarshal(msg.Data, content); err != nil {
+	if err := round.UnmarshalContent(msg.Data, content); err != nil {
 		return round.Message{}, fmt.Errorf("failed to unmarshal: %w", err)
 	}
 	roundMsg := round.Message{
@@ -462,7 +756,7 @@ func getRoundMessage(msg *Message, r round.Session) (round.Message, error) {
 func (h *MultiHandler) checkBroadcastHash() bool {
 	number := h.currentRound.Number()
 	// check BroadcastVerification
-	previousHash := h.broadcastHashes[number-1]
+	previousHash := h.previousBroadcastHash(number)
 	if previousHash == nil {
 		return true
 	}
@@ -480,6 +774,15 @@ func (h *MultiHandler) checkBroadcastHash() bool {
 	return true
 }
 
+// previousBroadcastHash returns the hash of the broadcast messages of the round preceding number, if any.
+func (h *MultiHandler) previousBroadcastHash(number round.Number) []byte {
+	previous, ok := number.Prev()
+	if !ok {
+		return nil
+	}
+	return h.broadcastHashes[previous]
+}
+
 func newQueue(senders []party.ID, rounds round.Number) map[round.Number]map[party.ID]*Message {
 	n := len(senders)
 	q := make(map[round.Number]map[party.ID]*Message, rounds)
@@ -0,0 +1,532 @@
+package protocol_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/frost"
+)
+
+// quorumRound1 sends a single message to all other parties.
+type quorumRound1 struct {
+	*round.Helper
+}
+
+func (quorumRound1) VerifyMessage(round.Message) error { return nil }
+func (quorumRound1) StoreMessage(round.Message) error  { return nil }
+func (r *quorumRound1) Finalize(out chan<- *round.Message) (round.Session, error) {
+	if err := r.SendMessage(out, &quorumMessage2{Value: 1}, ""); err != nil {
+		return r, err
+	}
+	return &quorumRound2{quorumRound1: r, received: map[party.ID]bool{r.SelfID(): true}}, nil
+}
+func (quorumRound1) MessageContent() round.Content { return nil }
+func (quorumRound1) Number() round.Number          { return 1 }
+
+// quorumRound2 outputs the number of parties it heard from, including itself.
+type quorumRound2 struct {
+	*quorumRound1
+	received map[party.ID]bool
+}
+
+type quorumMessage2 struct {
+	Value int
+}
+
+func (quorumMessage2) RoundNumber() round.Number { return 2 }
+
+func (quorumRound2) VerifyMessage(round.Message) error { return nil }
+func (r *quorumRound2) StoreMessage(msg round.Message) error {
+	r.received[msg.From] = true
+	return nil
+}
+func (r *quorumRound2) Finalize(chan<- *round.Message) (round.Session, error) {
+	return r.ResultRound(len(r.received)), nil
+}
+func (quorumRound2) MessageContent() round.Content { return &quorumMessage2{} }
+func (quorumRound2) Number() round.Number          { return 2 }
+
+func startQuorum(selfID party.ID, partyIDs party.IDSlice, threshold int) protocol.StartFunc {
+	return startQuorumEpoch(selfID, partyIDs, threshold, 0)
+}
+
+func startQuorumEpoch(selfID party.ID, partyIDs party.IDSlice, threshold int, epoch uint64) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		helper, err := round.NewSession(round.Info{
+			ProtocolID:       "test/quorum",
+			FinalRoundNumber: 2,
+			SelfID:           selfID,
+			PartyIDs:         partyIDs,
+			Threshold:        threshold,
+			Epoch:            epoch,
+		}, sessionID, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &quorumRound1{Helper: helper}, nil
+	}
+}
+
+// drain returns all messages currently queued in the handler's out channel.
+func drain(h *protocol.MultiHandler) []*protocol.Message {
+	var msgs []*protocol.Message
+	for {
+		select {
+		case msg, ok := <-h.Listen():
+			if !ok {
+				return msgs
+			}
+			msgs = append(msgs, msg)
+		default:
+			return msgs
+		}
+	}
+}
+
+// dependentRound1 is the same as quorumRound1, but returns a dependentRound2.
+type dependentRound1 struct {
+	*quorumRound1
+	dependsOn party.ID
+}
+
+func (r *dependentRound1) Finalize(out chan<- *round.Message) (round.Session, error) {
+	next, err := r.quorumRound1.Finalize(out)
+	if err != nil {
+		return next, err
+	}
+	return &dependentRound2{quorumRound2: next.(*quorumRound2), dependsOn: r.dependsOn}, nil
+}
+
+// dependentRound2 only requires the message of the party given by dependsOn, and outputs the parties it heard from.
+type dependentRound2 struct {
+	*quorumRound2
+	dependsOn party.ID
+}
+
+func (r *dependentRound2) DependsOn() []party.ID { return []party.ID{r.dependsOn} }
+
+func TestHandlerDependentRound(t *testing.T) {
+	partyIDs := test.PartyIDs(4)
+	dependsOn := partyIDs[2]
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		start := startQuorum(id, partyIDs, 1)
+		h, err := protocol.NewMultiHandler(func(sessionID []byte) (round.Session, error) {
+			r, err := start(sessionID)
+			if err != nil {
+				return nil, err
+			}
+			return &dependentRound1{quorumRound1: r.(*quorumRound1), dependsOn: dependsOn}, nil
+		}, nil)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+
+	self := handlers[partyIDs[0]]
+	self.Accept(drain(handlers[partyIDs[1]])[0])
+	_, err := self.Result()
+	require.Error(t, err, "protocol should wait for the message it depends on")
+
+	self.Accept(drain(handlers[dependsOn])[0])
+	result, err := self.Result()
+	require.NoError(t, err)
+	assert.Equal(t, 3, result)
+}
+
+// runHandlers delivers all outgoing messages between the handlers until none are left.
+func runHandlers(handlers map[party.ID]*protocol.MultiHandler) {
+	for {
+		delivered := false
+		for _, h := range handlers {
+			for _, msg := range drain(h) {
+				for id, other := range handlers {
+					if msg.IsFor(id) {
+						other.Accept(msg)
+						delivered = true
+					}
+				}
+			}
+		}
+		if !delivered {
+			return
+		}
+	}
+}
+
+func TestHandlerTrace(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	traces := make(map[party.ID]*bytes.Buffer, len(partyIDs))
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		traces[id] = new(bytes.Buffer)
+		h, err := protocol.NewMultiHandlerWithOptions(frost.Keygen(curve.Secp256k1{}, id, partyIDs, 1), nil, protocol.HandlerOptions{
+			TraceWriter: traces[id],
+		})
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	runHandlers(handlers)
+
+	for _, id := range partyIDs {
+		_, err := handlers[id].Result()
+		require.NoError(t, err)
+
+		trace := traces[id].Bytes()
+		require.NoError(t, protocol.VerifyTrace(bytes.NewReader(trace)))
+
+		var events = map[protocol.TraceEvent]int{}
+		for _, line := range bytes.Split(bytes.TrimSpace(trace), []byte("\n")) {
+			var record protocol.TraceRecord
+			require.NoError(t, json.Unmarshal(line, &record))
+			assert.Equal(t, id, record.Self)
+			events[record.Event]++
+		}
+		assert.NotZero(t, events[protocol.TraceIn])
+		assert.NotZero(t, events[protocol.TraceOut])
+		assert.NotZero(t, events[protocol.TraceBroadcastHash])
+
+		// tampering with a verification hash must be detected
+		tampered := bytes.Replace(trace, []byte(`"broadcastVerification":"`), []byte(`"broadcastVerification":"00`), 1)
+		assert.Error(t, protocol.VerifyTrace(bytes.NewReader(tampered)))
+	}
+}
+
+func TestVerifyTraceRounds(t *testing.T) {
+	record := func(event protocol.TraceEvent, number round.Number, hash string) string {
+		data, err := json.Marshal(&protocol.TraceRecord{Event: event, Round: number, BroadcastVerification: hash, BroadcastHash: hash})
+		require.NoError(t, err)
+		return string(data) + "\n"
+	}
+	// round 1 messages follow no broadcast round, and the abort of round 0 has no predecessor
+	trace := record(protocol.TraceOut, 1, "") +
+		record(protocol.TraceIn, 1, "") +
+		record(protocol.TraceBroadcastHash, 1, "aa") +
+		record(protocol.TraceIn, 2, "aa") +
+		record(protocol.TraceOut, 0, "")
+	require.NoError(t, protocol.VerifyTrace(strings.NewReader(trace)))
+
+	tampered := trace + record(protocol.TraceIn, 2, "bb")
+	assert.Error(t, protocol.VerifyTrace(strings.NewReader(tampered)))
+}
+
+func TestHandlerPruning(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	for _, keepAllRounds := range []bool{false, true} {
+		handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+		for _, id := range partyIDs {
+			h, err := protocol.NewMultiHandlerWithOptions(frost.Keygen(curve.Secp256k1{}, id, partyIDs, 1), nil, protocol.HandlerOptions{
+				KeepAllRounds: keepAllRounds,
+			})
+			require.NoError(t, err)
+			handlers[id] = h
+		}
+		runHandlers(handlers)
+
+		for _, h := range handlers {
+			_, err := h.Result()
+			require.NoError(t, err)
+			footprint := h.MemoryFootprint()
+			if keepAllRounds {
+				assert.Len(t, footprint.Rounds, 4)
+				assert.NotZero(t, footprint.Messages)
+				assert.NotZero(t, footprint.MessageBytes)
+			} else {
+				assert.Equal(t, []round.Number{0}, footprint.Rounds)
+				assert.Zero(t, footprint.Messages)
+				assert.Zero(t, footprint.MessageBytes)
+			}
+		}
+	}
+}
+
+func TestHandlerBroadcastHashVersion(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	newHandlers := func(versions ...protocol.BroadcastHashVersion) map[party.ID]*protocol.MultiHandler {
+		handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+		for i, id := range partyIDs {
+			h, err := protocol.NewMultiHandlerWithOptions(frost.Keygen(curve.Secp256k1{}, id, partyIDs, 1), nil, protocol.HandlerOptions{
+				BroadcastHashVersion: versions[i],
+			})
+			require.NoError(t, err)
+			handlers[id] = h
+		}
+		return handlers
+	}
+
+	v1 := newHandlers(protocol.BroadcastHashV1, protocol.BroadcastHashV1, protocol.BroadcastHashV1)
+	runHandlers(v1)
+	v2 := newHandlers(protocol.BroadcastHashV2, protocol.BroadcastHashV2, protocol.BroadcastHashV2)
+	runHandlers(v2)
+	v3 := newHandlers(protocol.BroadcastHashV3, protocol.BroadcastHashV3, protocol.BroadcastHashV3)
+	runHandlers(v3)
+	for _, id := range partyIDs {
+		_, err := v2[id].Result()
+		require.NoError(t, err)
+		_, err = v3[id].Result()
+		require.NoError(t, err)
+		// frost keygen broadcasts in round 2
+		h1, h2, h3 := v1[id].BroadcastHash(2), v2[id].BroadcastHash(2), v3[id].BroadcastHash(2)
+		require.NotNil(t, h2)
+		require.NotNil(t, h3)
+		assert.True(t, v2[id].VerifyBroadcastHash(2, h2))
+		assert.False(t, v2[id].VerifyBroadcastHash(2, h1))
+		assert.False(t, v3[id].VerifyBroadcastHash(2, h2))
+		assert.Equal(t, v2[partyIDs[0]].BroadcastHash(2), h2)
+		assert.Equal(t, v3[partyIDs[0]].BroadcastHash(2), h3)
+	}
+
+	mixed := newHandlers(protocol.BroadcastHashV1, protocol.BroadcastHashV2, protocol.BroadcastHashV2)
+	runHandlers(mixed)
+	for _, h := range mixed {
+		_, err := h.Result()
+		assert.Error(t, err, "parties using different versions should abort")
+	}
+
+	_, err := protocol.NewMultiHandlerWithOptions(frost.Keygen(curve.Secp256k1{}, partyIDs[0], partyIDs, 1), nil, protocol.HandlerOptions{
+		BroadcastHashVersion: protocol.BroadcastHashV3 + 1,
+	})
+	assert.Error(t, err)
+}
+
+func TestHandlerStats(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandlerWithOptions(frost.Keygen(curve.Secp256k1{}, id, partyIDs, 1), nil, protocol.HandlerOptions{
+			CollectStats: true,
+		})
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	runHandlers(handlers)
+
+	for _, h := range handlers {
+		_, err := h.Result()
+		require.NoError(t, err)
+		stats, ok := h.Stats()
+		require.True(t, ok)
+		assert.Positive(t, stats.MessagesIn)
+		assert.Positive(t, stats.BytesIn)
+		assert.Positive(t, stats.MessagesOut)
+		assert.Positive(t, stats.BytesOut)
+		require.Len(t, stats.Rounds, 3)
+		bytesIn, bytesOut := 0, 0
+		for i, r := range stats.Rounds {
+			assert.Equal(t, round.Number(i+1), r.Number)
+			assert.GreaterOrEqual(t, r.Duration, r.FinalizeDuration)
+			bytesIn += r.BytesIn
+			bytesOut += r.BytesOut
+		}
+		assert.Positive(t, stats.Rounds[1].VerifyDuration)
+		assert.Equal(t, stats.BytesIn, bytesIn)
+		assert.Equal(t, stats.BytesOut, bytesOut)
+
+		// every party sends the same messages to each other party
+		require.Len(t, stats.Peers, len(partyIDs)-1)
+		peerIn, sent := 0, -1
+		for _, p := range stats.Peers {
+			if sent < 0 {
+				sent = p.MessagesOut
+			}
+			assert.Equal(t, sent, p.MessagesOut)
+			assert.Positive(t, p.BytesOut)
+			peerIn += p.BytesIn
+		}
+		assert.Equal(t, stats.BytesIn, peerIn)
+	}
+
+	// stats are only collected when enabled
+	h, err := protocol.NewMultiHandler(frost.Keygen(curve.Secp256k1{}, partyIDs[0], partyIDs, 1), nil)
+	require.NoError(t, err)
+	_, ok := h.Stats()
+	assert.False(t, ok)
+}
+
+func TestHandlerUnicastBroadcast(t *testing.T) {
+	partyIDs := test.PartyIDs(4)
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for i, id := range partyIDs {
+		// only some parties expand their broadcasts, and all must agree on the broadcast hashes
+		h, err := protocol.NewMultiHandlerWithOptions(frost.Keygen(curve.Secp256k1{}, id, partyIDs, 2), nil, protocol.HandlerOptions{
+			UnicastBroadcast: i%2 == 0,
+		})
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+
+	msgs := drain(handlers[partyIDs[0]])
+	broadcasts := 0
+	for _, msg := range msgs {
+		if msg.Broadcast {
+			broadcasts++
+			assert.NotEmpty(t, msg.To)
+		}
+	}
+	assert.Equal(t, len(partyIDs)-1, broadcasts)
+	for _, msg := range msgs {
+		for id, h := range handlers {
+			if msg.IsFor(id) {
+				h.Accept(msg)
+			}
+		}
+	}
+
+	runHandlers(handlers)
+	var publicKey interface{}
+	for _, h := range handlers {
+		r, err := h.Result()
+		require.NoError(t, err)
+		if publicKey == nil {
+			publicKey = r.(*frost.Config).PublicKey
+		}
+		assert.True(t, r.(*frost.Config).PublicKey.Equal(publicKey.(curve.Point)))
+	}
+	for number := round.Number(2); number <= 3; number++ {
+		expected := handlers[partyIDs[0]].BroadcastHash(number)
+		require.NotNil(t, expected)
+		for _, h := range handlers {
+			assert.Equal(t, expected, h.BroadcastHash(number))
+		}
+	}
+}
+
+func TestHandlerResultMetadata(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(frost.Keygen(curve.Secp256k1{}, id, partyIDs, 1), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+		_, err = h.ResultMetadata()
+		assert.Error(t, err, "metadata should not be available before the end of the protocol")
+	}
+	runHandlers(handlers)
+
+	for _, h := range handlers {
+		metadata, err := h.ResultMetadata()
+		require.NoError(t, err)
+		assert.Equal(t, "frost/keygen-threshold", metadata.Protocol)
+		assert.Equal(t, protocol.ResultConfig, metadata.Kind)
+		assert.Equal(t, round.Number(3), metadata.FinalRound)
+		assert.Equal(t, curve.Secp256k1{}.Name(), metadata.Group)
+		assert.NotEmpty(t, metadata.SSID)
+
+		envelope, err := h.ResultEnvelope()
+		require.NoError(t, err)
+		assert.Equal(t, metadata, envelope.ResultMetadata)
+		r, _ := h.Result()
+		decoded := frost.EmptyConfig(curve.Secp256k1{})
+		require.NoError(t, envelope.Decode(decoded))
+		assert.True(t, decoded.PublicKey.Equal(r.(*frost.Config).PublicKey))
+	}
+
+	assert.Equal(t, protocol.ResultSignature, protocol.KindOf(&ecdsa.Signature{}))
+	assert.Equal(t, protocol.ResultConfig, protocol.KindOf([]*frost.Config{{}}))
+	assert.Equal(t, protocol.ResultUnknown, protocol.KindOf(3))
+}
+
+func TestHandlerFence(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	self := partyIDs[0]
+	fence := protocol.NewMemoryFence()
+	start := func(id party.ID, token uint64) *protocol.MultiHandler {
+		opts := protocol.HandlerOptions{}
+		if id == self {
+			opts.Fence, opts.FencingToken = fence, token
+		}
+		h, err := protocol.NewMultiHandlerWithOptions(frost.Keygen(curve.Secp256k1{}, id, partyIDs, 1), nil, opts)
+		require.NoError(t, err)
+		return h
+	}
+
+	_, err := protocol.NewMultiHandlerWithOptions(frost.Keygen(curve.Secp256k1{}, self, partyIDs, 1), nil, protocol.HandlerOptions{Fence: fence})
+	assert.Error(t, err, "a fencing token is required")
+
+	active := start(self, 1)
+	others := map[party.ID]*protocol.MultiHandler{partyIDs[1]: start(partyIDs[1], 0), partyIDs[2]: start(partyIDs[2], 0)}
+	require.NotEmpty(t, drain(active))
+
+	// the standby takes over the session before the active instance finalizes its next round
+	standby := start(self, 2)
+	msgs := drain(standby)
+	require.NotEmpty(t, msgs)
+	for _, h := range others {
+		for _, msg := range drain(h) {
+			if msg.IsFor(self) {
+				active.Accept(msg)
+			}
+		}
+	}
+	_, err = active.Result()
+	assert.ErrorIs(t, err, protocol.ErrFenced)
+	assert.Empty(t, drain(active), "a fenced instance must not alert the other parties")
+
+	// an instance with a lower token can no longer claim the session
+	assert.False(t, fence.Claim(msgs[0].SSID, 2, 1))
+	assert.True(t, fence.Claim(msgs[0].SSID, 2, 2))
+}
+
+func TestHandlerRestart(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	sessionID := protocol.DeriveSessionID("keygen", 0, nil)
+	opts := protocol.HandlerOptions{Restart: &protocol.RestartPolicy{MaxAttempts: 1}}
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandlerWithOptions(frost.Keygen(curve.Secp256k1{}, id, partyIDs, 1), sessionID, opts)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	// the first round messages are lost, and one party gives up
+	for _, h := range handlers {
+		drain(h)
+	}
+	restarted := partyIDs[0]
+	next, err := protocol.Restart(handlers[restarted])
+	require.NoError(t, err)
+	stale := drain(handlers[restarted])
+	require.Len(t, stale, 1)
+	for _, id := range partyIDs[1:] {
+		handlers[id].Accept(stale[0])
+		_, err = handlers[id].Result()
+		require.Error(t, err)
+	}
+	handlers[restarted] = next
+	for _, id := range partyIDs[1:] {
+		handlers[id], err = protocol.Restart(handlers[id])
+		require.NoError(t, err)
+	}
+	runHandlers(handlers)
+
+	for _, id := range partyIDs {
+		h := handlers[id]
+		_, err = h.Result()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), h.Attempt())
+		_, err = protocol.Restart(h)
+		assert.Error(t, err)
+	}
+
+	h, err := protocol.NewMultiHandlerWithOptions(frost.Keygen(curve.Secp256k1{}, partyIDs[0], partyIDs, 1), sessionID, opts)
+	require.NoError(t, err)
+	h, err = protocol.Restart(h)
+	require.NoError(t, err)
+	_, err = protocol.Restart(h)
+	assert.ErrorIs(t, err, protocol.ErrRestartLimit)
+
+	policy := protocol.RestartPolicy{Backoff: time.Second, MaxBackoff: 3 * time.Second}
+	assert.Equal(t, time.Second, policy.Delay(1))
+	assert.Equal(t, 2*time.Second, policy.Delay(2))
+	assert.Equal(t, 3*time.Second, policy.Delay(3))
+	assert.Equal(t, 3*time.Second, policy.Delay(60))
+}
@@ -0,0 +1,166 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// headerVersion is the first byte of a serialized Header.
+const headerVersion = 1
+
+const (
+	// MaxSSIDLength is the largest SSID accepted in a Header, the length of a hash.Hash digest.
+	MaxSSIDLength = hash.DigestLengthBytes
+	// MaxHeaderFieldLength is the largest party ID or protocol ID accepted in a Header.
+	MaxHeaderFieldLength = 255
+)
+
+const (
+	// headerFlagBroadcast is set in the flags of a Header of a broadcast message.
+	headerFlagBroadcast = 1 << iota
+	// headerFlagEpoch is set in the flags of a Header with a non-zero epoch. Other flags must be zero.
+	headerFlagEpoch
+)
+
+// Header contains the routing fields of a Message, without its content.
+//
+// Its canonical binary encoding is
+//
+//	version ‖ flags ‖ round ‖ len(SSID) ‖ SSID ‖ len(From) ‖ From ‖ len(To) ‖ To ‖ len(Protocol) ‖ Protocol
+//
+// where the version and flags are single bytes, the round is a big endian uint16, and lengths are single bytes.
+// If the epoch is not zero, it follows the round as a big endian uint64, and headerFlagEpoch is set.
+// It does not depend on the Go representation of Message, and ParseHeader only accepts this exact encoding.
+type Header struct {
+	SSID        []byte
+	From        party.ID
+	To          party.ID
+	Protocol    string
+	RoundNumber round.Number
+	Broadcast   bool
+	Epoch       uint64
+}
+
+// Header returns the header of m.
+func (m *Message) Header() Header {
+	return Header{
+		SSID:        m.SSID,
+		From:        m.From,
+		To:          m.To,
+		Protocol:    m.Protocol,
+		RoundNumber: m.RoundNumber,
+		Broadcast:   m.Broadcast,
+		Epoch:       m.Epoch,
+	}
+}
+
+// Validate returns an error if the fields of hdr are empty or too long to be serialized.
+// It only looks at the header, so that a message can be rejected before its content is decoded.
+func (hdr Header) Validate() error {
+	if len(hdr.SSID) == 0 || len(hdr.SSID) > MaxSSIDLength {
+		return fmt.Errorf("protocol: header SSID length %d is not in [1, %d]", len(hdr.SSID), MaxSSIDLength)
+	}
+	if hdr.From == "" || len(hdr.From) > MaxHeaderFieldLength {
+		return fmt.Errorf("protocol: header sender length %d is not in [1, %d]", len(hdr.From), MaxHeaderFieldLength)
+	}
+	if len(hdr.To) > MaxHeaderFieldLength {
+		return fmt.Errorf("protocol: header recipient length %d is larger than %d", len(hdr.To), MaxHeaderFieldLength)
+	}
+	if hdr.Protocol == "" || len(hdr.Protocol) > MaxHeaderFieldLength {
+		return fmt.Errorf("protocol: header protocol length %d is not in [1, %d]", len(hdr.Protocol), MaxHeaderFieldLength)
+	}
+	if !hdr.RoundNumber.Valid() {
+		return fmt.Errorf("protocol: header round number %d is out of range", hdr.RoundNumber)
+	}
+	return nil
+}
+
+// MarshalBinary returns the canonical encoding of hdr, or an error if hdr is invalid.
+func (hdr Header) MarshalBinary() ([]byte, error) {
+	if err := hdr.Validate(); err != nil {
+		return nil, err
+	}
+	var flags byte
+	if hdr.Broadcast {
+		flags |= headerFlagBroadcast
+	}
+	if hdr.Epoch != 0 {
+		flags |= headerFlagEpoch
+	}
+	out := make([]byte, 0, 16+len(hdr.SSID)+len(hdr.From)+len(hdr.To)+len(hdr.Protocol))
+	out = append(out, headerVersion, flags)
+	out = binary.BigEndian.AppendUint16(out, uint16(hdr.RoundNumber))
+	if hdr.Epoch != 0 {
+		out = binary.BigEndian.AppendUint64(out, hdr.Epoch)
+	}
+	for _, field := range [][]byte{hdr.SSID, []byte(hdr.From), []byte(hdr.To), []byte(hdr.Protocol)} {
+		out = append(out, byte(len(field)))
+		out = append(out, field...)
+	}
+	return out, nil
+}
+
+// ParseHeader parses a header encoded by Header.MarshalBinary.
+// Unknown versions and flags, invalid fields and trailing bytes are rejected,
+// so that every valid header has a single encoding.
+func ParseHeader(data []byte) (Header, error) {
+	if len(data) < 4 {
+		return Header{}, errors.New("protocol: header too short")
+	}
+	if data[0] != headerVersion {
+		return Header{}, fmt.Errorf("protocol: unknown header version %d", data[0])
+	}
+	flags := data[1]
+	if flags&^(headerFlagBroadcast|headerFlagEpoch) != 0 {
+		return Header{}, fmt.Errorf("protocol: unknown header flags %#x", flags)
+	}
+	hdr := Header{
+		RoundNumber: round.Number(binary.BigEndian.Uint16(data[2:4])),
+		Broadcast:   flags&headerFlagBroadcast != 0,
+	}
+	rest := data[4:]
+	if flags&headerFlagEpoch != 0 {
+		if len(rest) < 8 {
+			return Header{}, errors.New("protocol: header truncated")
+		}
+		hdr.Epoch, rest = binary.BigEndian.Uint64(rest[:8]), rest[8:]
+		if hdr.Epoch == 0 {
+			return Header{}, errors.New("protocol: header epoch flag is set for epoch 0")
+		}
+	}
+	var fields [4][]byte
+	for i := range fields {
+		if len(rest) == 0 || len(rest) < 1+int(rest[0]) {
+			return Header{}, errors.New("protocol: header truncated")
+		}
+		fields[i], rest = rest[1:1+int(rest[0])], rest[1+int(rest[0]):]
+	}
+	if len(rest) != 0 {
+		return Header{}, errors.New("protocol: trailing bytes after header")
+	}
+	hdr.SSID = append([]byte(nil), fields[0]...)
+	hdr.From, hdr.To, hdr.Protocol = party.ID(fields[1]), party.ID(fields[2]), string(fields[3])
+	if err := hdr.Validate(); err != nil {
+		return Header{}, err
+	}
+	return hdr, nil
+}
+
+// canonicalDigest returns the hash of the canonical header of m, its content and its broadcast verification,
+// which is used for the broadcast hash with BroadcastHashV3.
+func (m *Message) canonicalDigest() ([]byte, error) {
+	header, err := m.Header().MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return hash.New(
+		&hash.BytesWithDomain{TheDomain: "Header", Bytes: header},
+		&hash.BytesWithDomain{TheDomain: "Content", Bytes: m.Data},
+		&hash.BytesWithDomain{TheDomain: "BroadcastVerification", Bytes: m.BroadcastVerification},
+	).Sum(), nil
+}
@@ -0,0 +1,111 @@
+package protocol_test
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+)
+
+func TestHeader(t *testing.T) {
+	msg := &protocol.Message{
+		SSID:        bytes.Repeat([]byte{7}, 64),
+		From:        "alice",
+		To:          "bob",
+		Protocol:    "cmp/sign",
+		RoundNumber: 3,
+		Data:        []byte("content"),
+		Broadcast:   true,
+	}
+	data, err := msg.Header().MarshalBinary()
+	require.NoError(t, err)
+	hdr, err := protocol.ParseHeader(data)
+	require.NoError(t, err)
+	assert.Equal(t, msg.Header(), hdr)
+
+	// the encoding only depends on the header
+	other := *msg
+	other.Data = []byte("other content")
+	otherData, err := other.Header().MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, data, otherData)
+
+	// the epoch is only encoded when it is not zero
+	other.Epoch = 5
+	otherData, err = other.Header().MarshalBinary()
+	require.NoError(t, err)
+	assert.Len(t, otherData, len(data)+8)
+	hdr, err = protocol.ParseHeader(otherData)
+	require.NoError(t, err)
+	assert.Equal(t, other.Header(), hdr)
+	zeroEpoch := bytes.Clone(otherData)
+	copy(zeroEpoch[4:12], make([]byte, 8))
+
+	for name, invalid := range map[string]protocol.Header{
+		"empty SSID":     {From: "a", Protocol: "p", RoundNumber: 1},
+		"long SSID":      {SSID: make([]byte, protocol.MaxSSIDLength+1), From: "a", Protocol: "p", RoundNumber: 1},
+		"empty sender":   {SSID: []byte{1}, Protocol: "p", RoundNumber: 1},
+		"long recipient": {SSID: []byte{1}, From: "a", To: party.ID(strings.Repeat("b", protocol.MaxHeaderFieldLength+1)), Protocol: "p"},
+		"empty protocol": {SSID: []byte{1}, From: "a", RoundNumber: 1},
+		"round":          {SSID: []byte{1}, From: "a", Protocol: "p", RoundNumber: 1000},
+	} {
+		_, err = invalid.MarshalBinary()
+		assert.Error(t, err, name)
+	}
+
+	for name, invalid := range map[string][]byte{
+		"empty":    nil,
+		"version":  append([]byte{2}, data[1:]...),
+		"flags":    append([]byte{data[0], 0x04}, data[2:]...),
+		"epoch 0":  zeroEpoch,
+		"trailing": append(bytes.Clone(data), 0),
+		"truncate": data[:len(data)-1],
+	} {
+		_, err = protocol.ParseHeader(invalid)
+		assert.Error(t, err, name)
+	}
+
+	// arbitrary mutations never panic, and are either rejected or re-encoded identically
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		mutated := bytes.Clone(data)
+		for j := rng.Intn(4); j >= 0; j-- {
+			mutated[rng.Intn(len(mutated))] = byte(rng.Intn(256))
+		}
+		mutated = mutated[:rng.Intn(len(mutated)+1)]
+		hdr, err = protocol.ParseHeader(mutated)
+		if err != nil {
+			continue
+		}
+		encoded, err := hdr.MarshalBinary()
+		require.NoError(t, err)
+		assert.Equal(t, mutated, encoded)
+	}
+}
+
+func TestHandlerRejectsInvalidHeader(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	h := newQuorumHandler(t, partyIDs[0], partyIDs, protocol.HandlerOptions{})
+	drain(h)
+	var msg *protocol.Message
+	for _, m := range drain(newQuorumHandler(t, partyIDs[1], partyIDs, protocol.HandlerOptions{})) {
+		if m.IsFor(partyIDs[0]) {
+			msg = m
+		}
+	}
+	require.NotNil(t, msg)
+	require.True(t, h.CanAccept(msg))
+
+	long := *msg
+	long.Protocol += strings.Repeat("p", protocol.MaxHeaderFieldLength)
+	assert.False(t, h.CanAccept(&long))
+	short := *msg
+	short.SSID = nil
+	assert.False(t, h.CanAccept(&short))
+}
@@ -0,0 +1,137 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// Mailbox contains the messages received and emitted by a MultiHandler, without the state of its rounds.
+//
+// It allows two replicas of the same party to reconcile the messages they have received,
+// for example when a node exposed to the network buffers messages for an inner node which holds the shares.
+// Since messages are encrypted or only contain public data, a Mailbox can be transferred without the
+// protections required for a Config.
+type Mailbox struct {
+	// SSID identifies the session of the handler which exported the mailbox.
+	SSID []byte
+	// Messages contains the p2p messages received from the other parties.
+	Messages []*Message
+	// Broadcast contains the broadcast messages received from the other parties.
+	Broadcast []*Message
+	// BroadcastHashes contains the echo broadcast hash computed for each broadcast round.
+	BroadcastHashes map[round.Number][]byte
+	// Out contains the messages emitted by the handler which were not yet read from Listen.
+	Out []*Message
+}
+
+// ExportMailbox returns the messages stored by the handler, see Mailbox.
+// Messages of past rounds are only included if the handler was created with HandlerOptions.KeepAllRounds.
+//
+// Messages pending in the channel returned by Listen are included in Out, and remain available to Listen.
+// Once the protocol has finished, this channel is closed and Out is left empty.
+func (h *MultiHandler) ExportMailbox() *Mailbox {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	m := &Mailbox{
+		SSID:            bytes.Clone(h.currentRound.SSID()),
+		Messages:        queuedMessages(h.messages),
+		Broadcast:       queuedMessages(h.broadcast),
+		BroadcastHashes: h.copyBroadcastHashes(),
+	}
+	if h.err == nil && h.result == nil {
+		// the channel is only written to while h.mtx is held, so it can be drained and refilled in order
+		for n := len(h.out); n > 0; n-- {
+			msg := <-h.out
+			m.Out = append(m.Out, msg)
+			h.out <- msg
+		}
+	}
+	return m
+}
+
+// ImportMailbox delivers the messages of a Mailbox exported by another replica of the same party.
+//
+// Received messages go through the same validation as with Accept, and messages which were already
+// received are ignored. An error is returned if the mailbox belongs to a different session,
+// or if a broadcast hash differs from the one computed by this handler, which indicates that
+// the replicas did not receive the same broadcast messages.
+//
+// Messages in Out are emitted again on the channel returned by Listen,
+// and are discarded by recipients which have already received them.
+func (h *MultiHandler) ImportMailbox(m *Mailbox) error {
+	if m == nil {
+		return errors.New("protocol: nil mailbox")
+	}
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	r := h.currentRound
+	if !bytes.Equal(m.SSID, r.SSID()) {
+		return errors.New("protocol: mailbox belongs to a different session")
+	}
+	if err := h.checkMailboxHashes(m); err != nil {
+		return err
+	}
+
+	for _, msgs := range [][]*Message{m.Broadcast, m.Messages} {
+		for _, msg := range msgs {
+			h.accept(msg)
+		}
+	}
+	if err := h.checkMailboxHashes(m); err != nil {
+		return err
+	}
+
+	if h.err != nil {
+		return h.err
+	}
+	if h.result != nil {
+		return nil
+	}
+	for _, msg := range m.Out {
+		if msg == nil || msg.From != r.SelfID() {
+			continue
+		}
+		select {
+		case h.out <- msg:
+		default:
+			return round.ErrOutChanFull
+		}
+	}
+	return nil
+}
+
+// checkMailboxHashes returns an error if a broadcast hash of m differs from the one computed by h.
+func (h *MultiHandler) checkMailboxHashes(m *Mailbox) error {
+	for number, hash := range m.BroadcastHashes {
+		if local, ok := h.broadcastHashes[number]; ok && !bytes.Equal(local, hash) {
+			return fmt.Errorf("protocol: mailbox has a different broadcast hash for round %d", number)
+		}
+	}
+	return nil
+}
+
+// queuedMessages returns the messages stored in queue, ordered by round and sender.
+func queuedMessages(queue map[round.Number]map[party.ID]*Message) []*Message {
+	var msgs []*Message
+	for _, q := range queue {
+		for _, msg := range q {
+			if msg != nil {
+				msgs = append(msgs, msg)
+			}
+		}
+	}
+	sort.Slice(msgs, func(i, j int) bool {
+		if msgs[i].RoundNumber != msgs[j].RoundNumber {
+			return msgs[i].RoundNumber < msgs[j].RoundNumber
+		}
+		return msgs[i].From < msgs[j].From
+	})
+	return msgs
+}
@@ -0,0 +1,149 @@
+package protocol_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/frost"
+)
+
+func TestHandlerMailbox(t *testing.T) {
+	partyIDs := test.PartyIDs(4)
+	// all messages are required with a threshold of N-1
+	threshold := len(partyIDs) - 1
+	self := partyIDs[0]
+	newHandler := func(id party.ID) *protocol.MultiHandler {
+		h, err := protocol.NewMultiHandler(startQuorum(id, partyIDs, threshold), nil)
+		require.NoError(t, err)
+		return h
+	}
+	primary, replica := newHandler(self), newHandler(self)
+	var msgs []*protocol.Message
+	for _, id := range partyIDs[1:] {
+		msgs = append(msgs, drain(newHandler(id))...)
+	}
+	require.Len(t, msgs, 3)
+
+	// the primary receives two of the three messages it needs
+	primary.Accept(msgs[0])
+	primary.Accept(msgs[1])
+	mailbox := primary.ExportMailbox()
+	assert.Len(t, mailbox.Messages, 2)
+	assert.Empty(t, mailbox.Broadcast)
+	require.Len(t, mailbox.Out, 1)
+	assert.Equal(t, self, mailbox.Out[0].From)
+	assert.Len(t, drain(primary), 1, "exported messages should remain available to Listen")
+
+	drain(replica)
+	require.NoError(t, replica.ImportMailbox(mailbox))
+	assert.Len(t, drain(replica), 1, "pending messages should be emitted again")
+	_, err := replica.Result()
+	require.Error(t, err)
+	replica.Accept(msgs[2])
+	result, err := replica.Result()
+	require.NoError(t, err)
+	assert.Equal(t, len(partyIDs), result)
+
+	// importing the same mailbox again has no effect
+	require.NoError(t, replica.ImportMailbox(mailbox))
+
+	other, err := protocol.NewMultiHandler(startQuorum(self, partyIDs, threshold-1), nil)
+	require.NoError(t, err)
+	assert.Error(t, other.ImportMailbox(mailbox), "mailbox of a different session should be rejected")
+	assert.Error(t, other.ImportMailbox(nil))
+}
+
+func TestHandlerImportBroadcastHash(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	self, peer, missing := partyIDs[0], partyIDs[1], partyIDs[2]
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandlerWithOptions(frost.Keygen(curve.Secp256k1{}, id, partyIDs, 1), nil,
+			protocol.HandlerOptions{KeepAllRounds: true})
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	// self never receives the round 2 broadcast of missing
+	var withheld *protocol.Message
+	var queue []*protocol.Message
+	for _, id := range partyIDs {
+		queue = append(queue, drain(handlers[id])...)
+	}
+	for len(queue) > 0 {
+		msg := queue[0]
+		queue = queue[1:]
+		for _, id := range partyIDs {
+			if !msg.IsFor(id) {
+				continue
+			}
+			if id == self && msg.From == missing && msg.Broadcast && msg.RoundNumber == 2 {
+				withheld = msg
+				continue
+			}
+			handlers[id].Accept(msg)
+			queue = append(queue, drain(handlers[id])...)
+		}
+	}
+	require.NotNil(t, withheld)
+	require.Nil(t, handlers[self].BroadcastHash(2))
+
+	hash := handlers[peer].BroadcastHash(2)
+	require.NotNil(t, hash)
+	var broadcasts []*protocol.Message
+	for _, msg := range handlers[peer].ExportMailbox().Broadcast {
+		if msg.RoundNumber == 2 {
+			broadcasts = append(broadcasts, msg)
+		}
+	}
+	require.Len(t, broadcasts, len(partyIDs))
+
+	// the imported messages must agree with the local ones, and with the hash
+	tampered := make([]*protocol.Message, len(broadcasts))
+	copy(tampered, broadcasts)
+	for i, msg := range tampered {
+		if msg.From == self {
+			changed := *msg
+			changed.Data = append([]byte{0}, msg.Data...)
+			tampered[i] = &changed
+		}
+	}
+	assert.ErrorContains(t, handlers[self].ImportBroadcastHash(2, hash, tampered), "differs from the local one")
+	assert.Error(t, handlers[self].ImportBroadcastHash(2, hash, broadcasts[1:]))
+	assert.Error(t, handlers[self].ImportBroadcastHash(2, append([]byte{0}, hash[1:]...), broadcasts))
+	assert.Error(t, handlers[self].ImportBroadcastHash(3, hash, broadcasts))
+	_, err := handlers[self].Result()
+	require.EqualError(t, err, "protocol: not finished")
+
+	require.NoError(t, handlers[self].ImportBroadcastHash(2, hash, broadcasts))
+	assert.Equal(t, hash, handlers[self].BroadcastHash(2))
+	require.NoError(t, handlers[self].ImportBroadcastHash(2, hash, nil), "importing the same hash again has no effect")
+	assert.Error(t, handlers[self].ImportBroadcastHash(2, append([]byte{0}, hash[1:]...), nil))
+
+	// the messages of the following rounds, which were queued, can now be verified
+	for _, h := range handlers {
+		queue = append(queue, drain(h)...)
+	}
+	for _, msg := range queue {
+		for _, id := range partyIDs {
+			if msg.IsFor(id) && handlers[id].CanAccept(msg) {
+				handlers[id].Accept(msg)
+			}
+		}
+	}
+	runHandlers(handlers)
+	var public curve.Point
+	for _, h := range handlers {
+		r, err := h.Result()
+		require.NoError(t, err)
+		c := r.(*frost.Config)
+		if public != nil {
+			assert.True(t, public.Equal(c.PublicKey))
+		}
+		public = c.PublicKey
+	}
+}
@@ -0,0 +1,109 @@
+package protocol
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// MembershipPolicy selects how a MultiHandler treats messages of its session addressed to this party,
+// but sent by a party which does not take part in it. For example, a shareholder which is not among
+// the signers of a sign session, but was started with a different set of signers.
+//
+// Messages of the same protocol which belong to another session, such as a concurrent keygen among other parties
+// on a shared transport, are not subject to the policy: they never abort the session,
+// and are only counted if they are given to Accept, see MultiHandler.OtherSessions.
+type MembershipPolicy uint8
+
+const (
+	// MembershipIgnore drops such messages, and only counts them if they are given to Accept,
+	// see MultiHandler.NonParticipants. It is the default.
+	MembershipIgnore MembershipPolicy = iota
+	// MembershipReport additionally passes a *NonParticipantError to HandlerOptions.OnNonParticipant,
+	// so that operators can detect misconfigured nodes.
+	MembershipReport
+	// MembershipAbort additionally aborts the protocol with the *NonParticipantError, and the sender as culprit.
+	// This is only appropriate when the transport authenticates the sender of messages,
+	// since otherwise anyone could abort the session.
+	MembershipAbort
+)
+
+// NonParticipantError is reported for a message of the session sent by a party which does not take part in it.
+type NonParticipantError struct {
+	// From is the sender of the message.
+	From party.ID
+	// Protocol is the protocol ID of the message.
+	Protocol string
+}
+
+// Error implements error.
+func (e *NonParticipantError) Error() string {
+	return fmt.Sprintf("protocol: %s sent a message to this %s session, but is not one of its parties", e.From, e.Protocol)
+}
+
+// NonParticipants returns the number of messages received from each party which does not take part in the session.
+func (h *MultiHandler) NonParticipants() map[party.ID]int {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	counts := make(map[party.ID]int, len(h.nonParticipants))
+	for id, n := range h.nonParticipants {
+		counts[id] = n
+	}
+	return counts
+}
+
+// OtherSessions returns the number of messages of the protocol addressed to this party, but belonging to
+// another session, which were received from each party.
+func (h *MultiHandler) OtherSessions() map[party.ID]int {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	counts := make(map[party.ID]int, len(h.otherSessions))
+	for id, n := range h.otherSessions {
+		counts[id] = n
+	}
+	return counts
+}
+
+// fromNonParticipant returns true if msg is a message of the session for this party,
+// sent by a party which does not take part in it.
+func (h *MultiHandler) fromNonParticipant(msg *Message) bool {
+	r := h.currentRound
+	return msg != nil && msg.Protocol == r.ProtocolID() && bytes.Equal(msg.SSID, r.SSID()) &&
+		msg.IsFor(r.SelfID()) && !r.PartyIDs().Contains(msg.From)
+}
+
+// fromOtherSession returns true if msg is a message of the protocol for this party, but of another session.
+func (h *MultiHandler) fromOtherSession(msg *Message) bool {
+	r := h.currentRound
+	return msg != nil && msg.Protocol == r.ProtocolID() && !bytes.Equal(msg.SSID, r.SSID()) && msg.IsFor(r.SelfID())
+}
+
+// countOtherSession records msg in the counts returned by OtherSessions, and must be called with h.mtx held.
+func (h *MultiHandler) countOtherSession(msg *Message) {
+	if h.otherSessions == nil {
+		h.otherSessions = make(map[party.ID]int)
+	}
+	h.otherSessions[msg.From]++
+}
+
+// rejectNonParticipant applies the MembershipPolicy of the handler to msg, and must be called with h.mtx held.
+func (h *MultiHandler) rejectNonParticipant(msg *Message) {
+	if h.nonParticipants == nil {
+		h.nonParticipants = make(map[party.ID]int)
+	}
+	h.nonParticipants[msg.From]++
+	if h.membership == MembershipIgnore {
+		return
+	}
+	err := &NonParticipantError{
+		From:     msg.From,
+		Protocol: msg.Protocol,
+	}
+	if h.onNonParticipant != nil {
+		h.onNonParticipant(err)
+	}
+	if h.membership == MembershipAbort && h.err == nil && h.result == nil {
+		h.abort(err, msg.From)
+	}
+}
@@ -0,0 +1,106 @@
+package protocol_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+)
+
+func TestHandlerMembership(t *testing.T) {
+	shareholders := test.PartyIDs(4)
+	// only the first three shareholders take part in the session
+	signers := shareholders[:3]
+	outsider := shareholders[3]
+	// the outsider was started with all shareholders, so its messages belong to another session
+	otherSession := drain(newQuorumHandler(t, outsider, shareholders, protocol.HandlerOptions{}))
+	require.NotEmpty(t, otherSession)
+
+	h := newQuorumHandler(t, signers[0], signers, protocol.HandlerOptions{})
+	sameSession := *otherSession[0]
+	sameSession.SSID = drain(h)[0].SSID
+	assert.False(t, h.CanAccept(otherSession[0]))
+	assert.False(t, h.CanAccept(&sameSession))
+	h.Accept(otherSession[0])
+	h.Accept(&sameSession)
+	assert.Equal(t, 1, h.OtherSessions()[outsider])
+	assert.Equal(t, 1, h.NonParticipants()[outsider])
+
+	var reported []*protocol.NonParticipantError
+	h = newQuorumHandler(t, signers[0], signers, protocol.HandlerOptions{
+		Membership:       protocol.MembershipReport,
+		OnNonParticipant: func(err *protocol.NonParticipantError) { reported = append(reported, err) },
+	})
+	drain(h)
+	assert.False(t, h.CanAccept(otherSession[0]), "messages of other sessions are never reported")
+	require.True(t, h.CanAccept(&sameSession))
+	h.Accept(otherSession[0])
+	h.Accept(&sameSession)
+	require.Len(t, reported, 1)
+	assert.Equal(t, outsider, reported[0].From)
+	assert.Equal(t, 1, h.NonParticipants()[outsider])
+	assert.Equal(t, 1, h.OtherSessions()[outsider])
+	_, err := h.Result()
+	assert.EqualError(t, err, "protocol: not finished", "reported messages should not abort the protocol")
+
+	h = newQuorumHandler(t, signers[0], signers, protocol.HandlerOptions{Membership: protocol.MembershipAbort})
+	drain(h)
+	h.Accept(otherSession[0])
+	_, err = h.Result()
+	assert.EqualError(t, err, "protocol: not finished", "messages of other sessions should not abort the protocol")
+	h.Accept(&sameSession)
+	_, err = h.Result()
+	var nonParticipant *protocol.NonParticipantError
+	require.True(t, errors.As(err, &nonParticipant))
+	assert.Equal(t, outsider, nonParticipant.From)
+}
+
+func TestHandlerMembershipConcurrentSessions(t *testing.T) {
+	ids := test.PartyIDs(4)
+	// two sessions of the same protocol run at the same time among different parties, and share a transport
+	// which delivers every message to all the handlers of its recipients
+	sessions := []party.IDSlice{ids[:3], ids[1:]}
+	type member struct {
+		id party.ID
+		h  *protocol.MultiHandler
+	}
+	var members []member
+	for _, partyIDs := range sessions {
+		for _, id := range partyIDs {
+			h := newQuorumHandler(t, id, partyIDs, protocol.HandlerOptions{Membership: protocol.MembershipAbort})
+			members = append(members, member{id: id, h: h})
+		}
+	}
+
+	for delivered := true; delivered; {
+		delivered = false
+		for _, sender := range members {
+			for _, msg := range drain(sender.h) {
+				for _, m := range members {
+					if m.h != sender.h && msg.IsFor(m.id) {
+						m.h.Accept(msg)
+						delivered = true
+					}
+				}
+			}
+		}
+	}
+
+	for _, m := range members {
+		_, err := m.h.Result()
+		require.NoError(t, err, "party %s", m.id)
+		assert.Empty(t, m.h.NonParticipants())
+	}
+	// the first party only takes part in the first session, and received the messages of the others for the second one
+	assert.Equal(t, map[party.ID]int{ids[3]: 1, ids[1]: 1, ids[2]: 1}, members[0].h.OtherSessions())
+}
+
+func newQuorumHandler(t *testing.T, id party.ID, partyIDs party.IDSlice, opts protocol.HandlerOptions) *protocol.MultiHandler {
+	h, err := protocol.NewMultiHandlerWithOptions(startQuorum(id, partyIDs, len(partyIDs)-1), nil, opts)
+	require.NoError(t, err)
+	return h
+}
@@ -1,6 +1,7 @@
 package protocol
 
 import (
+	"encoding/binary"
 	"fmt"
 
 	"github.com/fxamacker/cbor/v2"
@@ -27,6 +28,9 @@ type Message struct {
 	// BroadcastVerification is the hash of all messages broadcast by the parties,
 	// and is included in all messages in the round following a broadcast round.
 	BroadcastVerification []byte
+	// Epoch is the number of refreshes of the key used by the session, and is zero for protocols which do not use a key.
+	// Messages of another epoch are rejected, see HandlerOptions.OnEpochMismatch.
+	Epoch uint64 `json:",omitempty"`
 }
 
 // String implements fmt.Stringer.
@@ -59,6 +63,10 @@ func (m *Message) Hash() []byte {
 		hash.BytesWithDomain{TheDomain: "Broadcast", Bytes: []byte{broadcast}},
 		hash.BytesWithDomain{TheDomain: "BroadcastVerification", Bytes: m.BroadcastVerification},
 	)
+	// the epoch is omitted when it is zero, so that the hash of such messages is unchanged
+	if m.Epoch != 0 {
+		_ = h.WriteAny(hash.BytesWithDomain{TheDomain: "Epoch", Bytes: binary.BigEndian.AppendUint64(nil, m.Epoch)})
+	}
 	return h.Sum()
 }
 
@@ -75,6 +83,7 @@ type marshallableMessage struct {
 	Data                  []byte
 	Broadcast             bool
 	BroadcastVerification []byte
+	Epoch                 uint64 `cbor:",omitempty"`
 }
 
 func (m *Message) toMarshallable() *marshallableMessage {
@@ -87,6 +96,7 @@ func (m *Message) toMarshallable() *marshallableMessage {
 		Data:                  m.Data,
 		Broadcast:             m.Broadcast,
 		BroadcastVerification: m.BroadcastVerification,
+		Epoch:                 m.Epoch,
 	}
 }
 
@@ -97,7 +107,10 @@ func (m *Message) MarshalBinary() ([]byte, error) {
 func (m *Message) UnmarshalBinary(data []byte) error {
 	deserialized := m.toMarshallable()
 	if err := cbor.Unmarshal(data, deserialized); err != nil {
-		return nil
+		return err
+	}
+	if !deserialized.RoundNumber.Valid() {
+		return fmt.Errorf("protocol: round number %d is out of range", deserialized.RoundNumber)
 	}
 	m.SSID = deserialized.SSID
 	m.From = deserialized.From
@@ -107,5 +120,6 @@ func (m *Message) UnmarshalBinary(data []byte) error {
 	m.Data = deserialized.Data
 	m.Broadcast = deserialized.Broadcast
 	m.BroadcastVerification = deserialized.BroadcastVerification
+	m.Epoch = deserialized.Epoch
 	return nil
 }
@@ -0,0 +1,80 @@
+package protocol
+
+import (
+	"io"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
+)
+
+// HandlerOptions configures optional behaviour of a MultiHandler.
+// The zero value corresponds to the behaviour of a handler created with NewMultiHandler.
+type HandlerOptions struct {
+	// TraceWriter, if not nil, receives a JSON record for every message accepted or emitted by the handler,
+	// as well as for every broadcast hash it computes. See TraceRecord.
+	TraceWriter io.Writer
+	// KeepAllRounds disables pruning of past rounds and their messages.
+	// By default, the handler only keeps the current and the previous round.
+	KeepAllRounds bool
+	// BroadcastHashVersion selects how the hash of all broadcast messages of a round is computed.
+	// All parties must use the same version, otherwise the protocol aborts after the first broadcast round.
+	BroadcastHashVersion BroadcastHashVersion
+	// MessageOrdering selects when P2P messages of a broadcast round are verified.
+	MessageOrdering MessageOrdering
+	// UnicastBroadcast emits each broadcast message as one copy addressed to every other party, for transports
+	// which can only deliver messages to a single recipient, and may then encrypt each copy for its recipient.
+	// The copies still have Broadcast set, and the broadcast hash is computed over the message without its recipient,
+	// so that handlers with and without this option can take part in the same protocol execution.
+	UnicastBroadcast bool
+	// CollectStats enables the collection of message sizes and round timings, see MultiHandler.Stats.
+	CollectStats bool
+	// Fence, if not nil, is claimed with FencingToken before every round is finalized,
+	// so that only one of several redundant instances of a party takes part in the session.
+	Fence Fence
+	// FencingToken must be positive when Fence is set.
+	FencingToken uint64
+	// DeterministicOutput encodes round contents canonically, with sorted map keys, and emits the messages
+	// of a round in a fixed order: broadcast messages first, then by recipient.
+	// The same logical message then always has the same encoding and the same Message.MessageID,
+	// so that retried messages can be deduplicated and correlated across parties.
+	DeterministicOutput bool
+	// Clock is used for round timings, trace timestamps and restart delays.
+	// If nil, platform.DefaultClock is used.
+	Clock platform.Clock
+	// Restart, if not nil, allows the handler to be restarted with Restart after an abort.
+	Restart *RestartPolicy
+	// Answer, if not nil, is called with the messages of other protocols addressed to this party,
+	// for example liveness challenges, see cmp.LivenessResponder.
+	// A non-nil reply is emitted on the channel returned by Listen, while the protocol is running.
+	Answer func(msg *Message) *Message
+	// Membership selects how messages from parties which do not take part in the session are treated.
+	Membership MembershipPolicy
+	// OnNonParticipant, if not nil, is called for every message rejected with MembershipReport or MembershipAbort.
+	// It is called while the handler is locked, and must not call any of its methods.
+	OnNonParticipant func(err *NonParticipantError)
+	// TolerateSelfEcho accepts messages emitted by this handler when a transport or coordinator echoes them back.
+	// CanAccept then returns true for them, and Accept ignores them, unless an echoed broadcast message differs
+	// from the one which was sent, in which case the handler aborts, since other parties may have received it.
+	// By default, CanAccept returns false for messages from self, and Accept ignores them.
+	TolerateSelfEcho bool
+	// OnEpochMismatch, if not nil, is called for every message of the protocol addressed to this party
+	// whose epoch differs from the epoch of the session, see Message.Epoch, and CanAccept then returns true for them.
+	// Such messages are always dropped without aborting the session: after a refresh, a party may still complete
+	// sessions started with the previous config while others already use the new one.
+	// It is called while the handler is locked, and must not call any of its methods.
+	OnEpochMismatch func(err *EpochMismatchError)
+}
+
+// MessageOrdering is a policy deciding when the handler verifies the P2P messages of a round
+// which also expects broadcast messages.
+type MessageOrdering uint8
+
+const (
+	// OrderPerSender verifies the P2P message of a party as soon as the broadcast message of that same party
+	// has been received. It is the default.
+	OrderPerSender MessageOrdering = iota
+	// OrderBroadcastFirst only verifies the P2P messages of a round once the broadcast messages of all parties
+	// have been received and stored, and then verifies them in the order of the party IDs.
+	// The round state seen by each P2P message is then the same regardless of the order in which messages
+	// arrive from the network, which makes verification and the identification of culprits deterministic.
+	OrderBroadcastFirst
+)
@@ -0,0 +1,172 @@
+package protocol_test
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/frost"
+)
+
+// orderRound1 broadcasts a value, and sends a P2P message to every party.
+type orderRound1 struct {
+	*round.Helper
+}
+
+func (orderRound1) VerifyMessage(round.Message) error { return nil }
+func (orderRound1) StoreMessage(round.Message) error  { return nil }
+func (r *orderRound1) Finalize(out chan<- *round.Message) (round.Session, error) {
+	if err := r.BroadcastMessage(out, &orderBroadcast2{Value: 1}); err != nil {
+		return r, err
+	}
+	for _, j := range r.OtherPartyIDs() {
+		if err := r.SendMessage(out, &orderMessage2{}, j); err != nil {
+			return r, err
+		}
+	}
+	return &orderRound2{orderRound1: r, broadcasts: map[party.ID]int{}}, nil
+}
+func (orderRound1) MessageContent() round.Content { return nil }
+func (orderRound1) Number() round.Number          { return 1 }
+
+// orderRound2 can only verify a P2P message once it has stored the broadcasts of all other parties,
+// as is the case for rounds whose P2P checks depend on values broadcast by everyone.
+type orderRound2 struct {
+	*orderRound1
+	broadcasts map[party.ID]int
+}
+
+type orderBroadcast2 struct {
+	round.NormalBroadcastContent
+	Value int
+}
+
+func (orderBroadcast2) RoundNumber() round.Number { return 2 }
+
+type orderMessage2 struct{}
+
+func (orderMessage2) RoundNumber() round.Number { return 2 }
+
+func (r *orderRound2) StoreBroadcastMessage(msg round.Message) error {
+	r.broadcasts[msg.From] = msg.Content.(*orderBroadcast2).Value
+	return nil
+}
+func (r *orderRound2) VerifyMessage(round.Message) error {
+	if len(r.broadcasts) != r.N()-1 {
+		return errors.New("verified before all broadcasts were stored")
+	}
+	return nil
+}
+func (orderRound2) StoreMessage(round.Message) error { return nil }
+func (r *orderRound2) Finalize(chan<- *round.Message) (round.Session, error) {
+	return r.ResultRound(len(r.broadcasts)), nil
+}
+func (orderRound2) MessageContent() round.Content            { return &orderMessage2{} }
+func (orderRound2) BroadcastContent() round.BroadcastContent { return &orderBroadcast2{} }
+func (orderRound2) Number() round.Number                     { return 2 }
+
+func startOrder(selfID party.ID, partyIDs party.IDSlice) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		helper, err := round.NewSession(round.Info{
+			ProtocolID:       "test/order",
+			FinalRoundNumber: 2,
+			SelfID:           selfID,
+			PartyIDs:         partyIDs,
+			Threshold:        1,
+		}, sessionID, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &orderRound1{Helper: helper}, nil
+	}
+}
+
+// runOrdering delivers all messages for the first party in the given order, and returns its result.
+func runOrdering(t *testing.T, ordering protocol.MessageOrdering, shuffle func([]*protocol.Message)) (interface{}, error) {
+	partyIDs := test.PartyIDs(4)
+	self := partyIDs[0]
+	var msgs []*protocol.Message
+	var h *protocol.MultiHandler
+	for _, id := range partyIDs {
+		handler, err := protocol.NewMultiHandlerWithOptions(startOrder(id, partyIDs), nil, protocol.HandlerOptions{
+			MessageOrdering: ordering,
+		})
+		require.NoError(t, err)
+		if id == self {
+			h = handler
+			continue
+		}
+		for _, msg := range drain(handler) {
+			if msg.IsFor(self) {
+				msgs = append(msgs, msg)
+			}
+		}
+	}
+	shuffle(msgs)
+	for _, msg := range msgs {
+		h.Accept(msg)
+	}
+	return h.Result()
+}
+
+// broadcastLast orders the messages so that one party's broadcast arrives after all P2P messages.
+func broadcastLast(msgs []*protocol.Message) {
+	var last *protocol.Message
+	out := msgs[:0]
+	for _, msg := range msgs {
+		if msg.Broadcast && last == nil {
+			last = msg
+			continue
+		}
+		out = append(out, msg)
+	}
+	copy(msgs, append(out, last))
+}
+
+func TestHandlerOrdering(t *testing.T) {
+	// with the default ordering, a P2P message may be verified before all broadcasts have been stored
+	_, err := runOrdering(t, protocol.OrderPerSender, broadcastLast)
+	assert.Error(t, err)
+
+	result, err := runOrdering(t, protocol.OrderBroadcastFirst, broadcastLast)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result)
+
+	// any adversarial ordering gives the same result
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		result, err = runOrdering(t, protocol.OrderBroadcastFirst, func(msgs []*protocol.Message) {
+			rng.Shuffle(len(msgs), func(i, j int) { msgs[i], msgs[j] = msgs[j], msgs[i] })
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, result)
+	}
+
+	// a real protocol completes with the strict ordering
+	partyIDs := test.PartyIDs(3)
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandlerWithOptions(frost.Keygen(curve.Secp256k1{}, id, partyIDs, 1), nil, protocol.HandlerOptions{
+			MessageOrdering: protocol.OrderBroadcastFirst,
+		})
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	runHandlers(handlers)
+	for _, h := range handlers {
+		_, err = h.Result()
+		require.NoError(t, err)
+	}
+
+	_, err = protocol.NewMultiHandlerWithOptions(startOrder("a", party.IDSlice{"a", "b"}), nil, protocol.HandlerOptions{
+		MessageOrdering: protocol.OrderBroadcastFirst + 1,
+	})
+	assert.Error(t, err)
+}
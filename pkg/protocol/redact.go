@@ -0,0 +1,87 @@
+package protocol
+
+import (
+	"encoding/hex"
+	"sort"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/internal/types"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// RedactedHandler is a snapshot of the state of a MultiHandler which can safely be shared, for example in a bug report.
+// The content of messages may contain secret shares, so it is replaced by its length and a short digest,
+// which can be compared with the dumps of other parties.
+type RedactedHandler struct {
+	Protocol    string                  `json:"protocol"`
+	SSID        string                  `json:"ssid"`
+	SelfID      party.ID                `json:"self"`
+	RoundNumber round.Number            `json:"round"`
+	FinalRound  round.Number            `json:"finalRound"`
+	Done        bool                    `json:"done"`
+	Error       string                  `json:"error,omitempty"`
+	Messages    []RedactedMessage       `json:"messages"`
+	Hashes      map[round.Number]string `json:"broadcastHashes"`
+}
+
+// RedactedMessage describes a message stored by the handler, without its content.
+type RedactedMessage struct {
+	RoundNumber round.Number `json:"round"`
+	From        party.ID     `json:"from"`
+	To          party.ID     `json:"to,omitempty"`
+	Broadcast   bool         `json:"broadcast,omitempty"`
+	Size        int          `json:"size"`
+	Digest      string       `json:"digest"`
+}
+
+// Redact returns a snapshot of the state of the handler without any secret, which can be marshalled with encoding/json.
+// The state of the rounds themselves is not included, and neither is the result of the protocol.
+func (h *MultiHandler) Redact() *RedactedHandler {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	r := h.currentRound
+	rh := &RedactedHandler{
+		Protocol:    r.ProtocolID(),
+		SSID:        hex.EncodeToString(r.SSID()),
+		SelfID:      r.SelfID(),
+		RoundNumber: r.Number(),
+		FinalRound:  r.FinalRoundNumber(),
+		Done:        h.result != nil,
+		Hashes:      make(map[round.Number]string, len(h.broadcastHashes)),
+	}
+	if h.err != nil {
+		rh.Error = h.err.Error()
+	}
+	for _, queue := range []map[round.Number]map[party.ID]*Message{h.broadcast, h.messages} {
+		for _, msgs := range queue {
+			for _, msg := range msgs {
+				if msg == nil {
+					continue
+				}
+				rh.Messages = append(rh.Messages, RedactedMessage{
+					RoundNumber: msg.RoundNumber,
+					From:        msg.From,
+					To:          msg.To,
+					Broadcast:   msg.Broadcast,
+					Size:        len(msg.Data),
+					Digest:      types.RedactedDigest(msg.Data),
+				})
+			}
+		}
+	}
+	sort.Slice(rh.Messages, func(i, j int) bool {
+		a, b := rh.Messages[i], rh.Messages[j]
+		if a.RoundNumber != b.RoundNumber {
+			return a.RoundNumber < b.RoundNumber
+		}
+		if a.Broadcast != b.Broadcast {
+			return a.Broadcast
+		}
+		return a.From < b.From
+	})
+	for number, hash := range h.broadcastHashes {
+		rh.Hashes[number] = hex.EncodeToString(hash)
+	}
+	return rh
+}
@@ -0,0 +1,61 @@
+package protocol_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/frost"
+)
+
+func TestHandlerRedact(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandlerWithOptions(frost.Keygen(curve.Secp256k1{}, id, partyIDs, 1), nil, protocol.HandlerOptions{
+			KeepAllRounds: true,
+		})
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+
+	// frost keygen sends its shares in the clear, which must not appear in the dump
+	var p2p []*protocol.Message
+	self := partyIDs[0]
+	for {
+		var msgs []*protocol.Message
+		for _, id := range partyIDs {
+			msgs = append(msgs, drain(handlers[id])...)
+		}
+		if len(msgs) == 0 {
+			break
+		}
+		for _, msg := range msgs {
+			if !msg.Broadcast && msg.To == self {
+				p2p = append(p2p, msg)
+			}
+			for id, h := range handlers {
+				if msg.IsFor(id) {
+					h.Accept(msg)
+				}
+			}
+		}
+	}
+	require.NotEmpty(t, p2p)
+
+	redacted := handlers[self].Redact()
+	assert.True(t, redacted.Done)
+	assert.Equal(t, self, redacted.SelfID)
+	assert.NotEmpty(t, redacted.Messages)
+	data, err := json.Marshal(redacted)
+	require.NoError(t, err)
+	for _, msg := range p2p {
+		assert.NotContains(t, string(data), hex.EncodeToString(msg.Data))
+	}
+}
@@ -0,0 +1,154 @@
+package protocol
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// RejectReason is the reason why a handler does not accept a message, see MultiHandler.CanAcceptErr.
+type RejectReason uint8
+
+const (
+	// RejectNilMessage is returned for a nil message.
+	RejectNilMessage RejectReason = iota + 1
+	// RejectInvalidHeader is returned for a message whose header is invalid, see Header.Validate.
+	RejectInvalidHeader
+	// RejectWrongRecipient is returned for a message addressed to another party.
+	RejectWrongRecipient
+	// RejectWrongProtocol is returned for a message of another protocol.
+	RejectWrongProtocol
+	// RejectWrongSSID is returned for a message of another session of the protocol.
+	RejectWrongSSID
+	// RejectWrongEpoch is returned for a message of the session sent with a config of another epoch.
+	RejectWrongEpoch
+	// RejectUnknownSender is returned for a message sent by a party which does not take part in the session.
+	RejectUnknownSender
+	// RejectNilData is returned for a message without content.
+	RejectNilData
+	// RejectInvalidRound is returned for a message of a round after the final round of the protocol.
+	RejectInvalidRound
+	// RejectStaleRound is returned for a message of a round which the handler has already completed.
+	RejectStaleRound
+)
+
+// String implements fmt.Stringer.
+func (r RejectReason) String() string {
+	switch r {
+	case RejectNilMessage:
+		return "nil message"
+	case RejectInvalidHeader:
+		return "invalid header"
+	case RejectWrongRecipient:
+		return "wrong recipient"
+	case RejectWrongProtocol:
+		return "wrong protocol"
+	case RejectWrongSSID:
+		return "wrong SSID"
+	case RejectWrongEpoch:
+		return "wrong epoch"
+	case RejectUnknownSender:
+		return "unknown sender"
+	case RejectNilData:
+		return "nil data"
+	case RejectInvalidRound:
+		return "invalid round"
+	case RejectStaleRound:
+		return "stale round"
+	}
+	return fmt.Sprintf("RejectReason(%d)", uint8(r))
+}
+
+// RejectError is returned by CanAcceptErr for a message which the handler does not accept.
+// Transports can log or count rejections by Reason, or report it to the sender.
+type RejectError struct {
+	// Reason is the first check the message failed.
+	Reason RejectReason
+	// From, Protocol and RoundNumber are copied from the message, if it is not nil.
+	From        party.ID
+	Protocol    string
+	RoundNumber round.Number
+}
+
+// Error implements error.
+func (e *RejectError) Error() string {
+	if e.Reason == RejectNilMessage {
+		return "protocol: message rejected: nil message"
+	}
+	return fmt.Sprintf("protocol: message from %s for round %d of %s rejected: %s", e.From, e.RoundNumber, e.Protocol, e.Reason)
+}
+
+// CanAcceptErr is the same as CanAccept, but returns a *RejectError with the reason why msg is not accepted,
+// or nil if CanAccept returns true.
+func (h *MultiHandler) CanAcceptErr(msg *Message) error {
+	if h.CanAccept(msg) {
+		return nil
+	}
+	return newRejectError(msg, h.rejectReason(msg))
+}
+
+// CanAcceptErr is the same as CanAccept, but returns a *RejectError with the reason why msg is not accepted,
+// or nil if CanAccept returns true.
+func (h *TwoPartyHandler) CanAcceptErr(msg *Message) error {
+	if h.CanAccept(msg) {
+		return nil
+	}
+	return newRejectError(msg, rejectReason(h.round, msg))
+}
+
+// rejectReason returns the reason why msg does not belong to the current round of the handler, or 0 if it does.
+func (h *MultiHandler) rejectReason(msg *Message) RejectReason {
+	if msg == nil {
+		return RejectNilMessage
+	}
+	// the header is checked without decoding the content
+	if msg.Header().Validate() != nil {
+		return RejectInvalidHeader
+	}
+	r := h.currentRound
+	if reason := rejectReason(r, msg); reason != 0 {
+		return reason
+	}
+	if msg.RoundNumber < r.Number() && msg.RoundNumber > 0 {
+		return RejectStaleRound
+	}
+	return 0
+}
+
+// rejectReason returns the reason why msg does not belong to the session of r, or 0 if it does.
+func rejectReason(r round.Session, msg *Message) RejectReason {
+	switch {
+	case msg == nil:
+		return RejectNilMessage
+	// are we the intended recipient
+	case !msg.IsFor(r.SelfID()):
+		return RejectWrongRecipient
+	case msg.Protocol != r.ProtocolID():
+		return RejectWrongProtocol
+	case !bytes.Equal(msg.SSID, r.SSID()):
+		return RejectWrongSSID
+	case msg.Epoch != r.Epoch():
+		return RejectWrongEpoch
+	case !r.PartyIDs().Contains(msg.From):
+		return RejectUnknownSender
+	case msg.Data == nil:
+		return RejectNilData
+	case msg.RoundNumber > r.FinalRoundNumber():
+		return RejectInvalidRound
+	}
+	return 0
+}
+
+func newRejectError(msg *Message, reason RejectReason) *RejectError {
+	if msg == nil {
+		return &RejectError{Reason: RejectNilMessage}
+	}
+	return &RejectError{
+		Reason:      reason,
+		From:        msg.From,
+		Protocol:    msg.Protocol,
+		RoundNumber: msg.RoundNumber,
+	}
+}
@@ -0,0 +1,56 @@
+package protocol_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+)
+
+func TestCanAcceptErr(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	h, err := protocol.NewMultiHandler(startQuorum(partyIDs[0], partyIDs, 2), nil)
+	require.NoError(t, err)
+	drain(h)
+	other, err := protocol.NewMultiHandler(startQuorum(partyIDs[1], partyIDs, 2), nil)
+	require.NoError(t, err)
+	msgs := drain(other)
+	require.NotEmpty(t, msgs)
+	valid := msgs[0]
+	require.NoError(t, h.CanAcceptErr(valid))
+
+	tests := []struct {
+		name   string
+		modify func(msg *protocol.Message)
+		reason protocol.RejectReason
+	}{
+		{"wrong recipient", func(msg *protocol.Message) { msg.To = partyIDs[2] }, protocol.RejectWrongRecipient},
+		{"wrong protocol", func(msg *protocol.Message) { msg.Protocol = "test/other" }, protocol.RejectWrongProtocol},
+		{"wrong SSID", func(msg *protocol.Message) { msg.SSID = []byte("other") }, protocol.RejectWrongSSID},
+		{"wrong epoch", func(msg *protocol.Message) { msg.Epoch = 1 }, protocol.RejectWrongEpoch},
+		{"unknown sender", func(msg *protocol.Message) { msg.From = "unknown" }, protocol.RejectUnknownSender},
+		{"nil data", func(msg *protocol.Message) { msg.Data = nil }, protocol.RejectNilData},
+		{"invalid round", func(msg *protocol.Message) { msg.RoundNumber = 3 }, protocol.RejectInvalidRound},
+		{"stale round", func(msg *protocol.Message) { msg.RoundNumber = 1 }, protocol.RejectStaleRound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := *valid
+			tt.modify(&msg)
+			assert.False(t, h.CanAccept(&msg))
+			err := h.CanAcceptErr(&msg)
+			var rejectErr *protocol.RejectError
+			require.True(t, errors.As(err, &rejectErr))
+			assert.Equal(t, tt.reason, rejectErr.Reason, rejectErr.Reason.String())
+			assert.Equal(t, msg.From, rejectErr.From)
+		})
+	}
+
+	err = h.CanAcceptErr(nil)
+	var rejectErr *protocol.RejectError
+	require.True(t, errors.As(err, &rejectErr))
+	assert.Equal(t, protocol.RejectNilMessage, rejectErr.Reason)
+}
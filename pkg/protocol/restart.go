@@ -0,0 +1,95 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrRestartLimit is returned by Restart once a handler has been restarted RestartPolicy.MaxAttempts times.
+var ErrRestartLimit = errors.New("protocol: restart limit reached")
+
+// RestartPolicy configures how a handler may be restarted with Restart after an abort.
+type RestartPolicy struct {
+	// MaxAttempts is the maximum number of restarts, not counting the initial execution.
+	MaxAttempts int
+	// Backoff is the delay before the first restart, which is doubled on every subsequent attempt.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between attempts. It is ignored if zero.
+	MaxBackoff time.Duration
+}
+
+// Delay returns the delay before the given attempt, starting at 1.
+func (p RestartPolicy) Delay(attempt uint64) time.Duration {
+	if attempt == 0 {
+		return 0
+	}
+	delay := p.Backoff
+	for i := uint64(1); i < attempt && delay > 0 && delay <= math.MaxInt64/2; i++ {
+		if p.MaxBackoff > 0 && delay >= p.MaxBackoff {
+			break
+		}
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// RestartSessionID derives the session ID of the given restart attempt from the session ID of the previous execution.
+// All parties compute the same value, so that no interaction is needed to agree on the new session.
+func RestartSessionID(sessionID []byte, attempt uint64) []byte {
+	return DeriveSessionID("restart", attempt, sessionID)
+}
+
+// Restart stops h if it is still running, which notifies the other parties,
+// and then creates a new handler for the same protocol and options, after the delay given by the RestartPolicy of h.
+// The session ID of the new handler is derived with RestartSessionID, so that messages of the
+// previous execution are ignored.
+//
+// All parties must restart the same number of times for the session IDs to match.
+// An error is returned if h has no RestartPolicy, has completed successfully, or has reached the maximum number of attempts.
+func Restart(h *MultiHandler) (*MultiHandler, error) {
+	h.mtx.Lock()
+	if h.restart == nil {
+		h.mtx.Unlock()
+		return nil, errors.New("protocol: handler has no restart policy")
+	}
+	if h.result != nil {
+		h.mtx.Unlock()
+		return nil, errors.New("protocol: handler has already completed")
+	}
+	attempt := h.attempt + 1
+	if attempt > uint64(h.restart.MaxAttempts) {
+		h.mtx.Unlock()
+		return nil, fmt.Errorf("%w: %d attempts", ErrRestartLimit, h.restart.MaxAttempts)
+	}
+	if h.err == nil {
+		h.abort(fmt.Errorf("restarting, attempt %d", attempt), h.currentRound.SelfID())
+	}
+	create, opts := h.create, h.opts
+	sessionID := RestartSessionID(h.sessionID, attempt)
+	delay := h.restart.Delay(attempt)
+	clock := h.clock
+	h.mtx.Unlock()
+
+	if delay > 0 {
+		<-clock.After(delay)
+	}
+
+	next, err := NewMultiHandlerWithOptions(create, sessionID, opts)
+	if err != nil {
+		return nil, err
+	}
+	next.attempt = attempt
+	return next, nil
+}
+
+// Attempt returns the number of times this execution has been restarted.
+func (h *MultiHandler) Attempt() uint64 {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.attempt
+}
@@ -0,0 +1,118 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+)
+
+// ResultKind classifies the result of a protocol, so that integrators can handle results
+// without relying on the round number or on type assertions for every protocol.
+type ResultKind string
+
+const (
+	// ResultUnknown is the kind of results which do not declare one.
+	ResultUnknown ResultKind = ""
+	// ResultConfig is the kind of key generation and refresh results.
+	ResultConfig ResultKind = "config"
+	// ResultSignature is the kind of signing results.
+	ResultSignature ResultKind = "signature"
+	// ResultPreSignature is the kind of presigning results.
+	ResultPreSignature ResultKind = "presignature"
+)
+
+// ResultMetadata describes the protocol execution which produced a result.
+type ResultMetadata struct {
+	// Protocol is the ID of the protocol, for example "cmp/keygen-threshold".
+	Protocol string
+	// Kind is the kind of the result, see KindOf.
+	Kind ResultKind
+	// FinalRound is the number of the last round of the protocol.
+	FinalRound round.Number
+	// Group is the name of the curve used by the protocol, if any.
+	Group string
+	// SSID identifies the protocol execution.
+	SSID []byte
+}
+
+// KindOf returns the kind of result, as declared by a method
+//
+//	ResultKind() string
+//
+// which is implemented by the results of all protocols in this module.
+// Slices of results, as returned by batch protocols, have the kind of their elements.
+func KindOf(result interface{}) ResultKind {
+	type kinded interface{ ResultKind() string }
+	if k, ok := result.(kinded); ok {
+		return ResultKind(k.ResultKind())
+	}
+	v := reflect.ValueOf(result)
+	if v.Kind() == reflect.Slice && v.Len() > 0 {
+		if k, ok := v.Index(0).Interface().(kinded); ok {
+			return ResultKind(k.ResultKind())
+		}
+	}
+	return ResultUnknown
+}
+
+// ResultMetadata returns the metadata of the result of the protocol, or an error if it did not complete successfully.
+func (h *MultiHandler) ResultMetadata() (ResultMetadata, error) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if h.result == nil {
+		if h.err != nil {
+			return ResultMetadata{}, *h.err
+		}
+		return ResultMetadata{}, errors.New("protocol: not finished")
+	}
+	r := h.currentRound
+	metadata := ResultMetadata{
+		Protocol:   r.ProtocolID(),
+		Kind:       KindOf(h.result),
+		FinalRound: r.FinalRoundNumber(),
+		SSID:       bytes.Clone(r.SSID()),
+	}
+	if group := r.Group(); group != nil {
+		metadata.Group = group.Name()
+	}
+	return metadata, nil
+}
+
+// ResultEnvelope contains the result of a protocol along with its metadata, so that it can be persisted,
+// for example with encoding/json, and decoded back into its original type.
+// Decoding requires the codec of the protocol which produced the result, see cmp.DecodeResult.
+type ResultEnvelope struct {
+	ResultMetadata
+	// Data is the CBOR encoding of the result.
+	Data []byte
+}
+
+// ResultEnvelope returns the result of the protocol in a ResultEnvelope,
+// or an error if it did not complete successfully.
+func (h *MultiHandler) ResultEnvelope() (*ResultEnvelope, error) {
+	metadata, err := h.ResultMetadata()
+	if err != nil {
+		return nil, err
+	}
+	h.mtx.Lock()
+	result := h.result
+	h.mtx.Unlock()
+	data, err := cbor.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("protocol: failed to encode result: %w", err)
+	}
+	return &ResultEnvelope{ResultMetadata: metadata, Data: data}, nil
+}
+
+// Decode unmarshals the result into v, which must be initialized for decoding,
+// for example with ecdsa.EmptySignature.
+func (e *ResultEnvelope) Decode(v interface{}) error {
+	if err := cbor.Unmarshal(e.Data, v); err != nil {
+		return fmt.Errorf("protocol: failed to decode %s result: %w", e.Protocol, err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package protocol_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/frost"
+)
+
+// runEchoHandlers is the same as runHandlers, but delivers every message to all handlers, including its sender,
+// as a coordinator relaying messages to all parties does. Messages are only delivered if CanAccept returns true.
+func runEchoHandlers(handlers map[party.ID]*protocol.MultiHandler) (echoes int) {
+	for {
+		delivered := false
+		for _, h := range handlers {
+			for _, msg := range drain(h) {
+				for _, other := range handlers {
+					if !other.CanAccept(msg) {
+						continue
+					}
+					if msg.From == other.CurrentRound().SelfID() {
+						echoes++
+					}
+					other.Accept(msg)
+					delivered = true
+				}
+			}
+		}
+		if !delivered {
+			return echoes
+		}
+	}
+}
+
+func TestHandlerSelfEcho(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	for _, tolerate := range []bool{false, true} {
+		for _, unicast := range []bool{false, true} {
+			handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+			for _, id := range partyIDs {
+				h, err := protocol.NewMultiHandlerWithOptions(frost.Keygen(curve.Secp256k1{}, id, partyIDs, 1), nil, protocol.HandlerOptions{
+					TolerateSelfEcho: tolerate,
+					UnicastBroadcast: unicast,
+				})
+				require.NoError(t, err)
+				handlers[id] = h
+			}
+			echoes := runEchoHandlers(handlers)
+			if tolerate {
+				assert.NotZero(t, echoes)
+			} else {
+				assert.Zero(t, echoes)
+			}
+			var public curve.Point
+			for _, h := range handlers {
+				r, err := h.Result()
+				require.NoError(t, err, "tolerate: %v, unicast: %v", tolerate, unicast)
+				c := r.(*frost.Config)
+				if public != nil {
+					assert.True(t, public.Equal(c.PublicKey))
+				}
+				public = c.PublicKey
+			}
+		}
+	}
+}
+
+func TestHandlerSelfEchoTampered(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	self := partyIDs[0]
+	newHandler := func(tolerate bool) (*protocol.MultiHandler, []*protocol.Message) {
+		h, err := protocol.NewMultiHandlerWithOptions(frost.Keygen(curve.Secp256k1{}, self, partyIDs, 1), nil, protocol.HandlerOptions{
+			TolerateSelfEcho: tolerate,
+		})
+		require.NoError(t, err)
+		return h, drain(h)
+	}
+
+	for _, tolerate := range []bool{false, true} {
+		h, sent := newHandler(tolerate)
+		require.NotEmpty(t, sent)
+		for _, msg := range sent {
+			assert.Equal(t, tolerate, h.CanAccept(msg))
+			// identical echoes are idempotent
+			h.Accept(msg)
+			h.Accept(msg)
+		}
+		_, err := h.Result()
+		assert.EqualError(t, err, "protocol: not finished")
+
+		var broadcast *protocol.Message
+		for _, msg := range sent {
+			if msg.Broadcast {
+				broadcast = msg
+			}
+		}
+		require.NotNil(t, broadcast)
+		tampered := *broadcast
+		tampered.Data = append([]byte{0}, broadcast.Data...)
+		h.Accept(&tampered)
+		_, err = h.Result()
+		if tolerate {
+			assert.ErrorContains(t, err, "echo of own broadcast message")
+		} else {
+			assert.EqualError(t, err, "protocol: not finished")
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
+)
+
+const (
+	// SessionIDLength is the length in bytes of session IDs created by NewSessionID and DeriveSessionID.
+	SessionIDLength = 32
+	// MinSessionIDLength is the minimum length in bytes of a session ID accepted by the handlers.
+	// Shorter identifiers, such as human readable strings, provide weak domain separation
+	// between protocol executions.
+	MinSessionIDLength = 16
+)
+
+// NewSessionID returns a uniformly random session ID of SessionIDLength bytes.
+//
+// All parties must use the same session ID, so it should be generated by a single party
+// and then distributed to the others.
+func NewSessionID() ([]byte, error) {
+	sessionID := make([]byte, SessionIDLength)
+	if _, err := io.ReadFull(platform.Reader, sessionID); err != nil {
+		return nil, fmt.Errorf("protocol: failed to sample session ID: %w", err)
+	}
+	return sessionID, nil
+}
+
+// DeriveSessionID deterministically derives a session ID of SessionIDLength bytes,
+// which all parties can compute locally without interaction.
+//
+// purpose describes the kind of execution, for example "sign" or "refresh",
+// counter must be incremented after every execution with the same purpose and key,
+// and keyFingerprint identifies the key the protocol is run with, for example its public key
+// or the RID of a config. The session ID is unique as long as the counter is never reused.
+func DeriveSessionID(purpose string, counter uint64, keyFingerprint []byte) []byte {
+	counterBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(counterBytes, counter)
+	h := hash.New(
+		&hash.BytesWithDomain{TheDomain: "Session Purpose", Bytes: []byte(purpose)},
+		&hash.BytesWithDomain{TheDomain: "Session Counter", Bytes: counterBytes},
+		&hash.BytesWithDomain{TheDomain: "Key Fingerprint", Bytes: keyFingerprint},
+	)
+	sessionID := make([]byte, SessionIDLength)
+	_, _ = io.ReadFull(h.Digest(), sessionID)
+	return sessionID
+}
+
+// ValidateSessionID returns an error if a session ID was given, but is too short,
+// or consists of a single repeated byte.
+// A nil session ID is accepted, since it remains optional.
+// This check is performed by the handlers when they are created.
+func ValidateSessionID(sessionID []byte) error {
+	if sessionID == nil {
+		return nil
+	}
+	if len(sessionID) < MinSessionIDLength {
+		return fmt.Errorf("protocol: session ID must be at least %d bytes, got %d", MinSessionIDLength, len(sessionID))
+	}
+	for _, b := range sessionID[1:] {
+		if b != sessionID[0] {
+			return nil
+		}
+	}
+	return fmt.Errorf("protocol: session ID has no entropy")
+}
@@ -0,0 +1,45 @@
+package protocol_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/example"
+)
+
+func TestSessionID(t *testing.T) {
+	a, err := protocol.NewSessionID()
+	require.NoError(t, err)
+	b, err := protocol.NewSessionID()
+	require.NoError(t, err)
+	assert.Len(t, a, protocol.SessionIDLength)
+	assert.False(t, bytes.Equal(a, b))
+
+	derived := protocol.DeriveSessionID("sign", 1, []byte("key"))
+	assert.Len(t, derived, protocol.SessionIDLength)
+	assert.Equal(t, derived, protocol.DeriveSessionID("sign", 1, []byte("key")))
+	assert.NotEqual(t, derived, protocol.DeriveSessionID("sign", 2, []byte("key")))
+	assert.NotEqual(t, derived, protocol.DeriveSessionID("refresh", 1, []byte("key")))
+	assert.NotEqual(t, derived, protocol.DeriveSessionID("sign", 1, []byte("other key")))
+}
+
+func TestHandlerSessionIDValidation(t *testing.T) {
+	partyIDs := test.PartyIDs(2)
+	start := example.StartXOR(partyIDs[0], partyIDs)
+
+	_, err := protocol.NewMultiHandler(start, nil)
+	assert.NoError(t, err, "session ID is optional")
+
+	_, err = protocol.NewMultiHandler(start, []byte("abc-2of3-test"))
+	assert.Error(t, err, "short session ID should be rejected")
+
+	_, err = protocol.NewMultiHandler(start, make([]byte, protocol.SessionIDLength))
+	assert.Error(t, err, "constant session ID should be rejected")
+
+	_, err = protocol.NewMultiHandler(start, protocol.DeriveSessionID("xor", 0, nil))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,157 @@
+package protocol
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// Codec identifies a serialization of Message.
+type Codec uint8
+
+const (
+	// CodecCBOR is the encoding produced by Message.MarshalBinary.
+	CodecCBOR Codec = iota
+	// CodecJSON is the default encoding of Message by encoding/json.
+	CodecJSON
+)
+
+// messageFields are the names of the fields of Message, in the order in which they are encoded.
+// The last field, the epoch, is omitted when it is zero.
+var messageFields = []string{"SSID", "From", "To", "Protocol", "RoundNumber", "Data", "Broadcast", "BroadcastVerification", "Epoch"}
+
+// fields returns the names of the fields encoded for m.
+func (m *Message) fields() []string {
+	if m.Epoch == 0 {
+		return messageFields[:len(messageFields)-1]
+	}
+	return messageFields
+}
+
+// EncodedSize returns the exact length of the encoding of m with the given codec, without encoding the byte slices of m.
+// It can be used to check that a message fits in a buffer before marshalling it.
+func (m *Message) EncodedSize(codec Codec) (int, error) {
+	switch codec {
+	case CodecCBOR:
+		return m.cborSize(), nil
+	case CodecJSON:
+		return m.jsonSize()
+	default:
+		return 0, fmt.Errorf("protocol: unknown codec %d", codec)
+	}
+}
+
+func (m *Message) cborSize() int {
+	fields := m.fields()
+	size := cborHeadSize(uint64(len(fields)))
+	for _, field := range fields {
+		size += cborStringSize(len(field))
+	}
+	size += cborBytesSize(m.SSID)
+	size += cborStringSize(len(m.From))
+	size += cborStringSize(len(m.To))
+	size += cborStringSize(len(m.Protocol))
+	size += cborHeadSize(uint64(m.RoundNumber))
+	size += cborBytesSize(m.Data)
+	// booleans are encoded in a single byte
+	size++
+	size += cborBytesSize(m.BroadcastVerification)
+	if m.Epoch != 0 {
+		size += cborHeadSize(m.Epoch)
+	}
+	return size
+}
+
+// cborHeadSize returns the length of the head of a CBOR data item with argument n.
+func cborHeadSize(n uint64) int {
+	switch {
+	case n < 24:
+		return 1
+	case n <= 0xff:
+		return 2
+	case n <= 0xffff:
+		return 3
+	case n <= 0xffffffff:
+		return 5
+	default:
+		return 9
+	}
+}
+
+func cborStringSize(n int) int {
+	return cborHeadSize(uint64(n)) + n
+}
+
+func cborBytesSize(b []byte) int {
+	// nil slices are encoded as null
+	if b == nil {
+		return 1
+	}
+	return cborStringSize(len(b))
+}
+
+func (m *Message) jsonSize() (int, error) {
+	// braces, and a colon after each field name, separated by commas
+	fields := m.fields()
+	size := 2 + 2*len(fields) - 1
+	for _, field := range fields {
+		size += len(field) + 2
+	}
+	// only strings may need escaping, so they are encoded, the byte slices are not
+	for _, s := range []string{string(m.From), string(m.To), m.Protocol} {
+		encoded, err := json.Marshal(s)
+		if err != nil {
+			return 0, err
+		}
+		size += len(encoded)
+	}
+	size += jsonBytesSize(m.SSID)
+	size += len(strconv.FormatUint(uint64(m.RoundNumber), 10))
+	size += jsonBytesSize(m.Data)
+	size += len(strconv.FormatBool(m.Broadcast))
+	size += jsonBytesSize(m.BroadcastVerification)
+	if m.Epoch != 0 {
+		size += len(strconv.FormatUint(m.Epoch, 10))
+	}
+	return size, nil
+}
+
+func jsonBytesSize(b []byte) int {
+	if b == nil {
+		return len("null")
+	}
+	return base64.StdEncoding.EncodedLen(len(b)) + 2
+}
+
+// EstimatedStateSize returns an estimate in bytes of the state retained by the handler,
+// given by the CBOR size of all stored messages and the broadcast hashes.
+// The state of the rounds themselves is not included, since it depends on the protocol.
+//
+// The estimate grows with every round unless the handler prunes past rounds, see HandlerOptions.KeepAllRounds.
+func (h *MultiHandler) EstimatedStateSize() int {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	size := 0
+	for _, queue := range []map[round.Number]map[party.ID]*Message{h.messages, h.broadcast} {
+		for _, msgs := range queue {
+			for _, msg := range msgs {
+				if msg != nil {
+					size += msg.cborSize()
+				}
+			}
+		}
+	}
+	for _, hash := range h.broadcastHashes {
+		size += len(hash)
+	}
+	for _, digests := range h.broadcastDigests {
+		for _, digest := range digests {
+			size += len(digest)
+		}
+	}
+	return size
+}
@@ -0,0 +1,81 @@
+package protocol_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/frost"
+)
+
+func TestMessageEncodedSize(t *testing.T) {
+	msgs := []*protocol.Message{
+		{},
+		{
+			SSID:        make([]byte, 32),
+			From:        "a",
+			To:          "<b>\n",
+			Protocol:    "cmp/sign",
+			RoundNumber: 300,
+			Data:        make([]byte, 70000),
+			Broadcast:   true,
+		},
+		{
+			From:                  "é",
+			RoundNumber:           2,
+			Data:                  []byte{},
+			BroadcastVerification: make([]byte, 64),
+		},
+		{
+			SSID:        make([]byte, 64),
+			From:        "a",
+			RoundNumber: 1,
+			Data:        []byte{1},
+			Epoch:       1 << 40,
+		},
+	}
+	for _, msg := range msgs {
+		data, err := msg.MarshalBinary()
+		require.NoError(t, err)
+		size, err := msg.EncodedSize(protocol.CodecCBOR)
+		require.NoError(t, err)
+		assert.Equal(t, len(data), size)
+
+		data, err = json.Marshal(msg)
+		require.NoError(t, err)
+		size, err = msg.EncodedSize(protocol.CodecJSON)
+		require.NoError(t, err)
+		assert.Equal(t, len(data), size)
+	}
+	_, err := msgs[0].EncodedSize(protocol.Codec(10))
+	assert.Error(t, err)
+}
+
+func TestHandlerEstimatedStateSize(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	run := func(opts protocol.HandlerOptions) map[party.ID]int {
+		handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+		for _, id := range partyIDs {
+			h, err := protocol.NewMultiHandlerWithOptions(frost.Keygen(curve.Secp256k1{}, id, partyIDs, 1), nil, opts)
+			require.NoError(t, err)
+			handlers[id] = h
+			assert.Positive(t, h.EstimatedStateSize())
+		}
+		runHandlers(handlers)
+		sizes := make(map[party.ID]int, len(partyIDs))
+		for id, h := range handlers {
+			sizes[id] = h.EstimatedStateSize()
+		}
+		return sizes
+	}
+	pruned := run(protocol.HandlerOptions{})
+	kept := run(protocol.HandlerOptions{KeepAllRounds: true})
+	for _, id := range partyIDs {
+		assert.Greater(t, kept[id], pruned[id])
+	}
+}
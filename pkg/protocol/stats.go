@@ -0,0 +1,165 @@
+package protocol
+
+import (
+	"sort"
+	"time"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
+)
+
+// Stats contains performance measurements of a protocol execution,
+// collected by a MultiHandler created with HandlerOptions.CollectStats.
+type Stats struct {
+	// MessagesIn and BytesIn count the messages accepted by the handler, and the size of their data.
+	MessagesIn, BytesIn int
+	// MessagesOut and BytesOut count the messages emitted by the handler, and the size of their data.
+	MessagesOut, BytesOut int
+	// Rounds contains the measurements of each round, sorted by round number.
+	// A round which has not been finalized yet only counts the messages received for it.
+	Rounds []RoundStats
+	// Peers contains the traffic exchanged with each other party.
+	Peers map[party.ID]PeerStats
+}
+
+// PeerStats counts the messages exchanged with a single party, and the size of their data.
+// A broadcast message emitted by the handler counts as sent to every other party.
+type PeerStats struct {
+	MessagesIn, BytesIn   int
+	MessagesOut, BytesOut int
+}
+
+// RoundStats contains the measurements of a single round.
+type RoundStats struct {
+	Number round.Number
+	// Duration is the wall time between the start of the round and the end of its finalization,
+	// which includes the time spent waiting for messages from other parties.
+	Duration time.Duration
+	// VerifyDuration is the time spent verifying and storing incoming messages,
+	// which is mostly spent verifying zero-knowledge proofs.
+	VerifyDuration time.Duration
+	// FinalizeDuration is the time spent computing the messages for the next round.
+	FinalizeDuration time.Duration
+	// MessagesIn and BytesIn count the messages received for this round,
+	// and MessagesOut and BytesOut the messages emitted when it was finalized, as in Stats.
+	MessagesIn, BytesIn, MessagesOut, BytesOut int
+}
+
+// statsCollector accumulates Stats. All methods are no-ops on a nil receiver,
+// so that the handler does not need to check whether stats are enabled.
+type statsCollector struct {
+	stats      Stats
+	rounds     map[round.Number]*RoundStats
+	peers      map[party.ID]*PeerStats
+	roundStart time.Time
+	clock      platform.Clock
+}
+
+func newStatsCollector(clock platform.Clock) *statsCollector {
+	return &statsCollector{
+		rounds:     map[round.Number]*RoundStats{},
+		peers:      map[party.ID]*PeerStats{},
+		roundStart: clock.Now(),
+		clock:      clock,
+	}
+}
+
+func (s *statsCollector) round(number round.Number) *RoundStats {
+	r, ok := s.rounds[number]
+	if !ok {
+		r = &RoundStats{Number: number}
+		s.rounds[number] = r
+	}
+	return r
+}
+
+func (s *statsCollector) peer(id party.ID) *PeerStats {
+	p, ok := s.peers[id]
+	if !ok {
+		p = &PeerStats{}
+		s.peers[id] = p
+	}
+	return p
+}
+
+func (s *statsCollector) received(msg *Message) {
+	if s == nil {
+		return
+	}
+	size := len(msg.Data)
+	s.stats.MessagesIn++
+	s.stats.BytesIn += size
+	r := s.round(msg.RoundNumber)
+	r.MessagesIn++
+	r.BytesIn += size
+	p := s.peer(msg.From)
+	p.MessagesIn++
+	p.BytesIn += size
+}
+
+// sent records a message emitted by the handler when finalizing the given round,
+// where others are the other parties of the session.
+func (s *statsCollector) sent(msg *Message, number round.Number, others []party.ID) {
+	if s == nil {
+		return
+	}
+	size := len(msg.Data)
+	s.stats.MessagesOut++
+	s.stats.BytesOut += size
+	r := s.round(number)
+	r.MessagesOut++
+	r.BytesOut += size
+	recipients := others
+	if msg.To != "" {
+		recipients = []party.ID{msg.To}
+	}
+	for _, id := range recipients {
+		p := s.peer(id)
+		p.MessagesOut++
+		p.BytesOut += size
+	}
+}
+
+func (s *statsCollector) verified(number round.Number, start time.Time) {
+	if s == nil {
+		return
+	}
+	s.round(number).VerifyDuration += s.clock.Now().Sub(start)
+}
+
+func (s *statsCollector) finalized(number round.Number, start time.Time) {
+	if s == nil {
+		return
+	}
+	now := s.clock.Now()
+	r := s.round(number)
+	r.FinalizeDuration += now.Sub(start)
+	r.Duration = now.Sub(s.roundStart)
+	s.roundStart = now
+}
+
+func (s *statsCollector) snapshot() Stats {
+	stats := s.stats
+	stats.Rounds = make([]RoundStats, 0, len(s.rounds))
+	for _, r := range s.rounds {
+		stats.Rounds = append(stats.Rounds, *r)
+	}
+	sort.Slice(stats.Rounds, func(i, j int) bool { return stats.Rounds[i].Number < stats.Rounds[j].Number })
+	stats.Peers = make(map[party.ID]PeerStats, len(s.peers))
+	for id, p := range s.peers {
+		stats.Peers[id] = *p
+	}
+	return stats
+}
+
+// Stats returns the measurements collected so far, and false if the handler was not created
+// with HandlerOptions.CollectStats.
+func (h *MultiHandler) Stats() (Stats, bool) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if h.stats == nil {
+		return Stats{}, false
+	}
+	return h.stats.snapshot(), true
+}
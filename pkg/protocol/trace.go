@@ -0,0 +1,145 @@
+package protocol
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// TraceEvent describes the kind of TraceRecord.
+type TraceEvent string
+
+const (
+	// TraceIn is recorded for every message accepted by the handler.
+	TraceIn TraceEvent = "in"
+	// TraceOut is recorded for every message emitted by the handler.
+	TraceOut TraceEvent = "out"
+	// TraceBroadcastHash is recorded when the handler has computed the hash of all broadcast messages for a round.
+	TraceBroadcastHash TraceEvent = "broadcast-hash"
+)
+
+// TraceRecord is a single line written to HandlerOptions.TraceWriter.
+//
+// The content of messages is never recorded, only its length and the hash of the full message.
+// This is enough to audit the ordering and origin of all messages, as well as to check the
+// consistency of the echo broadcast, without leaking any secret material.
+type TraceRecord struct {
+	Time     time.Time    `json:"time"`
+	Event    TraceEvent   `json:"event"`
+	Self     party.ID     `json:"self"`
+	Protocol string       `json:"protocol"`
+	SSID     string       `json:"ssid"`
+	Round    round.Number `json:"round"`
+	// From, To, Broadcast, Size, Hash and BroadcastVerification are only set for TraceIn and TraceOut.
+	From                  party.ID `json:"from,omitempty"`
+	To                    party.ID `json:"to,omitempty"`
+	Broadcast             bool     `json:"broadcast,omitempty"`
+	Size                  int      `json:"size,omitempty"`
+	Hash                  string   `json:"hash,omitempty"`
+	BroadcastVerification string   `json:"broadcastVerification,omitempty"`
+	// BroadcastHash is only set for TraceBroadcastHash.
+	BroadcastHash string `json:"broadcastHash,omitempty"`
+}
+
+// traceMessage writes a record for msg, if tracing is enabled.
+func (h *MultiHandler) traceMessage(event TraceEvent, msg *Message) {
+	if h.trace == nil {
+		return
+	}
+	h.writeTrace(&TraceRecord{
+		Event:                 event,
+		Protocol:              msg.Protocol,
+		SSID:                  hex.EncodeToString(msg.SSID),
+		Round:                 msg.RoundNumber,
+		From:                  msg.From,
+		To:                    msg.To,
+		Broadcast:             msg.Broadcast,
+		Size:                  len(msg.Data),
+		Hash:                  hex.EncodeToString(msg.Hash()),
+		BroadcastVerification: hex.EncodeToString(msg.BroadcastVerification),
+	})
+}
+
+// traceBroadcastHash writes a record for the broadcast hash of the given round, if tracing is enabled.
+func (h *MultiHandler) traceBroadcastHash(number round.Number, broadcastHash []byte) {
+	if h.trace == nil {
+		return
+	}
+	h.writeTrace(&TraceRecord{
+		Event:         TraceBroadcastHash,
+		Protocol:      h.currentRound.ProtocolID(),
+		SSID:          hex.EncodeToString(h.currentRound.SSID()),
+		Round:         number,
+		BroadcastHash: hex.EncodeToString(broadcastHash),
+	})
+}
+
+// writeTrace completes the record with the common fields and writes it as a single line.
+// Errors from the writer are ignored, since tracing must not interfere with the protocol execution.
+func (h *MultiHandler) writeTrace(record *TraceRecord) {
+	record.Time = h.clock.Now().UTC()
+	record.Self = h.currentRound.SelfID()
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	_, _ = h.trace.Write(append(data, '\n'))
+}
+
+// VerifyTrace reads the records written to a HandlerOptions.TraceWriter and checks that the broadcast hash chain is consistent.
+// That is, every message sent or received in the round following a broadcast round must include the broadcast hash
+// computed by the handler for that round.
+// The records of a single handler execution are expected.
+func VerifyTrace(r io.Reader) error {
+	broadcastHashes := map[round.Number]string{}
+	var messages []*TraceRecord
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var record TraceRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("trace: line %d: %w", line, err)
+		}
+		switch record.Event {
+		case TraceBroadcastHash:
+			if previous, ok := broadcastHashes[record.Round]; ok && previous != record.BroadcastHash {
+				return fmt.Errorf("trace: line %d: round %d: conflicting broadcast hashes", line, record.Round)
+			}
+			broadcastHashes[record.Round] = record.BroadcastHash
+		case TraceIn, TraceOut:
+			messages = append(messages, &record)
+		default:
+			return fmt.Errorf("trace: line %d: unknown event %q", line, record.Event)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("trace: %w", err)
+	}
+
+	for _, msg := range messages {
+		// aborts are sent with round 0, and do not include a verification hash
+		previous, ok := msg.Round.Prev()
+		if !ok {
+			continue
+		}
+		expected, ok := broadcastHashes[previous]
+		if !ok {
+			continue
+		}
+		if msg.BroadcastVerification != expected {
+			return fmt.Errorf("trace: round %d: message %s from %s has invalid broadcast verification", msg.Round, msg.Hash, msg.From)
+		}
+	}
+	return nil
+}
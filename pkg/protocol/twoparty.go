@@ -1,7 +1,6 @@
 package protocol
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"sync"
@@ -22,6 +21,9 @@ type TwoPartyHandler struct {
 }
 
 func NewTwoPartyHandler(create StartFunc, sessionID []byte, leader bool) (*TwoPartyHandler, error) {
+	if err := ValidateSessionID(sessionID); err != nil {
+		return nil, err
+	}
 	r, err := create(sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("protocol: failed to create round: %w", err)
@@ -78,6 +80,7 @@ func (h *TwoPartyHandler) abort(err error) {
 			From:     h.round.SelfID(),
 			Protocol: h.round.ProtocolID(),
 			Data:     []byte(h.err.Error()),
+			Epoch:    h.round.Epoch(),
 		}:
 		default:
 		}
@@ -97,7 +100,7 @@ func (h *TwoPartyHandler) canAdvance() bool {
 
 func extractRoundMessage(r round.Session, msg *Message) (round.Message, error) {
 	content := r.MessageContent()
-	if err := cbor.Unmarshal(msg.Data, content); err != nil {
+	if err := round.UnmarshalContent(msg.Data, content); err != nil {
 		return round.Message{}, fmt.Errorf("failed to unmarshal message: %w", err)
 	}
 	roundMsg := round.Message{
@@ -158,6 +161,7 @@ func (h *TwoPartyHandler) advance() {
 				Data:                  data,
 				Broadcast:             roundMsg.Broadcast,
 				BroadcastVerification: nil,
+				Epoch:                 newRound.Epoch(),
 			}
 			h.out <- msg
 		}
@@ -178,29 +182,7 @@ func (h *TwoPartyHandler) advance() {
 }
 
 func (h *TwoPartyHandler) CanAccept(msg *Message) bool {
-	r := h.round
-	if msg == nil {
-		return false
-	}
-	if !msg.IsFor(r.SelfID()) {
-		return false
-	}
-	if msg.Protocol != r.ProtocolID() {
-		return false
-	}
-	if !bytes.Equal(msg.SSID, r.SSID()) {
-		return false
-	}
-	if !r.PartyIDs().Contains(msg.From) {
-		return false
-	}
-	if msg.Data == nil {
-		return false
-	}
-	if msg.RoundNumber > r.FinalRoundNumber() {
-		return false
-	}
-	return true
+	return rejectReason(h.round, msg) == 0
 }
 
 func (h *TwoPartyHandler) Accept(msg *Message) {
@@ -0,0 +1,273 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// Anomaly is an inconsistency observed by a VerifierHandler.
+type Anomaly struct {
+	// RoundNumber is the round of the message which revealed the anomaly.
+	RoundNumber round.Number
+	// Culprits is empty if the misbehaving party cannot be identified.
+	Culprits []party.ID
+	// Err describes the anomaly.
+	Err error
+}
+
+// Error implements error.
+func (a Anomaly) Error() string {
+	if a.Culprits == nil {
+		return fmt.Sprintf("round %d: %s", a.RoundNumber, a.Err)
+	}
+	return fmt.Sprintf("round %d: culprits: %v: %s", a.RoundNumber, a.Culprits, a.Err)
+}
+
+// Unwrap implement errors.Wrapper.
+func (a Anomaly) Unwrap() error {
+	return a.Err
+}
+
+// VerifierHandler is a watch-only participant, which observes the messages of a protocol execution
+// without holding any secret or sending any message.
+//
+// It checks that
+//   - all messages belong to the same session,
+//   - no party sends two different broadcast messages in the same round,
+//   - all parties include the same echo broadcast hash of the previous round in their messages,
+//   - each message passes the optional validation function, which can verify zero-knowledge proofs
+//     using only public data.
+//
+// The broadcast hash is computed over the hash state of the session, which protocols update with values
+// derived from the contents of earlier rounds, so a VerifierHandler cannot recompute it from the messages
+// it observes. It therefore only detects parties which disagree with each other: if all parties include the
+// same hash, it is not checked against the broadcast messages. ExpectBroadcastHash provides the hash computed
+// by a trusted participant, against which the messages are then checked.
+//
+// Any violation is recorded as an Anomaly, and the observation continues.
+type VerifierHandler struct {
+	protocolID string
+	ssid       []byte
+	partyIDs   party.IDSlice
+	validate   func(*Message) error
+
+	// broadcast[r][j] is the broadcast message sent by j in round r.
+	broadcast map[round.Number]map[party.ID]*Message
+	// verification[r][j] is the broadcast hash included by j in its messages of round r.
+	verification map[round.Number]map[party.ID][]byte
+	// expected[r] is the broadcast hash of round r given to ExpectBroadcastHash.
+	expected map[round.Number][]byte
+	// seen contains the hashes of all accepted messages, since the same message may be relayed more than once.
+	seen      map[string]bool
+	anomalies []Anomaly
+	mtx       sync.Mutex
+}
+
+// NewVerifierHandler returns a VerifierHandler observing an execution of the protocol protocolID between partyIDs.
+// If ssid is nil, it is taken from the first accepted message.
+// validate is optional, and is called for every message which passes the consistency checks.
+func NewVerifierHandler(protocolID string, ssid []byte, partyIDs []party.ID, validate func(*Message) error) (*VerifierHandler, error) {
+	ids := party.NewIDSlice(partyIDs)
+	if len(ids) == 0 || !ids.Valid() {
+		return nil, errors.New("verifier: partyIDs invalid")
+	}
+	return &VerifierHandler{
+		protocolID:   protocolID,
+		ssid:         bytes.Clone(ssid),
+		partyIDs:     ids,
+		validate:     validate,
+		broadcast:    make(map[round.Number]map[party.ID]*Message),
+		verification: make(map[round.Number]map[party.ID][]byte),
+		expected:     make(map[round.Number][]byte),
+		seen:         make(map[string]bool),
+	}, nil
+}
+
+// Accept observes msg, and records any anomaly it reveals.
+func (v *VerifierHandler) Accept(msg *Message) {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+
+	if msg == nil {
+		return
+	}
+	if !v.partyIDs.Contains(msg.From) {
+		v.report(msg.RoundNumber, fmt.Errorf("message from unknown party %q", msg.From))
+		return
+	}
+	if msg.Protocol != v.protocolID {
+		v.report(msg.RoundNumber, fmt.Errorf("unexpected protocol %q", msg.Protocol), msg.From)
+		return
+	}
+	if v.ssid == nil {
+		v.ssid = bytes.Clone(msg.SSID)
+	} else if !bytes.Equal(v.ssid, msg.SSID) {
+		v.report(msg.RoundNumber, errors.New("message belongs to a different session"), msg.From)
+		return
+	}
+
+	// a sender using HandlerOptions.UnicastBroadcast emits one copy of each broadcast message per recipient,
+	// which only differ in their recipient, so broadcasts are compared without it, as in MultiHandler.store.
+	if msg.Broadcast && msg.To != "" {
+		canonical := *msg
+		canonical.To = ""
+		msg = &canonical
+	}
+
+	msgHash := string(msg.Hash())
+	if v.seen[msgHash] {
+		return
+	}
+	v.seen[msgHash] = true
+
+	if msg.Broadcast {
+		if v.broadcast[msg.RoundNumber] == nil {
+			v.broadcast[msg.RoundNumber] = make(map[party.ID]*Message, len(v.partyIDs))
+		}
+		if previous, ok := v.broadcast[msg.RoundNumber][msg.From]; ok {
+			if !bytes.Equal(previous.Data, msg.Data) || !bytes.Equal(previous.BroadcastVerification, msg.BroadcastVerification) {
+				v.report(msg.RoundNumber, errors.New("equivocating broadcast message"), msg.From)
+			}
+			return
+		}
+		v.broadcast[msg.RoundNumber][msg.From] = msg
+	}
+
+	if !v.checkVerification(msg) {
+		return
+	}
+
+	if v.validate != nil {
+		if err := v.validate(msg); err != nil {
+			v.report(msg.RoundNumber, err, msg.From)
+		}
+	}
+}
+
+// checkVerification records the BroadcastVerification of msg, and checks that it is consistent
+// with previous messages from the same sender, and with the expected hash or else with other parties.
+func (v *VerifierHandler) checkVerification(msg *Message) bool {
+	number := msg.RoundNumber
+	if v.verification[number] == nil {
+		v.verification[number] = make(map[party.ID][]byte, len(v.partyIDs))
+	}
+	seen := v.verification[number]
+	if previous, ok := seen[msg.From]; ok {
+		if !bytes.Equal(previous, msg.BroadcastVerification) {
+			v.report(number, errors.New("inconsistent broadcast verification across messages"), msg.From)
+			return false
+		}
+		return true
+	}
+	seen[msg.From] = bytes.Clone(msg.BroadcastVerification)
+	if expected := v.expectedVerification(number); expected != nil {
+		if !bytes.Equal(expected, msg.BroadcastVerification) {
+			v.report(number, errExpectedBroadcastHash, msg.From)
+		}
+		return true
+	}
+	for id, other := range seen {
+		if id != msg.From && !bytes.Equal(other, msg.BroadcastVerification) {
+			v.report(number, errors.New("parties disagree on the broadcast of the previous round"), id, msg.From)
+			break
+		}
+	}
+	return true
+}
+
+var errExpectedBroadcastHash = errors.New("broadcast verification differs from the expected broadcast hash")
+
+// expectedVerification returns the BroadcastVerification expected in the messages of round number,
+// or nil if the hash of the previous round was not given to ExpectBroadcastHash.
+func (v *VerifierHandler) expectedVerification(number round.Number) []byte {
+	previous, ok := number.Prev()
+	if !ok {
+		return nil
+	}
+	return v.expected[previous]
+}
+
+// ExpectBroadcastHash sets the hash of the broadcast messages of the given round, as computed by a participant
+// whose view is trusted, for instance with MultiHandler.BroadcastHash. Every party whose messages of the following
+// round include a different BroadcastVerification is then reported, including for messages observed earlier.
+// Only the first hash given for a round is used, and an empty broadcastHash is ignored,
+// since MultiHandler.BroadcastHash returns nil until the hash is known.
+func (v *VerifierHandler) ExpectBroadcastHash(number round.Number, broadcastHash []byte) {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	if len(broadcastHash) == 0 {
+		return
+	}
+	if _, ok := v.expected[number]; ok {
+		return
+	}
+	v.expected[number] = bytes.Clone(broadcastHash)
+	for next, seen := range v.verification {
+		if previous, ok := next.Prev(); !ok || previous != number {
+			continue
+		}
+		for _, id := range v.partyIDs {
+			if verification, ok := seen[id]; ok && !bytes.Equal(verification, broadcastHash) {
+				v.report(next, errExpectedBroadcastHash, id)
+			}
+		}
+	}
+}
+
+func (v *VerifierHandler) report(number round.Number, err error, culprits ...party.ID) {
+	v.anomalies = append(v.anomalies, Anomaly{
+		RoundNumber: number,
+		Culprits:    culprits,
+		Err:         err,
+	})
+}
+
+// Anomalies returns all anomalies observed so far, in the order they were detected.
+func (v *VerifierHandler) Anomalies() []Anomaly {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	return append([]Anomaly(nil), v.anomalies...)
+}
+
+// Missing returns the parties from which no broadcast message was observed in the given round.
+// This is only meaningful for rounds which expect a broadcast message.
+func (v *VerifierHandler) Missing(number round.Number) []party.ID {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	var missing []party.ID
+	for _, id := range v.partyIDs {
+		if _, ok := v.broadcast[number][id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+// Err returns an error summarizing the observed anomalies, or nil if there were none.
+func (v *VerifierHandler) Err() error {
+	anomalies := v.Anomalies()
+	if len(anomalies) == 0 {
+		return nil
+	}
+	culprits := make(map[party.ID]bool)
+	for _, a := range anomalies {
+		for _, id := range a.Culprits {
+			culprits[id] = true
+		}
+	}
+	var ids []party.ID
+	for _, id := range v.partyIDs {
+		if culprits[id] {
+			ids = append(ids, id)
+		}
+	}
+	return Error{
+		Culprits: ids,
+		Err:      fmt.Errorf("verifier: %d anomalies, first: %w", len(anomalies), anomalies[0]),
+	}
+}
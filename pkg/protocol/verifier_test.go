@@ -0,0 +1,208 @@
+package protocol_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/frost"
+)
+
+// observeKeygen runs a FROST keygen between 3 parties, and returns their handlers and all messages exchanged.
+func observeKeygen(t *testing.T, opts protocol.HandlerOptions) (party.IDSlice, map[party.ID]*protocol.MultiHandler, []*protocol.Message) {
+	partyIDs := test.PartyIDs(3)
+	handlers := make(map[party.ID]*protocol.MultiHandler, len(partyIDs))
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandlerWithOptions(frost.Keygen(curve.Secp256k1{}, id, partyIDs, 1), nil, opts)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	var observed []*protocol.Message
+	for {
+		delivered := false
+		for _, h := range handlers {
+			for _, msg := range drain(h) {
+				observed = append(observed, msg)
+				for id, other := range handlers {
+					if msg.IsFor(id) {
+						other.Accept(msg)
+						delivered = true
+					}
+				}
+			}
+		}
+		if !delivered {
+			break
+		}
+	}
+	for _, h := range handlers {
+		_, err := h.Result()
+		require.NoError(t, err)
+	}
+	return partyIDs, handlers, observed
+}
+
+func TestVerifierHandler(t *testing.T) {
+	partyIDs, _, observed := observeKeygen(t, protocol.HandlerOptions{})
+
+	validated := 0
+	v, err := protocol.NewVerifierHandler("frost/keygen-threshold", nil, partyIDs, func(*protocol.Message) error {
+		validated++
+		return nil
+	})
+	require.NoError(t, err)
+	for _, msg := range observed {
+		v.Accept(msg)
+		// relayed copies must not be reported
+		v.Accept(msg)
+	}
+	assert.Empty(t, v.Anomalies())
+	assert.NoError(t, v.Err())
+	assert.Equal(t, len(observed), validated)
+
+	var broadcast *protocol.Message
+	for _, msg := range observed {
+		if msg.Broadcast {
+			broadcast = msg
+			break
+		}
+	}
+	require.NotNil(t, broadcast)
+	assert.Empty(t, v.Missing(broadcast.RoundNumber))
+
+	// a party sending two different broadcasts in the same round
+	equivocation := *broadcast
+	equivocation.Data = append(bytes.Clone(broadcast.Data), 0)
+	v.Accept(&equivocation)
+	anomalies := v.Anomalies()
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, []party.ID{broadcast.From}, anomalies[0].Culprits)
+
+	// a party reporting a different view of the broadcast
+	v, err = protocol.NewVerifierHandler("frost/keygen-threshold", nil, partyIDs, nil)
+	require.NoError(t, err)
+	tampered := false
+	for _, msg := range observed {
+		if !tampered && msg.BroadcastVerification != nil && msg.From == partyIDs[2] {
+			forged := *msg
+			forged.BroadcastVerification = append([]byte{0}, msg.BroadcastVerification[1:]...)
+			msg = &forged
+			tampered = true
+		}
+		v.Accept(msg)
+	}
+	require.True(t, tampered)
+	require.Error(t, v.Err())
+	var perr protocol.Error
+	require.ErrorAs(t, v.Err(), &perr)
+	assert.Contains(t, perr.Culprits, partyIDs[2])
+
+	// messages from another session or protocol
+	v, err = protocol.NewVerifierHandler("frost/keygen-threshold", []byte("other session"), partyIDs, nil)
+	require.NoError(t, err)
+	v.Accept(observed[0])
+	assert.Len(t, v.Anomalies(), 1)
+
+	_, err = protocol.NewVerifierHandler("frost/keygen-threshold", nil, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestVerifierHandlerUnicastBroadcast(t *testing.T) {
+	partyIDs, _, observed := observeKeygen(t, protocol.HandlerOptions{UnicastBroadcast: true})
+
+	validated := 0
+	v, err := protocol.NewVerifierHandler("frost/keygen-threshold", nil, partyIDs, func(*protocol.Message) error {
+		validated++
+		return nil
+	})
+	require.NoError(t, err)
+	copies := 0
+	for _, msg := range observed {
+		if msg.Broadcast {
+			require.NotEmpty(t, msg.To)
+			copies++
+		}
+		v.Accept(msg)
+	}
+	// each broadcast is sent to the 2 other parties, and both copies are the same message
+	assert.Empty(t, v.Anomalies())
+	assert.Equal(t, len(observed)-copies/2, validated)
+
+	// a copy with different content is still an equivocation
+	for _, msg := range observed {
+		if msg.Broadcast {
+			equivocation := *msg
+			equivocation.Data = append(bytes.Clone(msg.Data), 0)
+			v.Accept(&equivocation)
+			break
+		}
+	}
+	assert.Len(t, v.Anomalies(), 1)
+}
+
+func TestVerifierHandlerExpectBroadcastHash(t *testing.T) {
+	partyIDs, handlers, observed := observeKeygen(t, protocol.HandlerOptions{})
+
+	// all parties include the same forged hash in their messages of one round
+	var number round.Number
+	for _, msg := range observed {
+		if msg.BroadcastVerification != nil {
+			number = msg.RoundNumber
+			break
+		}
+	}
+	require.NotZero(t, number)
+	previous, ok := number.Prev()
+	require.True(t, ok)
+	expected := handlers[partyIDs[0]].BroadcastHash(previous)
+	require.NotNil(t, expected)
+	forged := make([]*protocol.Message, 0, len(observed))
+	for _, msg := range observed {
+		if msg.RoundNumber == number {
+			copied := *msg
+			copied.BroadcastVerification = append([]byte{0}, msg.BroadcastVerification[1:]...)
+			msg = &copied
+		}
+		forged = append(forged, msg)
+	}
+
+	// which cannot be detected without the expected hash
+	v, err := protocol.NewVerifierHandler("frost/keygen-threshold", nil, partyIDs, nil)
+	require.NoError(t, err)
+	for _, msg := range forged {
+		v.Accept(msg)
+	}
+	assert.Empty(t, v.Anomalies())
+
+	// messages observed earlier are checked once the hash is given
+	v.ExpectBroadcastHash(previous, nil)
+	assert.Empty(t, v.Anomalies())
+	v.ExpectBroadcastHash(previous, expected)
+	var perr protocol.Error
+	require.ErrorAs(t, v.Err(), &perr)
+	assert.ElementsMatch(t, partyIDs, perr.Culprits)
+
+	// as are those observed afterwards
+	v, err = protocol.NewVerifierHandler("frost/keygen-threshold", nil, partyIDs, nil)
+	require.NoError(t, err)
+	v.ExpectBroadcastHash(previous, expected)
+	for _, msg := range observed {
+		v.Accept(msg)
+	}
+	assert.Empty(t, v.Anomalies())
+	for _, msg := range forged {
+		if msg.RoundNumber == number && msg.From == partyIDs[1] {
+			v.Accept(msg)
+		}
+	}
+	require.NotEmpty(t, v.Anomalies())
+	for _, a := range v.Anomalies() {
+		assert.Equal(t, []party.ID{partyIDs[1]}, a.Culprits)
+	}
+}
@@ -0,0 +1,115 @@
+package round_test
+
+import (
+	"fmt"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/pkg/round"
+)
+
+// sumRound1 broadcasts the party's value.
+type sumRound1 struct {
+	*round.Helper
+	value int
+}
+
+func (sumRound1) VerifyMessage(round.Message) error { return nil }
+func (sumRound1) StoreMessage(round.Message) error  { return nil }
+func (r *sumRound1) Finalize(out chan<- *round.Message) (round.Session, error) {
+	if err := r.BroadcastMessage(out, &broadcast2{Value: r.value}); err != nil {
+		return r, err
+	}
+	return &sumRound2{sumRound1: r, values: map[party.ID]int{r.SelfID(): r.value}}, nil
+}
+func (sumRound1) MessageContent() round.Content { return nil }
+func (sumRound1) Number() round.Number          { return 1 }
+
+// sumRound2 receives the values of all parties and outputs their sum.
+type sumRound2 struct {
+	*sumRound1
+	values map[party.ID]int
+}
+
+type broadcast2 struct {
+	round.NormalBroadcastContent
+	Value int
+}
+
+func (broadcast2) RoundNumber() round.Number { return 2 }
+
+func (r *sumRound2) StoreBroadcastMessage(msg round.Message) error {
+	body, ok := msg.Content.(*broadcast2)
+	if !ok || body == nil {
+		return fmt.Errorf("invalid content")
+	}
+	r.values[msg.From] = body.Value
+	return nil
+}
+func (r *sumRound2) Finalize(chan<- *round.Message) (round.Session, error) {
+	sum := 0
+	for _, v := range r.values {
+		sum += v
+	}
+	return r.ResultRound(sum), nil
+}
+func (sumRound2) MessageContent() round.Content            { return nil }
+func (sumRound2) BroadcastContent() round.BroadcastContent { return &broadcast2{} }
+func (sumRound2) Number() round.Number                     { return 2 }
+
+func startSum(selfID party.ID, partyIDs []party.ID, value int) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		helper, err := round.NewSession(round.Info{
+			ProtocolID:       "example/sum",
+			FinalRoundNumber: 2,
+			SelfID:           selfID,
+			PartyIDs:         partyIDs,
+			Threshold:        1,
+		}, sessionID, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &sumRound1{Helper: helper, value: value}, nil
+	}
+}
+
+func Example() {
+	partyIDs := []party.ID{"a", "b", "c"}
+	handlers := make(map[party.ID]*protocol.MultiHandler)
+	for i, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(startSum(id, partyIDs, i+1), nil)
+		if err != nil {
+			panic(err)
+		}
+		handlers[id] = h
+	}
+
+	// deliver messages until all handlers are done
+	for done := false; !done; {
+		done = true
+		for _, h := range handlers {
+			select {
+			case msg, ok := <-h.Listen():
+				if !ok {
+					continue
+				}
+				done = false
+				for id, other := range handlers {
+					if msg.IsFor(id) {
+						other.Accept(msg)
+					}
+				}
+			default:
+			}
+		}
+	}
+
+	for _, id := range partyIDs {
+		result, err := handlers[id].Result()
+		fmt.Println(id, result, err)
+	}
+	// Output:
+	// a 6 <nil>
+	// b 6 <nil>
+	// c 6 <nil>
+}
@@ -0,0 +1,75 @@
+// Package round exposes the interfaces implemented by the rounds of the protocols in this module,
+// so that other modules can implement their own round-based protocols and run them with protocol.MultiHandler.
+//
+// A protocol is a sequence of rounds. The first round embeds the *Helper returned by NewSession,
+// and is returned by the protocol.StartFunc given to the handler:
+//
+//   - the handler calls Finalize on the first round straight away, which sends the messages for round 2
+//     and returns round 2;
+//   - for each message received, the handler unmarshals msg.Data into the content returned by
+//     MessageContent or BroadcastContent of the current round, then calls StoreBroadcastMessage,
+//     or VerifyMessage and StoreMessage;
+//   - once all messages of a round have been stored, Finalize is called to obtain the next round.
+//     The last round returns the result of the protocol with Helper.ResultRound,
+//     and a misbehaving party is reported with Helper.AbortRound.
+//
+// Contents are encoded with CBOR, and need no registration: the handler decodes each message into
+// the value returned by MessageContent or BroadcastContent, which must therefore be a pointer
+// whose fields are initialized for decoding, for example with curve.Curve.NewPoint for points.
+// The RoundNumber of a content is the number of the round which receives it.
+//
+// The types of this package are aliases of the ones used by the protocols of this module,
+// which can therefore be composed with external rounds.
+package round
+
+import (
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+)
+
+type (
+	// Session is the current round of a protocol execution, along with the information about the session.
+	Session = round.Session
+	// Round is the interface implemented by every round.
+	Round = round.Round
+	// BroadcastRound is implemented by rounds which also expect a broadcast message from every party.
+	BroadcastRound = round.BroadcastRound
+	// DependentRound is implemented by rounds which can be finalized once the messages of some parties were stored.
+	DependentRound = round.DependentRound
+	// Info contains the parameters of a session, which must be the same for all parties.
+	Info = round.Info
+	// Helper implements Session without Round, and is embedded in the first round of a protocol.
+	Helper = round.Helper
+	// Number is the number of a round, starting at 1.
+	Number = round.Number
+	// Message is a message received or sent by a round.
+	Message = round.Message
+	// Content is the content of a P2P message.
+	Content = round.Content
+	// BroadcastContent is the content of a broadcast message.
+	BroadcastContent = round.BroadcastContent
+	// ReliableBroadcastContent is embedded in a BroadcastContent which requires reliable broadcast.
+	ReliableBroadcastContent = round.ReliableBroadcastContent
+	// NormalBroadcastContent is embedded in a BroadcastContent for which echo broadcast is sufficient.
+	NormalBroadcastContent = round.NormalBroadcastContent
+	// Output is the final round of a successful execution, returned by Helper.ResultRound.
+	Output = round.Output
+	// Abort is the final round of a failed execution, returned by Helper.AbortRound.
+	Abort = round.Abort
+)
+
+// NewSession creates a new *Helper, to be embedded in the first round of a protocol.
+// sessionID is the optional identifier given to the protocol.StartFunc,
+// and auxInfo contains additional public data to which the session is bound.
+func NewSession(info Info, sessionID []byte, pl *pool.Pool, auxInfo ...hash.WriterToWithDomain) (*Helper, error) {
+	return round.NewSession(info, sessionID, pl, auxInfo...)
+}
+
+// MaxRounds is the largest number of rounds of a protocol.
+const MaxRounds = round.MaxRounds
+
+// NewNumber returns n as a Number, or an error if it is out of the range [0, MaxRounds].
+func NewNumber(n int) (Number, error) {
+	return round.NewNumber(n)
+}
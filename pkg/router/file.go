@@ -0,0 +1,201 @@
+package router
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+)
+
+// FileStore is a Store which records all operations in an append-only journal,
+// so that the state of the Router survives restarts.
+// Every operation is synced to disk before returning.
+type FileStore struct {
+	memory *MemoryStore
+	path   string
+	file   *os.File
+}
+
+type journalOp string
+
+const (
+	opAdd       journalOp = "add"
+	opDelivered journalOp = "delivered"
+	opPending   journalOp = "pending"
+	opRemove    journalOp = "remove"
+)
+
+type journalRecord struct {
+	Op   journalOp `json:"op"`
+	Key  string    `json:"key"`
+	Data []byte    `json:"data,omitempty"`
+}
+
+// OpenFileStore opens the journal at path, creating it if necessary, and restores the stored messages.
+// The journal is compacted when opened, so that it only contains the current state.
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{memory: NewMemoryStore(), path: path}
+	if err := s.replay(); err != nil {
+		return nil, err
+	}
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileStore) replay() error {
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("router: %w", err)
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// every record is written with its newline at once, so a last record without one is partially written,
+			// and its operation did not complete
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("router: %w", err)
+		}
+		var record journalRecord
+		if err = json.Unmarshal(line, &record); err != nil {
+			return fmt.Errorf("router: journal: %w", err)
+		}
+		switch record.Op {
+		case opAdd:
+			msg := new(protocol.Message)
+			if err = msg.UnmarshalBinary(record.Data); err != nil {
+				return fmt.Errorf("router: journal: %w", err)
+			}
+			_, _ = s.memory.Add(record.Key, msg)
+		case opDelivered, opPending:
+			_ = s.memory.SetDelivered(record.Key, record.Op == opDelivered)
+		case opRemove:
+			_ = s.memory.Remove(record.Key)
+		default:
+			return fmt.Errorf("router: journal: unknown operation %q", record.Op)
+		}
+	}
+}
+
+// compact rewrites the journal with only the current state, and opens it for appending.
+func (s *FileStore) compact() error {
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("router: %w", err)
+	}
+	entries, _ := s.memory.Entries()
+	w := bufio.NewWriter(f)
+	for _, e := range entries {
+		if err = writeRecord(w, opAdd, e.Key, e.Message); err != nil {
+			f.Close()
+			return err
+		}
+		if e.Delivered {
+			if err = writeRecord(w, opDelivered, e.Key, nil); err != nil {
+				f.Close()
+				return err
+			}
+		}
+	}
+	if err = w.Flush(); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("router: %w", err)
+	}
+	if err = os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("router: %w", err)
+	}
+	s.file, err = os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("router: %w", err)
+	}
+	return nil
+}
+
+func writeRecord(w interface{ Write([]byte) (int, error) }, op journalOp, key string, msg *protocol.Message) error {
+	record := journalRecord{Op: op, Key: key}
+	if msg != nil {
+		data, err := msg.MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("router: %w", err)
+		}
+		record.Data = data
+	}
+	line, err := json.Marshal(&record)
+	if err != nil {
+		return fmt.Errorf("router: %w", err)
+	}
+	if _, err = w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("router: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) append(op journalOp, key string, msg *protocol.Message) error {
+	if err := writeRecord(s.file, op, key, msg); err != nil {
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("router: %w", err)
+	}
+	return nil
+}
+
+// Add implements Store.
+func (s *FileStore) Add(key string, msg *protocol.Message) (bool, error) {
+	if ok, _ := s.memory.Add(key, msg); !ok {
+		return false, nil
+	}
+	if err := s.append(opAdd, key, msg); err != nil {
+		_ = s.memory.Remove(key)
+		return false, err
+	}
+	return true, nil
+}
+
+// SetDelivered implements Store.
+func (s *FileStore) SetDelivered(key string, delivered bool) error {
+	op := opPending
+	if delivered {
+		op = opDelivered
+	}
+	if err := s.append(op, key, nil); err != nil {
+		return err
+	}
+	return s.memory.SetDelivered(key, delivered)
+}
+
+// Remove implements Store.
+func (s *FileStore) Remove(key string) error {
+	if err := s.append(opRemove, key, nil); err != nil {
+		return err
+	}
+	return s.memory.Remove(key)
+}
+
+// Entries implements Store.
+func (s *FileStore) Entries() ([]Entry, error) {
+	return s.memory.Entries()
+}
+
+// Close closes the journal.
+func (s *FileStore) Close() error {
+	return s.file.Close()
+}
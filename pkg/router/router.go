@@ -0,0 +1,146 @@
+// Package router delivers protocol messages to handlers exactly once, even across restarts.
+//
+// Every message received from the network is first recorded with Route, which ignores messages
+// that were already routed. Deliver then passes each pending message to a handler, and records that
+// it was delivered. Both operations are persisted by the Store, so that after a restart, messages which
+// were received but not yet delivered are delivered, and messages which were delivered are not
+// delivered a second time.
+//
+// If a handler is restored from a snapshot taken before some messages were delivered,
+// Rewind must be called so that these messages are delivered again.
+package router
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"sync"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+)
+
+// Router deduplicates and delivers messages, persisting its state in a Store.
+type Router struct {
+	store Store
+	mtx   sync.Mutex
+}
+
+// New returns a Router backed by store.
+func New(store Store) *Router {
+	return &Router{store: store}
+}
+
+// Key returns the identifier under which msg is deduplicated.
+// It is derived from the SSID, sender, recipient, round number and whether the message is a broadcast,
+// so that a party can send at most one message of each kind per round.
+func Key(msg *protocol.Message) string {
+	roundNumber := make([]byte, 2)
+	binary.BigEndian.PutUint16(roundNumber, uint16(msg.RoundNumber))
+	broadcast := []byte{0}
+	if msg.Broadcast {
+		broadcast[0] = 1
+	}
+	h := hash.New(
+		&hash.BytesWithDomain{TheDomain: "SSID", Bytes: msg.SSID},
+		&hash.BytesWithDomain{TheDomain: "From", Bytes: []byte(msg.From)},
+		&hash.BytesWithDomain{TheDomain: "To", Bytes: []byte(msg.To)},
+		&hash.BytesWithDomain{TheDomain: "Round Number", Bytes: roundNumber},
+		&hash.BytesWithDomain{TheDomain: "Broadcast", Bytes: broadcast},
+	)
+	return hex.EncodeToString(h.Sum()[:32])
+}
+
+// Route records msg so that it is delivered by a subsequent call to Deliver.
+// It returns false if a message with the same Key was already routed.
+func (r *Router) Route(msg *protocol.Message) (bool, error) {
+	if msg == nil {
+		return false, errors.New("router: nil message")
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.store.Add(Key(msg), msg)
+}
+
+// Deliver passes all pending messages which h can accept to h, in the order they were routed,
+// and returns the number of delivered messages.
+// Messages which h cannot accept remain pending, since they may be intended for another handler.
+func (r *Router) Deliver(h protocol.Handler) (int, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	entries, err := r.store.Entries()
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for _, e := range entries {
+		if e.Delivered || !h.CanAccept(e.Message) {
+			continue
+		}
+		h.Accept(e.Message)
+		// the mark is persisted once h has accepted the message, so that a crash in between cannot lose it.
+		// A message accepted twice by the same handler is ignored as a duplicate.
+		if err = r.store.SetDelivered(e.Key, true); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// Rewind marks all messages of the session ssid from round number onwards as pending again.
+// This must be called after restoring a handler from a snapshot taken in that round.
+func (r *Router) Rewind(ssid []byte, number round.Number) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	entries, err := r.store.Entries()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.Delivered && bytes.Equal(e.Message.SSID, ssid) && e.Message.RoundNumber >= number {
+			if err = r.store.SetDelivered(e.Key, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Forget removes all messages of the session ssid, once the protocol has completed.
+// Messages of this session which are routed afterwards are treated as new.
+func (r *Router) Forget(ssid []byte) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	entries, err := r.store.Entries()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if bytes.Equal(e.Message.SSID, ssid) {
+			if err = r.store.Remove(e.Key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Pending returns the number of messages which were routed but not yet delivered.
+func (r *Router) Pending() (int, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	entries, err := r.store.Entries()
+	if err != nil {
+		return 0, err
+	}
+	pending := 0
+	for _, e := range entries {
+		if !e.Delivered {
+			pending++
+		}
+	}
+	return pending, nil
+}
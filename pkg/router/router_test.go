@@ -0,0 +1,167 @@
+package router_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/pkg/router"
+)
+
+// recorder is a protocol.Handler which accepts all messages of a session.
+type recorder struct {
+	ssid     []byte
+	accepted []*protocol.Message
+}
+
+func (r *recorder) Result() (interface{}, error)     { return nil, nil }
+func (r *recorder) Listen() <-chan *protocol.Message { return nil }
+func (r *recorder) Stop()                            {}
+func (r *recorder) CanAccept(msg *protocol.Message) bool {
+	return bytes.Equal(msg.SSID, r.ssid)
+}
+func (r *recorder) Accept(msg *protocol.Message) { r.accepted = append(r.accepted, msg) }
+
+func message(ssid string, from party.ID, number round.Number, broadcast bool) *protocol.Message {
+	return &protocol.Message{
+		SSID:        []byte(ssid),
+		From:        from,
+		Protocol:    "test",
+		RoundNumber: number,
+		Data:        []byte{byte(number)},
+		Broadcast:   broadcast,
+	}
+}
+
+func testRouter(t *testing.T, r *router.Router) {
+	h := &recorder{ssid: []byte("a")}
+	for _, msg := range []*protocol.Message{
+		message("a", "1", 2, true),
+		message("a", "1", 2, false),
+		message("a", "2", 2, false),
+		message("b", "1", 2, false),
+	} {
+		ok, err := r.Route(msg)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+	// duplicates are ignored
+	ok, err := r.Route(message("a", "1", 2, true))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	n, err := r.Deliver(h)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	n, err = r.Deliver(h)
+	require.NoError(t, err)
+	assert.Zero(t, n, "messages must only be delivered once")
+
+	pending, err := r.Pending()
+	require.NoError(t, err)
+	assert.Equal(t, 1, pending, "message of another session stays pending")
+
+	// restoring the handler from a snapshot of round 2 requires redelivery
+	require.NoError(t, r.Rewind([]byte("a"), 2))
+	h.accepted = nil
+	n, err = r.Deliver(h)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	require.NoError(t, r.Forget([]byte("b")))
+	pending, err = r.Pending()
+	require.NoError(t, err)
+	assert.Zero(t, pending)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testRouter(t, router.New(router.NewMemoryStore()))
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	store, err := router.OpenFileStore(path)
+	require.NoError(t, err)
+	testRouter(t, router.New(store))
+
+	// route a new message, and restart before delivering it
+	ok, err := router.New(store).Route(message("a", "1", 3, false))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, store.Close())
+
+	store, err = router.OpenFileStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+	r := router.New(store)
+
+	// messages delivered before the restart are still known
+	ok, err = r.Route(message("a", "2", 2, false))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	h := &recorder{ssid: []byte("a")}
+	n, err := r.Deliver(h)
+	require.NoError(t, err)
+	require.Equal(t, 1, n, "only the undelivered message is delivered after a restart")
+	assert.Equal(t, round.Number(3), h.accepted[0].RoundNumber)
+}
+
+func TestFileStoreTruncatedJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	store, err := router.OpenFileStore(path)
+	require.NoError(t, err)
+	r := router.New(store)
+	for _, msg := range []*protocol.Message{message("a", "1", 2, false), message("a", "2", 2, false)} {
+		_, err = r.Route(msg)
+		require.NoError(t, err)
+	}
+	require.NoError(t, store.Close())
+	journal, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// a partially written last record is ignored
+	require.NoError(t, os.WriteFile(path, journal[:len(journal)-5], 0o600))
+	store, err = router.OpenFileStore(path)
+	require.NoError(t, err)
+	pending, err := router.New(store).Pending()
+	require.NoError(t, err)
+	assert.Equal(t, 1, pending)
+	require.NoError(t, store.Close())
+
+	// a corrupted record followed by others is an error, rather than dropping the following records
+	corrupted := append([]byte("{\n"), journal...)
+	require.NoError(t, os.WriteFile(path, corrupted, 0o600))
+	_, err = router.OpenFileStore(path)
+	assert.Error(t, err)
+}
+
+// failingStore is a Store which cannot record deliveries.
+type failingStore struct {
+	*router.MemoryStore
+}
+
+func (failingStore) SetDelivered(string, bool) error { return errors.New("disk full") }
+
+func TestDeliverMarksAfterAccept(t *testing.T) {
+	r := router.New(failingStore{router.NewMemoryStore()})
+	_, err := r.Route(message("a", "1", 2, false))
+	require.NoError(t, err)
+
+	// the message is accepted before the delivery is recorded, so a failure to record it
+	// leaves the message pending rather than lost
+	h := &recorder{ssid: []byte("a")}
+	_, err = r.Deliver(h)
+	assert.Error(t, err)
+	assert.Len(t, h.accepted, 1)
+	pending, err := r.Pending()
+	require.NoError(t, err)
+	assert.Equal(t, 1, pending)
+}
@@ -0,0 +1,83 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+)
+
+// Entry is a message stored by a Store.
+type Entry struct {
+	Key       string
+	Message   *protocol.Message
+	Delivered bool
+}
+
+// Store persists the messages of a Router.
+// Implementations do not need to be safe for concurrent use, since the Router serializes all calls.
+type Store interface {
+	// Add stores msg as pending under key. It returns false without modifying the store if key is already present.
+	Add(key string, msg *protocol.Message) (bool, error)
+	// SetDelivered updates the delivery status of the message stored under key.
+	SetDelivered(key string, delivered bool) error
+	// Remove deletes the message stored under key.
+	Remove(key string) error
+	// Entries returns all stored messages in the order they were added.
+	Entries() ([]Entry, error)
+}
+
+// MemoryStore is a Store which keeps all messages in memory.
+// It provides exactly-once delivery for the lifetime of the process.
+type MemoryStore struct {
+	entries map[string]*Entry
+	order   []string
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]*Entry)}
+}
+
+// Add implements Store.
+func (s *MemoryStore) Add(key string, msg *protocol.Message) (bool, error) {
+	if _, ok := s.entries[key]; ok {
+		return false, nil
+	}
+	s.entries[key] = &Entry{Key: key, Message: msg}
+	s.order = append(s.order, key)
+	return true, nil
+}
+
+// SetDelivered implements Store.
+func (s *MemoryStore) SetDelivered(key string, delivered bool) error {
+	e, ok := s.entries[key]
+	if !ok {
+		return fmt.Errorf("router: unknown message %s", key)
+	}
+	e.Delivered = delivered
+	return nil
+}
+
+// Remove implements Store.
+func (s *MemoryStore) Remove(key string) error {
+	if _, ok := s.entries[key]; !ok {
+		return nil
+	}
+	delete(s.entries, key)
+	for i, k := range s.order {
+		if k == key {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Entries implements Store.
+func (s *MemoryStore) Entries() ([]Entry, error) {
+	entries := make([]Entry, 0, len(s.order))
+	for _, key := range s.order {
+		entries = append(entries, *s.entries[key])
+	}
+	return entries, nil
+}
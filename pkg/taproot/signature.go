@@ -200,3 +200,6 @@ func (pk PublicKey) Verify(sig Signature, m []byte) bool {
 	}
 	return bytes.Equal(check.XBytes(), sig[:32])
 }
+
+// ResultKind returns "signature", see protocol.KindOf.
+func (Signature) ResultKind() string { return "signature" }
@@ -0,0 +1,92 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp"
+)
+
+// Store persists the config of a Wallet.
+type Store interface {
+	// Load returns the stored config, or nil if no config was saved yet.
+	Load() (*cmp.Config, error)
+	// Save replaces the stored config with c.
+	Save(c *cmp.Config) error
+}
+
+// MemoryStore is a Store which keeps the config in memory, for tests and short-lived wallets.
+type MemoryStore struct {
+	config *cmp.Config
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Load implements Store.
+func (s *MemoryStore) Load() (*cmp.Config, error) {
+	return s.config, nil
+}
+
+// Save implements Store.
+func (s *MemoryStore) Save(c *cmp.Config) error {
+	s.config = c
+	return nil
+}
+
+// FileStore is a Store which keeps the encoded config in a single file.
+// The file is replaced atomically by Save, so that a crash never leaves a partially written config.
+type FileStore struct {
+	path string
+}
+
+// NewFileStore returns a FileStore for the file at path, which does not need to exist yet.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load implements Store.
+func (s *FileStore) Load() (*cmp.Config, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("wallet: %w", err)
+	}
+	c := cmp.EmptyConfig(curve.Secp256k1{})
+	if err = c.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("wallet: %w", err)
+	}
+	return c, nil
+}
+
+// Save implements Store.
+func (s *FileStore) Save(c *cmp.Config) error {
+	data, err := c.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("wallet: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("wallet: %w", err)
+	}
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("wallet: %w", err)
+	}
+	if err = os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("wallet: %w", err)
+	}
+	return nil
+}
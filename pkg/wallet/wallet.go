@@ -0,0 +1,288 @@
+// Package wallet combines key generation, BIP32 derivation, presigning and signing with cmp
+// behind a small interface, for integrators which only need a threshold ECDSA wallet.
+//
+// Every party runs its own Wallet, and the parties taking part in an operation must all call it with the same
+// arguments, in the same order relative to their other operations with the same parties, since each operation
+// runs a protocol between them. The wallet numbers the operations run by each set of parties to derive the
+// session ID of each protocol, so that messages of consecutive operations are never confused, and routes
+// incoming messages with a router.Router, so that messages of an operation which has not started yet locally
+// are kept until it does.
+package wallet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/pkg/router"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
+)
+
+// Transport exchanges protocol messages with the other parties of a Wallet.
+type Transport interface {
+	// Self returns the ID of this party.
+	Self() party.ID
+	// Send delivers msg to msg.To, or to all other parties if msg.To is empty.
+	// It must not wait for the recipients to process msg.
+	Send(msg *protocol.Message) error
+	// Receive returns the channel of messages sent to this party. It is closed when the transport is closed.
+	Receive() <-chan *protocol.Message
+}
+
+// Options configures a Wallet, see NewWithOptions.
+type Options struct {
+	// Profile is used to derive addresses. If nil, ecdsa.ProfileEthereum is used.
+	Profile *ecdsa.Profile
+	// Pool is used to parallelize the protocols. If nil, they run on a single goroutine.
+	Pool *pool.Pool
+	// Handler configures the handlers of all protocols.
+	Handler protocol.HandlerOptions
+}
+
+// Wallet manages a single threshold key of this party.
+// Its methods are safe for concurrent use, but run one at a time.
+type Wallet struct {
+	mtx       sync.Mutex
+	store     Store
+	transport Transport
+	router    *router.Router
+	opts      Options
+
+	config  *cmp.Config
+	signers party.IDSlice
+	// presignatures were produced with config and signers, and are used in order by Sign.
+	// They are never persisted, since a presignature restored from a backup could be used twice.
+	presignatures []*ecdsa.PreSignature
+	// operations counts the protocols run by each set of parties, see sessionID.
+	operations map[string]uint64
+}
+
+// New returns a Wallet using the config saved in store, if any, and exchanging messages through transport.
+func New(store Store, transport Transport) (*Wallet, error) {
+	return NewWithOptions(store, transport, Options{})
+}
+
+// NewWithOptions is the same as New, with additional options.
+func NewWithOptions(store Store, transport Transport, opts Options) (*Wallet, error) {
+	if store == nil || transport == nil {
+		return nil, errors.New("wallet: store or transport is nil")
+	}
+	if opts.Profile == nil {
+		opts.Profile = ecdsa.ProfileEthereum
+	}
+	c, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	w := &Wallet{
+		store:      store,
+		transport:  transport,
+		router:     router.New(router.NewMemoryStore()),
+		opts:       opts,
+		operations: make(map[string]uint64),
+	}
+	if c != nil {
+		if c.ID != transport.Self() {
+			return nil, fmt.Errorf("wallet: stored config belongs to %s, not %s", c.ID, transport.Self())
+		}
+		w.setConfig(c)
+	}
+	return w, nil
+}
+
+// NewKey generates a new key between parties, any threshold+1 of which can sign, and saves it in the store,
+// replacing the current key.
+func (w *Wallet) NewKey(parties []party.ID, threshold int) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	self := w.transport.Self()
+	if !party.NewIDSlice(parties).Contains(self) {
+		return fmt.Errorf("wallet: parties do not include self (%s)", self)
+	}
+	result, err := w.run(cmp.Keygen(curve.Secp256k1{}, self, parties, threshold, w.opts.Pool), party.NewIDSlice(parties), "keygen")
+	if err != nil {
+		return err
+	}
+	c := result.(*cmp.Config)
+	if err = w.store.Save(c); err != nil {
+		return err
+	}
+	w.setConfig(c)
+	return nil
+}
+
+// SetSigners selects the parties which take part in Presign and Sign, which are all parties by default.
+// Presignatures produced with other signers are discarded.
+func (w *Wallet) SetSigners(signers []party.ID) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.config == nil {
+		return errors.New("wallet: no key")
+	}
+	sorted := party.NewIDSlice(signers)
+	if err := w.config.ValidateSigners(sorted); err != nil {
+		return fmt.Errorf("wallet: %w", err)
+	}
+	if len(sorted) != len(w.signers) || !w.signers.Contains(sorted...) {
+		w.signers = sorted
+		w.presignatures = nil
+	}
+	return nil
+}
+
+// Address returns the address of the key at path, such as "m/0/1", for Options.Profile.
+func (w *Wallet) Address(path string) (string, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.config == nil {
+		return "", errors.New("wallet: no key")
+	}
+	p, err := config.ParseDerivationPath(path)
+	if err != nil {
+		return "", err
+	}
+	return w.config.DeriveAddress(w.opts.Profile, p)
+}
+
+// Presign adds count presignatures to the pool used by Sign, which then only requires a single round.
+func (w *Wallet) Presign(count int) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.config == nil {
+		return errors.New("wallet: no key")
+	}
+	for i := 0; i < count; i++ {
+		result, err := w.run(cmp.Presign(w.config, w.signers, w.opts.Pool), w.signers, "presign")
+		if err != nil {
+			return err
+		}
+		w.presignatures = append(w.presignatures, result.(*ecdsa.PreSignature))
+	}
+	return nil
+}
+
+// Presignatures returns the number of presignatures available to Sign.
+func (w *Wallet) Presignatures() int {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return len(w.presignatures)
+}
+
+// Sign signs digest with the key at path, such as "m/0/1".
+// It uses the oldest presignature if there is one, and runs the full signing protocol otherwise.
+func (w *Wallet) Sign(path string, digest []byte) (*ecdsa.Signature, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.config == nil {
+		return nil, errors.New("wallet: no key")
+	}
+	p, err := config.ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	child, _, err := w.config.DerivePath(p)
+	if err != nil {
+		return nil, fmt.Errorf("wallet: %w", err)
+	}
+
+	var result interface{}
+	if len(w.presignatures) > 0 {
+		preSignature := w.presignatures[0]
+		// the presignature is consumed even if signing fails, since it must never be used twice
+		w.presignatures = w.presignatures[1:]
+		adapted, err := cmp.AdaptPreSignature(w.config, child, preSignature, digest)
+		if err != nil {
+			return nil, fmt.Errorf("wallet: %w", err)
+		}
+		result, err = w.run(cmp.PresignOnline(child, adapted, digest, w.opts.Pool), w.signers, "presign-online", []byte(path), digest)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		result, err = w.run(cmp.Sign(child, w.signers, digest, w.opts.Pool), w.signers, "sign", []byte(path), digest)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sig := result.(*ecdsa.Signature)
+	if !sig.Verify(child.PublicPoint(), digest) {
+		return nil, errors.New("wallet: invalid signature")
+	}
+	return sig, nil
+}
+
+// setConfig replaces the key of w, and resets the signers and presignatures.
+func (w *Wallet) setConfig(c *cmp.Config) {
+	w.config = c
+	w.signers = c.PartyIDs()
+	w.presignatures = nil
+}
+
+// sessionID returns the session ID of the next operation between participants, which is the same for all of them
+// as long as they perform the same operations in the same order.
+func (w *Wallet) sessionID(participants party.IDSlice, operation string, data ...[]byte) []byte {
+	key := participants.String()
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, w.operations[key])
+	w.operations[key]++
+	h := hash.New(
+		&hash.BytesWithDomain{TheDomain: "Wallet Operation", Bytes: []byte(operation)},
+		&hash.BytesWithDomain{TheDomain: "Wallet Counter", Bytes: counter},
+	)
+	for _, d := range data {
+		_ = h.WriteAny(&hash.BytesWithDomain{TheDomain: "Wallet Data", Bytes: d})
+	}
+	return h.Sum()
+}
+
+// run executes the protocol created by start until it completes, and returns its result.
+// Messages received for other sessions are kept by the router until the corresponding operation runs.
+func (w *Wallet) run(start protocol.StartFunc, participants party.IDSlice, operation string, data ...[]byte) (interface{}, error) {
+	h, err := protocol.NewMultiHandlerWithOptions(start, w.sessionID(participants, operation, data...), w.opts.Handler)
+	if err != nil {
+		return nil, fmt.Errorf("wallet: %s: %w", operation, err)
+	}
+	ssid := h.CurrentRound().SSID()
+	defer func() { _ = w.router.Forget(ssid) }()
+
+	if _, err = w.router.Deliver(h); err != nil {
+		h.Stop()
+		return nil, fmt.Errorf("wallet: %s: %w", operation, err)
+	}
+	for {
+		select {
+		case msg, ok := <-h.Listen():
+			if !ok {
+				result, err := h.Result()
+				if err != nil {
+					return nil, fmt.Errorf("wallet: %s: %w", operation, err)
+				}
+				return result, nil
+			}
+			if err = w.transport.Send(msg); err != nil {
+				h.Stop()
+				return nil, fmt.Errorf("wallet: %s: %w", operation, err)
+			}
+		case msg, ok := <-w.transport.Receive():
+			if !ok {
+				h.Stop()
+				return nil, fmt.Errorf("wallet: %s: transport closed", operation)
+			}
+			if _, err = w.router.Route(msg); err == nil {
+				_, err = w.router.Deliver(h)
+			}
+			if err != nil {
+				h.Stop()
+				return nil, fmt.Errorf("wallet: %s: %w", operation, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package wallet_test
+
+import (
+	"crypto/sha256"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/pkg/wallet"
+)
+
+// transport delivers messages between the parties of a test through buffered channels.
+type transport struct {
+	self  party.ID
+	peers map[party.ID]chan *protocol.Message
+}
+
+func newTransports(partyIDs party.IDSlice) map[party.ID]*transport {
+	peers := make(map[party.ID]chan *protocol.Message, len(partyIDs))
+	for _, id := range partyIDs {
+		peers[id] = make(chan *protocol.Message, 1000)
+	}
+	transports := make(map[party.ID]*transport, len(partyIDs))
+	for _, id := range partyIDs {
+		transports[id] = &transport{self: id, peers: peers}
+	}
+	return transports
+}
+
+func (t *transport) Self() party.ID                    { return t.self }
+func (t *transport) Receive() <-chan *protocol.Message { return t.peers[t.self] }
+func (t *transport) Send(msg *protocol.Message) error {
+	for id, c := range t.peers {
+		if msg.IsFor(id) {
+			c <- msg
+		}
+	}
+	return nil
+}
+
+// all runs f for every wallet concurrently.
+func all(t *testing.T, wallets map[party.ID]*wallet.Wallet, f func(id party.ID, w *wallet.Wallet) error) {
+	var wg sync.WaitGroup
+	errs := make(map[party.ID]error, len(wallets))
+	var mtx sync.Mutex
+	for id, w := range wallets {
+		wg.Add(1)
+		go func(id party.ID, w *wallet.Wallet) {
+			defer wg.Done()
+			err := f(id, w)
+			mtx.Lock()
+			errs[id] = err
+			mtx.Unlock()
+		}(id, w)
+	}
+	wg.Wait()
+	for id, err := range errs {
+		require.NoError(t, err, "party %s", id)
+	}
+}
+
+func TestWallet(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	partyIDs := test.PartyIDs(3)
+	transports := newTransports(partyIDs)
+	dir := t.TempDir()
+	wallets := make(map[party.ID]*wallet.Wallet, len(partyIDs))
+	for _, id := range partyIDs {
+		w, err := wallet.NewWithOptions(wallet.NewFileStore(filepath.Join(dir, string(id))), transports[id], wallet.Options{Pool: pl})
+		require.NoError(t, err)
+		_, err = w.Address("m")
+		assert.Error(t, err, "no key yet")
+		wallets[id] = w
+	}
+
+	all(t, wallets, func(id party.ID, w *wallet.Wallet) error { return w.NewKey(partyIDs, 1) })
+	addresses := make(map[string]bool)
+	for _, w := range wallets {
+		address, err := w.Address("m/0/1")
+		require.NoError(t, err)
+		addresses[address] = true
+	}
+	assert.Len(t, addresses, 1, "all parties derive the same address")
+
+	digest := sha256.Sum256([]byte("hello"))
+	assert.Error(t, wallets[partyIDs[2]].SetSigners(partyIDs[:2]), "signers must include self")
+	signers := map[party.ID]*wallet.Wallet{partyIDs[0]: wallets[partyIDs[0]], partyIDs[1]: wallets[partyIDs[1]]}
+	all(t, signers, func(id party.ID, w *wallet.Wallet) error { return w.SetSigners(partyIDs[:2]) })
+	all(t, signers, func(id party.ID, w *wallet.Wallet) error { return w.Presign(1) })
+	// the first signature uses the presignature, and the second runs the full protocol
+	for _, path := range []string{"m/0/1", "m/0/2"} {
+		sigs := make(map[party.ID]*ecdsa.Signature)
+		var mtx sync.Mutex
+		all(t, signers, func(id party.ID, w *wallet.Wallet) error {
+			sig, err := w.Sign(path, digest[:])
+			mtx.Lock()
+			sigs[id] = sig
+			mtx.Unlock()
+			return err
+		})
+		for _, w := range signers {
+			assert.Zero(t, w.Presignatures())
+		}
+		assert.Len(t, sigs, 2)
+	}
+
+	// the key is restored from the store
+	restored, err := wallet.New(wallet.NewFileStore(filepath.Join(dir, string(partyIDs[0]))), transports[partyIDs[0]])
+	require.NoError(t, err)
+	address, err := restored.Address("m/0/1")
+	require.NoError(t, err)
+	assert.True(t, addresses[address])
+	_, err = wallet.New(wallet.NewFileStore(filepath.Join(dir, string(partyIDs[0]))), transports[partyIDs[1]])
+	assert.Error(t, err, "the stored key belongs to another party")
+}
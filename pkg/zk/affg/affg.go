@@ -1,15 +1,17 @@
 package zkaffg
 
 import (
-	"crypto/rand"
+	"errors"
 
 	"github.com/cronokirby/saferith"
+	"github.com/taurusgroup/multi-party-sig/internal/cbormap"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/arith"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
 	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 )
 
 type Public struct {
@@ -110,16 +112,16 @@ func NewProof(group curve.Curve, hash *hash.Hash, public Public, private Private
 	verifier := public.Verifier
 	prover := public.Prover
 
-	alpha := sample.IntervalLEps(rand.Reader)
-	beta := sample.IntervalLPrimeEps(rand.Reader)
+	alpha := sample.IntervalLEps(platform.Reader)
+	beta := sample.IntervalLPrimeEps(platform.Reader)
 
-	rho := sample.UnitModN(rand.Reader, N0)
-	rhoY := sample.UnitModN(rand.Reader, N1)
+	rho := sample.UnitModN(platform.Reader, N0)
+	rhoY := sample.UnitModN(platform.Reader, N1)
 
-	gamma := sample.IntervalLEpsN(rand.Reader)
-	m := sample.IntervalLN(rand.Reader)
-	delta := sample.IntervalLEpsN(rand.Reader)
-	mu := sample.IntervalLN(rand.Reader)
+	gamma := sample.IntervalLEpsN(platform.Reader)
+	m := sample.IntervalLN(platform.Reader)
+	delta := sample.IntervalLEpsN(platform.Reader)
+	mu := sample.IntervalLN(platform.Reader)
 
 	cAlpha := public.Kv.Clone().Mul(verifier, alpha)            // = Cᵃ mod N₀ = α ⊙ Kv
 	A := verifier.EncWithNonce(beta, rho).Add(verifier, cAlpha) // = Enc₀(β,ρ) ⊕ (α ⊙ Kv)
@@ -258,3 +260,54 @@ func Empty(group curve.Curve) *Proof {
 		Commitment: &Commitment{Bx: group.NewPoint()},
 	}
 }
+
+// DecodeCBOR decodes the CBOR encoding of p produced by cbor.Marshal, without reflection.
+// p must have been created by Empty.
+func (p *Proof) DecodeCBOR(data []byte) error {
+	if p.Commitment == nil || p.Bx == nil {
+		return errors.New("zkaffg: proof was not created by Empty")
+	}
+	return cbormap.Decode(data, func(key string, value []byte) error {
+		switch cbormap.Key(key, "A", "Bx", "By", "E", "S", "F", "T", "Z1", "Z2", "Z3", "Z4", "W", "Wy") {
+		case "A":
+			p.A = new(paillier.Ciphertext)
+			return cbormap.Binary(value, p.A)
+		case "Bx":
+			return cbormap.Binary(value, p.Bx)
+		case "By":
+			p.By = new(paillier.Ciphertext)
+			return cbormap.Binary(value, p.By)
+		case "E":
+			p.E = new(saferith.Nat)
+			return cbormap.Binary(value, p.E)
+		case "S":
+			p.S = new(saferith.Nat)
+			return cbormap.Binary(value, p.S)
+		case "F":
+			p.F = new(saferith.Nat)
+			return cbormap.Binary(value, p.F)
+		case "T":
+			p.T = new(saferith.Nat)
+			return cbormap.Binary(value, p.T)
+		case "Z1":
+			p.Z1 = new(saferith.Int)
+			return cbormap.Binary(value, p.Z1)
+		case "Z2":
+			p.Z2 = new(saferith.Int)
+			return cbormap.Binary(value, p.Z2)
+		case "Z3":
+			p.Z3 = new(saferith.Int)
+			return cbormap.Binary(value, p.Z3)
+		case "Z4":
+			p.Z4 = new(saferith.Int)
+			return cbormap.Binary(value, p.Z4)
+		case "W":
+			p.W = new(saferith.Nat)
+			return cbormap.Binary(value, p.W)
+		case "Wy":
+			p.Wy = new(saferith.Nat)
+			return cbormap.Binary(value, p.Wy)
+		}
+		return nil
+	})
+}
@@ -1,8 +1,6 @@
 package zkaffp
 
 import (
-	"crypto/rand"
-
 	"github.com/cronokirby/saferith"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/arith"
@@ -10,6 +8,7 @@ import (
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
 	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 )
 
 type Public struct {
@@ -112,17 +111,17 @@ func NewProof(group curve.Curve, hash *hash.Hash, public Public, private Private
 	verifier := public.Verifier
 	prover := public.Prover
 
-	alpha := sample.IntervalLEps(rand.Reader)
-	beta := sample.IntervalLPrimeEps(rand.Reader)
+	alpha := sample.IntervalLEps(platform.Reader)
+	beta := sample.IntervalLPrimeEps(platform.Reader)
 
-	rho := sample.UnitModN(rand.Reader, N0)
-	rhoX := sample.UnitModN(rand.Reader, N1)
-	rhoY := sample.UnitModN(rand.Reader, N1)
+	rho := sample.UnitModN(platform.Reader, N0)
+	rhoX := sample.UnitModN(platform.Reader, N1)
+	rhoY := sample.UnitModN(platform.Reader, N1)
 
-	gamma := sample.IntervalLEpsN(rand.Reader)
-	m := sample.IntervalLN(rand.Reader)
-	delta := sample.IntervalLEpsN(rand.Reader)
-	mu := sample.IntervalLN(rand.Reader)
+	gamma := sample.IntervalLEpsN(platform.Reader)
+	m := sample.IntervalLN(platform.Reader)
+	delta := sample.IntervalLEpsN(platform.Reader)
+	mu := sample.IntervalLN(platform.Reader)
 
 	cAlpha := public.Kv.Clone().Mul(verifier, alpha)            // = Cᵃ mod N₀ = α ⊙ Kv
 	A := verifier.EncWithNonce(beta, rho).Add(verifier, cAlpha) // = Enc₀(β,ρ) ⊕ (α ⊙ Kv)
@@ -244,3 +243,8 @@ func challenge(hash *hash.Hash, group curve.Curve, public Public, commitment *Co
 	e = sample.IntervalScalar(hash.Digest(), group)
 	return
 }
+
+// Empty returns a proof ready for unmarshalling, see the zk package.
+func Empty(curve.Curve) *Proof {
+	return &Proof{Commitment: &Commitment{}}
+}
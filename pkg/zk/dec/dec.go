@@ -1,8 +1,6 @@
 package zkdec
 
 import (
-	"crypto/rand"
-
 	"github.com/cronokirby/saferith"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/arith"
@@ -10,6 +8,7 @@ import (
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
 	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 )
 
 type Public struct {
@@ -73,11 +72,11 @@ func (p *Proof) IsValid(public Public) bool {
 func NewProof(group curve.Curve, hash *hash.Hash, public Public, private Private) *Proof {
 	N := public.Prover.N()
 	NModulus := public.Prover.Modulus()
-	alpha := sample.IntervalLEps(rand.Reader)
+	alpha := sample.IntervalLEps(platform.Reader)
 
-	mu := sample.IntervalLN(rand.Reader)
-	nu := sample.IntervalLEpsN(rand.Reader)
-	r := sample.UnitModN(rand.Reader, N)
+	mu := sample.IntervalLN(platform.Reader)
+	nu := sample.IntervalLEpsN(platform.Reader)
+	r := sample.UnitModN(platform.Reader, N)
 
 	gamma := group.NewScalar().SetNat(alpha.Mod(group.Order()))
 
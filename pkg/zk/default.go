@@ -1,3 +1,9 @@
+// Package zk holds fixed Paillier and Pedersen parameters for the tests of the zero-knowledge proofs in its subpackages.
+//
+// Every subpackage provides Empty(group), which returns a proof ready for unmarshalling. The proofs of affp, enc,
+// fac, mod, mul, nth and prm contain no curve elements, so their Empty ignores the group, which is only accepted
+// for uniformity with the other subpackages. Proofs are serialized without a group tag, since the group is known
+// from the session which receives them, and is bound to the transcript from which their challenges are derived.
 package zk
 
 import (
@@ -1,12 +1,11 @@
 package zkelog
 
 import (
-	"crypto/rand"
-
 	"github.com/taurusgroup/multi-party-sig/internal/elgamal"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 )
 
 type Public struct {
@@ -67,8 +66,8 @@ func (p *Proof) IsValid(public Public) bool {
 }
 
 func NewProof(group curve.Curve, hash *hash.Hash, public Public, private Private) *Proof {
-	alpha := sample.Scalar(rand.Reader, group)
-	m := sample.Scalar(rand.Reader, group)
+	alpha := sample.Scalar(platform.Reader, group)
+	m := sample.Scalar(platform.Reader, group)
 
 	commitment := &Commitment{
 		A: alpha.ActOnBase(),                                  // A = α⋅G
@@ -125,7 +124,7 @@ func (p *Proof) Verify(hash *hash.Hash, public Public) bool {
 func challenge(hash *hash.Hash, group curve.Curve, public Public, commitment *Commitment) (e curve.Scalar, err error) {
 	err = hash.WriteAny(public.E, public.ElGamalPublic, public.Y, public.Base,
 		commitment.A, commitment.N, commitment.B)
-	e = sample.Scalar(hash.Digest(), group)
+	e = sample.Challenge(hash.Digest(), group, hash.WideChallenges())
 	return
 }
 
@@ -1,15 +1,15 @@
 package zkenc
 
 import (
-	"crypto/rand"
-
 	"github.com/cronokirby/saferith"
+	"github.com/taurusgroup/multi-party-sig/internal/cbormap"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/arith"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
 	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 )
 
 type Public struct {
@@ -65,10 +65,10 @@ func NewProof(group curve.Curve, hash *hash.Hash, public Public, private Private
 	N := public.Prover.N()
 	NModulus := public.Prover.Modulus()
 
-	alpha := sample.IntervalLEps(rand.Reader)
-	r := sample.UnitModN(rand.Reader, N)
-	mu := sample.IntervalLN(rand.Reader)
-	gamma := sample.IntervalLEpsN(rand.Reader)
+	alpha := sample.IntervalLEps(platform.Reader)
+	r := sample.UnitModN(platform.Reader, N)
+	mu := sample.IntervalLN(platform.Reader)
+	gamma := sample.IntervalLEpsN(platform.Reader)
 
 	A := public.Prover.EncWithNonce(alpha, r)
 
@@ -138,3 +138,38 @@ func challenge(hash *hash.Hash, group curve.Curve, public Public, commitment *Co
 	e = sample.IntervalScalar(hash.Digest(), group)
 	return
 }
+
+// Empty returns a proof ready for unmarshalling, see the zk package.
+func Empty(curve.Curve) *Proof {
+	return &Proof{Commitment: &Commitment{}}
+}
+
+// DecodeCBOR decodes the CBOR encoding of p produced by cbor.Marshal, without reflection.
+func (p *Proof) DecodeCBOR(data []byte) error {
+	if p.Commitment == nil {
+		p.Commitment = &Commitment{}
+	}
+	return cbormap.Decode(data, func(key string, value []byte) error {
+		switch cbormap.Key(key, "S", "A", "C", "Z1", "Z2", "Z3") {
+		case "S":
+			p.S = new(saferith.Nat)
+			return cbormap.Binary(value, p.S)
+		case "A":
+			p.A = new(paillier.Ciphertext)
+			return cbormap.Binary(value, p.A)
+		case "C":
+			p.C = new(saferith.Nat)
+			return cbormap.Binary(value, p.C)
+		case "Z1":
+			p.Z1 = new(saferith.Int)
+			return cbormap.Binary(value, p.Z1)
+		case "Z2":
+			p.Z2 = new(saferith.Nat)
+			return cbormap.Binary(value, p.Z2)
+		case "Z3":
+			p.Z3 = new(saferith.Int)
+			return cbormap.Binary(value, p.Z3)
+		}
+		return nil
+	})
+}
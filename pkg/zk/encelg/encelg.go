@@ -1,8 +1,6 @@
 package zkencelg
 
 import (
-	"crypto/rand"
-
 	"github.com/cronokirby/saferith"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/arith"
@@ -10,6 +8,7 @@ import (
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
 	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 )
 
 type Public struct {
@@ -85,12 +84,12 @@ func NewProof(group curve.Curve, hash *hash.Hash, public Public, private Private
 	N := public.Prover.N()
 	NModulus := public.Prover.Modulus()
 
-	alpha := sample.IntervalLEps(rand.Reader)
+	alpha := sample.IntervalLEps(platform.Reader)
 	alphaScalar := group.NewScalar().SetNat(alpha.Mod(group.Order()))
-	mu := sample.IntervalLN(rand.Reader)
-	r := sample.UnitModN(rand.Reader, N)
-	beta := sample.Scalar(rand.Reader, group)
-	gamma := sample.IntervalLEpsN(rand.Reader)
+	mu := sample.IntervalLN(platform.Reader)
+	r := sample.UnitModN(platform.Reader, N)
+	beta := sample.Scalar(platform.Reader, group)
+	gamma := sample.IntervalLEpsN(platform.Reader)
 
 	commitment := &Commitment{
 		S: public.Aux.Commit(private.X, mu),
@@ -1,18 +1,21 @@
 package zkfac
 
 import (
-	"crypto/rand"
-
 	"github.com/cronokirby/saferith"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/arith"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 )
 
 type Public struct {
 	N   *saferith.Modulus
 	Aux *pedersen.Parameters
+	// Context binds the challenge to the session and the prover.
+	// It is only used, and then required, if the hash uses hash.ChallengeV3.
+	Context *hash.ProofContext
 }
 
 type Private struct {
@@ -41,14 +44,14 @@ func NewProof(private Private, hash *hash.Hash, public Public) *Proof {
 	Nhat := public.Aux.NArith()
 
 	// Figure 28, point 1.
-	alpha := sample.IntervalLEpsRootN(rand.Reader)
-	beta := sample.IntervalLEpsRootN(rand.Reader)
-	mu := sample.IntervalLN(rand.Reader)
-	nu := sample.IntervalLN(rand.Reader)
-	sigma := sample.IntervalLN2(rand.Reader)
-	r := sample.IntervalLEpsN2(rand.Reader)
-	x := sample.IntervalLEpsN(rand.Reader)
-	y := sample.IntervalLEpsN(rand.Reader)
+	alpha := sample.IntervalLEpsRootN(platform.Reader)
+	beta := sample.IntervalLEpsRootN(platform.Reader)
+	mu := sample.IntervalLN(platform.Reader)
+	nu := sample.IntervalLN(platform.Reader)
+	sigma := sample.IntervalLN2(platform.Reader)
+	r := sample.IntervalLEpsN2(platform.Reader)
+	x := sample.IntervalLEpsN(platform.Reader)
+	y := sample.IntervalLEpsN(platform.Reader)
 
 	pInt := new(saferith.Int).SetNat(private.P)
 	qInt := new(saferith.Int).SetNat(private.Q)
@@ -94,10 +97,24 @@ func NewProof(private Private, hash *hash.Hash, public Public) *Proof {
 	}
 }
 
-func (p *Proof) Verify(public Public, hash *hash.Hash) bool {
+func (p *Proof) IsValid(public Public) bool {
 	if p == nil {
 		return false
 	}
+	if p.Sigma == nil || p.Z1 == nil || p.Z2 == nil || p.W1 == nil || p.W2 == nil || p.V == nil {
+		return false
+	}
+	c := p.Comm
+	if !arith.IsValidNatModN(public.Aux.N(), c.P, c.Q, c.A, c.B, c.T) {
+		return false
+	}
+	return true
+}
+
+func (p *Proof) Verify(public Public, hash *hash.Hash) bool {
+	if !p.IsValid(public) {
+		return false
+	}
 
 	e, err := challenge(hash, public, p.Comm)
 	if err != nil {
@@ -135,6 +152,9 @@ func (p *Proof) Verify(public Public, hash *hash.Hash) bool {
 }
 
 func challenge(hash *hash.Hash, public Public, commitment Commitment) (*saferith.Int, error) {
+	if err := hash.WriteProofContext(public.Context); err != nil {
+		return nil, err
+	}
 	err := hash.WriteAny(public.N, public.Aux, commitment.P, commitment.Q, commitment.A, commitment.B, commitment.T)
 	if err != nil {
 		return nil, err
@@ -149,3 +169,8 @@ func challenge(hash *hash.Hash, public Public, commitment Commitment) (*saferith
 	return sample.IntervalL(hash.Digest()), nil
 	// return sample.IntervalEps(hash.Digest()), nil
 }
+
+// Empty returns a proof ready for unmarshalling, see the zk package.
+func Empty(curve.Curve) *Proof {
+	return &Proof{}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
 	"github.com/taurusgroup/multi-party-sig/pkg/pool"
 )
@@ -39,4 +40,42 @@ func TestFac(t *testing.T) {
 	require.NoError(t, cbor.Unmarshal(out2, proof3), "failed to unmarshal 2nd proof")
 
 	assert.True(t, proof3.Verify(public, hash.New()))
+	proof4 := Empty(curve.Secp256k1{})
+	require.NoError(t, cbor.Unmarshal(out, proof4), "failed to unmarshal into an empty proof")
+	assert.True(t, proof4.Verify(public, hash.New()))
+}
+
+func TestFacProofContext(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	aux, _ := paillier.NewSecretKey(pl).GeneratePedersen()
+	sk := paillier.NewSecretKey(pl)
+	newHash := func(version hash.ChallengeVersion) *hash.Hash {
+		h := hash.New()
+		h.SetChallengeVersion(version)
+		return h
+	}
+	ctx := &hash.ProofContext{SSID: []byte("session"), Prover: "a"}
+	public := Public{N: sk.Modulus().Modulus, Aux: aux, Context: ctx}
+	private := Private{P: sk.P(), Q: sk.Q()}
+
+	proof := NewProof(private, newHash(hash.ChallengeV3), public)
+	assert.True(t, proof.Verify(public, newHash(hash.ChallengeV3)))
+
+	for name, other := range map[string]*hash.ProofContext{
+		"missing": nil,
+		"ssid":    {SSID: []byte("other session"), Prover: "a"},
+		"prover":  {SSID: []byte("session"), Prover: "b"},
+		"epoch":   {SSID: []byte("session"), Prover: "a", Epoch: 1},
+	} {
+		replayed := public
+		replayed.Context = other
+		assert.False(t, proof.Verify(replayed, newHash(hash.ChallengeV3)), name)
+	}
+
+	// earlier versions ignore the context
+	proof = NewProof(private, newHash(hash.ChallengeV2), public)
+	public.Context = nil
+	assert.True(t, proof.Verify(public, newHash(hash.ChallengeV2)))
 }
@@ -1,11 +1,10 @@
 package zklog
 
 import (
-	"crypto/rand"
-
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 )
 
 type Public struct {
@@ -59,8 +58,8 @@ func (p *Proof) IsValid() bool {
 }
 
 func NewProof(group curve.Curve, hash *hash.Hash, public Public, private Private) *Proof {
-	alpha := sample.Scalar(rand.Reader, group)
-	beta := sample.Scalar(rand.Reader, group)
+	alpha := sample.Scalar(platform.Reader, group)
+	beta := sample.Scalar(platform.Reader, group)
 
 	commitment := &Commitment{
 		A: alpha.ActOnBase(),   // A = α⋅G
@@ -117,7 +116,7 @@ func (p *Proof) Verify(hash *hash.Hash, public Public) bool {
 func challenge(hash *hash.Hash, group curve.Curve, public Public, commitment *Commitment) (e curve.Scalar, err error) {
 	err = hash.WriteAny(public.H, public.X, public.Y,
 		commitment.A, commitment.B, commitment.C)
-	e = sample.Scalar(hash.Digest(), group)
+	e = sample.Challenge(hash.Digest(), group, hash.WideChallenges())
 	return
 }
 
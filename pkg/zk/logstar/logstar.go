@@ -1,15 +1,17 @@
 package zklogstar
 
 import (
-	"crypto/rand"
+	"errors"
 
 	"github.com/cronokirby/saferith"
+	"github.com/taurusgroup/multi-party-sig/internal/cbormap"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/arith"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
 	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 )
 
 type Public struct {
@@ -82,10 +84,10 @@ func NewProof(group curve.Curve, hash *hash.Hash, public Public, private Private
 		public.G = group.NewBasePoint()
 	}
 
-	alpha := sample.IntervalLEps(rand.Reader)
-	r := sample.UnitModN(rand.Reader, N)
-	mu := sample.IntervalLN(rand.Reader)
-	gamma := sample.IntervalLEpsN(rand.Reader)
+	alpha := sample.IntervalLEps(platform.Reader)
+	r := sample.UnitModN(platform.Reader, N)
+	mu := sample.IntervalLN(platform.Reader)
+	gamma := sample.IntervalLEpsN(platform.Reader)
 
 	commitment := &Commitment{
 		A: public.Prover.EncWithNonce(alpha, r),
@@ -181,3 +183,36 @@ func Empty(group curve.Curve) *Proof {
 		Commitment: &Commitment{Y: group.NewPoint()},
 	}
 }
+
+// DecodeCBOR decodes the CBOR encoding of p produced by cbor.Marshal, without reflection.
+// p must have been created by Empty.
+func (p *Proof) DecodeCBOR(data []byte) error {
+	if p.Commitment == nil || p.Y == nil {
+		return errors.New("zklogstar: proof was not created by Empty")
+	}
+	return cbormap.Decode(data, func(key string, value []byte) error {
+		switch cbormap.Key(key, "S", "A", "Y", "D", "Z1", "Z2", "Z3") {
+		case "S":
+			p.S = new(saferith.Nat)
+			return cbormap.Binary(value, p.S)
+		case "A":
+			p.A = new(paillier.Ciphertext)
+			return cbormap.Binary(value, p.A)
+		case "Y":
+			return cbormap.Binary(value, p.Y)
+		case "D":
+			p.D = new(saferith.Nat)
+			return cbormap.Binary(value, p.D)
+		case "Z1":
+			p.Z1 = new(saferith.Int)
+			return cbormap.Binary(value, p.Z1)
+		case "Z2":
+			p.Z2 = new(saferith.Nat)
+			return cbormap.Binary(value, p.Z2)
+		case "Z3":
+			p.Z3 = new(saferith.Int)
+			return cbormap.Binary(value, p.Z3)
+		}
+		return nil
+	})
+}
@@ -1,20 +1,44 @@
 package zkmod
 
 import (
-	"crypto/rand"
 	"math/big"
 
 	"github.com/cronokirby/saferith"
 	"github.com/taurusgroup/multi-party-sig/internal/params"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/arith"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 	"github.com/taurusgroup/multi-party-sig/pkg/pool"
 )
 
 type Public struct {
 	// N = p*q
 	N *saferith.Modulus
+	// Iterations is the number of Fiat-Shamir iterations performed by the prover,
+	// and expected by the verifier.
+	// If zero, params.StatParam is used.
+	// The verifier rejects proofs with fewer than params.MinStatParam iterations, unless Insecure is set.
+	Iterations int
+	// Insecure allows Iterations to be below params.MinStatParam, for dry runs and tests only.
+	Insecure bool
+	// Context binds the challenge to the session and the prover.
+	// It is only used, and then required, if the hash uses hash.ChallengeV3.
+	Context *hash.ProofContext
+}
+
+// iterations returns the number of repetitions of the proof, defaulting to params.StatParam.
+func (p Public) iterations() int {
+	if p.Iterations <= 0 {
+		return params.StatParam
+	}
+	return p.Iterations
+}
+
+// secure returns true if the verifier accepts the number of iterations of the proof.
+func (p Public) secure() bool {
+	return p.Insecure || p.iterations() >= params.MinStatParam
 }
 
 type Private struct {
@@ -36,7 +60,7 @@ type Response struct {
 
 type Proof struct {
 	W         *big.Int
-	Responses [params.StatParam]Response
+	Responses []Response
 }
 
 // isQRModPQ checks that y is a quadratic residue mod both p and q.
@@ -133,6 +157,9 @@ func (p *Proof) IsValid(public Public) bool {
 	if !arith.IsValidBigModN(N, p.W) {
 		return false
 	}
+	if !public.secure() || len(p.Responses) != public.iterations() {
+		return false
+	}
 	for _, r := range p.Responses {
 		if !arith.IsValidBigModN(N, r.X, r.Z) {
 			return false
@@ -162,16 +189,17 @@ func NewProof(hash *hash.Hash, private Private, public Public, pl *pool.Pool) *P
 	qMod := saferith.ModulusFromNat(q)
 	phiMod := saferith.ModulusFromNat(phi)
 	// W can be leaked so no need to make this sampling return a nat.
-	w := sample.QNR(rand.Reader, n)
+	w := sample.QNR(platform.Reader, n)
 
 	nInverse := new(saferith.Nat).ModInverse(n.Nat(), phiMod)
 
 	e := fourthRootExponent(phi)
 
-	ys, _ := challenge(hash, n, w.Big())
+	iterations := public.iterations()
+	ys, _ := challenge(hash, public, w.Big())
 
-	var rs [params.StatParam]Response
-	pl.Parallelize(params.StatParam, func(i int) interface{} {
+	rs := make([]Response, iterations)
+	pl.Parallelize(iterations, func(i int) interface{} {
 		y := ys[i]
 
 		// Z = y^{n⁻¹ (mod n)}
@@ -229,7 +257,6 @@ func (p *Proof) Verify(public Public, hash *hash.Hash, pl *pool.Pool) bool {
 		return false
 	}
 	n := public.N.Big()
-	nMod := public.N
 	// check if n is odd and prime
 	if n.Bit(0) == 0 || n.ProbablyPrime(20) {
 		return false
@@ -243,12 +270,17 @@ func (p *Proof) Verify(public Public, hash *hash.Hash, pl *pool.Pool) bool {
 		return false
 	}
 
+	iterations := public.iterations()
+	if !public.secure() || len(p.Responses) != iterations {
+		return false
+	}
+
 	// get [yᵢ] <- ℤₙ
-	ys, err := challenge(hash, nMod, p.W)
+	ys, err := challenge(hash, public, p.W)
 	if err != nil {
 		return false
 	}
-	verifications := pl.Parallelize(params.StatParam, func(i int) interface{} {
+	verifications := pl.Parallelize(iterations, func(i int) interface{} {
 		return p.Responses[i].Verify(n, p.W, ys[i].Big())
 	})
 	for i := 0; i < len(verifications); i++ {
@@ -259,12 +291,20 @@ func (p *Proof) Verify(public Public, hash *hash.Hash, pl *pool.Pool) bool {
 	return true
 }
 
-func challenge(hash *hash.Hash, n *saferith.Modulus, w *big.Int) (es []*saferith.Nat, err error) {
-	err = hash.WriteAny(n, w)
-	es = make([]*saferith.Nat, params.StatParam)
+func challenge(hash *hash.Hash, public Public, w *big.Int) (es []*saferith.Nat, err error) {
+	if err = hash.WriteProofContext(public.Context); err != nil {
+		return nil, err
+	}
+	err = hash.WriteAny(public.N, w)
+	es = make([]*saferith.Nat, public.iterations())
 	var digest = hash.Digest()
 	for i := range es {
-		es[i] = sample.ModN(digest, n)
+		es[i] = sample.ModN(digest, public.N)
 	}
 	return
 }
+
+// Empty returns a proof ready for unmarshalling, see the zk package.
+func Empty(curve.Curve) *Proof {
+	return &Proof{}
+}
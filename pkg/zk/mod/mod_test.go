@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/arith"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
 	"github.com/taurusgroup/multi-party-sig/pkg/pool"
@@ -41,6 +42,9 @@ func TestMod(t *testing.T) {
 	require.NoError(t, cbor.Unmarshal(out2, proof3), "failed to unmarshal 2nd proof")
 
 	assert.True(t, proof3.Verify(public, hash.New(), pl))
+	proof4 := Empty(curve.Secp256k1{})
+	require.NoError(t, cbor.Unmarshal(out, proof4), "failed to unmarshal into an empty proof")
+	assert.True(t, proof4.Verify(public, hash.New(), pl))
 
 	proof.W = big.NewInt(0)
 	for idx := range proof.Responses {
@@ -50,6 +54,27 @@ func TestMod(t *testing.T) {
 	assert.False(t, proof.Verify(public, hash.New(), pl), "proof should have failed")
 }
 
+func TestModIterations(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	sk := zk.ProverPaillierSecret
+	private := Private{P: sk.P(), Q: sk.Q(), Phi: sk.Phi()}
+	public := Public{N: sk.PublicKey.N(), Iterations: 12, Insecure: true}
+	proof := NewProof(hash.New(), private, public, pl)
+	assert.Len(t, proof.Responses, 12)
+	assert.True(t, proof.Verify(public, hash.New(), pl))
+
+	// fewer iterations than params.MinStatParam are only accepted by an insecure verifier
+	public.Insecure = false
+	assert.False(t, proof.Verify(public, hash.New(), pl), "proof should have failed")
+	assert.False(t, proof.IsValid(public), "proof should be invalid")
+
+	// a verifier expecting the default number of iterations must reject the shorter proof
+	assert.False(t, proof.Verify(Public{N: public.N}, hash.New(), pl), "proof should have failed")
+	assert.False(t, proof.IsValid(Public{N: public.N}), "proof should be invalid")
+}
+
 func Test_set4thRoot(t *testing.T) {
 	var p, q uint64 = 311, 331
 	pMod := saferith.ModulusFromUint64(p)
@@ -83,7 +108,7 @@ func Test_hashFix(t *testing.T) {
 	N := zk.ProverPaillierSecret.N()
 	w := sample.QNR(rand.Reader, N).Big()
 	h := hash.New()
-	es, err := challenge(h, N, w)
+	es, err := challenge(h, Public{N: N}, w)
 	assert.NoError(t, err, "failed to compute challenge")
 
 	allEqual := true
@@ -107,7 +132,7 @@ func BenchmarkCRT(b *testing.B) {
 	ped, _ := sk.GeneratePedersen()
 
 	public := Public{
-		ped.N(),
+		N: ped.N(),
 	}
 
 	private := Private{
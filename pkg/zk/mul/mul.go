@@ -1,14 +1,13 @@
 package zkmul
 
 import (
-	"crypto/rand"
-
 	"github.com/cronokirby/saferith"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/arith"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 )
 
 type Public struct {
@@ -72,9 +71,9 @@ func NewProof(group curve.Curve, hash *hash.Hash, public Public, private Private
 
 	prover := public.Prover
 
-	alpha := sample.IntervalLEps(rand.Reader)
-	r := sample.UnitModN(rand.Reader, N)
-	s := sample.UnitModN(rand.Reader, N)
+	alpha := sample.IntervalLEps(platform.Reader)
+	r := sample.UnitModN(platform.Reader, N)
+	s := sample.UnitModN(platform.Reader, N)
 
 	A := public.Y.Clone().Mul(prover, alpha)
 	A.Randomize(prover, r)
@@ -149,3 +148,8 @@ func challenge(hash *hash.Hash, group curve.Curve, public Public, commitment *Co
 	e = sample.IntervalScalar(hash.Digest(), group)
 	return
 }
+
+// Empty returns a proof ready for unmarshalling, see the zk package.
+func Empty(curve.Curve) *Proof {
+	return &Proof{Commitment: &Commitment{}}
+}
@@ -51,4 +51,7 @@ func TestMul(t *testing.T) {
 	require.NoError(t, cbor.Unmarshal(out2, proof3), "failed to unmarshal 2nd proof")
 
 	assert.True(t, proof3.Verify(group, hash.New(), public))
+	proof4 := Empty(group)
+	require.NoError(t, cbor.Unmarshal(out, proof4), "failed to unmarshal into an empty proof")
+	assert.True(t, proof4.Verify(group, hash.New(), public))
 }
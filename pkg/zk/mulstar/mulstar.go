@@ -1,8 +1,6 @@
 package zkmulstar
 
 import (
-	"crypto/rand"
-
 	"github.com/cronokirby/saferith"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/arith"
@@ -10,6 +8,7 @@ import (
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
 	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 )
 
 type Public struct {
@@ -79,12 +78,12 @@ func NewProof(group curve.Curve, hash *hash.Hash, public Public, private Private
 
 	verifier := public.Verifier
 
-	alpha := sample.IntervalLEps(rand.Reader)
+	alpha := sample.IntervalLEps(platform.Reader)
 
-	r := sample.UnitModN(rand.Reader, N0)
+	r := sample.UnitModN(platform.Reader, N0)
 
-	gamma := sample.IntervalLEpsN(rand.Reader)
-	m := sample.IntervalLEpsN(rand.Reader)
+	gamma := sample.IntervalLEpsN(platform.Reader)
+	m := sample.IntervalLEpsN(platform.Reader)
 
 	A := public.C.Clone().Mul(verifier, alpha)
 	A.Randomize(verifier, r)
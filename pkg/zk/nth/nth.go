@@ -3,13 +3,13 @@
 package zknth
 
 import (
-	"crypto/rand"
-
 	"github.com/cronokirby/saferith"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/arith"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 )
 
 type Public struct {
@@ -52,7 +52,7 @@ func (p *Proof) IsValid(public Public) bool {
 func NewProof(hash *hash.Hash, public Public, private Private) *Proof {
 	N := public.N.N()
 	// α ← ℤₙˣ
-	alpha := sample.UnitModN(rand.Reader, N)
+	alpha := sample.UnitModN(platform.Reader, N)
 	// A = αⁿ (mod n²)
 	A := public.N.ModulusSquared().Exp(alpha, N.Nat())
 	commitment := Commitment{
@@ -93,3 +93,8 @@ func challenge(hash *hash.Hash, public Public, commitment Commitment) (e *saferi
 	e = sample.IntervalL(hash.Digest())
 	return
 }
+
+// Empty returns a proof ready for unmarshalling, see the zk package.
+func Empty(curve.Curve) *Proof {
+	return &Proof{}
+}
@@ -1,7 +1,6 @@
 package zkprm
 
 import (
-	"crypto/rand"
 	"io"
 	"math/big"
 
@@ -9,20 +8,46 @@ import (
 	"github.com/taurusgroup/multi-party-sig/internal/params"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/arith"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 	"github.com/taurusgroup/multi-party-sig/pkg/pool"
 )
 
 type Public struct {
 	Aux *pedersen.Parameters
+	// Iterations is the number of Fiat-Shamir iterations performed by the prover,
+	// and expected by the verifier.
+	// If zero, params.StatParam is used.
+	// The verifier rejects proofs with fewer than params.MinStatParam iterations, unless Insecure is set.
+	Iterations int
+	// Insecure allows Iterations to be below params.MinStatParam, for dry runs and tests only.
+	Insecure bool
+	// Context binds the challenge to the session and the prover.
+	// It is only used, and then required, if the hash uses hash.ChallengeV3.
+	Context *hash.ProofContext
 }
+
+// iterations returns the number of repetitions of the proof, defaulting to params.StatParam.
+func (p Public) iterations() int {
+	if p.Iterations <= 0 {
+		return params.StatParam
+	}
+	return p.Iterations
+}
+
+// secure returns true if the verifier accepts the number of iterations of the proof.
+func (p Public) secure() bool {
+	return p.Insecure || p.iterations() >= params.MinStatParam
+}
+
 type Private struct {
 	Lambda, Phi, P, Q *saferith.Nat
 }
 
 type Proof struct {
-	As, Zs [params.StatParam]*big.Int
+	As, Zs []*big.Int
 }
 
 func (p *Proof) IsValid(public Public) bool {
@@ -30,7 +55,14 @@ func (p *Proof) IsValid(public Public) bool {
 		return false
 	}
 
-	if !arith.IsValidBigModN(public.Aux.N().Big(), append(p.As[:], p.Zs[:]...)...) {
+	if !public.secure() {
+		return false
+	}
+	if iterations := public.iterations(); len(p.As) != iterations || len(p.Zs) != iterations {
+		return false
+	}
+
+	if !arith.IsValidBigModN(public.Aux.N().Big(), append(p.As[:len(p.As):len(p.As)], p.Zs...)...) {
 		return false
 	}
 	return true
@@ -44,12 +76,11 @@ func NewProof(private Private, hash *hash.Hash, public Public, pl *pool.Pool) *P
 
 	n := arith.ModulusFromFactors(private.P, private.Q)
 
-	var (
-		as [params.StatParam]*saferith.Nat
-		As [params.StatParam]*big.Int
-	)
-	lockedRand := pool.NewLockedReader(rand.Reader)
-	pl.Parallelize(params.StatParam, func(i int) interface{} {
+	iterations := public.iterations()
+	as := make([]*saferith.Nat, iterations)
+	As := make([]*big.Int, iterations)
+	lockedRand := pool.NewLockedReader(platform.Reader)
+	pl.Parallelize(iterations, func(i int) interface{} {
 		// aᵢ ∈ mod ϕ(N)
 		as[i] = sample.ModN(lockedRand, phi)
 
@@ -61,8 +92,8 @@ func NewProof(private Private, hash *hash.Hash, public Public, pl *pool.Pool) *P
 
 	es, _ := challenge(hash, public, As)
 	// Modular addition is not expensive enough to warrant parallelizing
-	var Zs [params.StatParam]*big.Int
-	for i := 0; i < params.StatParam; i++ {
+	Zs := make([]*big.Int, iterations)
+	for i := 0; i < iterations; i++ {
 		z := as[i]
 		// The challenge is public, so branching is ok
 		if es[i] {
@@ -85,6 +116,14 @@ func (p *Proof) Verify(public Public, hash *hash.Hash, pl *pool.Pool) bool {
 		return false
 	}
 
+	if !public.secure() {
+		return false
+	}
+	iterations := public.iterations()
+	if len(p.As) != iterations || len(p.Zs) != iterations {
+		return false
+	}
+
 	n, s, t := public.Aux.N().Big(), public.Aux.S().Big(), public.Aux.T().Big()
 
 	es, err := challenge(hash, public, p.As)
@@ -93,7 +132,7 @@ func (p *Proof) Verify(public Public, hash *hash.Hash, pl *pool.Pool) bool {
 	}
 
 	one := big.NewInt(1)
-	verifications := pl.Parallelize(params.StatParam, func(i int) interface{} {
+	verifications := pl.Parallelize(iterations, func(i int) interface{} {
 		var lhs, rhs big.Int
 		z := p.Zs[i]
 		a := p.As[i]
@@ -129,16 +168,19 @@ func (p *Proof) Verify(public Public, hash *hash.Hash, pl *pool.Pool) bool {
 	return true
 }
 
-func challenge(hash *hash.Hash, public Public, A [params.StatParam]*big.Int) (es []bool, err error) {
+func challenge(hash *hash.Hash, public Public, A []*big.Int) (es []bool, err error) {
+	if err = hash.WriteProofContext(public.Context); err != nil {
+		return nil, err
+	}
 	err = hash.WriteAny(public.Aux)
 	for _, a := range A {
 		_ = hash.WriteAny(a)
 	}
 
-	tmpBytes := make([]byte, params.StatParam)
+	tmpBytes := make([]byte, len(A))
 	_, _ = io.ReadFull(hash.Digest(), tmpBytes)
 
-	es = make([]bool, params.StatParam)
+	es = make([]bool, len(A))
 	for i := range es {
 		b := (tmpBytes[i] & 1) == 1
 		es[i] = b
@@ -146,3 +188,8 @@ func challenge(hash *hash.Hash, public Public, A [params.StatParam]*big.Int) (es
 
 	return
 }
+
+// Empty returns a proof ready for unmarshalling, see the zk package.
+func Empty(curve.Curve) *Proof {
+	return &Proof{}
+}
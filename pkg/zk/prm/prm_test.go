@@ -7,8 +7,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
 	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/pkg/zk"
 )
 
 func TestPrm(t *testing.T) {
@@ -40,6 +42,33 @@ func TestPrm(t *testing.T) {
 	require.NoError(t, cbor.Unmarshal(out2, proof3), "failed to unmarshal 2nd proof")
 
 	assert.True(t, proof3.Verify(public, hash.New(), pl))
+	proof4 := Empty(curve.Secp256k1{})
+	require.NoError(t, cbor.Unmarshal(out, proof4), "failed to unmarshal into an empty proof")
+	assert.True(t, proof4.Verify(public, hash.New(), pl))
+}
+
+func TestPrmIterations(t *testing.T) {
+	sk := zk.ProverPaillierSecret
+	ped, lambda := sk.GeneratePedersen()
+
+	public := Public{Aux: ped, Iterations: 12, Insecure: true}
+	proof := NewProof(Private{
+		Lambda: lambda,
+		Phi:    sk.Phi(),
+		P:      sk.P(),
+		Q:      sk.Q(),
+	}, hash.New(), public, nil)
+	assert.Len(t, proof.As, 12)
+	assert.True(t, proof.Verify(public, hash.New(), nil))
+
+	// fewer iterations than params.MinStatParam are only accepted by an insecure verifier
+	public.Insecure = false
+	assert.False(t, proof.Verify(public, hash.New(), nil), "proof should have failed")
+	assert.False(t, proof.IsValid(public), "proof should be invalid")
+
+	// a verifier expecting the default number of iterations must reject the shorter proof
+	assert.False(t, proof.Verify(Public{Aux: ped}, hash.New(), nil), "proof should have failed")
+	assert.False(t, proof.IsValid(Public{Aux: ped}), "proof should be invalid")
 }
 
 var p *Proof
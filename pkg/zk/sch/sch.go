@@ -1,12 +1,12 @@
 package zksch
 
 import (
-	"crypto/rand"
 	"io"
 
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 )
 
 // Randomness = a ← ℤₚ.
@@ -35,7 +35,7 @@ type Proof struct {
 func NewProof(hash *hash.Hash, public curve.Point, private curve.Scalar, gen curve.Point) *Proof {
 	group := private.Curve()
 
-	a := NewRandomness(rand.Reader, group, gen)
+	a := NewRandomness(platform.Reader, group, gen)
 	z := a.Prove(hash, public, private, gen)
 	return &Proof{
 		C: *a.Commitment(),
@@ -58,7 +58,7 @@ func NewRandomness(rand io.Reader, group curve.Curve, gen curve.Point) *Randomne
 
 func challenge(hash *hash.Hash, group curve.Curve, commitment *Commitment, public, gen curve.Point) (e curve.Scalar, err error) {
 	err = hash.WriteAny(commitment.C, public, gen)
-	e = sample.Scalar(hash.Digest(), group)
+	e = sample.Challenge(hash.Digest(), group, hash.WideChallenges())
 	return
 }
 
@@ -43,3 +43,24 @@ func TestSchFail(t *testing.T) {
 	proof := a.Prove(hash.New(), X, x, nil)
 	assert.False(t, proof.Verify(hash.New(), X, a.Commitment(), nil), "proof should not accept identity point")
 }
+
+func TestSchChallengeVersion(t *testing.T) {
+	group := curve.Secp256k1{}
+	newHash := func(version hash.ChallengeVersion) *hash.Hash {
+		h := hash.New()
+		h.SetChallengeVersion(version)
+		return h
+	}
+
+	a := NewRandomness(rand.Reader, group, nil)
+	x, X := sample.ScalarPointPair(rand.Reader, group)
+
+	proof := a.Prove(newHash(hash.ChallengeV2), X, x, nil)
+	assert.True(t, proof.Verify(newHash(hash.ChallengeV2), X, a.Commitment(), nil))
+	assert.True(t, proof.Verify(newHash(hash.ChallengeV2).Clone(), X, a.Commitment(), nil), "clones should keep the version")
+	assert.False(t, proof.Verify(newHash(hash.ChallengeV1), X, a.Commitment(), nil), "versions should not be interchangeable")
+
+	// the default version is unchanged
+	proof = a.Prove(newHash(hash.ChallengeV1), X, x, nil)
+	assert.True(t, proof.Verify(hash.New(), X, a.Commitment(), nil))
+}
@@ -0,0 +1,132 @@
+// Package capabilities implements a single round protocol in which the parties of a session announce the optional
+// features they support, so that heterogeneous nodes agree on the features used by the following protocols,
+// instead of failing mid-protocol when one of them emits a format the others cannot parse.
+package capabilities
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"strings"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+)
+
+// ProtocolID is the protocol ID of the negotiation.
+const ProtocolID = "capabilities"
+
+// Rounds is the number of rounds of the protocol.
+const Rounds round.Number = 2
+
+// Capabilities is a set of optional features.
+// Bits which are unknown to this version are kept, so that newer nodes can negotiate features among themselves.
+type Capabilities uint64
+
+const (
+	// CanonicalCodec encodes round contents canonically, see protocol.HandlerOptions.DeterministicOutput.
+	CanonicalCodec Capabilities = 1 << iota
+	// Compression is the compression of messages by the transport. It is only negotiated, and must be applied by the transport.
+	Compression
+	// StrictVerification verifies P2P messages once all broadcasts of a round are stored, see protocol.OrderBroadcastFirst.
+	StrictVerification
+	// BatchVerify is the authentication of the messages of a round with a single signature over their protocol.BatchRoot.
+	// It is only negotiated, and must be applied by the transport.
+	BatchVerify
+	// BroadcastHashV2 and BroadcastHashV3 are the versions of the broadcast hash, see protocol.BroadcastHashVersion.
+	BroadcastHashV2
+	BroadcastHashV3
+)
+
+// Local is the set of features implemented by the handlers of this version.
+// Transports which implement Compression or BatchVerify should add them.
+const Local = CanonicalCodec | StrictVerification | BroadcastHashV2 | BroadcastHashV3
+
+var names = []string{"canonical-codec", "compression", "strict-verification", "batch-verify", "broadcast-hash-v2", "broadcast-hash-v3"}
+
+// Has returns true if all features of other are in c.
+func (c Capabilities) Has(other Capabilities) bool {
+	return c&other == other
+}
+
+// bytes returns the big endian encoding of c.
+func (c Capabilities) bytes() []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(c))
+}
+
+// String returns the names of the features in c, separated by "|".
+func (c Capabilities) String() string {
+	if c == 0 {
+		return "none"
+	}
+	var out []string
+	for c != 0 {
+		i := bits.TrailingZeros64(uint64(c))
+		if i < len(names) {
+			out = append(out, names[i])
+		} else {
+			out = append(out, fmt.Sprintf("bit%d", i))
+		}
+		c &^= 1 << i
+	}
+	return strings.Join(out, "|")
+}
+
+// HandlerOptions returns opts with the features of c which are implemented by the handler,
+// so that all parties create the handlers of the following protocols with the same options.
+func (c Capabilities) HandlerOptions(opts protocol.HandlerOptions) protocol.HandlerOptions {
+	opts.DeterministicOutput = c.Has(CanonicalCodec)
+	opts.MessageOrdering = protocol.OrderPerSender
+	if c.Has(StrictVerification) {
+		opts.MessageOrdering = protocol.OrderBroadcastFirst
+	}
+	switch {
+	case c.Has(BroadcastHashV3):
+		opts.BroadcastHashVersion = protocol.BroadcastHashV3
+	case c.Has(BroadcastHashV2):
+		opts.BroadcastHashVersion = protocol.BroadcastHashV2
+	default:
+		opts.BroadcastHashVersion = protocol.BroadcastHashV1
+	}
+	return opts
+}
+
+// Agreement is the output of the protocol.
+type Agreement struct {
+	// Capabilities are the features supported by all parties.
+	Capabilities Capabilities
+	// Supported contains the features announced by each party.
+	Supported map[party.ID]Capabilities
+	// SessionID is derived from the features announced by all parties, and should be given to the handler of
+	// the following protocol, so that parties with different views of the negotiation cannot take part in the same session.
+	SessionID []byte
+}
+
+// Start returns the StartFunc of the negotiation, in which selfID announces supported, and requires the features
+// of required to be supported by all parties.
+//
+// Returns *Agreement if successful. Otherwise, the protocol aborts with the parties which do not support
+// a feature required by another party as culprits.
+func Start(selfID party.ID, partyIDs []party.ID, supported, required Capabilities) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		if !supported.Has(required) {
+			return nil, fmt.Errorf("capabilities: required features %s are not supported", required&^supported)
+		}
+		info := round.Info{
+			ProtocolID:       ProtocolID,
+			FinalRoundNumber: Rounds,
+			SelfID:           selfID,
+			PartyIDs:         partyIDs,
+		}
+		helper, err := round.NewSession(info, sessionID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("capabilities: %w", err)
+		}
+		return &round1{
+			Helper:    helper,
+			supported: supported,
+			required:  required,
+		}, nil
+	}
+}
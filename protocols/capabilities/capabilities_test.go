@@ -0,0 +1,86 @@
+package capabilities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+)
+
+func run(t *testing.T, partyIDs party.IDSlice, supported, required map[party.ID]Capabilities) []round.Session {
+	rounds := make([]round.Session, 0, len(partyIDs))
+	for _, id := range partyIDs {
+		r, err := Start(id, partyIDs, supported[id], required[id])([]byte("session"))
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	for {
+		err, done := test.Rounds(rounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	return rounds
+}
+
+func TestCapabilities(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	newer := Local | Compression | BatchVerify
+	older := CanonicalCodec | BroadcastHashV2
+
+	t.Run("agree", func(t *testing.T) {
+		supported := map[party.ID]Capabilities{partyIDs[0]: newer, partyIDs[1]: Local, partyIDs[2]: older}
+		rounds := run(t, partyIDs, supported, map[party.ID]Capabilities{partyIDs[0]: CanonicalCodec})
+		var sessionID []byte
+		for _, r := range rounds {
+			require.IsType(t, &round.Output{}, r)
+			agreement := r.(*round.Output).Result.(*Agreement)
+			assert.Equal(t, older, agreement.Capabilities)
+			assert.Equal(t, supported, agreement.Supported)
+			if sessionID == nil {
+				sessionID = agreement.SessionID
+			}
+			assert.Equal(t, sessionID, agreement.SessionID)
+		}
+
+		// a different announcement yields a different session
+		supported[partyIDs[2]] = Local
+		r := run(t, partyIDs, supported, nil)[0].(*round.Output).Result.(*Agreement)
+		assert.Equal(t, Local, r.Capabilities)
+		assert.NotEqual(t, sessionID, r.SessionID)
+	})
+
+	t.Run("unsupported requirement", func(t *testing.T) {
+		supported := map[party.ID]Capabilities{partyIDs[0]: newer, partyIDs[1]: newer, partyIDs[2]: older}
+		rounds := run(t, partyIDs, supported, map[party.ID]Capabilities{partyIDs[1]: BatchVerify})
+		for _, r := range rounds {
+			require.IsType(t, &round.Abort{}, r)
+			assert.Equal(t, []party.ID{partyIDs[2]}, r.(*round.Abort).Culprits)
+		}
+	})
+
+	t.Run("local requirement", func(t *testing.T) {
+		_, err := Start(partyIDs[0], partyIDs, older, StrictVerification)([]byte("session"))
+		assert.Error(t, err)
+	})
+}
+
+func TestHandlerOptions(t *testing.T) {
+	opts := Local.HandlerOptions(protocol.HandlerOptions{UnicastBroadcast: true})
+	assert.True(t, opts.UnicastBroadcast)
+	assert.True(t, opts.DeterministicOutput)
+	assert.Equal(t, protocol.OrderBroadcastFirst, opts.MessageOrdering)
+	assert.Equal(t, protocol.BroadcastHashV3, opts.BroadcastHashVersion)
+
+	opts = (CanonicalCodec | BroadcastHashV2).HandlerOptions(opts)
+	assert.Equal(t, protocol.OrderPerSender, opts.MessageOrdering)
+	assert.Equal(t, protocol.BroadcastHashV2, opts.BroadcastHashVersion)
+
+	assert.Equal(t, "canonical-codec|batch-verify|bit40", (CanonicalCodec | BatchVerify | 1<<40).String())
+	assert.Equal(t, "none", Capabilities(0).String())
+}
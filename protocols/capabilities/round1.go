@@ -0,0 +1,41 @@
+package capabilities
+
+import (
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+var _ round.Round = (*round1)(nil)
+
+type round1 struct {
+	*round.Helper
+
+	supported Capabilities
+	required  Capabilities
+}
+
+// VerifyMessage implements round.Round.
+func (round1) VerifyMessage(round.Message) error { return nil }
+
+// StoreMessage implements round.Round.
+func (round1) StoreMessage(round.Message) error { return nil }
+
+// Finalize implements round.Round
+//
+// - broadcast the supported and required features.
+func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
+	if err := r.BroadcastMessage(out, &broadcast2{Supported: r.supported, Required: r.required}); err != nil {
+		return r, err
+	}
+	return &round2{
+		round1:    r,
+		supported: map[party.ID]Capabilities{r.SelfID(): r.supported},
+		required:  map[party.ID]Capabilities{r.SelfID(): r.required},
+	}, nil
+}
+
+// MessageContent implements round.Round.
+func (round1) MessageContent() round.Content { return nil }
+
+// Number implements round.Round.
+func (round1) Number() round.Number { return 1 }
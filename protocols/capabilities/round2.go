@@ -0,0 +1,92 @@
+package capabilities
+
+import (
+	"fmt"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+var _ round.Round = (*round2)(nil)
+
+type round2 struct {
+	*round1
+
+	supported map[party.ID]Capabilities
+	required  map[party.ID]Capabilities
+}
+
+type broadcast2 struct {
+	round.ReliableBroadcastContent
+	Supported Capabilities
+	Required  Capabilities
+}
+
+// StoreBroadcastMessage implements round.BroadcastRound.
+//
+// - store the features announced by the sender, and check that it supports the features it requires.
+func (r *round2) StoreBroadcastMessage(msg round.Message) error {
+	body, ok := msg.Content.(*broadcast2)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+	if !body.Supported.Has(body.Required) {
+		return fmt.Errorf("required features %s are not supported", body.Required&^body.Supported)
+	}
+	r.supported[msg.From] = body.Supported
+	r.required[msg.From] = body.Required
+	return nil
+}
+
+// VerifyMessage implements round.Round.
+func (round2) VerifyMessage(round.Message) error { return nil }
+
+// StoreMessage implements round.Round.
+func (round2) StoreMessage(round.Message) error { return nil }
+
+// Finalize implements round.Round
+//
+// - compute the features supported by all parties, and abort if a party requires a feature which is not in them.
+// - derive the session ID from the features announced by all parties.
+func (r *round2) Finalize(chan<- *round.Message) (round.Session, error) {
+	agreed, required := ^Capabilities(0), Capabilities(0)
+	for _, id := range r.PartyIDs() {
+		agreed &= r.supported[id]
+		required |= r.required[id]
+	}
+	if missing := required &^ agreed; missing != 0 {
+		var culprits []party.ID
+		for _, id := range r.PartyIDs() {
+			if !r.supported[id].Has(required) {
+				culprits = append(culprits, id)
+			}
+		}
+		return r.AbortRound(fmt.Errorf("required features %s are not supported by all parties", missing), culprits...), nil
+	}
+
+	h := r.Hash()
+	for _, id := range r.PartyIDs() {
+		_ = h.WriteAny(id,
+			&hash.BytesWithDomain{TheDomain: "Supported", Bytes: r.supported[id].bytes()},
+			&hash.BytesWithDomain{TheDomain: "Required", Bytes: r.required[id].bytes()},
+		)
+	}
+	return r.ResultRound(&Agreement{
+		Capabilities: agreed,
+		Supported:    r.supported,
+		SessionID:    h.Sum()[:32],
+	}), nil
+}
+
+// RoundNumber implements round.Content.
+func (broadcast2) RoundNumber() round.Number { return 2 }
+
+// BroadcastContent implements round.BroadcastRound.
+func (round2) BroadcastContent() round.BroadcastContent { return &broadcast2{} }
+
+// MessageContent implements round.Round.
+func (round2) MessageContent() round.Content { return nil }
+
+// Number implements round.Round.
+func (round2) Number() round.Number { return 2 }
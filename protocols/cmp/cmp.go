@@ -1,6 +1,12 @@
 package cmp
 
 import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/taurusgroup/multi-party-sig/internal/params"
 	"github.com/taurusgroup/multi-party-sig/internal/round"
 	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
@@ -10,6 +16,7 @@ import (
 	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
 	"github.com/taurusgroup/multi-party-sig/protocols/cmp/keygen"
 	"github.com/taurusgroup/multi-party-sig/protocols/cmp/presign"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/proposal"
 	"github.com/taurusgroup/multi-party-sig/protocols/cmp/sign"
 )
 
@@ -27,12 +34,48 @@ func EmptyConfig(group curve.Curve) *Config {
 	}
 }
 
+func init() {
+	for _, protocolID := range []string{"cmp/keygen-threshold", "cmp/refresh-threshold", "cmp/join-threshold"} {
+		protocolID := protocolID
+		protocol.RegisterDescription(protocolID, func() *protocol.Description { return keygen.Describe(protocolID) })
+	}
+}
+
 // Keygen generates a new shared ECDSA key over the curve defined by `group`. After a successful execution,
 // all participants posses a unique share of this key, as well as auxiliary parameters required during signing.
 //
 // For better performance, a `pool.Pool` can be provided in order to parallelize certain steps of the protocol.
 // Returns *cmp.Config if successful.
 func Keygen(group curve.Curve, selfID party.ID, participants []party.ID, threshold int, pl *pool.Pool) protocol.StartFunc {
+	return KeygenWithStatParam(group, selfID, participants, threshold, 0, pl)
+}
+
+// KeygenWithStatParam is the same as Keygen, but sets the number of iterations used by the
+// zkmod and zkprm proofs. This allows increasing the statistical soundness of the proofs
+// at the cost of their size and verification time, and all participants must use the same value.
+// If statParam is 0, the default value is used. Values below params.MinStatParam are rejected.
+func KeygenWithStatParam(group curve.Curve, selfID party.ID, participants []party.ID, threshold, statParam int, pl *pool.Pool) protocol.StartFunc {
+	info := round.Info{
+		ProtocolID:       "cmp/keygen-threshold",
+		FinalRoundNumber: keygen.Rounds,
+		SelfID:           selfID,
+		PartyIDs:         participants,
+		Threshold:        threshold,
+		Group:            group,
+		StatParam:        statParam,
+	}
+	return keygen.Start(info, pl, nil)
+}
+
+// SecurityProfile sets the length of commitment randomness, RIDs and digests, see KeygenWithSecurityProfile.
+type SecurityProfile = params.SecurityProfile
+
+// DefaultSecurityProfile is the profile used by Keygen.
+var DefaultSecurityProfile = params.DefaultSecurityProfile
+
+// KeygenWithSecurityProfile is the same as Keygen, but uses the lengths given by profile for the commitments,
+// the RID and the digests exchanged during the protocol. All participants must use the same profile.
+func KeygenWithSecurityProfile(group curve.Curve, selfID party.ID, participants []party.ID, threshold int, profile SecurityProfile, pl *pool.Pool) protocol.StartFunc {
 	info := round.Info{
 		ProtocolID:       "cmp/keygen-threshold",
 		FinalRoundNumber: keygen.Rounds,
@@ -40,14 +83,95 @@ func Keygen(group curve.Curve, selfID party.ID, participants []party.ID, thresho
 		PartyIDs:         participants,
 		Threshold:        threshold,
 		Group:            group,
+		SecurityProfile:  profile,
 	}
 	return keygen.Start(info, pl, nil)
 }
 
+// KeygenBatch runs count independent executions of Keygen in a single session.
+// The executions share the same rounds and message flights, so that generating many keys
+// only costs the latency of a single one. Each key is generated with its own polynomial,
+// RID, chain key and auxiliary Paillier/Pedersen parameters, so that the resulting configs
+// are independent and can be refreshed separately.
+//
+// The Paillier/Pedersen setup, and its zkmod and zkprm proofs, are deliberately not shared between the keys.
+// The Paillier key of a party encrypts its nonce shares kᵢ and γᵢ in every presignature, so with a shared key,
+// the leak of any single config, which holds the secret, would expose these nonces for all the keys of the batch.
+// A refresh of one of the configs would also leave the others with the key it was meant to replace.
+//
+// Returns []*cmp.Config of length count if successful.
+func KeygenBatch(group curve.Curve, selfID party.ID, participants []party.ID, threshold, count int, pl *pool.Pool) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		if count <= 0 {
+			return nil, fmt.Errorf("keygen batch: count %d must be positive", count)
+		}
+		info := round.Info{
+			ProtocolID:       "cmp/keygen-threshold-batch",
+			FinalRoundNumber: keygen.Rounds,
+			SelfID:           selfID,
+			PartyIDs:         participants,
+			Threshold:        threshold,
+			Group:            group,
+		}
+		helper, err := round.NewSession(info, sessionID, pl, batchSize(count))
+		if err != nil {
+			return nil, fmt.Errorf("keygen batch: %w", err)
+		}
+		info.ProtocolID = "cmp/keygen-threshold"
+		sessions := make([]round.Session, count)
+		for i := range sessions {
+			sessions[i], err = keygen.Start(info, pl, nil)(round.BatchSessionID(helper.SSID(), i))
+			if err != nil {
+				return nil, err
+			}
+		}
+		return round.NewBatch(helper, sessions, func(results []interface{}) interface{} {
+			configs := make([]*Config, len(results))
+			for i, result := range results {
+				configs[i] = result.(*Config)
+			}
+			return configs
+		})
+	}
+}
+
+// batchSize binds the number of keys in a batch to the session.
+type batchSize uint32
+
+// WriteTo implements io.WriterTo.
+func (b batchSize) WriteTo(w io.Writer) (int64, error) {
+	buf := make([]byte, 4)
+	binary.BigEndian.PutUint32(buf, uint32(b))
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+// Domain implements hash.WriterToWithDomain.
+func (batchSize) Domain() string { return "Batch Size" }
+
+// DealerKeygen creates the configs of all parties for the given secret key locally, without running Keygen.
+// The machine running it learns all shares, so it is only meant for tests, and for migrating a key held by a single
+// party in a controlled ceremony. The configs are marked with Config.Dealt, see IsDealerKeygen, and should be refreshed
+// once they have been distributed.
+func DealerKeygen(secret curve.Scalar, parties []party.ID, threshold int, pl *pool.Pool) (map[party.ID]*Config, error) {
+	return keygen.Dealer(secret, parties, threshold, pl)
+}
+
+// IsDealerKeygen returns true if c was created by DealerKeygen and has not been refreshed since.
+func IsDealerKeygen(c *Config) bool {
+	return keygen.IsDealer(c)
+}
+
 // Refresh allows the parties to refresh all existing cryptographic keys from a previously generated Config.
 // The group's ECDSA public key remains the same, but any previous shares are rendered useless.
 // Returns *cmp.Config if successful.
 func Refresh(config *Config, pl *pool.Pool) protocol.StartFunc {
+	return RefreshWithStatParam(config, 0, pl)
+}
+
+// RefreshWithStatParam is the same as Refresh, but sets the number of iterations used by the
+// zkmod and zkprm proofs, see KeygenWithStatParam.
+func RefreshWithStatParam(config *Config, statParam int, pl *pool.Pool) protocol.StartFunc {
 	info := round.Info{
 		ProtocolID:       "cmp/refresh-threshold",
 		FinalRoundNumber: keygen.Rounds,
@@ -55,16 +179,112 @@ func Refresh(config *Config, pl *pool.Pool) protocol.StartFunc {
 		PartyIDs:         config.PartyIDs(),
 		Threshold:        config.Threshold,
 		Group:            config.Group,
+		StatParam:        statParam,
+		Epoch:            config.Epoch,
 	}
 	return keygen.Start(info, pl, config)
 }
 
+// Join gives a share of the key of config to newcomer, one of the parties which were invited to the Keygen
+// but did not take part in it, see KeygenOptions.Invited. All parties of config take part, along with newcomer,
+// which uses JoinAsNewParty. The public key and chain key remain the same, but all previous shares are replaced,
+// as in a Refresh.
+// Returns *cmp.Config if successful.
+func Join(config *Config, newcomer party.ID, pl *pool.Pool) protocol.StartFunc {
+	return keygen.StartJoin(joinInfo(config.ID, config.PartyIDs(), newcomer), pl, config)
+}
+
+// JoinAsNewParty is the same as Join, for the party selfID which joins the key.
+// The public config must be obtained from the other parties, and is checked to be the same as theirs.
+func JoinAsNewParty(public *config.PublicConfig, selfID party.ID, pl *pool.Pool) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		if public == nil {
+			return nil, errors.New("cmp: public config is nil")
+		}
+		return keygen.StartJoinAsNewParty(joinInfo(selfID, public.PartyIDs(), selfID), pl, public)(sessionID)
+	}
+}
+
+func joinInfo(selfID party.ID, previous party.IDSlice, newcomer party.ID) round.Info {
+	return round.Info{
+		ProtocolID:       "cmp/join-threshold",
+		FinalRoundNumber: keygen.Rounds,
+		SelfID:           selfID,
+		PartyIDs:         append(previous.Copy(), newcomer),
+	}
+}
+
+// RecoverConfig returns the config computed by a Keygen or Refresh handler which reached the last round,
+// but did not complete, for example because it aborted after the final message of another party was lost.
+// The config should be checked with AuditConfig before it is used, see keygen.RecoverConfig.
+func RecoverConfig(h *protocol.MultiHandler) (*Config, error) {
+	if h == nil {
+		return nil, errors.New("cmp: handler is nil")
+	}
+	return keygen.RecoverConfig(h.CurrentRound())
+}
+
+// AuditConfig checks that config is consistent, and that it was produced by the same Keygen or Refresh
+// as the public configs of the other parties, see keygen.AuditConfig.
+func AuditConfig(config *Config, others ...*config.PublicConfig) error {
+	return keygen.AuditConfig(config, others...)
+}
+
 // Sign generates an ECDSA signature for `messageHash` among the given `signers`.
+// The message hash must be a digest of the length given by ecdsa.DigestLength, 32 bytes for secp256k1,
+// otherwise an *ecdsa.DigestLengthError is returned. See SignMessage to sign a message which is not hashed yet.
 // Returns *ecdsa.Signature if successful.
 func Sign(config *Config, signers []party.ID, messageHash []byte, pl *pool.Pool) protocol.StartFunc {
 	return sign.StartSign(config, signers, messageHash, pl)
 }
 
+// SignMessage is the same as Sign, but first hashes `message` with the hash algorithm of `profile`.
+func SignMessage(config *Config, signers []party.ID, message []byte, profile *ecdsa.Profile, pl *pool.Pool) protocol.StartFunc {
+	if profile == nil {
+		return func([]byte) (round.Session, error) {
+			return nil, errors.New("cmp: profile is nil")
+		}
+	}
+	return sign.StartSign(config, signers, profile.HashMessage(message), pl)
+}
+
+// SigningConfirmer is called by SignWithConfirmation before any secret-dependent computation.
+type SigningConfirmer = sign.Confirmer
+
+// SignWithConfirmation is the same as Sign, but lets `confirm` inspect the message hash and the signers,
+// and refuse to sign. A refusal aborts the protocol for all signers, with this party as culprit.
+// If confirm is nil, it is equivalent to Sign.
+func SignWithConfirmation(config *Config, signers []party.ID, messageHash []byte, confirm SigningConfirmer, pl *pool.Pool) protocol.StartFunc {
+	return sign.StartSignWithConfirmation(config, signers, messageHash, confirm, pl)
+}
+
+// Policy restricts the use of a Config for signing, see SignWithPolicy.
+type Policy = config.Policy
+
+// PolicyRequest describes what a signature authorizes, and is checked against a Policy.
+type PolicyRequest = config.PolicyRequest
+
+// SignWithPolicy is the same as SignWithConfirmation, but every signer first evaluates `policy` against `request`,
+// and refuses to sign if it is not satisfied. The policy and request are bound to the session,
+// so that all signers must use the same ones.
+func SignWithPolicy(config *Config, signers []party.ID, messageHash []byte, policy *Policy, request *PolicyRequest, confirm SigningConfirmer, pl *pool.Pool) protocol.StartFunc {
+	return sign.StartSignWithPolicy(config, signers, messageHash, policy, request, confirm, pl)
+}
+
+// SignProposal contains the signers and message hash chosen by the initiator of ProposeSign.
+type SignProposal = proposal.SignProposal
+
+// SignAgreement is the result of ProposeSign.
+type SignAgreement = proposal.Agreement
+
+// ProposeSign lets the party `initiator` choose the signers and message hash of a signing session,
+// and distribute them to all parties of the config, who either accept or reject them.
+// The proposal is only read by the initiator, and `decide` is called by every party before accepting it.
+// Returns *cmp.SignAgreement if all signers accepted, which can be turned into SignOptions with SignOptionsFromAgreement.
+func ProposeSign(config *Config, initiator party.ID, p *SignProposal, decide proposal.Decide) protocol.StartFunc {
+	return proposal.Start(config, initiator, p, decide)
+}
+
 // Presign generates a preprocessed signature that does not depend on the message being signed.
 // When the message becomes available, the same participants can efficiently combine their shares
 // to produce a full signature with the PresignOnline protocol.
@@ -75,7 +295,44 @@ func Presign(config *Config, signers []party.ID, pl *pool.Pool) protocol.StartFu
 }
 
 // PresignOnline efficiently generates an ECDSA signature for `messageHash` given a preprocessed `PreSignature`.
+// As with Sign, the message hash must be a digest of the length given by ecdsa.DigestLength.
 // Returns *ecdsa.Signature if successful.
 func PresignOnline(config *Config, preSignature *ecdsa.PreSignature, messageHash []byte, pl *pool.Pool) protocol.StartFunc {
 	return presign.StartPresignOnline(config, preSignature, messageHash, pl)
 }
+
+// PresignOnlineMessage is the same as PresignOnline, but first hashes `message` with the hash algorithm of `profile`.
+func PresignOnlineMessage(config *Config, preSignature *ecdsa.PreSignature, message []byte, profile *ecdsa.Profile, pl *pool.Pool) protocol.StartFunc {
+	if profile == nil {
+		return func([]byte) (round.Session, error) {
+			return nil, errors.New("cmp: profile is nil")
+		}
+	}
+	return presign.StartPresignOnline(config, preSignature, profile.HashMessage(message), pl)
+}
+
+// AdaptPreSignature returns a presignature for child, a config derived from parent with Derive, DeriveBIP32 or DerivePath,
+// from a presignature produced with parent, so that a single pool of presignatures serves all derivation paths.
+// Its nonce is re-randomized with messageHash, which is stored in it as the only message PresignOnline accepts.
+// The returned presignature replaces preSignature, and only one of them may be used, see ecdsa.PreSignature.AdaptToChild.
+func AdaptPreSignature(parent, child *Config, preSignature *ecdsa.PreSignature, messageHash []byte) (*ecdsa.PreSignature, error) {
+	if parent == nil || child == nil || preSignature == nil {
+		return nil, errors.New("cmp: config or preSignature is nil")
+	}
+	if err := preSignature.CheckFingerprint(parent.Fingerprint()); err != nil {
+		return nil, fmt.Errorf("cmp: %w", err)
+	}
+	if parent.ID != child.ID || len(parent.Public) != len(child.Public) {
+		return nil, errors.New("cmp: child is not derived from parent")
+	}
+	// every share is adjusted by the same scalar, see Config.Derive
+	adjust := parent.Group.NewScalar().Set(child.ECDSA).Sub(parent.ECDSA)
+	adjustG := adjust.ActOnBase()
+	for id, public := range parent.Public {
+		childPublic, ok := child.Public[id]
+		if !ok || !public.ECDSA.Add(adjustG).Equal(childPublic.ECDSA) {
+			return nil, errors.New("cmp: child is not derived from parent")
+		}
+	}
+	return preSignature.AdaptToChild(adjust, messageHash, child.Fingerprint()), nil
+}
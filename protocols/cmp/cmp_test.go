@@ -25,6 +25,9 @@ func do(t *testing.T, id party.ID, ids []party.ID, threshold int, message []byte
 	require.NoError(t, err)
 	require.IsType(t, &Config{}, r)
 	c := r.(*Config)
+	decodedConfig := decodeEnvelope(t, h)
+	require.IsType(t, &Config{}, decodedConfig)
+	assert.True(t, decodedConfig.(*Config).PublicPoint().Equal(c.PublicPoint()))
 
 	h, err = protocol.NewMultiHandler(Refresh(c, pl), nil)
 	require.NoError(t, err)
@@ -44,6 +47,14 @@ func do(t *testing.T, id party.ID, ids []party.ID, threshold int, message []byte
 	require.IsType(t, &ecdsa.Signature{}, signResult)
 	signature := signResult.(*ecdsa.Signature)
 	assert.True(t, signature.Verify(c.PublicPoint(), message))
+	decodedSignature := decodeEnvelope(t, h)
+	require.IsType(t, &ecdsa.Signature{}, decodedSignature)
+	assert.True(t, decodedSignature.(*ecdsa.Signature).Verify(c.PublicPoint(), message))
+	for _, p := range []*ecdsa.Profile{ecdsa.ProfileBitcoin, ecdsa.ProfileEthereum, ecdsa.ProfileCosmos} {
+		encoded, err := p.Encode(*signature)
+		require.NoError(t, err)
+		assert.NoError(t, VerifyFinalSignature(c.PublicConfig(), message, encoded, p.Encoding), p.Name)
+	}
 
 	h, err = protocol.NewMultiHandler(Presign(c, ids, pl), nil)
 	require.NoError(t, err)
@@ -55,6 +66,9 @@ func do(t *testing.T, id party.ID, ids []party.ID, threshold int, message []byte
 	require.IsType(t, &ecdsa.PreSignature{}, signResult)
 	preSignature := signResult.(*ecdsa.PreSignature)
 	assert.NoError(t, preSignature.Validate())
+	decodedPreSignature := decodeEnvelope(t, h)
+	require.IsType(t, &ecdsa.PreSignature{}, decodedPreSignature)
+	assert.NoError(t, decodedPreSignature.(*ecdsa.PreSignature).Validate())
 
 	h, err = protocol.NewMultiHandler(PresignOnline(c, preSignature, message, pl), nil)
 	require.NoError(t, err)
@@ -65,12 +79,41 @@ func do(t *testing.T, id party.ID, ids []party.ID, threshold int, message []byte
 	require.IsType(t, &ecdsa.Signature{}, signResult)
 	signature = signResult.(*ecdsa.Signature)
 	assert.True(t, signature.Verify(c.PublicPoint(), message))
+
+	// a presignature of the parent key signs for a BIP32 child
+	h, err = protocol.NewMultiHandler(Presign(c, ids, pl), nil)
+	require.NoError(t, err)
+	test.HandlerLoop(c.ID, h, n)
+	signResult, err = h.Result()
+	require.NoError(t, err)
+	preSignature = signResult.(*ecdsa.PreSignature)
+
+	child, err := c.DeriveBIP32(7)
+	require.NoError(t, err)
+	_, err = AdaptPreSignature(child, c, preSignature, message)
+	assert.Error(t, err)
+	adapted, err := AdaptPreSignature(c, child, preSignature, message)
+	require.NoError(t, err)
+	// the adapted presignature only signs the message it was adapted for
+	other := ecdsa.ProfileEthereum.HashMessage([]byte("other"))
+	_, err = PresignOnline(child, adapted, other, pl)(nil)
+	assert.Error(t, err)
+
+	h, err = protocol.NewMultiHandler(PresignOnline(child, adapted, message, pl), nil)
+	require.NoError(t, err)
+	test.HandlerLoop(c.ID, h, n)
+
+	signResult, err = h.Result()
+	require.NoError(t, err)
+	require.IsType(t, &ecdsa.Signature{}, signResult)
+	signature = signResult.(*ecdsa.Signature)
+	assert.True(t, signature.Verify(child.PublicPoint(), message))
 }
 
 func TestCMP(t *testing.T) {
 	N := 3
 	T := N - 1
-	message := []byte("hello")
+	message := ecdsa.ProfileEthereum.HashMessage([]byte("hello"))
 
 	partyIDs := test.PartyIDs(N)
 
@@ -86,6 +129,54 @@ func TestCMP(t *testing.T) {
 	wg.Wait()
 }
 
+func TestKeygenBatch(t *testing.T) {
+	N := 2
+	T := N - 1
+	count := 2
+
+	partyIDs := test.PartyIDs(N)
+	n := test.NewNetwork(partyIDs)
+
+	results := make(map[party.ID][]*Config, N)
+	var mtx sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(N)
+	for _, id := range partyIDs {
+		go func(id party.ID) {
+			defer wg.Done()
+			pl := pool.NewPool(0)
+			defer pl.TearDown()
+			h, err := protocol.NewMultiHandler(KeygenBatch(curve.Secp256k1{}, id, partyIDs, T, count, pl), nil)
+			require.NoError(t, err)
+			test.HandlerLoop(id, h, n)
+			r, err := h.Result()
+			require.NoError(t, err)
+			require.IsType(t, []*Config{}, r)
+			mtx.Lock()
+			results[id] = r.([]*Config)
+			mtx.Unlock()
+		}(id)
+	}
+	wg.Wait()
+
+	first := results[partyIDs[0]]
+	require.Len(t, first, count)
+	for i := 0; i < count; i++ {
+		for _, id := range partyIDs[1:] {
+			assert.NoError(t, first[i].Compatible(results[id][i]))
+			assert.NoError(t, first[i].SameCeremony(results[id][i]))
+		}
+		// rounds 2 to 5 of keygen have broadcast messages
+		assert.Len(t, first[i].CeremonyLog, 4)
+	}
+	assert.Error(t, first[0].SameCeremony(first[1]), "each key of the batch has its own session")
+	assert.False(t, first[0].PublicPoint().Equal(first[1].PublicPoint()), "batch keys should be independent")
+	assert.NotEqual(t, first[0].RID, first[1].RID)
+
+	_, err := KeygenBatch(curve.Secp256k1{}, partyIDs[0], partyIDs, T, 0, nil)(nil)
+	assert.Error(t, err)
+}
+
 func TestStart(t *testing.T) {
 	group := curve.Secp256k1{}
 	N := 6
@@ -94,7 +185,7 @@ func TestStart(t *testing.T) {
 	defer pl.TearDown()
 	configs, partyIDs := test.GenerateConfig(group, N, T, rand.Reader, pl)
 
-	m := []byte("HELLO")
+	m := ecdsa.ProfileBitcoin.HashMessage([]byte("HELLO"))
 	selfID := partyIDs[0]
 	c := configs[selfID]
 	tests := []struct {
@@ -161,3 +252,34 @@ func TestStart(t *testing.T) {
 		})
 	}
 }
+
+func TestSignDigestLength(t *testing.T) {
+	group := curve.Secp256k1{}
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, partyIDs := test.GenerateConfig(group, 2, 1, rand.Reader, pl)
+	c := configs[partyIDs[0]]
+	message := []byte("hello")
+
+	var lengthErr *ecdsa.DigestLengthError
+	_, err := Sign(c, partyIDs, message, pl)(nil)
+	require.ErrorAs(t, err, &lengthErr)
+	assert.Equal(t, len(message), lengthErr.Length)
+	assert.Equal(t, 32, lengthErr.Expected)
+	_, err = Sign(c, partyIDs, make([]byte, 64), pl)(nil)
+	assert.ErrorAs(t, err, &lengthErr, "longer digests should not be truncated")
+
+	_, err = SignMessage(c, partyIDs, message, ecdsa.ProfileEthereum, pl)(nil)
+	assert.NoError(t, err)
+	_, err = SignMessage(c, partyIDs, message, nil, pl)(nil)
+	assert.Error(t, err)
+}
+
+func TestDescribe(t *testing.T) {
+	for _, protocolID := range []string{"cmp/keygen-threshold", "cmp/refresh-threshold", "cmp/sign"} {
+		d, err := protocol.Describe(protocolID)
+		require.NoError(t, err, protocolID)
+		assert.Equal(t, protocolID, d.Protocol)
+		assert.Len(t, d.Rounds, int(d.FinalRound))
+	}
+}
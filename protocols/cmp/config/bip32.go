@@ -0,0 +1,225 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/taurusgroup/multi-party-sig/internal/base58"
+	"github.com/taurusgroup/multi-party-sig/internal/bip32"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"golang.org/x/crypto/ripemd160" //nolint:staticcheck // required by BIP32 key fingerprints
+)
+
+// XPubVersion is the version prefix of mainnet extended public keys, which encode as "xpub...".
+var XPubVersion = []byte{0x04, 0x88, 0xB2, 0x1E}
+
+// extendedKeyLength is the length of a serialized extended key, without the version prefix.
+const extendedKeyLength = 1 + 4 + 4 + 32 + 33
+
+// DerivationPath is a sequence of unhardened BIP32 child indices, relative to the key of a Config.
+type DerivationPath []uint32
+
+// ParseDerivationPath parses a path of the form "m/0/1/2".
+// Hardened indices are rejected, since they cannot be derived from a threshold key.
+func ParseDerivationPath(s string) (DerivationPath, error) {
+	parts := strings.Split(s, "/")
+	if parts[0] != "m" {
+		return nil, fmt.Errorf("config: derivation path %q must start with m", s)
+	}
+	path := make(DerivationPath, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		i, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("config: invalid index %q in derivation path", part)
+		}
+		if i>>31 != 0 {
+			return nil, fmt.Errorf("config: hardened index %d in derivation path", i)
+		}
+		path = append(path, uint32(i))
+	}
+	return path, nil
+}
+
+// String returns the path in the format accepted by ParseDerivationPath.
+func (p DerivationPath) String() string {
+	var sb strings.Builder
+	sb.WriteString("m")
+	for _, i := range p {
+		sb.WriteString("/")
+		sb.WriteString(strconv.FormatUint(uint64(i), 10))
+	}
+	return sb.String()
+}
+
+// ExtendedKey is a BIP32 extended public key, whose serialization is also that of SLIP-0010 for secp256k1.
+// It allows wallets to derive the public keys of children without access to any share.
+type ExtendedKey struct {
+	// Depth is the number of derivations from the root key.
+	Depth uint8
+	// ParentFingerprint identifies the parent key, and is zero for the root key.
+	ParentFingerprint [4]byte
+	// ChildNumber is the index of this key in its parent, and is zero for the root key.
+	ChildNumber uint32
+	// ChainCode is the 32 byte chaining value, the ChainKey of the corresponding Config.
+	ChainCode []byte
+	// PublicKey is the public key of this node.
+	PublicKey curve.Point
+	// Path is the derivation path from the root key. It is not part of the serialization.
+	Path DerivationPath
+}
+
+// ExtendedKey returns the root extended public key of c.
+func (c *Config) ExtendedKey() (*ExtendedKey, error) {
+	return c.PublicConfig().ExtendedKey()
+}
+
+// DerivePath derives the config for the child at the given path, and returns it along with its extended public key.
+func (c *Config) DerivePath(path DerivationPath) (*Config, *ExtendedKey, error) {
+	key, err := c.ExtendedKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	child := c
+	for _, i := range path {
+		if child, err = child.DeriveBIP32(i); err != nil {
+			return nil, nil, fmt.Errorf("config: deriving %s: %w", key.Path, err)
+		}
+		if key, err = key.Child(i); err != nil {
+			return nil, nil, err
+		}
+	}
+	return child, key, nil
+}
+
+// EnumerateChildren regenerates the extended keys of the children of the node at parent,
+// with indices in [start, start+count). Indices which are invalid according to BIP32 are skipped.
+// This allows a wallet restored from a backed-up Config to find previously used addresses.
+func (c *Config) EnumerateChildren(parent DerivationPath, start, count uint32) ([]*ExtendedKey, error) {
+	_, key, err := c.DerivePath(parent)
+	if err != nil {
+		return nil, err
+	}
+	children := make([]*ExtendedKey, 0, count)
+	for i := start; i-start < count; i++ {
+		if i>>31 != 0 {
+			break
+		}
+		child, err := key.Child(i)
+		if err != nil {
+			continue
+		}
+		children = append(children, child)
+	}
+	return children, nil
+}
+
+// Child derives the extended public key of the ith child of k.
+func (k *ExtendedKey) Child(i uint32) (*ExtendedKey, error) {
+	public, ok := k.PublicKey.(*curve.Secp256k1Point)
+	if !ok {
+		return nil, errors.New("config: BIP32 derivation requires secp256k1")
+	}
+	if i>>31 != 0 {
+		return nil, fmt.Errorf("config: hardened index %d", i)
+	}
+	if k.Depth == 255 {
+		return nil, errors.New("config: maximum derivation depth reached")
+	}
+	scalar, chainCode, err := bip32.DeriveScalar(public, k.ChainCode, i)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	childKey := scalar.ActOnBase().Add(public)
+	if childKey.IsIdentity() {
+		return nil, fmt.Errorf("config: bad index: %d", i)
+	}
+	fingerprint, err := k.Fingerprint()
+	if err != nil {
+		return nil, err
+	}
+	path := make(DerivationPath, 0, len(k.Path)+1)
+	path = append(append(path, k.Path...), i)
+	return &ExtendedKey{
+		Depth:             k.Depth + 1,
+		ParentFingerprint: fingerprint,
+		ChildNumber:       i,
+		ChainCode:         chainCode,
+		PublicKey:         childKey,
+		Path:              path,
+	}, nil
+}
+
+// Fingerprint returns the first 4 bytes of the HASH160 of the compressed public key of k.
+func (k *ExtendedKey) Fingerprint() ([4]byte, error) {
+	var fingerprint [4]byte
+	data, err := k.PublicKey.MarshalBinary()
+	if err != nil {
+		return fingerprint, err
+	}
+	sha := sha256.Sum256(data)
+	h := ripemd160.New()
+	_, _ = h.Write(sha[:])
+	copy(fingerprint[:], h.Sum(nil))
+	return fingerprint, nil
+}
+
+// String returns the Base58Check serialization of k with XPubVersion.
+func (k *ExtendedKey) String() string {
+	data, err := k.MarshalBinary()
+	if err != nil {
+		return ""
+	}
+	return base58.CheckEncode(XPubVersion, data)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, returning the 74 byte serialization of k without version.
+func (k *ExtendedKey) MarshalBinary() ([]byte, error) {
+	if len(k.ChainCode) != 32 {
+		return nil, fmt.Errorf("config: expected 32 bytes for chain code, found %d", len(k.ChainCode))
+	}
+	public, err := k.PublicKey.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, extendedKeyLength)
+	out = append(out, k.Depth)
+	out = append(out, k.ParentFingerprint[:]...)
+	out = binary.BigEndian.AppendUint32(out, k.ChildNumber)
+	out = append(out, k.ChainCode...)
+	return append(out, public...), nil
+}
+
+// ParseExtendedKey parses an extended public key serialized by ExtendedKey.String.
+// Since the serialization does not contain the full derivation path, Path is only set for the root key.
+func ParseExtendedKey(group curve.Curve, s string) (*ExtendedKey, error) {
+	version, data, err := base58.CheckDecode(s, len(XPubVersion))
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	if !bytes.Equal(version, XPubVersion) {
+		return nil, fmt.Errorf("config: expected version %x, got %x", XPubVersion, version)
+	}
+	if len(data) != extendedKeyLength {
+		return nil, fmt.Errorf("config: invalid length for extended key: %d", len(data))
+	}
+	public, err := unmarshalPublicKey(group, data[41:])
+	if err != nil {
+		return nil, err
+	}
+	k := &ExtendedKey{
+		Depth:       data[0],
+		ChildNumber: binary.BigEndian.Uint32(data[5:9]),
+		ChainCode:   append([]byte{}, data[9:41]...),
+		PublicKey:   public,
+	}
+	copy(k.ParentFingerprint[:], data[1:5])
+	if k.Depth == 0 {
+		k.Path = DerivationPath{}
+	}
+	return k, nil
+}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
+)
+
+// PublicCommitment is a commitment to the Public entry of a party, which can be shown or transferred through
+// semi-trusted channels during onboarding, for instance as a QR code or a fingerprint read over the phone.
+// It reveals nothing about the entry, and is checked with Verify once the entry arrives, given the PublicOpening
+// kept by the party which created it.
+type PublicCommitment struct {
+	// ID is the party whose entry is committed to.
+	ID party.ID
+	// Blinded is ECDSA + r•G, where r is the blinding factor of the opening.
+	Blinded curve.Point
+	// Commitment binds the ID, the blinded point and the ElGamal, Paillier and Pedersen keys of the entry.
+	Commitment hash.Commitment
+}
+
+// PublicOpening opens a PublicCommitment. It must be kept until the entry has been verified,
+// and only sent along with the entry.
+type PublicOpening struct {
+	// Blinding is the scalar r added to the ECDSA share.
+	Blinding curve.Scalar
+	// Decommitment is the randomness of the commitment.
+	Decommitment hash.Decommitment
+}
+
+// Commit returns a commitment to the Public entry of party id, along with its opening.
+func (p *Public) Commit(id party.ID) (*PublicCommitment, *PublicOpening, error) {
+	if p == nil || p.ECDSA == nil || p.ElGamal == nil || p.Paillier == nil || p.Pedersen == nil {
+		return nil, nil, fmt.Errorf("config: party %s: incomplete public data", id)
+	}
+	r := sample.Scalar(platform.Reader, p.ECDSA.Curve())
+	blinded := p.ECDSA.Add(r.ActOnBase())
+	commitment, decommitment, err := commitmentHash(id).Commit(blinded, p.ElGamal, p.Paillier, p.Pedersen)
+	if err != nil {
+		return nil, nil, fmt.Errorf("config: party %s: %w", id, err)
+	}
+	return &PublicCommitment{ID: id, Blinded: blinded, Commitment: commitment},
+		&PublicOpening{Blinding: r, Decommitment: decommitment}, nil
+}
+
+// CommitPublic returns a commitment to the Public entry of party id in c, along with its opening.
+func (c *PublicConfig) CommitPublic(id party.ID) (*PublicCommitment, *PublicOpening, error) {
+	public, ok := c.Public[id]
+	if !ok {
+		return nil, nil, fmt.Errorf("config: party %s is not a party of this config", id)
+	}
+	return public.Commit(id)
+}
+
+// Verify returns an error unless public is the entry committed to by pc, opened with opening.
+func (pc *PublicCommitment) Verify(public *Public, opening *PublicOpening) error {
+	if public == nil || public.ECDSA == nil || public.ElGamal == nil || public.Paillier == nil || public.Pedersen == nil {
+		return fmt.Errorf("config: party %s: incomplete public data", pc.ID)
+	}
+	if opening == nil || opening.Blinding == nil {
+		return fmt.Errorf("config: party %s: missing opening", pc.ID)
+	}
+	if pc.Blinded == nil || !pc.Blinded.Equal(public.ECDSA.Add(opening.Blinding.ActOnBase())) {
+		return fmt.Errorf("config: party %s: ECDSA share does not match the commitment", pc.ID)
+	}
+	if !commitmentHash(pc.ID).Decommit(pc.Commitment, opening.Decommitment, pc.Blinded, public.ElGamal, public.Paillier, public.Pedersen) {
+		return fmt.Errorf("config: party %s: public data does not match the commitment", pc.ID)
+	}
+	return nil
+}
+
+// VerifyPublic returns an error unless the entry of party pc.ID in c is the one committed to by pc.
+func (c *PublicConfig) VerifyPublic(pc *PublicCommitment, opening *PublicOpening) error {
+	public, ok := c.Public[pc.ID]
+	if !ok {
+		return fmt.Errorf("config: party %s is not a party of this config", pc.ID)
+	}
+	return pc.Verify(public, opening)
+}
+
+// Fingerprint returns a short digest of pc, as groups of 4 hexadecimal characters,
+// meant to be compared by people over a separate channel.
+func (pc *PublicCommitment) Fingerprint() (string, error) {
+	data, err := pc.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	digest := hex.EncodeToString(hash.New(&hash.BytesWithDomain{TheDomain: "Public Commitment", Bytes: data}).Sum()[:10])
+	groups := make([]string, 0, len(digest)/4)
+	for i := 0; i < len(digest); i += 4 {
+		groups = append(groups, digest[i:i+4])
+	}
+	return strings.Join(groups, "-"), nil
+}
+
+func commitmentHash(id party.ID) *hash.Hash {
+	return hash.New(&hash.BytesWithDomain{TheDomain: "Public Commitment", Bytes: []byte(id)})
+}
+
+// EmptyPublicCommitment creates an empty PublicCommitment with a fixed group, ready for unmarshalling.
+func EmptyPublicCommitment(group curve.Curve) *PublicCommitment {
+	return &PublicCommitment{Blinded: group.NewPoint()}
+}
+
+// EmptyPublicOpening creates an empty PublicOpening with a fixed group, ready for unmarshalling.
+func EmptyPublicOpening(group curve.Curve) *PublicOpening {
+	return &PublicOpening{Blinding: group.NewScalar()}
+}
+
+type publicCommitmentMarshal struct {
+	ID         party.ID
+	Blinded    cbor.RawMessage
+	Commitment []byte
+}
+
+func (pc *PublicCommitment) MarshalBinary() ([]byte, error) {
+	blinded, err := cbor.Marshal(pc.Blinded)
+	if err != nil {
+		return nil, err
+	}
+	return cbor.Marshal(&publicCommitmentMarshal{
+		ID:         pc.ID,
+		Blinded:    blinded,
+		Commitment: pc.Commitment,
+	})
+}
+
+func (pc *PublicCommitment) UnmarshalBinary(data []byte) error {
+	if pc.Blinded == nil {
+		return errors.New("commitment must be initialized using EmptyPublicCommitment")
+	}
+	var pm publicCommitmentMarshal
+	if err := cbor.Unmarshal(data, &pm); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if err := cbor.Unmarshal(pm.Blinded, pc.Blinded); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if err := hash.Commitment(pm.Commitment).Validate(); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	pc.ID = pm.ID
+	pc.Commitment = pm.Commitment
+	return nil
+}
+
+type publicOpeningMarshal struct {
+	Blinding     cbor.RawMessage
+	Decommitment []byte
+}
+
+func (o *PublicOpening) MarshalBinary() ([]byte, error) {
+	blinding, err := cbor.Marshal(o.Blinding)
+	if err != nil {
+		return nil, err
+	}
+	return cbor.Marshal(&publicOpeningMarshal{
+		Blinding:     blinding,
+		Decommitment: o.Decommitment,
+	})
+}
+
+func (o *PublicOpening) UnmarshalBinary(data []byte) error {
+	if o.Blinding == nil {
+		return errors.New("opening must be initialized using EmptyPublicOpening")
+	}
+	var om publicOpeningMarshal
+	if err := cbor.Unmarshal(data, &om); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if err := cbor.Unmarshal(om.Blinding, o.Blinding); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if err := hash.Decommitment(om.Decommitment).Validate(); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	o.Decommitment = om.Decommitment
+	return nil
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+)
+
+// RoundDigest is the hash of all messages broadcast in a round of the keygen or refresh which produced a config,
+// as stored by the rounds of each party, so that parties which received the same broadcasts have the same digests.
+type RoundDigest struct {
+	Round  round.Number
+	Digest []byte
+}
+
+// RecordBroadcastHashes sets the CeremonyLog of c to the given broadcast hashes, sorted by round.
+// It is called by the keygen rounds which produce c.
+func (c *Config) RecordBroadcastHashes(hashes map[round.Number][]byte) {
+	c.CeremonyLog = ceremonyLog(hashes)
+}
+
+// SameCeremony returns an error unless c and other record the same broadcast hash for every round of the
+// keygen or refresh which produced them, which shows that they originate from the same execution
+// and that their parties received the same broadcasts.
+func (c *Config) SameCeremony(other *Config) error {
+	if other == nil {
+		return errors.New("config: other config is nil")
+	}
+	return sameCeremony(c.CeremonyLog, other.CeremonyLog)
+}
+
+func ceremonyLog(hashes map[round.Number][]byte) []RoundDigest {
+	log := make([]RoundDigest, 0, len(hashes))
+	for number, digest := range hashes {
+		log = append(log, RoundDigest{Round: number, Digest: bytes.Clone(digest)})
+	}
+	sort.Slice(log, func(i, j int) bool { return log[i].Round < log[j].Round })
+	return log
+}
+
+func sameCeremony(a, b []RoundDigest) error {
+	if len(a) == 0 || len(b) == 0 {
+		return errors.New("config: no ceremony log")
+	}
+	if len(a) != len(b) {
+		return fmt.Errorf("config: ceremony logs have %d and %d rounds", len(a), len(b))
+	}
+	for i := range a {
+		if a[i].Round != b[i].Round {
+			return fmt.Errorf("config: ceremony logs record different rounds: %d != %d", a[i].Round, b[i].Round)
+		}
+		if !bytes.Equal(a[i].Digest, b[i].Digest) {
+			return fmt.Errorf("config: ceremony logs differ in round %d", a[i].Round)
+		}
+	}
+	return nil
+}
+
+// SameCeremony is the same as Config.SameCeremony.
+func (c *PublicConfig) SameCeremony(other *PublicConfig) error {
+	if other == nil {
+		return errors.New("config: other config is nil")
+	}
+	return sameCeremony(c.CeremonyLog, other.CeremonyLog)
+}
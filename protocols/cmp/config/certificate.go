@@ -0,0 +1,132 @@
+package config
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+)
+
+// KeyCertificate is a Schnorr proof of knowledge of the secret key of a config, produced jointly by its parties
+// at the end of the keygen or refresh which created it. Each party j contributes a nonce commitment Bⱼ = bⱼ•G
+// and a response zⱼ = bⱼ + e•λⱼ•xⱼ, where λⱼ is its Lagrange coefficient over all parties,
+// so that z•G = B + e•X for the sums B = ∑ⱼ Bⱼ and z = ∑ⱼ zⱼ, and the public key X.
+//
+// The challenge e binds the proof to the public shares of all parties, see PublicConfig.CertificateChallenge,
+// so that anyone holding the public config can check that the key was created by the listed parties,
+// without taking part in the protocol.
+type KeyCertificate struct {
+	// Commitment B = ∑ⱼ Bⱼ
+	Commitment curve.Point
+	// Response z = ∑ⱼ zⱼ
+	Response curve.Scalar
+}
+
+// EmptyKeyCertificate creates an empty KeyCertificate with a fixed group, ready for unmarshalling.
+func EmptyKeyCertificate(group curve.Curve) *KeyCertificate {
+	return &KeyCertificate{
+		Commitment: group.NewPoint(),
+		Response:   group.NewScalar(),
+	}
+}
+
+type keyCertificateMarshal struct {
+	Commitment curve.Point
+	Response   curve.Scalar
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (k *KeyCertificate) MarshalBinary() ([]byte, error) {
+	return cbor.Marshal(&keyCertificateMarshal{Commitment: k.Commitment, Response: k.Response})
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// The certificate must be initialized with EmptyKeyCertificate.
+func (k *KeyCertificate) UnmarshalBinary(data []byte) error {
+	if k.Commitment == nil || k.Response == nil {
+		return errors.New("config: certificate must be initialized using EmptyKeyCertificate")
+	}
+	km := &keyCertificateMarshal{Commitment: k.Commitment, Response: k.Response}
+	if err := cbor.Unmarshal(data, km); err != nil {
+		return fmt.Errorf("config: certificate: %w", err)
+	}
+	k.Commitment, k.Response = km.Commitment, km.Response
+	return nil
+}
+
+// CertificateChallenge returns the challenge e of a KeyCertificate with the given commitment for the key of c,
+// which depends on the group, threshold, RID and chain key of c, and on the public shares of every party.
+func (c *PublicConfig) CertificateChallenge(commitment curve.Point) (curve.Scalar, error) {
+	threshold := make([]byte, 4)
+	binary.BigEndian.PutUint32(threshold, uint32(c.Threshold))
+	h := hash.New(
+		&hash.BytesWithDomain{TheDomain: "Key Certificate", Bytes: []byte(c.Group.Name())},
+		&hash.BytesWithDomain{TheDomain: "Threshold", Bytes: threshold},
+		&hash.BytesWithDomain{TheDomain: "RID", Bytes: c.RID},
+		&hash.BytesWithDomain{TheDomain: "Chain Key", Bytes: c.ChainKey},
+	)
+	for _, j := range c.PartyIDs() {
+		if err := h.WriteAny(j, c.Public[j].ECDSA); err != nil {
+			return nil, fmt.Errorf("config: certificate: %w", err)
+		}
+		for _, share := range c.Public[j].Shares {
+			if err := h.WriteAny(share); err != nil {
+				return nil, fmt.Errorf("config: certificate: %w", err)
+			}
+		}
+	}
+	if err := h.WriteAny(c.PublicPoint(), commitment); err != nil {
+		return nil, fmt.Errorf("config: certificate: %w", err)
+	}
+	return sample.Challenge(h.Digest(), c.Group, true), nil
+}
+
+// VerifyCertificate returns an error if c has no KeyCertificate, or if it does not prove knowledge
+// of the secret key corresponding to the public shares of c.
+func (c *PublicConfig) VerifyCertificate() error {
+	k := c.Certificate
+	if k == nil || k.Commitment == nil || k.Response == nil {
+		return errors.New("config: no key certificate")
+	}
+	if k.Commitment.IsIdentity() || k.Response.IsZero() {
+		return errors.New("config: invalid key certificate")
+	}
+	e, err := c.CertificateChallenge(k.Commitment)
+	if err != nil {
+		return err
+	}
+	// z•G = B + e•X
+	if !k.Response.ActOnBase().Equal(e.Act(c.PublicPoint()).Add(k.Commitment)) {
+		return errors.New("config: key certificate does not verify")
+	}
+	return nil
+}
+
+// VerifyCertificate is the same as PublicConfig.VerifyCertificate.
+func (c *Config) VerifyCertificate() error {
+	return c.PublicConfig().VerifyCertificate()
+}
+
+// marshalCertificate encodes k, or returns nil if k is nil, so that configs without certificate keep their encoding.
+func marshalCertificate(k *KeyCertificate) (cbor.RawMessage, error) {
+	if k == nil {
+		return nil, nil
+	}
+	return k.MarshalBinary()
+}
+
+// unmarshalCertificate decodes a certificate encoded with marshalCertificate.
+func unmarshalCertificate(group curve.Curve, data cbor.RawMessage) (*KeyCertificate, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	k := EmptyKeyCertificate(group)
+	if err := k.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
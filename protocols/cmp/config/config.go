@@ -1,6 +1,8 @@
 package config
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -10,7 +12,6 @@ import (
 	"github.com/taurusgroup/multi-party-sig/internal/params"
 	"github.com/taurusgroup/multi-party-sig/internal/types"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
-	"github.com/taurusgroup/multi-party-sig/pkg/math/polynomial"
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
 	"github.com/taurusgroup/multi-party-sig/pkg/party"
 	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
@@ -32,6 +33,9 @@ type Config struct {
 	Threshold int
 	// ECDSA is this party's share xᵢ of the secret ECDSA x.
 	ECDSA curve.Scalar
+	// Shares contains the additional shares of the secret ECDSA x held by this party, if it has a weight
+	// larger than 1, at the evaluation points given by ID.WeightedIDs. It is nil for unweighted configs.
+	Shares []curve.Scalar
 	// ElGamal is this party's yᵢ used for ElGamal.
 	ElGamal curve.Scalar
 	// Paillier is this party's Paillier decryption key.
@@ -40,8 +44,26 @@ type Config struct {
 	RID types.RID
 	// ChainKey is the chaining key value associated with this public key
 	ChainKey types.RID
+	// Epoch is the number of times this key was refreshed: it is 0 after keygen, and incremented by every refresh.
+	// It is included in the sessions of all protocols using this config, so that messages of a session using
+	// another epoch are rejected, and signings started before a refresh can complete with the previous config.
+	Epoch uint64
+	// Dealt is true if this config was created by a dealer which knew the secret key, see keygen.Dealer.
+	// A refresh clears it, and it is included in the hash of the config, so that it cannot be dropped silently.
+	Dealt bool
 	// Public maps party.ID to public. It contains all public information associated to a party.
 	Public map[party.ID]*Public
+	// Certificate is the proof produced by the parties at the end of the keygen or refresh, see KeyCertificate.
+	// It is nil for configs created otherwise, and is not included in the hash of the config.
+	Certificate *KeyCertificate
+	// CeremonyLog records the broadcast hash of every round of the keygen or refresh which produced this config,
+	// so that the configs of different parties can be checked to come from the same execution, see SameCeremony.
+	// It is nil for configs produced otherwise, and is included in the hash of the config.
+	CeremonyLog []RoundDigest
+	// Pending contains the sorted IDs of the parties which were invited to the keygen, but did not take part in it,
+	// so that they can obtain a share later with keygen.StartJoin. They hold no share until then.
+	// It is nil if all invited parties took part, and is not included in the hash of the config.
+	Pending []party.ID
 }
 
 // Public holds public information for a party.
@@ -54,20 +76,14 @@ type Public struct {
 	Paillier *paillier.PublicKey
 	// Pedersen is this party's public Pedersen parameters.
 	Pedersen *pedersen.Parameters
+	// Shares contains the public ECDSA shares of the additional evaluation points of a party with a weight
+	// larger than 1, see party.ID.WeightedIDs. The weight of the party is 1 + len(Shares).
+	Shares []curve.Point
 }
 
 // PublicPoint returns the group's public ECC point.
 func (c *Config) PublicPoint() curve.Point {
-	sum := c.Group.NewPoint()
-	partyIDs := make([]party.ID, 0, len(c.Public))
-	for j := range c.Public {
-		partyIDs = append(partyIDs, j)
-	}
-	l := polynomial.Lagrange(c.Group, partyIDs)
-	for j, partyJ := range c.Public {
-		sum = sum.Add(l[j].Act(partyJ.ECDSA))
-	}
-	return sum
+	return c.PublicConfig().PublicPoint()
 }
 
 // PartyIDs returns a sorted slice of party IDs.
@@ -117,6 +133,43 @@ func (c *Config) WriteTo(w io.Writer) (total int64, err error) {
 			return
 		}
 	}
+
+	// write the epoch, unless the key was never refreshed, so that the hash of such configs is unchanged
+	if c.Epoch != 0 {
+		epoch := make([]byte, 8)
+		binary.BigEndian.PutUint64(epoch, c.Epoch)
+		var m int
+		m, err = w.Write(epoch)
+		total += int64(m)
+		if err != nil {
+			return
+		}
+	}
+
+	// likewise, the provenance is only written for configs created by a dealer
+	if c.Dealt {
+		var m int
+		m, err = w.Write([]byte("dealt"))
+		total += int64(m)
+		if err != nil {
+			return
+		}
+	}
+
+	// and the ceremony log only for configs produced by keygen or refresh
+	for _, d := range c.CeremonyLog {
+		n, err = d.Round.WriteTo(w)
+		total += n
+		if err != nil {
+			return
+		}
+		var m int
+		m, err = w.Write(d.Digest)
+		total += int64(m)
+		if err != nil {
+			return
+		}
+	}
 	return
 }
 
@@ -169,6 +222,19 @@ func (p *Public) WriteTo(w io.Writer) (total int64, err error) {
 		return
 	}
 
+	// the additional shares are only written for weighted parties, so that the hash of other configs is unchanged
+	for _, share := range p.Shares {
+		data, err = share.MarshalBinary()
+		if err != nil {
+			return
+		}
+		n, err = w.Write(data)
+		total += int64(n)
+		if err != nil {
+			return
+		}
+	}
+
 	return
 }
 
@@ -176,28 +242,102 @@ func (p *Public) WriteTo(w io.Writer) (total int64, err error) {
 // a valid subset of the original parties of size > t,
 // and includes self.
 func (c *Config) CanSign(signers party.IDSlice) bool {
-	if !ValidThreshold(c.Threshold, len(signers)) {
-		return false
+	return c.ValidateSigners(signers) == nil
+}
+
+// ValidateSigners is the same as CanSign, but returns an error describing why
+// the given _sorted_ list of signers cannot be used.
+func (c *Config) ValidateSigners(signers party.IDSlice) error {
+	if weight := c.Weights().Total(signers); !ValidThreshold(c.Threshold, weight) {
+		return fmt.Errorf("config: %d signers with total weight %d is not enough for threshold %d", len(signers), weight, c.Threshold)
 	}
 
 	// check for duplicates
 	if !signers.Valid() {
-		return false
+		return errors.New("config: signers contains duplicates or is not sorted")
 	}
 
 	if !signers.Contains(c.ID) {
-		return false
+		return fmt.Errorf("config: signers does not include self (%s)", c.ID)
 	}
 
 	// check that the signers are a subset of the original parties,
 	// that it includes self, and that the size is > t.
 	for _, j := range signers {
-		if _, ok := c.Public[j]; !ok {
-			return false
+		public, ok := c.Public[j]
+		if !ok {
+			return fmt.Errorf("config: signer %s is not a party of this config", j)
+		}
+		if public == nil || public.Paillier == nil || public.Pedersen == nil {
+			return fmt.Errorf("config: signer %s has incomplete public data", j)
 		}
 	}
 
-	return true
+	// check that no two signers share the same Paillier or Pedersen modulus,
+	// which would indicate that the same config was copied for multiple parties.
+	paillierModuli := make(map[string]party.ID, len(signers))
+	pedersenModuli := make(map[string]party.ID, len(signers))
+	for _, j := range signers {
+		paillierN := string(c.Public[j].Paillier.N().Bytes())
+		pedersenN := string(c.Public[j].Pedersen.N().Bytes())
+		if other, ok := paillierModuli[paillierN]; ok {
+			return fmt.Errorf("config: signers %s and %s share the same Paillier modulus", other, j)
+		}
+		if other, ok := pedersenModuli[pedersenN]; ok {
+			return fmt.Errorf("config: signers %s and %s share the same Pedersen modulus", other, j)
+		}
+		paillierModuli[paillierN] = j
+		pedersenModuli[pedersenN] = j
+	}
+
+	return nil
+}
+
+// Compatible returns an error if c and other were not produced by the same execution of keygen or refresh.
+// That is, they must share the same group, threshold, RID, chain key and public data for all parties.
+func (c *Config) Compatible(other *Config) error {
+	if other == nil {
+		return errors.New("config: other config is nil")
+	}
+	if c.Group.Name() != other.Group.Name() {
+		return fmt.Errorf("config: group mismatch: %s != %s", c.Group.Name(), other.Group.Name())
+	}
+	if c.Threshold != other.Threshold {
+		return fmt.Errorf("config: threshold mismatch: %d != %d", c.Threshold, other.Threshold)
+	}
+	if !bytes.Equal(c.RID, other.RID) {
+		return errors.New("config: RID mismatch")
+	}
+	if !bytes.Equal(c.ChainKey, other.ChainKey) {
+		return errors.New("config: chain key mismatch")
+	}
+	if len(c.Public) != len(other.Public) {
+		return fmt.Errorf("config: number of parties mismatch: %d != %d", len(c.Public), len(other.Public))
+	}
+	for j, public := range c.Public {
+		otherPublic, ok := other.Public[j]
+		if !ok {
+			return fmt.Errorf("config: party %s missing from other config", j)
+		}
+		if !public.ECDSA.Equal(otherPublic.ECDSA) || !equalShares(public.Shares, otherPublic.Shares) {
+			return fmt.Errorf("config: party %s: ECDSA share mismatch", j)
+		}
+		if !public.ElGamal.Equal(otherPublic.ElGamal) {
+			return fmt.Errorf("config: party %s: ElGamal key mismatch", j)
+		}
+		if !public.Paillier.Equal(otherPublic.Paillier) {
+			return fmt.Errorf("config: party %s: Paillier key mismatch", j)
+		}
+		if public.Pedersen.N().Nat().Eq(otherPublic.Pedersen.N().Nat()) != 1 ||
+			public.Pedersen.S().Eq(otherPublic.Pedersen.S()) != 1 ||
+			public.Pedersen.T().Eq(otherPublic.Pedersen.T()) != 1 {
+			return fmt.Errorf("config: party %s: Pedersen parameters mismatch", j)
+		}
+	}
+	if !c.PublicPoint().Equal(other.PublicPoint()) {
+		return errors.New("config: public key mismatch")
+	}
+	return nil
 }
 
 func ValidThreshold(t, n int) bool {
@@ -235,19 +375,30 @@ func (c *Config) Derive(adjust curve.Scalar, newChainKey []byte) (*Config, error
 			ElGamal:  v.ElGamal,
 			Paillier: v.Paillier,
 			Pedersen: v.Pedersen,
+			Shares:   addToShares(v.Shares, adjustG),
 		}
 	}
+	var shares []curve.Scalar
+	for _, share := range c.Shares {
+		shares = append(shares, c.Group.NewScalar().Set(share).Add(adjust))
+	}
 
 	return &Config{
 		Group:     c.Group,
 		ID:        c.ID,
 		Threshold: c.Threshold,
 		ECDSA:     c.Group.NewScalar().Set(c.ECDSA).Add(adjust),
+		Shares:    shares,
 		ElGamal:   c.ElGamal,
 		Paillier:  c.Paillier,
 		RID:       c.RID,
 		ChainKey:  newChainKey,
+		Epoch:     c.Epoch,
+		Dealt:     c.Dealt,
 		Public:    public,
+		// the derived key comes from the same execution, but the certificate only proves knowledge of the original key
+		CeremonyLog: c.CeremonyLog,
+		Pending:     c.Pending,
 	}, nil
 }
 
@@ -272,3 +423,6 @@ func (c *Config) DeriveBIP32(i uint32) (*Config, error) {
 	}
 	return c.Derive(scalar, newChainKey)
 }
+
+// ResultKind returns "config", see protocol.KindOf.
+func (Config) ResultKind() string { return "config" }
@@ -0,0 +1,758 @@
+package config_test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/cronokirby/saferith"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/internal/types"
+	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/polynomial"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
+)
+
+func TestCanSign(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	configs, partyIDs := test.GenerateConfig(curve.Secp256k1{}, 3, 1, rand.Reader, pl)
+	c := configs[partyIDs[0]]
+	assert.True(t, c.CanSign(partyIDs))
+	assert.True(t, c.CanSign(partyIDs[:2]))
+	assert.False(t, c.CanSign(partyIDs[1:]), "signers must include self")
+	assert.False(t, c.CanSign(partyIDs[:1]), "signers must be more than the threshold")
+
+	// simulate a config whose public data was cloned from another party
+	cloned := *c
+	cloned.Public = make(map[party.ID]*config.Public, len(c.Public))
+	for j, public := range c.Public {
+		cloned.Public[j] = public
+	}
+	cloned.Public[partyIDs[1]] = c.Public[partyIDs[0]]
+	assert.False(t, cloned.CanSign(partyIDs[:2]), "duplicate moduli should be rejected")
+	assert.True(t, cloned.CanSign(party.IDSlice{partyIDs[0], partyIDs[2]}))
+}
+
+func TestCompatible(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	configs, partyIDs := test.GenerateConfig(curve.Secp256k1{}, 3, 1, rand.Reader, pl)
+	for _, id := range partyIDs {
+		assert.NoError(t, configs[partyIDs[0]].Compatible(configs[id]))
+	}
+
+	others, otherIDs := test.GenerateConfig(curve.Secp256k1{}, 3, 1, rand.Reader, pl)
+	require.Equal(t, partyIDs, otherIDs)
+	assert.Error(t, configs[partyIDs[0]].Compatible(others[partyIDs[1]]))
+
+	threshold := *configs[partyIDs[1]]
+	threshold.Threshold = 2
+	assert.Error(t, configs[partyIDs[0]].Compatible(&threshold))
+	assert.Error(t, configs[partyIDs[0]].Compatible(nil))
+}
+
+func TestSSIDHash(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	sessionID := []byte("session")
+	expected, err := configs[partyIDs[0]].SSIDHash(sessionID)
+	require.NoError(t, err)
+	for _, id := range partyIDs {
+		require.NoError(t, configs[id].VerifySSIDHash(sessionID, expected))
+	}
+
+	// it is the SSID of the refresh
+	r, err := cmp.Refresh(configs[partyIDs[1]], pl)(sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, expected, r.SSID())
+
+	other, err := configs[partyIDs[0]].SSIDHash(nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, expected, other)
+
+	diverged := *configs[partyIDs[1]]
+	diverged.RID = types.RID(make([]byte, len(diverged.RID)))
+	assert.Error(t, diverged.VerifySSIDHash(sessionID, expected))
+	diverged = *configs[partyIDs[1]]
+	diverged.Epoch = 1
+	assert.Error(t, diverged.VerifySSIDHash(sessionID, expected))
+}
+
+func TestSplitLocal(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, partyIDs := test.GenerateConfig(group, 2, 1, rand.Reader, pl)
+	c := configs[partyIDs[0]]
+
+	_, err := c.SplitLocal(2, 3)
+	assert.Error(t, err, "threshold larger than number of shares")
+
+	shares, err := c.SplitLocal(3, 2)
+	require.NoError(t, err)
+	require.Len(t, shares, 3)
+
+	// shares survive a round trip through CBOR
+	for i, share := range shares {
+		data, err := cbor.Marshal(share)
+		require.NoError(t, err)
+		decoded := config.EmptyLocalShare(group)
+		require.NoError(t, cbor.Unmarshal(data, decoded))
+		shares[i] = decoded
+	}
+
+	for _, subset := range [][]*config.LocalShare{shares[:2], shares[1:], {shares[0], shares[2]}, shares} {
+		reassembled, err := config.LocalReassemble(subset...)
+		require.NoError(t, err)
+		assert.True(t, reassembled.ECDSA.Equal(c.ECDSA))
+		assert.True(t, reassembled.ElGamal.Equal(c.ElGamal))
+		assert.True(t, reassembled.Paillier.PublicKey.Equal(c.Paillier.PublicKey))
+		assert.NoError(t, reassembled.Compatible(c))
+	}
+
+	_, err = config.LocalReassemble(shares[0])
+	assert.Error(t, err, "not enough shares")
+	_, err = config.LocalReassemble(shares[0], shares[0])
+	assert.Error(t, err, "duplicate shares")
+
+	tampered := *shares[1]
+	tampered.ECDSA = group.NewScalar().Set(tampered.ECDSA).Add(group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1)))
+	_, err = config.LocalReassemble(shares[0], &tampered)
+	assert.Error(t, err, "tampered share should not reassemble")
+}
+
+func TestPublicKeyEncoding(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, partyIDs := test.GenerateConfig(group, 2, 1, rand.Reader, pl)
+	c := configs[partyIDs[0]]
+	public := c.PublicPoint()
+
+	p, err := curve.PointFromHex(group, c.PublicKeyHex())
+	require.NoError(t, err)
+	assert.True(t, p.Equal(public))
+	assert.Len(t, c.PublicKeyHex(), 66)
+
+	s, err := curve.ScalarFromHex(group, curve.ScalarToHex(c.ECDSA))
+	require.NoError(t, err)
+	assert.True(t, s.Equal(c.ECDSA))
+
+	encoded, err := c.PublicKeyBech32("mpc")
+	require.NoError(t, err)
+	p, err = config.ParsePublicKeyBech32(group, "mpc", encoded)
+	require.NoError(t, err)
+	assert.True(t, p.Equal(public))
+	_, err = config.ParsePublicKeyBech32(group, "other", encoded)
+	assert.Error(t, err)
+
+	version := []byte{0x04, 0x88}
+	encoded, err = c.PublicKeyBase58Check(version)
+	require.NoError(t, err)
+	p, err = config.ParsePublicKeyBase58Check(group, version, encoded)
+	require.NoError(t, err)
+	assert.True(t, p.Equal(public))
+	_, err = config.ParsePublicKeyBase58Check(group, []byte{0x00, 0x00}, encoded)
+	assert.Error(t, err)
+}
+
+func TestMigrate(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	c := configs[partyIDs[0]]
+	require.NoError(t, c.Validate())
+
+	data, err := c.MarshalBinary()
+	require.NoError(t, err)
+	formats := []config.FormatVersion{config.FormatCBOR, config.FormatJSON, config.FormatEnvelope}
+	for _, from := range formats {
+		in, err := config.Migrate(group, data, config.FormatCBOR, from)
+		require.NoError(t, err)
+		for _, to := range formats {
+			out, err := config.Migrate(group, in, from, to)
+			require.NoError(t, err, "%d -> %d", from, to)
+			decoded, err := config.Decode(group, out, to)
+			require.NoError(t, err)
+			assert.Equal(t, c.Fingerprint(), decoded.Fingerprint())
+			assert.True(t, c.ECDSA.Equal(decoded.ECDSA))
+		}
+	}
+
+	// formats which record the group can be decoded without knowing it in advance
+	for _, format := range []config.FormatVersion{config.FormatJSON, config.FormatEnvelope} {
+		encoded, err := c.Encode(format)
+		require.NoError(t, err)
+		decoded, err := config.Decode(nil, encoded, format)
+		require.NoError(t, err)
+		assert.Equal(t, c.Fingerprint(), decoded.Fingerprint())
+	}
+
+	// a tampered envelope is rejected
+	envelope, err := c.Encode(config.FormatEnvelope)
+	require.NoError(t, err)
+	envelope[len(envelope)-1] ^= 1
+	_, err = config.Migrate(group, envelope, config.FormatEnvelope, config.FormatCBOR)
+	assert.Error(t, err)
+
+	// the chain key is part of the fingerprint
+	other := *c
+	other.ChainKey = append([]byte{}, c.ChainKey...)
+	other.ChainKey[0] ^= 1
+	assert.NotEqual(t, c.Fingerprint(), other.Fingerprint())
+
+	// secret material which does not match the public data is rejected
+	other = *c
+	other.ECDSA = configs[partyIDs[1]].ECDSA
+	assert.Error(t, other.Validate())
+
+	_, err = config.Migrate(group, data, config.FormatCBOR, config.LatestFormat+1)
+	assert.Error(t, err)
+}
+
+func TestEpoch(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	c := configs[partyIDs[0]]
+	refreshed := *c
+	refreshed.Epoch = 3
+	assert.NotEqual(t, c.Fingerprint(), refreshed.Fingerprint())
+	assert.Equal(t, c.KeyFingerprint(), refreshed.KeyFingerprint())
+	assert.Equal(t, refreshed.Fingerprint(), refreshed.PublicConfig().Fingerprint())
+
+	for _, format := range []config.FormatVersion{config.FormatCBOR, config.FormatJSON, config.FormatEnvelope} {
+		encoded, err := refreshed.Encode(format)
+		require.NoError(t, err)
+		decoded, err := config.Decode(group, encoded, format)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), decoded.Epoch, "format %d", format)
+		assert.Equal(t, refreshed.Fingerprint(), decoded.Fingerprint())
+	}
+
+	child, err := refreshed.DeriveBIP32(1)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), child.Epoch)
+
+	shares, err := refreshed.SplitLocal(3, 2)
+	require.NoError(t, err)
+	reassembled, err := config.LocalReassemble(shares[:2]...)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), reassembled.Epoch)
+	other := *shares[1].Config
+	other.Epoch = 2
+	shares[1].Config = &other
+	_, err = config.LocalReassemble(shares[:2]...)
+	assert.Error(t, err, "shares of different epochs")
+}
+
+func TestKeyCertificate(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	c := *configs[partyIDs[0]]
+	assert.Error(t, c.VerifyCertificate(), "dealt configs have no certificate")
+
+	// the certificate of the keygen, computed with the secret key
+	secret := group.NewScalar()
+	for j, l := range polynomial.Lagrange(group, partyIDs) {
+		secret.Add(l.Mul(configs[j].ECDSA))
+	}
+	nonce := sample.Scalar(rand.Reader, group)
+	commitment := nonce.ActOnBase()
+	e, err := c.PublicConfig().CertificateChallenge(commitment)
+	require.NoError(t, err)
+	c.Certificate = &config.KeyCertificate{
+		Commitment: commitment,
+		Response:   group.NewScalar().Set(e).Mul(secret).Add(nonce),
+	}
+	require.NoError(t, c.VerifyCertificate())
+	assert.Equal(t, configs[partyIDs[0]].Fingerprint(), c.Fingerprint(), "the certificate is not part of the hash")
+
+	for _, format := range []config.FormatVersion{config.FormatCBOR, config.FormatJSON, config.FormatEnvelope} {
+		encoded, err := c.Encode(format)
+		require.NoError(t, err)
+		decoded, err := config.Decode(group, encoded, format)
+		require.NoError(t, err)
+		assert.NoError(t, decoded.VerifyCertificate(), "format %d", format)
+	}
+	data, err := c.PublicConfig().MarshalBinary()
+	require.NoError(t, err)
+	public := config.EmptyPublicConfig(group)
+	require.NoError(t, public.UnmarshalBinary(data))
+	assert.NoError(t, public.VerifyCertificate())
+
+	// the certificate is bound to the public shares of all parties
+	other := c
+	other.ChainKey = append([]byte{}, c.ChainKey...)
+	other.ChainKey[0] ^= 1
+	assert.Error(t, other.VerifyCertificate())
+	other = c
+	other.Certificate = &config.KeyCertificate{
+		Commitment: commitment,
+		Response:   group.NewScalar().Set(c.Certificate.Response).Add(group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1))),
+	}
+	assert.Error(t, other.VerifyCertificate())
+}
+
+func TestCeremonyLog(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	a, b := *configs[partyIDs[0]], *configs[partyIDs[1]]
+	assert.Error(t, a.SameCeremony(&b), "dealt configs have no ceremony log")
+
+	hashes := map[round.Number][]byte{3: {3}, 2: {2}}
+	a.RecordBroadcastHashes(hashes)
+	b.RecordBroadcastHashes(hashes)
+	require.NoError(t, a.SameCeremony(&b))
+	assert.Equal(t, round.Number(2), a.CeremonyLog[0].Round, "the log is sorted by round")
+	assert.NotEqual(t, configs[partyIDs[0]].Fingerprint(), a.Fingerprint(), "the log is part of the hash")
+	assert.Equal(t, a.Fingerprint(), a.PublicConfig().Fingerprint())
+
+	for _, format := range []config.FormatVersion{config.FormatCBOR, config.FormatJSON, config.FormatEnvelope} {
+		encoded, err := a.Encode(format)
+		require.NoError(t, err)
+		decoded, err := config.Decode(group, encoded, format)
+		require.NoError(t, err)
+		assert.NoError(t, decoded.SameCeremony(&b), "format %d", format)
+	}
+	data, err := a.PublicConfig().MarshalBinary()
+	require.NoError(t, err)
+	public := config.EmptyPublicConfig(group)
+	require.NoError(t, public.UnmarshalBinary(data))
+	assert.NoError(t, public.SameCeremony(b.PublicConfig()))
+
+	b.RecordBroadcastHashes(map[round.Number][]byte{2: {2}, 3: {4}})
+	assert.Error(t, a.SameCeremony(&b))
+	b.RecordBroadcastHashes(map[round.Number][]byte{2: {2}})
+	assert.Error(t, a.SameCeremony(&b))
+}
+
+func TestExtendedKey(t *testing.T) {
+	group := curve.Secp256k1{}
+
+	// BIP32 test vector 2
+	root, err := config.ParseExtendedKey(group, "xpub661MyMwAqRbcFW31YEwpkMuc5THy2PSt5bDMsktWQcFF8syAmRUapSCGu8ED9W6oDMSgv6Zz8idoc4a6mr8BDzTJY47LJhkJ8UB7WEGuduB")
+	require.NoError(t, err)
+	assert.Equal(t, "xpub661MyMwAqRbcFW31YEwpkMuc5THy2PSt5bDMsktWQcFF8syAmRUapSCGu8ED9W6oDMSgv6Zz8idoc4a6mr8BDzTJY47LJhkJ8UB7WEGuduB", root.String())
+	child, err := root.Child(0)
+	require.NoError(t, err)
+	assert.Equal(t, "xpub69H7F5d8KSRgmmdJg2KhpAK8SR3DjMwAdkxj3ZuxV27CprR9LgpeyGmXUbC6wb7ERfvrnKZjXoUmmDznezpbZb7ap6r1D3tgFxHmwMkQTPH", child.String())
+	assert.Equal(t, "m/0", child.Path.String())
+
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, partyIDs := test.GenerateConfig(group, 2, 1, rand.Reader, pl)
+	c := configs[partyIDs[0]]
+
+	path, err := config.ParseDerivationPath("m/1/2")
+	require.NoError(t, err)
+	derived, key, err := c.DerivePath(path)
+	require.NoError(t, err)
+	assert.True(t, derived.PublicPoint().Equal(key.PublicKey))
+	assert.Equal(t, []byte(derived.ChainKey), key.ChainCode)
+	assert.Equal(t, path, key.Path)
+	assert.Equal(t, uint8(2), key.Depth)
+
+	// the other party derives the same keys
+	_, otherKey, err := configs[partyIDs[1]].DerivePath(path)
+	require.NoError(t, err)
+	assert.Equal(t, key.String(), otherKey.String())
+
+	children, err := c.EnumerateChildren(path[:1], 0, 5)
+	require.NoError(t, err)
+	require.Len(t, children, 5)
+	assert.Equal(t, key.String(), children[2].String())
+
+	_, err = config.ParseDerivationPath("m/0'/1")
+	assert.Error(t, err)
+	_, err = config.ParseDerivationPath("m/2147483648")
+	assert.Error(t, err)
+}
+
+func TestPublicConfigDerive(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	c := configs[partyIDs[0]]
+
+	// an address server only receives the public data
+	data, err := c.PublicConfig().MarshalBinary()
+	require.NoError(t, err)
+	public := config.EmptyPublicConfig(group)
+	require.NoError(t, public.UnmarshalBinary(data))
+	assert.True(t, c.PublicPoint().Equal(public.PublicPoint()))
+
+	path, err := config.ParseDerivationPath("m/0/7/3")
+	require.NoError(t, err)
+	derived, key, err := c.DerivePath(path)
+	require.NoError(t, err)
+	derivedPublic, publicKey, err := public.DerivePath(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, key.String(), publicKey.String())
+	assert.True(t, derived.PublicPoint().Equal(derivedPublic.PublicPoint()))
+	assert.Equal(t, derived.ChainKey, derivedPublic.ChainKey)
+	for _, id := range partyIDs {
+		assert.True(t, derived.Public[id].ECDSA.Equal(derivedPublic.Public[id].ECDSA))
+	}
+}
+
+func TestRefreshReceipt(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	before := configs[partyIDs[0]].PublicConfig()
+	var err error
+
+	// simulate a refresh by adding a sharing of 0 to all public shares, and sampling a new RID
+	zero := polynomial.NewPolynomial(group, before.Threshold, group.NewScalar())
+	after := *before
+	after.Public = make(map[party.ID]*config.Public, len(before.Public))
+	for j, public := range before.Public {
+		refreshed := *public
+		refreshed.ECDSA = public.ECDSA.Add(zero.Evaluate(j.Scalar(group)).ActOnBase())
+		after.Public[j] = &refreshed
+	}
+	after.RID, err = types.NewRID(rand.Reader)
+	require.NoError(t, err)
+
+	receipt, err := config.NewRefreshReceipt(before, &after)
+	require.NoError(t, err)
+	assert.NoError(t, receipt.Verify(before, &after))
+
+	data, err := receipt.MarshalBinary()
+	require.NoError(t, err)
+	decoded := config.EmptyRefreshReceipt(group)
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.NoError(t, decoded.Verify(before, &after))
+
+	_, err = config.NewRefreshReceipt(before, before)
+	assert.Error(t, err, "a config is not a refresh of itself")
+
+	rotated := after
+	rotated.ChainKey = types.RID(make([]byte, 32))
+	_, err = config.NewRefreshReceipt(before, &rotated)
+	assert.Error(t, err, "the chain key must be preserved")
+	assert.Error(t, receipt.Verify(before, &rotated))
+
+	other, _ := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	assert.Error(t, receipt.Verify(other[partyIDs[0]].PublicConfig(), &after), "the public key must be preserved")
+}
+
+func TestPublicCommitment(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	c := configs[partyIDs[0]].PublicConfig()
+	id := partyIDs[1]
+
+	commitment, opening, err := c.CommitPublic(id)
+	require.NoError(t, err)
+	assert.False(t, commitment.Blinded.Equal(c.Public[id].ECDSA))
+	assert.NoError(t, c.VerifyPublic(commitment, opening))
+	fingerprint, err := commitment.Fingerprint()
+	require.NoError(t, err)
+	assert.Len(t, fingerprint, 24)
+
+	data, err := commitment.MarshalBinary()
+	require.NoError(t, err)
+	decoded := config.EmptyPublicCommitment(group)
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	data, err = opening.MarshalBinary()
+	require.NoError(t, err)
+	decodedOpening := config.EmptyPublicOpening(group)
+	require.NoError(t, decodedOpening.UnmarshalBinary(data))
+	assert.NoError(t, decoded.Verify(c.Public[id], decodedOpening))
+	decodedFingerprint, err := decoded.Fingerprint()
+	require.NoError(t, err)
+	assert.Equal(t, fingerprint, decodedFingerprint)
+
+	// a commitment is bound to its party and to every key of the entry
+	assert.Error(t, commitment.Verify(c.Public[partyIDs[2]], opening))
+	swapped := *c.Public[id]
+	swapped.ElGamal = c.Public[partyIDs[2]].ElGamal
+	assert.Error(t, commitment.Verify(&swapped, opening))
+	other := *commitment
+	other.ID = partyIDs[2]
+	assert.Error(t, c.VerifyPublic(&other, opening))
+
+	otherCommitment, otherOpening, err := c.CommitPublic(id)
+	require.NoError(t, err)
+	assert.False(t, otherCommitment.Blinded.Equal(commitment.Blinded), "commitments are randomized")
+	assert.Error(t, commitment.Verify(c.Public[id], otherOpening))
+
+	_, _, err = c.CommitPublic("unknown")
+	assert.Error(t, err)
+}
+
+func TestPolicy(t *testing.T) {
+	ids := test.PartyIDs(3)
+	allowed := []byte("bc1qallowed")
+	policy := &config.Policy{
+		MaxAmount:           100,
+		AllowedDestinations: [][]byte{config.DestinationHash([]byte("bc1qother")), config.DestinationHash(allowed)},
+		RequiredSigners:     []party.ID{ids[2]},
+	}
+
+	assert.NoError(t, policy.Evaluate(ids, &config.PolicyRequest{Amount: 100, Destination: allowed}))
+	assert.Error(t, policy.Evaluate(ids, &config.PolicyRequest{Amount: 101, Destination: allowed}), "amount limit")
+	assert.Error(t, policy.Evaluate(ids, &config.PolicyRequest{Amount: 1, Destination: []byte("bc1qunknown")}), "destination")
+	assert.Error(t, policy.Evaluate(ids[:2], &config.PolicyRequest{Amount: 1, Destination: allowed}), "required signer")
+	assert.Error(t, policy.Evaluate(ids, nil))
+	assert.NoError(t, (&config.Policy{}).Evaluate(ids[:1], &config.PolicyRequest{Amount: 1 << 60}), "empty policy allows everything")
+
+	// the digest does not depend on the order of the lists
+	reordered := &config.Policy{
+		MaxAmount:           100,
+		AllowedDestinations: [][]byte{policy.AllowedDestinations[1], policy.AllowedDestinations[0]},
+		RequiredSigners:     policy.RequiredSigners,
+	}
+	assert.Equal(t, policy.Digest(), reordered.Digest())
+	assert.NotEqual(t, policy.Digest(), (&config.Policy{MaxAmount: 99}).Digest())
+
+	data, err := policy.MarshalBinary()
+	require.NoError(t, err)
+	var decoded config.Policy
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, policy.Digest(), decoded.Digest())
+}
+
+func TestRedact(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	configs, partyIDs := test.GenerateConfig(curve.Secp256k1{}, 2, 1, rand.Reader, pl)
+	c := configs[partyIDs[0]]
+	redacted := c.Redact()
+	data, err := json.Marshal(redacted)
+	require.NoError(t, err)
+
+	dump := string(data)
+	for _, secret := range []string{
+		curve.ScalarToHex(c.ECDSA),
+		curve.ScalarToHex(c.ElGamal),
+		hex.EncodeToString(c.Paillier.P().Bytes()),
+		hex.EncodeToString(c.Paillier.Q().Bytes()),
+	} {
+		assert.NotContains(t, dump, secret)
+	}
+	assert.Contains(t, dump, curve.ToHexCompressed(c.Public[c.ID].ECDSA))
+	assert.Len(t, redacted.Public, len(partyIDs))
+
+	// the digests are stable, and differ between parties
+	assert.Equal(t, redacted.ECDSA, c.Redact().ECDSA)
+	assert.NotEqual(t, redacted.ECDSA, configs[partyIDs[1]].Redact().ECDSA)
+}
+
+func TestAddress(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	c := configs[partyIDs[0]]
+
+	path, err := config.ParseDerivationPath("m/44/60/0/0/5")
+	require.NoError(t, err)
+	derived, _, err := c.DerivePath(path)
+	require.NoError(t, err)
+
+	for _, profile := range append(ecdsa.Profiles(), ecdsa.ProfileBitcoinTaproot) {
+		address, err := c.Address(profile)
+		require.NoError(t, err, profile.Name)
+		expected, err := profile.Address(c.PublicPoint())
+		require.NoError(t, err, profile.Name)
+		assert.Equal(t, expected, address, profile.Name)
+		publicAddress, err := c.PublicConfig().Address(profile)
+		require.NoError(t, err, profile.Name)
+		assert.Equal(t, address, publicAddress, profile.Name)
+
+		// deriving only the extended key gives the address of the derived config
+		childAddress, err := c.DeriveAddress(profile, path)
+		require.NoError(t, err, profile.Name)
+		expected, err = derived.Address(profile)
+		require.NoError(t, err, profile.Name)
+		assert.Equal(t, expected, childAddress, profile.Name)
+		assert.NotEqual(t, address, childAddress, profile.Name)
+	}
+
+	_, err = c.DeriveAddress(ecdsa.ProfileEthereum, config.DerivationPath{1 << 31})
+	assert.Error(t, err)
+}
+
+func TestStore(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	configs, partyIDs := test.GenerateConfig(curve.Secp256k1{}, 3, 1, rand.Reader, pl)
+	kv, err := config.NewDirKV(t.TempDir())
+	require.NoError(t, err)
+	for _, backend := range []config.KV{config.NewMemoryKV(), kv} {
+		s := config.NewStore(backend, 1)
+		key := configs[partyIDs[0]].KeyFingerprint()
+		for _, id := range partyIDs {
+			assert.Equal(t, key, configs[id].KeyFingerprint(), "the key fingerprint is the same for all parties")
+			version, err := s.Save(configs[id])
+			require.NoError(t, err)
+			assert.EqualValues(t, 1, version)
+		}
+		_, _, err := s.Latest(key, "unknown")
+		assert.ErrorIs(t, err, config.ErrNotFound)
+
+		// saving the same config does not add a version
+		c := configs[partyIDs[0]]
+		version, err := s.Save(c)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, version)
+
+		// refreshed configs are added as new versions, and only one previous version is retained
+		var rids []types.RID
+		for i := 0; i < 3; i++ {
+			refreshed := *c
+			refreshed.RID, err = types.NewRID(rand.Reader)
+			require.NoError(t, err)
+			rids = append(rids, refreshed.RID)
+			version, err = s.Save(&refreshed)
+			require.NoError(t, err)
+			assert.EqualValues(t, i+2, version)
+		}
+		assert.Equal(t, key, c.KeyFingerprint())
+		latest, version, err := s.Latest(key, c.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, 4, version)
+		assert.Equal(t, rids[2], latest.RID)
+		previous, err := s.Load(key, c.ID, 3)
+		require.NoError(t, err)
+		assert.Equal(t, rids[1], previous.RID)
+		_, err = s.Load(key, c.ID, 2)
+		assert.ErrorIs(t, err, config.ErrNotFound)
+
+		entries, err := s.List()
+		require.NoError(t, err)
+		assert.Equal(t, []config.StoreEntry{
+			{KeyFingerprint: key, ID: partyIDs[0], Version: 3},
+			{KeyFingerprint: key, ID: partyIDs[0], Version: 4},
+			{KeyFingerprint: key, ID: partyIDs[1], Version: 1},
+			{KeyFingerprint: key, ID: partyIDs[2], Version: 1},
+		}, entries)
+	}
+
+	keys, err := kv.List("")
+	require.NoError(t, err)
+	assert.Contains(t, keys, configs[partyIDs[1]].KeyFingerprint()+"/"+string(partyIDs[1])+"/00000001.json")
+}
+
+func TestWeights(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	weights := party.Weights{test.PartyIDs(3)[0]: 3}
+	configs, partyIDs := test.GenerateWeightedConfig(group, 3, 2, weights, rand.Reader, pl)
+	unweighted, _ := test.GenerateConfig(group, 3, 2, rand.Reader, pl)
+	c := configs[partyIDs[0]]
+	require.NoError(t, c.Validate())
+	assert.Equal(t, weights, c.Weights())
+	assert.Equal(t, weights, c.PublicConfig().Weights())
+	assert.Nil(t, unweighted[partyIDs[0]].Weights())
+
+	// the weighted party and any other party hold enough shares
+	assert.True(t, c.CanSign(partyIDs[:2]))
+	assert.False(t, configs[partyIDs[1]].CanSign(partyIDs[1:]))
+
+	// the additional shares survive all encodings
+	data, err := c.MarshalBinary()
+	require.NoError(t, err)
+	for _, format := range []config.FormatVersion{config.FormatCBOR, config.FormatJSON, config.FormatEnvelope} {
+		out, err := config.Migrate(group, data, config.FormatCBOR, format)
+		require.NoError(t, err)
+		decoded, err := config.Decode(group, out, format)
+		require.NoError(t, err)
+		assert.Equal(t, c.Fingerprint(), decoded.Fingerprint())
+		require.Len(t, decoded.Shares, 2)
+		assert.True(t, c.Shares[1].Equal(decoded.Shares[1]))
+		assert.NoError(t, decoded.Validate())
+	}
+	stripped := *c
+	stripped.Shares = nil
+	assert.Error(t, stripped.Validate(), "additional shares do not match public data")
+
+	// the public key is the sum of the additive shares of any valid set of signers
+	signers := partyIDs[:2]
+	sum := group.NewPoint()
+	for _, share := range c.PublicConfig().AdditivePublicShares(signers) {
+		sum = sum.Add(share)
+	}
+	assert.True(t, sum.Equal(c.PublicPoint()))
+	assert.True(t, c.AdditiveShare(signers).ActOnBase().Equal(c.PublicConfig().AdditivePublicShares(signers)[c.ID]))
+}
+
+func TestPending(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	group := curve.Secp256k1{}
+	configs, partyIDs := test.GenerateConfig(group, 3, 1, rand.Reader, pl)
+	c := configs[partyIDs[0]]
+	c.Pending = []party.ID{"d", "e"}
+	require.NoError(t, c.Validate())
+	assert.True(t, c.PublicConfig().IsPending("d"))
+	assert.False(t, c.PublicConfig().IsPending(partyIDs[1]))
+
+	// the pending parties survive all encodings, and do not change the fingerprint
+	data, err := c.MarshalBinary()
+	require.NoError(t, err)
+	for _, format := range []config.FormatVersion{config.FormatCBOR, config.FormatJSON, config.FormatEnvelope} {
+		out, err := config.Migrate(group, data, config.FormatCBOR, format)
+		require.NoError(t, err)
+		decoded, err := config.Decode(group, out, format)
+		require.NoError(t, err)
+		assert.Equal(t, c.Pending, decoded.Pending)
+		assert.Equal(t, configs[partyIDs[1]].Fingerprint(), decoded.Fingerprint())
+	}
+	publicData, err := c.PublicConfig().MarshalBinary()
+	require.NoError(t, err)
+	public := config.EmptyPublicConfig(group)
+	require.NoError(t, public.UnmarshalBinary(publicData))
+	assert.Equal(t, c.Pending, public.Pending)
+
+	invalid := *c
+	invalid.Pending = []party.ID{partyIDs[1]}
+	assert.Error(t, invalid.Validate(), "pending party already holds a share")
+	invalid.Pending = []party.ID{"e", "d"}
+	assert.Error(t, invalid.Validate(), "pending parties are not sorted")
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/elgamal"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// ElGamalPublic returns the ElGamal public key of the party id.
+func (c *Config) ElGamalPublic(id party.ID) (elgamal.PublicKey, error) {
+	public, ok := c.Public[id]
+	if !ok || public.ElGamal == nil {
+		return nil, fmt.Errorf("config: no ElGamal key for party %s", id)
+	}
+	return public.ElGamal, nil
+}
+
+// SealTo encrypts payload to the ElGamal key of the party to, see elgamal.Seal.
+func (c *Config) SealTo(rand io.Reader, to party.ID, payload, associatedData []byte) (*elgamal.Envelope, error) {
+	public, err := c.ElGamalPublic(to)
+	if err != nil {
+		return nil, err
+	}
+	return elgamal.Seal(rand, public, payload, associatedData)
+}
+
+// Open decrypts an envelope sealed to the ElGamal key of this party, see elgamal.Open.
+func (c *Config) Open(env *elgamal.Envelope, associatedData []byte) ([]byte, error) {
+	return elgamal.Open(c.ElGamal, env, associatedData)
+}
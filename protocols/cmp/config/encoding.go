@@ -0,0 +1,109 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/taurusgroup/multi-party-sig/internal/base58"
+	"github.com/taurusgroup/multi-party-sig/internal/bech32"
+	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+)
+
+// PublicKeyHex returns the hex encoding of the compressed public key of c.
+func (c *Config) PublicKeyHex() string {
+	return curve.ToHexCompressed(c.PublicPoint())
+}
+
+// PublicKeyBech32 returns the compressed public key of c encoded in Bech32,
+// with the human readable part hrp (for example "mpc").
+func (c *Config) PublicKeyBech32(hrp string) (string, error) {
+	data, err := c.PublicPoint().MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return bech32.Encode(hrp, data)
+}
+
+// PublicKeyBase58Check returns the compressed public key of c encoded in Base58Check,
+// prefixed with the given version bytes.
+func (c *Config) PublicKeyBase58Check(version []byte) (string, error) {
+	data, err := c.PublicPoint().MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return base58.CheckEncode(version, data), nil
+}
+
+// Address returns the address of the public key of c on the chain of profile,
+// for example ecdsa.ProfileBitcoinSegwit or ecdsa.ProfileEthereum.
+func (c *Config) Address(profile *ecdsa.Profile) (string, error) {
+	return profile.Address(c.PublicPoint())
+}
+
+// DeriveAddress returns the address of the child of c at the given path on the chain of profile.
+func (c *Config) DeriveAddress(profile *ecdsa.Profile, path DerivationPath) (string, error) {
+	return c.PublicConfig().DeriveAddress(profile, path)
+}
+
+// Address returns the address of the public key of c on the chain of profile.
+func (c *PublicConfig) Address(profile *ecdsa.Profile) (string, error) {
+	return profile.Address(c.PublicPoint())
+}
+
+// DeriveAddress returns the address of the child of c at the given path on the chain of profile.
+// Only the extended public key is derived, so that an address server can derive addresses cheaply.
+func (c *PublicConfig) DeriveAddress(profile *ecdsa.Profile, path DerivationPath) (string, error) {
+	key, err := c.ExtendedKey()
+	if err != nil {
+		return "", err
+	}
+	for _, i := range path {
+		if key, err = key.Child(i); err != nil {
+			return "", err
+		}
+	}
+	return key.Address(profile)
+}
+
+// Address returns the address of the public key of k on the chain of profile.
+func (k *ExtendedKey) Address(profile *ecdsa.Profile) (string, error) {
+	return profile.Address(k.PublicKey)
+}
+
+// ParsePublicKeyBech32 parses a public key encoded by Config.PublicKeyBech32,
+// and checks that its human readable part is hrp.
+func ParsePublicKeyBech32(group curve.Curve, hrp, s string) (curve.Point, error) {
+	decodedHRP, data, err := bech32.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	if decodedHRP != hrp {
+		return nil, fmt.Errorf("config: expected human readable part %q, got %q", hrp, decodedHRP)
+	}
+	return unmarshalPublicKey(group, data)
+}
+
+// ParsePublicKeyBase58Check parses a public key encoded by Config.PublicKeyBase58Check,
+// and checks that its version prefix is version.
+func ParsePublicKeyBase58Check(group curve.Curve, version []byte, s string) (curve.Point, error) {
+	decodedVersion, data, err := base58.CheckDecode(s, len(version))
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	if !bytes.Equal(decodedVersion, version) {
+		return nil, fmt.Errorf("config: expected version %x, got %x", version, decodedVersion)
+	}
+	return unmarshalPublicKey(group, data)
+}
+
+func unmarshalPublicKey(group curve.Curve, data []byte) (curve.Point, error) {
+	p := group.NewPoint()
+	if err := p.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("config: invalid public key: %w", err)
+	}
+	if p.IsIdentity() {
+		return nil, fmt.Errorf("config: public key is identity")
+	}
+	return p, nil
+}
@@ -30,6 +30,14 @@ type configMarshal struct {
 	P, Q           *saferith.Nat
 	RID, ChainKey  types.RID
 	Public         []cbor.RawMessage
+	// Epoch is omitted when it is 0, so that configs which were never refreshed keep their encoding.
+	Epoch       uint64          `cbor:",omitempty"`
+	Dealt       bool            `cbor:",omitempty"`
+	Certificate cbor.RawMessage `cbor:",omitempty"`
+	CeremonyLog []RoundDigest   `cbor:",omitempty"`
+	// Shares are the additional ECDSA shares of a weighted party, omitted otherwise.
+	Shares  [][]byte   `cbor:",omitempty"`
+	Pending []party.ID `cbor:",omitempty"`
 }
 
 type publicMarshal struct {
@@ -37,12 +45,18 @@ type publicMarshal struct {
 	ECDSA, ElGamal curve.Point
 	N              *saferith.Modulus
 	S, T           *saferith.Nat
+	Shares         [][]byte `cbor:",omitempty"`
 }
 
-func (c *Config) MarshalBinary() ([]byte, error) {
-	ps := make([]cbor.RawMessage, 0, len(c.Public))
-	for _, id := range c.PartyIDs() {
-		p := c.Public[id]
+// marshalPublic encodes the public data of all parties, sorted by party.ID.
+func marshalPublic(public map[party.ID]*Public) ([]cbor.RawMessage, error) {
+	ids := make([]party.ID, 0, len(public))
+	for id := range public {
+		ids = append(ids, id)
+	}
+	ps := make([]cbor.RawMessage, 0, len(public))
+	for _, id := range party.NewIDSlice(ids) {
+		p := public[id]
 		pm := &publicMarshal{
 			ID:      id,
 			ECDSA:   p.ECDSA,
@@ -51,22 +65,96 @@ func (c *Config) MarshalBinary() ([]byte, error) {
 			S:       p.Pedersen.S(),
 			T:       p.Pedersen.T(),
 		}
+		for _, share := range p.Shares {
+			data, err := share.MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			pm.Shares = append(pm.Shares, data)
+		}
 		data, err := cbor.Marshal(pm)
 		if err != nil {
 			return nil, err
 		}
 		ps = append(ps, data)
 	}
+	return ps, nil
+}
+
+// unmarshalPublic decodes and validates the public data of a single party.
+func unmarshalPublic(group curve.Curve, data cbor.RawMessage) (party.ID, *Public, error) {
+	p := &publicMarshal{
+		ECDSA:   group.NewPoint(),
+		ElGamal: group.NewPoint(),
+	}
+	if err := cbor.Unmarshal(data, p); err != nil {
+		return p.ID, nil, fmt.Errorf("config: party %s: %w", p.ID, err)
+	}
+	if err := paillier.ValidateN(p.N); err != nil {
+		return p.ID, nil, fmt.Errorf("config: party %s: %w", p.ID, err)
+	}
+	if err := pedersen.ValidateParameters(p.N, p.S, p.T); err != nil {
+		return p.ID, nil, fmt.Errorf("config: party %s: %w", p.ID, err)
+	}
+	if p.ECDSA.IsIdentity() || p.ElGamal.IsIdentity() {
+		return p.ID, nil, fmt.Errorf("config: party %s: ECDSA or ElGamal public key is identity", p.ID)
+	}
+	var shares []curve.Point
+	if len(p.Shares) > 0 {
+		var err error
+		if shares, err = curve.UnmarshalPoints(group, p.Shares); err != nil {
+			return p.ID, nil, fmt.Errorf("config: party %s: %w", p.ID, err)
+		}
+		for _, share := range shares {
+			if share.IsIdentity() {
+				return p.ID, nil, fmt.Errorf("config: party %s: ECDSA public share is identity", p.ID)
+			}
+		}
+	}
+
+	paillierPublic := paillier.NewPublicKey(p.N)
+	return p.ID, &Public{
+		ECDSA:    p.ECDSA,
+		ElGamal:  p.ElGamal,
+		Paillier: paillierPublic,
+		Pedersen: pedersen.New(paillierPublic.Modulus(), p.S, p.T),
+		Shares:   shares,
+	}, nil
+}
+
+func (c *Config) MarshalBinary() ([]byte, error) {
+	ps, err := marshalPublic(c.Public)
+	if err != nil {
+		return nil, err
+	}
+	certificate, err := marshalCertificate(c.Certificate)
+	if err != nil {
+		return nil, err
+	}
+	var shares [][]byte
+	for _, share := range c.Shares {
+		data, err := share.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, data)
+	}
 	return cbor.Marshal(&configMarshal{
-		ID:        c.ID,
-		Threshold: c.Threshold,
-		ECDSA:     c.ECDSA,
-		ElGamal:   c.ElGamal,
-		P:         c.Paillier.P(),
-		Q:         c.Paillier.Q(),
-		RID:       c.RID,
-		ChainKey:  c.ChainKey,
-		Public:    ps,
+		ID:          c.ID,
+		Threshold:   c.Threshold,
+		ECDSA:       c.ECDSA,
+		ElGamal:     c.ElGamal,
+		P:           c.Paillier.P(),
+		Q:           c.Paillier.Q(),
+		RID:         c.RID,
+		ChainKey:    c.ChainKey,
+		Public:      ps,
+		Epoch:       c.Epoch,
+		Dealt:       c.Dealt,
+		Certificate: certificate,
+		CeremonyLog: c.CeremonyLog,
+		Shares:      shares,
+		Pending:     c.Pending,
 	})
 }
 
@@ -96,6 +184,20 @@ func (c *Config) UnmarshalBinary(data []byte) error {
 	}
 	paillierSecret := paillier.NewSecretKeyFromPrimes(cm.P, cm.Q)
 
+	var shares []curve.Scalar
+	var publicShares []curve.Point
+	for _, data := range cm.Shares {
+		share := c.Group.NewScalar()
+		if err := share.UnmarshalBinary(data); err != nil {
+			return fmt.Errorf("config: additional share: %w", err)
+		}
+		if share.IsZero() {
+			return errors.New("config: additional ECDSA share is zero")
+		}
+		shares = append(shares, share)
+		publicShares = append(publicShares, share.ActOnBase())
+	}
+
 	// handle public parameters
 	ps := make(map[party.ID]*Public, len(cm.Public))
 	for _, pm := range cm.Public {
@@ -117,50 +219,56 @@ func (c *Config) UnmarshalBinary(data []byte) error {
 				ElGamal:  cm.ElGamal.ActOnBase(),
 				Paillier: paillierSecret.PublicKey,
 				Pedersen: pedersen.New(paillierSecret.Modulus(), p.S, p.T),
+				Shares:   publicShares,
 			}
 			continue
 		}
 
-		if err := paillier.ValidateN(p.N); err != nil {
-			return fmt.Errorf("config: party %s: %w", p.ID, err)
-		}
-		if err := pedersen.ValidateParameters(p.N, p.S, p.T); err != nil {
-			return fmt.Errorf("config: party %s: %w", p.ID, err)
-		}
-		if p.ECDSA.IsIdentity() || p.ElGamal.IsIdentity() {
-			return fmt.Errorf("config: party %s: ECDSA or ElGamal public key is identity", p.ID)
-		}
-
-		paillierPublic := paillier.NewPublicKey(p.N)
-		ps[p.ID] = &Public{
-			ECDSA:    p.ECDSA,
-			ElGamal:  p.ElGamal,
-			Paillier: paillierPublic,
-			Pedersen: pedersen.New(paillierPublic.Modulus(), p.S, p.T),
+		_, public, err := unmarshalPublic(c.Group, pm)
+		if err != nil {
+			return err
 		}
+		ps[p.ID] = public
 	}
 
 	// verify number of parties w.r.t. threshold
-	// want 0 ⩽ threshold ⩽ n-1
-	if !ValidThreshold(cm.Threshold, len(ps)) {
+	// want 0 ⩽ threshold ⩽ n-1, where n is the total weight of the parties
+	if !ValidThreshold(cm.Threshold, totalWeight(ps)) {
 		return fmt.Errorf("config: threshold %d is invalid", cm.Threshold)
 	}
+	if err := validateEvaluationPoints(c.Group, ps); err != nil {
+		return err
+	}
 
 	// check that we are included
 	if _, ok := ps[cm.ID]; !ok {
 		return errors.New("config: no public data for this party")
 	}
+	if err := validatePending(ps, cm.Pending); err != nil {
+		return err
+	}
+
+	certificate, err := unmarshalCertificate(c.Group, cm.Certificate)
+	if err != nil {
+		return err
+	}
 
 	*c = Config{
-		Group:     c.Group,
-		ID:        cm.ID,
-		Threshold: cm.Threshold,
-		ECDSA:     cm.ECDSA,
-		ElGamal:   cm.ElGamal,
-		Paillier:  paillierSecret,
-		RID:       cm.RID,
-		ChainKey:  cm.ChainKey,
-		Public:    ps,
+		Group:       c.Group,
+		ID:          cm.ID,
+		Threshold:   cm.Threshold,
+		ECDSA:       cm.ECDSA,
+		Shares:      shares,
+		ElGamal:     cm.ElGamal,
+		Paillier:    paillierSecret,
+		RID:         cm.RID,
+		ChainKey:    cm.ChainKey,
+		Epoch:       cm.Epoch,
+		Dealt:       cm.Dealt,
+		Public:      ps,
+		Certificate: certificate,
+		CeremonyLog: cm.CeremonyLog,
+		Pending:     cm.Pending,
 	}
 	return nil
 }
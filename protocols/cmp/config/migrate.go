@@ -0,0 +1,380 @@
+package config
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cronokirby/saferith"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// FormatVersion identifies a serialization format for a Config.
+type FormatVersion uint
+
+const (
+	// FormatCBOR is the output of Config.MarshalBinary.
+	FormatCBOR FormatVersion = 1
+	// FormatJSON is a JSON object where all keys and numbers are hex encoded, see Config.MarshalJSONFormat.
+	FormatJSON FormatVersion = 2
+	// FormatEnvelope is the canonical format: a CBOR envelope containing the format version, the group name,
+	// the fingerprint of the public data and the FormatCBOR encoding of the config.
+	FormatEnvelope FormatVersion = 3
+
+	// LatestFormat is the format new deployments should use.
+	LatestFormat = FormatEnvelope
+)
+
+// Fingerprint returns a hash of all the public data of the config, which is the same for all parties
+// and does not change when the config is serialized in a different format.
+// The ChainKey is included, unlike in the hash state of the protocols, since a corrupted chain key
+// would change every BIP32 derivation without being detected.
+func (c *Config) Fingerprint() []byte {
+	return hash.New(c, &hash.BytesWithDomain{TheDomain: "Chain Key", Bytes: c.ChainKey}).Sum()[:32]
+}
+
+// Validate checks that the secret material of c is consistent with its public data.
+func (c *Config) Validate() error {
+	if c.Group == nil || c.ECDSA == nil || c.ElGamal == nil || c.Paillier == nil {
+		return errors.New("config: missing fields")
+	}
+	if !ValidThreshold(c.Threshold, totalWeight(c.Public)) {
+		return fmt.Errorf("config: threshold %d is invalid", c.Threshold)
+	}
+	self, ok := c.Public[c.ID]
+	if !ok {
+		return errors.New("config: no public data for this party")
+	}
+	if !c.ECDSA.ActOnBase().Equal(self.ECDSA) {
+		return errors.New("config: ECDSA share does not match public data")
+	}
+	if !c.ElGamal.ActOnBase().Equal(self.ElGamal) {
+		return errors.New("config: ElGamal secret does not match public data")
+	}
+	if !c.Paillier.PublicKey.Equal(self.Paillier) {
+		return errors.New("config: Paillier key does not match public data")
+	}
+	if err := validatePending(c.Public, c.Pending); err != nil {
+		return err
+	}
+	return c.validateWeights()
+}
+
+// Migrate converts a serialized config from one format to another.
+// As with Decode, group may be nil if the source format records the name of the group.
+// The config is fully decoded and validated, and the migration fails if the fingerprint
+// of the re-encoded config differs from the original one.
+func Migrate(group curve.Curve, data []byte, from, to FormatVersion) ([]byte, error) {
+	c, err := Decode(group, data, from)
+	if err != nil {
+		return nil, fmt.Errorf("config: migrate: %w", err)
+	}
+	group = c.Group
+	out, err := c.Encode(to)
+	if err != nil {
+		return nil, fmt.Errorf("config: migrate: %w", err)
+	}
+	check, err := Decode(group, out, to)
+	if err != nil {
+		return nil, fmt.Errorf("config: migrate: re-decoding: %w", err)
+	}
+	if !bytes.Equal(c.Fingerprint(), check.Fingerprint()) {
+		return nil, errors.New("config: migrate: fingerprint changed")
+	}
+	return out, nil
+}
+
+// Encode serializes c in the given format.
+func (c *Config) Encode(format FormatVersion) ([]byte, error) {
+	switch format {
+	case FormatCBOR:
+		return c.MarshalBinary()
+	case FormatJSON:
+		return c.MarshalJSONFormat()
+	case FormatEnvelope:
+		data, err := c.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		return cbor.Marshal(&envelope{
+			Version:     FormatEnvelope,
+			Group:       c.Group.Name(),
+			Fingerprint: c.Fingerprint(),
+			Config:      data,
+		})
+	default:
+		return nil, fmt.Errorf("config: unknown format %d", format)
+	}
+}
+
+// Decode parses a config serialized in the given format, and validates it.
+// For FormatJSON and FormatEnvelope, which record the name of the group, group may be nil,
+// in which case it is obtained with curve.ByName.
+func Decode(group curve.Curve, data []byte, format FormatVersion) (*Config, error) {
+	c := EmptyConfig(group)
+	switch format {
+	case FormatCBOR:
+		if err := c.UnmarshalBinary(data); err != nil {
+			return nil, err
+		}
+	case FormatJSON:
+		if err := c.UnmarshalJSONFormat(data); err != nil {
+			return nil, err
+		}
+	case FormatEnvelope:
+		var e envelope
+		if err := cbor.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("config: envelope: %w", err)
+		}
+		if e.Version != FormatEnvelope {
+			return nil, fmt.Errorf("config: envelope: unexpected version %d", e.Version)
+		}
+		if group == nil {
+			var err error
+			if group, err = curve.ByName(e.Group); err != nil {
+				return nil, fmt.Errorf("config: envelope: %w", err)
+			}
+			c.Group = group
+		}
+		if e.Group != group.Name() {
+			return nil, fmt.Errorf("config: envelope: group %s does not match %s", e.Group, group.Name())
+		}
+		if err := c.UnmarshalBinary(e.Config); err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(e.Fingerprint, c.Fingerprint()) {
+			return nil, errors.New("config: envelope: fingerprint mismatch")
+		}
+	default:
+		return nil, fmt.Errorf("config: unknown format %d", format)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+type envelope struct {
+	Version     FormatVersion
+	Group       string
+	Fingerprint []byte
+	Config      cbor.RawMessage
+}
+
+type configJSON struct {
+	Group       string            `json:"group"`
+	ID          party.ID          `json:"id"`
+	Threshold   int               `json:"threshold"`
+	ECDSA       string            `json:"ecdsa"`
+	ElGamal     string            `json:"elgamal"`
+	P           string            `json:"p"`
+	Q           string            `json:"q"`
+	RID         string            `json:"rid"`
+	ChainKey    string            `json:"chainKey"`
+	Epoch       uint64            `json:"epoch,omitempty"`
+	Dealt       bool              `json:"dealt,omitempty"`
+	Public      []publicJSON      `json:"public"`
+	Certificate *certificateJSON  `json:"certificate,omitempty"`
+	CeremonyLog []roundDigestJSON `json:"ceremonyLog,omitempty"`
+	Shares      []string          `json:"shares,omitempty"`
+	Pending     []party.ID        `json:"pending,omitempty"`
+}
+
+type publicJSON struct {
+	ID      party.ID `json:"id"`
+	ECDSA   string   `json:"ecdsa"`
+	ElGamal string   `json:"elgamal"`
+	N       string   `json:"n"`
+	S       string   `json:"s"`
+	T       string   `json:"t"`
+	Shares  []string `json:"shares,omitempty"`
+}
+
+type roundDigestJSON struct {
+	Round  round.Number `json:"round"`
+	Digest string       `json:"digest"`
+}
+
+type certificateJSON struct {
+	Commitment string `json:"commitment"`
+	Response   string `json:"response"`
+}
+
+// MarshalJSONFormat encodes c in FormatJSON, where points are compressed and all values are hex encoded,
+// so that configs can be inspected and compared by operators.
+func (c *Config) MarshalJSONFormat() ([]byte, error) {
+	cj := configJSON{
+		Group:     c.Group.Name(),
+		ID:        c.ID,
+		Threshold: c.Threshold,
+		ECDSA:     curve.ScalarToHex(c.ECDSA),
+		ElGamal:   curve.ScalarToHex(c.ElGamal),
+		P:         hex.EncodeToString(c.Paillier.P().Bytes()),
+		Q:         hex.EncodeToString(c.Paillier.Q().Bytes()),
+		RID:       hex.EncodeToString(c.RID),
+		ChainKey:  hex.EncodeToString(c.ChainKey),
+		Epoch:     c.Epoch,
+		Dealt:     c.Dealt,
+		Pending:   c.Pending,
+	}
+	for _, id := range c.PartyIDs() {
+		p := c.Public[id]
+		cj.Public = append(cj.Public, publicJSON{
+			ID:      id,
+			ECDSA:   curve.ToHexCompressed(p.ECDSA),
+			ElGamal: curve.ToHexCompressed(p.ElGamal),
+			N:       hex.EncodeToString(p.Pedersen.N().Bytes()),
+			S:       hex.EncodeToString(p.Pedersen.S().Bytes()),
+			T:       hex.EncodeToString(p.Pedersen.T().Bytes()),
+		})
+		for _, share := range p.Shares {
+			cj.Public[len(cj.Public)-1].Shares = append(cj.Public[len(cj.Public)-1].Shares, curve.ToHexCompressed(share))
+		}
+	}
+	for _, share := range c.Shares {
+		cj.Shares = append(cj.Shares, curve.ScalarToHex(share))
+	}
+	if k := c.Certificate; k != nil {
+		cj.Certificate = &certificateJSON{
+			Commitment: curve.ToHexCompressed(k.Commitment),
+			Response:   curve.ScalarToHex(k.Response),
+		}
+	}
+	for _, d := range c.CeremonyLog {
+		cj.CeremonyLog = append(cj.CeremonyLog, roundDigestJSON{Round: d.Round, Digest: hex.EncodeToString(d.Digest)})
+	}
+	return json.Marshal(&cj)
+}
+
+// UnmarshalJSONFormat decodes a config in FormatJSON, and applies the same validation as UnmarshalBinary.
+// If c.Group is nil, the group is obtained from its name with curve.ByName.
+func (c *Config) UnmarshalJSONFormat(data []byte) error {
+	var cj configJSON
+	if err := json.Unmarshal(data, &cj); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if c.Group == nil {
+		group, err := curve.ByName(cj.Group)
+		if err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+		c.Group = group
+	}
+	group := c.Group
+	if cj.Group != group.Name() {
+		return fmt.Errorf("config: group %s does not match %s", cj.Group, group.Name())
+	}
+
+	var err error
+	cm := configMarshal{ID: cj.ID, Threshold: cj.Threshold, Epoch: cj.Epoch, Dealt: cj.Dealt, Pending: cj.Pending}
+	if cm.ECDSA, err = curve.ScalarFromHex(group, cj.ECDSA); err != nil {
+		return fmt.Errorf("config: ecdsa: %w", err)
+	}
+	if cm.ElGamal, err = curve.ScalarFromHex(group, cj.ElGamal); err != nil {
+		return fmt.Errorf("config: elgamal: %w", err)
+	}
+	if cm.P, err = natFromHex(cj.P); err != nil {
+		return fmt.Errorf("config: p: %w", err)
+	}
+	if cm.Q, err = natFromHex(cj.Q); err != nil {
+		return fmt.Errorf("config: q: %w", err)
+	}
+	if cm.RID, err = hex.DecodeString(cj.RID); err != nil {
+		return fmt.Errorf("config: rid: %w", err)
+	}
+	if cm.ChainKey, err = hex.DecodeString(cj.ChainKey); err != nil {
+		return fmt.Errorf("config: chain key: %w", err)
+	}
+	for _, pj := range cj.Public {
+		pm := publicMarshal{ID: pj.ID}
+		if pm.ECDSA, err = curve.PointFromHex(group, pj.ECDSA); err != nil {
+			return fmt.Errorf("config: party %s: %w", pj.ID, err)
+		}
+		if pm.ElGamal, err = curve.PointFromHex(group, pj.ElGamal); err != nil {
+			return fmt.Errorf("config: party %s: %w", pj.ID, err)
+		}
+		n, err := natFromHex(pj.N)
+		if err != nil {
+			return fmt.Errorf("config: party %s: %w", pj.ID, err)
+		}
+		pm.N = saferith.ModulusFromNat(n)
+		if pm.S, err = natFromHex(pj.S); err != nil {
+			return fmt.Errorf("config: party %s: %w", pj.ID, err)
+		}
+		if pm.T, err = natFromHex(pj.T); err != nil {
+			return fmt.Errorf("config: party %s: %w", pj.ID, err)
+		}
+		for _, sj := range pj.Shares {
+			share, err := curve.PointFromHex(group, sj)
+			if err != nil {
+				return fmt.Errorf("config: party %s: %w", pj.ID, err)
+			}
+			data, err := share.MarshalBinary()
+			if err != nil {
+				return fmt.Errorf("config: party %s: %w", pj.ID, err)
+			}
+			pm.Shares = append(pm.Shares, data)
+		}
+		raw, err := cbor.Marshal(&pm)
+		if err != nil {
+			return fmt.Errorf("config: party %s: %w", pj.ID, err)
+		}
+		cm.Public = append(cm.Public, raw)
+	}
+	if kj := cj.Certificate; kj != nil {
+		k := &KeyCertificate{}
+		if k.Commitment, err = curve.PointFromHex(group, kj.Commitment); err != nil {
+			return fmt.Errorf("config: certificate: %w", err)
+		}
+		if k.Response, err = curve.ScalarFromHex(group, kj.Response); err != nil {
+			return fmt.Errorf("config: certificate: %w", err)
+		}
+		if cm.Certificate, err = k.MarshalBinary(); err != nil {
+			return fmt.Errorf("config: certificate: %w", err)
+		}
+	}
+
+	for _, sj := range cj.Shares {
+		share, err := curve.ScalarFromHex(group, sj)
+		if err != nil {
+			return fmt.Errorf("config: additional share: %w", err)
+		}
+		data, err := share.MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("config: additional share: %w", err)
+		}
+		cm.Shares = append(cm.Shares, data)
+	}
+
+	for _, dj := range cj.CeremonyLog {
+		digest, err := hex.DecodeString(dj.Digest)
+		if err != nil {
+			return fmt.Errorf("config: ceremony log: %w", err)
+		}
+		cm.CeremonyLog = append(cm.CeremonyLog, RoundDigest{Round: dj.Round, Digest: digest})
+	}
+
+	// reuse the validation performed when decoding the CBOR format
+	encoded, err := cbor.Marshal(&cm)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	return c.UnmarshalBinary(encoded)
+}
+
+func natFromHex(s string) (*saferith.Nat, error) {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(saferith.Nat).SetBytes(data), nil
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// IsPending returns true if id was invited to the keygen of c, and may still join it with keygen.StartJoin.
+func (c *PublicConfig) IsPending(id party.ID) bool {
+	return party.IDSlice(c.Pending).Contains(id)
+}
+
+// validatePending checks that the pending parties are sorted, distinct, and do not hold a share yet.
+func validatePending(public map[party.ID]*Public, pending []party.ID) error {
+	if len(pending) == 0 {
+		return nil
+	}
+	if !party.IDSlice(pending).Valid() {
+		return errors.New("config: pending parties are not sorted or contain duplicates")
+	}
+	for _, id := range pending {
+		if _, ok := public[id]; ok {
+			return fmt.Errorf("config: pending party %s already holds a share", id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// Policy restricts the use of a key for signing. It is stored alongside a Config,
+// and evaluated by each party before it contributes to a signature.
+//
+// All parties must use the same policy, which is enforced by binding its digest to the signing session.
+type Policy struct {
+	// MaxAmount is the largest amount which may be signed for. If zero, amounts are not limited.
+	MaxAmount uint64
+	// AllowedDestinations contains the DestinationHash of every allowed destination.
+	// If empty, all destinations are allowed.
+	AllowedDestinations [][]byte
+	// RequiredSigners must all be part of the signers.
+	RequiredSigners []party.ID
+}
+
+// PolicyRequest describes what a signature authorizes, so that it can be checked against a Policy.
+// The caller is responsible for ensuring that the message being signed matches the request.
+type PolicyRequest struct {
+	Amount      uint64
+	Destination []byte
+}
+
+// DestinationHash returns the hash of a destination, as stored in Policy.AllowedDestinations.
+func DestinationHash(destination []byte) []byte {
+	sum := sha256.Sum256(destination)
+	return sum[:]
+}
+
+// Evaluate returns an error if signing request with signers is not allowed by p.
+func (p *Policy) Evaluate(signers party.IDSlice, request *PolicyRequest) error {
+	if request == nil {
+		return errors.New("policy: no request")
+	}
+	if p.MaxAmount != 0 && request.Amount > p.MaxAmount {
+		return fmt.Errorf("policy: amount %d exceeds limit %d", request.Amount, p.MaxAmount)
+	}
+	if len(p.AllowedDestinations) > 0 {
+		destination := DestinationHash(request.Destination)
+		allowed := false
+		for _, d := range p.AllowedDestinations {
+			if bytes.Equal(d, destination) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return errors.New("policy: destination is not allowed")
+		}
+	}
+	for _, id := range p.RequiredSigners {
+		if !signers.Contains(id) {
+			return fmt.Errorf("policy: required signer %s is missing", id)
+		}
+	}
+	return nil
+}
+
+// Digest returns a hash of p, which does not depend on the order of its lists.
+func (p *Policy) Digest() []byte {
+	data, err := p.MarshalBinary()
+	if err != nil {
+		panic(fmt.Errorf("policy: %w", err))
+	}
+	return hash.New(&hash.BytesWithDomain{TheDomain: "Signing Policy", Bytes: data}).Sum()[:32]
+}
+
+// Digest returns a hash of r.
+func (r *PolicyRequest) Digest() []byte {
+	data, err := cbor.Marshal(r)
+	if err != nil {
+		panic(fmt.Errorf("policy: %w", err))
+	}
+	return hash.New(&hash.BytesWithDomain{TheDomain: "Signing Policy Request", Bytes: data}).Sum()[:32]
+}
+
+// policyMarshal has the fields of Policy, without its marshalling methods.
+type policyMarshal Policy
+
+// MarshalBinary returns a canonical encoding of p.
+func (p *Policy) MarshalBinary() ([]byte, error) {
+	destinations := make([][]byte, len(p.AllowedDestinations))
+	copy(destinations, p.AllowedDestinations)
+	sort.Slice(destinations, func(i, j int) bool { return bytes.Compare(destinations[i], destinations[j]) < 0 })
+	return cbor.Marshal(&policyMarshal{
+		MaxAmount:           p.MaxAmount,
+		AllowedDestinations: destinations,
+		RequiredSigners:     party.NewIDSlice(p.RequiredSigners),
+	})
+}
+
+func (p *Policy) UnmarshalBinary(data []byte) error {
+	var decoded policyMarshal
+	if err := cbor.Unmarshal(data, &decoded); err != nil {
+		return fmt.Errorf("policy: %w", err)
+	}
+	for _, d := range decoded.AllowedDestinations {
+		if len(d) != sha256.Size {
+			return fmt.Errorf("policy: invalid destination hash length %d", len(d))
+		}
+	}
+	if !party.NewIDSlice(decoded.RequiredSigners).Valid() {
+		return errors.New("policy: required signers contains duplicates")
+	}
+	*p = Policy(decoded)
+	return nil
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/taurusgroup/multi-party-sig/internal/bip32"
+	"github.com/taurusgroup/multi-party-sig/internal/params"
+	"github.com/taurusgroup/multi-party-sig/internal/types"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// PublicConfig is the public part of a Config, which is the same for all parties.
+// It contains no secrets, and can be given to services which only need to derive public keys,
+// such as address generation servers.
+type PublicConfig struct {
+	// Group returns the Elliptic Curve Group associated with this config.
+	Group curve.Curve
+	// Threshold is the integer t which defines the maximum number of corruptions tolerated for this config.
+	Threshold int
+	// RID is a 32 byte random identifier generated for this config
+	RID types.RID
+	// ChainKey is the chaining key value associated with this public key
+	ChainKey types.RID
+	// Public maps party.ID to public. It contains all public information associated to a party.
+	Public map[party.ID]*Public
+	// Certificate proves that the parties hold the key, see KeyCertificate.
+	Certificate *KeyCertificate
+	// CeremonyLog records the execution which produced the config, see Config.CeremonyLog.
+	CeremonyLog []RoundDigest
+	// Epoch is the number of times the key was refreshed, see Config.Epoch.
+	Epoch uint64
+	// Dealt is true if the key was created by a dealer, see Config.Dealt.
+	Dealt bool
+	// Pending contains the parties which may still join the key, see Config.Pending.
+	Pending []party.ID
+}
+
+// EmptyPublicConfig creates an empty PublicConfig with a fixed group, ready for unmarshalling.
+func EmptyPublicConfig(group curve.Curve) *PublicConfig {
+	return &PublicConfig{
+		Group: group,
+	}
+}
+
+// PublicConfig returns the public part of c.
+func (c *Config) PublicConfig() *PublicConfig {
+	return &PublicConfig{
+		Group:       c.Group,
+		Threshold:   c.Threshold,
+		RID:         c.RID,
+		ChainKey:    c.ChainKey,
+		Public:      c.Public,
+		Certificate: c.Certificate,
+		CeremonyLog: c.CeremonyLog,
+		Epoch:       c.Epoch,
+		Dealt:       c.Dealt,
+		Pending:     c.Pending,
+	}
+}
+
+// PublicPoint returns the group's public ECC point.
+func (c *PublicConfig) PublicPoint() curve.Point {
+	sum := c.Group.NewPoint()
+	for _, share := range c.AdditivePublicShares(c.PartyIDs()) {
+		sum = sum.Add(share)
+	}
+	return sum
+}
+
+// PartyIDs returns a sorted slice of party IDs.
+func (c *PublicConfig) PartyIDs() party.IDSlice {
+	ids := make([]party.ID, 0, len(c.Public))
+	for j := range c.Public {
+		ids = append(ids, j)
+	}
+	return party.NewIDSlice(ids)
+}
+
+// Derive adds adjust•G to the public key of every party, and sets the chain key to newChainKey.
+// This matches Config.Derive with the same arguments, without requiring any secret share.
+func (c *PublicConfig) Derive(adjust curve.Scalar, newChainKey []byte) (*PublicConfig, error) {
+	if len(newChainKey) <= 0 {
+		newChainKey = c.ChainKey
+	}
+	if len(newChainKey) != params.SecBytes {
+		return nil, fmt.Errorf("expected %d bytes for chain key, found %d", params.SecBytes, len(newChainKey))
+	}
+	adjustG := adjust.ActOnBase()
+
+	public := make(map[party.ID]*Public, len(c.Public))
+	for k, v := range c.Public {
+		public[k] = &Public{
+			ECDSA:    v.ECDSA.Add(adjustG),
+			ElGamal:  v.ElGamal,
+			Paillier: v.Paillier,
+			Pedersen: v.Pedersen,
+			Shares:   addToShares(v.Shares, adjustG),
+		}
+	}
+
+	return &PublicConfig{
+		Group:       c.Group,
+		Threshold:   c.Threshold,
+		RID:         c.RID,
+		ChainKey:    newChainKey,
+		Public:      public,
+		CeremonyLog: c.CeremonyLog,
+		Epoch:       c.Epoch,
+		Dealt:       c.Dealt,
+		Pending:     c.Pending,
+	}, nil
+}
+
+// DeriveBIP32 derives the public data of the ith child of the consortium signing key,
+// which is equal to the public part of the Config returned by Config.DeriveBIP32.
+//
+// This function will panic if i ⩾ 2³¹, since that indicates a hardened key.
+func (c *PublicConfig) DeriveBIP32(i uint32) (*PublicConfig, error) {
+	publicPoint, ok := c.PublicPoint().(*curve.Secp256k1Point)
+	if !ok {
+		return nil, errors.New("DeriveBIP32 must be called with secp256k1")
+	}
+	scalar, newChainKey, err := bip32.DeriveScalar(publicPoint, c.ChainKey, i)
+	if err != nil {
+		return nil, err
+	}
+	return c.Derive(scalar, newChainKey)
+}
+
+// ExtendedKey returns the root extended public key of c.
+func (c *PublicConfig) ExtendedKey() (*ExtendedKey, error) {
+	if len(c.ChainKey) != 32 {
+		return nil, fmt.Errorf("config: expected 32 bytes for chain key, found %d", len(c.ChainKey))
+	}
+	return &ExtendedKey{
+		ChainCode: c.ChainKey,
+		PublicKey: c.PublicPoint(),
+		Path:      DerivationPath{},
+	}, nil
+}
+
+// DerivePath derives the public data of the child at the given path, and returns it along with its extended public key.
+func (c *PublicConfig) DerivePath(path DerivationPath) (*PublicConfig, *ExtendedKey, error) {
+	key, err := c.ExtendedKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	child := c
+	for _, i := range path {
+		if child, err = child.DeriveBIP32(i); err != nil {
+			return nil, nil, fmt.Errorf("config: deriving %s: %w", key.Path, err)
+		}
+		if key, err = key.Child(i); err != nil {
+			return nil, nil, err
+		}
+	}
+	return child, key, nil
+}
+
+type publicConfigMarshal struct {
+	Threshold     int
+	RID, ChainKey types.RID
+	Public        []cbor.RawMessage
+	Certificate   cbor.RawMessage `cbor:",omitempty"`
+	CeremonyLog   []RoundDigest   `cbor:",omitempty"`
+	Epoch         uint64          `cbor:",omitempty"`
+	Dealt         bool            `cbor:",omitempty"`
+	Pending       []party.ID      `cbor:",omitempty"`
+}
+
+func (c *PublicConfig) MarshalBinary() ([]byte, error) {
+	ps, err := marshalPublic(c.Public)
+	if err != nil {
+		return nil, err
+	}
+	certificate, err := marshalCertificate(c.Certificate)
+	if err != nil {
+		return nil, err
+	}
+	return cbor.Marshal(&publicConfigMarshal{
+		Threshold:   c.Threshold,
+		RID:         c.RID,
+		ChainKey:    c.ChainKey,
+		Public:      ps,
+		Certificate: certificate,
+		CeremonyLog: c.CeremonyLog,
+		Epoch:       c.Epoch,
+		Dealt:       c.Dealt,
+		Pending:     c.Pending,
+	})
+}
+
+func (c *PublicConfig) UnmarshalBinary(data []byte) error {
+	if c.Group == nil {
+		return errors.New("config must be initialized using EmptyPublicConfig")
+	}
+	var cm publicConfigMarshal
+	if err := cbor.Unmarshal(data, &cm); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	ps := make(map[party.ID]*Public, len(cm.Public))
+	for _, pm := range cm.Public {
+		id, public, err := unmarshalPublic(c.Group, pm)
+		if err != nil {
+			return err
+		}
+		if _, ok := ps[id]; ok {
+			return fmt.Errorf("config: party %s: duplicate entry", id)
+		}
+		ps[id] = public
+	}
+	if !ValidThreshold(cm.Threshold, totalWeight(ps)) {
+		return fmt.Errorf("config: threshold %d is invalid", cm.Threshold)
+	}
+	if err := validateEvaluationPoints(c.Group, ps); err != nil {
+		return err
+	}
+	if err := validatePending(ps, cm.Pending); err != nil {
+		return err
+	}
+	certificate, err := unmarshalCertificate(c.Group, cm.Certificate)
+	if err != nil {
+		return err
+	}
+	*c = PublicConfig{
+		Group:       c.Group,
+		Threshold:   cm.Threshold,
+		RID:         cm.RID,
+		ChainKey:    cm.ChainKey,
+		Public:      ps,
+		Certificate: certificate,
+		CeremonyLog: cm.CeremonyLog,
+		Epoch:       cm.Epoch,
+		Dealt:       cm.Dealt,
+		Pending:     cm.Pending,
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+)
+
+// RefreshReceipt attests that a refresh preserved the public key and the chain key of a config,
+// so that custodians can demonstrate the continuity of a key to auditors.
+//
+// It only refers to public data, and is checked against the public configs before and after the refresh
+// with Verify, without access to any party.
+type RefreshReceipt struct {
+	// PreviousFingerprint and Fingerprint are the fingerprints of the configs before and after the refresh.
+	PreviousFingerprint []byte
+	Fingerprint         []byte
+	// PublicKey is the public key shared by both configs.
+	PublicKey curve.Point
+	// ChainKeyDigest is a commitment to the chain key shared by both configs,
+	// which does not reveal the chain key itself.
+	ChainKeyDigest []byte
+}
+
+// Fingerprint returns a hash of the public data of c, equal to Config.Fingerprint.
+func (c *PublicConfig) Fingerprint() []byte {
+	return (&Config{Group: c.Group, Threshold: c.Threshold, RID: c.RID, ChainKey: c.ChainKey, Public: c.Public, Epoch: c.Epoch, Dealt: c.Dealt, CeremonyLog: c.CeremonyLog}).Fingerprint()
+}
+
+// NewRefreshReceipt returns the receipt of the refresh of before into after,
+// or an error if the refresh did not preserve the key.
+func NewRefreshReceipt(before, after *PublicConfig) (*RefreshReceipt, error) {
+	if err := checkContinuity(before, after); err != nil {
+		return nil, err
+	}
+	return &RefreshReceipt{
+		PreviousFingerprint: before.Fingerprint(),
+		Fingerprint:         after.Fingerprint(),
+		PublicKey:           after.PublicPoint(),
+		ChainKeyDigest:      chainKeyDigest(after),
+	}, nil
+}
+
+// Verify checks that r is the receipt of the refresh of before into after.
+func (r *RefreshReceipt) Verify(before, after *PublicConfig) error {
+	if err := checkContinuity(before, after); err != nil {
+		return err
+	}
+	if !bytes.Equal(r.PreviousFingerprint, before.Fingerprint()) {
+		return errors.New("receipt: previous fingerprint does not match")
+	}
+	if !bytes.Equal(r.Fingerprint, after.Fingerprint()) {
+		return errors.New("receipt: fingerprint does not match")
+	}
+	if r.PublicKey == nil || !r.PublicKey.Equal(after.PublicPoint()) {
+		return errors.New("receipt: public key does not match")
+	}
+	if !bytes.Equal(r.ChainKeyDigest, chainKeyDigest(after)) {
+		return errors.New("receipt: chain key does not match")
+	}
+	return nil
+}
+
+// checkContinuity returns an error if after is not a refresh of before.
+func checkContinuity(before, after *PublicConfig) error {
+	if before.Group.Name() != after.Group.Name() {
+		return fmt.Errorf("receipt: group changed from %s to %s", before.Group.Name(), after.Group.Name())
+	}
+	if before.Threshold != after.Threshold {
+		return fmt.Errorf("receipt: threshold changed from %d to %d", before.Threshold, after.Threshold)
+	}
+	if len(before.Public) != len(after.Public) {
+		return errors.New("receipt: parties changed")
+	}
+	for j := range before.Public {
+		if _, ok := after.Public[j]; !ok {
+			return fmt.Errorf("receipt: party %s missing after refresh", j)
+		}
+	}
+	if !before.PublicPoint().Equal(after.PublicPoint()) {
+		return errors.New("receipt: public key changed")
+	}
+	if !bytes.Equal(before.ChainKey, after.ChainKey) {
+		return errors.New("receipt: chain key changed")
+	}
+	if bytes.Equal(before.Fingerprint(), after.Fingerprint()) {
+		return errors.New("receipt: config was not refreshed")
+	}
+	return nil
+}
+
+func chainKeyDigest(c *PublicConfig) []byte {
+	return hash.New(&hash.BytesWithDomain{TheDomain: "Chain Key", Bytes: c.ChainKey}).Sum()[:32]
+}
+
+// EmptyRefreshReceipt creates an empty RefreshReceipt with a fixed group, ready for unmarshalling.
+func EmptyRefreshReceipt(group curve.Curve) *RefreshReceipt {
+	return &RefreshReceipt{PublicKey: group.NewPoint()}
+}
+
+type refreshReceiptMarshal struct {
+	PreviousFingerprint []byte
+	Fingerprint         []byte
+	PublicKey           cbor.RawMessage
+	ChainKeyDigest      []byte
+}
+
+func (r *RefreshReceipt) MarshalBinary() ([]byte, error) {
+	publicKey, err := cbor.Marshal(r.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return cbor.Marshal(&refreshReceiptMarshal{
+		PreviousFingerprint: r.PreviousFingerprint,
+		Fingerprint:         r.Fingerprint,
+		PublicKey:           publicKey,
+		ChainKeyDigest:      r.ChainKeyDigest,
+	})
+}
+
+func (r *RefreshReceipt) UnmarshalBinary(data []byte) error {
+	if r.PublicKey == nil {
+		return errors.New("receipt must be initialized using EmptyRefreshReceipt")
+	}
+	var rm refreshReceiptMarshal
+	if err := cbor.Unmarshal(data, &rm); err != nil {
+		return fmt.Errorf("receipt: %w", err)
+	}
+	if err := cbor.Unmarshal(rm.PublicKey, r.PublicKey); err != nil {
+		return fmt.Errorf("receipt: %w", err)
+	}
+	r.PreviousFingerprint = rm.PreviousFingerprint
+	r.Fingerprint = rm.Fingerprint
+	r.ChainKeyDigest = rm.ChainKeyDigest
+	return nil
+}
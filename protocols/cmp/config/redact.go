@@ -0,0 +1,70 @@
+package config
+
+import (
+	"encoding/hex"
+
+	"github.com/taurusgroup/multi-party-sig/internal/types"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// RedactedConfig is a copy of a Config which can safely be shared, for example in a bug report.
+// The secret shares are replaced by a short digest, so that dumps of different parties can still be compared,
+// and the Paillier primes are removed.
+type RedactedConfig struct {
+	Group     string       `json:"group"`
+	ID        party.ID     `json:"id"`
+	Threshold int          `json:"threshold"`
+	ECDSA     string       `json:"ecdsa"`
+	ElGamal   string       `json:"elgamal"`
+	RID       string       `json:"rid"`
+	ChainKey  string       `json:"chainKey"`
+	Epoch     uint64       `json:"epoch,omitempty"`
+	Dealt     bool         `json:"dealt,omitempty"`
+	Public    []publicJSON `json:"public"`
+}
+
+// Redact returns a copy of c without any secret, which can be marshalled with encoding/json.
+func (c *Config) Redact() *RedactedConfig {
+	rc := &RedactedConfig{
+		ID:        c.ID,
+		Threshold: c.Threshold,
+		ECDSA:     redactScalar(c.ECDSA),
+		ElGamal:   redactScalar(c.ElGamal),
+		RID:       hex.EncodeToString(c.RID),
+		ChainKey:  hex.EncodeToString(c.ChainKey),
+		Epoch:     c.Epoch,
+		Dealt:     c.Dealt,
+	}
+	if c.Group != nil {
+		rc.Group = c.Group.Name()
+	}
+	for _, id := range c.PartyIDs() {
+		p := c.Public[id]
+		pj := publicJSON{ID: id}
+		if p.ECDSA != nil {
+			pj.ECDSA = curve.ToHexCompressed(p.ECDSA)
+		}
+		if p.ElGamal != nil {
+			pj.ElGamal = curve.ToHexCompressed(p.ElGamal)
+		}
+		if p.Pedersen != nil {
+			pj.N = hex.EncodeToString(p.Pedersen.N().Bytes())
+			pj.S = hex.EncodeToString(p.Pedersen.S().Bytes())
+			pj.T = hex.EncodeToString(p.Pedersen.T().Bytes())
+		}
+		rc.Public = append(rc.Public, pj)
+	}
+	return rc
+}
+
+func redactScalar(s curve.Scalar) string {
+	if s == nil {
+		return ""
+	}
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return ""
+	}
+	return types.RedactedDigest(data)
+}
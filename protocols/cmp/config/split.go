@@ -0,0 +1,384 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cronokirby/saferith"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/taurusgroup/multi-party-sig/internal/types"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/polynomial"
+	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// LocalShare is a share of a party's secret material, held by one of that party's own devices.
+// It is created by Config.SplitLocal, and k of them can be combined with LocalReassemble
+// to recover the original Config.
+//
+// Splitting is purely local: the other parties of the consortium are not involved,
+// and the public data of the Config is unchanged.
+//
+// To unmarshal this struct, EmptyLocalShare should be called first with a specific group,
+// before using cbor.Unmarshal with that struct.
+type LocalShare struct {
+	// Config contains the public data of the original Config. Its secret fields are nil.
+	Config *Config
+	// Index is the index of this device, in 1, …, m.
+	Index int
+	// Threshold is the number k of shares required to reassemble the Config.
+	Threshold int
+	// ECDSA is this device's share of the party's ECDSA share xᵢ.
+	ECDSA curve.Scalar
+	// ElGamal is this device's share of the party's ElGamal secret yᵢ.
+	ElGamal curve.Scalar
+	// Paillier contains this device's shares of the Paillier primes p and q,
+	// encoded as chunks small enough to fit in a Scalar.
+	Paillier []curve.Scalar
+	// PrimeBytes is the length in bytes of each Paillier prime.
+	PrimeBytes int
+}
+
+// EmptyLocalShare creates an empty LocalShare with a fixed group, ready for unmarshalling.
+func EmptyLocalShare(group curve.Curve) *LocalShare {
+	return &LocalShare{Config: EmptyConfig(group)}
+}
+
+// SplitLocal splits the secret material of c into m shares, so that any k of them can be combined
+// with LocalReassemble to recover c. Each share contains the public data of c.
+//
+// This allows a single party to spread its key over several of its own devices,
+// without involving the other parties.
+func (c *Config) SplitLocal(m, k int) ([]*LocalShare, error) {
+	if k <= 0 || m < k {
+		return nil, fmt.Errorf("config: cannot split into %d shares with threshold %d", m, k)
+	}
+	if c.ECDSA == nil || c.ElGamal == nil || c.Paillier == nil {
+		return nil, errors.New("config: missing secret material")
+	}
+	if len(c.Shares) > 0 {
+		return nil, errors.New("config: cannot split a weighted config")
+	}
+	group := c.Group
+	chunkBytes := localChunkBytes(group)
+	primeBytes := (c.Paillier.P().AnnouncedLen() + 7) / 8
+	if q := (c.Paillier.Q().AnnouncedLen() + 7) / 8; q > primeBytes {
+		primeBytes = q
+	}
+	chunks := append(
+		localChunks(group, c.Paillier.P().FillBytes(make([]byte, primeBytes)), chunkBytes),
+		localChunks(group, c.Paillier.Q().FillBytes(make([]byte, primeBytes)), chunkBytes)...)
+
+	// degree k-1 so that any k shares reconstruct the constant
+	ecdsa := polynomial.NewPolynomial(group, k-1, c.ECDSA)
+	elgamal := polynomial.NewPolynomial(group, k-1, c.ElGamal)
+	paillierPolys := make([]*polynomial.Polynomial, len(chunks))
+	for i, chunk := range chunks {
+		paillierPolys[i] = polynomial.NewPolynomial(group, k-1, chunk)
+	}
+
+	public := c.publicOnly()
+	shares := make([]*LocalShare, m)
+	for i := range shares {
+		x := localIndex(group, i+1)
+		paillierShares := make([]curve.Scalar, len(paillierPolys))
+		for j, p := range paillierPolys {
+			paillierShares[j] = p.Evaluate(x)
+		}
+		shares[i] = &LocalShare{
+			Config:     public,
+			Index:      i + 1,
+			Threshold:  k,
+			ECDSA:      ecdsa.Evaluate(x),
+			ElGamal:    elgamal.Evaluate(x),
+			Paillier:   paillierShares,
+			PrimeBytes: primeBytes,
+		}
+	}
+	return shares, nil
+}
+
+// LocalReassemble recombines shares created by Config.SplitLocal into the original Config.
+// At least Threshold distinct shares of the same split must be given.
+// The recovered secrets are checked against the public data before being returned.
+func LocalReassemble(shares ...*LocalShare) (*Config, error) {
+	if len(shares) == 0 || shares[0] == nil || shares[0].Config == nil {
+		return nil, errors.New("config: no shares to reassemble")
+	}
+	first := shares[0]
+	public := first.Config
+	group := public.Group
+	if len(shares) < first.Threshold {
+		return nil, fmt.Errorf("config: %d shares is not enough for threshold %d", len(shares), first.Threshold)
+	}
+
+	indices := make([]int, 0, len(shares))
+	seen := make(map[int]bool, len(shares))
+	for _, s := range shares {
+		if s == nil || s.Config == nil {
+			return nil, errors.New("config: nil share")
+		}
+		if s.Threshold != first.Threshold || s.PrimeBytes != first.PrimeBytes || len(s.Paillier) != len(first.Paillier) {
+			return nil, fmt.Errorf("config: share %d does not belong to the same split", s.Index)
+		}
+		if err := public.Compatible(s.Config); err != nil {
+			return nil, fmt.Errorf("config: share %d: %w", s.Index, err)
+		}
+		if s.Config.Epoch != public.Epoch {
+			return nil, fmt.Errorf("config: share %d: epoch mismatch: %d != %d", s.Index, s.Config.Epoch, public.Epoch)
+		}
+		if s.Index <= 0 || seen[s.Index] {
+			return nil, fmt.Errorf("config: share index %d is invalid or duplicated", s.Index)
+		}
+		seen[s.Index] = true
+		indices = append(indices, s.Index)
+	}
+
+	lagrange := localLagrange(group, indices)
+	combine := func(get func(*LocalShare) curve.Scalar) curve.Scalar {
+		sum := group.NewScalar()
+		for i, s := range shares {
+			sum.Add(group.NewScalar().Set(lagrange[i]).Mul(get(s)))
+		}
+		return sum
+	}
+
+	ecdsa := combine(func(s *LocalShare) curve.Scalar { return s.ECDSA })
+	elgamal := combine(func(s *LocalShare) curve.Scalar { return s.ElGamal })
+	self, ok := public.Public[public.ID]
+	if !ok {
+		return nil, errors.New("config: no public data for this party")
+	}
+	if !ecdsa.ActOnBase().Equal(self.ECDSA) {
+		return nil, errors.New("config: reassembled ECDSA share does not match public data")
+	}
+	if !elgamal.ActOnBase().Equal(self.ElGamal) {
+		return nil, errors.New("config: reassembled ElGamal secret does not match public data")
+	}
+
+	chunkBytes := localChunkBytes(group)
+	primeChunks := len(first.Paillier) / 2
+	encoded := make([]byte, 0, len(first.Paillier)*chunkBytes)
+	for j := range first.Paillier {
+		chunk := combine(func(s *LocalShare) curve.Scalar { return s.Paillier[j] })
+		encoded = append(encoded, localChunkBytesOf(chunk, chunkBytes)...)
+	}
+	P := new(saferith.Nat).SetBytes(encoded[:primeChunks*chunkBytes])
+	Q := new(saferith.Nat).SetBytes(encoded[primeChunks*chunkBytes:])
+	if err := paillier.ValidatePrime(P); err != nil {
+		return nil, fmt.Errorf("config: reassembled prime P: %w", err)
+	}
+	if err := paillier.ValidatePrime(Q); err != nil {
+		return nil, fmt.Errorf("config: reassembled prime Q: %w", err)
+	}
+	paillierSecret := paillier.NewSecretKeyFromPrimes(P, Q)
+	if !paillierSecret.PublicKey.Equal(self.Paillier) {
+		return nil, errors.New("config: reassembled Paillier key does not match public data")
+	}
+
+	return &Config{
+		Group:     group,
+		ID:        public.ID,
+		Threshold: public.Threshold,
+		ECDSA:     ecdsa,
+		ElGamal:   elgamal,
+		Paillier:  paillierSecret,
+		RID:       public.RID.Copy(),
+		ChainKey:  public.ChainKey.Copy(),
+		Epoch:     public.Epoch,
+		Dealt:     public.Dealt,
+		Public:    public.Public,
+		// the ceremony log is part of the hash of the config
+		CeremonyLog: public.CeremonyLog,
+		Pending:     public.Pending,
+	}, nil
+}
+
+// publicOnly returns a copy of c with all secret fields set to nil.
+func (c *Config) publicOnly() *Config {
+	public := make(map[party.ID]*Public, len(c.Public))
+	for j, p := range c.Public {
+		public[j] = p
+	}
+	return &Config{
+		Group:       c.Group,
+		ID:          c.ID,
+		Threshold:   c.Threshold,
+		RID:         c.RID.Copy(),
+		ChainKey:    c.ChainKey.Copy(),
+		Epoch:       c.Epoch,
+		Dealt:       c.Dealt,
+		Public:      public,
+		CeremonyLog: c.CeremonyLog,
+		Pending:     c.Pending,
+	}
+}
+
+// localChunkBytes returns the number of bytes which always fit in a Scalar of the group.
+func localChunkBytes(group curve.Curve) int {
+	return (group.ScalarBits() - 1) / 8
+}
+
+// localChunks splits data into Scalars of chunkBytes bytes each, padding the first chunk.
+func localChunks(group curve.Curve, data []byte, chunkBytes int) []curve.Scalar {
+	padded := make([]byte, ((len(data)+chunkBytes-1)/chunkBytes)*chunkBytes)
+	copy(padded[len(padded)-len(data):], data)
+	chunks := make([]curve.Scalar, 0, len(padded)/chunkBytes)
+	for i := 0; i < len(padded); i += chunkBytes {
+		chunks = append(chunks, group.NewScalar().SetNat(new(saferith.Nat).SetBytes(padded[i:i+chunkBytes])))
+	}
+	return chunks
+}
+
+// localChunkBytesOf is the inverse of localChunks for a single chunk.
+func localChunkBytesOf(s curve.Scalar, chunkBytes int) []byte {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	// a scalar reconstructed from a valid split always fits in chunkBytes
+	return new(saferith.Nat).SetBytes(data).FillBytes(make([]byte, chunkBytes))
+}
+
+// localIndex returns the evaluation point of the device with the given index.
+func localIndex(group curve.Curve, index int) curve.Scalar {
+	return group.NewScalar().SetNat(new(saferith.Nat).SetUint64(uint64(index)))
+}
+
+// localLagrange returns the Lagrange coefficients at 0 for the given device indices.
+func localLagrange(group curve.Curve, indices []int) []curve.Scalar {
+	xs := make([]curve.Scalar, len(indices))
+	for i, index := range indices {
+		xs[i] = localIndex(group, index)
+	}
+	coefficients := make([]curve.Scalar, len(indices))
+	for i := range xs {
+		num, denom := group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1)), group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1))
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			// lᵢ = ∏ⱼ xⱼ/(xⱼ - xᵢ)
+			num.Mul(xs[j])
+			denom.Mul(group.NewScalar().Set(xs[j]).Sub(xs[i]))
+		}
+		coefficients[i] = num.Mul(denom.Invert())
+	}
+	return coefficients
+}
+
+type localShareMarshal struct {
+	ID            party.ID
+	Threshold     int
+	RID, ChainKey types.RID
+	Public        []cbor.RawMessage
+	Index         int
+	K             int
+	ECDSA         curve.Scalar
+	ElGamal       curve.Scalar
+	Paillier      [][]byte
+	PrimeBytes    int
+	Epoch         uint64        `cbor:",omitempty"`
+	Dealt         bool          `cbor:",omitempty"`
+	CeremonyLog   []RoundDigest `cbor:",omitempty"`
+	Pending       []party.ID    `cbor:",omitempty"`
+}
+
+func (s *LocalShare) MarshalBinary() ([]byte, error) {
+	ps, err := marshalPublic(s.Config.Public)
+	if err != nil {
+		return nil, err
+	}
+	paillierShares := make([][]byte, len(s.Paillier))
+	for i, share := range s.Paillier {
+		if paillierShares[i], err = share.MarshalBinary(); err != nil {
+			return nil, err
+		}
+	}
+	return cbor.Marshal(&localShareMarshal{
+		ID:          s.Config.ID,
+		Threshold:   s.Config.Threshold,
+		RID:         s.Config.RID,
+		ChainKey:    s.Config.ChainKey,
+		Public:      ps,
+		Index:       s.Index,
+		K:           s.Threshold,
+		ECDSA:       s.ECDSA,
+		ElGamal:     s.ElGamal,
+		Paillier:    paillierShares,
+		PrimeBytes:  s.PrimeBytes,
+		Epoch:       s.Config.Epoch,
+		Dealt:       s.Config.Dealt,
+		CeremonyLog: s.Config.CeremonyLog,
+		Pending:     s.Config.Pending,
+	})
+}
+
+func (s *LocalShare) UnmarshalBinary(data []byte) error {
+	if s.Config == nil || s.Config.Group == nil {
+		return errors.New("local share must be initialized using EmptyLocalShare")
+	}
+	group := s.Config.Group
+	sm := &localShareMarshal{
+		ECDSA:   group.NewScalar(),
+		ElGamal: group.NewScalar(),
+	}
+	if err := cbor.Unmarshal(data, sm); err != nil {
+		return fmt.Errorf("local share: %w", err)
+	}
+	if sm.Index <= 0 || sm.K <= 0 {
+		return errors.New("local share: invalid index or threshold")
+	}
+
+	ps := make(map[party.ID]*Public, len(sm.Public))
+	for _, pm := range sm.Public {
+		id, public, err := unmarshalPublic(group, pm)
+		if err != nil {
+			return err
+		}
+		if _, ok := ps[id]; ok {
+			return fmt.Errorf("local share: party %s: duplicate entry", id)
+		}
+		ps[id] = public
+	}
+	if !ValidThreshold(sm.Threshold, totalWeight(ps)) {
+		return fmt.Errorf("local share: threshold %d is invalid", sm.Threshold)
+	}
+	if _, ok := ps[sm.ID]; !ok {
+		return errors.New("local share: no public data for this party")
+	}
+	if err := validatePending(ps, sm.Pending); err != nil {
+		return fmt.Errorf("local share: %w", err)
+	}
+
+	paillierShares := make([]curve.Scalar, len(sm.Paillier))
+	for i, b := range sm.Paillier {
+		paillierShares[i] = group.NewScalar()
+		if err := paillierShares[i].UnmarshalBinary(b); err != nil {
+			return fmt.Errorf("local share: %w", err)
+		}
+	}
+
+	*s = LocalShare{
+		Config: &Config{
+			Group:       group,
+			ID:          sm.ID,
+			Threshold:   sm.Threshold,
+			RID:         sm.RID,
+			ChainKey:    sm.ChainKey,
+			Epoch:       sm.Epoch,
+			Dealt:       sm.Dealt,
+			CeremonyLog: sm.CeremonyLog,
+			Public:      ps,
+			Pending:     sm.Pending,
+		},
+		Index:      sm.Index,
+		Threshold:  sm.K,
+		ECDSA:      sm.ECDSA,
+		ElGamal:    sm.ElGamal,
+		Paillier:   paillierShares,
+		PrimeBytes: sm.PrimeBytes,
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"crypto/subtle"
+	"errors"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+)
+
+// RefreshProtocolID is the protocol ID of a refresh of a config, whose SSID is returned by SSIDHash.
+const RefreshProtocolID = "cmp/refresh-threshold"
+
+// SSIDHash returns the SSID of a refresh of c started with sessionID, which may be nil, exactly as computed
+// by each party when the session is created.
+//
+// It binds the group, the threshold, the party IDs, the RID, the epoch and the public data of all parties,
+// so that parties can exchange and compare it before running any protocol, and detect that their configs diverge
+// before any message is sent.
+func (c *Config) SSIDHash(sessionID []byte) ([]byte, error) {
+	if c == nil || c.Group == nil {
+		return nil, errors.New("config: config is empty")
+	}
+	helper, err := round.NewSession(round.Info{
+		ProtocolID: RefreshProtocolID,
+		// the number of rounds is not part of the SSID
+		FinalRoundNumber: 1,
+		SelfID:           c.ID,
+		PartyIDs:         c.PartyIDs(),
+		Threshold:        c.Threshold,
+		Group:            c.Group,
+		Epoch:            c.Epoch,
+	}, sessionID, nil, c)
+	if err != nil {
+		return nil, err
+	}
+	return helper.SSID(), nil
+}
+
+// VerifySSIDHash returns an error if expected, the SSIDHash of another party for sessionID, differs from the SSIDHash of c.
+func (c *Config) VerifySSIDHash(sessionID, expected []byte) error {
+	ssid, err := c.SSIDHash(sessionID)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(ssid, expected) != 1 {
+		return errors.New("config: SSID mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,382 @@
+package config
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// ErrNotFound is returned by a KV and a Store when no value is stored under a key.
+var ErrNotFound = errors.New("config: not found")
+
+// KV is the storage backing a Store, such as a directory or a key-value database.
+// Keys are paths of segments separated by "/", which never contain "." or ".." segments.
+type KV interface {
+	// Get returns the value stored under key, or ErrNotFound.
+	Get(key string) ([]byte, error)
+	// Put stores value under key. Readers must observe either the previous value or value, never a partial write.
+	Put(key string, value []byte) error
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(key string) error
+	// List returns all keys starting with prefix, in any order.
+	List(prefix string) ([]string, error)
+}
+
+// StoreEntry describes a config saved in a Store.
+type StoreEntry struct {
+	// KeyFingerprint identifies the key of the config, see Config.KeyFingerprint.
+	KeyFingerprint string
+	ID             party.ID
+	// Version is 1 for the first config saved for this key and party, and increases with every refresh.
+	Version uint64
+}
+
+// Store saves configs under the deterministic layout
+//
+//	<key fingerprint>/<party ID>/<version>.json
+//
+// where the version is zero-padded to 8 digits, and the file is the FormatJSON encoding of the config,
+// so that all tools share the same layout. Saving a refreshed config adds a version, and only the given
+// number of previous versions are kept.
+type Store struct {
+	kv     KV
+	retain int
+	mtx    sync.Mutex
+}
+
+// NewStore returns a Store backed by kv, which keeps retain previous versions of each config besides the latest.
+func NewStore(kv KV, retain int) *Store {
+	if retain < 0 {
+		retain = 0
+	}
+	return &Store{kv: kv, retain: retain}
+}
+
+// KeyFingerprint returns a hex identifier of the public key of c, which is the same for all parties
+// and, unlike Fingerprint, does not change when the config is refreshed.
+func (c *Config) KeyFingerprint() string {
+	data, _ := c.PublicPoint().MarshalBinary()
+	h := hash.New(
+		&hash.BytesWithDomain{TheDomain: "Group Name", Bytes: []byte(c.Group.Name())},
+		&hash.BytesWithDomain{TheDomain: "Public Key", Bytes: data},
+	)
+	return hex.EncodeToString(h.Sum()[:16])
+}
+
+// Save validates c and stores it as the latest version for its key and party, and returns its version.
+// If c is identical to the latest version, nothing is written. Versions older than the retained ones are deleted.
+func (s *Store) Save(c *Config) (uint64, error) {
+	if err := c.Validate(); err != nil {
+		return 0, err
+	}
+	key := c.KeyFingerprint()
+	dir, err := storeDir(key, c.ID)
+	if err != nil {
+		return 0, err
+	}
+	data, err := c.MarshalJSONFormat()
+	if err != nil {
+		return 0, err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	versions, err := s.versions(dir)
+	if err != nil {
+		return 0, err
+	}
+	version := uint64(1)
+	if len(versions) > 0 {
+		latest := versions[len(versions)-1]
+		previous, err := s.kv.Get(storeFile(dir, latest))
+		if err != nil {
+			return 0, err
+		}
+		if bytes.Equal(previous, data) {
+			return latest, nil
+		}
+		version = latest + 1
+	}
+	if err = s.kv.Put(storeFile(dir, version), data); err != nil {
+		return 0, err
+	}
+	versions = append(versions, version)
+	// a failure to delete only leaves additional versions, which are deleted by the next Save
+	for len(versions) > s.retain+1 {
+		if err = s.kv.Delete(storeFile(dir, versions[0])); err != nil {
+			return version, err
+		}
+		versions = versions[1:]
+	}
+	return version, nil
+}
+
+// Load returns the given version of the config of party id for the key with the given fingerprint.
+func (s *Store) Load(keyFingerprint string, id party.ID, version uint64) (*Config, error) {
+	dir, err := storeDir(keyFingerprint, id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.kv.Get(storeFile(dir, version))
+	if err != nil {
+		return nil, err
+	}
+	c := EmptyConfig(nil)
+	if err = c.UnmarshalJSONFormat(data); err != nil {
+		return nil, err
+	}
+	if c.ID != id || c.KeyFingerprint() != keyFingerprint {
+		return nil, fmt.Errorf("config: %s/%s/%d contains the config of another party or key", keyFingerprint, id, version)
+	}
+	return c, nil
+}
+
+// Latest returns the latest config of party id for the key with the given fingerprint, and its version.
+func (s *Store) Latest(keyFingerprint string, id party.ID) (*Config, uint64, error) {
+	dir, err := storeDir(keyFingerprint, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	s.mtx.Lock()
+	versions, err := s.versions(dir)
+	s.mtx.Unlock()
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(versions) == 0 {
+		return nil, 0, ErrNotFound
+	}
+	latest := versions[len(versions)-1]
+	c, err := s.Load(keyFingerprint, id, latest)
+	return c, latest, err
+}
+
+// List returns all stored configs, sorted by key fingerprint, party ID and version.
+// Files which do not follow the layout of the store are ignored.
+func (s *Store) List() ([]StoreEntry, error) {
+	keys, err := s.kv.List("")
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]StoreEntry, 0, len(keys))
+	for _, key := range keys {
+		e, ok := parseStoreKey(key)
+		if ok {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.KeyFingerprint != b.KeyFingerprint {
+			return a.KeyFingerprint < b.KeyFingerprint
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.Version < b.Version
+	})
+	return entries, nil
+}
+
+// versions returns the sorted versions stored in dir.
+func (s *Store) versions(dir string) ([]uint64, error) {
+	keys, err := s.kv.List(dir + "/")
+	if err != nil {
+		return nil, err
+	}
+	var versions []uint64
+	for _, key := range keys {
+		if e, ok := parseStoreKey(key); ok {
+			versions = append(versions, e.Version)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions, nil
+}
+
+// storeDir returns the directory of the configs of party id for the given key.
+// Party IDs are escaped, so that any ID maps to a single path segment.
+func storeDir(keyFingerprint string, id party.ID) (string, error) {
+	if _, err := hex.DecodeString(keyFingerprint); err != nil || keyFingerprint == "" {
+		return "", fmt.Errorf("config: invalid key fingerprint %q", keyFingerprint)
+	}
+	segment := url.PathEscape(string(id))
+	if segment == "" || segment == "." || segment == ".." {
+		return "", fmt.Errorf("config: party ID %q cannot be stored", id)
+	}
+	return keyFingerprint + "/" + segment, nil
+}
+
+func storeFile(dir string, version uint64) string {
+	return fmt.Sprintf("%s/%08d.json", dir, version)
+}
+
+// parseStoreKey parses a key created by storeFile.
+func parseStoreKey(key string) (StoreEntry, bool) {
+	parts := strings.Split(key, "/")
+	if len(parts) != 3 || !strings.HasSuffix(parts[2], ".json") {
+		return StoreEntry{}, false
+	}
+	id, err := url.PathUnescape(parts[1])
+	if err != nil {
+		return StoreEntry{}, false
+	}
+	version, err := strconv.ParseUint(strings.TrimSuffix(parts[2], ".json"), 10, 64)
+	if err != nil || version == 0 {
+		return StoreEntry{}, false
+	}
+	if dir, err := storeDir(parts[0], party.ID(id)); err != nil || storeFile(dir, version) != key {
+		return StoreEntry{}, false
+	}
+	return StoreEntry{KeyFingerprint: parts[0], ID: party.ID(id), Version: version}, true
+}
+
+// MemoryKV is a KV which keeps all values in memory.
+type MemoryKV struct {
+	values map[string][]byte
+	mtx    sync.Mutex
+}
+
+// NewMemoryKV returns an empty MemoryKV.
+func NewMemoryKV() *MemoryKV {
+	return &MemoryKV{values: make(map[string][]byte)}
+}
+
+// Get implements KV.
+func (kv *MemoryKV) Get(key string) ([]byte, error) {
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+	value, ok := kv.values[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return bytes.Clone(value), nil
+}
+
+// Put implements KV.
+func (kv *MemoryKV) Put(key string, value []byte) error {
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+	kv.values[key] = bytes.Clone(value)
+	return nil
+}
+
+// Delete implements KV.
+func (kv *MemoryKV) Delete(key string) error {
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+	delete(kv.values, key)
+	return nil
+}
+
+// List implements KV.
+func (kv *MemoryKV) List(prefix string) ([]string, error) {
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+	var keys []string
+	for key := range kv.values {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// DirKV is a KV which stores each value in a file under a directory.
+// Values are written to a temporary file which is then renamed, so that they are replaced atomically.
+type DirKV struct {
+	dir string
+}
+
+// NewDirKV returns a DirKV storing files under dir, which is created if necessary.
+func NewDirKV(dir string) (*DirKV, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	return &DirKV{dir: dir}, nil
+}
+
+func (kv *DirKV) path(key string) string {
+	return filepath.Join(kv.dir, filepath.FromSlash(key))
+}
+
+// Get implements KV.
+func (kv *DirKV) Get(key string) ([]byte, error) {
+	data, err := os.ReadFile(kv.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	return data, nil
+}
+
+// Put implements KV.
+func (kv *DirKV) Put(key string, value []byte) error {
+	path := kv.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if _, err = f.Write(value); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("config: %w", err)
+	}
+	return nil
+}
+
+// Delete implements KV.
+func (kv *DirKV) Delete(key string) error {
+	if err := os.Remove(kv.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("config: %w", err)
+	}
+	return nil
+}
+
+// List implements KV. Temporary files left by an interrupted Put are not listed.
+func (kv *DirKV) List(prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(kv.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || strings.HasSuffix(path, ".tmp") {
+			return err
+		}
+		rel, err := filepath.Rel(kv.dir, path)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/polynomial"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// Weights returns the number of shares held by each party with more than one share,
+// or nil if every party holds a single share.
+func (c *PublicConfig) Weights() party.Weights {
+	return weights(c.Public)
+}
+
+// Weights is the same as PublicConfig.Weights.
+func (c *Config) Weights() party.Weights {
+	return weights(c.Public)
+}
+
+func weights(public map[party.ID]*Public) party.Weights {
+	var w party.Weights
+	for j, p := range public {
+		if p == nil || len(p.Shares) == 0 {
+			continue
+		}
+		if w == nil {
+			w = make(party.Weights)
+		}
+		w[j] = 1 + len(p.Shares)
+	}
+	return w
+}
+
+// AdditivePublicShares returns the public share of every signer j, scaled so that they add up to the public key:
+// Σₖ λₖ•Xₖ, for all evaluation points k of j, where λₖ is the Lagrange coefficient of k over the evaluation points
+// of all signers. In an unweighted config, this is λⱼ•Xⱼ.
+func (c *PublicConfig) AdditivePublicShares(signers []party.ID) map[party.ID]curve.Point {
+	w := c.Weights()
+	lagrange := polynomial.Lagrange(c.Group, w.EvaluationPoints(signers))
+	shares := make(map[party.ID]curve.Point, len(signers))
+	for _, j := range signers {
+		public := c.Public[j]
+		ids := j.WeightedIDs(w.Of(j))
+		share := lagrange[j].Act(public.ECDSA)
+		for k, point := range public.Shares {
+			share = share.Add(lagrange[ids[k+1]].Act(point))
+		}
+		shares[j] = share
+	}
+	return shares
+}
+
+// AdditiveShare returns the share of this party scaled so that the shares of all signers add up to the secret key,
+// which matches its public share in PublicConfig.AdditivePublicShares.
+func (c *Config) AdditiveShare(signers []party.ID) curve.Scalar {
+	w := c.Weights()
+	lagrange := polynomial.Lagrange(c.Group, w.EvaluationPoints(signers))
+	ids := c.ID.WeightedIDs(w.Of(c.ID))
+	share := c.Group.NewScalar().Set(lagrange[c.ID]).Mul(c.ECDSA)
+	for k, s := range c.Shares {
+		share.Add(c.Group.NewScalar().Set(lagrange[ids[k+1]]).Mul(s))
+	}
+	return share
+}
+
+// validateWeights checks that the additional shares of c match its public data,
+// and that all evaluation points are valid.
+func (c *Config) validateWeights() error {
+	self := c.Public[c.ID]
+	if len(c.Shares) != len(self.Shares) {
+		return fmt.Errorf("config: %d additional shares, but public data has %d", len(c.Shares), len(self.Shares))
+	}
+	for k, share := range c.Shares {
+		if share == nil || !share.ActOnBase().Equal(self.Shares[k]) {
+			return errors.New("config: additional ECDSA share does not match public data")
+		}
+	}
+	return validateEvaluationPoints(c.Group, c.Public)
+}
+
+// validateEvaluationPoints checks that the evaluation points of all parties, including the virtual IDs
+// of weighted parties, are distinct and non-zero scalars.
+func validateEvaluationPoints(group curve.Curve, public map[party.ID]*Public) error {
+	w := weights(public)
+	if w == nil {
+		return nil
+	}
+	ids := make([]party.ID, 0, len(public))
+	for j := range public {
+		ids = append(ids, j)
+	}
+	if err := w.EvaluationPoints(ids).ValidateScalars(group); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	return nil
+}
+
+// totalWeight returns the total weight of all parties, which is their number for unweighted configs.
+func totalWeight(public map[party.ID]*Public) int {
+	total := 0
+	for _, p := range public {
+		total++
+		if p != nil {
+			total += len(p.Shares)
+		}
+	}
+	return total
+}
+
+// addToShares returns the given points with p added to each of them.
+func addToShares(shares []curve.Point, p curve.Point) []curve.Point {
+	if shares == nil {
+		return nil
+	}
+	out := make([]curve.Point, 0, len(shares))
+	for _, share := range shares {
+		out = append(out, share.Add(p))
+	}
+	return out
+}
+
+func equalShares(a, b []curve.Point) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if !a[k].Equal(b[k]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,135 @@
+package cmp
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/sign"
+)
+
+// EmergencySignProtocolID is the protocol ID of emergency signing sessions, as found in protocol.ResultMetadata,
+// so that audits can distinguish emergency signatures from routine ones.
+const EmergencySignProtocolID = sign.ProtocolEmergencySignID
+
+// EmergencySign is the same as SignWithPolicy, but runs the emergency variant of the protocol,
+// which binds failedAttempts to the session, and whose protocol ID is EmergencySignProtocolID.
+// It is meant to be started by an Escalation once the routine signers have failed too many times.
+// If policy is nil, request is ignored.
+// Returns *ecdsa.Signature if successful.
+func EmergencySign(config *Config, signers []party.ID, messageHash []byte, failedAttempts int, policy *Policy, request *PolicyRequest, confirm SigningConfirmer, pl *pool.Pool) protocol.StartFunc {
+	return sign.StartEmergencySign(config, signers, messageHash, failedAttempts, policy, request, confirm, pl)
+}
+
+// EscalationPolicy configures break-glass signing: once the routine signers have failed to sign a message
+// MaxFailures times, a larger emergency quorum, for example including parties holding their share in cold storage,
+// signs it with the same Config.
+type EscalationPolicy struct {
+	// Signers are the parties of routine signing sessions.
+	Signers []party.ID
+	// Emergency are the parties of emergency signing sessions. They must include all Signers,
+	// and have a larger total weight, see Config.Weights.
+	Emergency []party.ID
+	// MaxFailures is the number of failed routine sessions after which signing escalates to Emergency.
+	MaxFailures int
+	// SigningPolicy is optional, and is evaluated for routine and emergency sessions alike, see SignWithPolicy.
+	SigningPolicy *Policy
+}
+
+// Validate checks that both sets of signers can sign with c, that Emergency extends Signers,
+// and that MaxFailures is positive.
+func (p EscalationPolicy) Validate(c *Config) error {
+	if c == nil {
+		return errors.New("escalation: config is nil")
+	}
+	if p.MaxFailures <= 0 {
+		return errors.New("escalation: MaxFailures must be positive")
+	}
+	signers, emergency := party.NewIDSlice(p.Signers), party.NewIDSlice(p.Emergency)
+	for _, signers := range []party.IDSlice{signers, emergency} {
+		if !signers.Valid() {
+			return errors.New("escalation: signers contains duplicates")
+		}
+		if weight := c.Weights().Total(signers); !config.ValidThreshold(c.Threshold, weight) {
+			return fmt.Errorf("escalation: %d signers with total weight %d is not enough for threshold %d", len(signers), weight, c.Threshold)
+		}
+		for _, id := range signers {
+			if _, ok := c.Public[id]; !ok {
+				return fmt.Errorf("escalation: signer %s is not a party of this config", id)
+			}
+		}
+	}
+	for _, id := range signers {
+		if !emergency.Contains(id) {
+			return fmt.Errorf("escalation: emergency quorum must include the routine signer %s", id)
+		}
+	}
+	if c.Weights().Total(emergency) <= c.Weights().Total(signers) {
+		return errors.New("escalation: emergency quorum must have a larger total weight than the routine signers")
+	}
+	return nil
+}
+
+// Escalation tracks the failed attempts to sign a single message, and starts the signing session of the next attempt
+// with the routine or the emergency signers, following an EscalationPolicy.
+//
+// All parties must record the same failures, since the number of failed attempts is bound to emergency sessions,
+// which otherwise fail. It is safe for concurrent use.
+type Escalation struct {
+	config   *Config
+	policy   EscalationPolicy
+	mtx      sync.Mutex
+	failures int
+}
+
+// NewEscalation returns an Escalation for config, with no failed attempt.
+func NewEscalation(config *Config, policy EscalationPolicy) (*Escalation, error) {
+	if err := policy.Validate(config); err != nil {
+		return nil, err
+	}
+	return &Escalation{config: config, policy: policy}, nil
+}
+
+// RecordFailure records that an attempt failed, and returns true if the next attempt is an emergency signing session.
+func (e *Escalation) RecordFailure() bool {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	e.failures++
+	return e.failures >= e.policy.MaxFailures
+}
+
+// Failures returns the number of failed attempts.
+func (e *Escalation) Failures() int {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	return e.failures
+}
+
+// Escalated returns true if the next attempt is an emergency signing session.
+func (e *Escalation) Escalated() bool {
+	return e.Failures() >= e.policy.MaxFailures
+}
+
+// Signers returns the signers of the next attempt.
+func (e *Escalation) Signers() party.IDSlice {
+	if e.Escalated() {
+		return party.NewIDSlice(e.policy.Emergency)
+	}
+	return party.NewIDSlice(e.policy.Signers)
+}
+
+// Sign returns the StartFunc of the next attempt to sign messageHash, which is a routine signing session with
+// SignWithPolicy, or an emergency one with EmergencySign once MaxFailures attempts have failed.
+// Both evaluate the SigningPolicy of the EscalationPolicy against request, which is ignored if it is nil.
+// The config must belong to one of the signers returned by Signers.
+func (e *Escalation) Sign(messageHash []byte, request *PolicyRequest, confirm SigningConfirmer, pl *pool.Pool) protocol.StartFunc {
+	failures := e.Failures()
+	if failures >= e.policy.MaxFailures {
+		return EmergencySign(e.config, e.policy.Emergency, messageHash, failures, e.policy.SigningPolicy, request, confirm, pl)
+	}
+	return SignWithPolicy(e.config, e.policy.Signers, messageHash, e.policy.SigningPolicy, request, confirm, pl)
+}
@@ -0,0 +1,110 @@
+package cmp
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/sign"
+)
+
+func TestEscalation(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, partyIDs := test.GenerateConfig(curve.Secp256k1{}, 4, 1, rand.Reader, pl)
+	c := configs[partyIDs[0]]
+	policy := EscalationPolicy{
+		Signers:     partyIDs[:2],
+		Emergency:   partyIDs[:3],
+		MaxFailures: 2,
+	}
+
+	invalid := policy
+	invalid.Emergency = partyIDs[2:]
+	assert.Error(t, invalid.Validate(c), "emergency quorum must be larger")
+	invalid.Emergency = partyIDs[1:]
+	assert.Error(t, invalid.Validate(c), "emergency quorum must include the routine signers")
+	invalid.Emergency = partyIDs[:2]
+	assert.Error(t, invalid.Validate(c), "emergency quorum must be larger")
+	invalid = policy
+	invalid.MaxFailures = 0
+	assert.Error(t, invalid.Validate(c))
+	invalid = policy
+	invalid.Signers = partyIDs[:1]
+	assert.Error(t, invalid.Validate(c), "routine signers must satisfy the threshold")
+
+	e, err := NewEscalation(c, policy)
+	require.NoError(t, err)
+	messageHash := ecdsa.ProfileEthereum.HashMessage([]byte("hello"))
+	r, err := e.Sign(messageHash, nil, nil, pl)(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "cmp/sign", r.ProtocolID())
+	assert.Equal(t, partyIDs[:2], e.Signers())
+
+	assert.False(t, e.RecordFailure())
+	assert.True(t, e.RecordFailure())
+	assert.True(t, e.Escalated())
+	assert.Equal(t, partyIDs[:3], e.Signers())
+	r, err = e.Sign(messageHash, nil, nil, pl)(nil)
+	require.NoError(t, err)
+	assert.Equal(t, EmergencySignProtocolID, r.ProtocolID())
+	assert.Equal(t, partyIDs[:3], r.PartyIDs())
+}
+
+func TestEscalationPolicy(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, partyIDs := test.GenerateConfig(curve.Secp256k1{}, 4, 1, rand.Reader, pl)
+	c := configs[partyIDs[0]]
+	e, err := NewEscalation(c, EscalationPolicy{
+		Signers:       partyIDs[:2],
+		Emergency:     partyIDs[:3],
+		MaxFailures:   1,
+		SigningPolicy: &Policy{MaxAmount: 10},
+	})
+	require.NoError(t, err)
+	messageHash := ecdsa.ProfileEthereum.HashMessage([]byte("hello"))
+
+	// escalating must not bypass the signing policy
+	for _, escalated := range []bool{false, true} {
+		if escalated {
+			e.RecordFailure()
+		}
+		require.Equal(t, escalated, e.Escalated())
+		r, err := e.Sign(messageHash, &PolicyRequest{Amount: 11}, nil, pl)(nil)
+		require.NoError(t, err)
+		next, err := r.Finalize(make(chan *round.Message, 2*len(e.Signers())))
+		require.NoError(t, err)
+		require.IsType(t, &round.Abort{}, next)
+		assert.ErrorIs(t, next.(*round.Abort).Err, sign.ErrSigningRefused)
+
+		_, err = e.Sign(messageHash, nil, nil, pl)(nil)
+		assert.Error(t, err, "a policy requires a request")
+	}
+}
+
+func TestEscalationWeighted(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	ids := test.PartyIDs(3)
+	weights := party.Weights{ids[0]: 2}
+	configs, partyIDs := test.GenerateWeightedConfig(curve.Secp256k1{}, 3, 2, weights, rand.Reader, pl)
+	c := configs[partyIDs[0]]
+
+	// 2 signers with a total weight of 3 satisfy the threshold
+	policy := EscalationPolicy{
+		Signers:     partyIDs[:2],
+		Emergency:   partyIDs,
+		MaxFailures: 1,
+	}
+	assert.NoError(t, policy.Validate(c))
+	policy.Signers = partyIDs[1:]
+	assert.Error(t, policy.Validate(c), "routine signers must satisfy the threshold")
+}
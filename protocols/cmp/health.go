@@ -0,0 +1,145 @@
+package cmp
+
+import (
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/presign"
+)
+
+// healthDateLayout is the granularity of health checks: one canary message per key and per day.
+const healthDateLayout = "2006-01-02"
+
+// KeyFingerprint returns a short identifier of the shared public key of config, as a hex string.
+func KeyFingerprint(config *Config) string {
+	data, err := config.PublicPoint().MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(hash.New(&hash.BytesWithDomain{
+		TheDomain: "Key Fingerprint",
+		Bytes:     data,
+	}).Sum()[:16])
+}
+
+// CanaryMessage returns the 32 byte message signed during the health check of config on the given date.
+// It is derived from the UTC date and the key fingerprint, so that it can never be a valid transaction hash,
+// and a signature produced for one key or day cannot be replayed for another.
+func CanaryMessage(config *Config, date time.Time) []byte {
+	h := hash.New(
+		&hash.BytesWithDomain{TheDomain: "CMP Health Check", Bytes: []byte(date.UTC().Format(healthDateLayout))},
+		&hash.BytesWithDomain{TheDomain: "Key Fingerprint", Bytes: []byte(KeyFingerprint(config))},
+	)
+	return h.Sum()[:32]
+}
+
+// HealthCheck produces a signature on the canary message of the given date with a previously generated presignature.
+// This only requires the single round of PresignOnline, and proves that the signers are online and that their
+// shares are consistent with the public key, without signing anything meaningful.
+//
+// As with PresignOnline, the presignature must never be used again afterwards.
+// Returns *ecdsa.Signature if successful, which should be passed to HealthMonitor.Record.
+func HealthCheck(config *Config, preSignature *ecdsa.PreSignature, date time.Time, pl *pool.Pool) protocol.StartFunc {
+	return presign.StartPresignOnline(config, preSignature, CanaryMessage(config, date), pl)
+}
+
+// VerifyHealthCheck returns an error if signature is not a valid signature of the canary message of config for date.
+func VerifyHealthCheck(config *Config, date time.Time, signature *ecdsa.Signature) error {
+	if signature == nil {
+		return errors.New("health check: nil signature")
+	}
+	if !signature.Verify(config.PublicPoint(), CanaryMessage(config, date)) {
+		return errors.New("health check: invalid signature")
+	}
+	return nil
+}
+
+// HealthReport is the outcome of a single health check.
+type HealthReport struct {
+	// Fingerprint identifies the key, see KeyFingerprint.
+	Fingerprint string
+	// Date is the day of the canary message.
+	Date time.Time
+	// CheckedAt is the time at which the report was recorded.
+	CheckedAt time.Time
+	// Signers are the parties which took part in the check.
+	Signers party.IDSlice
+	// Err is nil if the check succeeded.
+	Err error
+}
+
+// Healthy returns true if the check succeeded.
+func (r HealthReport) Healthy() bool { return r.Err == nil }
+
+// HealthMonitor keeps the latest health report of each key, so that monitoring can alert on keys
+// whose shares have stopped working before a real signature is needed.
+// It is safe for concurrent use.
+type HealthMonitor struct {
+	mtx     sync.Mutex
+	reports map[string]HealthReport
+	now     func() time.Time
+}
+
+// NewHealthMonitor returns an empty HealthMonitor.
+func NewHealthMonitor() *HealthMonitor {
+	return &HealthMonitor{
+		reports: make(map[string]HealthReport),
+		now:     platform.Now,
+	}
+}
+
+// Record verifies the outcome of a HealthCheck protocol, given by the Result of its handler, and stores the report.
+func (m *HealthMonitor) Record(config *Config, signers []party.ID, date time.Time, result interface{}, err error) HealthReport {
+	if err == nil {
+		signature, ok := result.(*ecdsa.Signature)
+		if !ok {
+			err = errors.New("health check: result is not a signature")
+		} else {
+			err = VerifyHealthCheck(config, date, signature)
+		}
+	}
+	report := HealthReport{
+		Fingerprint: KeyFingerprint(config),
+		Date:        date.UTC(),
+		CheckedAt:   m.now(),
+		Signers:     party.NewIDSlice(signers),
+		Err:         err,
+	}
+	m.mtx.Lock()
+	m.reports[report.Fingerprint] = report
+	m.mtx.Unlock()
+	return report
+}
+
+// Latest returns the latest report for the key with the given fingerprint.
+func (m *HealthMonitor) Latest(fingerprint string) (HealthReport, bool) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	report, ok := m.reports[fingerprint]
+	return report, ok
+}
+
+// Unhealthy returns the sorted fingerprints of all keys whose latest check failed,
+// or which have not been successfully checked within maxAge.
+func (m *HealthMonitor) Unhealthy(maxAge time.Duration) []string {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	now := m.now()
+	var unhealthy []string
+	for fingerprint, report := range m.reports {
+		if !report.Healthy() || now.Sub(report.CheckedAt) > maxAge {
+			unhealthy = append(unhealthy, fingerprint)
+		}
+	}
+	sort.Strings(unhealthy)
+	return unhealthy
+}
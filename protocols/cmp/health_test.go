@@ -0,0 +1,74 @@
+package cmp
+
+import (
+	"crypto/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+)
+
+func TestHealthCheck(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, partyIDs := test.GenerateConfig(curve.Secp256k1{}, 2, 1, rand.Reader, pl)
+	date := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	c := configs[partyIDs[0]]
+
+	assert.Len(t, CanaryMessage(c, date), 32)
+	assert.Equal(t, CanaryMessage(c, date), CanaryMessage(c, date.Add(time.Hour)), "canary is fixed for a day")
+	assert.NotEqual(t, CanaryMessage(c, date), CanaryMessage(c, date.AddDate(0, 0, 1)))
+
+	n := test.NewNetwork(partyIDs)
+	results := make(map[party.ID]interface{}, len(partyIDs))
+	var mtx sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(len(partyIDs))
+	for _, id := range partyIDs {
+		go func(id party.ID) {
+			defer wg.Done()
+			config := configs[id]
+			h, err := protocol.NewMultiHandler(Presign(config, partyIDs, nil), nil)
+			require.NoError(t, err)
+			test.HandlerLoop(id, h, n)
+			r, err := h.Result()
+			require.NoError(t, err)
+
+			h, err = protocol.NewMultiHandler(HealthCheck(config, r.(*ecdsa.PreSignature), date, nil), nil)
+			require.NoError(t, err)
+			test.HandlerLoop(id, h, n)
+			r, err = h.Result()
+			require.NoError(t, err)
+			mtx.Lock()
+			results[id] = r
+			mtx.Unlock()
+		}(id)
+	}
+	wg.Wait()
+
+	monitor := NewHealthMonitor()
+	report := monitor.Record(c, partyIDs, date, results[partyIDs[0]], nil)
+	assert.True(t, report.Healthy())
+	assert.Empty(t, monitor.Unhealthy(time.Hour))
+	latest, ok := monitor.Latest(KeyFingerprint(c))
+	require.True(t, ok)
+	assert.Equal(t, report.Fingerprint, latest.Fingerprint)
+
+	// the signature is not valid for another day
+	report = monitor.Record(c, partyIDs, date.AddDate(0, 0, 1), results[partyIDs[0]], nil)
+	assert.False(t, report.Healthy())
+	assert.Equal(t, []string{KeyFingerprint(c)}, monitor.Unhealthy(time.Hour))
+
+	// a successful check becomes stale
+	monitor.Record(c, partyIDs, date, results[partyIDs[0]], nil)
+	monitor.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	assert.Equal(t, []string{KeyFingerprint(c)}, monitor.Unhealthy(time.Hour))
+}
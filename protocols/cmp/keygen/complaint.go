@@ -0,0 +1,81 @@
+package keygen
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cronokirby/saferith"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// ShareComplaint is the error returned when the share received from Accused in round 4
+// does not match its VSS polynomial.
+// It reveals the decryption of the share along with its nonce, so that any other party can check the complaint
+// without the Paillier secret key of Accuser, and decide which of the two parties is lying.
+//
+// Revealing the share is safe since the protocol aborts, and the share is never used.
+type ShareComplaint struct {
+	// Accuser is the party which received the share.
+	Accuser party.ID
+	// Accused is the party which sent the share.
+	Accused party.ID
+	// Share = Enc(x; ρ) is the ciphertext received by Accuser.
+	Share *paillier.Ciphertext
+	// Plaintext = x is the decryption of Share.
+	Plaintext *saferith.Int
+	// Nonce = ρ is the randomness of Share.
+	Nonce *saferith.Nat
+	// Expected = Fⱼ(i) is the evaluation at the index of Accuser of the VSS polynomial broadcast by Accused,
+	// or at one of its additional evaluation points if Accuser has a weight larger than 1.
+	Expected curve.Point
+}
+
+// Error implements error.
+func (c *ShareComplaint) Error() string {
+	return fmt.Sprintf("keygen: share received from %s does not match its VSS polynomial", c.Accused)
+}
+
+// newShareComplaint creates a complaint against accused for the given share.
+func (r *round4) newShareComplaint(accused party.ID, share *paillier.Ciphertext, expected curve.Point) error {
+	plaintext, nonce, err := r.PaillierSecret.DecWithRandomness(share)
+	if err != nil {
+		return err
+	}
+	return &ShareComplaint{
+		Accuser:   r.SelfID(),
+		Accused:   accused,
+		Share:     share,
+		Plaintext: plaintext,
+		Nonce:     nonce,
+		Expected:  expected,
+	}
+}
+
+// Verify returns nil if the complaint is justified, in which case Accused sent an invalid share.
+// Otherwise, Accuser is lying and an error is returned.
+//
+// receiver is the Paillier public key broadcast by Accuser in round 3.
+// The caller must also check that Expected is the evaluation at the index of Accuser
+// of the VSS polynomial broadcast by Accused in round 3.
+func (c *ShareComplaint) Verify(group curve.Curve, receiver *paillier.PublicKey) error {
+	if c.Share == nil || c.Plaintext == nil || c.Nonce == nil || c.Expected == nil {
+		return errors.New("keygen: complaint has nil fields")
+	}
+	if !receiver.ValidateCiphertexts(c.Share) {
+		return errors.New("keygen: complaint contains an invalid ciphertext")
+	}
+	if !receiver.EncWithNonce(c.Plaintext, c.Nonce).Equal(c.Share) {
+		return errors.New("keygen: complaint does not open the share")
+	}
+	share := group.NewScalar().SetNat(c.Plaintext.Mod(group.Order()))
+	if c.Plaintext.Eq(curve.MakeInt(share)) != 1 {
+		// the share is out of range
+		return nil
+	}
+	if share.ActOnBase().Equal(c.Expected) {
+		return errors.New("keygen: complaint against a valid share")
+	}
+	return nil
+}
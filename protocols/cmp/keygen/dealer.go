@@ -0,0 +1,109 @@
+package keygen
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/multi-party-sig/internal/types"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/polynomial"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
+)
+
+// Dealer creates the configs of all parties for the given secret key, without running the protocol.
+// The secret is shared with a random polynomial of degree threshold, and the keys of each party are sampled locally.
+//
+// The dealer knows the secret key and all shares, so the resulting configs are only as secure as the machine running
+// Dealer. It is meant for tests, and for migrating a key held by a single party in a controlled ceremony,
+// after which the configs should be refreshed and the dealer's memory wiped.
+//
+// The configs are marked with config.Config.Dealt, so that IsDealer can recognize them.
+// A refresh clears this mark.
+func Dealer(secret curve.Scalar, parties []party.ID, threshold int, pl *pool.Pool) (map[party.ID]*config.Config, error) {
+	return DealerWeighted(secret, parties, nil, threshold, pl)
+}
+
+// DealerWeighted is the same as Dealer, but gives each party the number of shares set in weights,
+// and threshold applies to the total weight of the parties, see party.Weights.
+func DealerWeighted(secret curve.Scalar, parties []party.ID, weights party.Weights, threshold int, pl *pool.Pool) (map[party.ID]*config.Config, error) {
+	if secret == nil || secret.IsZero() {
+		return nil, errors.New("keygen: dealer secret is zero")
+	}
+	partyIDs := party.NewIDSlice(parties)
+	if !partyIDs.Valid() {
+		return nil, errors.New("keygen: parties contains duplicates")
+	}
+	if err := weights.Validate(); err != nil {
+		return nil, fmt.Errorf("keygen: %w", err)
+	}
+	if !config.ValidThreshold(threshold, weights.Total(partyIDs)) {
+		return nil, fmt.Errorf("keygen: threshold %d is invalid for %d parties", threshold, len(partyIDs))
+	}
+	group := secret.Curve()
+	if err := weights.EvaluationPoints(partyIDs).ValidateScalars(group); err != nil {
+		return nil, fmt.Errorf("keygen: %w", err)
+	}
+
+	rid, err := types.NewRID(platform.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("keygen: failed to sample RID: %w", err)
+	}
+	chainKey, err := types.NewRID(platform.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("keygen: failed to sample chain key: %w", err)
+	}
+
+	f := polynomial.NewPolynomial(group, threshold, secret)
+	public := make(map[party.ID]*config.Public, len(partyIDs))
+	configs := make(map[party.ID]*config.Config, len(partyIDs))
+	for _, id := range partyIDs {
+		paillierSecret := paillier.NewSecretKey(pl)
+		s, t, _ := sample.Pedersen(platform.Reader, paillierSecret.Phi(), paillierSecret.N())
+		elGamalSecret := sample.Scalar(platform.Reader, group)
+		ecdsaSecret := f.Evaluate(id.Scalar(group))
+		public[id] = &config.Public{
+			ECDSA:    ecdsaSecret.ActOnBase(),
+			ElGamal:  elGamalSecret.ActOnBase(),
+			Paillier: paillierSecret.PublicKey,
+			Pedersen: pedersen.New(paillierSecret.Modulus(), s, t),
+		}
+		var extraShares []curve.Scalar
+		for _, extraID := range id.WeightedIDs(weights.Of(id))[1:] {
+			extra := f.Evaluate(extraID.Scalar(group))
+			extraShares = append(extraShares, extra)
+			public[id].Shares = append(public[id].Shares, extra.ActOnBase())
+		}
+		configs[id] = &config.Config{
+			Group:     group,
+			ID:        id,
+			Threshold: threshold,
+			ECDSA:     ecdsaSecret,
+			Shares:    extraShares,
+			ElGamal:   elGamalSecret,
+			Paillier:  paillierSecret,
+			RID:       rid.Copy(),
+			ChainKey:  chainKey.Copy(),
+			Dealt:     true,
+		}
+	}
+	// public is only complete once all parties were dealt, and each config gets its own copy of it
+	for _, c := range configs {
+		c.Public = make(map[party.ID]*config.Public, len(public))
+		for j, p := range public {
+			c.Public[j] = p
+		}
+	}
+	return configs, nil
+}
+
+// IsDealer returns true if c was created by Dealer and has not been refreshed since,
+// which means that the secret key was known to a single machine.
+func IsDealer(c *config.Config) bool {
+	return c.Dealt
+}
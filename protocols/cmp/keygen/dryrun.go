@@ -0,0 +1,112 @@
+package keygen
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/cronokirby/saferith"
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
+)
+
+// dryRunStatParam is the number of iterations of the zkmod and zkprm proofs during a dry run.
+const dryRunStatParam = 1
+
+// dryRunProtocolSuffix is appended to the protocol ID of a dry run, so that it can not be mixed with a real keygen.
+const dryRunProtocolSuffix = "-dry-run"
+
+// MaxDryRunParticipants is the maximum number of participants of a dry run, which is the number of fixed Paillier keys.
+const MaxDryRunParticipants = len(dryRunPrimes)
+
+// dryRunPrimes are the fixed Paillier primes of the participants of a dry run, in hex, given to each participant
+// according to its index in the sorted participants. They were generated with sample.Paillier.
+var dryRunPrimes = [...][2]string{
+	{"D5418CADE751BE5E6A000AE759FFAEC7F81841ABB9908BD7CAFB97B0E17D3436D419666D4DDCE0B48AD668FA7FD042B24504F4FCDB1BF0C3F225BBDAAC163147CCA1B607D6B89FE4BC7B134F88C634E467E67D73619993068F65367D6FA07C8509CC9C02B4C0B78B804149176A289B74FADF76C635AF171C0081BF2FAA76F4C3", "DBB15F04282A9B8E3E9A010EFFDB8DA647722C73BD58815C62D35A565779715DCCBF0877DD16271410C2D032BA7CA51A8AAA22618F321C8D0AA91AB21ACB26F0FEC2288AE75F3291A7DBCC2A62C5A07197332BB2A3CF54FAAEE2D6014F05FE13E0B09108CF33F47ACBC376A780F9E3ADA8F98F9F930C84CC4CEDAB727A91EAEB"},
+	{"D7E84E726C057D6BA8349E6DC14C337DB0200506A481BD0219E34297977707ED41D9B8D4120DCDC0C73A75F77E482933460B67D70105E706A2F3ADFFA9A989D6ECA2E42BB163D68ABE4D05A61CA2A084D173FB8371DBE90BA4386E328C77FB6EDC1E036FADA16EC3C26204E13822A589B57AAD99CCAEB00BE8AB70559EE2697B", "D1328B612785EB6101F331B3D2F8B3FF0FF78DB7F48B785D8500DD7D98C7673B0FAFA0674B8A60BBF65FC697EFD27966D6F1DC655FE2A8C12DD7B4C63407C27ECE69DAE9365417DD625B9B604C20361DF1E410DCEC4ED809B5B6BFF26A1B7A469B003AC893DD8202B604C549E7612F258877E415E85358A60C916DC709E31E27"},
+	{"F5D397B80A2E30DA3394F4D0166D6F670E19EF969C8E49607EB517A3E63A8BBF966715416FDD4ABE4F9F1EFBDD73397948FCC3E89F5570B6C17DD2C15F550F88FC0FBB9F3F6324DE1BBE4E11AC0AF6D91DE27E27BF85159704CC8C0B3548F94B8D7BB9F665C337BEFF5E554123DC26A8EA9DD5409C5966EEA07B7C8F96091D03", "E7365C429314B34EF2641FBBF026D0EB9040758EAFEF4187E08BB44C8A7437739091EC21EF9C962D1B1B4F2DE49B217798A4557ED30583F3E5396B94B924DCAB6BB3C4CC88148B82A9440A81297D50B99798DF885CA227F3691E4E060986EFD3306A50F2FAE789C5A17BF1BF3A205F9EBA68594C3FE19E93C9DF360AED06553B"},
+	{"DDE5861F0B1C63B715932541543677E5015C73935B20CDD4226E338062EC06A23F3F50A8EAE6D7CF59E67575B3FC26F3A703E1F32D375203BA06BF464A7461DE518CBD970C9BE2DB8678CA6906AC29D2054C36D0CA115432DB5D7FCD91A9AEF8E1D6C69D3DD31D695C567DE9B26DC222D15713D940233E362F30982CF8258023", "D5CC01875EE353128DAE78AA1103EC6A456DE3DDE0DB7E42DB094D4357D43FACAAAC7DB92AAC49EDBE30D8424DA07CFB97B820909BAE08AA65BF79D4893F9878480438A6F849D574353D392A49AAC9BBC52554C1AC6FAE5C79D6DF1AABE1B36A954D8736F187060413BF78379D06F4F679068E9E730A710CBF361D9563DCD693"},
+	{"E9511974354F9B22A662BC2593C3C64C522FE526CA411BC6D7CDC4B8B70F386A9B2DE352A847C5E314CFBF935ADC9F33C72902E62CADE0431B5C5FB32BAD3AF4A660EDD720BD61A0D4814621EBF436E68EB76D940B8A28ACE2EB4FB8B0DA090CEFC05209AC51B69578A9227402853E291B05F2B0E8F9EA3FD75866D55064A3A7", "FED2026F3129F801640B51F6DE4D829915C7259E5DB715B1605AAB4BA5CB08942724F558780B18C61050B69072DCC2FBA32292CBAAE1A567923CFDB36141908F64D0B712D9255B6A99D02E2717831AD48078EB49E0BC281D3FFC0786B2E2364D79DF80B41894CC2B3F3BB6AEBEF04FE550730FB733E1FCA063CA2EAE64083D0F"},
+	{"EF09DAF6BCD0A54590FBBEEC6D81DC2B10113DE87DD2E6ECC92774D8C48043C262E7C6136ED907B7FB03669914A068344B99FB68AE370F1DD80A63A036DCAAEA5AF0F06E0C3441AD2293E3963F577907E6A42955904B1944CF81B3D9B2609C91FD4410B12718E5635FB8878D5BBC1149563AA827F95BA0B27379E8E23EAFA1BF", "D9B376135106E8916D2F1CBDFBE0BE69126BB3339F04CC4481D98752E3DD6D477A65169099A5FEB383EE2EAB3B4317B90914B84D6B74F1B31F0D92C8D08ACE44A0E1EB999BCB55B0F5195D569C8BA3AC3CE8DE55F2C0D76EE418D16D924C7E64FF2ED2558604B87E31650E63B8EC1FAE113A0EBB9A430468F393E78010C7E20B"},
+	{"C75AFFA6661A01A7B3F8FBAF1B0D2CE4927CACFEB241E177BEFD01B14DE740E31CE7EE3F643AAC22249314FE74C34364C09F36861E13D75252232C98D266CAD2B54597C492902A57446D3EAF307F9468B0E6D057DA1E9D9FFEA95432B3C2AF109DF6A361F450CD6AF7ABF38EC6A8A77789B1306836874C6A73EB09B9C55FE2A3", "C3D046BF215D486B1089B753C23D8C3D61E135EBE344688DFB466C7DFDC1078E7AFDE0A30B15DD0D6FC2BB5570695D6D939C650A658D63A35436AF22CE74F92417683A755B1C098CFF70DC837C42333603F9BF1C403B8D2B4323A8069AEC56774261885966416C2DB1241E0703174456067F47133B4D833CDE28ED85FBC190C7"},
+	{"FCF1F60BA5E6A1A9CF6E5DDE3C8F8EA62AF01876274616E55BEBE73AB0E630B2F2C0C8FFA5BFE0F4E059A0534906AF23092E99FD16A3F70EFD74ECDFBFF87EB78B59B2EACECF7B48514011649299297B23060D0B886FCC2B8287F09031C052AC74A0C199F2C655DD30184BF036A537622BB84F54875CBF7F37372B89CFDBD713", "E7BA2F5849D352E451C7F73522A523B970657D474F43EC1AD0A6DD9646FBBD2978C18CE092686545B6D37D5F9391DC6B12973514930AFBC14133DF9EDE15EF1F701F14CF893606997C44BDB5422DF53A630177B9CAE56329AAE72A7500523F42882F5F2826307CE0A1996AE6AC75224FB6D37FE10F64544B635EF0BF6313B90F"},
+	{"EA4D6BF0D649A76872F724E6BEC391AA4AFA213EE57689C2A93570C3262A78A15810CB34ACB79219BA1A90B5A007F5A840B043A767E36CAEEE6159E7A62948456C948D7DF6F129F36E46D9D561DC8268637227CFDBCD17591D933781C467655B2C191ABB1B5BA656A496CCDDCD890F8D6019C30FA70849D5A607EA402C98E3CF", "DB1319AA3D43C0246D2178B33B4735EAF0C7B0793B58496D43342EAB952A14A8DB8EF22D017378C73170964DC217B2677AE8BDE57678C1321D91FD96FB3486C5F4F1C4704A766A1F69257581404660B954DB8B6B8E92BC03F5B6376E88A006C99A6341FB8725204D1F8A9EBB7B07EC5675353BD8212325574C033B4ACF40F743"},
+	{"EA5D99A55B9D1B4BAC2A21B26823DE70A1E15B63DFF5BE0629FD56E140079CF4F9A087C720FA5F11E129564CE6EC33E4858E2AD1FE8359CACD439C4EFCF118FCC1B90918701E84F57A2403839D139968FF479ACAF5CA6173F65179B878E4FEA15A1C36E781F6E03F1A77C726644E43EBBE58AB4A6D18C18B721D977ECA9B27B3", "F4B830A232DBB1D9527A7C8526163D6FA02693CB7F1810408C545FEB3A170F61965A7A55B6624FE845AE899250DD127453FE197EEC2B2F99BEAE4870A78FC8FED272819517D9CB3C4FC6BDDDC1FC3CDC624B8A55CB91D5AD12A1F1A0A97D5A8A1235E596C788000F652ADFF2CFA552257D50F67C0E1739E9A2EA01CB342A25FF"},
+	{"E6AA48C0BB9466825842A8642421B058F62364DB2BED77F11F489C90A1219DE7C27EEF6F1129A59344E40FE61386085C9DFC33338EA65907F8314C81A3332B0339779B1865BFBFB23DDEC13C200B4FB7F87FC7D005E5E606A47762051828312408BC0BFF4EA872D9A6C4FA911DA0DE5B4AEF6F37AA08F986483DE1A2DD3C3703", "E74116CB360170897BEA00D6804F022D4EC73F8CFD82B0E4A736B7BF0BED1E0D7DC174058B2664DAD15ED4BD77778C2558037C38CF39A9045F417C5287B77DB71F2135FC2185695FF977D80D8A49DFCC50989339A5767F518CD00376A733FCDF50E8DF98DFC5A0E266EAE630050ACECAC9DE211D72E7D6776B62485832D83F47"},
+	{"DA42954B9D8BEA7A11653F69123771DAB8198EAD5D3E42DB54B6479D3890FFA04850C6CE7A6914F93138DDF945D30C732987D57449F64C685B4E2A3F9ADA489851999F16CA6C5220BA2B7EA10C0807EB752C9B630561F51F9690B33C99C9DA8B544B10870E04545C57DF4029B458E4C87A3392C835EDBEEF59D5509BE0041753", "C0C78B2FEA82191B029E0BD3C867085E1FED966ECAB56C734801E3D92812EE1A3EEB00883C0EA7B8BF59115B7BF86C353F1C0EFF2DFF7CD19EAA3BBD50E8A289EAC8443024F6F91ED8069F9991B8DE0EB8B26537B7A9127D25C82FEF989FAD5841C50EA91568EF4DC4B5855D5B0209E89433D0287EC0B81F6D440D4404B4EAC7"},
+	{"E1CE2CA503507F52DE4779AFD180ED177C32F077BFB63715FE63585C54B11BC51422B7567DCFFD1C33C2F5BF94A82CD2D1C173830865E86845A4CF84078D2D6E8632FAC60C2A7D4C995D6489FF658D86BB4D41A2B7F66062FC68D16612D33C6B8629E09D78A98C4BEA64F54E88C09A1985A0FAA0164AAB6AB7BAD6204460248F", "D978B824BF1DD72D0583B0B749993C9085ABBD4DC316D6C77C7E762306E7D706C2DA382C7F7AE7FB42A71EC49DDF79237E11AD7D46BE7E4E1A69DA320A2230809E24964066B96ED99A34AED476D29C0506A70ABC0B2DE2CE4FC02E67071A82C57C3A23ADCA139056B784DF8F28B196147CA85FE56CB01AC2F08F4E628A8DEC2F"},
+	{"DD8B96B99F1871F61EFBFF07DC556DEA95247C83F254ABF6E45193E4F51952E69C819DA65E8A03371C926814F9AA43ED3B8E67B21C0A12D83F01242287EB636B9AF28D2257A7C42BC17F678AD2EBF6B0BF43D0E7E0003AF8FE42EB973D81945EC0EC4BEE936156DC7192E8D711F5B1605A05C22ED86FCCE9ACD328A07AC85857", "DCB2CFE6394854C7E728ABA0B297FC7F2E3C9A3EA477B324A818407B85EB6BD26DB44619AE33D610AB8A2F3A79B3B22AB99EF00B833BE71E52C467D5D7B89CC8B7F6954F3509BEFDF08188DED7DBF5968220B084A678B0CAAEE75B73586E809FE30172B998B3977CA3E854FE7568C8293BA2F914FF0A953668426C9DF5ABD6A7"},
+	{"E39CB403402D6EC290A924BAB96F98394CE5EF05794609D2B378258DEDC179E2278D6B39B67D6F0DB960680E59FC59872A4F60C9E36D8DB78775717041ADCFFB891CAABF6552C4F37BB9B7395B90242094F4BF7E013AE5DC2116D2998F8F0A07F85058048E8C25359423998AD19E19760D160EFB233D46E07811483FAD7A26F3", "EACD1AAA0641AF000A39A84D2DAB5E28BA0C3A978A48B75E12B570957E314EBA27E5030F7F2CC3AB953CE2584C15F97E4F5218C94C2F44E0D3BFCAAAB92C4D9B13A107A4287B2FE54D6861969DB18E617244A76C2CBD16A97C6AB79F677863C4A8307A06E52B8C74D681E1607B98BF83AC66F389EDA20EF5E75F78530322361B"},
+	{"E201E06E2DF817E7F04A1969B85975CE83B59CE8A31DE163582D9C169C79DBBA93EDC58A03C67C6B26E690DC2E20814850E2BD17E3A02A3F5E766AFEDF3FAA1226AEFE2E604AE90584519DAABEC79100BCDE2D2328051564065C1249598E42CFA839515889AE725FEC8D714E849AD4B2252C26409EB75DE6BE8C5D976FB1FBA7", "DBCAD57CF2CE947F4FFD6DE181825998BF62C537F456905BDD252B2D72EC68D823E7339F96018E7236744CADDEC32C14EC461AF66242058B0F60A1C435C45B8706F38B437AB5F8236BB635E4D6321F1D5ECAB261B80B3B41A3E31D8C5E9A184F20C1844FCA1C5431A19BF02404F64EDF4AF4FDBD300CD681138C2744C400931B"},
+}
+
+var (
+	dryRunKeysOnce sync.Once
+	dryRunKeys     []*paillier.SecretKey
+)
+
+// StartDryRun is the same as Start for a new key, but gives each participant a fixed and publicly known Paillier key,
+// and uses a single iteration of the zkmod and zkprm proofs.
+// It completes in milliseconds instead of minutes, and produces a config which is INSECURE:
+// anyone can decrypt the Paillier ciphertexts of all parties, and therefore recover their shares.
+//
+// It is only meant for integration tests of the code surrounding the protocol, see IsDryRun.
+func StartDryRun(info round.Info, pl *pool.Pool) protocol.StartFunc {
+	info.ProtocolID += dryRunProtocolSuffix
+	if info.StatParam == 0 {
+		info.StatParam = dryRunStatParam
+	}
+	info.InsecureStatParam = true
+	start := Start(info, pl, nil)
+	return func(sessionID []byte) (round.Session, error) {
+		if len(info.PartyIDs) > MaxDryRunParticipants {
+			return nil, fmt.Errorf("keygen: a dry run supports at most %d participants", MaxDryRunParticipants)
+		}
+		session, err := start(sessionID)
+		if err != nil {
+			return nil, err
+		}
+		session.(*round1).DryRun = true
+		return session, nil
+	}
+}
+
+// dryRunPaillier returns the fixed Paillier keys of the participants of a dry run.
+func dryRunPaillier() []*paillier.SecretKey {
+	dryRunKeysOnce.Do(func() {
+		dryRunKeys = make([]*paillier.SecretKey, len(dryRunPrimes))
+		for i, primes := range dryRunPrimes {
+			p, _ := new(saferith.Nat).SetHex(primes[0])
+			q, _ := new(saferith.Nat).SetHex(primes[1])
+			dryRunKeys[i] = paillier.NewSecretKeyFromPrimes(p, q)
+		}
+	})
+	return dryRunKeys
+}
+
+// dryRunKey returns the fixed Paillier key of this party in a dry run.
+func (r *round1) dryRunKey() (*paillier.SecretKey, error) {
+	for i, id := range r.PartyIDs() {
+		if id == r.SelfID() && i < MaxDryRunParticipants {
+			return dryRunPaillier()[i], nil
+		}
+	}
+	return nil, fmt.Errorf("a dry run supports at most %d participants", MaxDryRunParticipants)
+}
+
+// IsDryRun returns true if c was generated by StartDryRun, and must not be used to protect any funds.
+func IsDryRun(c *config.Config) bool {
+	for _, public := range c.Public {
+		if public.Paillier == nil {
+			continue
+		}
+		for _, sk := range dryRunPaillier() {
+			if public.Paillier.Equal(sk.PublicKey) {
+				return true
+			}
+		}
+	}
+	return false
+}
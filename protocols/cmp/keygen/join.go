@@ -0,0 +1,117 @@
+package keygen
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/polynomial"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
+)
+
+// StartJoin returns the StartFunc of a resharing which gives a share of the key of c to one of its pending parties,
+// which were invited to the keygen but did not take part in it, see config.Config.Pending.
+// All parties of c take part, along with the joining party, which uses StartJoinAsNewParty:
+// info.PartyIDs must contain c.PartyIDs() and the joining party.
+//
+// Each party j of c shares its additive share λⱼ•xⱼ with a polynomial of degree t, and the joining party shares 0,
+// so that the public key and chain key are unchanged, while all shares are replaced as in a refresh.
+// The Threshold, Group, Epoch and Pending parties of info are taken from c, and the joining party
+// is removed from the pending parties of the output.
+func StartJoin(info round.Info, pl *pool.Pool, c *config.Config) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		if c == nil {
+			return nil, errors.New("keygen: config is nil")
+		}
+		if c.ID != info.SelfID {
+			return nil, fmt.Errorf("keygen: config belongs to %s", c.ID)
+		}
+		return startJoin(info, pl, c.PublicConfig(), c.ECDSA, sessionID)
+	}
+}
+
+// StartJoinAsNewParty is the same as StartJoin, for the joining party, which must be one of the pending parties
+// of public. It obtains public from the other parties, and does not need to trust it: public is included in the SSID,
+// so that the session fails unless all parties use the same one.
+func StartJoinAsNewParty(info round.Info, pl *pool.Pool, public *config.PublicConfig) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		if public == nil {
+			return nil, errors.New("keygen: public config is nil")
+		}
+		if _, ok := public.Public[info.SelfID]; ok {
+			return nil, fmt.Errorf("keygen: %s already holds a share", info.SelfID)
+		}
+		return startJoin(info, pl, public, nil, sessionID)
+	}
+}
+
+// startJoin creates the first round of a join, where secret is the previous share of this party,
+// or nil for the joining party.
+func startJoin(info round.Info, pl *pool.Pool, public *config.PublicConfig, secret curve.Scalar, sessionID []byte) (round.Session, error) {
+	if public.Weights() != nil || !info.Weights.IsTrivial() {
+		return nil, errors.New("keygen: join of weighted configs is not supported")
+	}
+	previous := public.PartyIDs()
+	partyIDs := party.NewIDSlice(info.PartyIDs)
+	if !partyIDs.Contains(previous...) {
+		return nil, errors.New("keygen: all parties of the config must take part in a join")
+	}
+	if len(partyIDs) != len(previous)+1 {
+		return nil, fmt.Errorf("keygen: a join must add a single party, got %d parties for %d", len(partyIDs), len(previous))
+	}
+	var joining party.ID
+	for _, id := range partyIDs {
+		if _, ok := public.Public[id]; !ok {
+			joining = id
+		}
+	}
+	if !public.IsPending(joining) {
+		return nil, fmt.Errorf("keygen: %s is not a pending party of the config", joining)
+	}
+
+	info.Threshold = public.Threshold
+	info.Group = public.Group
+	info.Epoch = public.Epoch
+	info.Pending = party.IDSlice(public.Pending).Remove(joining)
+
+	// the chain key is kept, so it is bound to the session along with the public data
+	previousConfig := &config.Config{
+		Group:     public.Group,
+		Threshold: public.Threshold,
+		RID:       public.RID,
+		Epoch:     public.Epoch,
+		Public:    public.Public,
+	}
+	helper, err := round.NewSession(info, sessionID, pl, previousConfig,
+		&hash.BytesWithDomain{TheDomain: "Chain Key", Bytes: public.ChainKey})
+	if err != nil {
+		return nil, fmt.Errorf("keygen: %w", err)
+	}
+
+	group := helper.Group()
+	lagrange := polynomial.Lagrange(group, previous)
+	constants := make(map[party.ID]curve.Point, len(previous))
+	for _, j := range previous {
+		constants[j] = lagrange[j].Act(public.Public[j].ECDSA)
+	}
+
+	// fᵢ(0) = λᵢ•x'ᵢ, or 0 for the joining party
+	constant := group.NewScalar()
+	if secret != nil {
+		if !secret.ActOnBase().Equal(public.Public[helper.SelfID()].ECDSA) {
+			return nil, errors.New("keygen: ECDSA share does not match public data")
+		}
+		constant.Set(lagrange[helper.SelfID()]).Mul(secret)
+	}
+	return &round1{
+		Helper:             helper,
+		PreviousChainKey:   public.ChainKey.Copy(),
+		VSSSecret:          polynomial.NewPolynomial(group, helper.Threshold(), constant),
+		ResharingConstants: constants,
+	}, nil
+}
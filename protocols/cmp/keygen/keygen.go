@@ -1,7 +1,7 @@
 package keygen
 
 import (
-	"crypto/rand"
+	"errors"
 	"fmt"
 
 	"github.com/taurusgroup/multi-party-sig/internal/round"
@@ -9,6 +9,7 @@ import (
 	"github.com/taurusgroup/multi-party-sig/pkg/math/polynomial"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 	"github.com/taurusgroup/multi-party-sig/pkg/pool"
 	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
 	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
@@ -18,6 +19,13 @@ const Rounds round.Number = 5
 
 func Start(info round.Info, pl *pool.Pool, c *config.Config) protocol.StartFunc {
 	return func(sessionID []byte) (_ round.Session, err error) {
+		if c != nil && (c.Weights() != nil || !info.Weights.IsTrivial()) {
+			return nil, errors.New("keygen: refresh of weighted configs is not supported")
+		}
+		// the parties which may still join the key are kept by a refresh
+		if c != nil && info.Pending == nil {
+			info.Pending = c.Pending
+		}
 		var helper *round.Helper
 		if c == nil {
 			helper, err = round.NewSession(info, sessionID, pl)
@@ -45,7 +53,7 @@ func Start(info round.Info, pl *pool.Pool, c *config.Config) protocol.StartFunc
 		}
 
 		// sample fᵢ(X) deg(fᵢ) = t, fᵢ(0) = secretᵢ
-		VSSConstant := sample.Scalar(rand.Reader, group)
+		VSSConstant := sample.Scalar(platform.Reader, group)
 		VSSSecret := polynomial.NewPolynomial(group, helper.Threshold(), VSSConstant)
 		return &round1{
 			Helper:    helper,
@@ -1,16 +1,27 @@
 package keygen
 
 import (
+	"context"
+	"crypto/rand"
+	"errors"
 	mrand "math/rand"
+	"sync"
 	"testing"
 
+	"github.com/cronokirby/saferith"
 	"github.com/fxamacker/cbor/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/taurusgroup/multi-party-sig/internal/round"
 	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
 	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/pkg/zk"
 	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
 )
 
@@ -46,6 +57,7 @@ func checkOutput(t *testing.T, rounds []round.Session) {
 			assert.True(t, p.Pedersen.T().Eq(c.Public[id].Pedersen.T()) == 1, "T not the same", id)
 			assert.True(t, p.Pedersen.N().Nat().Eq(c.Public[id].Pedersen.N().Nat()) == 1, "N not the same", id)
 		}
+		assert.NoError(t, c.VerifyCertificate(), "key certificate of %s", c.ID)
 		data, err := c.MarshalBinary()
 		assert.NoError(t, err, "failed to marshal new config", c.ID)
 		c2 := config.EmptyConfig(group)
@@ -61,19 +73,116 @@ func TestKeygen(t *testing.T) {
 	N := 2
 	partyIDs := test.PartyIDs(N)
 
+	for _, version := range []hash.ChallengeVersion{hash.ChallengeV1, hash.ChallengeV3} {
+		rounds := make([]round.Session, 0, N)
+		for _, partyID := range partyIDs {
+			info := round.Info{
+				ProtocolID:       "cmp/keygen-test",
+				FinalRoundNumber: Rounds,
+				SelfID:           partyID,
+				PartyIDs:         partyIDs,
+				Threshold:        N - 1,
+				Group:            group,
+				ChallengeVersion: version,
+			}
+			r, err := Start(info, pl, nil)(nil)
+			require.NoError(t, err, "round creation should not result in an error")
+			rounds = append(rounds, r)
+		}
+
+		for {
+			err, done := test.Rounds(rounds, nil)
+			require.NoError(t, err, "failed to process round with challenge version %d", version)
+			if done {
+				break
+			}
+		}
+		checkOutput(t, rounds)
+	}
+}
+
+func TestStartWithPrimes(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	N := 2
+	partyIDs := test.PartyIDs(N)
+	keys := map[party.ID]*paillier.SecretKey{
+		partyIDs[0]: zk.ProverPaillierSecret,
+		partyIDs[1]: zk.VerifierPaillierSecret,
+	}
+	start := func(partyID party.ID, primes PrimeProvider) (round.Session, error) {
+		info := round.Info{
+			ProtocolID:        "cmp/keygen-test",
+			FinalRoundNumber:  Rounds,
+			SelfID:            partyID,
+			PartyIDs:          partyIDs,
+			Threshold:         N - 1,
+			Group:             group,
+			StatParam:         1,
+			InsecureStatParam: true,
+		}
+		return StartWithPrimes(info, pl, nil, primes)(nil)
+	}
+
 	rounds := make([]round.Session, 0, N)
 	for _, partyID := range partyIDs {
+		key := keys[partyID]
+		r, err := start(partyID, PrimeProviderFunc(func(context.Context) (*paillier.SecretKey, error) {
+			return key, nil
+		}))
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	for {
+		err, done := test.Rounds(rounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	checkOutput(t, rounds)
+	for _, r := range rounds {
+		c := r.(*round.Output).Result.(*config.Config)
+		assert.True(t, c.Paillier.PublicKey.Equal(keys[c.ID].PublicKey), "the key of the provider should be used")
+	}
+
+	// errors and invalid keys of the provider abort round 1
+	for _, primes := range []PrimeProvider{
+		PrimeProviderFunc(func(context.Context) (*paillier.SecretKey, error) { return nil, errors.New("unavailable") }),
+		PrimeProviderFunc(func(context.Context) (*paillier.SecretKey, error) { return nil, nil }),
+	} {
+		r, err := start(partyIDs[0], primes)
+		require.NoError(t, err)
+		_, err = r.(round.Round).Finalize(make(chan *round.Message, N))
+		assert.Error(t, err)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	N := 4
+	T := N - 1
+	configs, _ := test.GenerateConfig(group, N, T, mrand.New(mrand.NewSource(1)), pl)
+
+	rounds := make([]round.Session, 0, N)
+	for _, c := range configs {
+		c.Epoch = 1
 		info := round.Info{
-			ProtocolID:       "cmp/keygen-test",
+			ProtocolID:       "cmp/refresh-test",
 			FinalRoundNumber: Rounds,
-			SelfID:           partyID,
-			PartyIDs:         partyIDs,
+			SelfID:           c.ID,
+			PartyIDs:         c.PartyIDs(),
 			Threshold:        N - 1,
 			Group:            group,
+			Epoch:            c.Epoch,
 		}
-		r, err := Start(info, pl, nil)(nil)
+		r, err := Start(info, pl, c)(nil)
 		require.NoError(t, err, "round creation should not result in an error")
 		rounds = append(rounds, r)
+
 	}
 
 	for {
@@ -84,16 +193,300 @@ func TestKeygen(t *testing.T) {
 		}
 	}
 	checkOutput(t, rounds)
+	for _, r := range rounds {
+		assert.Equal(t, uint64(2), r.(*round.Output).Result.(*config.Config).Epoch, "a refresh starts the next epoch")
+	}
 }
 
-func TestRefresh(t *testing.T) {
+func TestShareComplaint(t *testing.T) {
+	sk := zk.VerifierPaillierSecret
+	pk := sk.PublicKey
+	expected := sample.Scalar(rand.Reader, group)
+
+	complain := func(share curve.Scalar) *ShareComplaint {
+		ct, _ := pk.Enc(curve.MakeInt(share))
+		plaintext, nonce, err := sk.DecWithRandomness(ct)
+		require.NoError(t, err)
+		return &ShareComplaint{
+			Accuser:   "a",
+			Accused:   "b",
+			Share:     ct,
+			Plaintext: plaintext,
+			Nonce:     nonce,
+			Expected:  expected.ActOnBase(),
+		}
+	}
+
+	// the accused sent a wrong share
+	c := complain(sample.Scalar(rand.Reader, group))
+	assert.NoError(t, c.Verify(group, pk))
+
+	// the accuser lies about a valid share
+	c = complain(expected)
+	assert.Error(t, c.Verify(group, pk))
+
+	// the accuser lies about the plaintext
+	c = complain(sample.Scalar(rand.Reader, group))
+	c.Plaintext = curve.MakeInt(expected)
+	assert.Error(t, c.Verify(group, pk))
+
+	c.Nonce = nil
+	assert.Error(t, c.Verify(group, pk))
+}
+
+// faultyShares makes From encrypt the share of To with a test.FaultyEncrypter in round 3.
+type faultyShares struct {
+	From, To party.ID
+	Fault    test.Fault
+	Key      *paillier.PublicKey
+}
+
+func (f *faultyShares) ModifyBefore(r round.Session) {
+	r3, ok := r.(*round3)
+	if !ok || r3.SelfID() != f.From {
+		return
+	}
+	f.Key = r3.PaillierPublic[f.To]
+	r3.Encrypters = map[party.ID]paillier.Encrypter{
+		f.To: &test.FaultyEncrypter{Key: f.Key, Fault: f.Fault},
+	}
+}
+func (*faultyShares) ModifyAfter(round.Session)                            {}
+func (*faultyShares) ModifyContent(round.Session, party.ID, round.Content) {}
+
+func TestFaultyShares(t *testing.T) {
 	pl := pool.NewPool(0)
 	defer pl.TearDown()
 
-	N := 4
-	T := N - 1
-	configs, _ := test.GenerateConfig(group, N, T, mrand.New(mrand.NewSource(1)), pl)
+	N, T := 3, 1
+	partyIDs := test.PartyIDs(N)
+
+	for _, fault := range []test.Fault{test.FaultBitFlip, test.FaultOutOfRange} {
+		rounds := make([]round.Session, 0, N)
+		for _, partyID := range partyIDs {
+			info := round.Info{
+				ProtocolID:       "cmp/keygen-test",
+				FinalRoundNumber: Rounds,
+				SelfID:           partyID,
+				PartyIDs:         partyIDs,
+				Threshold:        T,
+				Group:            group,
+			}
+			r, err := StartDryRun(info, pl)(nil)
+			require.NoError(t, err)
+			rounds = append(rounds, r)
+		}
+
+		rule := &faultyShares{From: partyIDs[0], To: partyIDs[1], Fault: fault}
+		var err error
+		for done := false; !done && err == nil; {
+			err, done = test.Rounds(rounds, rule)
+		}
+		var complaint *ShareComplaint
+		require.ErrorAs(t, err, &complaint, "fault %d", fault)
+		assert.Equal(t, partyIDs[0], complaint.Accused)
+		assert.Equal(t, partyIDs[1], complaint.Accuser)
+		assert.NoError(t, complaint.Verify(group, rule.Key), "the complaint against the faulty party is justified")
+	}
+}
+
+func TestRecoverConfig(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	N, T := 3, 1
+	partyIDs := test.PartyIDs(N)
+
+	rounds := make([]round.Session, 0, N)
+	for _, partyID := range partyIDs {
+		info := round.Info{
+			ProtocolID:       "cmp/keygen-test",
+			FinalRoundNumber: Rounds,
+			SelfID:           partyID,
+			PartyIDs:         partyIDs,
+			Threshold:        T,
+			Group:            group,
+		}
+		r, err := StartDryRun(info, pl)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	_, err := RecoverConfig(rounds[0])
+	assert.Error(t, err, "config is not known before round 5")
+
+	for rounds[0].Number() != Rounds {
+		err, _ := test.Rounds(rounds, nil)
+		require.NoError(t, err)
+	}
+	// the last party never receives the final messages
+	recovered, err := RecoverConfig(rounds[N-1])
+	require.NoError(t, err)
+
+	err, done := test.Rounds(rounds, nil)
+	require.NoError(t, err)
+	require.True(t, done)
+	others := make([]*config.PublicConfig, 0, N-1)
+	for _, r := range rounds[:N-1] {
+		c, err := RecoverConfig(r)
+		require.NoError(t, err)
+		others = append(others, c.PublicConfig())
+	}
+	assert.NoError(t, AuditConfig(recovered, others...))
+
+	other := *others[0]
+	other.RID = append([]byte{}, other.RID...)
+	other.RID[0] ^= 1
+	assert.Error(t, AuditConfig(recovered, &other), "config of another execution should be rejected")
+
+	// a share which is not on the polynomial of the others
+	inconsistent := *recovered
+	inconsistent.Public = make(map[party.ID]*config.Public, N)
+	for id, public := range recovered.Public {
+		p := *public
+		inconsistent.Public[id] = &p
+	}
+	inconsistent.Public[partyIDs[0]].ECDSA = group.NewBasePoint()
+	assert.Error(t, AuditConfig(&inconsistent))
+}
+
+// captureBroadcast3 records the round 3 broadcast messages of a keygen as a handler would receive them.
+type captureBroadcast3 struct {
+	mtx        sync.Mutex
+	transcript []*protocol.Message
+}
+
+func (*captureBroadcast3) ModifyBefore(round.Session) {}
+func (*captureBroadcast3) ModifyAfter(round.Session)  {}
+func (c *captureBroadcast3) ModifyContent(rNext round.Session, to party.ID, content round.Content) {
+	if _, ok := content.(*broadcast3); !ok || to != "" {
+		return
+	}
+	data, err := cbor.Marshal(content)
+	if err != nil {
+		panic(err)
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.transcript = append(c.transcript, &protocol.Message{
+		SSID:        rNext.SSID(),
+		From:        rNext.SelfID(),
+		Protocol:    rNext.ProtocolID(),
+		RoundNumber: 3,
+		Data:        data,
+		Broadcast:   true,
+	})
+}
+
+func TestRebuildPublic(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	N, T := 4, 1
+	partyIDs := test.PartyIDs(N)
 
+	rounds := make([]round.Session, 0, N)
+	for _, partyID := range partyIDs {
+		info := round.Info{
+			ProtocolID:       "cmp/keygen-test",
+			FinalRoundNumber: Rounds,
+			SelfID:           partyID,
+			PartyIDs:         partyIDs,
+			Threshold:        T,
+			Group:            group,
+		}
+		r, err := StartDryRun(info, pl)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	rule := &captureBroadcast3{}
+	for {
+		err, done := test.Rounds(rounds, rule)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	require.Len(t, rule.transcript, N)
+	c := rounds[0].(*round.Output).Result.(*config.Config)
+
+	damaged := *c
+	damaged.Public = make(map[party.ID]*config.Public, N)
+	for id, public := range c.Public {
+		damaged.Public[id] = public
+	}
+	delete(damaged.Public, partyIDs[1])
+	delete(damaged.Public, partyIDs[2])
+
+	rebuilt, err := RebuildPublic(&damaged, rule.transcript)
+	require.NoError(t, err)
+	assert.NoError(t, rebuilt.Compatible(c))
+	assert.Len(t, damaged.Public, N-2, "the damaged config should not be modified")
+
+	// the transcript must include all parties of the config
+	_, err = RebuildPublic(&damaged, rule.transcript[:N-1])
+	assert.Error(t, err)
+
+	// not enough shares remain to check the transcript
+	delete(damaged.Public, partyIDs[3])
+	_, err = RebuildPublic(&damaged, rule.transcript)
+	assert.Error(t, err)
+
+	// the transcript of another session is rejected
+	other := *c
+	other.Public = make(map[party.ID]*config.Public, N)
+	for id, public := range c.Public {
+		other.Public[id] = public
+	}
+	delete(other.Public, partyIDs[1])
+	other.ECDSA = group.NewScalar().Set(c.ECDSA).Add(group.NewScalar().SetNat(new(saferith.Nat).SetUint64(1)))
+	_, err = RebuildPublic(&other, rule.transcript)
+	assert.Error(t, err)
+}
+
+func TestDescribe(t *testing.T) {
+	d := Describe("cmp/keygen-test")
+	assert.Equal(t, Rounds, d.FinalRound)
+	require.Len(t, d.Rounds, int(Rounds))
+	assert.Equal(t, "keygen.broadcast2", d.Rounds[1].Broadcast)
+	assert.Empty(t, d.Rounds[1].Message)
+	assert.Equal(t, "keygen.message4", d.Rounds[3].Message)
+	assert.Equal(t, 2*(3-1), d.Rounds[3].Messages(3))
+}
+
+func TestDealer(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	N, T := 3, 1
+	partyIDs := test.PartyIDs(N)
+	secret := sample.Scalar(rand.Reader, group)
+	configs, err := Dealer(secret, partyIDs, T, pl)
+	require.NoError(t, err)
+	require.Len(t, configs, N)
+	for _, c := range configs {
+		assert.True(t, c.PublicPoint().Equal(secret.ActOnBase()))
+		assert.NoError(t, c.ValidateSigners(partyIDs))
+		assert.NoError(t, c.Compatible(configs[partyIDs[0]]))
+		assert.True(t, IsDealer(c))
+	}
+
+	// each config has its own public data
+	c0, c1 := configs[partyIDs[0]], configs[partyIDs[1]]
+	delete(c0.Public, partyIDs[2])
+	assert.Len(t, c1.Public, N)
+	c0.Public[partyIDs[2]] = c1.Public[partyIDs[2]]
+
+	// the mark survives serialization, and is bound to the fingerprint
+	data, err := c0.MarshalBinary()
+	require.NoError(t, err)
+	decoded := config.EmptyConfig(group)
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.True(t, IsDealer(decoded))
+	assert.Equal(t, c0.Fingerprint(), decoded.Fingerprint())
+	decoded.Dealt = false
+	assert.NotEqual(t, c0.Fingerprint(), decoded.Fingerprint())
+
+	// the configs can be refreshed, which removes the mark
 	rounds := make([]round.Session, 0, N)
 	for _, c := range configs {
 		info := round.Info{
@@ -101,21 +494,235 @@ func TestRefresh(t *testing.T) {
 			FinalRoundNumber: Rounds,
 			SelfID:           c.ID,
 			PartyIDs:         c.PartyIDs(),
-			Threshold:        N - 1,
+			Threshold:        T,
 			Group:            group,
 		}
 		r, err := Start(info, pl, c)(nil)
-		require.NoError(t, err, "round creation should not result in an error")
+		require.NoError(t, err)
 		rounds = append(rounds, r)
+	}
+	for {
+		err, done := test.Rounds(rounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	checkOutput(t, rounds)
+	refreshed := rounds[0].(*round.Output).Result.(*config.Config)
+	assert.True(t, refreshed.PublicPoint().Equal(secret.ActOnBase()))
+	assert.False(t, IsDealer(refreshed))
+
+	generated, _ := test.GenerateConfig(group, N, T, mrand.New(mrand.NewSource(1)), pl)
+	assert.False(t, IsDealer(generated[partyIDs[0]]))
+
+	_, err = Dealer(group.NewScalar(), partyIDs, T, pl)
+	assert.Error(t, err, "zero secret")
+	_, err = Dealer(secret, partyIDs, N, pl)
+	assert.Error(t, err, "threshold too large")
+	_, err = Dealer(secret, append(partyIDs, partyIDs[0]), T, pl)
+	assert.Error(t, err, "duplicate parties")
+}
+
+func TestKeygenWeighted(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
 
+	N, T := 3, 2
+	partyIDs := test.PartyIDs(N)
+	weights := party.Weights{partyIDs[0]: 3}
+
+	rounds := make([]round.Session, 0, N)
+	for _, partyID := range partyIDs {
+		info := round.Info{
+			ProtocolID:       "cmp/keygen-test",
+			FinalRoundNumber: Rounds,
+			SelfID:           partyID,
+			PartyIDs:         partyIDs,
+			Threshold:        T,
+			Group:            group,
+			Weights:          weights,
+		}
+		r, err := StartDryRun(info, pl)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
 	}
+	for {
+		err, done := test.Rounds(rounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	checkOutput(t, rounds)
+
+	configs := make(map[party.ID]*config.Config, N)
+	for _, r := range rounds {
+		c := r.(*round.Output).Result.(*config.Config)
+		configs[c.ID] = c
+		assert.NoError(t, c.Validate())
+		assert.Equal(t, weights, c.Weights())
+		assert.Len(t, c.Shares, weights.Of(c.ID)-1)
+	}
+
+	// the weighted party can sign with any other party, but the others cannot sign without it
+	// (a dry run shares its Paillier key between all parties, so CanSign rejects all signers)
+	c := configs[partyIDs[0]]
+	signers := party.NewIDSlice(partyIDs[:2])
+	assert.True(t, config.ValidThreshold(T, c.Weights().Total(signers)))
+	assert.False(t, config.ValidThreshold(T, c.Weights().Total(partyIDs[1:])))
+
+	secret := group.NewScalar()
+	for _, j := range signers {
+		secret.Add(configs[j].AdditiveShare(signers))
+	}
+	assert.True(t, secret.ActOnBase().Equal(c.PublicPoint()))
+
+	// weighted configs cannot be refreshed
+	info := round.Info{
+		ProtocolID:       "cmp/refresh-test",
+		FinalRoundNumber: Rounds,
+		SelfID:           c.ID,
+		PartyIDs:         partyIDs,
+		Threshold:        T,
+		Group:            group,
+		Weights:          weights,
+	}
+	_, err := Start(info, pl, c)(nil)
+	assert.Error(t, err)
+}
+
+func TestDealerWeighted(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	N, T := 4, 3
+	partyIDs := test.PartyIDs(N)
+	weights := party.Weights{partyIDs[0]: 2, partyIDs[1]: 2}
+	secret := sample.Scalar(rand.Reader, group)
+	configs, err := DealerWeighted(secret, partyIDs, weights, T, pl)
+	require.NoError(t, err)
+	for _, c := range configs {
+		assert.NoError(t, c.Validate())
+		assert.True(t, c.PublicPoint().Equal(secret.ActOnBase()))
+		assert.Equal(t, weights, c.Weights())
+	}
+
+	signers := party.NewIDSlice(partyIDs[:2])
+	require.NoError(t, configs[partyIDs[0]].ValidateSigners(signers))
+	sum := group.NewScalar()
+	for _, j := range signers {
+		sum.Add(configs[j].AdditiveShare(signers))
+	}
+	assert.True(t, sum.Equal(secret))
+	assert.Error(t, configs[partyIDs[0]].ValidateSigners(party.NewIDSlice(partyIDs[2:])))
+
+	_, err = DealerWeighted(secret, partyIDs, weights, 6, pl)
+	assert.Error(t, err, "threshold too large for total weight")
+	_, err = DealerWeighted(secret, partyIDs, party.Weights{partyIDs[0]: 0}, T, pl)
+	assert.Error(t, err, "invalid weight")
+}
+
+func TestJoin(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
 
+	N, T := 4, 1
+	partyIDs := test.PartyIDs(N)
+	present, pending := partyIDs[:N-1], partyIDs[N-1:]
+
+	// the keygen completes without the last invited party
+	rounds := make([]round.Session, 0, N-1)
+	for _, partyID := range present {
+		info := round.Info{
+			ProtocolID:       "cmp/keygen-test",
+			FinalRoundNumber: Rounds,
+			SelfID:           partyID,
+			PartyIDs:         present,
+			Threshold:        T,
+			Group:            group,
+			Pending:          pending,
+		}
+		r, err := StartDryRun(info, pl)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
 	for {
 		err, done := test.Rounds(rounds, nil)
-		require.NoError(t, err, "failed to process round")
+		require.NoError(t, err)
 		if done {
 			break
 		}
 	}
 	checkOutput(t, rounds)
+	configs := make(map[party.ID]*config.Config, N-1)
+	for _, r := range rounds {
+		c := r.(*round.Output).Result.(*config.Config)
+		assert.Equal(t, []party.ID(pending), c.Pending)
+		assert.True(t, c.PublicConfig().IsPending(pending[0]))
+		configs[c.ID] = c
+	}
+	public := configs[present[0]].PublicConfig()
+
+	// it obtains a share later, without changing the key
+	joinInfo := func(id party.ID) round.Info {
+		return round.Info{
+			ProtocolID:        "cmp/join-test",
+			FinalRoundNumber:  Rounds,
+			SelfID:            id,
+			PartyIDs:          partyIDs,
+			StatParam:         dryRunStatParam,
+			InsecureStatParam: true,
+		}
+	}
+	rounds = rounds[:0]
+	for _, partyID := range present {
+		r, err := StartJoin(joinInfo(partyID), pl, configs[partyID])(nil)
+		require.NoError(t, err)
+		r.(*round1).DryRun = true
+		rounds = append(rounds, r)
+	}
+	r, err := StartJoinAsNewParty(joinInfo(pending[0]), pl, public)(nil)
+	require.NoError(t, err)
+	r.(*round1).DryRun = true
+	rounds = append(rounds, r)
+	for _, r := range rounds {
+		assert.Equal(t, rounds[0].SSID(), r.SSID())
+	}
+	for {
+		err, done := test.Rounds(rounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	checkOutput(t, rounds)
+	for _, r := range rounds {
+		c := r.(*round.Output).Result.(*config.Config)
+		assert.NoError(t, c.Validate())
+		assert.Len(t, c.Public, N)
+		assert.Empty(t, c.Pending)
+		assert.Equal(t, uint64(1), c.Epoch)
+		assert.True(t, c.PublicPoint().Equal(public.PublicPoint()))
+		assert.Equal(t, public.ChainKey, c.ChainKey)
+	}
+
+	// the joining party cannot be given different public data
+	tampered := *public
+	tampered.ChainKey = public.ChainKey.Copy()
+	tampered.ChainKey[0] ^= 1
+	r, err = StartJoinAsNewParty(joinInfo(pending[0]), pl, &tampered)(nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, rounds[0].SSID(), r.SSID())
+
+	// only pending parties can join, one at a time
+	_, err = StartJoinAsNewParty(round.Info{
+		ProtocolID:       "cmp/join-test",
+		FinalRoundNumber: Rounds,
+		SelfID:           "z",
+		PartyIDs:         append(present, "z"),
+	}, pl, public)(nil)
+	assert.Error(t, err)
+	_, err = StartJoin(joinInfo(present[0]), pl, configs[present[1]])(nil)
+	assert.Error(t, err)
 }
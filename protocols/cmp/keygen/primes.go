@@ -0,0 +1,127 @@
+package keygen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
+)
+
+// PrimeProvider supplies the Paillier key of this party in round 1 of a keygen or refresh, instead of sampling it
+// when the round is finalized, which is by far the slowest step of the protocol. This lets hosts generate keys
+// ahead of time, in a background service, or in an HSM.
+//
+// GetPaillierKey must return a new key for every call: reusing a key across keygens or refreshes
+// defeats the purpose of refreshing it. The returned key is validated before it is used.
+// Round 1 blocks until GetPaillierKey returns, with a context which is never canceled,
+// so providers which may wait indefinitely should apply their own timeout.
+type PrimeProvider interface {
+	GetPaillierKey(ctx context.Context) (*paillier.SecretKey, error)
+}
+
+// PrimeProviderFunc adapts a function to the PrimeProvider interface.
+type PrimeProviderFunc func(ctx context.Context) (*paillier.SecretKey, error)
+
+// GetPaillierKey implements PrimeProvider.
+func (f PrimeProviderFunc) GetPaillierKey(ctx context.Context) (*paillier.SecretKey, error) {
+	return f(ctx)
+}
+
+// StartWithPrimes is the same as Start, but obtains the Paillier key of this party from primes.
+// If primes is nil, it is equivalent to Start.
+func StartWithPrimes(info round.Info, pl *pool.Pool, c *config.Config, primes PrimeProvider) protocol.StartFunc {
+	start := Start(info, pl, c)
+	if primes == nil {
+		return start
+	}
+	return func(sessionID []byte) (round.Session, error) {
+		session, err := start(sessionID)
+		if err != nil {
+			return nil, err
+		}
+		session.(*round1).Primes = primes
+		return session, nil
+	}
+}
+
+// paillierKey returns the Paillier key of this party, from r.Primes if it is set.
+func (r *round1) paillierKey() (*paillier.SecretKey, error) {
+	if r.DryRun {
+		return r.dryRunKey()
+	}
+	if r.Primes == nil {
+		return paillier.NewSecretKey(nil), nil
+	}
+	sk, err := r.Primes.GetPaillierKey(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain Paillier key: %w", err)
+	}
+	if sk == nil {
+		return nil, errors.New("failed to obtain Paillier key: provider returned nil")
+	}
+	if err = paillier.ValidatePrime(sk.P()); err != nil {
+		return nil, fmt.Errorf("invalid Paillier key: %w", err)
+	}
+	if err = paillier.ValidatePrime(sk.Q()); err != nil {
+		return nil, fmt.Errorf("invalid Paillier key: %w", err)
+	}
+	return sk, nil
+}
+
+// PrimePool is a PrimeProvider which generates Paillier keys in the background, and keeps up to a given number
+// of them ready, so that a keygen or refresh does not wait for them if enough time passed since the previous one.
+type PrimePool struct {
+	keys chan *paillier.SecretKey
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewPrimePool starts generating Paillier keys with pl, until size keys are ready or Close is called.
+func NewPrimePool(size int, pl *pool.Pool) *PrimePool {
+	if size < 1 {
+		size = 1
+	}
+	p := &PrimePool{
+		keys: make(chan *paillier.SecretKey, size),
+		stop: make(chan struct{}),
+	}
+	go func() {
+		for {
+			sk := paillier.NewSecretKey(pl)
+			select {
+			case p.keys <- sk:
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// GetPaillierKey implements PrimeProvider. It waits for a key to be ready, or for ctx to be done.
+func (p *PrimePool) GetPaillierKey(ctx context.Context) (*paillier.SecretKey, error) {
+	select {
+	case sk := <-p.keys:
+		return sk, nil
+	case <-p.stop:
+		return nil, errors.New("keygen: prime pool is closed")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Ready returns the number of keys which can be obtained without waiting.
+func (p *PrimePool) Ready() int {
+	return len(p.keys)
+}
+
+// Close stops the generation of keys. A key being generated is discarded once it is ready.
+func (p *PrimePool) Close() {
+	p.once.Do(func() { close(p.stop) })
+}
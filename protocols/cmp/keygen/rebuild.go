@@ -0,0 +1,149 @@
+package keygen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/arith"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/polynomial"
+	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	zksch "github.com/taurusgroup/multi-party-sig/pkg/zk/sch"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
+)
+
+// RebuildPublic returns a copy of c in which the public data of the parties missing from c.Public,
+// for example after the storage of the config was damaged, is recomputed from the transcript of the keygen
+// which produced c. Since only public data is affected, this avoids a new keygen or a resharing.
+//
+// transcript must contain the round 3 broadcast message of every party of the keygen, as sent and received
+// by this party, for example from the Mailbox exported by a handler created with HandlerOptions.KeepAllRounds.
+// The ECDSA share of party j is Σₖ Fₖ(j), where Fₖ is the VSS exponent polynomial broadcast by party k,
+// and its other public data is taken from its broadcast.
+//
+// The transcript is only used if it is consistent with c: the shares computed from it must be equal to
+// the Threshold+1 or more public shares remaining in c and to the secret share of this party,
+// and the remaining public data must be equal to the broadcast of each party.
+// Transcripts of a refresh are rejected, since the new shares also depend on the previous config.
+// The rebuilt config should still be checked with AuditConfig against the public configs of the other parties.
+func RebuildPublic(c *config.Config, transcript []*protocol.Message) (*config.Config, error) {
+	if c == nil || c.Group == nil || c.ECDSA == nil {
+		return nil, errors.New("keygen: config is missing fields")
+	}
+	if c.Weights() != nil {
+		return nil, errors.New("keygen: cannot rebuild the public data of a weighted config")
+	}
+	group := c.Group
+
+	broadcasts := make(map[party.ID]*broadcast3, len(transcript))
+	for _, msg := range transcript {
+		if msg == nil || !msg.Broadcast || msg.RoundNumber != 3 {
+			return nil, errors.New("keygen: transcript contains a message which is not a round 3 broadcast")
+		}
+		first := transcript[0]
+		if msg.Protocol != first.Protocol || !bytes.Equal(msg.SSID, first.SSID) {
+			return nil, errors.New("keygen: transcript contains messages of different sessions")
+		}
+		if _, ok := broadcasts[msg.From]; ok {
+			return nil, fmt.Errorf("keygen: transcript contains duplicate messages from %s", msg.From)
+		}
+		body := &broadcast3{
+			VSSPolynomial:      polynomial.EmptyExponent(group),
+			SchnorrCommitments: zksch.EmptyCommitment(group),
+			ElGamalPublic:      group.NewPoint(),
+		}
+		if err := round.UnmarshalContent(msg.Data, body); err != nil {
+			return nil, fmt.Errorf("keygen: transcript message from %s: %w", msg.From, err)
+		}
+		if body.VSSPolynomial.IsConstant {
+			return nil, errors.New("keygen: cannot rebuild public data from the transcript of a refresh")
+		}
+		if body.VSSPolynomial.Degree() != c.Threshold {
+			return nil, fmt.Errorf("keygen: vss polynomial of %s has incorrect degree", msg.From)
+		}
+		if err := paillier.ValidateN(body.N); err != nil {
+			return nil, fmt.Errorf("keygen: transcript message from %s: %w", msg.From, err)
+		}
+		if err := pedersen.ValidateParameters(body.N, body.S, body.T); err != nil {
+			return nil, fmt.Errorf("keygen: transcript message from %s: %w", msg.From, err)
+		}
+		broadcasts[msg.From] = body
+	}
+
+	if _, ok := broadcasts[c.ID]; !ok {
+		return nil, fmt.Errorf("keygen: transcript has no message from %s", c.ID)
+	}
+	if len(c.Public) < c.Threshold+1 {
+		return nil, fmt.Errorf("keygen: at least %d public shares are needed to check the transcript, config has %d",
+			c.Threshold+1, len(c.Public))
+	}
+
+	polynomials := make([]*polynomial.Exponent, 0, len(broadcasts))
+	for _, body := range broadcasts {
+		polynomials = append(polynomials, body.VSSPolynomial)
+	}
+	// F(X) = Σₖ Fₖ(X)
+	vssSum, err := polynomial.Sum(polynomials)
+	if err != nil {
+		return nil, fmt.Errorf("keygen: %w", err)
+	}
+	if !vssSum.Evaluate(c.ID.Scalar(group)).Equal(c.ECDSA.ActOnBase()) {
+		return nil, errors.New("keygen: transcript does not match the secret share of this party")
+	}
+
+	rebuilt := *c
+	rebuilt.Public = make(map[party.ID]*config.Public, len(broadcasts))
+	for j, body := range broadcasts {
+		public := &config.Public{
+			ECDSA:    vssSum.Evaluate(j.Scalar(group)),
+			ElGamal:  body.ElGamalPublic,
+			Paillier: paillier.NewPublicKey(body.N),
+			Pedersen: pedersen.New(arith.ModulusFromN(body.N), body.S, body.T),
+		}
+		if existing, ok := c.Public[j]; ok {
+			if err = checkPublic(j, existing, public); err != nil {
+				return nil, err
+			}
+			public = existing
+		}
+		rebuilt.Public[j] = public
+	}
+	for j := range c.Public {
+		if _, ok := broadcasts[j]; !ok {
+			return nil, fmt.Errorf("keygen: transcript has no message from %s", j)
+		}
+	}
+
+	if err = rebuilt.Validate(); err != nil {
+		return nil, fmt.Errorf("keygen: rebuilt config is invalid: %w", err)
+	}
+	if err = checkShares(rebuilt.PublicConfig()); err != nil {
+		return nil, err
+	}
+	return &rebuilt, nil
+}
+
+// checkPublic returns an error if the public data of party j stored in a config differs from the one
+// computed from a keygen transcript.
+func checkPublic(j party.ID, stored, computed *config.Public) error {
+	if stored == nil || stored.ECDSA == nil || stored.ElGamal == nil || stored.Paillier == nil || stored.Pedersen == nil {
+		return fmt.Errorf("keygen: public data of %s is incomplete", j)
+	}
+	if !stored.ECDSA.Equal(computed.ECDSA) {
+		return fmt.Errorf("keygen: transcript does not match the ECDSA share of %s", j)
+	}
+	if !stored.ElGamal.Equal(computed.ElGamal) {
+		return fmt.Errorf("keygen: transcript does not match the ElGamal key of %s", j)
+	}
+	if !stored.Paillier.Equal(computed.Paillier) {
+		return fmt.Errorf("keygen: transcript does not match the Paillier key of %s", j)
+	}
+	if stored.Pedersen.S().Eq(computed.Pedersen.S()) != 1 || stored.Pedersen.T().Eq(computed.Pedersen.T()) != 1 {
+		return fmt.Errorf("keygen: transcript does not match the Pedersen parameters of %s", j)
+	}
+	return nil
+}
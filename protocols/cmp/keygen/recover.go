@@ -0,0 +1,112 @@
+package keygen
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/polynomial"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
+)
+
+// RecoverConfig returns the config computed by a keygen or refresh session which reached round 5,
+// for example when the handler of a lagging party aborted because the round 5 message of another party was lost.
+//
+// All the public data of the config is verified before round 5, in which each party only proves knowledge
+// of its new share. Since the proofs of the missing messages were not verified, the recovered config
+// should be checked with AuditConfig against the public configs of the parties which completed the protocol.
+func RecoverConfig(r round.Session) (*config.Config, error) {
+	var c *config.Config
+	switch r := r.(type) {
+	case *round5:
+		c = r.UpdatedConfig
+	case *round.Output:
+		c, _ = r.Result.(*config.Config)
+	}
+	if c == nil {
+		if r == nil {
+			return nil, errors.New("keygen: cannot recover config from nil session")
+		}
+		return nil, fmt.Errorf("keygen: cannot recover config from round %d of %s", r.Number(), r.ProtocolID())
+	}
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("keygen: recovered config is invalid: %w", err)
+	}
+	return c, nil
+}
+
+// AuditConfig checks that c is consistent, and that it was produced by the same execution as the public configs
+// of the other parties, as returned by config.Config.PublicConfig.
+//
+// In addition to config.Config.Validate, it checks that the public shares of all parties
+// lie on a polynomial of degree c.Threshold, so that any set of Threshold+1 parties can sign for the same public key.
+func AuditConfig(c *config.Config, others ...*config.PublicConfig) error {
+	if c == nil {
+		return errors.New("keygen: config is nil")
+	}
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("keygen: %w", err)
+	}
+	if err := checkShares(c.PublicConfig()); err != nil {
+		return err
+	}
+	for i, other := range others {
+		if other == nil {
+			return fmt.Errorf("keygen: public config %d is nil", i)
+		}
+		otherConfig := &config.Config{
+			Group:     other.Group,
+			Threshold: other.Threshold,
+			RID:       other.RID,
+			ChainKey:  other.ChainKey,
+			Public:    other.Public,
+		}
+		if err := c.Compatible(otherConfig); err != nil {
+			return fmt.Errorf("keygen: public config %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// checkShares returns an error if the public shares of c do not lie on a polynomial of degree c.Threshold.
+// The shares of weighted parties are checked at each of their evaluation points.
+//
+// All sets of Threshold+1 shares on such a polynomial interpolate to the same public key,
+// so it is enough to replace a single party of the first set by each of the others.
+func checkShares(c *config.PublicConfig) error {
+	shares := evaluationShares(c)
+	ids := c.Weights().EvaluationPoints(c.PartyIDs())
+	base := ids[:c.Threshold+1]
+	expected := interpolate(c.Group, shares, base)
+	for _, j := range ids[c.Threshold+1:] {
+		subset := append(party.IDSlice{j}, base[1:]...)
+		if !interpolate(c.Group, shares, subset).Equal(expected) {
+			return fmt.Errorf("keygen: public share of party %s is inconsistent with the threshold", j)
+		}
+	}
+	return nil
+}
+
+// evaluationShares returns the public shares of c indexed by their evaluation point, see party.ID.WeightedIDs.
+func evaluationShares(c *config.PublicConfig) map[party.ID]curve.Point {
+	shares := make(map[party.ID]curve.Point, len(c.Public))
+	for j, public := range c.Public {
+		ids := j.WeightedIDs(1 + len(public.Shares))
+		shares[j] = public.ECDSA
+		for k, share := range public.Shares {
+			shares[ids[k+1]] = share
+		}
+	}
+	return shares
+}
+
+// interpolate returns the constant of the polynomial defined by the given shares at the evaluation points ids.
+func interpolate(group curve.Curve, shares map[party.ID]curve.Point, ids []party.ID) curve.Point {
+	sum := group.NewPoint()
+	for j, l := range polynomial.Lagrange(group, ids) {
+		sum = sum.Add(l.Act(shares[j]))
+	}
+	return sum
+}
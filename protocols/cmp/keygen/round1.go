@@ -1,7 +1,6 @@
 package keygen
 
 import (
-	"crypto/rand"
 	"errors"
 
 	"github.com/taurusgroup/multi-party-sig/internal/round"
@@ -13,6 +12,7 @@ import (
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
 	"github.com/taurusgroup/multi-party-sig/pkg/party"
 	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 	zksch "github.com/taurusgroup/multi-party-sig/pkg/zk/sch"
 )
 
@@ -41,7 +41,23 @@ type round1 struct {
 	// Polynomial from which the new secret shares are computed.
 	// Keygen:  fᵢ(0) = xⁱ
 	// Refresh: fᵢ(0) = 0
+	// Join:    fᵢ(0) = λᵢ•x'ᵢ, or 0 for the joining party
 	VSSSecret *polynomial.Polynomial
+
+	// ResharingConstants[j] = λⱼ•X'ⱼ is the expected constant Fⱼ(0) of the VSS polynomial of party j in a join,
+	// where X'ⱼ is its previous public share and λⱼ its Lagrange coefficient over the previous parties.
+	// The joining party is not in the map, and shares 0. It is nil for a keygen or refresh, see StartJoin.
+	ResharingConstants map[party.ID]curve.Point
+
+	// DryRun replaces the Paillier key with an insecure fixed one, see StartDryRun.
+	DryRun bool
+
+	// Primes supplies the Paillier key instead of sampling it, see StartWithPrimes.
+	Primes PrimeProvider
+
+	// Encrypters replaces the Paillier key of some parties when encrypting their share in round 3.
+	// It is only set in tests, to simulate a party which sends invalid shares.
+	Encrypters map[party.ID]paillier.Encrypter
 }
 
 // VerifyMessage implements round.Round.
@@ -52,7 +68,7 @@ func (r *round1) StoreMessage(round.Message) error { return nil }
 
 // Finalize implements round.Round
 //
-// - sample Paillier (pᵢ, qᵢ)
+// - sample Paillier (pᵢ, qᵢ), or obtain them from the PrimeProvider
 // - sample Pedersen Nᵢ, sᵢ, tᵢ
 // - sample aᵢ  <- 𝔽
 // - set Aᵢ = aᵢ⋅G
@@ -62,27 +78,34 @@ func (r *round1) StoreMessage(round.Message) error { return nil }
 // - commit to message.
 func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// generate Paillier and Pedersen
-	PaillierSecret := paillier.NewSecretKey(nil)
+	PaillierSecret, err := r.paillierKey()
+	if err != nil {
+		return r, err
+	}
 	SelfPaillierPublic := PaillierSecret.PublicKey
 	SelfPedersenPublic, PedersenSecret := PaillierSecret.GeneratePedersen()
 
-	ElGamalSecret, ElGamalPublic := sample.ScalarPointPair(rand.Reader, r.Group())
+	ElGamalSecret, ElGamalPublic := sample.ScalarPointPair(platform.Reader, r.Group())
 
 	// save our own share already so we are consistent with what we receive from others
 	SelfShare := r.VSSSecret.Evaluate(r.SelfID().Scalar(r.Group()))
+	SelfExtraShares := make([]curve.Scalar, 0, r.Weight(r.SelfID())-1)
+	for _, x := range r.extraPoints(r.SelfID()) {
+		SelfExtraShares = append(SelfExtraShares, r.VSSSecret.Evaluate(x))
+	}
 
 	// set Fᵢ(X) = fᵢ(X)•G
 	SelfVSSPolynomial := polynomial.NewPolynomialExponent(r.VSSSecret)
 
 	// generate Schnorr randomness
-	SchnorrRand := zksch.NewRandomness(rand.Reader, r.Group(), nil)
+	SchnorrRand := zksch.NewRandomness(platform.Reader, r.Group(), nil)
 
 	// Sample RIDᵢ
-	SelfRID, err := types.NewRID(rand.Reader)
+	SelfRID, err := types.NewRIDForProfile(platform.Reader, r.SecurityProfile())
 	if err != nil {
 		return r, errors.New("failed to sample Rho")
 	}
-	chainKey, err := types.NewRID(rand.Reader)
+	chainKey, err := types.NewRID(platform.Reader)
 	if err != nil {
 		return r, errors.New("failed to sample c")
 	}
@@ -103,24 +126,38 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	}
 
 	nextRound := &round2{
-		round1:         r,
-		VSSPolynomials: map[party.ID]*polynomial.Exponent{r.SelfID(): SelfVSSPolynomial},
-		Commitments:    map[party.ID]hash.Commitment{r.SelfID(): SelfCommitment},
-		RIDs:           map[party.ID]types.RID{r.SelfID(): SelfRID},
-		ChainKeys:      map[party.ID]types.RID{r.SelfID(): chainKey},
-		ShareReceived:  map[party.ID]curve.Scalar{r.SelfID(): SelfShare},
-		ElGamalPublic:  map[party.ID]curve.Point{r.SelfID(): ElGamalPublic},
-		PaillierPublic: map[party.ID]*paillier.PublicKey{r.SelfID(): SelfPaillierPublic},
-		Pedersen:       map[party.ID]*pedersen.Parameters{r.SelfID(): SelfPedersenPublic},
-		ElGamalSecret:  ElGamalSecret,
-		PaillierSecret: PaillierSecret,
-		PedersenSecret: PedersenSecret,
-		SchnorrRand:    SchnorrRand,
-		Decommitment:   Decommitment,
+		round1:                    r,
+		VSSPolynomial:             SelfVSSPolynomial,
+		VSSSum:                    SelfVSSPolynomial,
+		ExpectedPublicShares:      map[party.ID]curve.Point{},
+		ExpectedExtraPublicShares: map[party.ID][]curve.Point{},
+		Commitments:               map[party.ID]hash.Commitment{r.SelfID(): SelfCommitment},
+		RIDs:                      map[party.ID]types.RID{r.SelfID(): SelfRID},
+		ChainKeys:                 map[party.ID]types.RID{r.SelfID(): chainKey},
+		ShareReceived:             map[party.ID]curve.Scalar{r.SelfID(): SelfShare},
+		ExtraSharesReceived:       map[party.ID][]curve.Scalar{r.SelfID(): SelfExtraShares},
+		ElGamalPublic:             map[party.ID]curve.Point{r.SelfID(): ElGamalPublic},
+		PaillierPublic:            map[party.ID]*paillier.PublicKey{r.SelfID(): SelfPaillierPublic},
+		Pedersen:                  map[party.ID]*pedersen.Parameters{r.SelfID(): SelfPedersenPublic},
+		ElGamalSecret:             ElGamalSecret,
+		PaillierSecret:            PaillierSecret,
+		PedersenSecret:            PedersenSecret,
+		SchnorrRand:               SchnorrRand,
+		Decommitment:              Decommitment,
 	}
 	return nextRound, nil
 }
 
+// extraPoints returns the additional evaluation points of party j, if it has a weight larger than 1.
+func (r *round1) extraPoints(j party.ID) []curve.Scalar {
+	ids := j.WeightedIDs(r.Weight(j))[1:]
+	points := make([]curve.Scalar, 0, len(ids))
+	for _, id := range ids {
+		points = append(points, id.Scalar(r.Group()))
+	}
+	return points
+}
+
 // PreviousRound implements round.Round.
 func (round1) PreviousRound() round.Round { return nil }
 
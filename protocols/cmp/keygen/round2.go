@@ -18,8 +18,16 @@ var _ round.Round = (*round2)(nil)
 type round2 struct {
 	*round1
 
-	// VSSPolynomials[j] = Fⱼ(X) = fⱼ(X)•G
-	VSSPolynomials map[party.ID]*polynomial.Exponent
+	// VSSPolynomial = Fᵢ(X) = fᵢ(X)•G
+	VSSPolynomial *polynomial.Exponent
+	// VSSSum = ∑ⱼ Fⱼ(X), accumulated as the polynomials of other parties are received.
+	// Only the sum and the evaluations at our own index are kept, so that memory
+	// does not grow with n•t.
+	VSSSum *polynomial.Exponent
+	// ExpectedPublicShares[j] = Fⱼ(i)
+	ExpectedPublicShares map[party.ID]curve.Point
+	// ExpectedExtraPublicShares[j][k] = Fⱼ(iₖ), for the additional evaluation points iₖ of a weighted party
+	ExpectedExtraPublicShares map[party.ID][]curve.Point
 
 	// Commitments[j] = H(Keygen3ⱼ ∥ Decommitments[j])
 	Commitments map[party.ID]hash.Commitment
@@ -32,6 +40,8 @@ type round2 struct {
 	// ShareReceived[j] = xʲᵢ
 	// share received from party j
 	ShareReceived map[party.ID]curve.Scalar
+	// ExtraSharesReceived[j][k] = fⱼ(iₖ), for the additional evaluation points iₖ of a weighted party
+	ExtraSharesReceived map[party.ID][]curve.Scalar
 
 	ElGamalPublic map[party.ID]curve.Point
 	// PaillierPublic[j] = Nⱼ
@@ -70,11 +80,12 @@ func (r *round2) StoreBroadcastMessage(msg round.Message) error {
 	if !ok || body == nil {
 		return round.ErrInvalidContent
 	}
-	if err := body.Commitment.Validate(); err != nil {
+	if err := body.Commitment.ValidateProfile(r.SecurityProfile()); err != nil {
 		return err
 	}
 	r.Commitments[msg.From] = body.Commitment
-	return nil
+	// the broadcasts of every round are recorded in the CeremonyLog of the new config
+	return r.StoreBroadcast(msg)
 }
 
 // VerifyMessage implements round.Round.
@@ -91,7 +102,7 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 	err := r.BroadcastMessage(out, &broadcast3{
 		RID:                r.RIDs[r.SelfID()],
 		C:                  r.ChainKeys[r.SelfID()],
-		VSSPolynomial:      r.VSSPolynomials[r.SelfID()],
+		VSSPolynomial:      r.VSSPolynomial,
 		SchnorrCommitments: r.SchnorrRand.Commitment(),
 		ElGamalPublic:      r.ElGamalPublic[r.SelfID()],
 		N:                  r.Pedersen[r.SelfID()].N(),
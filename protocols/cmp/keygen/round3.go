@@ -11,9 +11,11 @@ import (
 	"github.com/taurusgroup/multi-party-sig/pkg/math/arith"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/polynomial"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
 	"github.com/taurusgroup/multi-party-sig/pkg/party"
 	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 	zkfac "github.com/taurusgroup/multi-party-sig/pkg/zk/fac"
 	zkmod "github.com/taurusgroup/multi-party-sig/pkg/zk/mod"
 	zkprm "github.com/taurusgroup/multi-party-sig/pkg/zk/prm"
@@ -39,6 +41,9 @@ type broadcast3 struct {
 	// SchnorrCommitments = Aᵢ Schnorr commitment for the final confirmation
 	SchnorrCommitments *zksch.Commitment
 	ElGamalPublic      curve.Point
+	// N, S and T are always sent in full rather than as a reference to the parameters of a previous session,
+	// since every keygen and refresh samples a new Paillier key: reusing it would defeat the purpose of the refresh.
+	//
 	// N Paillier and Pedersen N = p•q, p ≡ q ≡ 3 mod 4
 	N *saferith.Modulus
 	// S = r² mod N
@@ -55,11 +60,12 @@ type broadcast3 struct {
 // - verify degree of VSS polynomial Fⱼ "in-the-exponent"
 //   - if keygen, verify Fⱼ(0) != ∞
 //   - if refresh, verify Fⱼ(0) == ∞
+//   - if join, verify Fⱼ(0) == λⱼ•X'ⱼ, or ∞ for the joining party
 //
 // - validate Paillier
 // - validate Pedersen
 // - validate commitments.
-// - store ridⱼ, Cⱼ, Nⱼ, Sⱼ, Tⱼ, Fⱼ(X), Aⱼ.
+// - store ridⱼ, Cⱼ, Nⱼ, Sⱼ, Tⱼ, Fⱼ(i), Aⱼ, and add Fⱼ(X) to F(X).
 func (r *round3) StoreBroadcastMessage(msg round.Message) error {
 	from := msg.From
 	body, ok := msg.Content.(*broadcast3)
@@ -72,14 +78,14 @@ func (r *round3) StoreBroadcastMessage(msg round.Message) error {
 		return round.ErrNilFields
 	}
 	// check RID length
-	if err := body.RID.Validate(); err != nil {
+	if err := body.RID.ValidateProfile(r.SecurityProfile()); err != nil {
 		return fmt.Errorf("rid: %w", err)
 	}
 	if err := body.C.Validate(); err != nil {
 		return fmt.Errorf("chainkey: %w", err)
 	}
 	// check decommitment
-	if err := body.Decommitment.Validate(); err != nil {
+	if err := body.Decommitment.ValidateProfile(r.SecurityProfile()); err != nil {
 		return err
 	}
 
@@ -87,7 +93,12 @@ func (r *round3) StoreBroadcastMessage(msg round.Message) error {
 	VSSPolynomial := body.VSSPolynomial
 	// check that the constant coefficient is 0
 	// if refresh then the polynomial is constant
-	if !(r.VSSSecret.Constant().IsZero() == VSSPolynomial.IsConstant) {
+	if r.ResharingConstants != nil {
+		expected, ok := r.ResharingConstants[from]
+		if ok == VSSPolynomial.IsConstant || (ok && !VSSPolynomial.Constant().Equal(expected)) {
+			return errors.New("vss polynomial has incorrect constant")
+		}
+	} else if !(r.VSSSecret.Constant().IsZero() == VSSPolynomial.IsConstant) {
 		return errors.New("vss polynomial has incorrect constant")
 	}
 	// check deg(Fⱼ) = t
@@ -107,17 +118,39 @@ func (r *round3) StoreBroadcastMessage(msg round.Message) error {
 	// Verify decommit
 	if !r.HashForID(from).Decommit(r.Commitments[from], body.Decommitment,
 		body.RID, body.C, VSSPolynomial, body.SchnorrCommitments, body.ElGamalPublic, body.N, body.S, body.T) {
-		return errors.New("failed to decommit")
+		// recompute the commitment to report which field is likely responsible
+		err := r.HashForID(from).DecommitFields(r.Commitments[from], body.Decommitment,
+			hash.CommitField{Name: "RID", Value: body.RID},
+			hash.CommitField{Name: "chain key", Value: body.C},
+			hash.CommitField{Name: "VSS polynomial", Value: VSSPolynomial},
+			hash.CommitField{Name: "Schnorr commitment", Value: body.SchnorrCommitments},
+			hash.CommitField{Name: "ElGamal public key", Value: body.ElGamalPublic},
+			hash.CommitField{Name: "Paillier N", Value: body.N},
+			hash.CommitField{Name: "Pedersen S", Value: body.S},
+			hash.CommitField{Name: "Pedersen T", Value: body.T})
+		if err == nil {
+			err = errors.New("decommitment: inconsistent result")
+		}
+		return fmt.Errorf("failed to decommit: %w", err)
+	}
+	// F(X) += Fⱼ(X)
+	VSSSum, err := polynomial.Sum([]*polynomial.Exponent{r.VSSSum, VSSPolynomial})
+	if err != nil {
+		return err
 	}
 	r.RIDs[from] = body.RID
 	r.ChainKeys[from] = body.C
 	r.PaillierPublic[from] = paillier.NewPublicKey(body.N)
 	r.Pedersen[from] = pedersen.New(arith.ModulusFromN(body.N), body.S, body.T)
-	r.VSSPolynomials[from] = body.VSSPolynomial
+	r.ExpectedPublicShares[from] = VSSPolynomial.Evaluate(r.SelfID().Scalar(r.Group()))
+	for _, x := range r.extraPoints(r.SelfID()) {
+		r.ExpectedExtraPublicShares[from] = append(r.ExpectedExtraPublicShares[from], VSSPolynomial.Evaluate(x))
+	}
+	r.VSSSum = VSSSum
 	r.SchnorrCommitments[from] = body.SchnorrCommitments
 	r.ElGamalPublic[from] = body.ElGamalPublic
 
-	return nil
+	return r.StoreBroadcast(msg)
 }
 
 // VerifyMessage implements round.Round.
@@ -145,7 +178,7 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 		}
 	}
 	// RID = ⊕ⱼ RIDⱼ
-	rid := types.EmptyRID()
+	rid := types.EmptyRIDForProfile(r.SecurityProfile())
 	for _, j := range r.PartyIDs() {
 		rid.XOR(r.RIDs[j])
 	}
@@ -153,13 +186,14 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// temporary hash which does not modify the state
 	h := r.Hash()
 	_ = h.WriteAny(rid, r.SelfID())
+	proofContext := r.proofContext(r.SelfID())
 
 	// Prove N is a blum prime with zkmod
-	mod := zkmod.NewProof(h.Clone(), zkmod.Private{
+	mod := zkmod.NewProof(h.ForkLabel("zkmod"), zkmod.Private{
 		P:   r.PaillierSecret.P(),
 		Q:   r.PaillierSecret.Q(),
 		Phi: r.PaillierSecret.Phi(),
-	}, zkmod.Public{N: r.PaillierPublic[r.SelfID()].N()}, r.Pool)
+	}, zkmod.Public{N: r.PaillierPublic[r.SelfID()].N(), Iterations: r.StatParam(), Insecure: r.InsecureStatParam(), Context: proofContext}, r.Pool)
 
 	// prove s, t are correct as aux parameters with zkprm
 	prm := zkprm.NewProof(zkprm.Private{
@@ -167,11 +201,15 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 		Phi:    r.PaillierSecret.Phi(),
 		P:      r.PaillierSecret.P(),
 		Q:      r.PaillierSecret.Q(),
-	}, h.Clone(), zkprm.Public{Aux: r.Pedersen[r.SelfID()]}, r.Pool)
+	}, h.ForkLabel("zkprm"), zkprm.Public{Aux: r.Pedersen[r.SelfID()], Iterations: r.StatParam(), Insecure: r.InsecureStatParam(), Context: proofContext}, r.Pool)
+
+	// sample the nonce bᵢ of the key certificate, which is only used once the shares are known
+	certificateNonce, certificateCommitment := sample.ScalarPointPair(platform.Reader, r.Group())
 
 	if err := r.BroadcastMessage(out, &broadcast4{
-		Mod: mod,
-		Prm: prm,
+		Mod:                   mod,
+		Prm:                   prm,
+		CertificateCommitment: certificateCommitment,
 	}); err != nil {
 		return r, err
 	}
@@ -180,19 +218,27 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 	for _, j := range r.OtherPartyIDs() {
 
 		// Prove that the factors of N are relatively large
-		fac := zkfac.NewProof(zkfac.Private{P: r.PaillierSecret.P(), Q: r.PaillierSecret.Q()}, h.Clone(), zkfac.Public{
-			N:   r.PaillierPublic[r.SelfID()].N(),
-			Aux: r.Pedersen[j],
+		fac := zkfac.NewProof(zkfac.Private{P: r.PaillierSecret.P(), Q: r.PaillierSecret.Q()}, h.ForkLabel("zkfac:"+string(j)), zkfac.Public{
+			N:       r.PaillierPublic[r.SelfID()].N(),
+			Aux:     r.Pedersen[j],
+			Context: proofContext,
 		})
 
 		// compute fᵢ(j)
 		share := r.VSSSecret.Evaluate(j.Scalar(r.Group()))
 		// Encrypt share
-		C, _ := r.PaillierPublic[j].Enc(curve.MakeInt(share))
+		C, _ := r.encrypter(j).Enc(curve.MakeInt(share))
+		// encrypt the shares of the additional evaluation points of j
+		var extraShares []*paillier.Ciphertext
+		for _, x := range r.extraPoints(j) {
+			extra, _ := r.encrypter(j).Enc(curve.MakeInt(r.VSSSecret.Evaluate(x)))
+			extraShares = append(extraShares, extra)
+		}
 
 		err := r.SendMessage(out, &message4{
-			Share: C,
-			Fac:   fac,
+			Share:       C,
+			ExtraShares: extraShares,
+			Fac:         fac,
 		}, j)
 		if err != nil {
 			return r, err
@@ -202,12 +248,22 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// Write rid to the hash state
 	r.UpdateHashState(rid)
 	return &round4{
-		round3:   r,
-		RID:      rid,
-		ChainKey: chainKey,
+		round3:                 r,
+		RID:                    rid,
+		ChainKey:               chainKey,
+		CertificateNonce:       certificateNonce,
+		CertificateCommitments: map[party.ID]curve.Point{r.SelfID(): certificateCommitment},
 	}, nil
 }
 
+// encrypter returns the Encrypter for the share of j, which is its Paillier key unless it is replaced in r.Encrypters.
+func (r *round3) encrypter(j party.ID) paillier.Encrypter {
+	if e, ok := r.Encrypters[j]; ok {
+		return e
+	}
+	return r.PaillierPublic[j]
+}
+
 // MessageContent implements round.Round.
 func (round3) MessageContent() round.Content { return nil }
 
@@ -225,3 +281,9 @@ func (r *round3) BroadcastContent() round.BroadcastContent {
 
 // Number implements round.Round.
 func (round3) Number() round.Number { return 3 }
+
+// proofContext returns the context of the zkmod, zkprm and zkfac proofs created by party id in this session,
+// which is bound to their challenges with hash.ChallengeV3.
+func (r *round3) proofContext(id party.ID) *hash.ProofContext {
+	return &hash.ProofContext{SSID: r.SSID(), Prover: string(id)}
+}
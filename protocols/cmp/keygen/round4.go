@@ -6,7 +6,6 @@ import (
 	"github.com/taurusgroup/multi-party-sig/internal/round"
 	"github.com/taurusgroup/multi-party-sig/internal/types"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
-	"github.com/taurusgroup/multi-party-sig/pkg/math/polynomial"
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
 	"github.com/taurusgroup/multi-party-sig/pkg/party"
 	zkfac "github.com/taurusgroup/multi-party-sig/pkg/zk/fac"
@@ -25,23 +24,33 @@ type round4 struct {
 	RID types.RID
 	// ChainKey is a sequence of random bytes agreed upon together
 	ChainKey types.RID
+
+	// CertificateNonce = bᵢ
+	CertificateNonce curve.Scalar
+	// CertificateCommitments[j] = Bⱼ = bⱼ•G
+	CertificateCommitments map[party.ID]curve.Point
 }
 
 type message4 struct {
 	// Share = Encᵢ(x) is the encryption of the receivers share
 	Share *paillier.Ciphertext
-	Fac   *zkfac.Proof
+	// ExtraShares are the encryptions of the shares of the additional evaluation points of a weighted receiver.
+	ExtraShares []*paillier.Ciphertext `cbor:",omitempty"`
+	Fac         *zkfac.Proof
 }
 
 type broadcast4 struct {
 	round.NormalBroadcastContent
 	Mod *zkmod.Proof
 	Prm *zkprm.Proof
+	// CertificateCommitment = Bᵢ is the commitment of this party to the key certificate, see config.KeyCertificate.
+	CertificateCommitment curve.Point
 }
 
 // StoreBroadcastMessage implements round.BroadcastRound.
 //
 // - verify Mod, Prm proof for N
+// - save the key certificate commitment Bⱼ.
 func (r *round4) StoreBroadcastMessage(msg round.Message) error {
 	from := msg.From
 	body, ok := msg.Content.(*broadcast4)
@@ -49,17 +58,22 @@ func (r *round4) StoreBroadcastMessage(msg round.Message) error {
 		return round.ErrInvalidContent
 	}
 
+	if body.CertificateCommitment == nil || body.CertificateCommitment.IsIdentity() {
+		return round.ErrNilFields
+	}
+
 	// verify zkmod
-	if !body.Mod.Verify(zkmod.Public{N: r.Pedersen[from].N()}, r.HashForID(from), r.Pool) {
+	if !body.Mod.Verify(zkmod.Public{N: r.Pedersen[from].N(), Iterations: r.StatParam(), Insecure: r.InsecureStatParam(), Context: r.proofContext(from)}, r.HashForID(from).ForkLabel("zkmod"), r.Pool) {
 		return errors.New("failed to validate mod proof")
 	}
 
 	// verify zkprm
-	if !body.Prm.Verify(zkprm.Public{Aux: r.Pedersen[from]}, r.HashForID(from), r.Pool) {
+	if !body.Prm.Verify(zkprm.Public{Aux: r.Pedersen[from], Iterations: r.StatParam(), Insecure: r.InsecureStatParam(), Context: r.proofContext(from)}, r.HashForID(from).ForkLabel("zkprm"), r.Pool) {
 		return errors.New("failed to validate prm proof")
 	}
 
-	return nil
+	r.CertificateCommitments[from] = body.CertificateCommitment
+	return r.StoreBroadcast(msg)
 }
 
 // VerifyMessage implements round.Round.
@@ -75,9 +89,15 @@ func (r *round4) VerifyMessage(msg round.Message) error {
 	if !r.PaillierPublic[msg.To].ValidateCiphertexts(body.Share) {
 		return errors.New("invalid ciphertext")
 	}
+	if len(body.ExtraShares) != r.Weight(msg.To)-1 {
+		return errors.New("wrong number of additional shares")
+	}
+	if len(body.ExtraShares) > 0 && !r.PaillierPublic[msg.To].ValidateCiphertexts(body.ExtraShares...) {
+		return errors.New("invalid ciphertext")
+	}
 
 	// verify zkfac
-	if !body.Fac.Verify(zkfac.Public{N: r.PaillierPublic[from].N(), Aux: r.Pedersen[msg.To]}, r.HashForID(from)) {
+	if !body.Fac.Verify(zkfac.Public{N: r.PaillierPublic[from].N(), Aux: r.Pedersen[msg.To], Context: r.proofContext(from)}, r.HashForID(from).ForkLabel("zkfac:"+string(msg.To))) {
 		return errors.New("failed to validate fac proof")
 	}
 
@@ -89,32 +109,48 @@ func (r *round4) VerifyMessage(msg round.Message) error {
 // Since this message is only intended for us, we need to do the VSS verification here.
 // - check that the decrypted share did not overflow.
 // - check VSS condition.
+//   - if either check fails, return a ShareComplaint against the sender.
+// - do the same for the shares of our additional evaluation points, if weighted.
 // - save share.
 func (r *round4) StoreMessage(msg round.Message) error {
 	from, body := msg.From, msg.Content.(*message4)
 
-	// decrypt share
-	DecryptedShare, err := r.PaillierSecret.Dec(body.Share)
+	// X == Fⱼ(i)
+	Share, err := r.decryptShare(from, body.Share, r.ExpectedPublicShares[from])
 	if err != nil {
 		return err
 	}
-	Share := r.Group().NewScalar().SetNat(DecryptedShare.Mod(r.Group().Order()))
-	if DecryptedShare.Eq(curve.MakeInt(Share)) != 1 {
-		return errors.New("decrypted share is not in correct range")
-	}
-
-	// verify share with VSS
-	ExpectedPublicShare := r.VSSPolynomials[from].Evaluate(r.SelfID().Scalar(r.Group())) // Fⱼ(i)
-	PublicShare := Share.ActOnBase()
-	// X == Fⱼ(i)
-	if !PublicShare.Equal(ExpectedPublicShare) {
-		return errors.New("failed to validate VSS share")
+	ExtraShares := make([]curve.Scalar, 0, len(body.ExtraShares))
+	for k, ciphertext := range body.ExtraShares {
+		extra, err := r.decryptShare(from, ciphertext, r.ExpectedExtraPublicShares[from][k])
+		if err != nil {
+			return err
+		}
+		ExtraShares = append(ExtraShares, extra)
 	}
 
 	r.ShareReceived[from] = Share
+	r.ExtraSharesReceived[from] = ExtraShares
 	return nil
 }
 
+// decryptShare decrypts a share received from party j, and returns a ShareComplaint against j
+// if it overflows or does not match the expected public share given by the VSS polynomial of j.
+func (r *round4) decryptShare(from party.ID, ciphertext *paillier.Ciphertext, expected curve.Point) (curve.Scalar, error) {
+	DecryptedShare, err := r.PaillierSecret.Dec(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	Share := r.Group().NewScalar().SetNat(DecryptedShare.Mod(r.Group().Order()))
+	if DecryptedShare.Eq(curve.MakeInt(Share)) != 1 {
+		return nil, r.newShareComplaint(from, ciphertext, expected)
+	}
+	if !Share.ActOnBase().Equal(expected) {
+		return nil, r.newShareComplaint(from, ciphertext, expected)
+	}
+	return Share, nil
+}
+
 // Finalize implements round.Round
 //
 // - sum of all received shares
@@ -122,7 +158,8 @@ func (r *round4) StoreMessage(msg round.Message) error {
 // - recompute config SSID
 // - validate Config
 // - write new ssid hash to old hash state
-// - create proof of knowledge of secret.
+// - create proof of knowledge of secret
+// - create the response zᵢ = bᵢ + e•λᵢ•xᵢ of the key certificate.
 func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// add all shares to our secret
 	UpdatedSecretECDSA := r.Group().NewScalar()
@@ -132,18 +169,17 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 	for _, j := range r.PartyIDs() {
 		UpdatedSecretECDSA.Add(r.ShareReceived[j])
 	}
-
-	// [F₁(X), …, Fₙ(X)]
-	ShamirPublicPolynomials := make([]*polynomial.Exponent, 0, len(r.VSSPolynomials))
-	for _, VSSPolynomial := range r.VSSPolynomials {
-		ShamirPublicPolynomials = append(ShamirPublicPolynomials, VSSPolynomial)
+	var UpdatedExtraShares []curve.Scalar
+	for k := 0; k < r.Weight(r.SelfID())-1; k++ {
+		share := r.Group().NewScalar()
+		for _, j := range r.PartyIDs() {
+			share.Add(r.ExtraSharesReceived[j][k])
+		}
+		UpdatedExtraShares = append(UpdatedExtraShares, share)
 	}
 
 	// ShamirPublicPolynomial = F(X) = ∑Fⱼ(X)
-	ShamirPublicPolynomial, err := polynomial.Sum(ShamirPublicPolynomials)
-	if err != nil {
-		return r, err
-	}
+	ShamirPublicPolynomial := r.VSSSum
 
 	// compute the new public key share Xⱼ = F(j) (+X'ⱼ if doing a refresh)
 	PublicData := make(map[party.ID]*config.Public, len(r.PartyIDs()))
@@ -158,19 +194,38 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 			Paillier: r.PaillierPublic[j],
 			Pedersen: r.Pedersen[j],
 		}
+		for _, x := range r.extraPoints(j) {
+			PublicData[j].Shares = append(PublicData[j].Shares, ShamirPublicPolynomial.Evaluate(x))
+		}
+	}
+	// the shares are hashed with the config below and whenever it is used, so they are made affine at once
+	PublicECDSAShares := make([]curve.Point, 0, len(PublicData))
+	for _, public := range PublicData {
+		PublicECDSAShares = append(PublicECDSAShares, public.ECDSA)
+		PublicECDSAShares = append(PublicECDSAShares, public.Shares...)
 	}
+	curve.NormalizePoints(PublicECDSAShares...)
 
 	UpdatedConfig := &config.Config{
 		Group:     r.Group(),
 		ID:        r.SelfID(),
 		Threshold: r.Threshold(),
 		ECDSA:     UpdatedSecretECDSA,
+		Shares:    UpdatedExtraShares,
 		ElGamal:   r.ElGamalSecret,
 		Paillier:  r.PaillierSecret,
 		RID:       r.RID.Copy(),
 		ChainKey:  r.ChainKey.Copy(),
 		Public:    PublicData,
+		Pending:   r.Pending(),
 	}
+	// a refresh or join runs in the epoch of the previous config, and starts the next one
+	if r.PreviousSecretECDSA != nil || r.ResharingConstants != nil {
+		UpdatedConfig.Epoch = r.Epoch() + 1
+	}
+	// the broadcasts of rounds 2 to 4 are known, and are bound to the Schnorr proof below with the config,
+	// the ones of round 5 are added by round5.Finalize
+	UpdatedConfig.RecordBroadcastHashes(r.BroadcastDigests())
 
 	// write new ssid to hash, to bind the Schnorr proof to this new config
 	// Write SSID, selfID to temporary hash
@@ -179,16 +234,36 @@ func (r *round4) Finalize(out chan<- *round.Message) (round.Session, error) {
 
 	proof := r.SchnorrRand.Prove(h, PublicData[r.SelfID()].ECDSA, UpdatedSecretECDSA, nil)
 
+	// B = ∑ⱼ Bⱼ
+	certificateCommitment := r.Group().NewPoint()
+	for _, j := range r.PartyIDs() {
+		certificateCommitment = certificateCommitment.Add(r.CertificateCommitments[j])
+	}
+	certificateChallenge, err := UpdatedConfig.PublicConfig().CertificateChallenge(certificateCommitment)
+	if err != nil {
+		return r, err
+	}
+	// zᵢ = bᵢ + e•λᵢ•xᵢ, where λᵢ•xᵢ is replaced by the sum over all evaluation points of a weighted party
+	certificateResponse := r.Group().NewScalar().Set(certificateChallenge).Mul(UpdatedConfig.AdditiveShare(r.PartyIDs()))
+	certificateResponse.Add(r.CertificateNonce)
+
 	// send to all
-	err = r.BroadcastMessage(out, &broadcast5{SchnorrResponse: proof})
+	err = r.BroadcastMessage(out, &broadcast5{
+		SchnorrResponse:     proof,
+		CertificateResponse: certificateResponse,
+	})
 	if err != nil {
 		return r, err
 	}
 
 	r.UpdateHashState(UpdatedConfig)
 	return &round5{
-		round4:        r,
-		UpdatedConfig: UpdatedConfig,
+		round4:                r,
+		UpdatedConfig:         UpdatedConfig,
+		CertificateCommitment: certificateCommitment,
+		CertificateChallenge:  certificateChallenge,
+		AdditivePublicShares:  UpdatedConfig.PublicConfig().AdditivePublicShares(r.PartyIDs()),
+		CertificateResponses:  map[party.ID]curve.Scalar{r.SelfID(): certificateResponse},
 	}, nil
 }
 
@@ -202,7 +277,11 @@ func (round4) MessageContent() round.Content { return &message4{} }
 func (broadcast4) RoundNumber() round.Number { return 4 }
 
 // BroadcastContent implements round.BroadcastRound.
-func (round4) BroadcastContent() round.BroadcastContent { return &broadcast4{} }
+func (r *round4) BroadcastContent() round.BroadcastContent {
+	return &broadcast4{
+		CertificateCommitment: r.Group().NewPoint(),
+	}
+}
 
 // Number implements round.Round.
 func (round4) Number() round.Number { return 4 }
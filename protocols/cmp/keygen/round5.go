@@ -4,6 +4,8 @@ import (
 	"errors"
 
 	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
 	sch "github.com/taurusgroup/multi-party-sig/pkg/zk/sch"
 	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
 )
@@ -13,17 +15,30 @@ var _ round.Round = (*round5)(nil)
 type round5 struct {
 	*round4
 	UpdatedConfig *config.Config
+
+	// CertificateCommitment B = ∑ⱼ Bⱼ
+	CertificateCommitment curve.Point
+	// CertificateChallenge e = H(public config, B)
+	CertificateChallenge curve.Scalar
+	// AdditivePublicShares[j] = λⱼ•Xⱼ, where λⱼ is the Lagrange coefficient of party j over all parties,
+	// see config.PublicConfig.AdditivePublicShares
+	AdditivePublicShares map[party.ID]curve.Point
+	// CertificateResponses[j] = zⱼ
+	CertificateResponses map[party.ID]curve.Scalar
 }
 
 type broadcast5 struct {
 	round.NormalBroadcastContent
 	// SchnorrResponse is the Schnorr proof of knowledge of the new secret share
 	SchnorrResponse *sch.Response
+	// CertificateResponse zᵢ = bᵢ + e•λᵢ•xᵢ is the contribution of this party to the key certificate
+	CertificateResponse curve.Scalar
 }
 
 // StoreBroadcastMessage implements round.BroadcastRound.
 //
-// - verify all Schnorr proof for the new ecdsa share.
+// - verify all Schnorr proof for the new ecdsa share
+// - verify the key certificate response zⱼ•G = Bⱼ + e•λⱼ•Xⱼ.
 func (r *round5) StoreBroadcastMessage(msg round.Message) error {
 	from := msg.From
 	body, ok := msg.Content.(*broadcast5)
@@ -31,7 +46,7 @@ func (r *round5) StoreBroadcastMessage(msg round.Message) error {
 		return round.ErrInvalidContent
 	}
 
-	if !body.SchnorrResponse.IsValid() {
+	if !body.SchnorrResponse.IsValid() || body.CertificateResponse == nil || body.CertificateResponse.IsZero() {
 		return round.ErrNilFields
 	}
 
@@ -40,7 +55,14 @@ func (r *round5) StoreBroadcastMessage(msg round.Message) error {
 		r.SchnorrCommitments[from], nil) {
 		return errors.New("failed to validate schnorr proof for received share")
 	}
-	return nil
+
+	expected := r.CertificateChallenge.Act(r.AdditivePublicShares[from])
+	expected = expected.Add(r.CertificateCommitments[from])
+	if !body.CertificateResponse.ActOnBase().Equal(expected) {
+		return errors.New("failed to validate key certificate response")
+	}
+	r.CertificateResponses[from] = body.CertificateResponse
+	return r.StoreBroadcast(msg)
 }
 
 // VerifyMessage implements round.Round.
@@ -50,7 +72,22 @@ func (round5) VerifyMessage(round.Message) error { return nil }
 func (r *round5) StoreMessage(round.Message) error { return nil }
 
 // Finalize implements round.Round.
+//
+// - set the key certificate (B, z = ∑ⱼ zⱼ) of the new config.
+// - record the broadcasts of all rounds in the CeremonyLog of the new config.
 func (r *round5) Finalize(chan<- *round.Message) (round.Session, error) {
+	response := r.Group().NewScalar()
+	for _, j := range r.PartyIDs() {
+		response.Add(r.CertificateResponses[j])
+	}
+	r.UpdatedConfig.Certificate = &config.KeyCertificate{
+		Commitment: r.CertificateCommitment,
+		Response:   response,
+	}
+	if err := r.UpdatedConfig.VerifyCertificate(); err != nil {
+		return r, err
+	}
+	r.UpdatedConfig.RecordBroadcastHashes(r.BroadcastDigests())
 	return r.ResultRound(r.UpdatedConfig), nil
 }
 
@@ -63,7 +100,8 @@ func (broadcast5) RoundNumber() round.Number { return 5 }
 // BroadcastContent implements round.BroadcastRound.
 func (r *round5) BroadcastContent() round.BroadcastContent {
 	return &broadcast5{
-		SchnorrResponse: sch.EmptyResponse(r.Group()),
+		SchnorrResponse:     sch.EmptyResponse(r.Group()),
+		CertificateResponse: r.Group().NewScalar(),
 	}
 }
 
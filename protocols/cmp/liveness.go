@@ -0,0 +1,216 @@
+package cmp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	zksch "github.com/taurusgroup/multi-party-sig/pkg/zk/sch"
+)
+
+// LivenessProtocolID is the Protocol of the messages of a liveness check, see LivenessCheck.
+const LivenessProtocolID = "cmp/liveness"
+
+const (
+	// livenessNonceLength is the length of the random nonce of a challenge, which is used as SSID of the messages.
+	livenessNonceLength = 32
+	// livenessChallengeRound and livenessResponseRound are the RoundNumber of the messages of a liveness check.
+	livenessChallengeRound = 1
+	livenessResponseRound  = 2
+)
+
+// livenessChallenge is the Data of a challenge message.
+type livenessChallenge struct {
+	// Deadline is the Unix time in seconds after which the challenge must not be answered.
+	Deadline int64
+}
+
+// LivenessCheck challenges the other parties of a config to prove that they are reachable and hold their keys,
+// without running a protocol. Each party answers with a Schnorr proof of knowledge of its ElGamal secret,
+// bound to the random nonce of the challenge, so that responses cannot be replayed.
+//
+// This is meant to be run by an orchestration layer before starting a keygen, refresh or signing session,
+// in order to select signers which are online. It is safe for concurrent use.
+type LivenessCheck struct {
+	config    *Config
+	nonce     []byte
+	deadline  time.Time
+	clock     platform.Clock
+	mtx       sync.Mutex
+	reachable map[party.ID]bool
+}
+
+// NewLivenessCheck returns a LivenessCheck for the other parties of config, whose challenge expires after timeout.
+// If clock is nil, platform.DefaultClock is used.
+func NewLivenessCheck(config *Config, timeout time.Duration, clock platform.Clock) (*LivenessCheck, error) {
+	if config == nil {
+		return nil, errors.New("liveness: config is nil")
+	}
+	if timeout <= 0 {
+		return nil, errors.New("liveness: timeout must be positive")
+	}
+	if clock == nil {
+		clock = platform.DefaultClock
+	}
+	nonce := make([]byte, livenessNonceLength)
+	if _, err := io.ReadFull(platform.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("liveness: failed to sample nonce: %w", err)
+	}
+	return &LivenessCheck{
+		config:    config,
+		nonce:     nonce,
+		deadline:  clock.Now().Add(timeout),
+		clock:     clock,
+		reachable: make(map[party.ID]bool),
+	}, nil
+}
+
+// Challenge returns the message which must be sent to all other parties.
+func (l *LivenessCheck) Challenge() *protocol.Message {
+	data, err := cbor.Marshal(&livenessChallenge{Deadline: l.deadline.Unix()})
+	if err != nil {
+		panic(err)
+	}
+	return &protocol.Message{
+		SSID:        bytes.Clone(l.nonce),
+		From:        l.config.ID,
+		Protocol:    LivenessProtocolID,
+		RoundNumber: livenessChallengeRound,
+		Data:        data,
+	}
+}
+
+// Accept verifies a response to the challenge, and records its sender as reachable.
+// Responses received after the deadline are rejected.
+func (l *LivenessCheck) Accept(msg *protocol.Message) error {
+	if msg == nil || msg.Protocol != LivenessProtocolID || msg.RoundNumber != livenessResponseRound {
+		return errors.New("liveness: not a liveness response")
+	}
+	if msg.To != l.config.ID || !bytes.Equal(msg.SSID, l.nonce) {
+		return errors.New("liveness: response to a different challenge")
+	}
+	now := l.clock.Now()
+	if now.After(l.deadline) {
+		return fmt.Errorf("liveness: response from %s after the deadline", msg.From)
+	}
+	public, err := l.config.ElGamalPublic(msg.From)
+	if err != nil || msg.From == l.config.ID {
+		return fmt.Errorf("liveness: %s is not another party of this config", msg.From)
+	}
+	proof := zksch.EmptyProof(l.config.Group)
+	if err = cbor.Unmarshal(msg.Data, proof); err != nil {
+		return fmt.Errorf("liveness: failed to decode response from %s: %w", msg.From, err)
+	}
+	h := livenessHash(l.config, l.nonce, l.deadline.Unix(), l.config.ID, msg.From)
+	if !proof.Verify(h, public, nil) {
+		return fmt.Errorf("liveness: invalid response from %s", msg.From)
+	}
+
+	l.mtx.Lock()
+	l.reachable[msg.From] = true
+	l.mtx.Unlock()
+	return nil
+}
+
+// Reachable returns the sorted IDs of the parties which answered the challenge, including self.
+func (l *LivenessCheck) Reachable() party.IDSlice {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	ids := []party.ID{l.config.ID}
+	for id := range l.reachable {
+		ids = append(ids, id)
+	}
+	return party.NewIDSlice(ids)
+}
+
+// Unreachable returns the sorted IDs of the parties which have not answered the challenge.
+func (l *LivenessCheck) Unreachable() party.IDSlice {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	var ids []party.ID
+	for _, id := range l.config.PartyIDs() {
+		if !l.reachable[id] && id != l.config.ID {
+			ids = append(ids, id)
+		}
+	}
+	return party.NewIDSlice(ids)
+}
+
+// Done returns true once all other parties have answered, or the deadline has passed.
+func (l *LivenessCheck) Done() bool {
+	return len(l.Unreachable()) == 0 || l.clock.Now().After(l.deadline)
+}
+
+// AnswerLiveness returns the response of config to a challenge sent with LivenessCheck.Challenge,
+// or an error if msg is not a challenge for this party, or if its deadline has passed.
+// If clock is nil, platform.DefaultClock is used.
+func AnswerLiveness(config *Config, msg *protocol.Message, clock platform.Clock) (*protocol.Message, error) {
+	if msg == nil || msg.Protocol != LivenessProtocolID || msg.RoundNumber != livenessChallengeRound {
+		return nil, errors.New("liveness: not a liveness challenge")
+	}
+	if !msg.IsFor(config.ID) {
+		return nil, errors.New("liveness: challenge is not for this party")
+	}
+	if _, ok := config.Public[msg.From]; !ok {
+		return nil, fmt.Errorf("liveness: %s is not a party of this config", msg.From)
+	}
+	if len(msg.SSID) != livenessNonceLength {
+		return nil, errors.New("liveness: invalid nonce")
+	}
+	var challenge livenessChallenge
+	if err := cbor.Unmarshal(msg.Data, &challenge); err != nil {
+		return nil, fmt.Errorf("liveness: failed to decode challenge: %w", err)
+	}
+	if clock == nil {
+		clock = platform.DefaultClock
+	}
+	if clock.Now().After(time.Unix(challenge.Deadline, 0)) {
+		return nil, errors.New("liveness: challenge has expired")
+	}
+
+	h := livenessHash(config, msg.SSID, challenge.Deadline, msg.From, config.ID)
+	proof := zksch.NewProof(h, config.Public[config.ID].ElGamal, config.ElGamal, nil)
+	data, err := cbor.Marshal(proof)
+	if err != nil {
+		return nil, fmt.Errorf("liveness: failed to encode response: %w", err)
+	}
+	return &protocol.Message{
+		SSID:        bytes.Clone(msg.SSID),
+		From:        config.ID,
+		To:          msg.From,
+		Protocol:    LivenessProtocolID,
+		RoundNumber: livenessResponseRound,
+		Data:        data,
+	}, nil
+}
+
+// LivenessResponder returns a function for HandlerOptions.Answer, so that a handler running a protocol
+// for config automatically answers liveness challenges. Invalid and expired challenges are ignored.
+func LivenessResponder(config *Config, clock platform.Clock) func(*protocol.Message) *protocol.Message {
+	return func(msg *protocol.Message) *protocol.Message {
+		response, err := AnswerLiveness(config, msg, clock)
+		if err != nil {
+			return nil
+		}
+		return response
+	}
+}
+
+// livenessHash binds a response to the challenge, the key and both parties.
+func livenessHash(config *Config, nonce []byte, deadline int64, challenger, responder party.ID) *hash.Hash {
+	return hash.New(
+		&hash.BytesWithDomain{TheDomain: "Liveness Nonce", Bytes: nonce},
+		&hash.BytesWithDomain{TheDomain: "Liveness Deadline", Bytes: []byte(time.Unix(deadline, 0).UTC().Format(time.RFC3339))},
+		config.RID,
+		challenger,
+		responder,
+	)
+}
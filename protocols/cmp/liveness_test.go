@@ -0,0 +1,70 @@
+package cmp
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+)
+
+// fixedClock always returns the same time.
+type fixedClock struct{ t time.Time }
+
+func (c fixedClock) Now() time.Time                       { return c.t }
+func (c fixedClock) After(time.Duration) <-chan time.Time { return nil }
+
+func TestLivenessCheck(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	configs, partyIDs := test.GenerateConfig(curve.Secp256k1{}, 3, 1, rand.Reader, pl)
+	self := configs[partyIDs[0]]
+	clock := fixedClock{t: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+
+	check, err := NewLivenessCheck(self, time.Minute, clock)
+	require.NoError(t, err)
+	challenge := check.Challenge()
+	assert.False(t, check.Done())
+
+	// the first party answers directly
+	response, err := AnswerLiveness(configs[partyIDs[1]], challenge, clock)
+	require.NoError(t, err)
+	require.NoError(t, check.Accept(response))
+	assert.Equal(t, party.IDSlice{partyIDs[2]}, check.Unreachable())
+
+	other, err := NewLivenessCheck(self, time.Minute, clock)
+	require.NoError(t, err)
+	assert.Error(t, other.Accept(response), "response to another challenge should be rejected")
+
+	// the second party answers through the handler of a running protocol
+	h, err := protocol.NewMultiHandlerWithOptions(Presign(configs[partyIDs[2]], partyIDs, pl), nil, protocol.HandlerOptions{
+		Answer: LivenessResponder(configs[partyIDs[2]], clock),
+	})
+	require.NoError(t, err)
+	for len(h.Listen()) > 0 {
+		<-h.Listen()
+	}
+	require.True(t, h.CanAccept(challenge))
+	h.Accept(challenge)
+	require.Len(t, h.Listen(), 1)
+	response = <-h.Listen()
+	require.NoError(t, check.Accept(response))
+	assert.Equal(t, partyIDs, check.Reachable())
+	assert.True(t, check.Done())
+
+	forged := *response
+	forged.From = partyIDs[1]
+	assert.Error(t, check.Accept(&forged), "response should be bound to its sender")
+
+	expired := fixedClock{t: clock.t.Add(2 * time.Minute)}
+	_, err = AnswerLiveness(configs[partyIDs[1]], challenge, expired)
+	assert.Error(t, err)
+	_, err = AnswerLiveness(configs[partyIDs[1]], response, clock)
+	assert.Error(t, err, "a response is not a challenge")
+}
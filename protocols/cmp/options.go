@@ -0,0 +1,307 @@
+package cmp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/keygen"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/sign"
+)
+
+// KeygenOptions gathers the parameters given to Keygen, so that they can be validated
+// before the protocol is started.
+type KeygenOptions struct {
+	Group        curve.Curve
+	SelfID       party.ID
+	Participants []party.ID
+	Threshold    int
+	SessionID    []byte
+	// DryRun generates an INSECURE config with fixed Paillier keys and minimal proofs, in milliseconds.
+	// It supports at most keygen.MaxDryRunParticipants participants.
+	// It is only meant for integration tests of the orchestration around the protocol, see IsDryRun.
+	// All participants must set the same value.
+	DryRun bool
+	// Primes is optional, and supplies the Paillier key of this party instead of sampling it during the protocol,
+	// see keygen.PrimeProvider. It cannot be used with DryRun.
+	Primes PrimeProvider
+	// Weights is optional, and gives some participants several shares, so that Threshold applies to
+	// their total weight, see party.Weights. All participants must set the same value.
+	Weights party.Weights
+	// Invited is optional, and contains all the parties invited to the keygen, so that a keygen whose
+	// invitees did not all show up can be restarted without them.
+	// The protocol itself still requires a message from every Participant in every round, and does not
+	// complete without them: when some invited parties are absent, the present ones agree on the new set of
+	// Participants and start a new keygen with it, which is allowed if there are at least Quorum of them.
+	// The absent parties are recorded as pending in the config, and obtain a share later with Join.
+	// All participants must set the same value.
+	Invited []party.ID
+	// Quorum is the minimum number of Participants when Invited is set, or len(Invited)-1 if it is 0.
+	Quorum int
+}
+
+// PrimeProvider supplies the Paillier key of a party during keygen, see KeygenOptions.Primes.
+type PrimeProvider = keygen.PrimeProvider
+
+// Validate returns an error describing the first problem found with the options, if any.
+// It does not start the protocol, and can therefore be used as a dry run.
+func (o KeygenOptions) Validate() error {
+	if o.Group == nil {
+		return errors.New("keygen: group is nil")
+	}
+	participants := party.NewIDSlice(o.Participants)
+	if !participants.Valid() {
+		return errors.New("keygen: participants contains duplicates")
+	}
+	if !participants.Contains(o.SelfID) {
+		return fmt.Errorf("keygen: participants does not include self (%s)", o.SelfID)
+	}
+	if err := o.Weights.Validate(); err != nil {
+		return fmt.Errorf("keygen: %w", err)
+	}
+	if weight := o.Weights.Total(participants); !config.ValidThreshold(o.Threshold, weight) {
+		return fmt.Errorf("keygen: threshold %d is invalid for %d participants with total weight %d", o.Threshold, len(participants), weight)
+	}
+	if err := validateSessionID(o.SessionID); err != nil {
+		return fmt.Errorf("keygen: %w", err)
+	}
+	if err := o.validateQuorum(participants); err != nil {
+		return err
+	}
+	if o.DryRun && o.Primes != nil {
+		return errors.New("keygen: a dry run uses fixed Paillier keys, and cannot use a prime provider")
+	}
+	if o.DryRun && len(participants) > keygen.MaxDryRunParticipants {
+		return fmt.Errorf("keygen: a dry run supports at most %d participants", keygen.MaxDryRunParticipants)
+	}
+	return nil
+}
+
+// Start returns the StartFunc for Keygen with these options.
+func (o KeygenOptions) Start(pl *pool.Pool) protocol.StartFunc {
+	if o.DryRun {
+		return keygen.StartDryRun(round.Info{
+			ProtocolID:       "cmp/keygen-threshold",
+			FinalRoundNumber: keygen.Rounds,
+			SelfID:           o.SelfID,
+			PartyIDs:         o.Participants,
+			Threshold:        o.Threshold,
+			Group:            o.Group,
+			Weights:          o.Weights,
+			Pending:          o.pending(),
+		}, pl)
+	}
+	return keygen.StartWithPrimes(round.Info{
+		ProtocolID:       "cmp/keygen-threshold",
+		FinalRoundNumber: keygen.Rounds,
+		SelfID:           o.SelfID,
+		PartyIDs:         o.Participants,
+		Threshold:        o.Threshold,
+		Group:            o.Group,
+		Weights:          o.Weights,
+		Pending:          o.pending(),
+	}, pl, nil, o.Primes)
+}
+
+// validateQuorum checks that the participants are invited, and that there are enough of them.
+func (o KeygenOptions) validateQuorum(participants party.IDSlice) error {
+	if o.Invited == nil {
+		if o.Quorum != 0 {
+			return errors.New("keygen: a quorum requires the invited parties")
+		}
+		return nil
+	}
+	invited := party.NewIDSlice(o.Invited)
+	if !invited.Valid() {
+		return errors.New("keygen: invited parties contain duplicates")
+	}
+	for _, id := range participants {
+		if !invited.Contains(id) {
+			return fmt.Errorf("keygen: participant %s was not invited", id)
+		}
+	}
+	quorum := o.Quorum
+	if quorum == 0 {
+		quorum = len(invited) - 1
+	}
+	if quorum < 1 || quorum > len(invited) {
+		return fmt.Errorf("keygen: quorum %d is invalid for %d invited parties", quorum, len(invited))
+	}
+	if len(participants) < quorum {
+		return fmt.Errorf("keygen: %d participants is less than the quorum of %d", len(participants), quorum)
+	}
+	if !o.Weights.IsTrivial() && len(participants) < len(invited) {
+		return errors.New("keygen: weighted keys cannot be joined later")
+	}
+	return nil
+}
+
+// pending returns the invited parties which are not participants.
+func (o KeygenOptions) pending() []party.ID {
+	participants := party.NewIDSlice(o.Participants)
+	var pending []party.ID
+	for _, id := range o.Invited {
+		if !participants.Contains(id) {
+			pending = append(pending, id)
+		}
+	}
+	return pending
+}
+
+// IsDryRun returns true if c was generated with KeygenOptions.DryRun.
+// Such a config is insecure, and must never be used to protect any funds.
+func IsDryRun(c *Config) bool {
+	return keygen.IsDryRun(c)
+}
+
+// SignOptions gathers the parameters given to Sign, so that they can be validated
+// before the protocol is started.
+type SignOptions struct {
+	Config      *Config
+	Signers     []party.ID
+	MessageHash []byte
+	SessionID   []byte
+	// Confirm is optional, see SignWithConfirmation.
+	Confirm SigningConfirmer
+	// Policy and PolicyRequest are optional, see SignWithPolicy.
+	Policy        *Policy
+	PolicyRequest *PolicyRequest
+	// Profile is optional, and selects the conventions of the chain the signature is intended for.
+	// When set, the message hash must be computed with Profile.HashMessage, and the signature
+	// serialized with Encode.
+	Profile *ecdsa.Profile
+	// Check is optional, see SignWithCheck and EthereumWatchdog.
+	Check SignatureCheck
+}
+
+// Validate returns an error describing the first problem found with the options, if any.
+// It does not start the protocol, and can therefore be used as a dry run.
+//
+// In addition to the checks performed when the protocol starts, it verifies that the secret
+// material of the config is consistent with its public data, which may not be the case
+// if the config was corrupted or derived incorrectly.
+func (o SignOptions) Validate() error {
+	c := o.Config
+	if c == nil {
+		return errors.New("sign: config is nil")
+	}
+	if err := validateConfig(c); err != nil {
+		return fmt.Errorf("sign: %w", err)
+	}
+	if err := c.ValidateSigners(party.NewIDSlice(o.Signers)); err != nil {
+		return fmt.Errorf("sign: %w", err)
+	}
+	if len(o.MessageHash) == 0 {
+		return errors.New("sign: message hash is empty")
+	}
+	if isZero(o.MessageHash) {
+		return errors.New("sign: message hash is zero")
+	}
+	if err := ecdsa.ValidateDigest(c.Group, o.MessageHash); err != nil {
+		return fmt.Errorf("sign: %w", err)
+	}
+	if err := validateSessionID(o.SessionID); err != nil {
+		return fmt.Errorf("sign: %w", err)
+	}
+	if o.Policy != nil {
+		if err := o.Policy.Evaluate(party.NewIDSlice(o.Signers), o.PolicyRequest); err != nil {
+			return fmt.Errorf("sign: %w", err)
+		}
+	}
+	if o.Profile != nil {
+		if err := o.Profile.Supports(c.Group); err != nil {
+			return fmt.Errorf("sign: %w", err)
+		}
+		if len(o.MessageHash) != len(o.Profile.HashMessage(nil)) {
+			return fmt.Errorf("sign: message hash has length %d, which does not match profile %s", len(o.MessageHash), o.Profile.Name)
+		}
+	}
+	return nil
+}
+
+// Encode serializes a signature produced with these options according to the profile.
+func (o SignOptions) Encode(sig *ecdsa.Signature) ([]byte, error) {
+	if o.Profile == nil {
+		return nil, errors.New("sign: no profile")
+	}
+	return o.Profile.Encode(*sig)
+}
+
+// Address returns the address of the public key of the config, according to the profile.
+func (o SignOptions) Address() (string, error) {
+	if o.Profile == nil {
+		return "", errors.New("sign: no profile")
+	}
+	return o.Profile.Address(o.Config.PublicPoint())
+}
+
+// Start returns the StartFunc for Sign with these options.
+// The session is bound to SessionID: the handler may start it with a nil session ID, in which case
+// SessionID is used, but any other session ID is rejected.
+func (o SignOptions) Start(pl *pool.Pool) protocol.StartFunc {
+	start := sign.StartSignWithCheck(o.Config, o.Signers, o.MessageHash, o.Policy, o.PolicyRequest, o.Confirm, o.Check, pl)
+	return func(sessionID []byte) (round.Session, error) {
+		if sessionID == nil {
+			sessionID = o.SessionID
+		} else if !bytes.Equal(sessionID, o.SessionID) {
+			return nil, errors.New("sign: session ID does not match the options")
+		}
+		return start(sessionID)
+	}
+}
+
+// SignOptionsFromAgreement returns the SignOptions accepted by all parties during ProposeSign.
+// The session ID is derived from the negotiation, so that the signing session is bound to it.
+func SignOptionsFromAgreement(c *Config, agreement *SignAgreement) SignOptions {
+	return SignOptions{
+		Config:      c,
+		Signers:     agreement.Signers,
+		MessageHash: agreement.MessageHash,
+		SessionID:   agreement.SessionID,
+	}
+}
+
+// validateSessionID requires a session ID to be set, and to be accepted by the handlers.
+func validateSessionID(sessionID []byte) error {
+	if sessionID == nil {
+		return errors.New("session ID is missing")
+	}
+	return protocol.ValidateSessionID(sessionID)
+}
+
+// validateConfig checks that the secret shares of c correspond to its public data.
+func validateConfig(c *Config) error {
+	if c.Group == nil || c.ECDSA == nil || c.ElGamal == nil || c.Paillier == nil {
+		return errors.New("config is missing secret data")
+	}
+	public, ok := c.Public[c.ID]
+	if !ok || public == nil {
+		return fmt.Errorf("config has no public data for self (%s)", c.ID)
+	}
+	if public.ECDSA == nil || !c.ECDSA.ActOnBase().Equal(public.ECDSA) {
+		return errors.New("config ECDSA share does not match its public share")
+	}
+	if public.ElGamal == nil || !c.ElGamal.ActOnBase().Equal(public.ElGamal) {
+		return errors.New("config ElGamal key does not match its public key")
+	}
+	if public.Paillier == nil || !public.Paillier.Equal(c.Paillier.PublicKey) {
+		return errors.New("config Paillier key does not match its public key")
+	}
+	return nil
+}
+
+func isZero(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,307 @@
+package cmp
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/keygen"
+)
+
+func TestKeygenOptionsValidate(t *testing.T) {
+	ids := test.PartyIDs(3)
+	sessionID := protocol.DeriveSessionID("keygen", 0, nil)
+	valid := KeygenOptions{
+		Group:        curve.Secp256k1{},
+		SelfID:       ids[0],
+		Participants: ids,
+		Threshold:    1,
+		SessionID:    sessionID,
+	}
+	assert.NoError(t, valid.Validate())
+
+	invalid := valid
+	invalid.Group = nil
+	assert.Error(t, invalid.Validate())
+
+	invalid = valid
+	invalid.SelfID = "z"
+	assert.Error(t, invalid.Validate())
+
+	invalid = valid
+	invalid.Threshold = 3
+	assert.Error(t, invalid.Validate())
+
+	invalid = valid
+	invalid.SessionID = nil
+	assert.Error(t, invalid.Validate())
+
+	invalid = valid
+	invalid.SessionID = []byte("abc-2of3-test")
+	assert.Error(t, invalid.Validate())
+
+	invalid = valid
+	invalid.DryRun = true
+	invalid.Primes = keygen.PrimeProviderFunc(func(context.Context) (*paillier.SecretKey, error) { return nil, nil })
+	assert.Error(t, invalid.Validate(), "a dry run cannot use a prime provider")
+
+	invalid = valid
+	invalid.DryRun = true
+	invalid.Participants = test.PartyIDs(keygen.MaxDryRunParticipants + 1)
+	invalid.SelfID = invalid.Participants[0]
+	assert.Error(t, invalid.Validate(), "a dry run has a limited number of Paillier keys")
+
+	quorum := valid
+	quorum.Invited = append(ids.Copy(), "d")
+	assert.NoError(t, quorum.Validate())
+	assert.Equal(t, []party.ID{"d"}, quorum.pending())
+
+	invalid = quorum
+	invalid.Quorum = 4
+	assert.Error(t, invalid.Validate(), "not enough participants")
+
+	invalid = quorum
+	invalid.Invited = ids[1:]
+	assert.Error(t, invalid.Validate(), "participant not invited")
+
+	invalid = valid
+	invalid.Quorum = 2
+	assert.Error(t, invalid.Validate(), "quorum without invited parties")
+}
+
+func TestSignOptionsValidate(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	configs, ids := test.GenerateConfig(curve.Secp256k1{}, 3, 1, rand.Reader, pl)
+	c := configs[ids[0]]
+	valid := SignOptions{
+		Config:      c,
+		Signers:     ids[:2],
+		MessageHash: ecdsa.ProfileBitcoin.HashMessage([]byte("hello")),
+		SessionID:   protocol.DeriveSessionID("sign", 0, c.RID),
+	}
+	assert.NoError(t, valid.Validate())
+
+	invalid := valid
+	invalid.Config = nil
+	assert.Error(t, invalid.Validate())
+
+	invalid = valid
+	invalid.Signers = ids[1:]
+	assert.Error(t, invalid.Validate(), "signers must include self")
+
+	invalid = valid
+	invalid.MessageHash = make([]byte, 32)
+	assert.Error(t, invalid.Validate(), "zero hash should be rejected")
+
+	invalid = valid
+	invalid.MessageHash = []byte("hello")
+	assert.Error(t, invalid.Validate(), "unhashed message should be rejected")
+
+	invalid = valid
+	invalid.SessionID = nil
+	assert.Error(t, invalid.Validate())
+
+	// a config holding another party's secret share
+	mismatched := *c
+	mismatched.ECDSA = configs[ids[1]].ECDSA
+	invalid = valid
+	invalid.Config = &mismatched
+	assert.Error(t, invalid.Validate())
+}
+
+func TestSignOptionsStart(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	configs, ids := test.GenerateConfig(curve.Secp256k1{}, 3, 1, rand.Reader, pl)
+	c := configs[ids[0]]
+	opts := SignOptions{
+		Config:      c,
+		Signers:     ids[:2],
+		MessageHash: ecdsa.ProfileBitcoin.HashMessage([]byte("hello")),
+		SessionID:   protocol.DeriveSessionID("sign", 0, c.RID),
+	}
+	require.NoError(t, opts.Validate())
+
+	withDefault, err := opts.Start(pl)(nil)
+	require.NoError(t, err)
+	withSessionID, err := opts.Start(pl)(opts.SessionID)
+	require.NoError(t, err)
+	assert.Equal(t, withSessionID.SSID(), withDefault.SSID(), "a nil session ID should use the one of the options")
+
+	_, err = opts.Start(pl)(protocol.DeriveSessionID("sign", 1, c.RID))
+	assert.Error(t, err, "another session ID should be rejected")
+}
+
+func TestSignOptionsProfile(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	configs, ids := test.GenerateConfig(curve.Secp256k1{}, 3, 1, rand.Reader, pl)
+	c := configs[ids[0]]
+	opts := SignOptions{
+		Config:      c,
+		Signers:     ids[:2],
+		MessageHash: []byte("hello"),
+		SessionID:   protocol.DeriveSessionID("sign", 0, c.RID),
+		Profile:     ecdsa.ProfileEthereum,
+	}
+	assert.Error(t, opts.Validate(), "message hash must match the profile")
+
+	opts.MessageHash = opts.Profile.HashMessage([]byte("hello"))
+	assert.NoError(t, opts.Validate())
+
+	address, err := opts.Address()
+	require.NoError(t, err)
+	assert.Len(t, address, 42)
+
+	opts.Profile = nil
+	_, err = opts.Address()
+	assert.Error(t, err)
+}
+
+func TestKeygenOptionsDryRun(t *testing.T) {
+	N := 3
+	partyIDs := test.PartyIDs(N)
+	n := test.NewNetwork(partyIDs)
+	sessionID := protocol.DeriveSessionID("keygen-dry-run", 0, nil)
+
+	results := make(map[party.ID]*Config, N)
+	var mtx sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(N)
+	for _, id := range partyIDs {
+		go func(id party.ID) {
+			defer wg.Done()
+			opts := KeygenOptions{
+				Group:        curve.Secp256k1{},
+				SelfID:       id,
+				Participants: partyIDs,
+				Threshold:    1,
+				SessionID:    sessionID,
+				DryRun:       true,
+			}
+			require.NoError(t, opts.Validate())
+			h, err := protocol.NewMultiHandler(opts.Start(nil), opts.SessionID)
+			require.NoError(t, err)
+			test.HandlerLoop(id, h, n)
+			r, err := h.Result()
+			require.NoError(t, err)
+			mtx.Lock()
+			results[id] = r.(*Config)
+			mtx.Unlock()
+		}(id)
+	}
+	wg.Wait()
+
+	first := results[partyIDs[0]]
+	for _, id := range partyIDs {
+		assert.True(t, IsDryRun(results[id]))
+		assert.NoError(t, first.Compatible(results[id]))
+		assert.NoError(t, first.SameCeremony(results[id]))
+		assert.NoError(t, results[id].VerifyCertificate())
+	}
+	// rounds 2 to 5 of keygen have broadcast messages
+	assert.Len(t, first.CeremonyLog, 4)
+
+	// the configs of a dry run can sign
+	signers := partyIDs[:2]
+	messageHash := ecdsa.ProfileBitcoin.HashMessage([]byte("dry run"))
+	signNetwork := test.NewNetwork(signers)
+	wg.Add(len(signers))
+	for _, id := range signers {
+		go func(id party.ID) {
+			defer wg.Done()
+			opts := SignOptions{
+				Config:      results[id],
+				Signers:     signers,
+				MessageHash: messageHash,
+				SessionID:   protocol.DeriveSessionID("sign-dry-run", 0, nil),
+			}
+			require.NoError(t, opts.Validate())
+			h, err := protocol.NewMultiHandler(opts.Start(nil), opts.SessionID)
+			require.NoError(t, err)
+			test.HandlerLoop(id, h, signNetwork)
+			r, err := h.Result()
+			require.NoError(t, err)
+			assert.True(t, r.(*ecdsa.Signature).Verify(first.PublicPoint(), messageHash))
+		}(id)
+	}
+	wg.Wait()
+
+	// a dry run can not be mixed with a real keygen
+	dryRun, err := KeygenOptions{Group: curve.Secp256k1{}, SelfID: partyIDs[0], Participants: partyIDs, Threshold: 1, DryRun: true}.Start(nil)(sessionID)
+	require.NoError(t, err)
+	secure, err := Keygen(curve.Secp256k1{}, partyIDs[0], partyIDs, 1, nil)(sessionID)
+	require.NoError(t, err)
+	assert.NotEqual(t, dryRun.SSID(), secure.SSID())
+}
+
+func TestKeygenQuorum(t *testing.T) {
+	N := 4
+	invited := test.PartyIDs(N)
+	participants := invited[:N-1]
+	n := test.NewNetwork(participants)
+	sessionID := protocol.DeriveSessionID("keygen-quorum", 0, nil)
+
+	results := make(map[party.ID]*Config, N-1)
+	var mtx sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(N - 1)
+	for _, id := range participants {
+		go func(id party.ID) {
+			defer wg.Done()
+			opts := KeygenOptions{
+				Group:        curve.Secp256k1{},
+				SelfID:       id,
+				Participants: participants,
+				Threshold:    1,
+				SessionID:    sessionID,
+				DryRun:       true,
+				Invited:      invited,
+			}
+			require.NoError(t, opts.Validate())
+			h, err := protocol.NewMultiHandler(opts.Start(nil), opts.SessionID)
+			require.NoError(t, err)
+			test.HandlerLoop(id, h, n)
+			r, err := h.Result()
+			require.NoError(t, err)
+			mtx.Lock()
+			results[id] = r.(*Config)
+			mtx.Unlock()
+		}(id)
+	}
+	wg.Wait()
+
+	newcomer := invited[N-1]
+	for _, c := range results {
+		assert.NoError(t, c.Validate())
+		assert.Equal(t, []party.ID{newcomer}, c.Pending)
+	}
+
+	// all parties of the join agree on the session, including the newcomer
+	joinSessionID := protocol.DeriveSessionID("join", 0, nil)
+	newParty, err := JoinAsNewParty(results[participants[0]].PublicConfig(), newcomer, nil)(joinSessionID)
+	require.NoError(t, err)
+	for _, c := range results {
+		r, err := Join(c, newcomer, nil)(joinSessionID)
+		require.NoError(t, err)
+		assert.Equal(t, newParty.SSID(), r.SSID())
+	}
+	_, err = Join(results[participants[0]], "z", nil)(joinSessionID)
+	assert.Error(t, err, "only pending parties can join")
+	_, err = JoinAsNewParty(nil, newcomer, nil)(joinSessionID)
+	assert.Error(t, err)
+}
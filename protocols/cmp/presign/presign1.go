@@ -1,8 +1,6 @@
 package presign
 
 import (
-	"crypto/rand"
-
 	"github.com/taurusgroup/multi-party-sig/internal/elgamal"
 	"github.com/taurusgroup/multi-party-sig/internal/round"
 	"github.com/taurusgroup/multi-party-sig/internal/types"
@@ -12,6 +10,7 @@ import (
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
 	"github.com/taurusgroup/multi-party-sig/pkg/party"
 	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 	"github.com/taurusgroup/multi-party-sig/pkg/pool"
 	zkencelg "github.com/taurusgroup/multi-party-sig/pkg/zk/encelg"
 )
@@ -44,6 +43,9 @@ type presign1 struct {
 
 	// Message is the message to be signed. If it is nil, a presignature is created.
 	Message []byte
+
+	// ConfigFingerprint identifies the config used, and is stored in the presignature.
+	ConfigFingerprint []byte
 }
 
 // VerifyMessage implements round.Round.
@@ -68,12 +70,12 @@ func (presign1) StoreMessage(round.Message) error { return nil }
 // In two rounds, we compare the hashes received and if they are different then we abort.
 func (r *presign1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// γᵢ <- 𝔽,
-	GammaShare := sample.Scalar(rand.Reader, r.Group())
+	GammaShare := sample.Scalar(platform.Reader, r.Group())
 	// Gᵢ = Encᵢ(γᵢ;νᵢ)
 	G, GNonce := r.Paillier[r.SelfID()].Enc(curve.MakeInt(GammaShare))
 
 	// kᵢ <- 𝔽,
-	KShare := sample.Scalar(rand.Reader, r.Group())
+	KShare := sample.Scalar(platform.Reader, r.Group())
 	KShareInt := curve.MakeInt(KShare)
 	// Kᵢ = Encᵢ(kᵢ;ρᵢ)
 	K, KNonce := r.Paillier[r.SelfID()].Enc(KShareInt)
@@ -81,7 +83,7 @@ func (r *presign1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// Zᵢ = (bᵢ⋅G, kᵢ⋅G+bᵢ⋅Yᵢ), bᵢ
 	ElGamalK, ElGamalNonce := elgamal.Encrypt(r.ElGamal[r.SelfID()], KShare)
 
-	presignatureID, err := types.NewRID(rand.Reader)
+	presignatureID, err := types.NewRID(platform.Reader)
 	if err != nil {
 		return r, err
 	}
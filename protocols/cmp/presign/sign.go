@@ -9,7 +9,6 @@ import (
 	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
-	"github.com/taurusgroup/multi-party-sig/pkg/math/polynomial"
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
 	"github.com/taurusgroup/multi-party-sig/pkg/party"
 	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
@@ -37,11 +36,16 @@ func StartPresign(c *config.Config, signers []party.ID, message []byte, pl *pool
 			PartyIDs:  signers,
 			Threshold: c.Threshold,
 			Group:     c.Group,
+			Epoch:     c.Epoch,
+			Weights:   c.Weights(),
 		}
 		if len(message) == 0 {
 			info.FinalRoundNumber = protocolOfflineRounds
 			info.ProtocolID = protocolOfflineID
 		} else {
+			if err := ecdsa.ValidateDigest(c.Group, message); err != nil {
+				return nil, fmt.Errorf("sign.Create: %w", err)
+			}
 			info.FinalRoundNumber = protocolFullRounds
 			info.ProtocolID = protocolFullID
 		}
@@ -51,8 +55,8 @@ func StartPresign(c *config.Config, signers []party.ID, message []byte, pl *pool
 			return nil, fmt.Errorf("sign.Create: %w", err)
 		}
 
-		if !c.CanSign(helper.PartyIDs()) {
-			return nil, errors.New("sign.Create: signers is not a valid signing subset")
+		if err = c.ValidateSigners(helper.PartyIDs()); err != nil {
+			return nil, fmt.Errorf("sign.Create: signers is not a valid signing subset: %w", err)
 		}
 		// Scale public data
 		T := helper.N()
@@ -62,13 +66,13 @@ func StartPresign(c *config.Config, signers []party.ID, message []byte, pl *pool
 		Paillier := make(map[party.ID]*paillier.PublicKey, T)
 		Pedersen := make(map[party.ID]*pedersen.Parameters, T)
 		PublicKey := group.NewPoint()
-		lagrange := polynomial.Lagrange(group, signers)
+		additiveShares := c.PublicConfig().AdditivePublicShares(helper.PartyIDs())
 		// Scale own secret
-		SecretECDSA := group.NewScalar().Set(lagrange[c.ID]).Mul(c.ECDSA)
+		SecretECDSA := c.AdditiveShare(helper.PartyIDs())
 		for _, j := range helper.PartyIDs() {
 			public := c.Public[j]
 			// scale public key share
-			ECDSA[j] = lagrange[j].Act(public.ECDSA)
+			ECDSA[j] = additiveShares[j]
 			ElGamal[j] = public.ElGamal
 			Paillier[j] = public.Paillier
 			Pedersen[j] = public.Pedersen
@@ -87,11 +91,19 @@ func StartPresign(c *config.Config, signers []party.ID, message []byte, pl *pool
 			Paillier:       Paillier,
 			Pedersen:       Pedersen,
 			Message:        message,
+			// the fingerprint only depends on public data, so it is the same for all signers
+			ConfigFingerprint: c.Fingerprint(),
 		}, nil
 	}
 }
 
 func StartPresignOnline(c *config.Config, preSignature *ecdsa.PreSignature, message []byte, pl *pool.Pool) protocol.StartFunc {
+	return StartPresignOnlineWithCheck(c, preSignature, message, nil, pl)
+}
+
+// StartPresignOnlineWithCheck is the same as StartPresignOnline, but calls check on the signature once it has been
+// verified, and aborts with its error instead of returning the signature. If check is nil, it is equivalent to StartPresignOnline.
+func StartPresignOnlineWithCheck(c *config.Config, preSignature *ecdsa.PreSignature, message []byte, check func(messageHash []byte, sig *ecdsa.Signature) error, pl *pool.Pool) protocol.StartFunc {
 	return func(sessionID []byte) (round.Session, error) {
 		if c == nil || preSignature == nil {
 			return nil, errors.New("presign: config or preSignature is nil")
@@ -100,10 +112,19 @@ func StartPresignOnline(c *config.Config, preSignature *ecdsa.PreSignature, mess
 		if len(message) == 0 {
 			return nil, errors.New("sign.Create: message is nil")
 		}
+		if err := ecdsa.ValidateDigest(c.Group, message); err != nil {
+			return nil, fmt.Errorf("sign.Create: %w", err)
+		}
 
 		if err := preSignature.Validate(); err != nil {
 			return nil, fmt.Errorf("sign.Create: %w", err)
 		}
+		if err := preSignature.CheckFingerprint(c.Fingerprint()); err != nil {
+			return nil, fmt.Errorf("sign.Create: %w", err)
+		}
+		if err := preSignature.CheckMessage(message); err != nil {
+			return nil, fmt.Errorf("sign.Create: %w", err)
+		}
 
 		signers := preSignature.SignerIDs()
 
@@ -118,6 +139,8 @@ func StartPresignOnline(c *config.Config, preSignature *ecdsa.PreSignature, mess
 			PartyIDs:         signers,
 			Threshold:        c.Threshold,
 			Group:            c.Group,
+			Epoch:            c.Epoch,
+			Weights:          c.Weights(),
 		}
 
 		helper, err := round.NewSession(
@@ -140,6 +163,7 @@ func StartPresignOnline(c *config.Config, preSignature *ecdsa.PreSignature, mess
 			PublicKey:    c.PublicPoint(),
 			Message:      message,
 			PreSignature: preSignature,
+			check:        check,
 		}, nil
 	}
 }
@@ -17,6 +17,8 @@ type sign1 struct {
 	Message []byte
 	// PreSignature = (R, {R̄ⱼ,Sⱼ}ⱼ, kᵢ, χᵢ)
 	PreSignature *ecdsa.PreSignature
+	// check is called on the signature in the last round, if not nil.
+	check func(messageHash []byte, sig *ecdsa.Signature) error
 }
 
 // VerifyMessage implements round.Round.
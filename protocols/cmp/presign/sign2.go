@@ -2,6 +2,7 @@ package presign
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/taurusgroup/multi-party-sig/internal/round"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
@@ -47,12 +48,17 @@ func (sign2) StoreMessage(round.Message) error { return nil }
 
 // Finalize implements round.Round
 //
-// - verify (r,s)
+// - verify (r,s), and run the additional check, if any
 // - if not, find culprit.
 func (r *sign2) Finalize(chan<- *round.Message) (round.Session, error) {
 	s := r.PreSignature.Signature(r.SigmaShares)
 
 	if s.Verify(r.PublicKey, r.Message) {
+		if r.check != nil {
+			if err := r.check(r.Message, s); err != nil {
+				return r.AbortRound(fmt.Errorf("signature check failed: %w", err)), nil
+			}
+		}
 		return r.ResultRound(s), nil
 	}
 
@@ -39,7 +39,7 @@ func init() {
 		configs[id], _ = c.DeriveBIP32(0)
 	}
 
-	messageHash = make([]byte, 64)
+	messageHash = make([]byte, 32)
 	sha3.ShakeSum128(messageHash, []byte("hello"))
 }
 
@@ -0,0 +1,32 @@
+package presign
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+)
+
+// VerifySigmaShare checks the signature share σⱼ broadcast in msg by msg.From in the last round of the online phase,
+// so that a coordinator relaying the messages can blame a signer as soon as its share arrives,
+// instead of only learning that the aggregated signature is invalid.
+//
+// Only the public parts of preSignature are used, so it does not need to be the presignature of a signer.
+func VerifySigmaShare(preSignature *ecdsa.PreSignature, msg *protocol.Message, message []byte) error {
+	if preSignature == nil || msg == nil {
+		return errors.New("presign: presignature or message is nil")
+	}
+	if !msg.Broadcast || msg.RoundNumber != protocolFullRounds {
+		return fmt.Errorf("presign: message of round %d does not contain a signature share", msg.RoundNumber)
+	}
+	body := &broadcastSign2{Sigma: preSignature.Group().NewScalar()}
+	if err := cbor.Unmarshal(msg.Data, body); err != nil {
+		return fmt.Errorf("presign: %w", err)
+	}
+	if body.Sigma.IsZero() || !preSignature.VerifySignatureShare(msg.From, body.Sigma, message) {
+		return fmt.Errorf("presign: invalid signature share from %s", msg.From)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package presign
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+)
+
+// runHandlers delivers all messages between the handlers created by start until they are done,
+// and returns the results along with all exchanged messages.
+func runHandlers(t *testing.T, start func(c party.ID) protocol.StartFunc) (map[party.ID]interface{}, []*protocol.Message) {
+	handlers := make(map[party.ID]*protocol.MultiHandler, N)
+	for _, id := range partyIDs {
+		h, err := protocol.NewMultiHandler(start(id), nil)
+		require.NoError(t, err)
+		handlers[id] = h
+	}
+	var exchanged []*protocol.Message
+	for delivered := true; delivered; {
+		delivered = false
+		for _, h := range handlers {
+			for done := false; !done; {
+				select {
+				case msg, ok := <-h.Listen():
+					if !ok {
+						done = true
+						break
+					}
+					exchanged = append(exchanged, msg)
+					for id, other := range handlers {
+						if msg.IsFor(id) {
+							other.Accept(msg)
+							delivered = true
+						}
+					}
+				default:
+					done = true
+				}
+			}
+		}
+	}
+	results := make(map[party.ID]interface{}, N)
+	for id, h := range handlers {
+		result, err := h.Result()
+		require.NoError(t, err)
+		results[id] = result
+	}
+	return results, exchanged
+}
+
+func TestVerifySigmaShare(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	results, _ := runHandlers(t, func(id party.ID) protocol.StartFunc {
+		return StartPresign(configs[id], partyIDs, nil, pl)
+	})
+	preSignatures := make(map[party.ID]*ecdsa.PreSignature, N)
+	for id, result := range results {
+		preSignatures[id] = result.(*ecdsa.PreSignature)
+	}
+
+	_, exchanged := runHandlers(t, func(id party.ID) protocol.StartFunc {
+		return StartPresignOnline(configs[id], preSignatures[id], messageHash, pl)
+	})
+	verified := 0
+	for _, msg := range exchanged {
+		// any party's presignature can be used to verify the shares
+		preSignature := preSignatures[partyIDs[0]]
+		if msg.RoundNumber != protocolFullRounds {
+			assert.Error(t, VerifySigmaShare(preSignature, msg, messageHash))
+			continue
+		}
+		assert.NoError(t, VerifySigmaShare(preSignature, msg, messageHash))
+		assert.Error(t, VerifySigmaShare(preSignature, msg, []byte("other message")))
+		forged := *msg
+		forged.From = partyIDs[0]
+		if msg.From == forged.From {
+			forged.From = partyIDs[1]
+		}
+		assert.Error(t, VerifySigmaShare(preSignature, &forged, messageHash))
+		verified++
+	}
+	assert.Equal(t, N, verified)
+}
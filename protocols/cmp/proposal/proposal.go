@@ -0,0 +1,110 @@
+// Package proposal implements a short protocol in which an initiator proposes the parameters of a signing session
+// to all parties of a config, and every party accepts or rejects them.
+// Its output binds the signers and the message to the transcript of the negotiation,
+// so that they need not be agreed upon out of band.
+package proposal
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
+)
+
+// Rounds is the number of rounds of the protocol.
+const Rounds round.Number = 3
+
+// SignProposal contains the parameters of a signing session chosen by the initiator.
+type SignProposal struct {
+	// Signers is the subset of the parties of the config which take part in the signing session.
+	Signers []party.ID
+	// MessageHash is the hash of the message to be signed.
+	MessageHash []byte
+	// SessionID is an optional identifier chosen by the initiator, which is included in Agreement.SessionID.
+	SessionID []byte
+	// Deadline is the Unix time in seconds after which the proposal must be rejected. Zero means no deadline.
+	Deadline int64
+}
+
+// Validate checks that the proposal can be used with c.
+func (p *SignProposal) Validate(c *config.Config) error {
+	if p == nil {
+		return errors.New("proposal: empty proposal")
+	}
+	signers := party.NewIDSlice(p.Signers)
+	if !signers.Valid() {
+		return errors.New("proposal: signers contains duplicates")
+	}
+	if !config.ValidThreshold(c.Threshold, c.Weights().Total(signers)) {
+		return fmt.Errorf("proposal: %d signers is not enough for threshold %d", len(signers), c.Threshold)
+	}
+	for _, id := range signers {
+		if _, ok := c.Public[id]; !ok {
+			return fmt.Errorf("proposal: signer %s is not a party of this config", id)
+		}
+	}
+	if len(p.MessageHash) == 0 {
+		return errors.New("proposal: message hash is empty")
+	}
+	return nil
+}
+
+// Agreement is the output of the protocol, and contains the parameters of the signing session accepted by all signers.
+type Agreement struct {
+	Initiator   party.ID
+	Signers     party.IDSlice
+	MessageHash []byte
+	// SessionID is derived from the transcript of the negotiation, and should be given to the handler of
+	// the signing session, so that the session is bound to the negotiation.
+	SessionID []byte
+}
+
+// Decide is called by every party once the proposal has been received, and before it is accepted.
+// Returning an error rejects the proposal, and the error is sent to the other parties as the reason.
+type Decide func(initiator party.ID, proposal *SignProposal) error
+
+// Start returns the StartFunc for the negotiation between all parties of c.
+// The initiator must provide the proposal, which is ignored for all other parties.
+// If decide is nil, all valid proposals are accepted.
+//
+// Returns *Agreement if all signers accepted the proposal. Otherwise, the protocol aborts
+// with the rejecting signers as culprits.
+func Start(c *config.Config, initiator party.ID, proposal *SignProposal, decide Decide) protocol.StartFunc {
+	return func(sessionID []byte) (round.Session, error) {
+		if _, ok := c.Public[initiator]; !ok {
+			return nil, fmt.Errorf("proposal: initiator %s is not a party of this config", initiator)
+		}
+		if c.ID == initiator {
+			if err := proposal.Validate(c); err != nil {
+				return nil, err
+			}
+		} else {
+			proposal = nil
+		}
+		info := round.Info{
+			ProtocolID:       "cmp/sign-proposal",
+			FinalRoundNumber: Rounds,
+			SelfID:           c.ID,
+			PartyIDs:         c.PartyIDs(),
+			Threshold:        c.Threshold,
+			Group:            c.Group,
+			Epoch:            c.Epoch,
+		}
+		helper, err := round.NewSession(info, sessionID, nil, c, initiator)
+		if err != nil {
+			return nil, fmt.Errorf("proposal: %w", err)
+		}
+		return &round1{
+			Helper:    helper,
+			config:    c,
+			initiator: initiator,
+			proposal:  proposal,
+			decide:    decide,
+			now:       platform.Now,
+		}, nil
+	}
+}
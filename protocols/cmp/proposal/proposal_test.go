@@ -0,0 +1,101 @@
+package proposal
+
+import (
+	"errors"
+	mrand "math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/internal/test"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
+)
+
+func run(t *testing.T, configs map[party.ID]*config.Config, partyIDs party.IDSlice, proposal *SignProposal, decide Decide) []round.Session {
+	initiator := partyIDs[0]
+	rounds := make([]round.Session, 0, len(partyIDs))
+	for _, id := range partyIDs {
+		r, err := Start(configs[id], initiator, proposal, decide)([]byte("session"))
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	for {
+		err, done := test.Rounds(rounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	return rounds
+}
+
+func TestProposal(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+
+	N, T := 4, 2
+	configs, partyIDs := test.GenerateConfig(curve.Secp256k1{}, N, T, mrand.New(mrand.NewSource(1)), pl)
+	signers := partyIDs[1:]
+	proposal := &SignProposal{
+		Signers:     signers,
+		MessageHash: []byte("hash"),
+		Deadline:    time.Now().Add(time.Hour).Unix(),
+	}
+
+	t.Run("accept", func(t *testing.T) {
+		rounds := run(t, configs, partyIDs, proposal, nil)
+		var sessionID []byte
+		for _, r := range rounds {
+			require.IsType(t, &round.Output{}, r)
+			agreement := r.(*round.Output).Result.(*Agreement)
+			assert.Equal(t, partyIDs[0], agreement.Initiator)
+			assert.Equal(t, party.NewIDSlice(signers), agreement.Signers)
+			assert.Equal(t, proposal.MessageHash, agreement.MessageHash)
+			if sessionID == nil {
+				sessionID = agreement.SessionID
+			}
+			assert.Equal(t, sessionID, agreement.SessionID)
+		}
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		rejecter := signers[0]
+		decide := func(party.ID, *SignProposal) error { return nil }
+		rounds := make([]round.Session, 0, N)
+		for _, id := range partyIDs {
+			d := decide
+			if id == rejecter {
+				d = func(party.ID, *SignProposal) error { return errors.New("policy") }
+			}
+			r, err := Start(configs[id], partyIDs[0], proposal, d)([]byte("session"))
+			require.NoError(t, err)
+			rounds = append(rounds, r)
+		}
+		for {
+			err, done := test.Rounds(rounds, nil)
+			require.NoError(t, err)
+			if done {
+				break
+			}
+		}
+		for _, r := range rounds {
+			require.IsType(t, &round.Abort{}, r)
+			assert.Equal(t, []party.ID{rejecter}, r.(*round.Abort).Culprits)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		c := configs[partyIDs[0]]
+		_, err := Start(c, partyIDs[0], &SignProposal{Signers: partyIDs[:T], MessageHash: []byte("hash")}, nil)(nil)
+		assert.Error(t, err, "too few signers")
+		_, err = Start(c, partyIDs[0], &SignProposal{Signers: signers}, nil)(nil)
+		assert.Error(t, err, "empty hash")
+		_, err = Start(c, "unknown", proposal, nil)(nil)
+		assert.Error(t, err, "unknown initiator")
+	})
+}
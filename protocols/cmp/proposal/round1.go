@@ -0,0 +1,44 @@
+package proposal
+
+import (
+	"time"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
+)
+
+var _ round.Round = (*round1)(nil)
+
+type round1 struct {
+	*round.Helper
+
+	config    *config.Config
+	initiator party.ID
+	// proposal is only set for the initiator
+	proposal *SignProposal
+	decide   Decide
+	now      func() time.Time
+}
+
+// VerifyMessage implements round.Round.
+func (round1) VerifyMessage(round.Message) error { return nil }
+
+// StoreMessage implements round.Round.
+func (round1) StoreMessage(round.Message) error { return nil }
+
+// Finalize implements round.Round
+//
+// - the initiator broadcasts the proposal, all other parties broadcast an empty message.
+func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
+	if err := r.BroadcastMessage(out, &broadcast2{Proposal: r.proposal}); err != nil {
+		return r, err
+	}
+	return &round2{round1: r}, nil
+}
+
+// MessageContent implements round.Round.
+func (round1) MessageContent() round.Content { return nil }
+
+// Number implements round.Round.
+func (round1) Number() round.Number { return 1 }
@@ -0,0 +1,89 @@
+package proposal
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+)
+
+var _ round.Round = (*round2)(nil)
+
+type round2 struct {
+	*round1
+}
+
+type broadcast2 struct {
+	round.ReliableBroadcastContent
+	// Proposal is only set by the initiator.
+	Proposal *SignProposal
+}
+
+// StoreBroadcastMessage implements round.BroadcastRound.
+//
+// - store the proposal of the initiator, and check that no other party sent one.
+func (r *round2) StoreBroadcastMessage(msg round.Message) error {
+	body, ok := msg.Content.(*broadcast2)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+	if msg.From != r.initiator {
+		if body.Proposal != nil {
+			return errors.New("proposal sent by a party other than the initiator")
+		}
+		return nil
+	}
+	if err := body.Proposal.Validate(r.config); err != nil {
+		return err
+	}
+	r.proposal = body.Proposal
+	return nil
+}
+
+// VerifyMessage implements round.Round.
+func (round2) VerifyMessage(round.Message) error { return nil }
+
+// StoreMessage implements round.Round.
+func (round2) StoreMessage(round.Message) error { return nil }
+
+// Finalize implements round.Round
+//
+// - decide whether to accept the proposal, and broadcast the decision.
+func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
+	var reason string
+	if err := r.check(); err != nil {
+		reason = err.Error()
+	}
+	if err := r.BroadcastMessage(out, &broadcast3{Accept: reason == "", Reason: reason}); err != nil {
+		return r, err
+	}
+	return &round3{
+		round2:   r,
+		accepted: map[string]bool{},
+		reasons:  map[string]string{},
+	}, nil
+}
+
+// check returns an error if this party rejects the proposal.
+func (r *round2) check() error {
+	if r.proposal.Deadline != 0 && r.now().After(time.Unix(r.proposal.Deadline, 0)) {
+		return fmt.Errorf("deadline %s has passed", time.Unix(r.proposal.Deadline, 0).UTC().Format(time.RFC3339))
+	}
+	if r.decide != nil {
+		return r.decide(r.initiator, r.proposal)
+	}
+	return nil
+}
+
+// RoundNumber implements round.Content.
+func (broadcast2) RoundNumber() round.Number { return 2 }
+
+// BroadcastContent implements round.BroadcastRound.
+func (round2) BroadcastContent() round.BroadcastContent { return &broadcast2{} }
+
+// MessageContent implements round.Round.
+func (round2) MessageContent() round.Content { return nil }
+
+// Number implements round.Round.
+func (round2) Number() round.Number { return 2 }
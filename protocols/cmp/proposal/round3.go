@@ -0,0 +1,103 @@
+package proposal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+var _ round.Round = (*round3)(nil)
+
+type round3 struct {
+	*round2
+	// accepted and reasons are indexed by party.ID as a string, so that they can be written to the hash in order.
+	accepted map[string]bool
+	reasons  map[string]string
+}
+
+type broadcast3 struct {
+	round.ReliableBroadcastContent
+	Accept bool
+	Reason string
+}
+
+// StoreBroadcastMessage implements round.BroadcastRound.
+func (r *round3) StoreBroadcastMessage(msg round.Message) error {
+	body, ok := msg.Content.(*broadcast3)
+	if !ok || body == nil {
+		return round.ErrInvalidContent
+	}
+	if body.Accept && body.Reason != "" {
+		return errors.New("accepting party gave a reason")
+	}
+	r.accepted[string(msg.From)] = body.Accept
+	r.reasons[string(msg.From)] = body.Reason
+	return nil
+}
+
+// VerifyMessage implements round.Round.
+func (round3) VerifyMessage(round.Message) error { return nil }
+
+// StoreMessage implements round.Round.
+func (round3) StoreMessage(round.Message) error { return nil }
+
+// Finalize implements round.Round
+//
+// - abort if any signer rejected the proposal.
+// - otherwise, derive the session ID of the signing session from the proposal and all decisions.
+func (r *round3) Finalize(chan<- *round.Message) (round.Session, error) {
+	r.accepted[string(r.SelfID())] = r.check() == nil
+
+	signers := party.NewIDSlice(r.proposal.Signers)
+	var rejected []party.ID
+	var reasons []string
+	for _, id := range signers {
+		if !r.accepted[string(id)] {
+			rejected = append(rejected, id)
+			if reason := r.reasons[string(id)]; reason != "" {
+				reasons = append(reasons, fmt.Sprintf("%s: %s", id, reason))
+			}
+		}
+	}
+	if len(rejected) > 0 {
+		return r.AbortRound(fmt.Errorf("proposal rejected by signers (%s)", strings.Join(reasons, "; ")), rejected...), nil
+	}
+
+	h := r.Hash()
+	_ = h.WriteAny(
+		&hash.BytesWithDomain{TheDomain: "Initiator", Bytes: []byte(r.initiator)},
+		signers,
+		&hash.BytesWithDomain{TheDomain: "Message Hash", Bytes: r.proposal.MessageHash},
+		&hash.BytesWithDomain{TheDomain: "Proposal Session ID", Bytes: r.proposal.SessionID},
+	)
+	for _, id := range r.PartyIDs() {
+		decision := []byte{0}
+		if r.accepted[string(id)] {
+			decision[0] = 1
+		}
+		_ = h.WriteAny(id, &hash.BytesWithDomain{TheDomain: "Decision", Bytes: decision})
+	}
+
+	return r.ResultRound(&Agreement{
+		Initiator:   r.initiator,
+		Signers:     signers,
+		MessageHash: r.proposal.MessageHash,
+		SessionID:   h.Sum()[:32],
+	}), nil
+}
+
+// RoundNumber implements round.Content.
+func (broadcast3) RoundNumber() round.Number { return 3 }
+
+// BroadcastContent implements round.BroadcastRound.
+func (round3) BroadcastContent() round.BroadcastContent { return &broadcast3{} }
+
+// MessageContent implements round.Round.
+func (round3) MessageContent() round.Content { return nil }
+
+// Number implements round.Round.
+func (round3) Number() round.Number { return 3 }
@@ -0,0 +1,54 @@
+package cmp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
+)
+
+// DecodeResult decodes a result persisted with MultiHandler.ResultEnvelope by one of the protocols of this package.
+// Depending on the kind of result, it returns a *Config, an *ecdsa.Signature or an *ecdsa.PreSignature,
+// in the same way as MultiHandler.Result.
+func DecodeResult(e *protocol.ResultEnvelope) (interface{}, error) {
+	if e == nil {
+		return nil, fmt.Errorf("cmp: nil result envelope")
+	}
+	if !strings.HasPrefix(e.Protocol, "cmp/") {
+		return nil, fmt.Errorf("cmp: result of protocol %q", e.Protocol)
+	}
+	group, err := curve.ByName(e.Group)
+	if err != nil {
+		return nil, fmt.Errorf("cmp: %w", err)
+	}
+
+	var result interface{}
+	switch e.Kind {
+	case protocol.ResultConfig:
+		result = EmptyConfig(group)
+	case protocol.ResultSignature:
+		sig := ecdsa.EmptySignature(group)
+		result = &sig
+	case protocol.ResultPreSignature:
+		result = ecdsa.EmptyPreSignature(group)
+	default:
+		return nil, fmt.Errorf("cmp: cannot decode result of kind %q from %s", e.Kind, e.Protocol)
+	}
+	if err = e.Decode(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// VerifyFinalSignature returns nil if signature is a valid signature of hash by the public key of public,
+// serialized with the given encoding, see ecdsa.VerifyEncoded.
+// It can be used by relayers and auditors who only hold the public part of a Config.
+func VerifyFinalSignature(public *config.PublicConfig, hash, signature []byte, encoding ecdsa.SignatureEncoding) error {
+	if public == nil {
+		return fmt.Errorf("cmp: nil public config")
+	}
+	return ecdsa.VerifyEncoded(public.PublicPoint(), hash, signature, encoding)
+}
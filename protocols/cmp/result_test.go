@@ -0,0 +1,37 @@
+package cmp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+)
+
+// decodeEnvelope persists the result of h as JSON, and decodes it with DecodeResult.
+func decodeEnvelope(t *testing.T, h *protocol.MultiHandler) interface{} {
+	e, err := h.ResultEnvelope()
+	require.NoError(t, err)
+	data, err := json.Marshal(e)
+	require.NoError(t, err)
+
+	var decoded protocol.ResultEnvelope
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	r, err := DecodeResult(&decoded)
+	require.NoError(t, err)
+	return r
+}
+
+func TestDecodeResult(t *testing.T) {
+	_, err := DecodeResult(nil)
+	assert.Error(t, err)
+	_, err = DecodeResult(&protocol.ResultEnvelope{ResultMetadata: protocol.ResultMetadata{
+		Protocol: "frost/keygen-threshold", Kind: protocol.ResultConfig, Group: "secp256k1",
+	}})
+	assert.Error(t, err, "results of other protocols should be rejected")
+	_, err = DecodeResult(&protocol.ResultEnvelope{ResultMetadata: protocol.ResultMetadata{
+		Protocol: "cmp/sign-proposal", Kind: protocol.ResultUnknown, Group: "secp256k1",
+	}})
+	assert.Error(t, err, "results of unknown kind should be rejected")
+}
@@ -0,0 +1,108 @@
+package sign
+
+import (
+	"github.com/taurusgroup/multi-party-sig/internal/cbormap"
+	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
+	zkenc "github.com/taurusgroup/multi-party-sig/pkg/zk/enc"
+)
+
+// The contents of this protocol are received from every signer in every session, so they are decoded without
+// reflection, see round.ContentUnmarshaler. Fields which are not set by the round's content constructor
+// are allocated as cbor.Unmarshal would, keys are matched to fields as it does, see cbormap.Key,
+// and unknown keys are ignored.
+
+// UnmarshalContent implements round.ContentUnmarshaler.
+func (x *broadcast2) UnmarshalContent(data []byte) error {
+	return cbormap.Decode(data, func(key string, value []byte) error {
+		switch cbormap.Key(key, "K", "G") {
+		case "K":
+			x.K = new(paillier.Ciphertext)
+			return cbormap.Binary(value, x.K)
+		case "G":
+			x.G = new(paillier.Ciphertext)
+			return cbormap.Binary(value, x.G)
+		}
+		return nil
+	})
+}
+
+// UnmarshalContent implements round.ContentUnmarshaler.
+func (x *message2) UnmarshalContent(data []byte) error {
+	return cbormap.Decode(data, func(key string, value []byte) error {
+		if cbormap.Key(key, "ProofEnc") != "" {
+			x.ProofEnc = zkenc.Empty(nil)
+			return x.ProofEnc.DecodeCBOR(value)
+		}
+		return nil
+	})
+}
+
+// UnmarshalContent implements round.ContentUnmarshaler.
+func (x *broadcast3) UnmarshalContent(data []byte) error {
+	return cbormap.Decode(data, func(key string, value []byte) error {
+		if cbormap.Key(key, "BigGammaShare") != "" {
+			return cbormap.Binary(value, x.BigGammaShare)
+		}
+		return nil
+	})
+}
+
+// UnmarshalContent implements round.ContentUnmarshaler.
+func (x *message3) UnmarshalContent(data []byte) error {
+	return cbormap.Decode(data, func(key string, value []byte) error {
+		switch cbormap.Key(key, "DeltaD", "DeltaF", "DeltaProof", "ChiD", "ChiF", "ChiProof", "ProofLog") {
+		case "DeltaD":
+			x.DeltaD = new(paillier.Ciphertext)
+			return cbormap.Binary(value, x.DeltaD)
+		case "DeltaF":
+			x.DeltaF = new(paillier.Ciphertext)
+			return cbormap.Binary(value, x.DeltaF)
+		case "DeltaProof":
+			return x.DeltaProof.DecodeCBOR(value)
+		case "ChiD":
+			x.ChiD = new(paillier.Ciphertext)
+			return cbormap.Binary(value, x.ChiD)
+		case "ChiF":
+			x.ChiF = new(paillier.Ciphertext)
+			return cbormap.Binary(value, x.ChiF)
+		case "ChiProof":
+			return x.ChiProof.DecodeCBOR(value)
+		case "ProofLog":
+			return x.ProofLog.DecodeCBOR(value)
+		}
+		return nil
+	})
+}
+
+// UnmarshalContent implements round.ContentUnmarshaler.
+func (x *broadcast4) UnmarshalContent(data []byte) error {
+	return cbormap.Decode(data, func(key string, value []byte) error {
+		switch cbormap.Key(key, "DeltaShare", "BigDeltaShare") {
+		case "DeltaShare":
+			return cbormap.Binary(value, x.DeltaShare)
+		case "BigDeltaShare":
+			return cbormap.Binary(value, x.BigDeltaShare)
+		}
+		return nil
+	})
+}
+
+// UnmarshalContent implements round.ContentUnmarshaler.
+func (x *message4) UnmarshalContent(data []byte) error {
+	return cbormap.Decode(data, func(key string, value []byte) error {
+		if cbormap.Key(key, "ProofLog") != "" {
+			return x.ProofLog.DecodeCBOR(value)
+		}
+		return nil
+	})
+}
+
+// UnmarshalContent implements round.ContentUnmarshaler.
+func (x *broadcast5) UnmarshalContent(data []byte) error {
+	return cbormap.Decode(data, func(key string, value []byte) error {
+		if cbormap.Key(key, "SigmaShare") != "" {
+			return cbormap.Binary(value, x.SigmaShare)
+		}
+		return nil
+	})
+}
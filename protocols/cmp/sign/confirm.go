@@ -0,0 +1,38 @@
+package sign
+
+import (
+	"errors"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// ErrSigningRefused is the error of the signing protocol when a Confirmer declined the request.
+var ErrSigningRefused = errors.New("sign: signing refused")
+
+// Metadata describes a signing request, as presented to a Confirmer.
+type Metadata struct {
+	// ProtocolID and SSID identify the execution of the protocol.
+	ProtocolID string
+	SSID       []byte
+	SelfID     party.ID
+	Signers    party.IDSlice
+	// PublicKey is the public key the signature will be valid for.
+	PublicKey curve.Point
+}
+
+// Confirmer lets an application inspect a signing request, for example to show it to a user,
+// before any secret-dependent computation is performed.
+type Confirmer interface {
+	// ConfirmSigning returns true if messageHash may be signed.
+	// If it returns false or an error, the protocol is aborted and the other signers are notified.
+	ConfirmSigning(messageHash []byte, metadata Metadata) (bool, error)
+}
+
+// ConfirmFunc is a function implementing Confirmer.
+type ConfirmFunc func(messageHash []byte, metadata Metadata) (bool, error)
+
+// ConfirmSigning implements Confirmer.
+func (f ConfirmFunc) ConfirmSigning(messageHash []byte, metadata Metadata) (bool, error) {
+	return f(messageHash, metadata)
+}
@@ -0,0 +1,23 @@
+package sign
+
+import (
+	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+)
+
+func init() {
+	for _, protocolID := range []string{protocolSignID, ProtocolEmergencySignID} {
+		protocolID := protocolID
+		protocol.RegisterDescription(protocolID, func() *protocol.Description { return describe(protocolID) })
+	}
+}
+
+// describe returns the description of the signing protocol with the given ID, see protocol.Describe.
+func describe(protocolID string) *protocol.Description {
+	r1 := &round1{Helper: round.NewDescriptionHelper(protocolID, protocolSignRounds)}
+	r2 := &round2{round1: r1}
+	r3 := &round3{round2: r2}
+	r4 := &round4{round3: r3}
+	r5 := &round5{round4: r4}
+	return protocol.DescribeRounds(protocolID, r1, r2, r3, r4, r5)
+}
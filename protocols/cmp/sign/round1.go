@@ -1,15 +1,19 @@
 package sign
 
 import (
-	"crypto/rand"
+	"context"
+	"fmt"
 
 	"github.com/taurusgroup/multi-party-sig/internal/round"
+	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
 	"github.com/taurusgroup/multi-party-sig/pkg/party"
 	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 	zkenc "github.com/taurusgroup/multi-party-sig/pkg/zk/enc"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
 )
 
 var _ round.Round = (*round1)(nil)
@@ -26,6 +30,12 @@ type round1 struct {
 	ECDSA          map[party.ID]curve.Point
 
 	Message []byte
+
+	confirm Confirmer
+	policy  *config.Policy
+	request *config.PolicyRequest
+	// check is called on the signature in the last round, if not nil.
+	check func(messageHash []byte, sig *ecdsa.Signature) error
 }
 
 // VerifyMessage implements round.Round.
@@ -36,6 +46,7 @@ func (round1) StoreMessage(round.Message) error { return nil }
 
 // Finalize implements round.Round
 //
+// - evaluate the Policy, if any, and ask the Confirmer, if any, whether the message may be signed.
 // - sample kᵢ, γᵢ <- 𝔽,
 // - Γᵢ = [γᵢ]⋅G
 // - Gᵢ = Encᵢ(γᵢ;νᵢ)
@@ -49,14 +60,35 @@ func (round1) StoreMessage(round.Message) error { return nil }
 // In the next round, we send a hash of all the {Kⱼ,Gⱼ}ⱼ.
 // In two rounds, we compare the hashes received and if they are different then we abort.
 func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
+	if r.policy != nil {
+		if err := r.policy.Evaluate(r.PartyIDs(), r.request); err != nil {
+			return r.AbortRound(fmt.Errorf("%w: %v", ErrSigningRefused, err), r.SelfID()), nil
+		}
+	}
+	if r.confirm != nil {
+		ok, err := r.confirm.ConfirmSigning(r.Message, Metadata{
+			ProtocolID: r.ProtocolID(),
+			SSID:       r.SSID(),
+			SelfID:     r.SelfID(),
+			Signers:    r.PartyIDs(),
+			PublicKey:  r.PublicKey,
+		})
+		if err != nil {
+			return r.AbortRound(fmt.Errorf("%w: %v", ErrSigningRefused, err), r.SelfID()), nil
+		}
+		if !ok {
+			return r.AbortRound(ErrSigningRefused, r.SelfID()), nil
+		}
+	}
+
 	// γᵢ <- 𝔽,
 	// Γᵢ = [γᵢ]⋅G
-	GammaShare, BigGammaShare := sample.ScalarPointPair(rand.Reader, r.Group())
+	GammaShare, BigGammaShare := sample.ScalarPointPair(platform.Reader, r.Group())
 	// Gᵢ = Encᵢ(γᵢ;νᵢ)
 	G, GNonce := r.Paillier[r.SelfID()].Enc(curve.MakeInt(GammaShare))
 
 	// kᵢ <- 𝔽,
-	KShare := sample.Scalar(rand.Reader, r.Group())
+	KShare := sample.Scalar(platform.Reader, r.Group())
 	// Kᵢ = Encᵢ(kᵢ;ρᵢ)
 	K, KNonce := r.Paillier[r.SelfID()].Enc(curve.MakeInt(KShare))
 
@@ -65,9 +97,9 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	if err := r.BroadcastMessage(out, &broadcastMsg); err != nil {
 		return r, err
 	}
-	errors := r.Pool.Parallelize(len(otherIDs), func(i int) interface{} {
+	if err := r.Pool.ParallelizeErr(context.Background(), len(otherIDs), func(i int) error {
 		j := otherIDs[i]
-		proof := zkenc.NewProof(r.Group(), r.HashForID(r.SelfID()), zkenc.Public{
+		proof := zkenc.NewProof(r.Group(), r.HashForID(r.SelfID()).ForkLabel("zkenc:"+string(j)), zkenc.Public{
 			K:      K,
 			Prover: r.Paillier[r.SelfID()],
 			Aux:    r.Pedersen[j],
@@ -76,18 +108,11 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 			Rho: KNonce,
 		})
 
-		err := r.SendMessage(out, &message2{
+		return r.SendMessage(out, &message2{
 			ProofEnc: proof,
 		}, j)
-		if err != nil {
-			return err
-		}
-		return nil
-	})
-	for _, err := range errors {
-		if err != nil {
-			return r, err.(error)
-		}
+	}); err != nil {
+		return r, err
 	}
 
 	return &round2{
@@ -1,12 +1,13 @@
 package sign
 
 import (
+	"context"
 	"errors"
 
 	"github.com/cronokirby/saferith"
-	"github.com/taurusgroup/multi-party-sig/internal/mta"
 	"github.com/taurusgroup/multi-party-sig/internal/round"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/mta"
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
 	"github.com/taurusgroup/multi-party-sig/pkg/party"
 	zkenc "github.com/taurusgroup/multi-party-sig/pkg/zk/enc"
@@ -85,7 +86,7 @@ func (r *round2) VerifyMessage(msg round.Message) error {
 		return round.ErrNilFields
 	}
 
-	if !body.ProofEnc.Verify(r.Group(), r.HashForID(from), zkenc.Public{
+	if !body.ProofEnc.Verify(r.Group(), r.HashForID(from).ForkLabel("zkenc:"+string(to)), zkenc.Public{
 		K:      r.K[from],
 		Prover: r.Paillier[from],
 		Aux:    r.Pedersen[to],
@@ -111,22 +112,19 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 	}
 
 	otherIDs := r.OtherPartyIDs()
-	type mtaOut struct {
-		err       error
-		DeltaBeta *saferith.Int
-		ChiBeta   *saferith.Int
-	}
-	mtaOuts := r.Pool.Parallelize(len(otherIDs), func(i int) interface{} {
+	DeltaBetas := make([]*saferith.Int, len(otherIDs))
+	ChiBetas := make([]*saferith.Int, len(otherIDs))
+	if err := r.Pool.ParallelizeErr(context.Background(), len(otherIDs), func(i int) error {
 		j := otherIDs[i]
 
-		DeltaBeta, DeltaD, DeltaF, DeltaProof := mta.ProveAffG(r.Group(), r.HashForID(r.SelfID()),
+		DeltaBeta, DeltaD, DeltaF, DeltaProof := mta.ProveAffG(r.Group(), r.HashForID(r.SelfID()).ForkLabel("zkaffg:delta:"+string(j)),
 			r.GammaShare, r.BigGammaShare[r.SelfID()], r.K[j],
 			r.SecretPaillier, r.Paillier[j], r.Pedersen[j])
 		ChiBeta, ChiD, ChiF, ChiProof := mta.ProveAffG(r.Group(),
-			r.HashForID(r.SelfID()), curve.MakeInt(r.SecretECDSA), r.ECDSA[r.SelfID()], r.K[j],
+			r.HashForID(r.SelfID()).ForkLabel("zkaffg:chi:"+string(j)), curve.MakeInt(r.SecretECDSA), r.ECDSA[r.SelfID()], r.K[j],
 			r.SecretPaillier, r.Paillier[j], r.Pedersen[j])
 
-		proof := zklogstar.NewProof(r.Group(), r.HashForID(r.SelfID()),
+		proof := zklogstar.NewProof(r.Group(), r.HashForID(r.SelfID()).ForkLabel("zklogstar:gamma:"+string(j)),
 			zklogstar.Public{
 				C:      r.G[r.SelfID()],
 				X:      r.BigGammaShare[r.SelfID()],
@@ -137,7 +135,8 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 				Rho: r.GNonce,
 			})
 
-		err := r.SendMessage(out, &message3{
+		DeltaBetas[i], ChiBetas[i] = DeltaBeta, ChiBeta
+		return r.SendMessage(out, &message3{
 			DeltaD:     DeltaD,
 			DeltaF:     DeltaF,
 			DeltaProof: DeltaProof,
@@ -146,22 +145,14 @@ func (r *round2) Finalize(out chan<- *round.Message) (round.Session, error) {
 			ChiProof:   ChiProof,
 			ProofLog:   proof,
 		}, j)
-		return mtaOut{
-			err:       err,
-			DeltaBeta: DeltaBeta,
-			ChiBeta:   ChiBeta,
-		}
-	})
-	DeltaShareBetas := make(map[party.ID]*saferith.Int, len(otherIDs)-1)
-	ChiShareBetas := make(map[party.ID]*saferith.Int, len(otherIDs)-1)
-	for idx, mtaOutRaw := range mtaOuts {
-		j := otherIDs[idx]
-		m := mtaOutRaw.(mtaOut)
-		if m.err != nil {
-			return r, m.err
-		}
-		DeltaShareBetas[j] = m.DeltaBeta
-		ChiShareBetas[j] = m.ChiBeta
+	}); err != nil {
+		return r, err
+	}
+	DeltaShareBetas := make(map[party.ID]*saferith.Int, len(otherIDs))
+	ChiShareBetas := make(map[party.ID]*saferith.Int, len(otherIDs))
+	for idx, j := range otherIDs {
+		DeltaShareBetas[j] = DeltaBetas[idx]
+		ChiShareBetas[j] = ChiBetas[idx]
 	}
 
 	return &round3{
@@ -1,6 +1,7 @@
 package sign
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -68,7 +69,7 @@ func (r *round3) VerifyMessage(msg round.Message) error {
 		return round.ErrInvalidContent
 	}
 
-	if !body.DeltaProof.Verify(r.HashForID(from), zkaffg.Public{
+	if !body.DeltaProof.Verify(r.HashForID(from).ForkLabel("zkaffg:delta:"+string(to)), zkaffg.Public{
 		Kv:       r.K[to],
 		Dv:       body.DeltaD,
 		Fp:       body.DeltaF,
@@ -80,7 +81,7 @@ func (r *round3) VerifyMessage(msg round.Message) error {
 		return errors.New("failed to validate affg proof for Delta MtA")
 	}
 
-	if !body.ChiProof.Verify(r.HashForID(from), zkaffg.Public{
+	if !body.ChiProof.Verify(r.HashForID(from).ForkLabel("zkaffg:chi:"+string(to)), zkaffg.Public{
 		Kv:       r.K[to],
 		Dv:       body.ChiD,
 		Fp:       body.ChiF,
@@ -92,7 +93,7 @@ func (r *round3) VerifyMessage(msg round.Message) error {
 		return errors.New("failed to validate affg proof for Chi MtA")
 	}
 
-	if !body.ProofLog.Verify(r.HashForID(from), zklogstar.Public{
+	if !body.ProofLog.Verify(r.HashForID(from).ForkLabel("zklogstar:gamma:"+string(to)), zklogstar.Public{
 		C:      r.G[from],
 		X:      r.BigGammaShare[from],
 		Prover: r.Paillier[from],
@@ -144,6 +145,8 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 	// Δᵢ = [kᵢ]Γ
 	KShareInt := curve.MakeInt(r.KShare)
 	BigDeltaShare := r.KShare.Act(Gamma)
+	// Γ and Δᵢ are hashed in the proof for every other party
+	curve.NormalizePoints(Gamma, BigDeltaShare)
 
 	// δᵢ = γᵢ kᵢ
 	DeltaShare := new(saferith.Int).Mul(r.GammaShare, KShareInt, -1)
@@ -175,10 +178,10 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 	}
 
 	otherIDs := r.OtherPartyIDs()
-	errs := r.Pool.Parallelize(len(otherIDs), func(i int) interface{} {
+	if err := r.Pool.ParallelizeErr(context.Background(), len(otherIDs), func(i int) error {
 		j := otherIDs[i]
 
-		proofLog := zklogstar.NewProof(r.Group(), r.HashForID(r.SelfID()), zklogstar.Public{
+		proofLog := zklogstar.NewProof(r.Group(), r.HashForID(r.SelfID()).ForkLabel("zklogstar:delta:"+string(j)), zklogstar.Public{
 			C:      r.K[r.SelfID()],
 			X:      BigDeltaShare,
 			G:      Gamma,
@@ -186,18 +189,11 @@ func (r *round3) Finalize(out chan<- *round.Message) (round.Session, error) {
 			Aux:    r.Pedersen[j],
 		}, zkPrivate)
 
-		err := r.SendMessage(out, &message4{
+		return r.SendMessage(out, &message4{
 			ProofLog: proofLog,
 		}, j)
-		if err != nil {
-			return err
-		}
-		return nil
-	})
-	for _, err := range errs {
-		if err != nil {
-			return r, err.(error)
-		}
+	}); err != nil {
+		return r, err
 	}
 
 	return &round4{
@@ -71,7 +71,7 @@ func (r *round4) VerifyMessage(msg round.Message) error {
 		Prover: r.Paillier[from],
 		Aux:    r.Pedersen[to],
 	}
-	if !body.ProofLog.Verify(r.HashForID(from), zkLogPublic) {
+	if !body.ProofLog.Verify(r.HashForID(from).ForkLabel("zklogstar:delta:"+string(to)), zkLogPublic) {
 		return errors.New("failed to validate log proof")
 	}
 
@@ -2,6 +2,7 @@ package sign
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/taurusgroup/multi-party-sig/internal/round"
 	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
@@ -63,6 +64,7 @@ func (round5) StoreMessage(round.Message) error { return nil }
 //
 // - compute σ = ∑ⱼ σⱼ
 // - verify signature.
+// - run the additional check, if any.
 func (r *round5) Finalize(chan<- *round.Message) (round.Session, error) {
 	// compute σ = ∑ⱼ σⱼ
 	Sigma := r.Group().NewScalar()
@@ -79,6 +81,12 @@ func (r *round5) Finalize(chan<- *round.Message) (round.Session, error) {
 		return r.AbortRound(errors.New("failed to validate signature")), nil
 	}
 
+	if r.check != nil {
+		if err := r.check(r.Message, signature); err != nil {
+			return r.AbortRound(fmt.Errorf("signature check failed: %w", err)), nil
+		}
+	}
+
 	return r.ResultRound(signature), nil
 }
 
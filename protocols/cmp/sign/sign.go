@@ -1,13 +1,15 @@
 package sign
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 
 	"github.com/taurusgroup/multi-party-sig/internal/round"
 	"github.com/taurusgroup/multi-party-sig/internal/types"
+	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
+	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
-	"github.com/taurusgroup/multi-party-sig/pkg/math/polynomial"
 	"github.com/taurusgroup/multi-party-sig/pkg/paillier"
 	"github.com/taurusgroup/multi-party-sig/pkg/party"
 	"github.com/taurusgroup/multi-party-sig/pkg/pedersen"
@@ -22,7 +24,48 @@ const (
 	protocolSignRounds round.Number = 5
 )
 
+// ProtocolEmergencySignID is the protocol ID of signing sessions started with StartEmergencySign.
+const ProtocolEmergencySignID = "cmp/sign-emergency"
+
 func StartSign(config *config.Config, signers []party.ID, message []byte, pl *pool.Pool) protocol.StartFunc {
+	return StartSignWithConfirmation(config, signers, message, nil, pl)
+}
+
+// StartSignWithConfirmation is the same as StartSign, but calls confirm at the start of the first round.
+// If confirm is nil, all requests are accepted.
+func StartSignWithConfirmation(config *config.Config, signers []party.ID, message []byte, confirm Confirmer, pl *pool.Pool) protocol.StartFunc {
+	return StartSignWithPolicy(config, signers, message, nil, nil, confirm, pl)
+}
+
+// StartSignWithPolicy is the same as StartSignWithConfirmation, but additionally evaluates policy against request
+// at the start of the first round, before confirm is called.
+// The digests of the policy and request are bound to the session, so that the protocol fails
+// unless all signers use the same ones. If policy is nil, request is ignored.
+func StartSignWithPolicy(config *config.Config, signers []party.ID, message []byte, policy *config.Policy, request *config.PolicyRequest, confirm Confirmer, pl *pool.Pool) protocol.StartFunc {
+	return StartSignWithCheck(config, signers, message, policy, request, confirm, nil, pl)
+}
+
+// StartSignWithCheck is the same as StartSignWithPolicy, but calls check on the signature once it has been verified,
+// and aborts with its error instead of returning the signature. If check is nil, no additional check is performed.
+func StartSignWithCheck(config *config.Config, signers []party.ID, message []byte, policy *config.Policy, request *config.PolicyRequest, confirm Confirmer, check func(messageHash []byte, sig *ecdsa.Signature) error, pl *pool.Pool) protocol.StartFunc {
+	return start(protocolSignID, nil, config, signers, message, policy, request, confirm, check, pl)
+}
+
+// StartEmergencySign is the same as StartSignWithPolicy, but runs the emergency variant of the protocol,
+// meant for a larger quorum of signers after failedAttempts routine signing attempts have failed.
+// The protocol ID is ProtocolEmergencySignID, and failedAttempts is bound to the session,
+// so that emergency signatures can be told apart from routine ones in the transcripts, and by the Confirmer.
+// The policy is evaluated and bound to the session as in routine signing, so that escalating does not bypass it.
+func StartEmergencySign(config *config.Config, signers []party.ID, message []byte, failedAttempts int, policy *config.Policy, request *config.PolicyRequest, confirm Confirmer, pl *pool.Pool) protocol.StartFunc {
+	marker := make([]byte, 8)
+	binary.BigEndian.PutUint64(marker, uint64(failedAttempts))
+	aux := []hash.WriterToWithDomain{&hash.BytesWithDomain{TheDomain: "Emergency Signing Failed Attempts", Bytes: marker}}
+	return start(ProtocolEmergencySignID, aux, config, signers, message, policy, request, confirm, nil, pl)
+}
+
+// start returns the StartFunc of a signing session with the given protocol ID,
+// whose hash additionally includes aux.
+func start(protocolID string, aux []hash.WriterToWithDomain, config *config.Config, signers []party.ID, message []byte, policy *config.Policy, request *config.PolicyRequest, confirm Confirmer, check func(messageHash []byte, sig *ecdsa.Signature) error, pl *pool.Pool) protocol.StartFunc {
 	return func(sessionID []byte) (round.Session, error) {
 		group := config.Group
 
@@ -30,23 +73,41 @@ func StartSign(config *config.Config, signers []party.ID, message []byte, pl *po
 		if len(message) == 0 {
 			return nil, errors.New("sign.Create: message is nil")
 		}
+		if err := ecdsa.ValidateDigest(group, message); err != nil {
+			return nil, fmt.Errorf("sign.Create: %w", err)
+		}
+
+		auxInfo := []hash.WriterToWithDomain{config, types.SigningMessage(message)}
+		if policy != nil {
+			if request == nil {
+				return nil, errors.New("sign.Create: policy requires a request")
+			}
+			auxInfo = append(auxInfo,
+				&hash.BytesWithDomain{TheDomain: "Signing Policy", Bytes: policy.Digest()},
+				&hash.BytesWithDomain{TheDomain: "Signing Policy Request", Bytes: request.Digest()},
+			)
+		}
+
+		auxInfo = append(auxInfo, aux...)
 
 		info := round.Info{
-			ProtocolID:       protocolSignID,
+			ProtocolID:       protocolID,
 			FinalRoundNumber: protocolSignRounds,
 			SelfID:           config.ID,
 			PartyIDs:         signers,
 			Threshold:        config.Threshold,
 			Group:            config.Group,
+			Epoch:            config.Epoch,
+			Weights:          config.Weights(),
 		}
 
-		helper, err := round.NewSession(info, sessionID, pl, config, types.SigningMessage(message))
+		helper, err := round.NewSession(info, sessionID, pl, auxInfo...)
 		if err != nil {
 			return nil, fmt.Errorf("sign.Create: %w", err)
 		}
 
-		if !config.CanSign(helper.PartyIDs()) {
-			return nil, errors.New("sign.Create: signers is not a valid signing subset")
+		if err = config.ValidateSigners(helper.PartyIDs()); err != nil {
+			return nil, fmt.Errorf("sign.Create: signers is not a valid signing subset: %w", err)
 		}
 
 		// Scale public data
@@ -55,14 +116,14 @@ func StartSign(config *config.Config, signers []party.ID, message []byte, pl *po
 		Paillier := make(map[party.ID]*paillier.PublicKey, T)
 		Pedersen := make(map[party.ID]*pedersen.Parameters, T)
 		PublicKey := group.NewPoint()
-		lagrange := polynomial.Lagrange(group, signers)
+		additiveShares := config.PublicConfig().AdditivePublicShares(helper.PartyIDs())
 		// Scale own secret
-		SecretECDSA := group.NewScalar().Set(lagrange[config.ID]).Mul(config.ECDSA)
+		SecretECDSA := config.AdditiveShare(helper.PartyIDs())
 		SecretPaillier := config.Paillier
 		for _, j := range helper.PartyIDs() {
 			public := config.Public[j]
 			// scale public key share
-			ECDSA[j] = lagrange[j].Act(public.ECDSA)
+			ECDSA[j] = additiveShares[j]
 			Paillier[j] = public.Paillier
 			Pedersen[j] = public.Pedersen
 			PublicKey = PublicKey.Add(ECDSA[j])
@@ -77,6 +138,10 @@ func StartSign(config *config.Config, signers []party.ID, message []byte, pl *po
 			Pedersen:       Pedersen,
 			ECDSA:          ECDSA,
 			Message:        message,
+			confirm:        confirm,
+			policy:         policy,
+			request:        request,
+			check:          check,
 		}, nil
 	}
 }
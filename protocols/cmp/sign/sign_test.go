@@ -1,16 +1,23 @@
 package sign
 
 import (
+	"errors"
+	"fmt"
 	mrand "math/rand"
+	"reflect"
+	"sync"
 	"testing"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/taurusgroup/multi-party-sig/internal/round"
 	"github.com/taurusgroup/multi-party-sig/internal/test"
 	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
 	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/config"
 	"golang.org/x/crypto/sha3"
 )
 
@@ -30,7 +37,7 @@ func TestRound(t *testing.T) {
 	publicPoint := configs[partyIDs[0]].PublicPoint()
 
 	messageToSign := []byte("hello")
-	messageHash := make([]byte, 64)
+	messageHash := make([]byte, 32)
 	sha3.ShakeSum128(messageHash, messageToSign)
 
 	rounds := make([]round.Session, 0, N)
@@ -57,3 +64,349 @@ func TestRound(t *testing.T) {
 		assert.True(t, signature.Verify(publicPoint, messageHash), "expected valid signature")
 	}
 }
+
+func TestConfirmSigning(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	group := curve.Secp256k1{}
+
+	N, T := 3, 1
+	configs, partyIDs := test.GenerateConfig(group, N, T, mrand.New(mrand.NewSource(1)), pl)
+	messageHash := ecdsa.ProfileEthereum.HashMessage([]byte("hello"))
+
+	var seen []byte
+	var metadata Metadata
+	confirm := func(accept bool, err error) Confirmer {
+		return ConfirmFunc(func(hash []byte, m Metadata) (bool, error) {
+			seen, metadata = hash, m
+			return accept, err
+		})
+	}
+	c := configs[partyIDs[0]]
+
+	r, err := StartSignWithConfirmation(c, partyIDs, messageHash, confirm(true, nil), pl)(nil)
+	require.NoError(t, err)
+	next, err := r.Finalize(make(chan *round.Message, 2*N))
+	require.NoError(t, err)
+	assert.IsType(t, &round2{}, next)
+	assert.Equal(t, messageHash, seen)
+	assert.Equal(t, c.ID, metadata.SelfID)
+	assert.Equal(t, partyIDs, metadata.Signers)
+	assert.True(t, c.PublicPoint().Equal(metadata.PublicKey))
+
+	for _, refuse := range []Confirmer{confirm(false, nil), confirm(true, errors.New("locked"))} {
+		out := make(chan *round.Message, 2*N)
+		r, err = StartSignWithConfirmation(c, partyIDs, messageHash, refuse, pl)(nil)
+		require.NoError(t, err)
+		next, err = r.Finalize(out)
+		require.NoError(t, err)
+		require.IsType(t, &round.Abort{}, next)
+		abort := next.(*round.Abort)
+		assert.ErrorIs(t, abort.Err, ErrSigningRefused)
+		assert.Equal(t, []party.ID{c.ID}, abort.Culprits)
+		assert.Empty(t, out, "no messages should be sent after a refusal")
+	}
+}
+
+func TestSignPolicy(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	group := curve.Secp256k1{}
+
+	N, T := 3, 1
+	configs, partyIDs := test.GenerateConfig(group, N, T, mrand.New(mrand.NewSource(1)), pl)
+	c := configs[partyIDs[0]]
+	messageHash := ecdsa.ProfileEthereum.HashMessage([]byte("hello"))
+	policy := &config.Policy{MaxAmount: 10}
+
+	r, err := StartSignWithPolicy(c, partyIDs, messageHash, policy, &config.PolicyRequest{Amount: 10}, nil, pl)(nil)
+	require.NoError(t, err)
+	next, err := r.Finalize(make(chan *round.Message, 2*N))
+	require.NoError(t, err)
+	assert.IsType(t, &round2{}, next)
+
+	// parties with different policies do not share the same session
+	other, err := StartSignWithPolicy(c, partyIDs, messageHash, &config.Policy{MaxAmount: 11}, &config.PolicyRequest{Amount: 10}, nil, pl)(nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, r.SSID(), other.SSID())
+
+	r, err = StartSignWithPolicy(c, partyIDs, messageHash, policy, &config.PolicyRequest{Amount: 11}, nil, pl)(nil)
+	require.NoError(t, err)
+	next, err = r.Finalize(make(chan *round.Message, 2*N))
+	require.NoError(t, err)
+	require.IsType(t, &round.Abort{}, next)
+	assert.ErrorIs(t, next.(*round.Abort).Err, ErrSigningRefused)
+
+	_, err = StartSignWithPolicy(c, partyIDs, messageHash, policy, nil, nil, pl)(nil)
+	assert.Error(t, err, "a policy requires a request")
+}
+
+func TestEmergencySign(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	group := curve.Secp256k1{}
+
+	N, T := 3, 1
+	configs, partyIDs := test.GenerateConfig(group, N, T, mrand.New(mrand.NewSource(1)), pl)
+	messageHash := ecdsa.ProfileEthereum.HashMessage([]byte("hello"))
+
+	routine, err := StartSign(configs[partyIDs[0]], partyIDs, messageHash, pl)(nil)
+	require.NoError(t, err)
+	other, err := StartEmergencySign(configs[partyIDs[0]], partyIDs, messageHash, 2, nil, nil, nil, pl)(nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, routine.SSID(), other.SSID())
+
+	rounds := make([]round.Session, 0, N)
+	for _, id := range partyIDs {
+		r, err := StartEmergencySign(configs[id], partyIDs, messageHash, 3, nil, nil, nil, pl)(nil)
+		require.NoError(t, err)
+		assert.Equal(t, ProtocolEmergencySignID, r.ProtocolID())
+		assert.NotEqual(t, other.SSID(), r.SSID(), "the number of failed attempts should be bound to the session")
+		rounds = append(rounds, r)
+	}
+	for {
+		err, done := test.Rounds(rounds, nil)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	for _, r := range rounds {
+		require.IsType(t, &round.Output{}, r)
+		signature := r.(*round.Output).Result.(*ecdsa.Signature)
+		assert.True(t, signature.Verify(configs[partyIDs[0]].PublicPoint(), messageHash))
+	}
+}
+
+func TestEmergencySignPolicy(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	group := curve.Secp256k1{}
+
+	N, T := 3, 1
+	configs, partyIDs := test.GenerateConfig(group, N, T, mrand.New(mrand.NewSource(1)), pl)
+	c := configs[partyIDs[0]]
+	messageHash := ecdsa.ProfileEthereum.HashMessage([]byte("hello"))
+	policy := &config.Policy{MaxAmount: 10}
+
+	r, err := StartEmergencySign(c, partyIDs, messageHash, 2, policy, &config.PolicyRequest{Amount: 10}, nil, pl)(nil)
+	require.NoError(t, err)
+	other, err := StartEmergencySign(c, partyIDs, messageHash, 2, nil, nil, nil, pl)(nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, r.SSID(), other.SSID(), "the policy should be bound to the session")
+
+	r, err = StartEmergencySign(c, partyIDs, messageHash, 2, policy, &config.PolicyRequest{Amount: 11}, nil, pl)(nil)
+	require.NoError(t, err)
+	next, err := r.Finalize(make(chan *round.Message, 2*N))
+	require.NoError(t, err)
+	require.IsType(t, &round.Abort{}, next)
+	assert.ErrorIs(t, next.(*round.Abort).Err, ErrSigningRefused)
+
+	_, err = StartEmergencySign(c, partyIDs, messageHash, 2, policy, nil, nil, pl)(nil)
+	assert.Error(t, err, "a policy requires a request")
+}
+
+func TestSignWithCheck(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	group := curve.Secp256k1{}
+
+	N, T := 3, 1
+	configs, partyIDs := test.GenerateConfig(group, N, T, mrand.New(mrand.NewSource(1)), pl)
+	messageHash := ecdsa.ProfileEthereum.HashMessage([]byte("hello"))
+	address, err := configs[partyIDs[0]].Address(ecdsa.ProfileEthereum)
+	require.NoError(t, err)
+	other, err := configs[partyIDs[0]].DeriveAddress(ecdsa.ProfileEthereum, config.DerivationPath{1})
+	require.NoError(t, err)
+
+	for _, expected := range []string{address, other} {
+		expected := expected
+		check := func(hash []byte, sig *ecdsa.Signature) error {
+			return ecdsa.VerifyEthereumAddress(expected, hash, *sig)
+		}
+		rounds := make([]round.Session, 0, N)
+		for _, id := range partyIDs {
+			r, err := StartSignWithCheck(configs[id], partyIDs, messageHash, nil, nil, nil, check, pl)(nil)
+			require.NoError(t, err)
+			rounds = append(rounds, r)
+		}
+		for {
+			err, done := test.Rounds(rounds, nil)
+			require.NoError(t, err)
+			if done {
+				break
+			}
+		}
+		for _, r := range rounds {
+			if expected == address {
+				require.IsType(t, &round.Output{}, r)
+				continue
+			}
+			require.IsType(t, &round.Abort{}, r)
+			assert.ErrorContains(t, r.(*round.Abort).Err, "signature check failed")
+		}
+	}
+}
+
+// captureContents records every content sent during a protocol execution, with the round receiving it.
+type captureContents struct {
+	mtx      sync.Mutex
+	rounds   []round.Session
+	contents []round.Content
+}
+
+func (c *captureContents) ModifyBefore(round.Session) {}
+func (c *captureContents) ModifyAfter(round.Session)  {}
+func (c *captureContents) ModifyContent(rNext round.Session, _ party.ID, content round.Content) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.rounds = append(c.rounds, rNext)
+	c.contents = append(c.contents, content)
+}
+
+// captureSign runs a signing session between 2 parties, and returns the contents they sent.
+func captureSign(t testing.TB) *captureContents {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	group := curve.Secp256k1{}
+
+	N, T := 3, 1
+	configs, partyIDs := test.GenerateConfig(group, N, T, mrand.New(mrand.NewSource(1)), pl)
+	partyIDs = partyIDs[:T+1]
+	messageHash := ecdsa.ProfileEthereum.HashMessage([]byte("hello"))
+
+	rounds := make([]round.Session, 0, len(partyIDs))
+	for _, partyID := range partyIDs {
+		r, err := StartSign(configs[partyID], partyIDs, messageHash, pl)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	capture := &captureContents{}
+	for {
+		err, done := test.Rounds(rounds, capture)
+		require.NoError(t, err)
+		if done {
+			break
+		}
+	}
+	return capture
+}
+
+// newContent returns an empty content of the same kind as content, as the round r receiving it allocates it.
+func newContent(r round.Session, content round.Content) round.Content {
+	if _, ok := content.(round.BroadcastContent); ok {
+		return r.(round.BroadcastRound).BroadcastContent()
+	}
+	return r.MessageContent()
+}
+
+func TestContentUnmarshal(t *testing.T) {
+	capture := captureSign(t)
+	canonical, err := cbor.CanonicalEncOptions().EncMode()
+	require.NoError(t, err)
+	unmarshalled := 0
+	for i, content := range capture.contents {
+		r := capture.rounds[i]
+		for _, marshal := range []func(interface{}) ([]byte, error){cbor.Marshal, canonical.Marshal} {
+			data, err := marshal(content)
+			require.NoError(t, err)
+
+			expected := newContent(r, content)
+			require.NoError(t, cbor.Unmarshal(data, expected))
+			actual := newContent(r, content)
+			if _, ok := actual.(round.ContentUnmarshaler); !ok {
+				continue
+			}
+			require.NoError(t, round.UnmarshalContent(data, actual))
+			require.NoError(t, test.ContentRoundTrip(expected, actual))
+			unmarshalled++
+
+			assert.Error(t, round.UnmarshalContent(data[:len(data)-1], newContent(r, content)), "truncated data")
+			assert.Error(t, round.UnmarshalContent(append(data, 0), newContent(r, content)), "trailing data")
+		}
+	}
+	assert.NotZero(t, unmarshalled)
+}
+
+// FuzzDecode checks that the contents decoded without reflection agree with cbor.Unmarshal.
+// UnmarshalContent may reject data which cbor.Unmarshal accepts, such as indefinite lengths or duplicate keys,
+// but whatever it accepts must be accepted by cbor.Unmarshal, with the same result.
+func FuzzDecode(f *testing.F) {
+	capture := captureSign(f)
+	// one round receiving each kind of content
+	var (
+		rounds   []round.Session
+		contents []round.Content
+	)
+	seen := make(map[reflect.Type]bool)
+	for i, content := range capture.contents {
+		if _, ok := newContent(capture.rounds[i], content).(round.ContentUnmarshaler); !ok || seen[reflect.TypeOf(content)] {
+			continue
+		}
+		seen[reflect.TypeOf(content)] = true
+		rounds = append(rounds, capture.rounds[i])
+		contents = append(contents, content)
+		data, err := cbor.Marshal(content)
+		require.NoError(f, err)
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for i, content := range contents {
+			actual := newContent(rounds[i], content)
+			if err := round.UnmarshalContent(data, actual); err != nil {
+				continue
+			}
+			expected := newContent(rounds[i], content)
+			require.NoError(t, unmarshal(data, expected), "%T", content)
+			require.NoError(t, test.ContentRoundTrip(expected, actual))
+		}
+	})
+}
+
+// unmarshal calls cbor.Unmarshal, and returns an error if it panics,
+// as it does when decoding null into an interface holding a value, such as a curve.Scalar.
+func unmarshal(data []byte, v interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("cbor.Unmarshal panicked: %v", r)
+		}
+	}()
+	return cbor.Unmarshal(data, v)
+}
+
+func TestSignWeighted(t *testing.T) {
+	pl := pool.NewPool(0)
+	defer pl.TearDown()
+	group := curve.Secp256k1{}
+
+	N, T := 3, 2
+	weights := party.Weights{test.PartyIDs(N)[0]: 2}
+	configs, partyIDs := test.GenerateWeightedConfig(group, N, T, weights, mrand.New(mrand.NewSource(1)), pl)
+	publicPoint := configs[partyIDs[0]].PublicPoint()
+	messageHash := ecdsa.ProfileEthereum.HashMessage([]byte("hello"))
+
+	// T signers are enough when one of them holds two shares
+	signers := partyIDs[:T]
+	rounds := make([]round.Session, 0, T)
+	for _, partyID := range signers {
+		r, err := StartSign(configs[partyID], signers, messageHash, pl)(nil)
+		require.NoError(t, err)
+		rounds = append(rounds, r)
+	}
+	for {
+		err, done := test.Rounds(rounds, nil)
+		require.NoError(t, err, "failed to process round")
+		if done {
+			break
+		}
+	}
+	for _, r := range rounds {
+		require.IsType(t, &round.Output{}, r, "expected result round")
+		signature := r.(*round.Output).Result.(*ecdsa.Signature)
+		assert.True(t, signature.Verify(publicPoint, messageHash), "expected valid signature")
+	}
+
+	_, err := StartSign(configs[partyIDs[1]], partyIDs[1:], messageHash, pl)(nil)
+	assert.Error(t, err, "signers without the weighted party are not enough")
+}
@@ -0,0 +1,44 @@
+package cmp
+
+import (
+	"fmt"
+
+	"github.com/taurusgroup/multi-party-sig/pkg/ecdsa"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/pool"
+	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/presign"
+	"github.com/taurusgroup/multi-party-sig/protocols/cmp/sign"
+)
+
+// SignatureCheck is called by SignWithCheck and PresignOnlineWithCheck on a signature which verifies,
+// before it is returned. If it returns an error, the protocol aborts with it instead.
+type SignatureCheck func(messageHash []byte, sig *ecdsa.Signature) error
+
+// EthereumWatchdog returns a SignatureCheck which serializes the signature with SigEthereum,
+// recovers the address of the signer from the result, and fails unless it is the Ethereum address of config,
+// see ecdsa.VerifyEthereumAddress.
+//
+// The signature itself is already verified by the protocol, so a failure indicates a bug in its serialization,
+// which would otherwise only be noticed once the signature is rejected onchain.
+func EthereumWatchdog(config *Config) (SignatureCheck, error) {
+	address, err := config.Address(ecdsa.ProfileEthereum)
+	if err != nil {
+		return nil, fmt.Errorf("cmp: %w", err)
+	}
+	return func(messageHash []byte, sig *ecdsa.Signature) error {
+		return ecdsa.VerifyEthereumAddress(address, messageHash, *sig)
+	}, nil
+}
+
+// SignWithCheck is the same as Sign, but calls check on the signature before returning it.
+// If check is nil, it is equivalent to Sign.
+func SignWithCheck(config *Config, signers []party.ID, messageHash []byte, check SignatureCheck, pl *pool.Pool) protocol.StartFunc {
+	return sign.StartSignWithCheck(config, signers, messageHash, nil, nil, nil, check, pl)
+}
+
+// PresignOnlineWithCheck is the same as PresignOnline, but calls check on the signature before returning it.
+// If check is nil, it is equivalent to PresignOnline.
+func PresignOnlineWithCheck(config *Config, preSignature *ecdsa.PreSignature, messageHash []byte, check SignatureCheck, pl *pool.Pool) protocol.StartFunc {
+	return presign.StartPresignOnlineWithCheck(config, preSignature, messageHash, check, pl)
+}
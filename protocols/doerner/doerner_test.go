@@ -23,15 +23,17 @@ func runHandler(wg *sync.WaitGroup, id party.ID, handler protocol.Handler, netwo
 
 var testGroup = curve.Secp256k1{}
 
+var testSessionID = protocol.DeriveSessionID("doerner/test", 0, nil)
+
 func runKeygen(partyIDs party.IDSlice) (*ConfigSender, *ConfigReceiver, error) {
 	pl := pool.NewPool(0)
 	defer pl.TearDown()
 
-	h0, err := protocol.NewTwoPartyHandler(Keygen(testGroup, true, partyIDs[0], partyIDs[1], pl), []byte("session"), true)
+	h0, err := protocol.NewTwoPartyHandler(Keygen(testGroup, true, partyIDs[0], partyIDs[1], pl), testSessionID, true)
 	if err != nil {
 		return nil, nil, err
 	}
-	h1, err := protocol.NewTwoPartyHandler(Keygen(testGroup, false, partyIDs[1], partyIDs[0], pl), []byte("session"), false)
+	h1, err := protocol.NewTwoPartyHandler(Keygen(testGroup, false, partyIDs[1], partyIDs[0], pl), testSessionID, false)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -67,11 +69,11 @@ func runRefresh(partyIDs party.IDSlice, configSender *ConfigSender, configReceiv
 	pl := pool.NewPool(0)
 	defer pl.TearDown()
 
-	h0, err := protocol.NewTwoPartyHandler(RefreshReceiver(configReceiver, partyIDs[0], partyIDs[1], pl), []byte("session"), true)
+	h0, err := protocol.NewTwoPartyHandler(RefreshReceiver(configReceiver, partyIDs[0], partyIDs[1], pl), testSessionID, true)
 	if err != nil {
 		return nil, nil, err
 	}
-	h1, err := protocol.NewTwoPartyHandler(RefreshSender(configSender, partyIDs[1], partyIDs[0], pl), []byte("session"), false)
+	h1, err := protocol.NewTwoPartyHandler(RefreshSender(configSender, partyIDs[1], partyIDs[0], pl), testSessionID, false)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -109,11 +111,11 @@ func runSign(partyIDs party.IDSlice, configSender *keygen.ConfigSender, configRe
 	pl := pool.NewPool(0)
 	defer pl.TearDown()
 
-	h0, err := protocol.NewTwoPartyHandler(SignReceiver(configReceiver, partyIDs[0], partyIDs[1], testHash, pl), []byte("session"), true)
+	h0, err := protocol.NewTwoPartyHandler(SignReceiver(configReceiver, partyIDs[0], partyIDs[1], testHash, pl), testSessionID, true)
 	if err != nil {
 		return nil, err
 	}
-	h1, err := protocol.NewTwoPartyHandler(SignSender(configSender, partyIDs[1], partyIDs[0], testHash, pl), []byte("session"), true)
+	h1, err := protocol.NewTwoPartyHandler(SignSender(configSender, partyIDs[1], partyIDs[0], testHash, pl), testSessionID, true)
 	if err != nil {
 		return nil, err
 	}
@@ -1,7 +1,6 @@
 package keygen
 
 import (
-	"crypto/rand"
 	"errors"
 	"fmt"
 
@@ -12,6 +11,7 @@ import (
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 	"github.com/taurusgroup/multi-party-sig/pkg/pool"
 	"github.com/taurusgroup/multi-party-sig/pkg/protocol"
 )
@@ -118,7 +118,7 @@ func StartKeygen(group curve.Curve, receiver bool, selfID, otherID party.ID, sec
 
 		refresh := true
 		if secretShare == nil && public == nil {
-			secretShare = sample.Scalar(rand.Reader, group)
+			secretShare = sample.Scalar(platform.Reader, group)
 			refresh = false
 		}
 		publicShare := secretShare.ActOnBase()
@@ -183,3 +183,9 @@ func (c *ConfigSender) DeriveBIP32(i uint32) (*ConfigSender, error) {
 	}
 	return c.Derive(scalar, newChainKey)
 }
+
+// ResultKind returns "config", see protocol.KindOf.
+func (ConfigReceiver) ResultKind() string { return "config" }
+
+// ResultKind returns "config", see protocol.KindOf.
+func (ConfigSender) ResultKind() string { return "config" }
@@ -1,7 +1,7 @@
 package keygen
 
 import (
-	"crypto/rand"
+	"io"
 
 	"github.com/taurusgroup/multi-party-sig/internal/ot"
 	"github.com/taurusgroup/multi-party-sig/internal/params"
@@ -9,6 +9,7 @@ import (
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 	zksch "github.com/taurusgroup/multi-party-sig/pkg/zk/sch"
 )
 
@@ -56,12 +57,12 @@ func (r *round1R) Finalize(out chan<- *round.Message) (round.Session, error) {
 		return r, err
 	}
 	chainKey := make([]byte, params.SecBytes)
-	_, _ = rand.Read(chainKey)
+	_, _ = io.ReadFull(platform.Reader, chainKey)
 	chainKeyCommit, chainKeyDecommit, err := r.Hash().Commit(chainKey)
 	if err != nil {
 		return r, err
 	}
-	refreshScalar := sample.Scalar(rand.Reader, r.Group())
+	refreshScalar := sample.Scalar(platform.Reader, r.Group())
 	refreshCommit, refreshDecommit, err := r.Hash().Commit(refreshScalar)
 	if err != nil {
 		return r, err
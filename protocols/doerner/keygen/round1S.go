@@ -1,7 +1,7 @@
 package keygen
 
 import (
-	"crypto/rand"
+	"io"
 
 	"github.com/taurusgroup/multi-party-sig/internal/ot"
 	"github.com/taurusgroup/multi-party-sig/internal/params"
@@ -9,6 +9,7 @@ import (
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 	zksch "github.com/taurusgroup/multi-party-sig/pkg/zk/sch"
 )
 
@@ -87,8 +88,8 @@ func (r *round1S) StoreMessage(msg round.Message) (err error) {
 func (r *round1S) Finalize(out chan<- *round.Message) (round.Session, error) {
 	proof := zksch.NewProof(r.Hash(), r.publicShare, r.secretShare, nil)
 	chainKey := make([]byte, params.SecBytes)
-	_, _ = rand.Read(chainKey)
-	refreshScalar := sample.Scalar(rand.Reader, r.Group())
+	_, _ = io.ReadFull(platform.Reader, chainKey)
+	refreshScalar := sample.Scalar(platform.Reader, r.Group())
 	if err := r.SendMessage(out, &message1S{r.publicShare, chainKey, refreshScalar, proof, r.otMsg}, ""); err != nil {
 		return r, err
 	}
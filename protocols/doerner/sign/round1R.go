@@ -1,13 +1,12 @@
 package sign
 
 import (
-	"crypto/rand"
-
 	"github.com/taurusgroup/multi-party-sig/internal/ot"
 	"github.com/taurusgroup/multi-party-sig/internal/round"
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 	"github.com/taurusgroup/multi-party-sig/protocols/doerner/keygen"
 )
 
@@ -34,7 +33,7 @@ func (r *round1R) VerifyMessage(round.Message) error { return nil }
 func (r *round1R) StoreMessage(round.Message) error { return nil }
 
 func (r *round1R) Finalize(out chan<- *round.Message) (round.Session, error) {
-	kB := sample.Scalar(rand.Reader, r.Group())
+	kB := sample.Scalar(platform.Reader, r.Group())
 	D := kB.ActOnBase()
 	kB.Invert()
 	tag0 := &hash.BytesWithDomain{TheDomain: "Multiply0", Bytes: nil}
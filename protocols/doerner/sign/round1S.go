@@ -1,7 +1,6 @@
 package sign
 
 import (
-	"crypto/rand"
 	"errors"
 
 	"github.com/taurusgroup/multi-party-sig/internal/ot"
@@ -9,6 +8,7 @@ import (
 	"github.com/taurusgroup/multi-party-sig/pkg/hash"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 	zksch "github.com/taurusgroup/multi-party-sig/pkg/zk/sch"
 	"github.com/taurusgroup/multi-party-sig/protocols/doerner/keygen"
 )
@@ -65,7 +65,7 @@ func (r *round1S) StoreMessage(msg round.Message) error {
 func (r *round1S) Finalize(out chan<- *round.Message) (round.Session, error) {
 	group := r.Group()
 
-	kAPrime := sample.Scalar(rand.Reader, group)
+	kAPrime := sample.Scalar(platform.Reader, group)
 	RPrime := kAPrime.Act(r.D)
 
 	H := r.Hash()
@@ -75,7 +75,7 @@ func (r *round1S) Finalize(out chan<- *round.Message) (round.Session, error) {
 	R := kA.Act(r.D)
 	RProof := zksch.NewProof(r.Hash(), R, kA, r.D)
 
-	phi := sample.Scalar(rand.Reader, group)
+	phi := sample.Scalar(platform.Reader, group)
 	kAInv := group.NewScalar().Set(kA).Invert()
 	alpha1 := group.NewScalar().Set(r.config.SecretShare).Mul(kAInv)
 	alpha2 := group.NewScalar().Set(kAInv)
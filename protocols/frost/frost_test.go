@@ -94,3 +94,57 @@ func TestFrost(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestFrostRoundTrip(t *testing.T) {
+	N := 3
+	T := N - 1
+	message := []byte("hello")
+
+	partyIDs := test.PartyIDs(N)
+
+	// every message is serialized and restored with both CBOR and JSON before delivery
+	n := test.NewRoundTripNetwork(partyIDs)
+
+	var wg sync.WaitGroup
+	wg.Add(N)
+	for _, id := range partyIDs {
+		go do(t, id, partyIDs, T, message, n, &wg)
+	}
+	wg.Wait()
+	assert.Empty(t, n.Errors())
+}
+
+func TestFrostTranscript(t *testing.T) {
+	partyIDs := test.PartyIDs(3)
+	message := []byte("hello")
+
+	keygen := func(id party.ID) protocol.StartFunc { return Keygen(curve.Secp256k1{}, id, partyIDs, 1) }
+	transcript, results, err := test.Record(partyIDs, nil, keygen)
+	require.NoError(t, err)
+	configs := make(map[party.ID]*Config, len(results))
+	for id, r := range results {
+		configs[id] = r.(*Config)
+	}
+	sign := func(id party.ID) protocol.StartFunc { return Sign(configs[id], partyIDs, message) }
+	signTranscript, _, err := test.Record(partyIDs, nil, sign)
+	require.NoError(t, err)
+
+	// transcripts can be stored as fixtures
+	data, err := transcript.MarshalBinary()
+	require.NoError(t, err)
+	restored := &test.Transcript{}
+	require.NoError(t, restored.UnmarshalBinary(data))
+
+	for _, id := range partyIDs {
+		result, err := restored.Replay(id, keygen(id))
+		require.NoError(t, err)
+		assert.True(t, result.(*Config).PublicKey.Equal(configs[id].PublicKey))
+		_, err = signTranscript.Replay(id, sign(id))
+		require.NoError(t, err)
+	}
+
+	// different randomness is detected
+	restored.Parties[partyIDs[0]].Randomness[0] ^= 1
+	_, err = restored.Replay(partyIDs[0], keygen(partyIDs[0]))
+	assert.Error(t, err)
+}
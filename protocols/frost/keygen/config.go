@@ -210,3 +210,9 @@ func (r *TaprootConfig) DeriveChild(i uint32) (*TaprootConfig, error) {
 	}
 	return r.Derive(scalar, newChainKey)
 }
+
+// ResultKind returns "config", see protocol.KindOf.
+func (Config) ResultKind() string { return "config" }
+
+// ResultKind returns "config", see protocol.KindOf.
+func (TaprootConfig) ResultKind() string { return "config" }
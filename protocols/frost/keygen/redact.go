@@ -0,0 +1,69 @@
+package keygen
+
+import (
+	"encoding/hex"
+
+	"github.com/taurusgroup/multi-party-sig/internal/types"
+	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
+	"github.com/taurusgroup/multi-party-sig/pkg/party"
+)
+
+// RedactedConfig is a copy of a Config or TaprootConfig which can safely be shared, for example in a bug report.
+// The private share is replaced by a short digest, so that dumps of different parties can still be compared.
+type RedactedConfig struct {
+	ID                 party.ID            `json:"id"`
+	Threshold          int                 `json:"threshold"`
+	PrivateShare       string              `json:"privateShare"`
+	PublicKey          string              `json:"publicKey"`
+	ChainKey           string              `json:"chainKey"`
+	VerificationShares map[party.ID]string `json:"verificationShares"`
+}
+
+// Redact returns a copy of r without any secret, which can be marshalled with encoding/json.
+func (r *Config) Redact() *RedactedConfig {
+	rc := &RedactedConfig{
+		ID:                 r.ID,
+		Threshold:          r.Threshold,
+		PrivateShare:       redactScalar(r.PrivateShare),
+		ChainKey:           hex.EncodeToString(r.ChainKey),
+		VerificationShares: map[party.ID]string{},
+	}
+	if r.PublicKey != nil {
+		rc.PublicKey = curve.ToHexCompressed(r.PublicKey)
+	}
+	if r.VerificationShares != nil {
+		for id, point := range r.VerificationShares.Points {
+			rc.VerificationShares[id] = curve.ToHexCompressed(point)
+		}
+	}
+	return rc
+}
+
+// Redact returns a copy of r without any secret, which can be marshalled with encoding/json.
+func (r *TaprootConfig) Redact() *RedactedConfig {
+	rc := &RedactedConfig{
+		ID:                 r.ID,
+		Threshold:          r.Threshold,
+		PublicKey:          hex.EncodeToString(r.PublicKey),
+		ChainKey:           hex.EncodeToString(r.ChainKey),
+		VerificationShares: make(map[party.ID]string, len(r.VerificationShares)),
+	}
+	if r.PrivateShare != nil {
+		rc.PrivateShare = redactScalar(r.PrivateShare)
+	}
+	for id, point := range r.VerificationShares {
+		rc.VerificationShares[id] = curve.ToHexCompressed(point)
+	}
+	return rc
+}
+
+func redactScalar(s curve.Scalar) string {
+	if s == nil {
+		return ""
+	}
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return ""
+	}
+	return types.RedactedDigest(data)
+}
@@ -1,7 +1,6 @@
 package keygen
 
 import (
-	"crypto/rand"
 	"fmt"
 
 	"github.com/taurusgroup/multi-party-sig/internal/round"
@@ -11,6 +10,7 @@ import (
 	"github.com/taurusgroup/multi-party-sig/pkg/math/polynomial"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 	zksch "github.com/taurusgroup/multi-party-sig/pkg/zk/sch"
 )
 
@@ -73,7 +73,7 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	a_i0 := group.NewScalar()
 	a_i0_times_G := group.NewPoint()
 	if !r.refresh {
-		a_i0 = sample.Scalar(rand.Reader, r.Group())
+		a_i0 = sample.Scalar(platform.Reader, r.Group())
 		a_i0_times_G = a_i0.ActOnBase()
 	}
 	f_i := polynomial.NewPolynomial(r.Group(), r.threshold, a_i0)
@@ -111,7 +111,7 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	Phi_i := polynomial.NewPolynomialExponent(f_i)
 
 	// c_i is our contribution to the chaining key
-	c_i, err := types.NewRID(rand.Reader)
+	c_i, err := types.NewRID(platform.Reader)
 	if err != nil {
 		return r, fmt.Errorf("failed to sample ChainKey")
 	}
@@ -57,7 +57,7 @@ func (r *round2) StoreBroadcastMessage(msg round.Message) error {
 		return round.ErrNilFields
 	}
 
-	if err := body.Commitment.Validate(); err != nil {
+	if err := body.Commitment.ValidateProfile(r.SecurityProfile()); err != nil {
 		return fmt.Errorf("commitment: %w", err)
 	}
 
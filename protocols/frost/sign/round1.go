@@ -1,12 +1,13 @@
 package sign
 
 import (
-	"crypto/rand"
+	"io"
 
 	"github.com/taurusgroup/multi-party-sig/internal/round"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/curve"
 	"github.com/taurusgroup/multi-party-sig/pkg/math/sample"
 	"github.com/taurusgroup/multi-party-sig/pkg/party"
+	"github.com/taurusgroup/multi-party-sig/pkg/platform"
 	"github.com/zeebo/blake3"
 )
 
@@ -75,7 +76,7 @@ func (r *round1) Finalize(out chan<- *round.Message) (round.Session, error) {
 	_, _ = nonceHasher.Write(r.Hash().Sum())
 	_, _ = nonceHasher.Write(r.M)
 	a := make([]byte, 32)
-	_, _ = rand.Read(a)
+	_, _ = io.ReadFull(platform.Reader, a)
 	_, _ = nonceHasher.Write(a)
 	nonceDigest := nonceHasher.Digest()
 
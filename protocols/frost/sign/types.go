@@ -56,3 +56,6 @@ func (sig Signature) Verify(public curve.Point, m []byte) bool {
 
 	return expected.Equal(actual)
 }
+
+// ResultKind returns "signature", see protocol.KindOf.
+func (Signature) ResultKind() string { return "signature" }